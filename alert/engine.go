@@ -0,0 +1,251 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/metrics"
+)
+
+const (
+	STOP  = 1
+	START = 2
+)
+
+const defaultInterval = 15 * time.Second
+
+var errUnsupportedMetric = errors.New("metric is not found or not a gauge, counter or meter")
+
+// Engine evaluates the rules every interval and notifies an alert when a rule starts or stops
+// firing, so that a broken condition is reported once instead of on every evaluation.
+type Engine struct {
+	source    Source
+	notifiers []Notifier
+	interval  time.Duration
+	log       log15.Logger
+
+	rulesLock sync.Mutex
+	rules     []*Rule
+	firing    map[string]*Alert
+
+	status     int
+	statusLock sync.Mutex
+	ticker     *time.Ticker
+	terminal   chan struct{}
+	wg         sync.WaitGroup
+}
+
+func NewEngine(source Source, notifiers []Notifier, interval time.Duration) *Engine {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	return &Engine{
+		source:    source,
+		notifiers: notifiers,
+		interval:  interval,
+		log:       log15.New("module", "alert"),
+		firing:    make(map[string]*Alert),
+		status:    STOP,
+	}
+}
+
+func (e *Engine) Start() {
+	e.statusLock.Lock()
+	defer e.statusLock.Unlock()
+	if e.status == START {
+		return
+	}
+
+	e.ticker = time.NewTicker(e.interval)
+	e.terminal = make(chan struct{})
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			select {
+			case <-e.ticker.C:
+				e.evaluateAll()
+			case <-e.terminal:
+				return
+			}
+		}
+	}()
+
+	e.status = START
+}
+
+func (e *Engine) Stop() {
+	e.statusLock.Lock()
+	defer e.statusLock.Unlock()
+	if e.status == STOP {
+		return
+	}
+
+	e.ticker.Stop()
+	close(e.terminal)
+	e.wg.Wait()
+	e.status = STOP
+}
+
+func (e *Engine) AddRule(rule *Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	e.rulesLock.Lock()
+	defer e.rulesLock.Unlock()
+	for _, r := range e.rules {
+		if r.Name == rule.Name {
+			return ErrRuleExists
+		}
+	}
+	e.rules = append(e.rules, rule)
+	return nil
+}
+
+// RemoveRule removes the rule named name, it is not resolved if it is firing.
+func (e *Engine) RemoveRule(name string) error {
+	e.rulesLock.Lock()
+	defer e.rulesLock.Unlock()
+	for i, r := range e.rules {
+		if r.Name == name {
+			e.rules = append(e.rules[:i], e.rules[i+1:]...)
+			delete(e.firing, name)
+			return nil
+		}
+	}
+	return ErrRuleNotFound
+}
+
+func (e *Engine) Rules() []*Rule {
+	e.rulesLock.Lock()
+	defer e.rulesLock.Unlock()
+	rules := make([]*Rule, len(e.rules))
+	copy(rules, e.rules)
+	return rules
+}
+
+// Firing returns the alerts of the rules which are firing now.
+func (e *Engine) Firing() []*Alert {
+	e.rulesLock.Lock()
+	defer e.rulesLock.Unlock()
+	alerts := make([]*Alert, 0, len(e.firing))
+	for _, r := range e.rules {
+		if a, ok := e.firing[r.Name]; ok {
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+func (e *Engine) evaluateAll() {
+	var alerts []*Alert
+	now := time.Now()
+
+	e.rulesLock.Lock()
+	for _, r := range e.rules {
+		value, broken, err := e.evaluate(r, now)
+		if err != nil {
+			e.log.Error(fmt.Sprintf("evaluate rule %s failed, error is %v", r.Name, err), "method", "evaluateAll")
+			continue
+		}
+		_, wasFiring := e.firing[r.Name]
+		if broken == wasFiring {
+			continue
+		}
+		a := &Alert{Rule: r.Name, Kind: r.Kind, Value: value.String(), Message: r.message(value), Resolved: !broken, Time: now}
+		if broken {
+			e.firing[r.Name] = a
+		} else {
+			delete(e.firing, r.Name)
+		}
+		alerts = append(alerts, a)
+	}
+	e.rulesLock.Unlock()
+
+	for _, a := range alerts {
+		e.notify(a)
+	}
+}
+
+func (e *Engine) notify(a *Alert) {
+	e.log.Warn(a.title(), "value", a.Value, "message", a.Message)
+	for _, n := range e.notifiers {
+		if err := n.Notify(a); err != nil {
+			e.log.Error("notify alert failed, error is "+err.Error(), "method", "notify")
+		}
+	}
+}
+
+// evaluate returns the current value of the rule and whether it breaks the threshold.
+func (e *Engine) evaluate(r *Rule, now time.Time) (*big.Int, bool, error) {
+	threshold, err := r.threshold()
+	if err != nil {
+		return nil, false, err
+	}
+	switch r.Kind {
+	case KindPeerCount:
+		value := big.NewInt(int64(e.source.PeerCount()))
+		return value, compare(value, threshold, OpLess), nil
+	case KindSnapshotStall:
+		latest := e.source.GetLatestSnapshotBlock()
+		if latest == nil || latest.Timestamp == nil {
+			return nil, false, errors.New("latest snapshot block is not available")
+		}
+		value := big.NewInt(int64(now.Sub(*latest.Timestamp) / time.Second))
+		return value, compare(value, threshold, OpGreater), nil
+	case KindBalance:
+		value, err := e.source.GetAccountBalanceByTokenId(r.Address, r.TokenId)
+		if err != nil {
+			return nil, false, err
+		}
+		if value == nil {
+			value = big.NewInt(0)
+		}
+		return value, compare(value, threshold, OpLess), nil
+	case KindFilterBacklog:
+		value := big.NewInt(int64(e.source.FilterBacklog()))
+		return value, compare(value, threshold, OpGreater), nil
+	case KindMetric:
+		value, err := metricValue(metrics.DefaultRegistry, r.Metric)
+		if err != nil {
+			return nil, false, err
+		}
+		return value, compare(value, threshold, r.Op), nil
+	}
+	return nil, false, ErrUnknownRuleKind
+}
+
+// metricValue reads the value of a gauge, the count of a counter or the 1-minute rate of a meter.
+func metricValue(r metrics.Registry, name string) (*big.Int, error) {
+	switch m := r.Get(name).(type) {
+	case metrics.Gauge:
+		return big.NewInt(m.Value()), nil
+	case metrics.GaugeFloat64:
+		return big.NewInt(int64(m.Value())), nil
+	case metrics.Counter:
+		return big.NewInt(m.Count()), nil
+	case metrics.Meter:
+		return big.NewInt(int64(m.Rate1())), nil
+	}
+	return nil, errUnsupportedMetric
+}
+
+func (r *Rule) message(value *big.Int) string {
+	switch r.Kind {
+	case KindPeerCount:
+		return fmt.Sprintf("peer count is %s, threshold is %s", value, r.Threshold)
+	case KindSnapshotStall:
+		return fmt.Sprintf("no new snapshot block for %s seconds, threshold is %s", value, r.Threshold)
+	case KindBalance:
+		return fmt.Sprintf("balance of %s in %s is %s, threshold is %s", r.Address, r.TokenId, value, r.Threshold)
+	case KindFilterBacklog:
+		return fmt.Sprintf("polling filters buffer %s messages, threshold is %s", value, r.Threshold)
+	case KindMetric:
+		return fmt.Sprintf("metric %s is %s, threshold is %s %s", r.Metric, value, r.Op, r.Threshold)
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package alert
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/metrics"
+)
+
+type mockSource struct {
+	peerCount int
+	latest    *ledger.SnapshotBlock
+	balance   *big.Int
+	backlog   int
+}
+
+func (s *mockSource) PeerCount() int { return s.peerCount }
+
+func (s *mockSource) GetLatestSnapshotBlock() *ledger.SnapshotBlock { return s.latest }
+
+func (s *mockSource) GetAccountBalanceByTokenId(addr *types.Address, tokenId *types.TokenTypeId) (*big.Int, error) {
+	return s.balance, nil
+}
+
+func (s *mockSource) FilterBacklog() int { return s.backlog }
+
+type mockNotifier struct {
+	alerts []*Alert
+}
+
+func (n *mockNotifier) Notify(alert *Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func TestRule_Validate(t *testing.T) {
+	cases := []struct {
+		rule Rule
+		err  error
+	}{
+		{Rule{Kind: KindPeerCount, Threshold: "3"}, ErrEmptyRuleName},
+		{Rule{Name: "a", Kind: "unknown", Threshold: "3"}, ErrUnknownRuleKind},
+		{Rule{Name: "a", Kind: KindPeerCount, Threshold: "-1"}, ErrInvalidThreshold},
+		{Rule{Name: "a", Kind: KindBalance, Threshold: "1"}, ErrMissingAddress},
+		{Rule{Name: "a", Kind: KindMetric, Threshold: "1"}, ErrMissingMetricName},
+		{Rule{Name: "a", Kind: KindMetric, Threshold: "1", Metric: "m", Op: "="}, ErrUnknownOp},
+		{Rule{Name: "a", Kind: KindSnapshotStall, Threshold: "60"}, nil},
+	}
+	for i, c := range cases {
+		if err := c.rule.Validate(); err != c.err {
+			t.Fatalf("case %d: expected %v, got %v", i, c.err, err)
+		}
+	}
+
+	r := &Rule{Name: "a", Kind: KindBalance, Threshold: "1", Address: &types.AddressPledge}
+	if err := r.Validate(); err != nil || r.TokenId == nil || *r.TokenId != ledger.ViteTokenId {
+		t.Fatalf("balance rule should default to vite token, got %v %v", r.TokenId, err)
+	}
+}
+
+func TestEngine_evaluateAll(t *testing.T) {
+	now := time.Now()
+	source := &mockSource{peerCount: 1, latest: &ledger.SnapshotBlock{Timestamp: &now}, balance: big.NewInt(100)}
+	notifier := &mockNotifier{}
+	e := NewEngine(source, []Notifier{notifier}, 0)
+
+	rules := []*Rule{
+		{Name: "peers", Kind: KindPeerCount, Threshold: "3"},
+		{Name: "stall", Kind: KindSnapshotStall, Threshold: "60"},
+		{Name: "balance", Kind: KindBalance, Threshold: "10", Address: &types.AddressPledge},
+		{Name: "backlog", Kind: KindFilterBacklog, Threshold: "1000"},
+	}
+	for _, r := range rules {
+		if err := e.AddRule(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := e.AddRule(&Rule{Name: "peers", Kind: KindPeerCount, Threshold: "1"}); err != ErrRuleExists {
+		t.Fatalf("expected ErrRuleExists, got %v", err)
+	}
+
+	e.evaluateAll()
+	if len(notifier.alerts) != 1 || notifier.alerts[0].Rule != "peers" || notifier.alerts[0].Resolved {
+		t.Fatalf("unexpected alerts %+v", notifier.alerts)
+	}
+
+	// a firing rule is not notified again
+	e.evaluateAll()
+	if len(notifier.alerts) != 1 {
+		t.Fatalf("firing rule is notified again")
+	}
+
+	stale := now.Add(-2 * time.Minute)
+	source.peerCount = 5
+	source.latest = &ledger.SnapshotBlock{Timestamp: &stale}
+	source.balance = big.NewInt(1)
+	source.backlog = 2000
+	e.evaluateAll()
+	if len(notifier.alerts) != 5 || !notifier.alerts[1].Resolved {
+		t.Fatalf("unexpected alerts %+v", notifier.alerts)
+	}
+	if firing := e.Firing(); len(firing) != 3 || firing[0].Rule != "stall" {
+		t.Fatalf("unexpected firing alerts %+v", firing)
+	}
+
+	if err := e.RemoveRule("stall"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.RemoveRule("stall"); err != ErrRuleNotFound {
+		t.Fatalf("expected ErrRuleNotFound, got %v", err)
+	}
+	if firing := e.Firing(); len(firing) != 2 {
+		t.Fatalf("removed rule is still firing")
+	}
+}
+
+func TestMetricValue(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.Register("gauge", &metrics.StandardGauge{})
+	r.Register("counter", &metrics.StandardCounter{})
+	r.Get("gauge").(metrics.Gauge).Update(7)
+	r.Get("counter").(metrics.Counter).Inc(3)
+
+	if v, err := metricValue(r, "gauge"); err != nil || v.Int64() != 7 {
+		t.Fatalf("unexpected gauge value %v %v", v, err)
+	}
+	if v, err := metricValue(r, "counter"); err != nil || v.Int64() != 3 {
+		t.Fatalf("unexpected counter value %v %v", v, err)
+	}
+	if _, err := metricValue(r, "unknown"); err != errUnsupportedMetric {
+		t.Fatalf("expected errUnsupportedMetric, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package alert
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// Source provides the values the rules are evaluated over.
+type Source interface {
+	PeerCount() int
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetAccountBalanceByTokenId(addr *types.Address, tokenId *types.TokenTypeId) (*big.Int, error)
+	FilterBacklog() int
+}
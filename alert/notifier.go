@@ -0,0 +1,94 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// Alert is sent to the notifiers when a rule starts firing, and again with Resolved set
+// when it stops.
+type Alert struct {
+	Rule     string    `json:"rule"`
+	Kind     string    `json:"kind"`
+	Value    string    `json:"value"`
+	Message  string    `json:"message"`
+	Resolved bool      `json:"resolved"`
+	Time     time.Time `json:"time"`
+}
+
+func (a *Alert) title() string {
+	if a.Resolved {
+		return "[gvite] resolved: " + a.Rule
+	}
+	return "[gvite] firing: " + a.Rule
+}
+
+// Notifier delivers alerts to somewhere out of the node.
+type Notifier interface {
+	Notify(alert *Alert) error
+}
+
+// WebhookNotifier posts each alert as json to URL.
+type WebhookNotifier struct {
+	URL    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{URL: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (n *WebhookNotifier) Notify(alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook %s responds %s", n.URL, resp.Status)
+	}
+	return nil
+}
+
+// EmailConfig is the smtp server and the mailboxes an EmailNotifier uses, the plain auth
+// is used if Username is not empty.
+type EmailConfig struct {
+	SmtpAddr string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+type EmailNotifier struct {
+	cfg EmailConfig
+}
+
+func NewEmailNotifier(cfg EmailConfig) *EmailNotifier {
+	return &EmailNotifier{cfg: cfg}
+}
+
+func (n *EmailNotifier) Notify(alert *Alert) error {
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		host := n.cfg.SmtpAddr
+		if i := strings.LastIndex(host, ":"); i >= 0 {
+			host = host[:i]
+		}
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\nvalue: %s\r\ntime: %s\r\n",
+		n.cfg.From, strings.Join(n.cfg.To, ","), alert.title(), alert.Message, alert.Value, alert.Time.Format(time.RFC3339))
+	return smtp.SendMail(n.cfg.SmtpAddr, auth, n.cfg.From, n.cfg.To, []byte(msg))
+}
@@ -0,0 +1,102 @@
+package alert
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// Kinds of the conditions a rule evaluates.
+const (
+	// KindPeerCount fires when the count of connected peers is below Threshold
+	KindPeerCount = "peerCount"
+	// KindSnapshotStall fires when no snapshot block is produced for Threshold seconds
+	KindSnapshotStall = "snapshotStall"
+	// KindBalance fires when the balance of TokenId of Address is below Threshold
+	KindBalance = "balance"
+	// KindFilterBacklog fires when the polling filters buffer more than Threshold messages in all
+	KindFilterBacklog = "filterBacklog"
+	// KindMetric compares the metric named Metric of the default registry to Threshold with Op
+	KindMetric = "metric"
+)
+
+const (
+	OpLess    = "<"
+	OpGreater = ">"
+)
+
+var (
+	ErrEmptyRuleName     = errors.New("rule name must not be empty")
+	ErrUnknownRuleKind   = errors.New("unknown rule kind")
+	ErrInvalidThreshold  = errors.New("invalid rule threshold")
+	ErrMissingAddress    = errors.New("balance rule needs an address")
+	ErrMissingMetricName = errors.New("metric rule needs a metric name")
+	ErrUnknownOp         = errors.New("unknown rule op, must be < or >")
+	ErrRuleExists        = errors.New("rule already exists")
+	ErrRuleNotFound      = errors.New("rule not found")
+)
+
+// Rule is a condition evaluated periodically, an alert is fired when it becomes true and
+// resolved when it becomes false again. Threshold is a decimal string so that balances
+// in the smallest unit fit in it.
+type Rule struct {
+	Name      string             `json:"name"`
+	Kind      string             `json:"kind"`
+	Threshold string             `json:"threshold"`
+	Address   *types.Address     `json:"address,omitempty"`
+	TokenId   *types.TokenTypeId `json:"tokenId,omitempty"`
+	Metric    string             `json:"metric,omitempty"`
+	Op        string             `json:"op,omitempty"`
+}
+
+func (r *Rule) threshold() (*big.Int, error) {
+	threshold, ok := new(big.Int).SetString(r.Threshold, 10)
+	if !ok || threshold.Sign() < 0 {
+		return nil, ErrInvalidThreshold
+	}
+	return threshold, nil
+}
+
+// Validate checks the rule and fills the defaults of the optional fields.
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return ErrEmptyRuleName
+	}
+	if _, err := r.threshold(); err != nil {
+		return err
+	}
+	switch r.Kind {
+	case KindPeerCount, KindSnapshotStall, KindFilterBacklog:
+	case KindBalance:
+		if r.Address == nil {
+			return ErrMissingAddress
+		}
+		if r.TokenId == nil {
+			tokenId := ledger.ViteTokenId
+			r.TokenId = &tokenId
+		}
+	case KindMetric:
+		if r.Metric == "" {
+			return ErrMissingMetricName
+		}
+		if r.Op == "" {
+			r.Op = OpGreater
+		}
+		if r.Op != OpLess && r.Op != OpGreater {
+			return ErrUnknownOp
+		}
+	default:
+		return ErrUnknownRuleKind
+	}
+	return nil
+}
+
+// compare reports whether value breaks threshold with op.
+func compare(value, threshold *big.Int, op string) bool {
+	if op == OpLess {
+		return value.Cmp(threshold) < 0
+	}
+	return value.Cmp(threshold) > 0
+}
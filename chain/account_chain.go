@@ -9,6 +9,7 @@ import (
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/monitor"
+	"github.com/vitelabs/go-vite/trie"
 	"github.com/vitelabs/go-vite/vm_context"
 	"time"
 )
@@ -130,7 +131,7 @@ func (c *chain) InsertAccountBlocks(vmAccountBlocks []*vm_context.VmAccountBlock
 					c.log.Error("WriteSendBlockMeta failed, error is "+saveSendBlockMetaErr.Error(), "method", "InsertAccountBlocks")
 					return saveSendBlockMetaErr
 				}
-			} else if !c.IsGenesisAccountBlock(accountBlock) {
+			} else if !c.IsGenesisAccountBlock(accountBlock) && !c.isImportedStateBlock(accountBlock) {
 				err := errors.New(fmt.Sprintf("sendBlockMeta is nil, accountBlock is %+v\n, acccountBlockMeta is %+v\n", accountBlock, accountBlock.Meta))
 				c.log.Error(err.Error(), "method", "InsertAccountBlocks")
 				return err
@@ -191,6 +192,13 @@ func (c *chain) InsertAccountBlocks(vmAccountBlocks []*vm_context.VmAccountBlock
 		callback()
 	}
 
+	// Delete the pledge quotas changed, the leases of quota change the pledge quotas of both sides
+	if lastVmAccountBlock.AccountBlock.AccountAddress == types.AddressPledge || lastVmAccountBlock.AccountBlock.AccountAddress == types.AddressQuotaLease {
+		c.pledgeQuotaCache.Purge()
+	} else {
+		c.pledgeQuotaCache.DeleteAddr(lastVmAccountBlock.AccountBlock.AccountAddress)
+	}
+
 	// trigger writing success event
 	c.em.triggerInsertAccountBlocksSuccess(vmAccountBlocks)
 
@@ -283,6 +291,33 @@ func (c *chain) GetAccountBlocksByHeight(addr types.Address, start, count uint64
 	return blockList, nil
 }
 
+// GetAccountBlocksBySnapshotHash returns the latest blocks of addr from endHeight downwards which refer to
+// snapshotHash, in descending order of height. No block meta
+func (c *chain) GetAccountBlocksBySnapshotHash(addr *types.Address, endHeight uint64, snapshotHash *types.Hash) ([]*ledger.AccountBlock, error) {
+	monitorTags := []string{"chain", "GetAccountBlocksBySnapshotHash"}
+	defer monitor.LogTimerConsuming(monitorTags, time.Now())
+
+	account, gaErr := c.chainDb.Account.GetAccountByAddress(addr)
+	if gaErr != nil {
+		c.log.Error("Query account failed. Error is "+gaErr.Error(), "method", "GetAccountBlocksBySnapshotHash")
+		return nil, gaErr
+	}
+	if account == nil {
+		return nil, nil
+	}
+
+	blockList, gbErr := c.chainDb.Ac.GetBlocksBySnapshotHash(account.AccountId, endHeight, snapshotHash)
+	if gbErr != nil {
+		c.log.Error("Query block failed. Error is "+gbErr.Error(), "method", "GetAccountBlocksBySnapshotHash")
+		return nil, gbErr
+	}
+
+	for _, block := range blockList {
+		c.completeBlock(block, account)
+	}
+	return blockList, nil
+}
+
 // No block meta
 func (c *chain) GetAccountBlockMap(queryParams map[types.Address]*BlockMapQueryParam) map[types.Address][]*ledger.AccountBlock {
 	monitorTags := []string{"chain", "GetAccountBlockMap"}
@@ -342,7 +377,16 @@ func (c *chain) GetAccountBalance(addr *types.Address) (map[types.TokenTypeId]*b
 	if trie == nil {
 		return nil, nil
 	}
-	storageIterator := trie.NewIterator(vm_context.STORAGE_KEY_BALANCE)
+	balanceMap, err := getBalanceMap(trie)
+	if err != nil {
+		c.log.Error("types.BytesToTokenTypeId failed, error is "+err.Error(), "method", "GetAccountBalance")
+		return nil, err
+	}
+	return balanceMap, nil
+}
+
+func getBalanceMap(stateTrie *trie.Trie) (map[types.TokenTypeId]*big.Int, error) {
+	storageIterator := stateTrie.NewIterator(vm_context.STORAGE_KEY_BALANCE)
 	balanceMap := make(map[types.TokenTypeId]*big.Int)
 	prefixKeyLen := len(vm_context.STORAGE_KEY_BALANCE)
 	for {
@@ -354,7 +398,6 @@ func (c *chain) GetAccountBalance(addr *types.Address) (map[types.TokenTypeId]*b
 		tokenIdBytes := key[prefixKeyLen:]
 		tokenId, err := types.BytesToTokenTypeId(tokenIdBytes)
 		if err != nil {
-			c.log.Error("types.BytesToTokenTypeId failed, error is "+err.Error(), "method", "GetAccountBalance")
 			return nil, err
 		}
 
@@ -479,6 +522,30 @@ func (c *chain) GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlo
 	return block, nil
 }
 
+// GetAccountBlocksByDestinationTag returns the send blocks to addr carrying the destination tag, see ledger.DestinationTagPrefix.
+func (c *chain) GetAccountBlocksByDestinationTag(addr *types.Address, tag uint64, index, count int) ([]*ledger.AccountBlock, error) {
+	monitorTags := []string{"chain", "GetAccountBlocksByDestinationTag"}
+	defer monitor.LogTimerConsuming(monitorTags, time.Now())
+
+	hashList, err := c.chainDb.Ac.GetHashListByDestinationTag(addr, tag, index, count)
+	if err != nil {
+		c.log.Error("GetHashListByDestinationTag failed. Error is "+err.Error(), "method", "GetAccountBlocksByDestinationTag")
+		return nil, err
+	}
+
+	blocks := make([]*ledger.AccountBlock, 0, len(hashList))
+	for _, hash := range hashList {
+		block, err := c.GetAccountBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
 func (c *chain) GetAccountBlocksByAddress(addr *types.Address, index, num, count int) ([]*ledger.AccountBlock, error) {
 	monitorTags := []string{"chain", "GetAccountBlocksByAddress"}
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
@@ -695,6 +762,7 @@ func (c *chain) DeleteAccountBlocks(addr *types.Address, toHeight uint64) (map[t
 
 	// Delete cache
 	c.stateTriePool.Delete(needRemoveAddrList)
+	c.pledgeQuotaCache.Purge()
 
 	c.em.triggerDeleteAccountBlocksSuccess(subLedger)
 
@@ -256,6 +256,34 @@ func TestGetAccountBlocksByHeight(t *testing.T) {
 	//}
 }
 
+func TestGetAccountBlocksBySnapshotHash(t *testing.T) {
+	chainInstance := getChainInstance()
+	addr1, _, _ := types.CreateAddress()
+	addr2, _, _ := types.CreateAddress()
+	snapshotHash := chainInstance.GetGenesisSnapshotBlock().Hash
+	for i := 0; i < 10; i++ {
+		blocks, _, _ := randomSendViteBlock(snapshotHash, &addr1, &addr2)
+		chainInstance.InsertAccountBlocks(blocks)
+	}
+
+	blocks, err := chainInstance.GetAccountBlocksBySnapshotHash(&addr1, 10, &snapshotHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 10 || blocks[0].Height != 10 || blocks[9].Height != 1 {
+		t.Fatalf("unexpected blocks %+v", blocks)
+	}
+
+	otherHash := types.Hash{1}
+	blocks, err = chainInstance.GetAccountBlocksBySnapshotHash(&addr1, 10, &otherHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 0 {
+		t.Fatalf("no block refers to %v, but got %+v", otherHash, blocks)
+	}
+}
+
 func TestChain_GetAccountBlockMap(t *testing.T) {
 
 	chainInstance := getChainInstance()
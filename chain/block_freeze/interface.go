@@ -0,0 +1,11 @@
+package block_freeze
+
+import (
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type Chain interface {
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	ChainDb() *chain_db.ChainDb
+}
@@ -0,0 +1,184 @@
+package block_freeze
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/chain_db/freezer"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+const (
+	STOP  = 1
+	START = 2
+)
+
+const batchSize = 1000
+
+// Mover moves the account blocks confirmed more than freezeDays ago from leveldb to the freezer,
+// leaving the freezer pointers in their place. The keys stay in leveldb, so the account chain
+// is iterated as before while the leveldb values and compactions get small.
+type Mover struct {
+	chain   Chain
+	freezer *freezer.Freezer
+	log     log15.Logger
+
+	freezeHeight uint64
+
+	status     int
+	statusLock sync.Mutex
+	ticker     *time.Ticker
+	terminal   chan struct{}
+	wg         sync.WaitGroup
+}
+
+func NewMover(chain Chain, f *freezer.Freezer, freezeDays uint64) *Mover {
+	return &Mover{
+		chain:   chain,
+		freezer: f,
+		log:     log15.New("module", "block_freeze"),
+
+		freezeHeight: freezeDays * types.SnapshotDayHeight,
+
+		status: STOP,
+	}
+}
+
+func (m *Mover) Start() {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.status == START {
+		return
+	}
+
+	m.ticker = time.NewTicker(10 * time.Minute)
+	m.terminal = make(chan struct{})
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.moveAll()
+		for {
+			select {
+			case <-m.ticker.C:
+				m.moveAll()
+			case <-m.terminal:
+				return
+			}
+		}
+	}()
+
+	m.status = START
+}
+
+func (m *Mover) Stop() {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.status == STOP {
+		return
+	}
+
+	m.ticker.Stop()
+	close(m.terminal)
+	m.wg.Wait()
+	m.status = STOP
+}
+
+// moveAll walks through all the account chains batch by batch.
+func (m *Mover) moveAll() {
+	latestHeight := m.chain.GetLatestSnapshotBlock().Height
+	if latestHeight <= m.freezeHeight {
+		return
+	}
+	limitHeight := latestHeight - m.freezeHeight
+
+	start := []byte{database.DBKP_ACCOUNTBLOCK}
+	total := 0
+	for start != nil {
+		select {
+		case <-m.terminal:
+			return
+		default:
+		}
+
+		var count int
+		var err error
+		if start, count, err = m.moveBatch(start, limitHeight); err != nil {
+			m.log.Error("moveBatch failed, error is "+err.Error(), "method", "moveAll")
+			return
+		}
+		total += count
+	}
+	if total > 0 {
+		m.log.Info(fmt.Sprintf("froze %d account blocks confirmed before snapshot height %d", total, limitHeight), "method", "moveAll")
+	}
+}
+
+// moveBatch freezes up to batchSize account blocks from start which are confirmed at or below
+// limitHeight, it returns the start of the next batch, or nil if all the blocks are visited.
+func (m *Mover) moveBatch(start []byte, limitHeight uint64) ([]byte, int, error) {
+	chainDb := m.chain.ChainDb()
+
+	r := util.BytesPrefix([]byte{database.DBKP_ACCOUNTBLOCK})
+	r.Start = start
+	iter := chainDb.Db().NewIterator(r, nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	count := 0
+	ok := iter.First()
+	for ok && count < batchSize {
+		key := append([]byte{}, iter.Key()...)
+		value := iter.Value()
+		if database.FrozenPointer(value) != nil {
+			ok = iter.Next()
+			continue
+		}
+
+		hash, _ := types.BytesToHash(key[17:])
+		meta, err := chainDb.Ac.GetBlockMeta(&hash)
+		if err != nil {
+			return nil, 0, err
+		}
+		if meta == nil || meta.SnapshotHeight == 0 || meta.SnapshotHeight > limitHeight {
+			// the higher blocks of this account are confirmed later, go to the next account
+			accountId := binary.BigEndian.Uint64(key[1:9])
+			nextKey, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, accountId+1)
+			ok = iter.Seek(nextKey)
+			continue
+		}
+
+		pointer, err := m.freezer.Append(value)
+		if err != nil {
+			return nil, 0, err
+		}
+		batch.Put(key, database.EncodeFrozenValue(pointer.Bytes()))
+		count++
+		ok = iter.Next()
+	}
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, 0, err
+	}
+
+	var next []byte
+	if ok {
+		next = append([]byte{}, iter.Key()...)
+	}
+	if count == 0 {
+		return next, 0, nil
+	}
+
+	// the items must be durable before leveldb points to them
+	if err := m.freezer.Sync(); err != nil {
+		return nil, 0, err
+	}
+	if err := chainDb.Commit(batch); err != nil {
+		return nil, 0, err
+	}
+	return next, count, nil
+}
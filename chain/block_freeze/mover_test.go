@@ -0,0 +1,89 @@
+package block_freeze
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/chain_db/freezer"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type mockChain struct {
+	chainDb *chain_db.ChainDb
+	latest  *ledger.SnapshotBlock
+}
+
+func (c *mockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock { return c.latest }
+func (c *mockChain) ChainDb() *chain_db.ChainDb                    { return c.chainDb }
+
+func TestMover_moveAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "block_freeze")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chainDb := chain_db.NewChainDb(filepath.Join(dir, "ledger"))
+	defer chainDb.Db().Close()
+	f, err := freezer.NewFreezer(filepath.Join(dir, "freezer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	chainDb.Ac.SetFreezer(f)
+
+	chain := &mockChain{chainDb: chainDb, latest: &ledger.SnapshotBlock{Height: 2 * types.SnapshotDayHeight}}
+	now := time.Now()
+	batch := new(leveldb.Batch)
+	var blocks []*ledger.AccountBlock
+	// account 1 has blocks confirmed at 1, 2 and the latest snapshot height, account 2 at 1
+	for i, accountId := range []uint64{1, 1, 1, 2} {
+		addr, _, _ := types.CreateAddress()
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Height:         uint64(i + 1),
+			AccountAddress: addr,
+			Amount:         big.NewInt(int64(i)),
+			Timestamp:      &now,
+		}
+		block.Hash = block.ComputeHash()
+		blocks = append(blocks, block)
+		chainDb.Ac.WriteBlock(batch, accountId, block)
+		chainDb.Ac.WriteBlockMeta(batch, &block.Hash, &ledger.AccountBlockMeta{AccountId: accountId, Height: block.Height})
+	}
+	chainDb.Ac.WriteBeSnapshot(batch, &blocks[0].Hash, 1)
+	chainDb.Ac.WriteBeSnapshot(batch, &blocks[1].Hash, 2)
+	chainDb.Ac.WriteBeSnapshot(batch, &blocks[2].Hash, chain.latest.Height)
+	chainDb.Ac.WriteBeSnapshot(batch, &blocks[3].Hash, 1)
+	if err := chainDb.Commit(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMover(chain, f, 1)
+	m.terminal = make(chan struct{})
+	m.moveAll()
+
+	for i, block := range blocks {
+		accountId := uint64(1)
+		if i == 3 {
+			accountId = 2
+		}
+		key, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, accountId, block.Height, block.Hash.Bytes())
+		value, _ := chainDb.Db().Get(key, nil)
+		if frozen := database.FrozenPointer(value) != nil; frozen != (i != 2) {
+			t.Errorf("block %d frozen is %v", i, frozen)
+		}
+		got, err := chainDb.Ac.GetBlock(&block.Hash)
+		if err != nil || got == nil || got.Amount.Cmp(block.Amount) != 0 {
+			t.Fatalf("GetBlock failed, %v %v", got, err)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package body_compress
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+const (
+	STOP  = 1
+	START = 2
+)
+
+const batchSize = 1000
+
+var (
+	// confirmedHeights is how deep a block must be confirmed before it is rewritten, so that
+	// the migration never races with a rollback deleting the same block.
+	confirmedHeights = types.SnapshotHourHeight
+
+	migrationDoneValue = []byte{}
+)
+
+// Migrator compresses the account blocks and the vm log lists written before compression was
+// enabled, in the background and in batches. The progress is kept in the chain db, so it
+// continues from where it stopped on restart. The blocks which are not confirmed deep enough
+// when they are visited are left as they are, they are readable either way.
+type Migrator struct {
+	chain Chain
+	log   log15.Logger
+
+	status     int
+	statusLock sync.Mutex
+	terminal   chan struct{}
+	wg         sync.WaitGroup
+}
+
+func NewMigrator(chain Chain) *Migrator {
+	return &Migrator{
+		chain:  chain,
+		log:    log15.New("module", "body_compress"),
+		status: STOP,
+	}
+}
+
+func (m *Migrator) Start() {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.status == START {
+		return
+	}
+
+	m.terminal = make(chan struct{})
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := m.migrate(); err != nil {
+			m.log.Error("migrate failed, error is "+err.Error(), "method", "Start")
+		}
+	}()
+
+	m.status = START
+}
+
+func (m *Migrator) Stop() {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+	if m.status == STOP {
+		return
+	}
+
+	close(m.terminal)
+	m.wg.Wait()
+	m.status = STOP
+}
+
+func (m *Migrator) cursorKey() []byte {
+	key, _ := database.EncodeKey(database.DBKP_COMPRESS_MIGRATION)
+	return key
+}
+
+// cursor returns the last migrated account block key, done is set once the migration is finished.
+func (m *Migrator) cursor() (cursor []byte, done bool, err error) {
+	value, err := m.chain.ChainDb().Db().Get(m.cursorKey(), nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return value, len(value) == 0, nil
+}
+
+func (m *Migrator) migrate() error {
+	cursor, done, err := m.cursor()
+	if err != nil || done {
+		return err
+	}
+	m.log.Info("start compressing account blocks and vm logs", "method", "migrate")
+
+	start := time.Now()
+	total := 0
+	for {
+		select {
+		case <-m.terminal:
+			return nil
+		default:
+		}
+
+		var count int
+		cursor, count, err = m.migrateBatch(cursor)
+		if err != nil {
+			return err
+		}
+		total += count
+		if cursor == nil {
+			m.log.Info(fmt.Sprintf("compressed %d account blocks in %s", total, time.Since(start)), "method", "migrate")
+			return nil
+		}
+	}
+}
+
+// migrateBatch compresses up to batchSize account blocks after cursor and the vm log lists of them,
+// it returns the cursor of the next batch, or nil if there are no more blocks.
+func (m *Migrator) migrateBatch(cursor []byte) ([]byte, int, error) {
+	chainDb := m.chain.ChainDb()
+	db := chainDb.Db()
+
+	latestHeight := m.chain.GetLatestSnapshotBlock().Height
+
+	r := util.BytesPrefix([]byte{database.DBKP_ACCOUNTBLOCK})
+	if cursor != nil {
+		r.Start = append(append([]byte{}, cursor...), 0)
+	}
+	iter := db.NewIterator(r, nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	count := 0
+	var lastKey []byte
+	for i := 0; i < batchSize && iter.Next(); i++ {
+		lastKey = append([]byte{}, iter.Key()...)
+		value := iter.Value()
+		if database.IsEncodedValue(value) {
+			continue
+		}
+
+		hash, _ := types.BytesToHash(lastKey[17:])
+		meta, err := chainDb.Ac.GetBlockMeta(&hash)
+		if err != nil {
+			return nil, 0, err
+		}
+		if meta == nil || meta.SnapshotHeight == 0 || meta.SnapshotHeight+confirmedHeights > latestHeight {
+			continue
+		}
+
+		block := &ledger.AccountBlock{}
+		if err := block.DbDeserialize(value); err != nil {
+			return nil, 0, err
+		}
+		batch.Put(lastKey, database.EncodeValue(value, true))
+		count++
+
+		if block.LogHash != nil {
+			if err := m.migrateVmLogList(batch, block.LogHash); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, 0, err
+	}
+
+	if lastKey == nil {
+		batch.Put(m.cursorKey(), migrationDoneValue)
+	} else {
+		batch.Put(m.cursorKey(), lastKey)
+	}
+	if err := chainDb.Commit(batch); err != nil {
+		return nil, 0, err
+	}
+	return lastKey, count, nil
+}
+
+func (m *Migrator) migrateVmLogList(batch *leveldb.Batch, logHash *types.Hash) error {
+	key, _ := database.EncodeKey(database.DBKP_LOG_LIST, logHash.Bytes())
+	value, err := m.chain.ChainDb().KeyspaceDb(database.DBKP_LOG_LIST).Get(key, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if !database.IsEncodedValue(value) {
+		batch.Put(key, database.EncodeValue(value, true))
+	}
+	return nil
+}
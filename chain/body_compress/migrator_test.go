@@ -0,0 +1,91 @@
+package body_compress
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type mockChain struct {
+	chainDb *chain_db.ChainDb
+	latest  *ledger.SnapshotBlock
+}
+
+func (c *mockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock { return c.latest }
+func (c *mockChain) ChainDb() *chain_db.ChainDb                    { return c.chainDb }
+
+func TestMigrator_migrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "body_compress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chainDb := chain_db.NewChainDb(dir)
+	defer chainDb.Db().Close()
+	chain := &mockChain{chainDb: chainDb, latest: &ledger.SnapshotBlock{Height: 2 * confirmedHeights}}
+
+	addr, _, _ := types.CreateAddress()
+	logList := ledger.VmLogList{{Data: []byte("log")}}
+	now := time.Now()
+	var blocks []*ledger.AccountBlock
+	batch := new(leveldb.Batch)
+	for i := uint64(1); i <= 3; i++ {
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Height:         i,
+			AccountAddress: addr,
+			Amount:         big.NewInt(0),
+			Timestamp:      &now,
+			LogHash:        logList.Hash(),
+		}
+		block.Hash = block.ComputeHash()
+		blocks = append(blocks, block)
+		chainDb.Ac.WriteBlock(batch, 1, block)
+		chainDb.Ac.WriteBlockMeta(batch, &block.Hash, &ledger.AccountBlockMeta{AccountId: 1, Height: i})
+	}
+	chainDb.Ac.WriteVmLogList(batch, logList)
+	// the last block is confirmed too recently to be migrated
+	chainDb.Ac.WriteBeSnapshot(batch, &blocks[0].Hash, 1)
+	chainDb.Ac.WriteBeSnapshot(batch, &blocks[1].Hash, 1)
+	chainDb.Ac.WriteBeSnapshot(batch, &blocks[2].Hash, chain.latest.Height)
+	if err := chainDb.Commit(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMigrator(chain)
+	m.terminal = make(chan struct{})
+	if err := m.migrate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, done, _ := m.cursor(); !done {
+		t.Fatal("migration should be done")
+	}
+
+	for i, block := range blocks {
+		key, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, uint64(1), block.Height, block.Hash.Bytes())
+		value, _ := chainDb.Db().Get(key, nil)
+		if compressed := database.IsEncodedValue(value); compressed != (i < 2) {
+			t.Errorf("block %d compressed is %v", block.Height, compressed)
+		}
+		got, err := chainDb.Ac.GetBlock(&block.Hash)
+		if err != nil || got == nil || got.Height != block.Height {
+			t.Fatalf("GetBlock failed, %v %v", got, err)
+		}
+	}
+	key, _ := database.EncodeKey(database.DBKP_LOG_LIST, logList.Hash().Bytes())
+	if value, _ := chainDb.Db().Get(key, nil); !database.IsEncodedValue(value) {
+		t.Error("vm log list should be compressed")
+	}
+	if got, err := chainDb.Ac.GetVmLogList(logList.Hash()); err != nil || len(got) != 1 {
+		t.Fatalf("GetVmLogList failed, %v %v", got, err)
+	}
+}
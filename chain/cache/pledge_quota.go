@@ -0,0 +1,139 @@
+package chain_cache
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/metrics"
+)
+
+var (
+	pledgeQuotaCacheHit  = metrics.GetOrRegisterCounter("chain/pledgeQuotaCache/hit", nil)
+	pledgeQuotaCacheMiss = metrics.GetOrRegisterCounter("chain/pledgeQuotaCache/miss", nil)
+)
+
+// DefaultPledgeQuotaCacheSize is the count of the pledge quotas the chain caches
+const DefaultPledgeQuotaCacheSize = 10000
+
+type pledgeQuotaKey struct {
+	addr         types.Address
+	snapshotHash types.Hash
+}
+
+// PledgeQuotaCache caches the pledge quotas of the addresses at snapshot blocks, so that the onroad workers
+// and the rpc don't walk the prev blocks of an address again and again. It holds at most size quotas, the
+// least recently used are evicted.
+//
+// A quota depends on the latest account block of the address, so the quotas of an address are deleted when its
+// account blocks are inserted. All the quotas are purged when a snapshot block is inserted, when the pledge
+// contract is written and when the blocks are rolled back. The quotas are added with the generation got before
+// the calculation like SnapshotBlockCache does, so that a quota calculated before an invalidation is dropped.
+type PledgeQuotaCache struct {
+	lock       sync.Mutex
+	quotas     *simplelru.LRU
+	addrKeys   map[types.Address]map[types.Hash]struct{}
+	generation uint64
+
+	hits   uint64
+	misses uint64
+}
+
+func NewPledgeQuotaCache(size int) *PledgeQuotaCache {
+	cache := &PledgeQuotaCache{
+		addrKeys: make(map[types.Address]map[types.Hash]struct{}),
+	}
+	cache.quotas, _ = simplelru.NewLRU(size, func(key interface{}, value interface{}) {
+		cache.deleteKey(key.(pledgeQuotaKey))
+	})
+	return cache
+}
+
+// Generation returns the generation to add the quotas calculated from now on with.
+func (cache *PledgeQuotaCache) Generation() uint64 {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	return cache.generation
+}
+
+// Add adds the quota of addr at snapshotHash calculated at generation, it's dropped if an invalidation
+// happened since.
+func (cache *PledgeQuotaCache) Add(snapshotHash types.Hash, addr types.Address, quota uint64, generation uint64) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if generation != cache.generation {
+		return
+	}
+	key := pledgeQuotaKey{addr, snapshotHash}
+	cache.quotas.Add(key, quota)
+	hashes, ok := cache.addrKeys[addr]
+	if !ok {
+		hashes = make(map[types.Hash]struct{})
+		cache.addrKeys[addr] = hashes
+	}
+	hashes[snapshotHash] = struct{}{}
+}
+
+func (cache *PledgeQuotaCache) Get(snapshotHash types.Hash, addr types.Address) (uint64, bool) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if value, ok := cache.quotas.Get(pledgeQuotaKey{addr, snapshotHash}); ok {
+		cache.hit()
+		return value.(uint64), true
+	}
+	cache.miss()
+	return 0, false
+}
+
+// DeleteAddr removes the quotas of addr, it's called after the account blocks of addr are inserted.
+func (cache *PledgeQuotaCache) DeleteAddr(addr types.Address) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	cache.generation++
+	for snapshotHash := range cache.addrKeys[addr] {
+		cache.quotas.Remove(pledgeQuotaKey{addr, snapshotHash})
+	}
+}
+
+func (cache *PledgeQuotaCache) Purge() {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	cache.generation++
+	cache.quotas.Purge()
+}
+
+func (cache *PledgeQuotaCache) Len() int {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	return cache.quotas.Len()
+}
+
+// HitRate returns the rate of the lookups found in the cache, the lookups counted from the start.
+func (cache *PledgeQuotaCache) HitRate() float64 {
+	hits := atomic.LoadUint64(&cache.hits)
+	total := hits + atomic.LoadUint64(&cache.misses)
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+func (cache *PledgeQuotaCache) deleteKey(key pledgeQuotaKey) {
+	if hashes, ok := cache.addrKeys[key.addr]; ok {
+		delete(hashes, key.snapshotHash)
+		if len(hashes) == 0 {
+			delete(cache.addrKeys, key.addr)
+		}
+	}
+}
+
+func (cache *PledgeQuotaCache) hit() {
+	atomic.AddUint64(&cache.hits, 1)
+	pledgeQuotaCacheHit.Inc(1)
+}
+
+func (cache *PledgeQuotaCache) miss() {
+	atomic.AddUint64(&cache.misses, 1)
+	pledgeQuotaCacheMiss.Inc(1)
+}
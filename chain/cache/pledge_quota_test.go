@@ -0,0 +1,41 @@
+package chain_cache
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+func TestPledgeQuotaCache(t *testing.T) {
+	cache := NewPledgeQuotaCache(3)
+	addr1, addr2 := types.Address{1}, types.Address{2}
+	for i := byte(1); i <= 3; i++ {
+		cache.Add(types.Hash{i}, addr1, uint64(i), cache.Generation())
+	}
+	cache.Add(types.Hash{1}, addr2, 100, cache.Generation())
+	if _, ok := cache.Get(types.Hash{1}, addr1); cache.Len() != 3 || ok {
+		t.Fatal("the least recently used quota should be evicted")
+	}
+	if q, ok := cache.Get(types.Hash{2}, addr1); !ok || q != 2 {
+		t.Fatalf("unexpected quota %v, %v", q, ok)
+	}
+
+	// a quota calculated before the invalidation is dropped
+	generation := cache.Generation()
+	cache.DeleteAddr(addr1)
+	cache.Add(types.Hash{3}, addr1, 3, generation)
+	if _, ok := cache.Get(types.Hash{3}, addr1); ok || cache.Len() != 1 {
+		t.Fatal("the quotas of the address should be deleted")
+	}
+	if q, ok := cache.Get(types.Hash{1}, addr2); !ok || q != 100 {
+		t.Fatal("the quotas of other addresses should be kept")
+	}
+
+	cache.Purge()
+	if _, ok := cache.Get(types.Hash{1}, addr2); ok || cache.Len() != 0 || len(cache.addrKeys) != 0 {
+		t.Fatal("the cache should be empty after purge")
+	}
+	if rate := cache.HitRate(); rate <= 0 || rate >= 1 {
+		t.Fatalf("unexpected hit rate %v", rate)
+	}
+}
@@ -0,0 +1,145 @@
+package chain_cache
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/metrics"
+)
+
+var (
+	snapshotBlockCacheHit  = metrics.GetOrRegisterCounter("chain/snapshotBlockCache/hit", nil)
+	snapshotBlockCacheMiss = metrics.GetOrRegisterCounter("chain/snapshotBlockCache/miss", nil)
+)
+
+// DefaultSnapshotBlockCacheSize is the count of the snapshot block heads the chain caches, about six hours of blocks
+const DefaultSnapshotBlockCacheSize = 20000
+
+// SnapshotBlockCache caches the heads of the recently read snapshot blocks by height and hash, so that the
+// lookups of quota calculation, verification and consensus don't read the db again and again. It holds at
+// most size heads without snapshot content, the least recently used are evicted. The heads in the cache are
+// shared and must not be modified, the getters return copies.
+//
+// The blocks read from the db are added with the generation got before the read, DeleteFrom bumps the
+// generation, so that a block read before a rollback is never added after it.
+type SnapshotBlockCache struct {
+	lock       sync.Mutex
+	blocks     *simplelru.LRU
+	heights    map[types.Hash]uint64
+	generation uint64
+
+	hits   uint64
+	misses uint64
+}
+
+func NewSnapshotBlockCache(size int) *SnapshotBlockCache {
+	cache := &SnapshotBlockCache{
+		heights: make(map[types.Hash]uint64),
+	}
+	cache.blocks, _ = simplelru.NewLRU(size, func(key interface{}, value interface{}) {
+		delete(cache.heights, value.(*ledger.SnapshotBlock).Hash)
+	})
+	return cache
+}
+
+// Generation returns the generation to add the blocks read from now on with.
+func (cache *SnapshotBlockCache) Generation() uint64 {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	return cache.generation
+}
+
+// Add adds the head of block read at generation, it's dropped if a rollback happened since.
+func (cache *SnapshotBlockCache) Add(block *ledger.SnapshotBlock, generation uint64) {
+	if block == nil {
+		return
+	}
+	head := *block
+	head.SnapshotContent = nil
+	head.StateTrie = nil
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if generation != cache.generation {
+		return
+	}
+	if value, ok := cache.blocks.Peek(head.Height); ok {
+		delete(cache.heights, value.(*ledger.SnapshotBlock).Hash)
+	}
+	cache.blocks.Add(head.Height, &head)
+	cache.heights[head.Hash] = head.Height
+}
+
+func (cache *SnapshotBlockCache) GetByHeight(height uint64) *ledger.SnapshotBlock {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if value, ok := cache.blocks.Get(height); ok {
+		cache.hit()
+		head := *value.(*ledger.SnapshotBlock)
+		return &head
+	}
+	cache.miss()
+	return nil
+}
+
+func (cache *SnapshotBlockCache) GetByHash(hash *types.Hash) *ledger.SnapshotBlock {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if height, ok := cache.heights[*hash]; ok {
+		if value, ok := cache.blocks.Get(height); ok {
+			cache.hit()
+			head := *value.(*ledger.SnapshotBlock)
+			return &head
+		}
+	}
+	cache.miss()
+	return nil
+}
+
+// DeleteFrom removes the blocks from height upwards, it's called after they are rolled back.
+func (cache *SnapshotBlockCache) DeleteFrom(height uint64) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	cache.generation++
+	for _, key := range cache.blocks.Keys() {
+		if key.(uint64) >= height {
+			cache.blocks.Remove(key)
+		}
+	}
+}
+
+func (cache *SnapshotBlockCache) Purge() {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	cache.generation++
+	cache.blocks.Purge()
+}
+
+func (cache *SnapshotBlockCache) Len() int {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	return cache.blocks.Len()
+}
+
+// HitRate returns the rate of the lookups found in the cache, the lookups counted from the start.
+func (cache *SnapshotBlockCache) HitRate() float64 {
+	hits := atomic.LoadUint64(&cache.hits)
+	total := hits + atomic.LoadUint64(&cache.misses)
+	if total == 0 {
+		return 0
+	}
+	return float64(hits) / float64(total)
+}
+
+func (cache *SnapshotBlockCache) hit() {
+	atomic.AddUint64(&cache.hits, 1)
+	snapshotBlockCacheHit.Inc(1)
+}
+
+func (cache *SnapshotBlockCache) miss() {
+	atomic.AddUint64(&cache.misses, 1)
+	snapshotBlockCacheMiss.Inc(1)
+}
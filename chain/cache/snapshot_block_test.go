@@ -0,0 +1,57 @@
+package chain_cache
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func newTestSnapshotBlock(height uint64) *ledger.SnapshotBlock {
+	return &ledger.SnapshotBlock{
+		Hash:            types.Hash{byte(height)},
+		Height:          height,
+		SnapshotContent: ledger.SnapshotContent{},
+	}
+}
+
+func TestSnapshotBlockCache(t *testing.T) {
+	cache := NewSnapshotBlockCache(3)
+	for i := uint64(1); i <= 4; i++ {
+		cache.Add(newTestSnapshotBlock(i), cache.Generation())
+	}
+	if cache.Len() != 3 || cache.GetByHeight(1) != nil || cache.GetByHash(&types.Hash{1}) != nil {
+		t.Fatal("the least recently used block should be evicted")
+	}
+	block := cache.GetByHash(&types.Hash{2})
+	if block == nil || block.Height != 2 || block.SnapshotContent != nil {
+		t.Fatalf("unexpected block %+v", block)
+	}
+
+	// a block read before the rollback is dropped
+	generation := cache.Generation()
+	cache.DeleteFrom(3)
+	cache.Add(newTestSnapshotBlock(3), generation)
+	if cache.GetByHeight(3) != nil || cache.GetByHeight(4) != nil || cache.GetByHash(&types.Hash{4}) != nil {
+		t.Fatal("the blocks rolled back should be deleted")
+	}
+	if cache.GetByHeight(2) == nil {
+		t.Fatal("the blocks below the rollback should be kept")
+	}
+
+	// a new block at a height replaces the hash of the old one
+	forked := newTestSnapshotBlock(2)
+	forked.Hash = types.Hash{100}
+	cache.Add(forked, cache.Generation())
+	if cache.GetByHash(&types.Hash{2}) != nil || cache.GetByHash(&types.Hash{100}) == nil {
+		t.Fatal("the hash of the replaced block should be removed")
+	}
+
+	cache.Purge()
+	if cache.Len() != 0 || cache.GetByHash(&types.Hash{100}) != nil {
+		t.Fatal("the cache should be empty after purge")
+	}
+	if rate := cache.HitRate(); rate <= 0 || rate >= 1 {
+		t.Fatalf("unexpected hit rate %v", rate)
+	}
+}
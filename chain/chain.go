@@ -4,11 +4,17 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain/block_freeze"
+	"github.com/vitelabs/go-vite/chain/body_compress"
 	"github.com/vitelabs/go-vite/chain/cache"
 	"github.com/vitelabs/go-vite/chain/index"
+	"github.com/vitelabs/go-vite/chain/log_archive"
 	"github.com/vitelabs/go-vite/chain/sender"
 	"github.com/vitelabs/go-vite/chain/trie_gc"
 	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/chain_db/freezer"
+	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/compress"
 	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/ledger"
@@ -32,7 +38,9 @@ type chain struct {
 
 	createAccountLock sync.Mutex
 
-	needSnapshotCache *chain_cache.NeedSnapshotCache
+	needSnapshotCache  *chain_cache.NeedSnapshotCache
+	snapshotBlockCache *chain_cache.SnapshotBlockCache
+	pledgeQuotaCache   *chain_cache.PledgeQuotaCache
 
 	genesisSnapshotBlock *ledger.SnapshotBlock
 	latestSnapshotBlock  *ledger.SnapshotBlock
@@ -54,6 +62,13 @@ type chain struct {
 
 	saList *chain_cache.AdditionList
 	fti    *chain_index.FilterTokenIndex
+
+	logArchive *log_archive.LogArchive
+
+	compressMigrator *body_compress.Migrator
+
+	freezer     *freezer.Freezer
+	freezeMover *block_freeze.Mover
 }
 
 func NewChain(cfg *config.Config) Chain {
@@ -80,12 +95,18 @@ func NewChain(cfg *config.Config) Chain {
 	}
 
 	chain.needSnapshotCache = chain_cache.NewNeedSnapshotCache(chain)
+	chain.snapshotBlockCache = chain_cache.NewSnapshotBlockCache(chain_cache.DefaultSnapshotBlockCacheSize)
+	chain.pledgeQuotaCache = chain_cache.NewPledgeQuotaCache(chain_cache.DefaultPledgeQuotaCacheSize)
 	chain.blackBlock = NewBlackBlock(chain, chain.cfg.OpenBlackBlock)
 
 	// set ledger GenesisAccountAddress
 	ledger.GenesisAccountAddress = cfg.Genesis.GenesisAccountAddress
 
 	initGenesis(cfg.Genesis)
+
+	if chain.cfg.HistoryHeight > 0 {
+		return newHistoryChain(chain, chain.cfg.HistoryHeight)
+	}
 	return chain
 }
 
@@ -104,20 +125,56 @@ func (c *chain) Init() {
 	if chainDb == nil {
 		c.log.Crit("NewChain failed, db init failed", "method", "Init")
 	}
+	// the keyspaces split are kept split even if the option is turned off, they are merged back by gvite ledger keyspaces
+	if c.cfg.SplitKeyspaces {
+		if err := chainDb.SplitKeyspaces(); err != nil {
+			c.log.Crit("chainDb.SplitKeyspaces failed, error is "+err.Error(), "method", "Init")
+		}
+	}
+	chainDb.Ac.SetCompress(c.cfg.CompressBlockBody)
 	c.chainDb = chainDb
 
+	// freezer, it is opened even if freezing is disabled because the frozen blocks are still read from it
+	var err error
+	c.freezer, err = freezer.NewFreezer(filepath.Join(c.dataDir, "ledger_freezer"))
+	if err != nil {
+		c.log.Crit("freezer.NewFreezer failed, error is "+err.Error(), "method", "Init")
+	}
+	chainDb.Ac.SetFreezer(c.freezer)
+
 	// cache
 	c.initCache()
 
 	// saList
-	var err error
 	c.saList, err = chain_cache.NewAdditionList(c)
 	if err != nil {
 		c.log.Crit("chain_cache.NewAdditionList failed, error is "+err.Error(), "method", "Init")
 	}
 
 	// trie gc
-	c.trieGc = trie_gc.NewCollector(c, c.cfg.LedgerGcRetain)
+	ledgerGcRetain := c.cfg.LedgerGcRetain
+	if c.cfg.LedgerGcRetainDays > 0 {
+		ledgerGcRetain = c.cfg.LedgerGcRetainDays * types.SnapshotDayHeight
+	}
+	c.trieGc = trie_gc.NewCollector(c, ledgerGcRetain, c.cfg.LedgerGcCheckpointInterval)
+
+	// log archive
+	if c.cfg.VmLogRetainDays > 0 {
+		c.logArchive, err = log_archive.NewLogArchive(c, filepath.Join(c.dataDir, "ledger_log_archive"), c.cfg.VmLogRetainDays)
+		if err != nil {
+			c.log.Crit("log_archive.NewLogArchive failed, error is "+err.Error(), "method", "Init")
+		}
+	}
+
+	// compress migrator
+	if c.cfg.CompressBlockBody {
+		c.compressMigrator = body_compress.NewMigrator(c)
+	}
+
+	// freeze mover
+	if c.cfg.BlockFreezeDays > 0 {
+		c.freezeMover = block_freeze.NewMover(c, c.freezer, c.cfg.BlockFreezeDays)
+	}
 
 	// compressor
 	compressor := compress.NewCompressor(c, c.dataDir)
@@ -173,7 +230,7 @@ func (c *chain) checkForkPoints() (bool, *config.ForkPoint, error) {
 
 	for k := 0; k < t.NumField(); k++ {
 		forkPoint := v.Field(k).Interface().(*config.ForkPoint)
-		if forkPoint.Height > 0 && forkPoint.Hash != nil && forkPoint.Height <= latestSnapshotHeight {
+		if forkPoint != nil && forkPoint.Height > 0 && forkPoint.Hash != nil && forkPoint.Height <= latestSnapshotHeight {
 			blockPoint, err := c.GetSnapshotBlockByHash(forkPoint.Hash)
 			if err != nil {
 				return false, nil, err
@@ -294,6 +351,12 @@ func (c *chain) initCache() {
 	// needSnapshotCache
 	c.needSnapshotCache.Build()
 
+	// snapshotBlockCache
+	c.snapshotBlockCache.Purge()
+
+	// pledgeQuotaCache
+	c.pledgeQuotaCache.Purge()
+
 	// trieNodePool
 	c.trieNodePool = trie.NewTrieNodePool()
 }
@@ -356,6 +419,21 @@ func (c *chain) Start() {
 		fmt.Printf("FilterTokenIndex initialization complete\n")
 	}
 
+	// log archive
+	if c.logArchive != nil {
+		c.logArchive.Start()
+	}
+
+	// compress migrator
+	if c.compressMigrator != nil {
+		c.compressMigrator.Start()
+	}
+
+	// freeze mover
+	if c.freezeMover != nil {
+		c.freezeMover.Start()
+	}
+
 	c.log.Info("Chain module started")
 }
 
@@ -368,6 +446,21 @@ func (c *chain) Stop() {
 	// saList top
 	c.saList.Stop()
 
+	// log archive
+	if c.logArchive != nil {
+		c.logArchive.Stop()
+	}
+
+	// compress migrator
+	if c.compressMigrator != nil {
+		c.compressMigrator.Stop()
+	}
+
+	// freeze mover
+	if c.freezeMover != nil {
+		c.freezeMover.Stop()
+	}
+
 	// trie gc
 	if c.cfg.LedgerGc {
 		c.TrieGc().Stop()
@@ -392,7 +485,7 @@ func (c *chain) Destroy() {
 	c.stateTriePool = nil
 
 	// close db
-	c.chainDb.Db().Close()
+	c.chainDb.Close()
 	c.chainDb = nil
 
 	// compressor
@@ -407,6 +500,20 @@ func (c *chain) Destroy() {
 	// kafka sender
 	c.kafkaSender = nil
 
+	// log archive
+	if c.logArchive != nil {
+		c.logArchive.Destroy()
+		c.logArchive = nil
+	}
+
+	// compress migrator
+	c.compressMigrator = nil
+
+	// freezer
+	c.freezeMover = nil
+	c.freezer.Close()
+	c.freezer = nil
+
 	c.log.Info("Chain module destroyed")
 }
 func (c *chain) TrieGc() trie_gc.Collector {
@@ -414,5 +521,5 @@ func (c *chain) TrieGc() trie_gc.Collector {
 }
 
 func (c *chain) TrieDb() *leveldb.DB {
-	return c.ChainDb().Db()
+	return c.ChainDb().KeyspaceDb(database.DBKP_TRIE_NODE)
 }
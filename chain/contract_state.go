@@ -0,0 +1,198 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	"github.com/vitelabs/go-vite/vm_context"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+)
+
+var ErrImportDisabled = errors.New("the contract state can only be imported into a dev chain, start the node with --devmining")
+
+// ContractState is the complete storage of a contract at a snapshot block, balances and code included. It is
+// exported from a node of the live net to reproduce the state of a contract on a dev chain.
+type ContractState struct {
+	Address        types.Address          `json:"address"`
+	SnapshotHeight uint64                 `json:"snapshotHeight"`
+	SnapshotHash   types.Hash             `json:"snapshotHash"`
+	StateHash      types.Hash             `json:"stateHash"`
+	Storage        []*ContractStorageItem `json:"storage"`
+}
+
+type ContractStorageItem struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// Trie returns the state trie of the storage, which must have the hash of the exported state
+func (state *ContractState) Trie() (*trie.Trie, error) {
+	stateTrie := trie.NewTrie(nil, nil, nil)
+	for _, item := range state.Storage {
+		stateTrie.SetValue(item.Key, item.Value)
+	}
+	if stateTrie.Hash() == nil || *stateTrie.Hash() != state.StateHash {
+		return nil, fmt.Errorf("the storage of contract %s doesn't match the state hash %s", state.Address, state.StateHash)
+	}
+	return stateTrie, nil
+}
+
+func WriteContractState(w io.Writer, state *ContractState) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(state)
+}
+
+// ReadContractState reads a contract state written by WriteContractState and checks its storage
+func ReadContractState(r io.Reader) (*ContractState, error) {
+	state := &ContractState{}
+	if err := json.NewDecoder(r).Decode(state); err != nil {
+		return nil, err
+	}
+	if _, err := state.Trie(); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ExportContractState writes the storage of the contract at the snapshot block of snapshotHeight to fileName.
+// The state of a past snapshot block is kept only if the ledger gc is disabled.
+func (c *chain) ExportContractState(addr *types.Address, snapshotHeight uint64, fileName string) (*ContractState, error) {
+	snapshotBlock, err := c.GetSnapshotBlockByHeight(snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if snapshotBlock == nil {
+		return nil, fmt.Errorf("snapshot block %d doesn't exist", snapshotHeight)
+	}
+	if c.trieGc.IsPruned(snapshotHeight) {
+		return nil, vm_context.ErrStatePruned
+	}
+	if ok, err := c.ShallowCheckStateTrie(&snapshotBlock.StateHash); err != nil || !ok {
+		return nil, vm_context.ErrStatePruned
+	}
+
+	stateHashBytes := c.GetStateTrie(&snapshotBlock.StateHash).GetValue(addr.Bytes())
+	if len(stateHashBytes) == 0 {
+		return nil, fmt.Errorf("contract %s has no state at snapshot block %d", addr, snapshotHeight)
+	}
+	stateHash, err := types.BytesToHash(stateHashBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ContractState{
+		Address:        *addr,
+		SnapshotHeight: snapshotBlock.Height,
+		SnapshotHash:   snapshotBlock.Hash,
+		StateHash:      stateHash,
+	}
+	iterator := c.GetStateTrie(&stateHash).NewIterator(nil)
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		state.Storage = append(state.Storage, &ContractStorageItem{Key: key, Value: value})
+	}
+	// the trie iterator walks the children of a full node in no particular order
+	sort.Slice(state.Storage, func(i, j int) bool {
+		return bytes.Compare(state.Storage[i].Key, state.Storage[j].Key) < 0
+	})
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if err := WriteContractState(file, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ImportContractState replaces the storage of a contract of the dev chain with the contract state in fileName,
+// by an account block received from no send block. The contract must have been created on the dev chain, the
+// block is snapshotted by the next snapshot block mined.
+func (c *chain) ImportContractState(fileName string) (*ledger.AccountBlock, error) {
+	if c.globalCfg.Producer == nil || !c.globalCfg.DevMining {
+		return nil, ErrImportDisabled
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	state, err := ReadContractState(file)
+	if err != nil {
+		return nil, err
+	}
+
+	prevBlock, err := c.GetLatestAccountBlock(&state.Address)
+	if err != nil {
+		return nil, err
+	}
+	if prevBlock == nil {
+		return nil, fmt.Errorf("contract %s doesn't exist on the dev chain, create it first", state.Address)
+	}
+	snapshotBlock := c.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(c, &snapshotBlock.Hash, &prevBlock.Hash, &state.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// the balances and code are imported as well, so the storage is written bypassing the vm context
+	unsavedCache := vmContext.UnsavedCache()
+	imported := make(map[string]bool, len(state.Storage))
+	for _, item := range state.Storage {
+		imported[string(item.Key)] = true
+	}
+	iterator := vmContext.NewStorageIterator(&state.Address, nil)
+	for {
+		key, _, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		if !imported[string(key)] {
+			unsavedCache.SetStorage(key, nil)
+		}
+	}
+	for _, item := range state.Storage {
+		if !bytes.Equal(unsavedCache.GetStorage(item.Key), item.Value) {
+			unsavedCache.SetStorage(item.Key, item.Value)
+		}
+	}
+
+	now := time.Now()
+	block := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       prevBlock.Hash,
+		Height:         prevBlock.Height + 1,
+		AccountAddress: state.Address,
+		Amount:         big.NewInt(0),
+		Fee:            big.NewInt(0),
+		SnapshotHash:   snapshotBlock.Hash,
+		Timestamp:      &now,
+	}
+	block.StateHash = *vmContext.GetStorageHash()
+	block.Hash = block.ComputeHash()
+
+	if err := c.InsertAccountBlocks([]*vm_context.VmAccountBlock{{AccountBlock: block, VmContext: vmContext}}); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// isImportedStateBlock reports whether block is inserted by ImportContractState on a dev chain
+func (c *chain) isImportedStateBlock(block *ledger.AccountBlock) bool {
+	return c.globalCfg.Producer != nil && c.globalCfg.DevMining &&
+		block.BlockType == ledger.BlockTypeReceive && block.FromBlockHash == types.ZERO_HASH
+}
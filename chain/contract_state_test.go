@@ -0,0 +1,42 @@
+package chain
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/trie"
+	"testing"
+)
+
+func TestReadContractState(t *testing.T) {
+	addr, _, _ := types.CreateAddress()
+	stateTrie := trie.NewTrie(nil, nil, nil)
+	state := &ContractState{Address: addr, SnapshotHeight: 100}
+	for _, item := range []*ContractStorageItem{
+		{Key: []byte("key1"), Value: []byte("value1")},
+		{Key: []byte("key2"), Value: bytes.Repeat([]byte("long value2"), 4)},
+	} {
+		stateTrie.SetValue(item.Key, item.Value)
+		state.Storage = append(state.Storage, item)
+	}
+	state.StateHash = *stateTrie.Hash()
+
+	buf := &bytes.Buffer{}
+	if err := WriteContractState(buf, state); err != nil {
+		t.Fatal(err)
+	}
+	written := buf.Bytes()
+	read, err := ReadContractState(bytes.NewReader(written))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Address != addr || read.SnapshotHeight != 100 || len(read.Storage) != 2 || !bytes.Equal(read.Storage[1].Value, state.Storage[1].Value) {
+		t.Fatalf("unexpected contract state read %+v", read)
+	}
+
+	state.Storage[0].Value = []byte("changed")
+	buf.Reset()
+	WriteContractState(buf, state)
+	if _, err := ReadContractState(buf); err == nil {
+		t.Fatal("the storage not matching the state hash should not be read")
+	}
+}
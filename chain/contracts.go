@@ -4,6 +4,7 @@ import (
 	"github.com/vitelabs/go-vite/vm/contracts/abi"
 	"math/big"
 
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/monitor"
@@ -12,6 +13,15 @@ import (
 	"time"
 )
 
+// isPrecompiledContractAddress reports whether addr is a built-in contract at the latest snapshot block
+func (c *chain) isPrecompiledContractAddress(addr types.Address) bool {
+	var height uint64
+	if latestSnapshotBlock := c.GetLatestSnapshotBlock(); latestSnapshotBlock != nil {
+		height = latestSnapshotBlock.Height
+	}
+	return fork.IsPrecompiledContractAddress(addr, height)
+}
+
 func (c *chain) GetContractGidByAccountBlock(block *ledger.AccountBlock) (*types.Gid, error) {
 	monitorTags := []string{"chain", "GetContractGidByAccountBlock"}
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
@@ -21,7 +31,7 @@ func (c *chain) GetContractGidByAccountBlock(block *ledger.AccountBlock) (*types
 	}
 
 	if block.Height == 1 {
-		if types.IsPrecompiledContractAddress(block.AccountAddress) {
+		if c.isPrecompiledContractAddress(block.AccountAddress) {
 			return &types.DELEGATE_GID, nil
 		}
 
@@ -44,7 +54,7 @@ func (c *chain) GetContractGid(addr *types.Address) (*types.Gid, error) {
 		return nil, nil
 	}
 
-	if types.IsPrecompiledContractAddress(*addr) {
+	if c.isPrecompiledContractAddress(*addr) {
 		return &types.DELEGATE_GID, nil
 	}
 
@@ -80,6 +90,11 @@ func (c *chain) GetPledgeQuotas(snapshotHash types.Hash, beneficialList []types.
 	}
 	quotas := make(map[types.Address]uint64)
 	for _, addr := range beneficialList {
+		if q, ok := c.pledgeQuotaCache.Get(snapshotHash, addr); ok {
+			quotas[addr] = q
+			continue
+		}
+		generation := c.pledgeQuotaCache.Generation()
 		balanceDb, err := vm_context.NewVmContext(c, &snapshotHash, nil, &addr)
 		if err != nil {
 			c.log.Error("NewVmContext failed, error is "+err.Error(), "method", "GetPledgeQuotas")
@@ -90,6 +105,7 @@ func (c *chain) GetPledgeQuotas(snapshotHash types.Hash, beneficialList []types.
 		if err != nil {
 			return nil, err
 		}
+		c.pledgeQuotaCache.Add(snapshotHash, addr, quotas[addr], generation)
 	}
 	return quotas, nil
 }
@@ -97,13 +113,22 @@ func (c *chain) GetPledgeQuota(snapshotHash types.Hash, beneficial types.Address
 	monitorTags := []string{"chain", "GetPledgeQuota"}
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
 
+	if q, ok := c.pledgeQuotaCache.Get(snapshotHash, beneficial); ok {
+		return q, nil
+	}
+	generation := c.pledgeQuotaCache.Generation()
 	vmContext, err := vm_context.NewVmContext(c, &snapshotHash, nil, &beneficial)
 	if err != nil {
 		c.log.Error("NewVmContext failed, error is "+err.Error(), "method", "GetPledgeQuota")
 		return 0, err
 	}
 	pledgeAmount := abi.GetPledgeBeneficialAmount(vmContext, beneficial)
-	return quota.GetPledgeQuota(vmContext, beneficial, pledgeAmount)
+	q, err := quota.GetPledgeQuota(vmContext, beneficial, pledgeAmount)
+	if err != nil {
+		return 0, err
+	}
+	c.pledgeQuotaCache.Add(snapshotHash, beneficial, q, generation)
+	return q, nil
 }
 
 func (c *chain) GetRegisterList(snapshotHash types.Hash, gid types.Gid) ([]*types.Registration, error) {
@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+// benchmarkGetPledgeQuota measures the quota lookups of the onroad workers, which query the quotas of the contracts
+// at the latest snapshot block. Without the cache the quotas are dropped before every lookup, so each one reads the
+// pledge amount and calculates the quota again.
+func benchmarkGetPledgeQuota(b *testing.B, cached bool, getQuota func(c *chain, snapshotHash types.Hash, addrList []types.Address) error) {
+	c := getChainInstance().(*chain)
+	snapshotHash := c.GetLatestSnapshotBlock().Hash
+	addrList := make([]types.Address, 100)
+	for i := range addrList {
+		addrList[i], _, _ = types.CreateAddress()
+	}
+	c.pledgeQuotaCache.Purge()
+	if err := getQuota(c, snapshotHash, addrList); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !cached {
+			b.StopTimer()
+			c.pledgeQuotaCache.Purge()
+			b.StartTimer()
+		}
+		if err := getQuota(c, snapshotHash, addrList); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkChain_GetPledgeQuota(b *testing.B) {
+	getQuota := func(c *chain, snapshotHash types.Hash, addrList []types.Address) error {
+		for _, addr := range addrList {
+			if _, err := c.GetPledgeQuota(snapshotHash, addr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	b.Run("cached", func(b *testing.B) { benchmarkGetPledgeQuota(b, true, getQuota) })
+	b.Run("uncached", func(b *testing.B) { benchmarkGetPledgeQuota(b, false, getQuota) })
+}
+
+func BenchmarkChain_GetPledgeQuotas(b *testing.B) {
+	getQuota := func(c *chain, snapshotHash types.Hash, addrList []types.Address) error {
+		_, err := c.GetPledgeQuotas(snapshotHash, addrList)
+		return err
+	}
+	b.Run("cached", func(b *testing.B) { benchmarkGetPledgeQuota(b, true, getQuota) })
+	b.Run("uncached", func(b *testing.B) { benchmarkGetPledgeQuota(b, false, getQuota) })
+}
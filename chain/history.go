@@ -0,0 +1,287 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+var ErrReadOnly = errors.New("the chain is read only")
+
+// historyChain is the chain as it was at the snapshot block of a past height, for investigating an incident
+// without changing the ledger. The blocks after the height are hidden, the latest account blocks are the ones
+// confirmed by then and the balances are read from their state tries, which are kept only if the ledger gc is
+// disabled. All the writes fail with ErrReadOnly.
+type historyChain struct {
+	*chain
+	height        uint64
+	snapshotBlock *ledger.SnapshotBlock
+}
+
+func newHistoryChain(c *chain, height uint64) *historyChain {
+	return &historyChain{
+		chain:  c,
+		height: height,
+	}
+}
+
+func (c *historyChain) Init() {
+	c.chain.Init()
+
+	snapshotBlock, err := c.chain.GetSnapshotBlockByHeight(c.height)
+	if err != nil {
+		panic(errors.New("GetSnapshotBlockByHeight failed when init history chain, error is " + err.Error()))
+	}
+	if snapshotBlock == nil {
+		panic(fmt.Errorf("snapshot block %d doesn't exist", c.height))
+	}
+	if ok, err := c.chain.ShallowCheckStateTrie(&snapshotBlock.StateHash); err != nil || !ok {
+		panic(fmt.Errorf("the state of snapshot block %d is pruned, the ledger gc must be disabled to keep it", c.height))
+	}
+	c.snapshotBlock = snapshotBlock
+}
+
+// Start starts none of the background tasks of the chain, which write the ledger.
+func (c *historyChain) Start() {
+	c.log.Info(fmt.Sprintf("Chain module started read only at snapshot block %d", c.height))
+}
+
+func (c *historyChain) Stop() {
+	c.log.Info("Chain module stopped")
+}
+
+func (c *historyChain) InsertAccountBlocks(vmAccountBlocks []*vm_context.VmAccountBlock) error {
+	return ErrReadOnly
+}
+
+func (c *historyChain) InsertSnapshotBlock(snapshotBlock *ledger.SnapshotBlock) error {
+	return ErrReadOnly
+}
+
+func (c *historyChain) DeleteAccountBlocks(addr *types.Address, toHeight uint64) (map[types.Address][]*ledger.AccountBlock, error) {
+	return nil, ErrReadOnly
+}
+
+func (c *historyChain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.SnapshotBlock, map[types.Address][]*ledger.AccountBlock, error) {
+	return nil, nil, ErrReadOnly
+}
+
+func (c *historyChain) ImportContractState(fileName string) (*ledger.AccountBlock, error) {
+	return nil, ErrReadOnly
+}
+
+func (c *historyChain) SaveRpcFilter(id string, filter []byte) error {
+	return ErrReadOnly
+}
+
+func (c *historyChain) DeleteRpcFilter(id string) error {
+	return ErrReadOnly
+}
+
+func (c *historyChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.snapshotBlock
+}
+
+func (c *historyChain) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	if height > c.height {
+		return nil, nil
+	}
+	return c.chain.GetSnapshotBlockByHeight(height)
+}
+
+func (c *historyChain) GetSnapshotBlockHeadByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	if height > c.height {
+		return nil, nil
+	}
+	return c.chain.GetSnapshotBlockHeadByHeight(height)
+}
+
+func (c *historyChain) GetSnapshotBlockByHash(hash *types.Hash) (*ledger.SnapshotBlock, error) {
+	block, err := c.chain.GetSnapshotBlockByHash(hash)
+	if err != nil || block == nil || block.Height > c.height {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (c *historyChain) GetSnapshotBlockHeadByHash(hash *types.Hash) (*ledger.SnapshotBlock, error) {
+	block, err := c.chain.GetSnapshotBlockHeadByHash(hash)
+	if err != nil || block == nil || block.Height > c.height {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (c *historyChain) GetSnapshotBlocksByHash(originBlockHash *types.Hash, count uint64, forward, containSnapshotContent bool) ([]*ledger.SnapshotBlock, error) {
+	blocks, err := c.chain.GetSnapshotBlocksByHash(originBlockHash, count, forward, containSnapshotContent)
+	if err != nil {
+		return nil, err
+	}
+	return c.hideSnapshotBlocks(blocks), nil
+}
+
+func (c *historyChain) GetSnapshotBlocksByHeight(height uint64, count uint64, forward, containSnapshotContent bool) ([]*ledger.SnapshotBlock, error) {
+	if !forward && height > c.height {
+		// backwards from the height of the view
+		if count <= height-c.height {
+			return nil, nil
+		}
+		count -= height - c.height
+		height = c.height
+	}
+	blocks, err := c.chain.GetSnapshotBlocksByHeight(height, count, forward, containSnapshotContent)
+	if err != nil {
+		return nil, err
+	}
+	return c.hideSnapshotBlocks(blocks), nil
+}
+
+// hideSnapshotBlocks removes the blocks after the height of the view
+func (c *historyChain) hideSnapshotBlocks(blocks []*ledger.SnapshotBlock) []*ledger.SnapshotBlock {
+	visible := blocks[:0]
+	for _, block := range blocks {
+		if block.Height <= c.height {
+			visible = append(visible, block)
+		}
+	}
+	return visible
+}
+
+func (c *historyChain) GetConfirmBlock(accountBlockHash *types.Hash) (*ledger.SnapshotBlock, error) {
+	block, err := c.chain.GetConfirmBlock(accountBlockHash)
+	if err != nil || block == nil || block.Height > c.height {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (c *historyChain) GetConfirmTimes(accountBlockHash *types.Hash) (uint64, error) {
+	block, err := c.GetConfirmBlock(accountBlockHash)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return c.height - block.Height + 1, nil
+}
+
+func (c *historyChain) GetLatestAccountBlock(addr *types.Address) (*ledger.AccountBlock, error) {
+	return c.chain.GetConfirmAccountBlock(c.height, addr)
+}
+
+// latestHeight returns the height of the latest account block of addr in the view, 0 if none
+func (c *historyChain) latestHeight(addr *types.Address) (uint64, error) {
+	block, err := c.GetLatestAccountBlock(addr)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return block.Height, nil
+}
+
+func (c *historyChain) GetAccountBlockByHeight(addr *types.Address, height uint64) (*ledger.AccountBlock, error) {
+	latestHeight, err := c.latestHeight(addr)
+	if err != nil || height > latestHeight {
+		return nil, err
+	}
+	return c.chain.GetAccountBlockByHeight(addr, height)
+}
+
+func (c *historyChain) GetAccountBlockHashByHeight(addr *types.Address, height uint64) (*types.Hash, error) {
+	latestHeight, err := c.latestHeight(addr)
+	if err != nil || height > latestHeight {
+		return nil, err
+	}
+	return c.chain.GetAccountBlockHashByHeight(addr, height)
+}
+
+func (c *historyChain) GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error) {
+	block, err := c.chain.GetAccountBlockByHash(blockHash)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	latestHeight, err := c.latestHeight(&block.AccountAddress)
+	if err != nil || block.Height > latestHeight {
+		return nil, err
+	}
+	return block, nil
+}
+
+func (c *historyChain) GetAccountBlocksByHeight(addr types.Address, start uint64, count uint64, forward bool) ([]*ledger.AccountBlock, error) {
+	latestHeight, err := c.latestHeight(&addr)
+	if err != nil {
+		return nil, err
+	}
+	if !forward && start > latestHeight {
+		if count <= start-latestHeight {
+			return nil, nil
+		}
+		count -= start - latestHeight
+		start = latestHeight
+	}
+	if start == 0 || start > latestHeight {
+		return nil, nil
+	}
+	blocks, err := c.chain.GetAccountBlocksByHeight(addr, start, count, forward)
+	if err != nil {
+		return nil, err
+	}
+	return hideAccountBlocks(blocks, latestHeight), nil
+}
+
+func (c *historyChain) GetAccountBlocksByHash(addr types.Address, origin *types.Hash, count uint64, forward bool) ([]*ledger.AccountBlock, error) {
+	latestHeight, err := c.latestHeight(&addr)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := c.chain.GetAccountBlocksByHash(addr, origin, count, forward)
+	if err != nil {
+		return nil, err
+	}
+	return hideAccountBlocks(blocks, latestHeight), nil
+}
+
+// hideAccountBlocks removes the blocks above latestHeight
+func hideAccountBlocks(blocks []*ledger.AccountBlock, latestHeight uint64) []*ledger.AccountBlock {
+	visible := blocks[:0]
+	for _, block := range blocks {
+		if block.Height <= latestHeight {
+			visible = append(visible, block)
+		}
+	}
+	return visible
+}
+
+// GetUnConfirmAccountBlocks returns nil, all the account blocks in the view are confirmed.
+func (c *historyChain) GetUnConfirmAccountBlocks(addr *types.Address) []*ledger.AccountBlock {
+	return nil
+}
+
+func (c *historyChain) GetUnConfirmedSubLedger() (map[types.Address][]*ledger.AccountBlock, error) {
+	return make(map[types.Address][]*ledger.AccountBlock), nil
+}
+
+func (c *historyChain) GetUnConfirmedPartSubLedger(addrList []types.Address) (map[types.Address][]*ledger.AccountBlock, error) {
+	return make(map[types.Address][]*ledger.AccountBlock), nil
+}
+
+func (c *historyChain) GetAccountBalance(addr *types.Address) (map[types.TokenTypeId]*big.Int, error) {
+	block, err := c.GetLatestAccountBlock(addr)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return getBalanceMap(c.GetStateTrie(&block.StateHash))
+}
+
+func (c *historyChain) GetAccountBalanceByTokenId(addr *types.Address, tokenId *types.TokenTypeId) (*big.Int, error) {
+	balance := big.NewInt(0)
+	block, err := c.GetLatestAccountBlock(addr)
+	if err != nil || block == nil {
+		return balance, err
+	}
+	if value := c.GetStateTrie(&block.StateHash).GetValue(vm_context.BalanceKey(tokenId)); value != nil {
+		balance.SetBytes(value)
+	}
+	return balance, nil
+}
@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"testing"
+)
+
+func TestHistoryChain(t *testing.T) {
+	chainInstance := getChainInstance().(*chain)
+	latestSnapshotBlock := chainInstance.GetLatestSnapshotBlock()
+
+	hc := newHistoryChain(chainInstance, latestSnapshotBlock.Height)
+	hc.snapshotBlock = latestSnapshotBlock
+
+	// the blocks not confirmed yet are hidden
+	blocks, addrs, _ := randomSendViteBlock(latestSnapshotBlock.Hash, nil, nil)
+	if err := chainInstance.InsertAccountBlocks(blocks); err != nil {
+		t.Fatal(err)
+	}
+	if block, err := hc.GetLatestAccountBlock(&addrs[0]); err != nil || block != nil {
+		t.Fatalf("unexpected latest block %+v, error %v", block, err)
+	}
+	if block, err := hc.GetAccountBlockByHash(&blocks[0].AccountBlock.Hash); err != nil || block != nil {
+		t.Fatalf("unconfirmed block %+v should be hidden, error %v", block, err)
+	}
+
+	if block, err := hc.GetSnapshotBlockByHeight(latestSnapshotBlock.Height + 1); err != nil || block != nil {
+		t.Fatalf("unexpected snapshot block %+v, error %v", block, err)
+	}
+	if times, err := hc.GetConfirmTimes(&blocks[0].AccountBlock.Hash); err != nil || times != 0 {
+		t.Fatalf("unexpected confirm times %d, error %v", times, err)
+	}
+
+	if err := hc.InsertAccountBlocks(blocks); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if _, _, err := hc.DeleteSnapshotBlocksToHeight(1); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+}
@@ -30,6 +30,7 @@ type Chain interface {
 	InsertAccountBlocks(vmAccountBlocks []*vm_context.VmAccountBlock) error
 	GetAccountBlocksByHash(addr types.Address, origin *types.Hash, count uint64, forward bool) ([]*ledger.AccountBlock, error)
 	GetAccountBlocksByHeight(addr types.Address, start uint64, count uint64, forward bool) ([]*ledger.AccountBlock, error)
+	GetAccountBlocksBySnapshotHash(addr *types.Address, endHeight uint64, snapshotHash *types.Hash) ([]*ledger.AccountBlock, error)
 	GetAccountBlockMap(queryParams map[types.Address]*BlockMapQueryParam) map[types.Address][]*ledger.AccountBlock
 	GetLatestAccountBlock(addr *types.Address) (*ledger.AccountBlock, error)
 	GetAccountBalance(addr *types.Address) (map[types.TokenTypeId]*big.Int, error)
@@ -40,6 +41,7 @@ type Chain interface {
 	GetAccountBlockByHeight(addr *types.Address, height uint64) (*ledger.AccountBlock, error)
 	GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error)
 	GetAccountBlocksByAddress(addr *types.Address, index int, num int, count int) ([]*ledger.AccountBlock, error)
+	GetAccountBlocksByDestinationTag(addr *types.Address, tag uint64, index int, count int) ([]*ledger.AccountBlock, error)
 	GetFirstConfirmedAccountBlockBySbHeight(snapshotBlockHeight uint64, addr *types.Address) (*ledger.AccountBlock, error)
 
 	GetUnConfirmAccountBlocks(addr *types.Address) []*ledger.AccountBlock
@@ -112,6 +114,16 @@ type Chain interface {
 	GetSubLedgerByHash(startBlockHash *types.Hash, count uint64, forward bool) ([]*ledger.CompressedFileMeta, [][2]uint64, error)
 	GetConfirmSubLedger(fromHeight uint64, toHeight uint64) ([]*ledger.SnapshotBlock, map[types.Address][]*ledger.AccountBlock, error)
 	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
+
+	// Contract states exported from the live net and imported into a dev chain
+	ExportContractState(addr *types.Address, snapshotHeight uint64, fileName string) (*ContractState, error)
+	ImportContractState(fileName string) (*ledger.AccountBlock, error)
+
+	// Durable filters of the subscribe api
+	GetRpcFilter(id string) ([]byte, error)
+	SaveRpcFilter(id string, filter []byte) error
+	DeleteRpcFilter(id string) error
+
 	UnRegister(listenerId uint64)
 	TrieDb() *leveldb.DB
 	CleanTrieNodePool()
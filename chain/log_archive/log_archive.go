@@ -0,0 +1,265 @@
+package log_archive
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+const (
+	DBKP_ARCHIVED_HEIGHT = byte(1)
+	DBKP_LOG_SEGMENT     = byte(2)
+)
+
+const (
+	STOP  = 1
+	START = 2
+)
+
+// LogArchive moves the vm logs confirmed more than retainDays ago out of the chain db
+// into gzipped columnar segment files, one segment per segmentHeights snapshot blocks.
+// The archived logs are still readable by log list hash, at the cost of decoding a segment.
+type LogArchive struct {
+	chain Chain
+	log   log15.Logger
+
+	dataDir      string
+	db           *leveldb.DB
+	retainHeight uint64
+
+	segmentHeights uint64
+	segmentCache   *lru.Cache
+
+	status     int
+	statusLock sync.Mutex
+	ticker     *time.Ticker
+	terminal   chan struct{}
+	wg         sync.WaitGroup
+}
+
+func NewLogArchive(chain Chain, dataDir string, retainDays uint64) (*LogArchive, error) {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+	db, err := database.NewLevelDb(filepath.Join(dataDir, "index"))
+	if err != nil {
+		return nil, err
+	}
+	segmentCache, _ := lru.New(16)
+
+	return &LogArchive{
+		chain: chain,
+		log:   log15.New("module", "log_archive"),
+
+		dataDir:      dataDir,
+		db:           db,
+		retainHeight: retainDays * types.SnapshotDayHeight,
+
+		segmentHeights: types.SnapshotHourHeight,
+		segmentCache:   segmentCache,
+
+		status: STOP,
+	}, nil
+}
+
+func (la *LogArchive) Start() {
+	la.statusLock.Lock()
+	defer la.statusLock.Unlock()
+	if la.status == START {
+		return
+	}
+
+	la.ticker = time.NewTicker(10 * time.Minute)
+	la.terminal = make(chan struct{})
+	la.wg.Add(1)
+	go func() {
+		defer la.wg.Done()
+		la.archive()
+		for {
+			select {
+			case <-la.ticker.C:
+				la.archive()
+			case <-la.terminal:
+				return
+			}
+		}
+	}()
+
+	la.status = START
+}
+
+func (la *LogArchive) Stop() {
+	la.statusLock.Lock()
+	defer la.statusLock.Unlock()
+	if la.status == STOP {
+		return
+	}
+
+	la.ticker.Stop()
+	close(la.terminal)
+	la.wg.Wait()
+	la.status = STOP
+}
+
+func (la *LogArchive) Destroy() {
+	la.db.Close()
+}
+
+// GetVmLogList returns the archived vm log list of logListHash, nil if it isn't archived.
+func (la *LogArchive) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
+	key, _ := database.EncodeKey(DBKP_LOG_SEGMENT, logListHash.Bytes())
+	value, err := la.db.Get(key, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	from := binary.BigEndian.Uint64(value[:8])
+	to := binary.BigEndian.Uint64(value[8:])
+
+	s, err := la.getSegment(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.get(*logListHash), nil
+}
+
+func (la *LogArchive) getSegment(from, to uint64) (*segment, error) {
+	name := la.segmentFileName(from, to)
+	if cached, ok := la.segmentCache.Get(name); ok {
+		return cached.(*segment), nil
+	}
+	buf, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := decodeSegment(buf)
+	if err != nil {
+		return nil, err
+	}
+	la.segmentCache.Add(name, s)
+	return s, nil
+}
+
+func (la *LogArchive) segmentFileName(from, to uint64) string {
+	return filepath.Join(la.dataDir, fmt.Sprintf("logs_%d_%d", from, to))
+}
+
+func (la *LogArchive) archivedHeight() (uint64, error) {
+	key, _ := database.EncodeKey(DBKP_ARCHIVED_HEIGHT)
+	value, err := la.db.Get(key, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+// archive archives segment by segment until the retained snapshot heights are reached.
+func (la *LogArchive) archive() {
+	for {
+		select {
+		case <-la.terminal:
+			return
+		default:
+		}
+
+		archivedHeight, err := la.archivedHeight()
+		if err != nil {
+			la.log.Error("archivedHeight failed, error is "+err.Error(), "method", "archive")
+			return
+		}
+		latestHeight := la.chain.GetLatestSnapshotBlock().Height
+		if latestHeight <= la.retainHeight+la.segmentHeights ||
+			archivedHeight+la.segmentHeights > latestHeight-la.retainHeight {
+			return
+		}
+
+		if err := la.archiveSegment(archivedHeight+1, archivedHeight+la.segmentHeights); err != nil {
+			la.log.Error("archiveSegment failed, error is "+err.Error(), "method", "archive")
+			return
+		}
+	}
+}
+
+// archiveSegment writes the vm logs confirmed by the snapshot blocks from fromHeight to toHeight
+// into a segment file, indexes them and deletes them from the chain db.
+func (la *LogArchive) archiveSegment(fromHeight, toHeight uint64) error {
+	snapshotBlocks, err := la.chain.GetSnapshotBlocksByHeight(fromHeight, toHeight-fromHeight+1, true, true)
+	if err != nil {
+		return err
+	}
+	subLedger, err := la.chain.GetConfirmSubLedgerBySnapshotBlocks(snapshotBlocks)
+	if err != nil {
+		return err
+	}
+
+	chainDb := la.chain.ChainDb()
+	s := &segment{}
+	for _, blocks := range subLedger {
+		for _, block := range blocks {
+			if block.LogHash == nil || s.get(*block.LogHash) != nil {
+				continue
+			}
+			logList, err := chainDb.Ac.GetVmLogList(block.LogHash)
+			if err != nil {
+				return err
+			}
+			if len(logList) > 0 {
+				s.add(*block.LogHash, logList)
+			}
+		}
+	}
+
+	if len(s.hashes) > 0 {
+		buf, err := s.encode()
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(la.segmentFileName(fromHeight, toHeight), buf, 0600); err != nil {
+			return err
+		}
+	}
+
+	batch := new(leveldb.Batch)
+	segmentValue := make([]byte, 16)
+	binary.BigEndian.PutUint64(segmentValue[:8], fromHeight)
+	binary.BigEndian.PutUint64(segmentValue[8:], toHeight)
+	for _, hash := range s.hashes {
+		key, _ := database.EncodeKey(DBKP_LOG_SEGMENT, hash.Bytes())
+		batch.Put(key, segmentValue)
+	}
+	heightKey, _ := database.EncodeKey(DBKP_ARCHIVED_HEIGHT)
+	heightValue := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightValue, toHeight)
+	batch.Put(heightKey, heightValue)
+	if err := la.db.Write(batch, nil); err != nil {
+		return err
+	}
+
+	chainBatch := new(leveldb.Batch)
+	for _, hash := range s.hashes {
+		h := hash
+		chainDb.Ac.DeleteVmLogList(chainBatch, &h)
+	}
+	if err := chainDb.Commit(chainBatch); err != nil {
+		return err
+	}
+
+	la.log.Info(fmt.Sprintf("archive %d vm log lists from %d to %d", len(s.hashes), fromHeight, toHeight), "method", "archiveSegment")
+	return nil
+}
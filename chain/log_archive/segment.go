@@ -0,0 +1,151 @@
+package log_archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+var errCorruptedSegment = errors.New("corrupted log archive segment")
+
+// segment is the columnar layout of an archive file, every column is stored contiguously:
+// log list hashes, log count of each list, topic count of each log, topics, data length of each log, data.
+type segment struct {
+	hashes   []types.Hash
+	logLists []ledger.VmLogList
+}
+
+func (s *segment) add(hash types.Hash, logList ledger.VmLogList) {
+	s.hashes = append(s.hashes, hash)
+	s.logLists = append(s.logLists, logList)
+}
+
+func (s *segment) get(hash types.Hash) ledger.VmLogList {
+	for i, h := range s.hashes {
+		if h == hash {
+			return s.logLists[i]
+		}
+	}
+	return nil
+}
+
+func (s *segment) encode() ([]byte, error) {
+	var logCounts, topicCounts, topics, dataLens, data bytes.Buffer
+	buf := make([]byte, 4)
+	for _, logList := range s.logLists {
+		binary.BigEndian.PutUint32(buf, uint32(len(logList)))
+		logCounts.Write(buf)
+		for _, vmLog := range logList {
+			topicCounts.WriteByte(byte(len(vmLog.Topics)))
+			for _, topic := range vmLog.Topics {
+				topics.Write(topic.Bytes())
+			}
+			binary.BigEndian.PutUint32(buf, uint32(len(vmLog.Data)))
+			dataLens.Write(buf)
+			data.Write(vmLog.Data)
+		}
+	}
+
+	var out bytes.Buffer
+	w := gzip.NewWriter(&out)
+	binary.BigEndian.PutUint32(buf, uint32(len(s.hashes)))
+	w.Write(buf)
+	for _, hash := range s.hashes {
+		w.Write(hash.Bytes())
+	}
+	for _, column := range []*bytes.Buffer{&logCounts, &topicCounts, &topics, &dataLens, &data} {
+		if _, err := w.Write(column.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func decodeSegment(buf []byte) (*segment, error) {
+	r, err := gzip.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	next := func(n int) ([]byte, error) {
+		if n > len(raw) {
+			return nil, errCorruptedSegment
+		}
+		b := raw[:n]
+		raw = raw[n:]
+		return b, nil
+	}
+
+	b, err := next(4)
+	if err != nil {
+		return nil, err
+	}
+	count := int(binary.BigEndian.Uint32(b))
+	s := &segment{hashes: make([]types.Hash, count), logLists: make([]ledger.VmLogList, count)}
+	for i := 0; i < count; i++ {
+		if b, err = next(types.HashSize); err != nil {
+			return nil, err
+		}
+		s.hashes[i], _ = types.BytesToHash(b)
+	}
+
+	logTotal := 0
+	logCounts := make([]int, count)
+	for i := 0; i < count; i++ {
+		if b, err = next(4); err != nil {
+			return nil, err
+		}
+		logCounts[i] = int(binary.BigEndian.Uint32(b))
+		logTotal += logCounts[i]
+	}
+
+	topicCounts, err := next(logTotal)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*ledger.VmLog, logTotal)
+	for i := range logs {
+		logs[i] = &ledger.VmLog{}
+		for j := 0; j < int(topicCounts[i]); j++ {
+			if b, err = next(types.HashSize); err != nil {
+				return nil, err
+			}
+			topic, _ := types.BytesToHash(b)
+			logs[i].Topics = append(logs[i].Topics, topic)
+		}
+	}
+
+	dataLens := make([]int, logTotal)
+	for i := range logs {
+		if b, err = next(4); err != nil {
+			return nil, err
+		}
+		dataLens[i] = int(binary.BigEndian.Uint32(b))
+	}
+	for i := range logs {
+		if b, err = next(dataLens[i]); err != nil {
+			return nil, err
+		}
+		if len(b) > 0 {
+			logs[i].Data = b
+		}
+	}
+
+	for i := 0; i < count; i++ {
+		s.logLists[i] = logs[:logCounts[i]]
+		logs = logs[logCounts[i]:]
+	}
+	return s, nil
+}
@@ -0,0 +1,46 @@
+package log_archive
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestSegment_encode(t *testing.T) {
+	topicA := types.DataHash([]byte("a"))
+	topicB := types.DataHash([]byte("b"))
+	logListA := ledger.VmLogList{
+		{Topics: []types.Hash{topicA, topicB}, Data: []byte("data")},
+		{Topics: []types.Hash{topicB}},
+	}
+	logListB := ledger.VmLogList{
+		{Data: []byte("other data")},
+	}
+
+	s := &segment{}
+	s.add(*logListA.Hash(), logListA)
+	s.add(*logListB.Hash(), logListB)
+
+	buf, err := s.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeSegment(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, logList := range []ledger.VmLogList{logListA, logListB} {
+		got := decoded.get(*logList.Hash())
+		if got == nil || *got.Hash() != *logList.Hash() {
+			t.Fatalf("unexpected log list %v", got)
+		}
+	}
+	if decoded.get(topicA) != nil {
+		t.Fatal("unknown hash should not be found")
+	}
+
+	if _, err := decodeSegment(buf[:len(buf)/2]); err == nil {
+		t.Fatal("truncated segment should fail to decode")
+	}
+}
@@ -17,5 +17,13 @@ func (c *chain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
 		return nil, err
 	}
 
+	if vmLogList == nil && c.logArchive != nil {
+		// the vm logs may have been moved to the log archive
+		if vmLogList, err = c.logArchive.GetVmLogList(logListHash); err != nil {
+			c.log.Error("logArchive.GetVmLogList failed, error is "+err.Error(), "method", "GetVmLogList")
+			return nil, err
+		}
+	}
+
 	return vmLogList, nil
 }
@@ -41,7 +41,7 @@ func (c *chain) InsertAccountBlocks(vmAccountBlocks []*vm_context.VmAccountBlock
 			return saveBlockMetaErr
 		}
 	}
-	if err := c.chainDb.Db().Write(batch, nil); err != nil {
+	if err := c.chainDb.Commit(batch); err != nil {
 		return err
 	}
 	return nil
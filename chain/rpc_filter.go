@@ -0,0 +1,27 @@
+package chain
+
+// GetRpcFilter returns the durable rpc filter of id, it's nil if not found.
+func (c *chain) GetRpcFilter(id string) ([]byte, error) {
+	filter, err := c.chainDb.Filter.GetFilter(id)
+	if err != nil {
+		c.log.Error("GetFilter failed, error is "+err.Error(), "method", "GetRpcFilter")
+		return nil, err
+	}
+	return filter, nil
+}
+
+func (c *chain) SaveRpcFilter(id string, filter []byte) error {
+	if err := c.chainDb.Filter.WriteFilter(id, filter); err != nil {
+		c.log.Error("WriteFilter failed, error is "+err.Error(), "method", "SaveRpcFilter")
+		return err
+	}
+	return nil
+}
+
+func (c *chain) DeleteRpcFilter(id string) error {
+	if err := c.chainDb.Filter.DeleteFilter(id); err != nil {
+		c.log.Error("DeleteFilter failed, error is "+err.Error(), "method", "DeleteRpcFilter")
+		return err
+	}
+	return nil
+}
@@ -177,6 +177,7 @@ func (c *chain) InsertSnapshotBlock(snapshotBlock *ledger.SnapshotBlock) error {
 
 	// Set cache
 	c.latestSnapshotBlock = snapshotBlock
+	c.pledgeQuotaCache.Purge()
 	// Trigger success
 	c.em.triggerInsertSnapshotBlocksSuccess([]*ledger.SnapshotBlock{snapshotBlock})
 
@@ -225,11 +226,17 @@ func (c *chain) GetSnapshotBlockHeadByHeight(height uint64) (*ledger.SnapshotBlo
 	monitorTags := []string{"chain", "GetSnapshotBlockHeadByHeight"}
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
 
+	if block := c.snapshotBlockCache.GetByHeight(height); block != nil {
+		return block, nil
+	}
+
+	generation := c.snapshotBlockCache.Generation()
 	block, gsbErr := c.chainDb.Sc.GetSnapshotBlock(height, false)
 	if gsbErr != nil {
 		c.log.Error("GetSnapshotBlock failed, error is "+gsbErr.Error(), "method", "GetSnapshotBlockHeadByHeight")
 		return nil, gsbErr
 	}
+	c.snapshotBlockCache.Add(block, generation)
 
 	return block, nil
 }
@@ -238,6 +245,10 @@ func (c *chain) GetSnapshotBlockHeadByHash(hash *types.Hash) (*ledger.SnapshotBl
 	monitorTags := []string{"chain", "GetSnapshotBlockHeadByHash"}
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
 
+	if block := c.snapshotBlockCache.GetByHash(hash); block != nil {
+		return block, nil
+	}
+
 	height, err := c.chainDb.Sc.GetSnapshotBlockHeight(hash)
 	if err != nil {
 		c.log.Error("GetSnapshotBlockHeight failed, error is "+err.Error(), "method", "GetSnapshotBlockHeadByHash")
@@ -254,12 +265,29 @@ func (c *chain) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock,
 	monitorTags := []string{"chain", "GetSnapshotBlockByHeight"}
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
 
+	if block := c.snapshotBlockCache.GetByHeight(height); block != nil {
+		return c.withSnapshotContent(block)
+	}
+
+	generation := c.snapshotBlockCache.Generation()
 	block, gsbErr := c.chainDb.Sc.GetSnapshotBlock(height, true)
 	if gsbErr != nil {
 		c.log.Error("GetSnapshotBlock failed, error is "+gsbErr.Error(), "method", "GetSnapshotBlockByHeight")
 		return nil, gsbErr
 	}
+	c.snapshotBlockCache.Add(block, generation)
+
+	return block, nil
+}
 
+// withSnapshotContent reads the snapshot content of the cached head block
+func (c *chain) withSnapshotContent(block *ledger.SnapshotBlock) (*ledger.SnapshotBlock, error) {
+	content, err := c.chainDb.Sc.GetSnapshotContent(block.Height)
+	if err != nil {
+		c.log.Error("GetSnapshotContent failed, error is "+err.Error(), "method", "withSnapshotContent")
+		return nil, err
+	}
+	block.SnapshotContent = content
 	return block, nil
 }
 
@@ -267,6 +295,10 @@ func (c *chain) GetSnapshotBlockByHash(hash *types.Hash) (*ledger.SnapshotBlock,
 	monitorTags := []string{"chain", "GetSnapshotBlockByHash"}
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
 
+	if block := c.snapshotBlockCache.GetByHash(hash); block != nil {
+		return c.withSnapshotContent(block)
+	}
+
 	height, err := c.chainDb.Sc.GetSnapshotBlockHeight(hash)
 	if err != nil {
 		c.log.Error("GetSnapshotBlockHeight failed, error is "+err.Error(), "method", "GetSnapshotBlockByHash")
@@ -634,6 +666,8 @@ func (c *chain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.Snapsho
 
 	// Delete cache
 	c.stateTriePool.Delete(needRemoveAddrList)
+	c.snapshotBlockCache.DeleteFrom(snapshotBlocks[0].Height)
+	c.pledgeQuotaCache.Purge()
 
 	// Set cache
 	c.latestSnapshotBlock = prevSnapshotBlock
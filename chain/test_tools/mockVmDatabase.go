@@ -143,3 +143,7 @@ func (context *MockVmDatabase) GetOriginalStorage(key []byte) []byte {
 func (context *MockVmDatabase) GetSelfAccountBlockByHeight(height uint64) *ledger.AccountBlock {
 	return nil
 }
+
+func (context *MockVmDatabase) GetPrevAccountBlocksBySnapshotHash(snapshotHash *types.Hash) ([]*ledger.AccountBlock, error) {
+	return nil, nil
+}
@@ -2,9 +2,13 @@ package trie_gc
 
 import (
 	"fmt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vitelabs/go-vite/chain_db/database"
 	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/metrics"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,6 +18,12 @@ const (
 	STATUS_MARKING_AND_CLEANING = 3
 )
 
+var (
+	registerGaugesOnce sync.Once
+	// gaugeCollector is the collector created last, which the gauges read
+	gaugeCollector atomic.Value
+)
+
 type collector struct {
 	terminal     chan struct{}
 	taskTerminal chan struct{}
@@ -32,9 +42,26 @@ type collector struct {
 	log log15.Logger
 
 	marker *Marker
+
+	statsLock sync.RWMutex
+	stats     Stats
 }
 
-func NewCollector(chain Chain, ledgerGcRetain uint64) Collector {
+// Stats are the results of the ledger gc
+type Stats struct {
+	// PrunedHeight is the height below which the states of the snapshot blocks are pruned except the checkpoints,
+	// 0 if nothing is pruned. It is read from the db, the other stats are counted since the node started.
+	PrunedHeight uint64
+	// DeletedNodes is the number of the trie nodes and ref values deleted
+	DeletedNodes uint64
+	// TrieDbSize is the approximate bytes of the trie nodes and ref values on disk
+	TrieDbSize uint64
+	// LastRunTime is the time the last run of the gc finished, zero if it never finished
+	LastRunTime     time.Time
+	LastRunDuration time.Duration
+}
+
+func NewCollector(chain Chain, ledgerGcRetain uint64, checkpointInterval uint64) Collector {
 	gc := &collector{
 		minCheckInterval: time.Hour,
 		maxCheckInterval: 3 * time.Hour,
@@ -42,12 +69,31 @@ func NewCollector(chain Chain, ledgerGcRetain uint64) Collector {
 		chain: chain,
 		log:   log15.New("module", "trie_gc"),
 
-		marker: NewMarker(chain, ledgerGcRetain),
+		marker: NewMarker(chain, ledgerGcRetain, checkpointInterval),
 	}
+	gaugeCollector.Store(gc)
+	registerGaugesOnce.Do(registerGauges)
 
 	return gc
 }
 
+// registerGauges exports the stats of the collector created last to the default metrics registry, they are recorded
+// only if metrics are enabled
+func registerGauges() {
+	stats := func() *Stats {
+		return gaugeCollector.Load().(*collector).Stats()
+	}
+	metrics.NewRegisteredFunctionalGauge("trie_gc/prunedHeight", nil, func() int64 {
+		return int64(stats().PrunedHeight)
+	})
+	metrics.NewRegisteredFunctionalGauge("trie_gc/deletedNodes", nil, func() int64 {
+		return int64(stats().DeletedNodes)
+	})
+	metrics.NewRegisteredFunctionalGauge("trie_gc/trieDbSize", nil, func() int64 {
+		return int64(stats().TrieDbSize)
+	})
+}
+
 func (gc *collector) Check() (bool, error) {
 	const (
 		numPerCheck = 100
@@ -167,6 +213,45 @@ func (gc *collector) Status() uint8 {
 	return gc.status
 }
 
+// IsPruned reports whether the state of the snapshot block of snapshotHeight is pruned by the gc, including the
+// states pruned before the node started
+func (gc *collector) IsPruned(snapshotHeight uint64) bool {
+	return snapshotHeight < gc.prunedHeight() && !gc.marker.IsCheckpoint(snapshotHeight)
+}
+
+func (gc *collector) prunedHeight() uint64 {
+	prunedHeight, err := gc.marker.PrunedHeight()
+	if err != nil {
+		gc.log.Error("PrunedHeight failed, error is "+err.Error(), "method", "prunedHeight")
+		return 0
+	}
+	return prunedHeight
+}
+
+func (gc *collector) Stats() *Stats {
+	gc.statsLock.RLock()
+	stats := gc.stats
+	gc.statsLock.RUnlock()
+
+	stats.PrunedHeight = gc.prunedHeight()
+	stats.TrieDbSize = gc.trieDbSize()
+	return &stats
+}
+
+func (gc *collector) trieDbSize() uint64 {
+	var ranges []util.Range
+	for _, prefix := range []byte{database.DBKP_TRIE_NODE, database.DBKP_TRIE_REF_VALUE} {
+		dbKey, _ := database.EncodeKey(prefix)
+		ranges = append(ranges, *util.BytesPrefix(dbKey))
+	}
+	sizes, err := gc.chain.TrieDb().SizeOf(ranges)
+	if err != nil {
+		gc.log.Error("SizeOf failed, error is "+err.Error(), "method", "trieDbSize")
+		return 0
+	}
+	return uint64(sizes.Sum())
+}
+
 func (gc *collector) runTask() {
 	gc.statusLock.Lock()
 	if gc.status > STATUS_STARTED {
@@ -183,8 +268,16 @@ func (gc *collector) runTask() {
 		gc.statusLock.Unlock()
 	}()
 	gc.log.Info("gc run task.")
-	if err := gc.marker.MarkAndClean(gc.taskTerminal); err != nil {
+	startTime := time.Now()
+	_, deleted, err := gc.marker.MarkAndClean(gc.taskTerminal)
+	if err != nil {
 		gc.log.Error("gc.Marker.Mark failed, error is "+err.Error(), "method", "runTask")
 		return
 	}
+
+	gc.statsLock.Lock()
+	defer gc.statsLock.Unlock()
+	gc.stats.DeletedNodes += deleted
+	gc.stats.LastRunTime = time.Now()
+	gc.stats.LastRunDuration = gc.stats.LastRunTime.Sub(startTime)
 }
@@ -18,6 +18,8 @@ type Collector interface {
 	Recover() (returnErr error)
 
 	RetainMinHeight() uint64
+	IsPruned(snapshotHeight uint64) bool
+	Stats() *Stats
 }
 
 type Chain interface {
@@ -1,6 +1,7 @@
 package trie_gc
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -20,6 +21,8 @@ type Marker struct {
 	markSleepPerRound time.Duration
 
 	retainSnapshotHeight uint64
+	// the states of the snapshot blocks at the multiples of checkpointInterval are kept, none if it's 0
+	checkpointInterval uint64
 
 	triePool *trie.TrieNodePool
 
@@ -27,7 +30,7 @@ type Marker struct {
 	stopSaveMinGap uint64
 }
 
-func NewMarker(chain Chain, retainSnapshotHeight uint64) *Marker {
+func NewMarker(chain Chain, retainSnapshotHeight uint64, checkpointInterval uint64) *Marker {
 	if retainSnapshotHeight <= 0 {
 		retainSnapshotHeight = 86400
 	}
@@ -37,6 +40,7 @@ func NewMarker(chain Chain, retainSnapshotHeight uint64) *Marker {
 		markSleepPerRound: time.Millisecond * 5,
 
 		retainSnapshotHeight: retainSnapshotHeight,
+		checkpointInterval:   checkpointInterval,
 		stopSaveMinGap:       100,
 
 		triePool: trie.NewCustomTrieNodePool(50*10000, 25*10000),
@@ -48,6 +52,11 @@ func (m *Marker) RetainSnapshotHeight() uint64 {
 	return m.retainSnapshotHeight
 }
 
+// IsCheckpoint reports whether the state of the snapshot block of snapshotHeight is kept after it is out of the retained
+func (m *Marker) IsCheckpoint(snapshotHeight uint64) bool {
+	return m.checkpointInterval > 0 && snapshotHeight%m.checkpointInterval == 0
+}
+
 func (m *Marker) getMinSnapshotHeight() uint64 {
 	latestSnapshotBlock := m.chain.GetLatestSnapshotBlock()
 	if latestSnapshotBlock.Height <= m.retainSnapshotHeight+types.AccountLimitSnapshotHeight {
@@ -57,7 +66,10 @@ func (m *Marker) getMinSnapshotHeight() uint64 {
 	}
 }
 
-func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
+// MarkAndClean deletes the states of the snapshot blocks below the min snapshot height except the checkpoints, and
+// returns the min snapshot height with the number of the trie nodes and ref values deleted. The height is 0 if
+// nothing is cleaned.
+func (m *Marker) MarkAndClean(terminal <-chan struct{}) (uint64, uint64, error) {
 	markedHashSet := make(map[types.Hash]struct{})
 	refHashSet := make(map[types.Hash]struct{})
 
@@ -66,12 +78,16 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 
 	lastBeId, err := m.chain.GetLatestBlockEventId()
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
 
 	minSnapshotHeight := m.getMinSnapshotHeight()
 	if minSnapshotHeight <= 1 {
-		return nil
+		return 0, 0, nil
+	}
+
+	if err := m.markCheckpoints(minSnapshotHeight, markedHashSet, refHashSet); err != nil {
+		return 0, 0, err
 	}
 
 	beginEventId := uint64(1)
@@ -83,14 +99,14 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 		for i := targetEventId; i >= beginEventId; i-- {
 			eventType, hashList, err := m.chain.GetEvent(i)
 			if err != nil {
-				return err
+				return 0, 0, err
 			}
 			switch eventType {
 			case access.AddAccountBlocksEvent:
 				for _, hash := range hashList {
 					block, err := m.chain.GetAccountBlockByHash(&hash)
 					if err != nil {
-						return err
+						return 0, 0, err
 					}
 					if block == nil {
 						continue
@@ -98,7 +114,7 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 
 					setErr := m.setAccountBlockNodeHashSet(block, markedHashSet, refHashSet)
 					if setErr != nil {
-						return setErr
+						return 0, 0, setErr
 					}
 				}
 			case access.AddSnapshotBlocksEvent:
@@ -106,7 +122,7 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 				for _, hash := range hashList {
 					block, err := m.chain.GetSnapshotBlockByHash(&hash)
 					if err != nil {
-						return err
+						return 0, 0, err
 					}
 					if block == nil {
 						continue
@@ -114,7 +130,7 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 
 					setErr := m.setSnapshotBlockNodeHashSet(block, markedHashSet, refHashSet)
 					if setErr != nil {
-						return setErr
+						return 0, 0, setErr
 					}
 
 					if block.Height <= minSnapshotHeight {
@@ -129,7 +145,7 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 			if markEventIndex > m.markEventPerRound {
 				select {
 				case <-terminal:
-					return nil
+					return 0, 0, nil
 				default:
 					if m.markSleepPerRound > 0 {
 						time.Sleep(m.markSleepPerRound)
@@ -145,7 +161,7 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 		lastBeId, err := m.chain.GetLatestBlockEventId()
 		if err != nil {
 			m.chain.StartSaveTrie()
-			return err
+			return 0, 0, err
 		}
 
 		if lastBeId <= targetEventId {
@@ -158,14 +174,69 @@ func (m *Marker) MarkAndClean(terminal <-chan struct{}) error {
 		targetEventId = lastBeId
 	}
 
-	return m.clean(markedHashSet, refHashSet)
+	deleted, err := m.clean(markedHashSet, refHashSet, minSnapshotHeight)
+	if err != nil {
+		return 0, 0, err
+	}
+	return minSnapshotHeight, deleted, nil
 }
 
-func (m *Marker) clean(hashSet map[types.Hash]struct{}, refHashSet map[types.Hash]struct{}) error {
+// markCheckpoints marks the states of the checkpoints below minSnapshotHeight, the ones pruned before the checkpoints
+// are configured are skipped
+func (m *Marker) markCheckpoints(minSnapshotHeight uint64, hashSet map[types.Hash]struct{}, refHashSet map[types.Hash]struct{}) error {
+	if m.checkpointInterval <= 0 {
+		return nil
+	}
+	for height := m.checkpointInterval; height < minSnapshotHeight; height += m.checkpointInterval {
+		block, err := m.chain.GetSnapshotBlockByHeight(height)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			continue
+		}
+		if ok, err := m.chain.ShallowCheckStateTrie(&block.StateHash); err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+		if err := m.setSnapshotBlockNodeHashSet(block, hashSet, refHashSet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrunedHeight returns the height below which the states of the snapshot blocks are pruned except the checkpoints,
+// 0 if nothing is pruned. It is saved with the deletions, so the states pruned before the node started are included.
+func (m *Marker) PrunedHeight() (uint64, error) {
+	key, _ := database.EncodeKey(database.DBKP_TRIE_GC_PRUNED_HEIGHT)
+	value, err := m.chain.ChainDb().Db().Get(key, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func (m *Marker) clean(hashSet map[types.Hash]struct{}, refHashSet map[types.Hash]struct{}, prunedHeight uint64) (uint64, error) {
 	m.chain.StopSaveTrie()
 	defer m.chain.StartSaveTrie()
 
 	batch := new(leveldb.Batch)
+	deleted := uint64(0)
+
+	// a rollback of the snapshot chain lowers the min snapshot height, the states above it are pruned already
+	if savedHeight, err := m.PrunedHeight(); err != nil {
+		return 0, err
+	} else if prunedHeight > savedHeight {
+		key, _ := database.EncodeKey(database.DBKP_TRIE_GC_PRUNED_HEIGHT)
+		value := make([]byte, 8)
+		binary.BigEndian.PutUint64(value, prunedHeight)
+		batch.Put(key, value)
+	}
 
 	// clear trie node
 	dbkey, _ := database.EncodeKey(database.DBKP_TRIE_NODE)
@@ -178,6 +249,7 @@ func (m *Marker) clean(hashSet map[types.Hash]struct{}, refHashSet map[types.Has
 
 		if _, ok := hashSet[hash]; !ok {
 			batch.Delete(iter.Key())
+			deleted++
 		}
 	}
 
@@ -191,21 +263,22 @@ func (m *Marker) clean(hashSet map[types.Hash]struct{}, refHashSet map[types.Has
 
 		if _, ok := refHashSet[hash]; !ok {
 			batch.Delete(refIter.Key())
+			deleted++
 		}
 	}
 
 	if err := m.chain.ChainDb().Commit(batch); err != nil {
-		return err
+		return 0, err
 	}
 
 	// clear cache
 	m.chain.CleanTrieNodePool()
 
 	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
-		return err
+		return 0, err
 	}
 
-	return nil
+	return deleted, nil
 }
 
 func (m *Marker) setAccountBlockNodeHashSet(accountBlock *ledger.AccountBlock, hashSet map[types.Hash]struct{}, refHashSet map[types.Hash]struct{}) error {
@@ -241,7 +314,7 @@ func (m *Marker) setNodeHashSet(stateHash types.Hash, hashSet map[types.Hash]str
 		return true
 	}
 
-	stateTrie := trie.NewTrie(m.chain.ChainDb().Db(), &stateHash, m.triePool)
+	stateTrie := trie.NewTrie(m.chain.TrieDb(), &stateHash, m.triePool)
 
 	if stateTrie.Root == nil {
 		return errors.New(fmt.Sprintf("stateTrie.Root is nil, stateHash is %s", stateHash))
@@ -86,7 +86,7 @@ func (gc *collector) saveTrie(t *trie.Trie) error {
 }
 
 func isNodeExist(chainInstance Chain, node *trie.TrieNode) (bool, error) {
-	db := chainInstance.ChainDb().Db()
+	db := chainInstance.TrieDb()
 
 	nodeHash := node.Hash()
 	dbKey, _ := database.EncodeKey(database.DBKP_TRIE_NODE, nodeHash.Bytes())
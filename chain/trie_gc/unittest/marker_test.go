@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/syndtr/goleveldb/leveldb/util"
 	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/trie_gc"
 	"github.com/vitelabs/go-vite/chain_db/access"
 	"github.com/vitelabs/go-vite/chain_db/database"
 	"github.com/vitelabs/go-vite/common/types"
@@ -190,11 +191,19 @@ func Test_marker_mark_clean(t *testing.T) {
 	}
 
 	fmt.Printf("marker.MarkAndClean...\n")
-	err2 := marker.MarkAndClean(nil)
+	prunedHeight, _, err2 := marker.MarkAndClean(nil)
 	if err2 != nil {
 		t.Fatal(err2)
 	}
 
+	// the pruned height is saved, a collector created after a restart reads it from the db
+	if savedHeight, err := marker.PrunedHeight(); err != nil || savedHeight < prunedHeight {
+		t.Fatalf("unexpected saved pruned height %d, %v, pruned %d", savedHeight, err, prunedHeight)
+	}
+	if prunedHeight > 1 && !trie_gc.NewCollector(chainInstance, 0, 0).IsPruned(prunedHeight-1) {
+		t.Fatalf("the state of %d should be pruned", prunedHeight-1)
+	}
+
 	fmt.Printf("loadNewAllHashSet...\n")
 	newAllHashSet := loadAllHashSet(chainInstance)
 
@@ -213,3 +222,17 @@ func Test_marker_mark_clean(t *testing.T) {
 	cleanRefHashSet := filterHashSet(allRefHashSet, newAllRefHashSet)
 	fmt.Printf("Clean %d ref nodes\n", len(cleanRefHashSet))
 }
+
+func TestMarker_IsCheckpoint(t *testing.T) {
+	marker := trie_gc.NewMarker(nil, 100, 0)
+	if marker.IsCheckpoint(1000) {
+		t.Fatal("no checkpoint should be kept if the interval is 0")
+	}
+
+	marker = trie_gc.NewMarker(nil, 100, 1000)
+	for height, expected := range map[uint64]bool{999: false, 1000: true, 1001: false, 3000: true} {
+		if marker.IsCheckpoint(height) != expected {
+			t.Fatalf("IsCheckpoint(%d) should be %v", height, expected)
+		}
+	}
+}
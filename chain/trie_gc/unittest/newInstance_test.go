@@ -162,5 +162,5 @@ func newChainInstance(dirName string, clearDataDir bool) chain.Chain {
 }
 
 func newMarkerInstance(chainInstance chain.Chain) *trie_gc.Marker {
-	return trie_gc.NewMarker(chainInstance, 0)
+	return trie_gc.NewMarker(chainInstance, 0, 0)
 }
@@ -53,7 +53,7 @@ func deleteAllTrie(chainInstance chain.Chain) error {
 }
 
 func recoverTrie(chainInstance chain.Chain) error {
-	collector := trie_gc.NewCollector(chainInstance, 0)
+	collector := trie_gc.NewCollector(chainInstance, 0, 0)
 	return collector.Recover()
 }
 
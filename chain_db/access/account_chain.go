@@ -8,6 +8,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/util"
 	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/chain_db/freezer"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
@@ -26,20 +27,114 @@ func getAccountBlockHeight(dbKey []byte) uint64 {
 }
 
 type AccountChain struct {
-	db *leveldb.DB
+	db       *leveldb.DB
+	logDb    *leveldb.DB
+	indexDb  *leveldb.DB
+	compress bool
+	freezer  *freezer.Freezer
 }
 
 func NewAccountChain(db *leveldb.DB) *AccountChain {
 	return &AccountChain{
-		db: db,
+		db:      db,
+		logDb:   db,
+		indexDb: db,
 	}
 }
 
+// SetKeyspaceDbs sets the dbs which the vm log lists and the destination tags are read from.
+func (ac *AccountChain) SetKeyspaceDbs(logDb, indexDb *leveldb.DB) {
+	ac.logDb = logDb
+	ac.indexDb = indexDb
+}
+
+// SetCompress sets whether the account blocks and vm log lists are compressed when written,
+// the compressed and the raw values are both readable regardless of it.
+func (ac *AccountChain) SetCompress(compress bool) {
+	ac.compress = compress
+}
+
+// SetFreezer sets the freezer which the frozen account blocks are read from.
+func (ac *AccountChain) SetFreezer(f *freezer.Freezer) {
+	ac.freezer = f
+}
+
+// readValue returns the raw value of buf, reading it from the freezer if it is frozen.
+func (ac *AccountChain) readValue(buf []byte) ([]byte, error) {
+	if pointerBytes := database.FrozenPointer(buf); pointerBytes != nil {
+		if ac.freezer == nil {
+			return nil, errors.New("the value is frozen but there is no freezer")
+		}
+		pointer, err := freezer.PointerFromBytes(pointerBytes)
+		if err != nil {
+			return nil, err
+		}
+		if buf, err = ac.freezer.Read(pointer); err != nil {
+			return nil, err
+		}
+	}
+	return database.DecodeValue(buf)
+}
+
+func (ac *AccountChain) deserializeBlock(block *ledger.AccountBlock, buf []byte) error {
+	raw, err := ac.readValue(buf)
+	if err != nil {
+		return err
+	}
+	return block.DbDeserialize(raw)
+}
+
 func (ac *AccountChain) DeleteBlock(batch *leveldb.Batch, accountId uint64, height uint64, hash *types.Hash) {
 	key, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, accountId, height, hash.Bytes())
 	batch.Delete(key)
 }
 
+func (ac *AccountChain) destinationTagKey(accountId uint64, block *ledger.AccountBlock) []byte {
+	tag, ok := block.DestinationTag()
+	if !ok {
+		return nil
+	}
+	key, _ := database.EncodeKey(database.DBKP_DESTINATION_TAG, block.ToAddress.Bytes(), tag, accountId, block.Height)
+	return key
+}
+
+func (ac *AccountChain) writeDestinationTag(batch *leveldb.Batch, accountId uint64, block *ledger.AccountBlock) {
+	if key := ac.destinationTagKey(accountId, block); key != nil {
+		batch.Put(key, block.Hash.Bytes())
+	}
+}
+
+func (ac *AccountChain) deleteDestinationTag(batch *leveldb.Batch, accountId uint64, block *ledger.AccountBlock) {
+	if key := ac.destinationTagKey(accountId, block); key != nil {
+		batch.Delete(key)
+	}
+}
+
+// GetHashListByDestinationTag returns the hashes of the send blocks to addr carrying tag, skipping the first
+// index ones. The blocks are ordered by the sender account and height.
+func (ac *AccountChain) GetHashListByDestinationTag(addr *types.Address, tag uint64, index, count int) ([]*types.Hash, error) {
+	key, _ := database.EncodeKey(database.DBKP_DESTINATION_TAG, addr.Bytes(), tag)
+	iter := ac.indexDb.NewIterator(util.BytesPrefix(key), nil)
+	defer iter.Release()
+
+	var hashList []*types.Hash
+	for i := 0; iter.Next() && len(hashList) < count; i++ {
+		if i < index {
+			continue
+		}
+		hash, err := types.BytesToHash(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		hashList = append(hashList, &hash)
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+	return hashList, nil
+}
+
 func (ac *AccountChain) DeleteBlockMeta(batch *leveldb.Batch, hash *types.Hash) {
 	key, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCKMETA, hash.Bytes())
 	batch.Delete(key)
@@ -55,7 +150,10 @@ func (ac *AccountChain) WriteBlock(batch *leveldb.Batch, accountId uint64, block
 
 	key, err := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, accountId, block.Height, block.Hash.Bytes())
 
-	batch.Put(key, buf)
+	batch.Put(key, database.EncodeValue(buf, ac.compress))
+
+	// Index destination tag
+	ac.writeDestinationTag(batch, accountId, block)
 	return nil
 }
 
@@ -140,7 +238,7 @@ func (ac *AccountChain) GetLatestBlock(accountId uint64) (*ledger.AccountBlock,
 		return nil, nil
 	}
 	block := &ledger.AccountBlock{}
-	if ddsErr := block.DbDeserialize(iter.Value()); ddsErr != nil {
+	if ddsErr := ac.deserializeBlock(block, iter.Value()); ddsErr != nil {
 		return nil, ddsErr
 	}
 
@@ -168,7 +266,7 @@ func (ac *AccountChain) GetBlockListByAccountId(accountId, startHeight, endHeigh
 	i := uint64(0)
 	for ; iter.Next(); i++ {
 		block := &ledger.AccountBlock{}
-		err := block.DbDeserialize(iter.Value())
+		err := ac.deserializeBlock(block, iter.Value())
 
 		if err != nil {
 			return nil, err
@@ -197,6 +295,37 @@ func (ac *AccountChain) GetBlockListByAccountId(accountId, startHeight, endHeigh
 	}
 }
 
+// GetBlocksBySnapshotHash returns the blocks from endHeight downwards which refer to snapshotHash, in descending
+// order of height. The blocks of an account refer to the snapshot blocks in ascending order, so it's one reverse
+// iteration stopped at the first block referring to another snapshot block.
+func (ac *AccountChain) GetBlocksBySnapshotHash(accountId, endHeight uint64, snapshotHash *types.Hash) ([]*ledger.AccountBlock, error) {
+	startKey, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, accountId, 1)
+	limitKey, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, accountId, endHeight+1)
+
+	iter := ac.db.NewIterator(&util.Range{Start: startKey, Limit: limitKey}, nil)
+	defer iter.Release()
+
+	var blockList []*ledger.AccountBlock
+	for iterOk := iter.Last(); iterOk; iterOk = iter.Prev() {
+		block := &ledger.AccountBlock{}
+		if err := ac.deserializeBlock(block, iter.Value()); err != nil {
+			return nil, err
+		}
+		if block.SnapshotHash != *snapshotHash {
+			break
+		}
+
+		block.Hash = *getAccountBlockHash(iter.Key())
+		blockList = append(blockList, block)
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+
+	return blockList, nil
+}
+
 func (ac *AccountChain) GetBlock(blockHash *types.Hash) (*ledger.AccountBlock, error) {
 	blockMeta, gbmErr := ac.GetBlockMeta(blockHash)
 	if gbmErr != nil {
@@ -218,7 +347,7 @@ func (ac *AccountChain) GetBlock(blockHash *types.Hash) (*ledger.AccountBlock, e
 	}
 
 	accountBlock := &ledger.AccountBlock{}
-	if dsErr := accountBlock.DbDeserialize(data); dsErr != nil {
+	if dsErr := ac.deserializeBlock(accountBlock, data); dsErr != nil {
 		return nil, dsErr
 	}
 	accountBlock.Hash = *blockHash
@@ -257,7 +386,7 @@ func (ac *AccountChain) GetBlockMeta(blockHash *types.Hash) (*ledger.AccountBloc
 
 func (ac *AccountChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
 	key, _ := database.EncodeKey(database.DBKP_LOG_LIST, logListHash.Bytes())
-	data, err := ac.db.Get(key, nil)
+	data, err := ac.logDb.Get(key, nil)
 	if err != nil {
 		if err != leveldb.ErrNotFound {
 			return nil, err
@@ -265,7 +394,11 @@ func (ac *AccountChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList,
 		return nil, nil
 	}
 
-	vmLogList, dErr := ledger.VmLogListDeserialize(data)
+	raw, dErr := database.DecodeValue(data)
+	if dErr != nil {
+		return nil, dErr
+	}
+	vmLogList, dErr := ledger.VmLogListDeserialize(raw)
 	if dErr != nil {
 		return nil, err
 	}
@@ -332,7 +465,7 @@ func (ac *AccountChain) WriteVmLogList(batch *leveldb.Batch, logList ledger.VmLo
 		return err
 	}
 
-	batch.Put(key, buf)
+	batch.Put(key, database.EncodeValue(buf, ac.compress))
 
 	return nil
 }
@@ -354,7 +487,7 @@ func (ac *AccountChain) GetBlockByHeight(accountId uint64, height uint64) (*ledg
 	}
 
 	accountBlock := &ledger.AccountBlock{}
-	if dsErr := accountBlock.DbDeserialize(iter.Value()); dsErr != nil {
+	if dsErr := ac.deserializeBlock(accountBlock, iter.Value()); dsErr != nil {
 		return nil, dsErr
 	}
 
@@ -440,7 +573,7 @@ func (ac *AccountChain) deleteChain(batch *leveldb.Batch, accountId uint64, toHe
 	for iter.Next() {
 
 		deleteBlock := &ledger.AccountBlock{}
-		if dsErr := deleteBlock.DbDeserialize(iter.Value()); dsErr != nil {
+		if dsErr := ac.deserializeBlock(deleteBlock, iter.Value()); dsErr != nil {
 			return nil, dsErr
 		}
 
@@ -454,6 +587,9 @@ func (ac *AccountChain) deleteChain(batch *leveldb.Batch, accountId uint64, toHe
 		// Delete block
 		ac.DeleteBlock(batch, accountId, deleteBlock.Height, &deleteBlock.Hash)
 
+		// Delete destination tag index
+		ac.deleteDestinationTag(batch, accountId, deleteBlock)
+
 		// Delete block meta
 		ac.DeleteBlockMeta(batch, &deleteBlock.Hash)
 
@@ -514,7 +650,7 @@ func (ac *AccountChain) GetDeleteMapAndReopenList(planToDelete map[uint64]uint64
 			for iter.Next() {
 				accountBlock := &ledger.AccountBlock{}
 
-				if dsErr := accountBlock.DbDeserialize(iter.Value()); dsErr != nil {
+				if dsErr := ac.deserializeBlock(accountBlock, iter.Value()); dsErr != nil {
 					iter.Release()
 					return nil, nil, dsErr
 				}
@@ -708,7 +844,7 @@ func (ac *AccountChain) GetConfirmAccountBlock(snapshotHeight uint64, accountId
 		}
 		if accountBlockMeta.SnapshotHeight > 0 && accountBlockMeta.SnapshotHeight <= snapshotHeight {
 			accountBlock := &ledger.AccountBlock{}
-			if dsErr := accountBlock.DbDeserialize(iter.Value()); dsErr != nil {
+			if dsErr := ac.deserializeBlock(accountBlock, iter.Value()); dsErr != nil {
 				return nil, dsErr
 			}
 
@@ -789,7 +925,7 @@ func (ac *AccountChain) GetUnConfirmAccountBlocks(accountId uint64, beforeHeight
 		}
 		if accountBlockMeta.SnapshotHeight <= 0 {
 			accountBlock := &ledger.AccountBlock{}
-			if dsErr := accountBlock.DbDeserialize(iter.Value()); dsErr != nil {
+			if dsErr := ac.deserializeBlock(accountBlock, iter.Value()); dsErr != nil {
 				return nil, dsErr
 			}
 
@@ -829,7 +965,7 @@ func (ac *AccountChain) GetSendAndReceiveBlocks(accountId uint64, snapshotBlockH
 
 	for iter.Next() {
 		accountBlock := &ledger.AccountBlock{}
-		if dsErr := accountBlock.DbDeserialize(iter.Value()); dsErr != nil {
+		if dsErr := ac.deserializeBlock(accountBlock, iter.Value()); dsErr != nil {
 			return nil, nil, dsErr
 		}
 
@@ -882,7 +1018,7 @@ func (ac *AccountChain) GetSendBlocks(accountId uint64, snapshotBlockHeight uint
 
 	for iter.Next() {
 		accountBlock := &ledger.AccountBlock{}
-		if dsErr := accountBlock.DbDeserialize(iter.Value()); dsErr != nil {
+		if dsErr := ac.deserializeBlock(accountBlock, iter.Value()); dsErr != nil {
 			return nil, dsErr
 		}
 
@@ -0,0 +1,39 @@
+package access
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db/database"
+)
+
+// RpcFilter stores the durable filters of the subscribe api, the values are opaque to the chain.
+type RpcFilter struct {
+	db *leveldb.DB
+}
+
+func NewRpcFilter(db *leveldb.DB) *RpcFilter {
+	return &RpcFilter{
+		db: db,
+	}
+}
+
+func (rf *RpcFilter) GetFilter(id string) ([]byte, error) {
+	key, _ := database.EncodeKey(database.DBKP_RPC_FILTER, []byte(id))
+	value, err := rf.db.Get(key, nil)
+	if err != nil {
+		if err != leveldb.ErrNotFound {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return value, nil
+}
+
+func (rf *RpcFilter) WriteFilter(id string, value []byte) error {
+	key, _ := database.EncodeKey(database.DBKP_RPC_FILTER, []byte(id))
+	return rf.db.Put(key, value, nil)
+}
+
+func (rf *RpcFilter) DeleteFilter(id string) error {
+	key, _ := database.EncodeKey(database.DBKP_RPC_FILTER, []byte(id))
+	return rf.db.Delete(key, nil)
+}
@@ -14,11 +14,15 @@ type ChainDb struct {
 	dbDir string
 	db    *leveldb.DB
 
+	// keyspaceDbs are the dbs of the keyspaces split from db, by key prefix
+	keyspaceDbs map[byte]*leveldb.DB
+
 	Ac      *access.AccountChain
 	Sc      *access.SnapshotChain
 	Account *access.Account
 	Be      *access.BlockEvent
 	OnRoad  *access.OnRoad
+	Filter  *access.RpcFilter
 
 	log log15.Logger
 }
@@ -62,19 +66,26 @@ func (chainDb *ChainDb) initDb() error {
 	chainDb.Account = access.NewAccount(db)
 	chainDb.Be = access.NewBlockEvent(db)
 	chainDb.OnRoad = access.NewOnRoad(db)
+	chainDb.Filter = access.NewRpcFilter(db)
 
-	return nil
+	return chainDb.openKeyspaces()
 }
 
 func (chainDb *ChainDb) ClearData() error {
 	if chainDb.db != nil {
-		if closeErr := chainDb.db.Close(); closeErr != nil {
+		if closeErr := chainDb.Close(); closeErr != nil {
 			return errors.New("Close db failed, error is " + closeErr.Error())
 		}
 	}
 
-	if err := os.RemoveAll(chainDb.dbDir); err != nil && err != os.ErrNotExist {
-		return errors.New("Remove " + chainDb.dbDir + " failed, error is " + err.Error())
+	dirList := []string{chainDb.dbDir}
+	for _, keyspace := range database.Keyspaces {
+		dirList = append(dirList, chainDb.keyspaceDir(keyspace))
+	}
+	for _, dir := range dirList {
+		if err := os.RemoveAll(dir); err != nil && err != os.ErrNotExist {
+			return errors.New("Remove " + dir + " failed, error is " + err.Error())
+		}
 	}
 
 	chainDb.db = nil
@@ -85,6 +96,34 @@ func (chainDb *ChainDb) Db() *leveldb.DB {
 	return chainDb.db
 }
 
+// Commit writes batch, routing the keys of the split keyspaces to their dbs.
 func (chainDb *ChainDb) Commit(batch *leveldb.Batch) error {
-	return chainDb.db.Write(batch, nil)
+	if len(chainDb.keyspaceDbs) == 0 {
+		return chainDb.db.Write(batch, nil)
+	}
+
+	router := &keyspaceRouter{
+		chainDb: chainDb,
+		main:    new(leveldb.Batch),
+		ops:     make(map[*leveldb.DB]map[string]*keyspaceOp),
+	}
+	if err := batch.Replay(router); err != nil {
+		return err
+	}
+	return router.write()
+}
+
+// Close closes the main db and the keyspace dbs.
+func (chainDb *ChainDb) Close() error {
+	var closeErr error
+	for _, db := range chainDb.keyspaceDbList() {
+		if err := db.Close(); err != nil {
+			closeErr = err
+		}
+	}
+	chainDb.keyspaceDbs = nil
+	if err := chainDb.db.Close(); err != nil {
+		closeErr = err
+	}
+	return closeErr
 }
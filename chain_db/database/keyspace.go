@@ -0,0 +1,56 @@
+package database
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// Keyspace is a group of key prefixes which can be stored in a leveldb of its own, so that it is compacted
+// with the options fitting its access pattern and its writes don't churn the reads of the other keys.
+type Keyspace struct {
+	Name     string
+	Prefixes []byte
+	Options  *opt.Options
+}
+
+// Keyspaces are the keyspaces stored apart from the main db when the chain db is split. The account blocks,
+// snapshot blocks and their metas stay in the main db.
+var Keyspaces = []*Keyspace{
+	{
+		// the trie nodes are read randomly by hash
+		Name:     "state",
+		Prefixes: []byte{DBKP_TRIE_NODE, DBKP_TRIE_REF_VALUE},
+		Options: &opt.Options{
+			BlockCacheCapacity: 256 * opt.MiB,
+			Filter:             filter.NewBloomFilter(10),
+		},
+	},
+	{
+		// the vm log lists are written heavily and read rarely, and they are compressed by the chain already
+		Name:     "logs",
+		Prefixes: []byte{DBKP_LOG_LIST},
+		Options: &opt.Options{
+			BlockCacheCapacity:  16 * opt.MiB,
+			Compression:         opt.NoCompression,
+			WriteBuffer:         64 * opt.MiB,
+			CompactionTableSize: 8 * opt.MiB,
+		},
+	},
+	{
+		Name:     "indexes",
+		Prefixes: []byte{DBKP_DESTINATION_TAG, DBKP_RPC_FILTER},
+		Options: &opt.Options{
+			BlockCacheCapacity: 32 * opt.MiB,
+		},
+	},
+}
+
+func NewKeyspaceDb(dbDir string, keyspace *Keyspace) (*leveldb.DB, error) {
+	db, err := leveldb.OpenFile(dbDir, keyspace.Options)
+
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
@@ -36,4 +36,12 @@ const (
 	DBKP_BE_SNAPSHOT = byte(17)
 
 	DBKP_ADDITIONAL_LIST = byte(18)
+
+	DBKP_COMPRESS_MIGRATION = byte(19)
+
+	DBKP_DESTINATION_TAG = byte(20)
+
+	DBKP_RPC_FILTER = byte(21)
+
+	DBKP_TRIE_GC_PRUNED_HEIGHT = byte(22)
 )
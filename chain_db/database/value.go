@@ -0,0 +1,63 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/golang/snappy"
+)
+
+// A compressed or frozen value starts with valueMarker followed by its type. The proto
+// encoded values never start with 0 because field number 0 is invalid, so the values
+// written before compression was enabled are read as they are.
+const (
+	valueMarker = byte(0)
+
+	VALUE_SNAPPY = byte(1)
+	// VALUE_FROZEN values hold the freezer pointer of the value moved out of leveldb
+	VALUE_FROZEN = byte(2)
+)
+
+var ErrUnknownValueCompression = errors.New("unknown value compression")
+
+// EncodeValue compresses buf with snappy if compress is set, otherwise it returns buf.
+func EncodeValue(buf []byte, compress bool) []byte {
+	if !compress {
+		return buf
+	}
+	encoded := make([]byte, 2+snappy.MaxEncodedLen(len(buf)))
+	encoded[0] = valueMarker
+	encoded[1] = VALUE_SNAPPY
+	n := len(snappy.Encode(encoded[2:], buf))
+	return encoded[:2+n]
+}
+
+// DecodeValue returns the raw value of buf whether it is compressed or not, frozen values
+// must be read from the freezer first.
+func DecodeValue(buf []byte) ([]byte, error) {
+	if !IsEncodedValue(buf) {
+		return buf, nil
+	}
+	switch buf[1] {
+	case VALUE_SNAPPY:
+		return snappy.Decode(nil, buf[2:])
+	default:
+		return nil, ErrUnknownValueCompression
+	}
+}
+
+// IsEncodedValue reports whether buf is a compressed or frozen value rather than a raw one.
+func IsEncodedValue(buf []byte) bool {
+	return len(buf) >= 2 && buf[0] == valueMarker
+}
+
+func EncodeFrozenValue(pointer []byte) []byte {
+	return append([]byte{valueMarker, VALUE_FROZEN}, pointer...)
+}
+
+// FrozenPointer returns the freezer pointer held by buf, nil if buf is not a frozen value.
+func FrozenPointer(buf []byte) []byte {
+	if !IsEncodedValue(buf) || buf[1] != VALUE_FROZEN {
+		return nil
+	}
+	return buf[2:]
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeValue(t *testing.T) {
+	raw := bytes.Repeat([]byte{8, 1, 18, 32}, 64)
+
+	if buf := EncodeValue(raw, false); !bytes.Equal(buf, raw) || IsEncodedValue(buf) {
+		t.Fatal("value should be kept as it is if compress is not set")
+	}
+
+	compressed := EncodeValue(raw, true)
+	if !IsEncodedValue(compressed) || len(compressed) >= len(raw) {
+		t.Fatalf("value should be compressed, got %d bytes", len(compressed))
+	}
+	for _, buf := range [][]byte{raw, compressed} {
+		decoded, err := DecodeValue(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(decoded, raw) {
+			t.Fatal("decoded value mismatch")
+		}
+	}
+
+	if _, err := DecodeValue([]byte{valueMarker, 100, 1}); err != ErrUnknownValueCompression {
+		t.Fatalf("expected ErrUnknownValueCompression, got %v", err)
+	}
+}
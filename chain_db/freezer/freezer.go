@@ -0,0 +1,232 @@
+package freezer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	defaultMaxFileSize = 512 * 1024 * 1024
+
+	PointerSize = 16
+)
+
+var (
+	ErrInvalidPointer = errors.New("invalid freezer pointer")
+	ErrFreezerClosed  = errors.New("freezer is closed")
+)
+
+// Pointer locates an item in the freezer files, it is kept in leveldb in place of the item.
+type Pointer struct {
+	File   uint32
+	Offset uint64
+	Length uint32
+}
+
+func (p Pointer) Bytes() []byte {
+	buf := make([]byte, PointerSize)
+	binary.BigEndian.PutUint32(buf[0:4], p.File)
+	binary.BigEndian.PutUint64(buf[4:12], p.Offset)
+	binary.BigEndian.PutUint32(buf[12:16], p.Length)
+	return buf
+}
+
+func PointerFromBytes(buf []byte) (Pointer, error) {
+	if len(buf) != PointerSize {
+		return Pointer{}, ErrInvalidPointer
+	}
+	return Pointer{
+		File:   binary.BigEndian.Uint32(buf[0:4]),
+		Offset: binary.BigEndian.Uint64(buf[4:12]),
+		Length: binary.BigEndian.Uint32(buf[12:16]),
+	}, nil
+}
+
+// Freezer is an append-only store of immutable items in flat files. Only the last file is
+// appended to, the others are sealed and are memory-mapped for reading where supported.
+// Items are located by the Pointer returned by Append, there is no index besides it.
+type Freezer struct {
+	dir         string
+	maxFileSize int64
+
+	lock     sync.RWMutex
+	head     *os.File
+	headNum  uint32
+	headSize int64
+	sealed   map[uint32]readerAt
+	closed   bool
+}
+
+func NewFreezer(dir string) (*Freezer, error) {
+	return newFreezer(dir, defaultMaxFileSize)
+}
+
+func newFreezer(dir string, maxFileSize int64) (*Freezer, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	f := &Freezer{
+		dir:         dir,
+		maxFileSize: maxFileSize,
+		sealed:      make(map[uint32]readerAt),
+	}
+
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if num, ok := parseFileName(info.Name()); ok && num > f.headNum {
+			f.headNum = num
+		}
+	}
+	if err := f.openHead(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *Freezer) fileName(num uint32) string {
+	return filepath.Join(f.dir, fmt.Sprintf("blocks.%06d.dat", num))
+}
+
+func parseFileName(name string) (uint32, bool) {
+	if !strings.HasPrefix(name, "blocks.") || !strings.HasSuffix(name, ".dat") {
+		return 0, false
+	}
+	num, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, "blocks."), ".dat"), 10, 32)
+	if err != nil {
+		return 0, false
+	}
+	return uint32(num), true
+}
+
+func (f *Freezer) openHead() error {
+	head, err := os.OpenFile(f.fileName(f.headNum), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	size, err := head.Seek(0, io.SeekEnd)
+	if err != nil {
+		head.Close()
+		return err
+	}
+	f.head = head
+	f.headSize = size
+	return nil
+}
+
+// Append writes data at the end of the head file, a new head file is started once it is full.
+// The data is not durable until Sync returns.
+func (f *Freezer) Append(data []byte) (Pointer, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.closed {
+		return Pointer{}, ErrFreezerClosed
+	}
+
+	if f.headSize > 0 && f.headSize+int64(len(data)) > f.maxFileSize {
+		if err := f.head.Sync(); err != nil {
+			return Pointer{}, err
+		}
+		if err := f.head.Close(); err != nil {
+			return Pointer{}, err
+		}
+		f.headNum++
+		if err := f.openHead(); err != nil {
+			return Pointer{}, err
+		}
+	}
+
+	if _, err := f.head.Write(data); err != nil {
+		return Pointer{}, err
+	}
+	p := Pointer{File: f.headNum, Offset: uint64(f.headSize), Length: uint32(len(data))}
+	f.headSize += int64(len(data))
+	return p, nil
+}
+
+func (f *Freezer) Sync() error {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	if f.closed {
+		return ErrFreezerClosed
+	}
+	return f.head.Sync()
+}
+
+// Read returns a copy of the item located by p.
+func (f *Freezer) Read(p Pointer) ([]byte, error) {
+	if err := f.openSealed(p.File); err != nil {
+		return nil, err
+	}
+
+	// the read lock is held while reading, so that the sealed file isn't unmapped meanwhile
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	if f.closed {
+		return nil, ErrFreezerClosed
+	}
+	var r readerAt = f.head
+	if p.File != f.headNum {
+		r = f.sealed[p.File]
+	}
+	buf := make([]byte, p.Length)
+	if _, err := r.ReadAt(buf, int64(p.Offset)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// openSealed opens the sealed file num for reading if it isn't yet.
+func (f *Freezer) openSealed(num uint32) error {
+	f.lock.RLock()
+	closed, headNum, opened := f.closed, f.headNum, f.sealed[num] != nil
+	f.lock.RUnlock()
+	if closed {
+		return ErrFreezerClosed
+	}
+	if num > headNum {
+		return ErrInvalidPointer
+	}
+	if num == headNum || opened {
+		return nil
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.closed {
+		return ErrFreezerClosed
+	}
+	if f.sealed[num] != nil {
+		return nil
+	}
+	r, err := openSealed(f.fileName(num))
+	if err != nil {
+		return err
+	}
+	f.sealed[num] = r
+	return nil
+}
+
+func (f *Freezer) Close() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	for _, r := range f.sealed {
+		r.Close()
+	}
+	f.sealed = nil
+	return f.head.Close()
+}
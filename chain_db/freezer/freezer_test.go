@@ -0,0 +1,69 @@
+package freezer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestFreezer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	f, err := newFreezer(dir, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var items [][]byte
+	var pointers []Pointer
+	for i := 0; i < 10; i++ {
+		item := bytes.Repeat([]byte{byte(i)}, 10+i)
+		p, err := f.Append(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		items = append(items, item)
+		pointers = append(pointers, p)
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if pointers[len(pointers)-1].File == 0 {
+		t.Fatal("the head file should have been rolled over")
+	}
+
+	check := func(f *Freezer) {
+		for i, p := range pointers {
+			decoded, err := PointerFromBytes(p.Bytes())
+			if err != nil || decoded != p {
+				t.Fatalf("pointer %v decoded to %v, %v", p, decoded, err)
+			}
+			item, err := f.Read(decoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(item, items[i]) {
+				t.Fatalf("item %d mismatch", i)
+			}
+		}
+	}
+	check(f)
+	f.Close()
+
+	reopened, err := newFreezer(dir, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	check(reopened)
+	if p, err := reopened.Append([]byte{1}); err != nil || p.File < pointers[len(pointers)-1].File {
+		t.Fatalf("append after reopen should go to the head file, got %v %v", p, err)
+	}
+	if _, err := reopened.Read(Pointer{File: 100}); err != ErrInvalidPointer {
+		t.Fatalf("expected ErrInvalidPointer, got %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package freezer
+
+import "os"
+
+// openSealed falls back to reading the sealed files with pread where mmap is not used.
+func openSealed(name string) (readerAt, error) {
+	return os.Open(name)
+}
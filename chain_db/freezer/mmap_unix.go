@@ -0,0 +1,47 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package freezer
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// mmapFile is a read-only memory-mapped sealed freezer file.
+type mmapFile []byte
+
+func openSealed(name string) (readerAt, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() == 0 {
+		return mmapFile(nil), nil
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, err
+	}
+	return mmapFile(data), nil
+}
+
+func (m mmapFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > int64(len(m)) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return copy(p, m[off:]), nil
+}
+
+func (m mmapFile) Close() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return syscall.Munmap(m)
+}
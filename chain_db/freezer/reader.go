@@ -0,0 +1,6 @@
+package freezer
+
+type readerAt interface {
+	ReadAt(p []byte, off int64) (int, error)
+	Close() error
+}
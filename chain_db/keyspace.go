@@ -0,0 +1,276 @@
+package chain_db
+
+import (
+	"errors"
+	"os"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vitelabs/go-vite/chain_db/access"
+	"github.com/vitelabs/go-vite/chain_db/database"
+)
+
+// moveBatchSize is the number of keys moved between the main db and a keyspace db in one batch
+const moveBatchSize = 10000
+
+func (chainDb *ChainDb) keyspaceDir(keyspace *database.Keyspace) string {
+	return chainDb.dbDir + "_" + keyspace.Name
+}
+
+// openKeyspaces opens the keyspace dbs existing on disk. The keys of their prefixes left in the main db by
+// an interrupted migration are moved to them, so that every key is in exactly one db.
+func (chainDb *ChainDb) openKeyspaces() error {
+	chainDb.keyspaceDbs = make(map[byte]*leveldb.DB)
+	for _, keyspace := range database.Keyspaces {
+		if _, err := os.Stat(chainDb.keyspaceDir(keyspace)); os.IsNotExist(err) {
+			continue
+		}
+		if err := chainDb.openKeyspace(keyspace); err != nil {
+			return err
+		}
+	}
+	chainDb.resetAccess()
+	return nil
+}
+
+func (chainDb *ChainDb) openKeyspace(keyspace *database.Keyspace) error {
+	db, err := database.NewKeyspaceDb(chainDb.keyspaceDir(keyspace), keyspace)
+	if err != nil {
+		return errors.New("open keyspace " + keyspace.Name + " failed, error is " + err.Error())
+	}
+	for _, prefix := range keyspace.Prefixes {
+		chainDb.keyspaceDbs[prefix] = db
+	}
+
+	count, err := moveKeys(chainDb.db, db, keyspace.Prefixes)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		chainDb.log.Info("moved keys to keyspace", "keyspace", keyspace.Name, "count", count)
+	}
+	return nil
+}
+
+// resetAccess points the access objects reading the split keyspaces directly to their dbs.
+func (chainDb *ChainDb) resetAccess() {
+	chainDb.Ac.SetKeyspaceDbs(chainDb.KeyspaceDb(database.DBKP_LOG_LIST), chainDb.KeyspaceDb(database.DBKP_DESTINATION_TAG))
+	chainDb.Filter = access.NewRpcFilter(chainDb.KeyspaceDb(database.DBKP_RPC_FILTER))
+}
+
+// SplitKeyspaces moves the keyspaces in database.Keyspaces out of the main db into leveldbs of their own,
+// which are stored beside it. It does nothing for the keyspaces split already.
+func (chainDb *ChainDb) SplitKeyspaces() error {
+	for _, keyspace := range database.Keyspaces {
+		if _, ok := chainDb.keyspaceDbs[keyspace.Prefixes[0]]; ok {
+			continue
+		}
+		if err := chainDb.openKeyspace(keyspace); err != nil {
+			return err
+		}
+	}
+	chainDb.resetAccess()
+	return nil
+}
+
+// MergeKeyspaces moves the split keyspaces back into the main db and removes their dbs.
+func (chainDb *ChainDb) MergeKeyspaces() error {
+	for _, keyspace := range database.Keyspaces {
+		db, ok := chainDb.keyspaceDbs[keyspace.Prefixes[0]]
+		if !ok {
+			continue
+		}
+		count, err := moveKeys(db, chainDb.db, keyspace.Prefixes)
+		if err != nil {
+			return err
+		}
+		chainDb.log.Info("moved keys to the main db", "keyspace", keyspace.Name, "count", count)
+
+		for _, prefix := range keyspace.Prefixes {
+			delete(chainDb.keyspaceDbs, prefix)
+		}
+		if err := db.Close(); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(chainDb.keyspaceDir(keyspace)); err != nil {
+			return err
+		}
+	}
+	chainDb.resetAccess()
+	return nil
+}
+
+// IsKeyspaceSplit reports whether the keyspace of prefix is stored apart from the main db.
+func (chainDb *ChainDb) IsKeyspaceSplit(prefix byte) bool {
+	_, ok := chainDb.keyspaceDbs[prefix]
+	return ok
+}
+
+// KeyspaceDb returns the db storing the keys of prefix.
+func (chainDb *ChainDb) KeyspaceDb(prefix byte) *leveldb.DB {
+	if db, ok := chainDb.keyspaceDbs[prefix]; ok {
+		return db
+	}
+	return chainDb.db
+}
+
+// keyspaceDbList returns the distinct keyspace dbs.
+func (chainDb *ChainDb) keyspaceDbList() []*leveldb.DB {
+	var dbList []*leveldb.DB
+	seen := make(map[*leveldb.DB]struct{})
+	for _, db := range chainDb.keyspaceDbs {
+		if _, ok := seen[db]; ok {
+			continue
+		}
+		seen[db] = struct{}{}
+		dbList = append(dbList, db)
+	}
+	return dbList
+}
+
+// moveKeys moves the keys of prefixes from src to dst by batches. A batch is written to dst before it is deleted
+// from src, so an interrupted move leaves every key in at least one of them and can be resumed.
+func moveKeys(src, dst *leveldb.DB, prefixes []byte) (uint64, error) {
+	count := uint64(0)
+	for _, prefix := range prefixes {
+		iter := src.NewIterator(util.BytesPrefix([]byte{prefix}), nil)
+
+		putBatch := new(leveldb.Batch)
+		deleteBatch := new(leveldb.Batch)
+		flush := func() error {
+			if putBatch.Len() == 0 {
+				return nil
+			}
+			if err := dst.Write(putBatch, nil); err != nil {
+				return err
+			}
+			if err := src.Write(deleteBatch, nil); err != nil {
+				return err
+			}
+			count += uint64(putBatch.Len())
+			putBatch.Reset()
+			deleteBatch.Reset()
+			return nil
+		}
+
+		for iter.Next() {
+			putBatch.Put(iter.Key(), iter.Value())
+			deleteBatch.Delete(iter.Key())
+			if putBatch.Len() >= moveBatchSize {
+				if err := flush(); err != nil {
+					iter.Release()
+					return count, err
+				}
+			}
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil {
+			return count, err
+		}
+		if err := flush(); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// keyspaceOp is the last write of a key of a split keyspace in a batch
+type keyspaceOp struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// keyspaceRouter splits a batch into the batch of the main db and the writes of the keyspace dbs.
+type keyspaceRouter struct {
+	chainDb *ChainDb
+	main    *leveldb.Batch
+	ops     map[*leveldb.DB]map[string]*keyspaceOp
+}
+
+func (r *keyspaceRouter) db(key []byte) *leveldb.DB {
+	if len(key) == 0 {
+		return nil
+	}
+	return r.chainDb.keyspaceDbs[key[0]]
+}
+
+func (r *keyspaceRouter) op(db *leveldb.DB, key []byte) *keyspaceOp {
+	ops, ok := r.ops[db]
+	if !ok {
+		ops = make(map[string]*keyspaceOp)
+		r.ops[db] = ops
+	}
+	op, ok := ops[string(key)]
+	if !ok {
+		op = &keyspaceOp{key: append([]byte(nil), key...)}
+		ops[string(key)] = op
+	}
+	return op
+}
+
+func (r *keyspaceRouter) Put(key, value []byte) {
+	db := r.db(key)
+	if db == nil {
+		r.main.Put(key, value)
+		return
+	}
+	op := r.op(db, key)
+	op.value = append([]byte(nil), value...)
+	op.delete = false
+}
+
+func (r *keyspaceRouter) Delete(key []byte) {
+	db := r.db(key)
+	if db == nil {
+		r.main.Delete(key)
+		return
+	}
+	op := r.op(db, key)
+	op.value = nil
+	op.delete = true
+}
+
+// write writes the puts of the keyspace dbs, then the main batch, then the deletes of the keyspace dbs. The values
+// of the split keyspaces are referenced from the main db, so a crash in between leaves unreferenced values at worst.
+func (r *keyspaceRouter) write() error {
+	putBatches := make(map[*leveldb.DB]*leveldb.Batch)
+	deleteBatches := make(map[*leveldb.DB]*leveldb.Batch)
+	for db, ops := range r.ops {
+		putBatch := new(leveldb.Batch)
+		deleteBatch := new(leveldb.Batch)
+		for _, op := range ops {
+			if op.delete {
+				deleteBatch.Delete(op.key)
+			} else {
+				putBatch.Put(op.key, op.value)
+			}
+		}
+		putBatches[db] = putBatch
+		deleteBatches[db] = deleteBatch
+	}
+
+	for db, batch := range putBatches {
+		if batch.Len() == 0 {
+			continue
+		}
+		if err := db.Write(batch, nil); err != nil {
+			return err
+		}
+	}
+	if r.main.Len() > 0 {
+		if err := r.chainDb.db.Write(r.main, nil); err != nil {
+			return err
+		}
+	}
+	for db, batch := range deleteBatches {
+		if batch.Len() == 0 {
+			continue
+		}
+		if err := db.Write(batch, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
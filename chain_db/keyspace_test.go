@@ -0,0 +1,92 @@
+package chain_db
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db/database"
+)
+
+func TestChainDb_SplitKeyspaces(t *testing.T) {
+	// the keyspace dbs are beside the ledger dir, so the temp dir holds them all
+	tmpDir, err := ioutil.TempDir("", "keyspace_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dbDir := filepath.Join(tmpDir, "ledger")
+
+	chainDb := NewChainDb(dbDir)
+	if chainDb == nil {
+		t.Fatal("NewChainDb failed")
+	}
+
+	blockKey := []byte{database.DBKP_ACCOUNTBLOCK, 1}
+	logKey := []byte{database.DBKP_LOG_LIST, 1}
+	trieKey := []byte{database.DBKP_TRIE_NODE, 1}
+	batch := new(leveldb.Batch)
+	batch.Put(blockKey, []byte("block"))
+	batch.Put(logKey, []byte("log"))
+	batch.Put(trieKey, []byte("trie"))
+	if err := chainDb.Commit(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chainDb.SplitKeyspaces(); err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := chainDb.Db().Has(logKey, nil); has {
+		t.Fatal("the log list should be moved out of the main db")
+	}
+	if value, _ := chainDb.KeyspaceDb(database.DBKP_LOG_LIST).Get(logKey, nil); !bytes.Equal(value, []byte("log")) {
+		t.Fatalf("unexpected log list %s", value)
+	}
+
+	// a put after a delete of the same key in a batch wins
+	batch = new(leveldb.Batch)
+	batch.Put(blockKey, []byte("block2"))
+	batch.Delete(trieKey)
+	batch.Put(trieKey, []byte("trie2"))
+	batch.Delete(logKey)
+	if err := chainDb.Commit(batch); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := chainDb.KeyspaceDb(database.DBKP_TRIE_NODE).Get(trieKey, nil); !bytes.Equal(value, []byte("trie2")) {
+		t.Fatalf("unexpected trie node %s", value)
+	}
+	if has, _ := chainDb.KeyspaceDb(database.DBKP_LOG_LIST).Has(logKey, nil); has {
+		t.Fatal("the log list should be deleted")
+	}
+	if has, _ := chainDb.Db().Has(trieKey, nil); has {
+		t.Fatal("the trie node should not be written to the main db")
+	}
+
+	// the split keyspaces are opened again
+	if err := chainDb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	chainDb = NewChainDb(dbDir)
+	if !chainDb.IsKeyspaceSplit(database.DBKP_TRIE_REF_VALUE) || chainDb.IsKeyspaceSplit(database.DBKP_ACCOUNTBLOCK) {
+		t.Fatal("the keyspaces should be split")
+	}
+
+	if err := chainDb.MergeKeyspaces(); err != nil {
+		t.Fatal(err)
+	}
+	for _, keyspace := range database.Keyspaces {
+		if _, err := os.Stat(dbDir + "_" + keyspace.Name); !os.IsNotExist(err) {
+			t.Fatalf("the db of keyspace %v should be removed", keyspace.Name)
+		}
+	}
+	if value, _ := chainDb.Db().Get(trieKey, nil); !bytes.Equal(value, []byte("trie2")) {
+		t.Fatalf("unexpected trie node %s", value)
+	}
+	if value, _ := chainDb.Db().Get(blockKey, nil); !bytes.Equal(value, []byte("block2")) {
+		t.Fatalf("unexpected block %s", value)
+	}
+	chainDb.Close()
+}
@@ -1,6 +1,7 @@
 package gvite_plugins
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -24,12 +25,14 @@ var (
 		Action:   utils.MigrateFlags(localConsoleAction),
 		Name:     "console",
 		Usage:    "Start an interactive JavaScript environment",
-		Flags:    jsFlags,
+		Flags:    utils.MergeFlags(jsFlags, historyFlags),
 		Category: "CONSOLE COMMANDS",
 		Description: `
 The GVite console is an interactive shell for the JavaScript runtime environment
 which exposes a node admin interface as well as the Ðapp JavaScript API.
-See https://github.com/vitelabs/go-vite/wiki/JavaScript-Console.`,
+See https://github.com/vitelabs/go-vite/wiki/JavaScript-Console.
+With --readonly --at-height H, the ledger is served as it was at the snapshot
+block H without connecting to peers or changing the ledger.`,
 	}
 
 	//local
@@ -51,7 +54,7 @@ JavaScript API. See https://github.com/vitelabs/go-vite/wiki/JavaScript-Console`
 		Name:      "attach",
 		Usage:     "Start an interactive JavaScript environment (connect to node)",
 		ArgsUsage: "[endpoint]",
-		Flags:     append(consoleFlags, utils.DataDirFlag),
+		Flags:     append(consoleFlags, utils.DataDirFlag, utils.ProxyFlag),
 		Category:  "CONSOLE COMMANDS",
 		Description: `
 The GVite console is an interactive shell for the JavaScript runtime environment
@@ -170,7 +173,7 @@ func remoteConsoleAction(ctx *cli.Context) error {
 	if attachEndpoint == "" {
 		attachEndpoint = defaultAttachEndpoint(dataDir)
 	}
-	client, err := dialRPC(dataDir, attachEndpoint)
+	client, err := dialRPC(dataDir, attachEndpoint, ctx.GlobalString(utils.ProxyFlag.Name))
 	if err != nil {
 		log.Error(fmt.Sprintf("Unable to attach to remote gvite: %v", err))
 		return err
@@ -200,9 +203,9 @@ func remoteConsoleAction(ctx *cli.Context) error {
 	return nil
 }
 
-// dialRPC returns a RPC client which connects to the given endpoint.
+// dialRPC returns a RPC client which connects to the given endpoint, through the SOCKS5 proxy if it's not empty.
 // The check for empty endpoint implements the defaulting logic for "gvite attach" with no argument.
-func dialRPC(dataDir string, endpoint string) (*rpc.Client, error) {
+func dialRPC(dataDir string, endpoint string, proxy string) (*rpc.Client, error) {
 	if endpoint == "" {
 		config := &node.Config{
 			DataDir: dataDir,
@@ -210,6 +213,9 @@ func dialRPC(dataDir string, endpoint string) (*rpc.Client, error) {
 		}
 		endpoint = config.IPCEndpoint()
 	}
+	if proxy != "" {
+		return rpc.DialProxy(context.Background(), endpoint, proxy)
+	}
 	return rpc.Dial(endpoint)
 }
 
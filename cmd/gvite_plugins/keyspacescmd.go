@@ -0,0 +1,91 @@
+package gvite_plugins
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	keyspacesCommand = cli.Command{
+		Name:     "keyspaces",
+		Usage:    "Split the ledger into keyspaces or merge them back, the node must be stopped",
+		Category: "LEDGER COMMANDS",
+		Description: `
+The state, vm logs and indexes of the ledger can be stored in leveldbs of their own beside the
+ledger, each compacted with its own settings, so that heavy log writes don't slow down the reads
+of the blocks. A node with SplitKeyspaces set splits the ledger when it starts, these commands do
+it offline.
+`,
+		Subcommands: []cli.Command{
+			{
+				Action: utils.MigrateFlags(keyspacesSplitAction),
+				Name:   "split",
+				Usage:  "Move the keyspaces out of the ledger",
+				Flags:  configFlags,
+			},
+			{
+				Action: utils.MigrateFlags(keyspacesMergeAction),
+				Name:   "merge",
+				Usage:  "Move the keyspaces back into the ledger",
+				Flags:  configFlags,
+			},
+		},
+	}
+)
+
+func openLedgerDb(ctx *cli.Context) (*chain_db.ChainDb, error) {
+	cfg, err := nodemanager.FullNodeMaker{}.MakeNodeConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chainDb := chain_db.NewChainDb(filepath.Join(cfg.DataDir, "ledger"))
+	if chainDb == nil {
+		return nil, errors.New("open the ledger failed")
+	}
+	return chainDb, nil
+}
+
+func printKeyspaces(chainDb *chain_db.ChainDb) {
+	for _, keyspace := range database.Keyspaces {
+		state := "merged"
+		if chainDb.IsKeyspaceSplit(keyspace.Prefixes[0]) {
+			state = "split"
+		}
+		fmt.Printf("%v: %v\n", keyspace.Name, state)
+	}
+}
+
+func keyspacesSplitAction(ctx *cli.Context) error {
+	chainDb, err := openLedgerDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer chainDb.Close()
+
+	if err := chainDb.SplitKeyspaces(); err != nil {
+		return err
+	}
+	printKeyspaces(chainDb)
+	return nil
+}
+
+func keyspacesMergeAction(ctx *cli.Context) error {
+	chainDb, err := openLedgerDb(ctx)
+	if err != nil {
+		return err
+	}
+	defer chainDb.Close()
+
+	if err := chainDb.MergeKeyspaces(); err != nil {
+		return err
+	}
+	printKeyspaces(chainDb)
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/vitelabs/go-vite/cmd/nodemanager"
 	"github.com/vitelabs/go-vite/cmd/params"
 	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/common/profile"
 	"github.com/vitelabs/go-vite/log15"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -47,6 +48,9 @@ var (
 		utils.ListenPortFlag,
 		utils.NodeKeyHexFlag,
 		utils.DiscoveryFlag,
+		utils.NoDiscoveryFlag,
+		utils.ProxyFlag,
+		utils.OnionAddrFlag,
 	}
 
 	//IPC
@@ -76,11 +80,18 @@ var (
 		utils.PreloadJSFlag,
 	}
 
+	//History console
+	historyFlags = []cli.Flag{
+		utils.ReadOnlyFlag,
+		utils.AtHeightFlag,
+	}
+
 	//Producer
 	producerFlags = []cli.Flag{
 		utils.MinerFlag,
 		utils.CoinBaseFlag,
 		utils.MinerIntervalFlag,
+		utils.DevMiningFlag,
 	}
 
 	//Log
@@ -98,6 +109,11 @@ var (
 	netFlags = []cli.Flag{
 		utils.SingleFlag,
 		utils.FilePortFlag,
+		utils.LegacyProtocolDeadlineFlag,
+		utils.NodeOperatorFlag,
+		utils.NodeContactFlag,
+		utils.NodeRegionFlag,
+		utils.NodeServicesFlag,
 	}
 
 	//Stat
@@ -153,6 +169,8 @@ func init() {
 		attachCommand,
 		ledgerRecoverCommand,
 		exportCommand,
+		testvectorsCommand,
+		keyspacesCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -186,6 +204,9 @@ func beforeAction(ctx *cli.Context) error {
 		listenAddress = fmt.Sprintf("%s:%d", "0.0.0.0", pprofPort)
 		var visitAddress = fmt.Sprintf("http://localhost:%d/debug/pprof", pprofPort)
 
+		// labeled profiles of a time window, the samples of a module are picked out with -tagfocus module=<module>
+		http.HandleFunc("/debug/pprof/module", profile.Handler)
+
 		go func() {
 			log.Info("Enable a performance analysis tool, you can visit the address of `" + visitAddress + "`")
 			http.ListenAndServe(listenAddress, nil)
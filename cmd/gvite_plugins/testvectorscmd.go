@@ -0,0 +1,87 @@
+package gvite_plugins
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/ledger/testvectors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	testvectorsCommand = cli.Command{
+		Name:     "testvectors",
+		Usage:    "Generate or verify serialization and hashing test vectors",
+		Category: "MISCELLANEOUS COMMANDS",
+		Description: `
+The test vectors are the canonical encodings and hashes of account blocks, snapshot blocks
+and tokens, generated deterministically so that other implementations can check compatibility.
+`,
+		Subcommands: []cli.Command{
+			{
+				Action:    utils.MigrateFlags(testvectorsGenerateAction),
+				Name:      "generate",
+				Usage:     "Write the test vectors as json to the file, or to stdout if no file is given",
+				ArgsUsage: "[file]",
+			},
+			{
+				Action:    utils.MigrateFlags(testvectorsVerifyAction),
+				Name:      "verify",
+				Usage:     "Verify the test vectors in the file against this implementation",
+				ArgsUsage: "<file>",
+			},
+		},
+	}
+)
+
+func testvectorsGenerateAction(ctx *cli.Context) error {
+	forkPoints := testvectors.MainNetForkPoints()
+	fork.SetForkPoints(forkPoints)
+
+	v, err := testvectors.Generate(forkPoints)
+	if err != nil {
+		return err
+	}
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if ctx.NArg() == 0 {
+		fmt.Println(string(buf))
+		return nil
+	}
+	return ioutil.WriteFile(ctx.Args().First(), buf, 0644)
+}
+
+func testvectorsVerifyAction(ctx *cli.Context) error {
+	if ctx.NArg() == 0 {
+		return errors.New("the test vectors file is required")
+	}
+	buf, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	v := &testvectors.Vectors{}
+	if err := json.Unmarshal(buf, v); err != nil {
+		return err
+	}
+	if v.ForkPoints == nil || v.ForkPoints.Smart == nil || v.ForkPoints.Mint == nil {
+		return errors.New("the fork points of the test vectors are missing")
+	}
+	fork.SetForkPoints(v.ForkPoints)
+
+	errs := testvectors.Verify(v)
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of the test vectors failed", len(errs))
+	}
+	fmt.Printf("all %d test vectors passed\n", len(v.AccountBlocks)+len(v.SnapshotBlocks)+len(v.Tokens))
+	return nil
+}
@@ -1,10 +1,15 @@
 package nodemanager
 
 import (
+	"errors"
+
+	"github.com/vitelabs/go-vite/cmd/utils"
 	"github.com/vitelabs/go-vite/node"
 	"gopkg.in/urfave/cli.v1"
 )
 
+var errAtHeightRequired = errors.New("--readonly must be used with --at-height")
+
 type ConsoleNodeManager struct {
 	ctx  *cli.Context
 	node *node.Node
@@ -15,6 +20,29 @@ func NewConsoleNodeManager(ctx *cli.Context, maker NodeMaker) (*ConsoleNodeManag
 	if err != nil {
 		return nil, err
 	}
+
+	if ctx.GlobalBool(utils.ReadOnlyFlag.Name) {
+		height := ctx.GlobalUint64(utils.AtHeightFlag.Name)
+		if height == 0 {
+			return nil, errAtHeightRequired
+		}
+
+		// single mode, the blocks of peers are not received
+		node.Config().Single = true
+		node.ViteConfig().Net.Single = true
+
+		// no miner
+		node.Config().MinerEnabled = false
+		node.ViteConfig().Producer.Producer = false
+
+		// no ledger gc, the state at the height must be kept
+		ledgerGc := false
+		node.Config().LedgerGc = &ledgerGc
+		node.ViteConfig().Chain.LedgerGc = ledgerGc
+
+		node.ViteConfig().Chain.HistoryHeight = height
+	}
+
 	return &ConsoleNodeManager{
 		ctx:  ctx,
 		node: node,
@@ -395,17 +395,21 @@ func exportRegisterBalance(m map[types.Address]*big.Int, trie *trie.Trie) map[ty
 }
 
 func exportPledgeBalance(m map[types.Address]*big.Int, trie *trie.Trie) map[types.Address]*big.Int {
-	// for pledge contract, return to pledge addr
+	// for pledge contract, return to pledge addr, or to the agent paid for it
 	iter := trie.NewIterator(nil)
 	for {
 		key, value, ok := iter.Next()
 		if !ok {
 			break
 		}
-		if abi.IsPledgeKey(key) {
+		if abi.IsPledgeKey(key) || abi.IsAgentPledgeKey(key) || abi.IsLockPledgeKey(key) {
 			pledgeInfo := new(abi.PledgeInfo)
 			if err := abi.ABIPledge.UnpackVariable(pledgeInfo, abi.VariableNamePledgeInfo, value); err == nil && pledgeInfo.Amount != nil && pledgeInfo.Amount.Sign() > 0 {
-				m = updateBalance(m, abi.GetPledgeAddrFromPledgeKey(key), pledgeInfo.Amount)
+				if abi.IsAgentPledgeKey(key) {
+					m = updateBalance(m, abi.GetAgentFromPledgeKey(key), pledgeInfo.Amount)
+				} else {
+					m = updateBalance(m, abi.GetPledgeAddrFromPledgeKey(key), pledgeInfo.Amount)
+				}
 			}
 		}
 	}
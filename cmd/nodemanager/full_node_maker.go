@@ -11,6 +11,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 var defaultNodeConfigFileName = "node_config.json"
@@ -99,6 +100,18 @@ func mappingNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.SetPrivateKey(nodeKeyHex)
 	}
 
+	if ctx.GlobalBool(utils.NoDiscoveryFlag.Name) {
+		cfg.Discovery = false
+	}
+
+	if proxy := ctx.GlobalString(utils.ProxyFlag.Name); len(proxy) > 0 {
+		cfg.Proxy = proxy
+	}
+
+	if onionAddr := ctx.GlobalString(utils.OnionAddrFlag.Name); len(onionAddr) > 0 {
+		cfg.OnionAddr = onionAddr
+	}
+
 	//Ipc Config
 	if ctx.GlobalIsSet(utils.IPCEnabledFlag.Name) {
 		cfg.IPCEnabled = ctx.GlobalBool(utils.IPCEnabledFlag.Name)
@@ -147,6 +160,10 @@ func mappingNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.MinerInterval = ctx.GlobalInt(utils.MinerIntervalFlag.Name)
 	}
 
+	if ctx.GlobalIsSet(utils.DevMiningFlag.Name) {
+		cfg.DevMining = ctx.GlobalBool(utils.DevMiningFlag.Name)
+	}
+
 	//Log Level Config
 	if logLevel := ctx.GlobalString(utils.LogLvlFlag.Name); len(logLevel) > 0 {
 		cfg.LogLevel = logLevel
@@ -172,6 +189,26 @@ func mappingNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.FilePort = ctx.GlobalInt(utils.FilePortFlag.Name)
 	}
 
+	if ctx.GlobalIsSet(utils.LegacyProtocolDeadlineFlag.Name) {
+		cfg.LegacyProtocolDeadline = ctx.GlobalInt64(utils.LegacyProtocolDeadlineFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(utils.NodeOperatorFlag.Name) {
+		cfg.NodeOperator = ctx.GlobalString(utils.NodeOperatorFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(utils.NodeContactFlag.Name) {
+		cfg.NodeContact = ctx.GlobalString(utils.NodeContactFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(utils.NodeRegionFlag.Name) {
+		cfg.NodeRegion = ctx.GlobalString(utils.NodeRegionFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(utils.NodeServicesFlag.Name) {
+		cfg.NodeServices = strings.Split(ctx.GlobalString(utils.NodeServicesFlag.Name), ",")
+	}
+
 	//metrics
 	if ctx.GlobalIsSet(utils.MetricsEnabledFlag.Name) {
 		mBool := ctx.GlobalBool(utils.MetricsEnabledFlag.Name)
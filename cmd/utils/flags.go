@@ -72,6 +72,18 @@ var (
 		Name:  "discovery", //mapping:p2p.Discovery
 		Usage: "enable p2p discovery or not",
 	}
+	NoDiscoveryFlag = cli.BoolFlag{
+		Name:  "nodiscover", //mapping:p2p.Discovery
+		Usage: "Disable p2p discovery, peers are the static nodes and the ones they share only",
+	}
+	ProxyFlag = cli.StringFlag{
+		Name:  "proxy", //mapping:p2p.Proxy
+		Usage: "SOCKS5 proxy to dial peers and remote nodes to attach through, e.g. tor at 127.0.0.1:9050",
+	}
+	OnionAddrFlag = cli.StringFlag{
+		Name:  "onion", //mapping:p2p.OnionAddr
+		Usage: "Onion service address of the node published to peers, e.g. xxx.onion:8483",
+	}
 
 	//IPC Settings
 	IPCEnabledFlag = cli.BoolFlag{
@@ -142,6 +154,11 @@ var (
 		Usage: "Miner Interval(unit: second)",
 	}
 
+	DevMiningFlag = cli.BoolFlag{
+		Name:  "devmining",
+		Usage: "Produce the snapshot blocks only on demand of the test api, in a dev network",
+	}
+
 	//Log Lvl
 	LogLvlFlag = cli.StringFlag{
 		Name:  "loglevel",
@@ -180,6 +197,16 @@ var (
 		Usage: "The snapshot block height",
 	}
 
+	//History console
+	ReadOnlyFlag = cli.BoolFlag{
+		Name:  "readonly",
+		Usage: "Serve the ledger read only without connecting to peers, must be used with --at-height",
+	}
+	AtHeightFlag = cli.Uint64Flag{
+		Name:  "at-height",
+		Usage: "The snapshot block height the read only ledger is pinned at, the ledger gc must have been disabled",
+	}
+
 	//Net
 	SingleFlag = cli.BoolFlag{
 		Name:  "single",
@@ -191,6 +218,28 @@ var (
 		Usage: "File transfer listening port",
 	}
 
+	LegacyProtocolDeadlineFlag = cli.Int64Flag{
+		Name:  "legacyprotocoldeadline",
+		Usage: "Unix time until which the legacy versions of the vite protocol are served, 0 means no deadline",
+	}
+
+	NodeOperatorFlag = cli.StringFlag{
+		Name:  "nodeoperator",
+		Usage: "Operator name of the node gossiped to peers",
+	}
+	NodeContactFlag = cli.StringFlag{
+		Name:  "nodecontact",
+		Usage: "Operator contact of the node gossiped to peers",
+	}
+	NodeRegionFlag = cli.StringFlag{
+		Name:  "noderegion",
+		Usage: "Geographic region of the node gossiped to peers",
+	}
+	NodeServicesFlag = cli.StringFlag{
+		Name:  "nodeservices",
+		Usage: "Comma separated services of the node gossiped to peers, archive and light-server",
+	}
+
 	//Stat
 	PProfEnabledFlag = cli.BoolFlag{
 		Name:  "pprof",
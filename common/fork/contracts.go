@@ -0,0 +1,45 @@
+package fork
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+// isPrecompiledContractForked reports whether the fork adding the built-in contract addr is reached at blockHeight
+func isPrecompiledContractForked(addr types.Address, blockHeight uint64) bool {
+	switch addr {
+	case types.AddressAmm:
+		return IsAmmFork(blockHeight)
+	case types.AddressAllowance:
+		return IsAllowanceFork(blockHeight)
+	case types.AddressEventRegistry:
+		return IsEventRegistryFork(blockHeight)
+	case types.AddressCheckpoint:
+		return IsCheckpointFork(blockHeight)
+	case types.AddressQuotaLease:
+		return IsQuotaLeaseFork(blockHeight)
+	}
+	return false
+}
+
+// IsPrecompiledContractAddress reports whether addr is a built-in contract at the snapshot block height, the
+// contracts added by a fork are plain addresses before it
+func IsPrecompiledContractAddress(addr types.Address, blockHeight uint64) bool {
+	return types.IsPrecompiledContractAddress(addr) || isPrecompiledContractForked(addr, blockHeight)
+}
+
+// IsPrecompiledContractWithoutQuotaAddress reports whether addr is a built-in contract receiving without quota at the
+// snapshot block height
+func IsPrecompiledContractWithoutQuotaAddress(addr types.Address, blockHeight uint64) bool {
+	return types.IsPrecompiledContractWithoutQuotaAddress(addr) || isPrecompiledContractForked(addr, blockHeight)
+}
+
+// GetPrecompiledContractAddressList returns the built-in contracts at the snapshot block height
+func GetPrecompiledContractAddressList(blockHeight uint64) []types.Address {
+	addrList := append([]types.Address(nil), types.PrecompiledContractAddressList...)
+	for _, addr := range types.ForkedPrecompiledContractAddressList {
+		if isPrecompiledContractForked(addr, blockHeight) {
+			addrList = append(addrList, addr)
+		}
+	}
+	return addrList
+}
@@ -29,6 +29,9 @@ func SetForkPoints(points *config.ForkPoints) {
 
 	for k := 0; k < t.NumField(); k++ {
 		forkPoint := v.Field(k).Interface().(*config.ForkPoint)
+		if forkPoint == nil {
+			continue
+		}
 		forkPointList = append(forkPointList, &ForkPointItem{
 			ForkPoint: *forkPoint,
 			forkName:  t.Field(k).Name,
@@ -38,6 +41,15 @@ func SetForkPoints(points *config.ForkPoints) {
 	sort.Sort(forkPointList)
 }
 
+func (f *ForkPointItem) Name() string {
+	return f.forkName
+}
+
+// GetForkPointList returns the fork points sorted by height
+func GetForkPointList() ForkPointList {
+	return forkPointList
+}
+
 func IsSmartFork(blockHeight uint64) bool {
 	return forkPoints.Smart.Height > 0 && blockHeight >= forkPoints.Smart.Height
 }
@@ -46,6 +58,74 @@ func IsMintFork(blockHeight uint64) bool {
 	return forkPoints.Mint.Height > 0 && blockHeight >= forkPoints.Mint.Height
 }
 
+func IsAmmFork(blockHeight uint64) bool {
+	return forkPoints.Amm != nil && forkPoints.Amm.Height > 0 && blockHeight >= forkPoints.Amm.Height
+}
+
+func IsAllowanceFork(blockHeight uint64) bool {
+	return forkPoints.Allowance != nil && forkPoints.Allowance.Height > 0 && blockHeight >= forkPoints.Allowance.Height
+}
+
+func IsReceiveQuotaFork(blockHeight uint64) bool {
+	return forkPoints.ReceiveQuota != nil && forkPoints.ReceiveQuota.Height > 0 && blockHeight >= forkPoints.ReceiveQuota.Height
+}
+
+func IsEventRegistryFork(blockHeight uint64) bool {
+	return forkPoints.EventRegistry != nil && forkPoints.EventRegistry.Height > 0 && blockHeight >= forkPoints.EventRegistry.Height
+}
+
+func IsCheckpointFork(blockHeight uint64) bool {
+	return forkPoints.Checkpoint != nil && forkPoints.Checkpoint.Height > 0 && blockHeight >= forkPoints.Checkpoint.Height
+}
+
+func IsAgentPledgeFork(blockHeight uint64) bool {
+	return forkPoints.AgentPledge != nil && forkPoints.AgentPledge.Height > 0 && blockHeight >= forkPoints.AgentPledge.Height
+}
+
+func IsPledgeLockFork(blockHeight uint64) bool {
+	return forkPoints.PledgeLock != nil && forkPoints.PledgeLock.Height > 0 && blockHeight >= forkPoints.PledgeLock.Height
+}
+
+func IsRegistrationDetailFork(blockHeight uint64) bool {
+	return forkPoints.RegistrationDetail != nil && forkPoints.RegistrationDetail.Height > 0 && blockHeight >= forkPoints.RegistrationDetail.Height
+}
+
+func IsSplitVoteFork(blockHeight uint64) bool {
+	return forkPoints.SplitVote != nil && forkPoints.SplitVote.Height > 0 && blockHeight >= forkPoints.SplitVote.Height
+}
+
+func IsDeterministicAddressFork(blockHeight uint64) bool {
+	return forkPoints.DeterministicAddress != nil && forkPoints.DeterministicAddress.Height > 0 && blockHeight >= forkPoints.DeterministicAddress.Height
+}
+
+func IsRevertReasonFork(blockHeight uint64) bool {
+	return forkPoints.RevertReason != nil && forkPoints.RevertReason.Height > 0 && blockHeight >= forkPoints.RevertReason.Height
+}
+
+func IsSelfdestructFork(blockHeight uint64) bool {
+	return forkPoints.Selfdestruct != nil && forkPoints.Selfdestruct.Height > 0 && blockHeight >= forkPoints.Selfdestruct.Height
+}
+
+func IsCongestionQuotaFork(blockHeight uint64) bool {
+	return forkPoints.CongestionQuota != nil && forkPoints.CongestionQuota.Height > 0 && blockHeight >= forkPoints.CongestionQuota.Height
+}
+
+func IsQuotaLeaseFork(blockHeight uint64) bool {
+	return forkPoints.QuotaLease != nil && forkPoints.QuotaLease.Height > 0 && blockHeight >= forkPoints.QuotaLease.Height
+}
+
+func IsStorageDeletionFork(blockHeight uint64) bool {
+	return forkPoints.StorageDeletion != nil && forkPoints.StorageDeletion.Height > 0 && blockHeight >= forkPoints.StorageDeletion.Height
+}
+
+func IsSizeLimitFork(blockHeight uint64) bool {
+	return forkPoints.SizeLimit != nil && forkPoints.SizeLimit.Height > 0 && blockHeight >= forkPoints.SizeLimit.Height
+}
+
+func IsStorageSizeLimitFork(blockHeight uint64) bool {
+	return forkPoints.StorageSizeLimit != nil && forkPoints.StorageSizeLimit.Height > 0 && blockHeight >= forkPoints.StorageSizeLimit.Height
+}
+
 func GetForkPoints() config.ForkPoints {
 	return forkPoints
 }
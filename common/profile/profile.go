@@ -0,0 +1,105 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/pprof"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitelabs/go-vite/common"
+)
+
+// LabelModule is the pprof label key of the subsystem a goroutine works for, the samples of a
+// module are picked out with `go tool pprof -tagfocus module=<module>`.
+const LabelModule = "module"
+
+const (
+	ModuleSync     = "sync"
+	ModulePool     = "pool"
+	ModuleRPC      = "rpc"
+	ModuleProducer = "producer"
+)
+
+const (
+	TypeCPU       = "cpu"
+	TypeGoroutine = "goroutine"
+)
+
+const (
+	defaultSeconds = 30
+	maxSeconds     = 600
+)
+
+var (
+	ErrCapturing   = errors.New("another profile is being captured")
+	ErrUnknownType = errors.New("unknown profile type, must be cpu or goroutine")
+)
+
+// Labels returns the label set of module.
+func Labels(module string) pprof.LabelSet {
+	return pprof.Labels(LabelModule, module)
+}
+
+// Go starts fn in a goroutine labeled with module, the goroutines started by fn inherit the label.
+func Go(module string, fn func()) {
+	common.Go(func() {
+		pprof.Do(context.Background(), Labels(module), func(context.Context) {
+			fn()
+		})
+	})
+}
+
+var capturing int32
+
+// Capture writes a profile of typ to w. A cpu profile samples the window of d, a goroutine
+// profile is taken when d is over. Both keep the module labels of the samples.
+func Capture(w io.Writer, typ string, d time.Duration) error {
+	if typ != TypeCPU && typ != TypeGoroutine {
+		return ErrUnknownType
+	}
+	if !atomic.CompareAndSwapInt32(&capturing, 0, 1) {
+		return ErrCapturing
+	}
+	defer atomic.StoreInt32(&capturing, 0)
+
+	if typ == TypeGoroutine {
+		time.Sleep(d)
+		return pprof.Lookup("goroutine").WriteTo(w, 0)
+	}
+	if err := pprof.StartCPUProfile(w); err != nil {
+		return err
+	}
+	time.Sleep(d)
+	pprof.StopCPUProfile()
+	return nil
+}
+
+// Handler serves the labeled profiles at /debug/pprof/module?type=cpu&seconds=30, it's meant
+// for the pprof port only.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	typ := r.FormValue("type")
+	if typ == "" {
+		typ = TypeCPU
+	}
+	seconds, err := strconv.Atoi(r.FormValue("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = defaultSeconds
+	}
+	if seconds > maxSeconds {
+		seconds = maxSeconds
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pprof"`, typ))
+	if err := Capture(w, typ, time.Duration(seconds)*time.Second); err != nil {
+		w.Header().Del("Content-Disposition")
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, err.Error())
+	}
+}
@@ -0,0 +1,54 @@
+package profile
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGo(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+	started := make(chan struct{})
+	Go(ModuleSync, func() {
+		// the label is inherited by the goroutines started by fn
+		go func() {
+			close(started)
+			<-blocked
+		}()
+		<-blocked
+	})
+	<-started
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), `labels: {"module":"sync"}`); n != 2 {
+		t.Fatalf("expected 2 labeled goroutines, got %v", n)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Capture(&buf, "heap", 0); err != ErrUnknownType {
+		t.Fatalf("expected ErrUnknownType, got %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- Capture(&bytes.Buffer{}, TypeGoroutine, 200*time.Millisecond)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := Capture(&buf, TypeGoroutine, 0); err != ErrCapturing {
+		t.Fatalf("expected ErrCapturing, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if err := Capture(&buf, TypeGoroutine, 0); err != nil || buf.Len() == 0 {
+		t.Fatalf("capture goroutine profile failed, %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+// Package socks implements the client side of SOCKS5 CONNECT, enough to dial TCP connections
+// through a proxy such as tor.
+package socks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	version5 = 5
+
+	authNone     = 0
+	authPassword = 2
+
+	cmdConnect = 1
+
+	atypIPv4   = 1
+	atypDomain = 3
+	atypIPv6   = 4
+)
+
+var (
+	errUnsupportedNetwork = errors.New("socks5 dials tcp only")
+	errNoAcceptableAuth   = errors.New("socks5 proxy accepts none of the auth methods")
+	errAuthFailed         = errors.New("socks5 username/password authentication failed")
+	errHostTooLong        = errors.New("socks5 host name is longer than 255 bytes")
+	errInvalidReply       = errors.New("invalid socks5 reply")
+)
+
+var replyErrors = [...]string{
+	1: "general SOCKS server failure",
+	2: "connection not allowed by ruleset",
+	3: "network unreachable",
+	4: "host unreachable",
+	5: "connection refused",
+	6: "TTL expired",
+	7: "command not supported",
+	8: "address type not supported",
+}
+
+// Dialer dials TCP connections through the SOCKS5 proxy at Proxy. The host of the target address
+// is resolved by the proxy, so that onion addresses can be dialed through tor, and no DNS query
+// of the target is made locally.
+type Dialer struct {
+	Proxy    string // host:port of the proxy
+	Username string // username and password to authenticate, no authentication if Username is empty
+	Password string
+	Timeout  time.Duration // timeout of the proxy connection and the CONNECT request, 0 means no timeout
+}
+
+// Dial connects to addr through the proxy.
+func (d *Dialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext connects to addr through the proxy using the provided context.
+func (d *Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, errUnsupportedNetwork
+	}
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+
+	nd := &net.Dialer{Timeout: d.Timeout}
+	c, err := nd.DialContext(ctx, "tcp", d.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Timeout > 0 {
+		c.SetDeadline(time.Now().Add(d.Timeout))
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.SetDeadline(deadline)
+	}
+
+	if err = d.connect(c, host, uint16(port)); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("socks5 connect %s through %s: %v", addr, d.Proxy, err)
+	}
+	c.SetDeadline(time.Time{})
+
+	conn := &Conn{Conn: c}
+	if ip := net.ParseIP(host); ip != nil {
+		conn.remote = &net.TCPAddr{IP: ip, Port: int(port)}
+	}
+	return conn, nil
+}
+
+func (d *Dialer) connect(c net.Conn, host string, port uint16) error {
+	// greeting
+	method := byte(authNone)
+	if d.Username != "" {
+		method = authPassword
+	}
+	if _, err := c.Write([]byte{version5, 1, method}); err != nil {
+		return err
+	}
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(c, buf); err != nil {
+		return err
+	}
+	if buf[0] != version5 {
+		return errInvalidReply
+	}
+	if buf[1] != method {
+		return errNoAcceptableAuth
+	}
+
+	if method == authPassword {
+		if len(d.Username) > 255 || len(d.Password) > 255 {
+			return errAuthFailed
+		}
+		req := []byte{1, byte(len(d.Username))}
+		req = append(req, d.Username...)
+		req = append(req, byte(len(d.Password)))
+		req = append(req, d.Password...)
+		if _, err := c.Write(req); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(c, buf); err != nil {
+			return err
+		}
+		if buf[1] != 0 {
+			return errAuthFailed
+		}
+	}
+
+	// CONNECT
+	req := []byte{version5, cmdConnect, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return errHostTooLong
+		}
+		req = append(req, atypDomain, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, atypIPv4)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, atypIPv6)
+		req = append(req, ip.To16()...)
+	}
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := c.Write(req); err != nil {
+		return err
+	}
+
+	// reply: version, reply, reserved, atyp, bound address, bound port
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(c, head); err != nil {
+		return err
+	}
+	if head[0] != version5 {
+		return errInvalidReply
+	}
+	if head[1] != 0 {
+		if int(head[1]) < len(replyErrors) {
+			return errors.New(replyErrors[head[1]])
+		}
+		return fmt.Errorf("unknown socks5 reply %d", head[1])
+	}
+
+	var n int
+	switch head[3] {
+	case atypIPv4:
+		n = net.IPv4len
+	case atypIPv6:
+		n = net.IPv6len
+	case atypDomain:
+		if _, err := io.ReadFull(c, head[:1]); err != nil {
+			return err
+		}
+		n = int(head[0])
+	default:
+		return errInvalidReply
+	}
+	_, err := io.ReadFull(c, make([]byte, n+2))
+	return err
+}
+
+// Conn is a connection dialed through the proxy.
+type Conn struct {
+	net.Conn
+	remote *net.TCPAddr
+}
+
+// RemoteAddr returns the target address if it's an IP address, or the address of the proxy if
+// the target is a host name, e.g. an onion address, which is resolved by the proxy only.
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// IsOnion reports whether host is an onion address of tor.
+func IsOnion(host string) bool {
+	const suffix = ".onion"
+	return len(host) > len(suffix) && host[len(host)-len(suffix):] == suffix
+}
@@ -0,0 +1,130 @@
+package socks
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+// serveProxy accepts one connection, records the CONNECT request and replies with rep
+func serveProxy(t *testing.T, ln net.Listener, user, pass string, rep byte, target chan<- []byte) {
+	c, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	buf := make([]byte, 3)
+	if _, err = io.ReadFull(c, buf); err != nil {
+		t.Error(err)
+		return
+	}
+	c.Write([]byte{version5, buf[2]})
+
+	if buf[2] == authPassword {
+		head := make([]byte, 2)
+		io.ReadFull(c, head)
+		u := make([]byte, head[1])
+		io.ReadFull(c, u)
+		io.ReadFull(c, head[:1])
+		p := make([]byte, head[0])
+		io.ReadFull(c, p)
+		if string(u) != user || string(p) != pass {
+			c.Write([]byte{1, 1})
+			return
+		}
+		c.Write([]byte{1, 0})
+	}
+
+	head := make([]byte, 5)
+	if _, err = io.ReadFull(c, head); err != nil {
+		t.Error(err)
+		return
+	}
+	var n int
+	switch head[3] {
+	case atypIPv4:
+		n = net.IPv4len - 1
+	case atypIPv6:
+		n = net.IPv6len - 1
+	case atypDomain:
+		n = int(head[4])
+	}
+	addr := make([]byte, n+2)
+	io.ReadFull(c, addr)
+	target <- append(head[3:], addr...)
+
+	c.Write([]byte{version5, rep, 0, atypIPv4, 127, 0, 0, 1, 0, 80})
+	if rep == 0 {
+		c.Write([]byte("hello"))
+	}
+}
+
+func TestDialer_Dial(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	target := make(chan []byte, 1)
+	d := &Dialer{Proxy: ln.Addr().String()}
+
+	// onion address is resolved by the proxy
+	go serveProxy(t, ln, "", "", 0, target)
+	c, err := d.Dial("tcp", "vite.onion:8483")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req := <-target; req[0] != atypDomain || string(req[2:12]) != "vite.onion" || req[12] != 0x21 || req[13] != 0x23 {
+		t.Fatalf("unexpected connect request %v", req)
+	}
+	if c.RemoteAddr().String() != ln.Addr().String() {
+		t.Fatalf("remote address of onion target should be the proxy, got %s", c.RemoteAddr())
+	}
+	buf := make([]byte, 5)
+	if _, err = io.ReadFull(c, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("unexpected data %q: %v", buf, err)
+	}
+	c.Close()
+
+	// ip target with authentication
+	d.Username, d.Password = "vite", "best"
+	go serveProxy(t, ln, "vite", "best", 0, target)
+	if c, err = d.Dial("tcp", "[2001:db8::1]:8483"); err != nil {
+		t.Fatal(err)
+	}
+	if req := <-target; req[0] != atypIPv6 || !net.IP(req[1:17]).Equal(net.ParseIP("2001:db8::1")) {
+		t.Fatalf("unexpected connect request %v", req)
+	}
+	if addr, ok := c.RemoteAddr().(*net.TCPAddr); !ok || addr.String() != "[2001:db8::1]:8483" {
+		t.Fatalf("unexpected remote address %s", c.RemoteAddr())
+	}
+	c.Close()
+
+	// wrong password
+	go serveProxy(t, ln, "vite", "good", 0, target)
+	if _, err = d.Dial("tcp", "1.2.3.4:8483"); err == nil {
+		t.Fatal("authentication should fail")
+	}
+
+	// connection refused by the target
+	d.Username, d.Password = "", ""
+	go serveProxy(t, ln, "", "", 5, target)
+	if _, err = d.Dial("tcp", "1.2.3.4:8483"); err == nil {
+		t.Fatal("connect should fail")
+	}
+	if req := <-target; req[0] != atypIPv4 || !net.IP(req[1:5]).Equal(net.IPv4(1, 2, 3, 4)) {
+		t.Fatalf("unexpected connect request %v", req)
+	}
+
+	if _, err = d.Dial("udp", "1.2.3.4:8483"); err != errUnsupportedNetwork {
+		t.Fatalf("expected errUnsupportedNetwork, got %v", err)
+	}
+}
+
+func TestIsOnion(t *testing.T) {
+	if !IsOnion("vite.onion") || IsOnion(".onion") || IsOnion("vite.org") {
+		t.Fatal("unexpected IsOnion")
+	}
+}
@@ -26,9 +26,18 @@ var (
 	AddressPledge, _         = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3})
 	AddressConsensusGroup, _ = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4})
 	AddressMintage, _        = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5})
+	AddressAmm, _            = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6})
+	AddressAllowance, _      = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 7})
+	AddressEventRegistry, _  = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 8})
+	AddressCheckpoint, _     = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 9})
+	AddressQuotaLease, _     = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10})
 
 	PrecompiledContractAddressList             = []Address{AddressRegister, AddressVote, AddressPledge, AddressConsensusGroup, AddressMintage}
 	PrecompiledContractWithoutQuotaAddressList = []Address{AddressRegister, AddressVote, AddressPledge, AddressConsensusGroup, AddressMintage}
+
+	// ForkedPrecompiledContractAddressList are the built-in contracts added by forks, they are plain addresses
+	// before the snapshot height of their fork, see fork.IsPrecompiledContractAddress
+	ForkedPrecompiledContractAddressList = []Address{AddressAmm, AddressAllowance, AddressEventRegistry, AddressCheckpoint, AddressQuotaLease}
 )
 
 func IsPrecompiledContractAddress(addr Address) bool {
@@ -159,3 +168,12 @@ func (a *Address) UnmarshalJSON(input []byte) error {
 func (a Address) MarshalText() ([]byte, error) {
 	return []byte(a.String()), nil
 }
+
+func (a *Address) UnmarshalText(input []byte) error {
+	addresses, e := HexToAddress(string(input))
+	if e != nil {
+		return e
+	}
+	a.SetBytes(addresses.Bytes())
+	return nil
+}
@@ -25,9 +25,23 @@ func (groupInfo *ConsensusGroupInfo) IsActive() bool {
 	return groupInfo.WithdrawHeight > 0
 }
 
+// VoteWeightTotal is the weight of the whole balance of a voter, the votes split across several nodes have
+// weights summing up to it
+const VoteWeightTotal uint64 = 10000
+
 type VoteInfo struct {
 	VoterAddr Address
 	NodeName  string
+	Weight    uint64 // the share of the voter balance counted for the node, in units of 1/VoteWeightTotal
+}
+
+// WeightedBalance returns the share of the voter balance counted for the node
+func (v *VoteInfo) WeightedBalance(balance *big.Int) *big.Int {
+	if v.Weight >= VoteWeightTotal {
+		return new(big.Int).Set(balance)
+	}
+	result := new(big.Int).Mul(balance, new(big.Int).SetUint64(v.Weight))
+	return result.Quo(result, new(big.Int).SetUint64(VoteWeightTotal))
 }
 
 type Registration struct {
@@ -12,4 +12,16 @@ type Chain struct {
 	GenesisFile          string
 	LedgerGc             bool
 	OpenFilterTokenIndex bool
+	VmLogRetainDays      uint64
+	CompressBlockBody    bool
+	BlockFreezeDays      uint64
+	SplitKeyspaces       bool
+	// HistoryHeight pins the chain read only at the snapshot block of the height if it's not 0
+	HistoryHeight uint64
+	// LedgerGcRetainDays retains the states of the snapshot blocks of the days instead of LedgerGcRetain heights
+	// if it's not 0
+	LedgerGcRetainDays uint64
+	// LedgerGcCheckpointInterval keeps the states of the snapshot blocks at the multiples of the height from the
+	// ledger gc if it's not 0, so that the past states are still queried at the checkpoints
+	LedgerGcCheckpointInterval uint64
 }
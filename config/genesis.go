@@ -40,6 +40,52 @@ type ForkPoint struct {
 type ForkPoints struct {
 	Smart *ForkPoint
 	Mint  *ForkPoint
+	// Amm activates the built-in amm contract, it is not scheduled if nil
+	Amm *ForkPoint
+	// Allowance activates the built-in allowance contract, it is not scheduled if nil
+	Allowance *ForkPoint
+	// ReceiveQuota activates the quota metering of built-in contract receives, it is not scheduled if nil
+	ReceiveQuota *ForkPoint
+	// EventRegistry activates the built-in event registry contract, it is not scheduled if nil
+	EventRegistry *ForkPoint
+	// Checkpoint activates the built-in account checkpoint contract, it is not scheduled if nil
+	Checkpoint *ForkPoint
+	// AgentPledge activates the pledges of an agent on behalf of another address, it is not scheduled if nil
+	AgentPledge *ForkPoint
+	// PledgeLock activates the pledges locked for a tier of PledgeLockTiers, it is not scheduled if nil
+	PledgeLock *ForkPoint
+	// RegistrationDetail activates the display names and reward withdraw addresses of the registrations, it is
+	// not scheduled if nil
+	RegistrationDetail *ForkPoint
+	// SplitVote activates the votes split across several nodes, it is not scheduled if nil
+	SplitVote *ForkPoint
+	// DeterministicAddress activates the contract addresses derived from the creator, the salt and the code of
+	// a send create block, it is not scheduled if nil
+	DeterministicAddress *ForkPoint
+	// RevertReason activates the reasons kept in the data of the failed receive blocks, it is not scheduled if nil
+	RevertReason *ForkPoint
+	// Selfdestruct activates the SELFDESTRUCT opcode, it is not scheduled if nil
+	Selfdestruct *ForkPoint
+	// CongestionQuota scales the quota of the accounts down by the congestion of the net, it is not scheduled if nil
+	CongestionQuota *ForkPoint
+	// QuotaLease activates the built-in quota lease contract and the quota of the leased pledge amounts, it is not
+	// scheduled if nil
+	QuotaLease *ForkPoint
+	// StorageDeletion removes the deleted storage keys from the state trie instead of keeping empty values, it is not
+	// scheduled if nil
+	StorageDeletion *ForkPoint
+	// SizeLimit activates the size limits of the account block data and the vm logs, it is not scheduled if nil
+	SizeLimit *ForkPoint
+	// StorageSizeLimit activates the limit of the bytes the storage of an account grows by in an account block, it is
+	// not scheduled if nil
+	StorageSizeLimit *ForkPoint
+}
+
+// PledgeLockTier is a lock duration a pledge may choose, the locked pledge gets the quota of Multiplier
+// percent of its amount
+type PledgeLockTier struct {
+	LockHeight uint64
+	Multiplier uint64
 }
 
 type Genesis struct {
@@ -49,4 +95,6 @@ type Genesis struct {
 	CommonConsensusGroup   *ConsensusGroupInfo
 
 	ForkPoints *ForkPoints
+	// PledgeLockTiers are the lock tiers of the pledges in ascending lock height, numbered from 1
+	PledgeLockTiers []*PledgeLockTier
 }
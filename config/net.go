@@ -3,4 +3,11 @@ package config
 type Net struct {
 	Single      bool   `json:"Single"`
 	FileAddress string `json:"FileAddress"`
+	// unix time until which the legacy versions of the vite protocol are served, 0 means no deadline
+	LegacyProtocolDeadline int64 `json:"LegacyProtocolDeadline"`
+	// metadata of the node gossiped to the peers, the services are the names like archive and light-server
+	NodeOperator string   `json:"NodeOperator"`
+	NodeContact  string   `json:"NodeContact"`
+	NodeRegion   string   `json:"NodeRegion"`
+	NodeServices []string `json:"NodeServices"`
 }
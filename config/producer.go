@@ -4,6 +4,9 @@ type Producer struct {
 	Producer         bool   `json:"Producer"`
 	Coinbase         string `json:"Coinbase"`
 	EntropyStorePath string `json:"EntropyStorePath"`
+	// DevMining produces the snapshot blocks only on demand of the test api, for the integration tests of a
+	// dev network
+	DevMining bool `json:"DevMining"`
 }
 
 //func MergeMinerConfig(cfg *Miner) *Miner {
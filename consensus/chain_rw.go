@@ -87,15 +87,18 @@ func (self *chainRw) CalVoteDetails(gid types.Gid, info *core.GroupInfo, block l
 
 func (self *chainRw) GenVoteDetails(snapshotHash types.Hash, registration *types.Registration, infos []*types.VoteInfo, id types.TokenTypeId) *VoteDetails {
 	var addrs []types.Address
+	voteMap := make(map[types.Address]*types.VoteInfo)
 	for _, v := range infos {
 		if v.NodeName == registration.Name {
 			addrs = append(addrs, v.VoterAddr)
+			voteMap[v.VoterAddr] = v
 		}
 	}
 	balanceMap, _ := self.rw.GetBalanceList(snapshotHash, id, addrs)
 	balanceTotal := big.NewInt(0)
-	for _, v := range balanceMap {
-		balanceTotal.Add(balanceTotal, v)
+	for addr, v := range balanceMap {
+		balanceMap[addr] = voteMap[addr].WeightedBalance(v)
+		balanceTotal.Add(balanceTotal, balanceMap[addr])
 	}
 	return &VoteDetails{
 		Vote: core.Vote{
@@ -33,16 +33,18 @@ func CalVotes(info *GroupInfo, block ledger.HashHeight, rw stateCh) ([]*Vote, er
 }
 func GenVote(snapshotHash types.Hash, registration *types.Registration, infos []*types.VoteInfo, id types.TokenTypeId, rw stateCh) *Vote {
 	var addrs []types.Address
+	voteMap := make(map[types.Address]*types.VoteInfo)
 	for _, v := range infos {
 		if v.NodeName == registration.Name {
 			addrs = append(addrs, v.VoterAddr)
+			voteMap[v.VoterAddr] = v
 		}
 	}
 	result := &Vote{Balance: big.NewInt(0), Name: registration.Name, Addr: registration.NodeAddr}
 	if len(addrs) > 0 {
 		balanceMap, _ := rw.GetBalanceList(snapshotHash, id, addrs)
-		for _, v := range balanceMap {
-			result.Balance.Add(result.Balance, v)
+		for addr, v := range balanceMap {
+			result.Balance.Add(result.Balance, voteMap[addr].WeightedBalance(v))
 		}
 	}
 	return result
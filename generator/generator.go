@@ -188,7 +188,7 @@ func (gen *Generator) packSendBlockWithMessage(message *IncomingMessage) (blockP
 
 	if message.Difficulty != nil {
 		// currently, default mode of GenerateWithOnroad is to calc pow
-		nonce, err := pow.GetPowNonce(message.Difficulty, types.DataHash(append(blockPacked.AccountAddress.Bytes(), blockPacked.PrevHash.Bytes()...)))
+		nonce, err := pow.GenerateNonce(message.Difficulty, types.DataHash(append(blockPacked.AccountAddress.Bytes(), blockPacked.PrevHash.Bytes()...)))
 		if err != nil {
 			return nil, err
 		}
@@ -237,7 +237,7 @@ func (gen *Generator) packBlockWithSendBlock(sendBlock *ledger.AccountBlock, con
 		if snapshotBlock.Height > preBlockReferredSbHeight && difficulty != nil {
 			// currently, default mode of GenerateWithOnroad is to calc pow
 			//difficulty = pow.defaultDifficulty
-			nonce, err := pow.GetPowNonce(difficulty, types.DataHash(append(blockPacked.AccountAddress.Bytes(), blockPacked.PrevHash.Bytes()...)))
+			nonce, err := pow.GenerateNonce(difficulty, types.DataHash(append(blockPacked.AccountAddress.Bytes(), blockPacked.PrevHash.Bytes()...)))
 			if err != nil {
 				return nil, err
 			}
@@ -116,6 +116,10 @@ type AccountBlock struct {
 	Difficulty *big.Int `json:"difficulty"`
 	Nonce      []byte   `json:"nonce"`
 	Signature  []byte   `json:"signature"`
+
+	// Salt derives the contract address of a send create block from the creator and the code instead of the
+	// height and prev hash, it is nil for the other blocks
+	Salt *types.Hash `json:"salt,omitempty"`
 }
 
 func (ab *AccountBlock) Copy() *AccountBlock {
@@ -159,6 +163,11 @@ func (ab *AccountBlock) Copy() *AccountBlock {
 
 	newAb.Signature = make([]byte, len(ab.Signature))
 	copy(newAb.Signature, ab.Signature)
+
+	if ab.Salt != nil {
+		salt := *ab.Salt
+		newAb.Salt = &salt
+	}
 	return &newAb
 }
 
@@ -214,6 +223,9 @@ func (ab *AccountBlock) proto() *vitepb.AccountBlock {
 	}
 	pb.Nonce = ab.Nonce
 	pb.Signature = ab.Signature
+	if ab.Salt != nil {
+		pb.Salt = ab.Salt.Bytes()
+	}
 	return pb
 }
 
@@ -282,6 +294,11 @@ func (ab *AccountBlock) DeProto(pb *vitepb.AccountBlock) {
 	ab.Nonce = pb.Nonce
 	ab.Signature = pb.Signature
 
+	if len(pb.Salt) > 0 {
+		salt, _ := types.BytesToHash(pb.Salt)
+		ab.Salt = &salt
+	}
+
 }
 
 func (ab *AccountBlock) ComputeHash() types.Hash {
@@ -338,6 +355,12 @@ func (ab *AccountBlock) ComputeHash() types.Hash {
 	// Nonce
 	source = append(source, ab.Nonce...)
 
+	// Salt, led by its length so that it is never read as a part of the nonce. The hash of a block without it is kept
+	if ab.Salt != nil {
+		source = append(source, byte(types.HashSize))
+		source = append(source, ab.Salt.Bytes()...)
+	}
+
 	hash, _ := types.BytesToHash(crypto.Hash256(source))
 	return hash
 }
@@ -368,13 +391,16 @@ func (ab *AccountBlock) Serialize() ([]byte, error) {
 }
 
 func (ab *AccountBlock) Deserialize(buf []byte) error {
+	if err := checkSize("account block", len(buf), MaxAccountBlockSize); err != nil {
+		return err
+	}
 	pb := &vitepb.AccountBlock{}
 	if err := proto.Unmarshal(buf, pb); err != nil {
 		return err
 	}
 	ab.DeProto(pb)
 
-	return nil
+	return ab.CheckSize()
 }
 
 func (ab *AccountBlock) IsSendBlock() bool {
@@ -42,6 +42,40 @@ func TestComputeHash(t *testing.T) {
 	fmt.Println(block.ComputeHash())
 }
 
+func TestComputeHash_Salt(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_847e1672c9a775ca0f3c3a2d3bf389ca466e5501cbecdb7107")
+	nonce, _ := base64.StdEncoding.DecodeString("PRdIJ3eSXDQ=")
+	fromBlockHash, _ := types.HexToHash("48290760a0249c28e92bfbcac31e1c0b61e74f666bddc1a2574b96a7bb533852")
+	snapshotBlockHash, _ := types.HexToHash("3e3393b720679ff09dbc57f6e23570dbca3dc947cf28cdcbad3abc1cb6da2bee")
+	ts := time.Unix(1539604021, 0)
+	block := &AccountBlock{
+		BlockType: 4,
+
+		Height:         1,
+		PrevHash:       types.Hash{},
+		AccountAddress: addr,
+		Fee:            big.NewInt(1),
+		Nonce:          nonce,
+		Timestamp:      &ts,
+		FromBlockHash:  fromBlockHash,
+		SnapshotHash:   snapshotBlockHash,
+	}
+	// the hash of a block without a salt is kept
+	if hash := block.ComputeHash().String(); hash != "a6436a6fd52a962a30cf13b15fce1734ba79c88b9b80e6267343f93f42fb58d5" {
+		t.Fatalf("the hash of a block without a salt changed, got %v", hash)
+	}
+
+	salt := types.DataHash([]byte("salt"))
+	saltedBlock := block.Copy()
+	saltedBlock.Salt = &salt
+	// a block without a salt whose nonce ends with the salt bytes
+	similarBlock := block.Copy()
+	similarBlock.Nonce = append(append([]byte{}, nonce...), salt.Bytes()...)
+	if saltedBlock.ComputeHash() == block.ComputeHash() || saltedBlock.ComputeHash() == similarBlock.ComputeHash() {
+		t.Fatal("a salted block should not hash as a block without the salt")
+	}
+}
+
 func TestHash(t *testing.T) {
 	source := []byte("050697d3810c30816b005a03511c734c1159f5090000000000000000000000000000000000000000000000000000000000000000")
 
@@ -0,0 +1,38 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// DestinationTagPrefix marks the data of a send block as starting with a destination tag, which lets
+// exchanges route deposits to one address to sub accounts. The data is the prefix, the tag as 8 big-endian
+// bytes and an optional memo.
+var DestinationTagPrefix = []byte{0x76, 0x74, 0x61, 0x67}
+
+const destinationTagDataSize = 4 + 8
+
+// NewDestinationTagData returns the data of a send block carrying tag and memo.
+func NewDestinationTagData(tag uint64, memo []byte) []byte {
+	data := make([]byte, destinationTagDataSize, destinationTagDataSize+len(memo))
+	copy(data, DestinationTagPrefix)
+	binary.BigEndian.PutUint64(data[len(DestinationTagPrefix):], tag)
+	return append(data, memo...)
+}
+
+// ParseDestinationTag returns the destination tag and the memo in data, ok is false if data carries no tag.
+func ParseDestinationTag(data []byte) (tag uint64, memo []byte, ok bool) {
+	if len(data) < destinationTagDataSize || !bytes.HasPrefix(data, DestinationTagPrefix) {
+		return 0, nil, false
+	}
+	return binary.BigEndian.Uint64(data[len(DestinationTagPrefix):destinationTagDataSize]), data[destinationTagDataSize:], true
+}
+
+// DestinationTag returns the destination tag of a send call block, ok is false if the block carries no tag.
+func (ab *AccountBlock) DestinationTag() (tag uint64, ok bool) {
+	if ab.BlockType != BlockTypeSendCall {
+		return 0, false
+	}
+	tag, _, ok = ParseDestinationTag(ab.Data)
+	return tag, ok
+}
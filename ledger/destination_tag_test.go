@@ -0,0 +1,29 @@
+package ledger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDestinationTag(t *testing.T) {
+	data := NewDestinationTagData(1234567, []byte("memo"))
+	tag, memo, ok := ParseDestinationTag(data)
+	if !ok || tag != 1234567 || !bytes.Equal(memo, []byte("memo")) {
+		t.Fatalf("parse failed, tag %v, memo %s, ok %v", tag, memo, ok)
+	}
+
+	block := &AccountBlock{BlockType: BlockTypeSendCall, Data: data}
+	if tag, ok := block.DestinationTag(); !ok || tag != 1234567 {
+		t.Fatalf("unexpected block tag %v, ok %v", tag, ok)
+	}
+	block.BlockType = BlockTypeReceive
+	if _, ok := block.DestinationTag(); ok {
+		t.Fatal("receive block should carry no tag")
+	}
+
+	for _, data := range [][]byte{nil, []byte("memo"), DestinationTagPrefix, append([]byte{0}, data...)} {
+		if _, _, ok := ParseDestinationTag(data); ok {
+			t.Fatalf("data %v should carry no tag", data)
+		}
+	}
+}
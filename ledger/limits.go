@@ -0,0 +1,72 @@
+package ledger
+
+import (
+	"fmt"
+)
+
+const (
+	// MaxAccountBlockDataSize is the max size of the data field of an account block
+	MaxAccountBlockDataSize = 1024 * 1024
+	// MaxAccountBlockSize is the max size of a serialized account block received from the net
+	MaxAccountBlockSize = MaxAccountBlockDataSize + 4*1024
+	// MaxVmLogDataSize is the max size of the data field of a vm log
+	MaxVmLogDataSize = 64 * 1024
+	// MaxVmLogTopics is the max count of topics of a vm log
+	MaxVmLogTopics = 4
+	// MaxStorageSizeDelta is the max bytes the storage of an account grows by in an account block, so that a
+	// contract of the max code size can still be created
+	MaxStorageSizeDelta = MaxAccountBlockDataSize + 64*1024
+)
+
+// SizeLimitError is returned when a field exceeds its protocol size limit.
+type SizeLimitError struct {
+	Field string
+	Size  int
+	Limit int
+}
+
+func (e *SizeLimitError) Error() string {
+	return fmt.Sprintf("%s size %d exceeds limit %d", e.Field, e.Size, e.Limit)
+}
+
+func checkSize(field string, size int, limit int) error {
+	if size > limit {
+		return &SizeLimitError{Field: field, Size: size, Limit: limit}
+	}
+	return nil
+}
+
+// CheckSize checks the data field of the account block against the protocol limit.
+func (ab *AccountBlock) CheckSize() error {
+	return checkSize("account block data", len(ab.Data), MaxAccountBlockDataSize)
+}
+
+// CheckSize checks the topics and data of the vm log against the protocol limits.
+func (vl *VmLog) CheckSize() error {
+	if err := checkSize("vm log topics", len(vl.Topics), MaxVmLogTopics); err != nil {
+		return err
+	}
+	return checkSize("vm log data", len(vl.Data), MaxVmLogDataSize)
+}
+
+// CheckSize checks every vm log of the list against the protocol limits.
+func (vll VmLogList) CheckSize() error {
+	for _, vmLog := range vll {
+		if err := vmLog.CheckSize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckStorageSizeDelta checks the bytes the storage of an account grows by in an account block against the protocol
+// limit.
+func CheckStorageSizeDelta(sizeDelta int64) error {
+	if sizeDelta <= 0 {
+		return nil
+	}
+	if sizeDelta > MaxStorageSizeDelta {
+		return &SizeLimitError{Field: "storage delta", Size: int(sizeDelta), Limit: MaxStorageSizeDelta}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package ledger
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+func TestAccountBlock_CheckSize(t *testing.T) {
+	block := &AccountBlock{Data: make([]byte, MaxAccountBlockDataSize)}
+	if err := block.CheckSize(); err != nil {
+		t.Fatal(err)
+	}
+	block.Data = append(block.Data, 0)
+	err := block.CheckSize()
+	if _, ok := err.(*SizeLimitError); !ok {
+		t.Fatalf("expected SizeLimitError, got %v", err)
+	}
+
+	if err := block.Deserialize(make([]byte, MaxAccountBlockSize+1)); err == nil {
+		t.Fatal("oversized account block should fail to deserialize")
+	}
+}
+
+func TestVmLogList_CheckSize(t *testing.T) {
+	logs := VmLogList{{Topics: make([]types.Hash, MaxVmLogTopics), Data: make([]byte, MaxVmLogDataSize)}}
+	if err := logs.CheckSize(); err != nil {
+		t.Fatal(err)
+	}
+	logs = append(logs, &VmLog{Topics: make([]types.Hash, MaxVmLogTopics+1)})
+	if err := logs.CheckSize(); err == nil {
+		t.Fatal("expected topics size error")
+	}
+	logs[1] = &VmLog{Data: make([]byte, MaxVmLogDataSize+1)}
+	if err := logs.CheckSize(); err == nil {
+		t.Fatal("expected data size error")
+	}
+}
+
+func TestCheckStorageSizeDelta(t *testing.T) {
+	for _, sizeDelta := range []int64{-MaxStorageSizeDelta - 1, 0, MaxStorageSizeDelta} {
+		if err := CheckStorageSizeDelta(sizeDelta); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := CheckStorageSizeDelta(MaxStorageSizeDelta + 1).(*SizeLimitError); !ok {
+		t.Fatal("expected SizeLimitError")
+	}
+}
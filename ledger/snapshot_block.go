@@ -105,7 +105,7 @@ func (sc SnapshotContent) Proto() *vitepb.SnapshotContent {
 
 func (sc *SnapshotContent) Serialize() ([]byte, error) {
 	pb := sc.Proto()
-	buf, err := proto.Marshal(pb)
+	buf, err := marshalDeterministic(pb)
 	if err != nil {
 		snapshotBlockLog.Error("proto.Marshal failed, error is "+err.Error(), "method", "SnapshotContent.Serialize")
 		return nil, err
@@ -124,6 +124,17 @@ func (sc *SnapshotContent) Deserialize(buf []byte) error {
 	return nil
 }
 
+// marshalDeterministic marshals pb with the map entries sorted by key, so that
+// the snapshot content is always encoded into the same bytes.
+func marshalDeterministic(pb proto.Message) ([]byte, error) {
+	buffer := proto.NewBuffer(nil)
+	buffer.SetDeterministic(true)
+	if err := buffer.Marshal(pb); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
 type SnapshotBlock struct {
 	Hash types.Hash `json:"hash"`
 
@@ -243,7 +254,7 @@ func (sb *SnapshotBlock) DbSerialize() ([]byte, error) {
 
 func (sb *SnapshotBlock) Serialize() ([]byte, error) {
 	pb := sb.Proto()
-	buf, err := proto.Marshal(pb)
+	buf, err := marshalDeterministic(pb)
 	if err != nil {
 		snapshotBlockLog.Error("proto.Marshal failed, error is "+err.Error(), "method", "SnapshotBlock.Serialize")
 		return nil, err
@@ -0,0 +1,49 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"time", "address", "token", "tokenId", "type", "counterparty", "amount", "fiatValue", "currency", "height", "hash"}
+
+// WriteCSV writes a row for each entry of s, the groups one after another. The amounts are in whole tokens
+// and the times in UTC.
+func WriteCSV(w io.Writer, s *Statement) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, group := range s.Groups {
+		for _, entry := range group.Entries {
+			counterparty := ""
+			if entry.Type != EntryTypeFee {
+				counterparty = entry.Counterparty.String()
+			}
+			currency := ""
+			if entry.FiatValue != nil {
+				currency = s.Currency
+			}
+			record := []string{
+				entry.Time.UTC().Format(time.RFC3339),
+				s.Address.String(),
+				group.Symbol,
+				group.TokenId.String(),
+				entry.Type,
+				counterparty,
+				FormatAmount(entry.Amount, group.Decimals),
+				formatFiat(entry.FiatValue),
+				currency,
+				strconv.FormatUint(entry.Height, 10),
+				entry.Hash.String(),
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
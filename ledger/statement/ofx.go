@@ -0,0 +1,147 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// ofxBankId identifies the chain in the BANKID of the accounts
+const ofxBankId = "VITE"
+
+const ofxTimeLayout = "20060102150405"
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+var ofxStatusOk = ofxStatus{Code: 0, Severity: "INFO"}
+
+type ofxDocument struct {
+	XMLName xml.Name `xml:"OFX"`
+	SignOn  struct {
+		Status   ofxStatus `xml:"STATUS"`
+		DtServer string    `xml:"DTSERVER"`
+		Language string    `xml:"LANGUAGE"`
+	} `xml:"SIGNONMSGSRSV1>SONRS"`
+	Statements []*ofxStatementResponse `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatementResponse struct {
+	TrnUid  string    `xml:"TRNUID"`
+	Status  ofxStatus `xml:"STATUS"`
+	CurDef  string    `xml:"STMTRS>CURDEF"`
+	Account struct {
+		BankId   string `xml:"BANKID"`
+		AcctId   string `xml:"ACCTID"`
+		AcctType string `xml:"ACCTTYPE"`
+	} `xml:"STMTRS>BANKACCTFROM"`
+	TranList struct {
+		DtStart      string            `xml:"DTSTART"`
+		DtEnd        string            `xml:"DTEND"`
+		Transactions []*ofxTransaction `xml:"STMTTRN"`
+	} `xml:"STMTRS>BANKTRANLIST"`
+	LedgerBal struct {
+		BalAmt string `xml:"BALAMT"`
+		DtAsOf string `xml:"DTASOF"`
+	} `xml:"STMTRS>LEDGERBAL"`
+}
+
+type ofxTransaction struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DtPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FitId    string `xml:"FITID"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+// WriteOFX writes s as an OFX 2.2 document with a bank statement for each token group, the account id is
+// the address and the token id, and the currency is the token symbol. The ledger balance of a statement is
+// the net flow of the entries, not the balance of the address.
+func WriteOFX(w io.Writer, s *Statement) error {
+	now := time.Now().UTC()
+	doc := &ofxDocument{}
+	doc.SignOn.Status = ofxStatusOk
+	doc.SignOn.DtServer = now.Format(ofxTimeLayout)
+	doc.SignOn.Language = "ENG"
+
+	for i, group := range s.Groups {
+		response := &ofxStatementResponse{
+			TrnUid: strconv.Itoa(i + 1),
+			Status: ofxStatusOk,
+			CurDef: group.Symbol,
+		}
+		response.Account.BankId = ofxBankId
+		response.Account.AcctId = s.Address.String() + ":" + group.TokenId.String()
+		response.Account.AcctType = "CHECKING"
+
+		start, end := s.From, s.To
+		if start.IsZero() && len(group.Entries) > 0 {
+			start = group.Entries[0].Time
+		}
+		if end.IsZero() {
+			end = now
+		}
+		response.TranList.DtStart = start.UTC().Format(ofxTimeLayout)
+		response.TranList.DtEnd = end.UTC().Format(ofxTimeLayout)
+
+		for _, entry := range group.Entries {
+			response.TranList.Transactions = append(response.TranList.Transactions, &ofxTransaction{
+				TrnType:  ofxTransactionType(entry),
+				DtPosted: entry.Time.UTC().Format(ofxTimeLayout),
+				TrnAmt:   FormatAmount(entry.Amount, group.Decimals),
+				// a send block has an entry of the amount and one of the fee
+				FitId: entry.Hash.String() + "-" + entry.Type,
+				Memo:  ofxMemo(entry, s.Currency),
+			})
+		}
+		response.LedgerBal.BalAmt = FormatAmount(new(big.Int).Sub(group.Inflow, group.Outflow), group.Decimals)
+		response.LedgerBal.DtAsOf = response.TranList.DtEnd
+		doc.Statements = append(doc.Statements, response)
+	}
+
+	if _, err := io.WriteString(w, ofxHeader); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func ofxTransactionType(entry *Entry) string {
+	switch entry.Type {
+	case EntryTypeFee:
+		return "FEE"
+	case EntryTypeReceive:
+		return "CREDIT"
+	default:
+		return "DEBIT"
+	}
+}
+
+func ofxMemo(entry *Entry, currency string) string {
+	memo := ""
+	switch entry.Type {
+	case EntryTypeSend:
+		memo = "to " + entry.Counterparty.String()
+	case EntryTypeReceive:
+		memo = "from " + entry.Counterparty.String()
+	case EntryTypeFee:
+		memo = "fee"
+	}
+	if entry.FiatValue != nil {
+		memo += " (" + formatFiat(entry.FiatValue) + " " + currency + ")"
+	}
+	return memo
+}
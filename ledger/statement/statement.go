@@ -0,0 +1,304 @@
+package statement
+
+// statement builds the statements of an address from its account blocks and writes them in the formats
+// accounting software imports, CSV and OFX. Each entry is a single movement of a token, the fee of a send
+// block is an entry of its own.
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// readBatchSize is the count of the account blocks read from the chain at a time
+const readBatchSize = 100
+
+const (
+	EntryTypeSend    = "send"
+	EntryTypeReceive = "receive"
+	EntryTypeFee     = "fee"
+)
+
+var ErrInvalidRange = errors.New("the end of the range is before the start")
+
+// Chain is the part of chain.Chain a statement is built from.
+type Chain interface {
+	GetLatestAccountBlock(addr *types.Address) (*ledger.AccountBlock, error)
+	GetAccountBlocksByHeight(addr types.Address, start uint64, count uint64, forward bool) ([]*ledger.AccountBlock, error)
+	GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error)
+	GetTokenInfoById(tokenId *types.TokenTypeId) (*types.TokenInfo, error)
+}
+
+// PriceSource values the tokens in a fiat currency.
+type PriceSource interface {
+	Currency() string
+	// Price returns the price of a whole token at t, nil if unknown
+	Price(tokenId types.TokenTypeId, t time.Time) (*big.Float, error)
+}
+
+// StaticPrices values the tokens at fixed prices, whatever the time is.
+type StaticPrices struct {
+	FiatCurrency string
+	Prices       map[types.TokenTypeId]*big.Float
+}
+
+func (s *StaticPrices) Currency() string {
+	return s.FiatCurrency
+}
+
+func (s *StaticPrices) Price(tokenId types.TokenTypeId, t time.Time) (*big.Float, error) {
+	return s.Prices[tokenId], nil
+}
+
+// Filter selects the entries of a statement. The zero From or To leaves the range open on that side, nil
+// TokenIds selects all the tokens.
+type Filter struct {
+	From     time.Time
+	To       time.Time
+	TokenIds []types.TokenTypeId
+}
+
+func (f *Filter) containsToken(tokenId types.TokenTypeId) bool {
+	if len(f.TokenIds) == 0 {
+		return true
+	}
+	for _, id := range f.TokenIds {
+		if id == tokenId {
+			return true
+		}
+	}
+	return false
+}
+
+type Entry struct {
+	Time   time.Time
+	Height uint64
+	Hash   types.Hash
+	Type   string
+	// Counterparty is the receiver of a send, the sender of a receive and empty for a fee
+	Counterparty types.Address
+	// Amount is negative for the sends and the fees
+	Amount *big.Int
+	// FiatValue is the value of Amount at Time, nil without a price
+	FiatValue *big.Float
+}
+
+// TokenGroup is the entries of a token in ascending order of height.
+type TokenGroup struct {
+	TokenId  types.TokenTypeId
+	Symbol   string
+	Decimals uint8
+	Entries  []*Entry
+	Inflow   *big.Int
+	Outflow  *big.Int
+}
+
+type Statement struct {
+	Address types.Address
+	From    time.Time
+	To      time.Time
+	// Currency is the fiat currency of the values, empty without a price source
+	Currency string
+	Groups   []*TokenGroup
+}
+
+// Build builds the statement of addr from the account blocks of c, usually a chain.Chain. The blocks are read from the latest
+// backwards until one is before filter.From, prices may be nil.
+func Build(c Chain, addr types.Address, filter Filter, prices PriceSource) (*Statement, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, ErrInvalidRange
+	}
+	b := &builder{
+		chain:     c,
+		filter:    filter,
+		prices:    prices,
+		groups:    make(map[types.TokenTypeId]*TokenGroup),
+		statement: &Statement{Address: addr, From: filter.From, To: filter.To},
+	}
+	if prices != nil {
+		b.statement.Currency = prices.Currency()
+	}
+
+	latestBlock, err := c.GetLatestAccountBlock(&addr)
+	if err != nil {
+		return nil, err
+	}
+	if latestBlock == nil {
+		return b.statement, nil
+	}
+	for height := latestBlock.Height; height > 0; {
+		blocks, err := c.GetAccountBlocksByHeight(addr, height, readBatchSize, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			break
+		}
+		// the blocks are in descending order of height
+		for _, block := range blocks {
+			if block.Timestamp != nil && !filter.From.IsZero() && block.Timestamp.Before(filter.From) {
+				return b.finish(), nil
+			}
+			if err := b.add(block); err != nil {
+				return nil, err
+			}
+		}
+		lowest := blocks[len(blocks)-1].Height
+		if lowest <= 1 {
+			break
+		}
+		height = lowest - 1
+	}
+	return b.finish(), nil
+}
+
+type builder struct {
+	chain     Chain
+	filter    Filter
+	prices    PriceSource
+	groups    map[types.TokenTypeId]*TokenGroup
+	statement *Statement
+}
+
+func (b *builder) add(block *ledger.AccountBlock) error {
+	var t time.Time
+	if block.Timestamp != nil {
+		t = *block.Timestamp
+	}
+	if !b.filter.To.IsZero() && t.After(b.filter.To) {
+		return nil
+	}
+
+	switch {
+	case block.IsSendBlock():
+		// backwards, so the fee is added before the send it follows
+		if block.Fee != nil && block.Fee.Sign() > 0 {
+			if err := b.addEntry(block, t, EntryTypeFee, types.Address{}, ledger.ViteTokenId, new(big.Int).Neg(block.Fee)); err != nil {
+				return err
+			}
+		}
+		if block.Amount != nil && block.Amount.Sign() > 0 {
+			return b.addEntry(block, t, EntryTypeSend, block.ToAddress, block.TokenId, new(big.Int).Neg(block.Amount))
+		}
+	case block.BlockType == ledger.BlockTypeReceive:
+		// a receive error block leaves the balance untouched, the tokens are refunded
+		sendBlock, err := b.chain.GetAccountBlockByHash(&block.FromBlockHash)
+		if err != nil {
+			return err
+		}
+		if sendBlock != nil && sendBlock.Amount != nil && sendBlock.Amount.Sign() > 0 {
+			return b.addEntry(block, t, EntryTypeReceive, sendBlock.AccountAddress, sendBlock.TokenId, new(big.Int).Set(sendBlock.Amount))
+		}
+	}
+	return nil
+}
+
+func (b *builder) addEntry(block *ledger.AccountBlock, t time.Time, entryType string, counterparty types.Address, tokenId types.TokenTypeId, amount *big.Int) error {
+	if !b.filter.containsToken(tokenId) {
+		return nil
+	}
+	group, err := b.getGroup(tokenId)
+	if err != nil {
+		return err
+	}
+	entry := &Entry{
+		Time:         t,
+		Height:       block.Height,
+		Hash:         block.Hash,
+		Type:         entryType,
+		Counterparty: counterparty,
+		Amount:       amount,
+	}
+	if b.prices != nil {
+		price, err := b.prices.Price(tokenId, t)
+		if err != nil {
+			return err
+		}
+		if price != nil {
+			entry.FiatValue = new(big.Float).Mul(tokenAmount(amount, group.Decimals), price)
+		}
+	}
+	group.Entries = append(group.Entries, entry)
+	if amount.Sign() > 0 {
+		group.Inflow.Add(group.Inflow, amount)
+	} else {
+		group.Outflow.Sub(group.Outflow, amount)
+	}
+	return nil
+}
+
+func (b *builder) getGroup(tokenId types.TokenTypeId) (*TokenGroup, error) {
+	if group, ok := b.groups[tokenId]; ok {
+		return group, nil
+	}
+	group := &TokenGroup{TokenId: tokenId, Inflow: new(big.Int), Outflow: new(big.Int)}
+	tokenInfo, err := b.chain.GetTokenInfoById(&tokenId)
+	if err != nil {
+		return nil, err
+	}
+	if tokenInfo != nil {
+		group.Symbol = tokenInfo.TokenSymbol
+		group.Decimals = tokenInfo.Decimals
+	}
+	b.groups[tokenId] = group
+	return group, nil
+}
+
+// finish sorts the entries read backwards, and the groups by symbol
+func (b *builder) finish() *Statement {
+	for _, group := range b.groups {
+		for i, j := 0, len(group.Entries)-1; i < j; i, j = i+1, j-1 {
+			group.Entries[i], group.Entries[j] = group.Entries[j], group.Entries[i]
+		}
+		b.statement.Groups = append(b.statement.Groups, group)
+	}
+	sort.Slice(b.statement.Groups, func(i, j int) bool {
+		if b.statement.Groups[i].Symbol != b.statement.Groups[j].Symbol {
+			return b.statement.Groups[i].Symbol < b.statement.Groups[j].Symbol
+		}
+		return b.statement.Groups[i].TokenId.String() < b.statement.Groups[j].TokenId.String()
+	})
+	return b.statement
+}
+
+// tokenAmount converts amount in the smallest unit to whole tokens
+func tokenAmount(amount *big.Int, decimals uint8) *big.Float {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Float).Quo(new(big.Float).SetInt(amount), new(big.Float).SetInt(unit))
+}
+
+// FormatAmount formats amount in the smallest unit as a decimal of whole tokens, exactly.
+func FormatAmount(amount *big.Int, decimals uint8) string {
+	sign := ""
+	abs := new(big.Int).Set(amount)
+	if abs.Sign() < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+	digits := abs.String()
+	if decimals == 0 {
+		return sign + digits
+	}
+	for len(digits) <= int(decimals) {
+		digits = "0" + digits
+	}
+	intPart, fracPart := digits[:len(digits)-int(decimals)], digits[len(digits)-int(decimals):]
+	for len(fracPart) > 0 && fracPart[len(fracPart)-1] == '0' {
+		fracPart = fracPart[:len(fracPart)-1]
+	}
+	if fracPart == "" {
+		return sign + intPart
+	}
+	return sign + intPart + "." + fracPart
+}
+
+func formatFiat(value *big.Float) string {
+	if value == nil {
+		return ""
+	}
+	return value.Text('f', 2)
+}
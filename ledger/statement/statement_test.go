@@ -0,0 +1,136 @@
+package statement
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type testChain struct {
+	blocks []*ledger.AccountBlock
+	tokens map[types.TokenTypeId]*types.TokenInfo
+}
+
+func (c *testChain) GetLatestAccountBlock(addr *types.Address) (*ledger.AccountBlock, error) {
+	return c.blocks[len(c.blocks)-1], nil
+}
+
+func (c *testChain) GetAccountBlocksByHeight(addr types.Address, start uint64, count uint64, forward bool) ([]*ledger.AccountBlock, error) {
+	var blocks []*ledger.AccountBlock
+	for height := start; height > 0 && uint64(len(blocks)) < count; height-- {
+		blocks = append(blocks, c.blocks[height-1])
+	}
+	return blocks, nil
+}
+
+func (c *testChain) GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error) {
+	if *blockHash == (types.Hash{100}) {
+		return &ledger.AccountBlock{AccountAddress: types.Address{2}, TokenId: ledger.ViteTokenId, Amount: big.NewInt(3e18)}, nil
+	}
+	return nil, nil
+}
+
+func (c *testChain) GetTokenInfoById(tokenId *types.TokenTypeId) (*types.TokenInfo, error) {
+	return c.tokens[*tokenId], nil
+}
+
+func newTestChain() *testChain {
+	tokenId := types.TokenTypeId{1}
+	t1, t2, t3 := time.Unix(1000, 0), time.Unix(2000, 0), time.Unix(3000, 0)
+	return &testChain{
+		blocks: []*ledger.AccountBlock{
+			{Height: 1, Hash: types.Hash{1}, BlockType: ledger.BlockTypeReceive, FromBlockHash: types.Hash{100}, Timestamp: &t1},
+			{Height: 2, Hash: types.Hash{2}, BlockType: ledger.BlockTypeSendCall, ToAddress: types.Address{3}, TokenId: ledger.ViteTokenId,
+				Amount: big.NewInt(1e18), Fee: big.NewInt(5e17), Timestamp: &t2},
+			{Height: 3, Hash: types.Hash{3}, BlockType: ledger.BlockTypeSendCall, ToAddress: types.Address{3}, TokenId: tokenId,
+				Amount: big.NewInt(250), Fee: big.NewInt(0), Timestamp: &t3},
+		},
+		tokens: map[types.TokenTypeId]*types.TokenInfo{
+			ledger.ViteTokenId: {TokenSymbol: "VITE", Decimals: 18},
+			tokenId:            {TokenSymbol: "AAA", Decimals: 2},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	c := newTestChain()
+	prices := &StaticPrices{FiatCurrency: "USD", Prices: map[types.TokenTypeId]*big.Float{ledger.ViteTokenId: big.NewFloat(0.5)}}
+	s, err := Build(c, types.Address{1}, Filter{}, prices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Groups) != 2 || s.Groups[0].Symbol != "AAA" || s.Groups[1].Symbol != "VITE" {
+		t.Fatalf("unexpected groups %+v", s.Groups)
+	}
+	vite := s.Groups[1]
+	if len(vite.Entries) != 3 || vite.Entries[0].Type != EntryTypeReceive || vite.Entries[1].Type != EntryTypeSend || vite.Entries[2].Type != EntryTypeFee {
+		t.Fatalf("unexpected entries %+v", vite.Entries)
+	}
+	if FormatAmount(vite.Inflow, 18) != "3" || FormatAmount(vite.Outflow, 18) != "1.5" || formatFiat(vite.Entries[1].FiatValue) != "-0.50" {
+		t.Fatalf("unexpected flows %v %v", vite.Inflow, vite.Outflow)
+	}
+	if s.Groups[0].Entries[0].FiatValue != nil {
+		t.Fatal("the token without a price should have no fiat value")
+	}
+
+	s, err = Build(c, types.Address{1}, Filter{From: time.Unix(1500, 0), To: time.Unix(2500, 0), TokenIds: []types.TokenTypeId{ledger.ViteTokenId}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Groups) != 1 || len(s.Groups[0].Entries) != 2 || s.Groups[0].Entries[0].Height != 2 {
+		t.Fatalf("unexpected filtered statement %+v", s.Groups)
+	}
+
+	if _, err := Build(c, types.Address{1}, Filter{From: time.Unix(2, 0), To: time.Unix(1, 0)}, nil); err != ErrInvalidRange {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	s, err := Build(newTestChain(), types.Address{1}, Filter{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[1], ",AAA,") || !strings.Contains(lines[1], ",-2.5,") {
+		t.Fatalf("unexpected csv %s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteOFX(&buf, s); err != nil {
+		t.Fatal(err)
+	}
+	ofx := buf.String()
+	if strings.Count(ofx, "<STMTTRN>") != 4 || strings.Count(ofx, "<STMTTRNRS>") != 2 ||
+		!strings.Contains(ofx, "<TRNTYPE>FEE</TRNTYPE>") || !strings.Contains(ofx, "<BALAMT>1.5</BALAMT>") {
+		t.Fatalf("unexpected ofx %s", ofx)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	for _, test := range []struct {
+		amount   int64
+		decimals uint8
+		result   string
+	}{
+		{0, 18, "0"},
+		{1, 2, "0.01"},
+		{-150, 2, "-1.5"},
+		{1200, 2, "12"},
+		{7, 0, "7"},
+	} {
+		if result := FormatAmount(big.NewInt(test.amount), test.decimals); result != test.result {
+			t.Fatalf("format %v with %v decimals, expected %v, got %v", test.amount, test.decimals, test.result, result)
+		}
+	}
+}
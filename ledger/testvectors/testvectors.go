@@ -0,0 +1,347 @@
+package testvectors
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+)
+
+// Version is bumped whenever the set of generated vectors changes.
+const Version = 1
+
+// Vectors are the canonical encodings and hashes of the ledger structures, they are
+// generated deterministically so that other implementations can check compatibility.
+type Vectors struct {
+	Version        int                    `json:"version"`
+	ForkPoints     *config.ForkPoints     `json:"forkPoints"`
+	AccountBlocks  []*AccountBlockVector  `json:"accountBlocks"`
+	SnapshotBlocks []*SnapshotBlockVector `json:"snapshotBlocks"`
+	Tokens         []*TokenVector         `json:"tokens"`
+}
+
+type AccountBlockVector struct {
+	Name     string               `json:"name"`
+	Block    *ledger.AccountBlock `json:"block"`
+	Encoding string               `json:"encoding"`
+	Hash     types.Hash           `json:"hash"`
+}
+
+type SnapshotBlockVector struct {
+	Name     string                `json:"name"`
+	Block    *ledger.SnapshotBlock `json:"block"`
+	Encoding string                `json:"encoding"`
+	Hash     types.Hash            `json:"hash"`
+}
+
+type TokenVector struct {
+	Name     string            `json:"name"`
+	TokenId  types.TokenTypeId `json:"tokenId"`
+	Token    *types.TokenInfo  `json:"token"`
+	Encoding string            `json:"encoding"`
+}
+
+// MainNetForkPoints returns the fork points of the main net, the snapshot block hash differs across them.
+func MainNetForkPoints() *config.ForkPoints {
+	return &config.ForkPoints{
+		Smart: &config.ForkPoint{Height: 5788912},
+		Mint:  &config.ForkPoint{Height: 9453262},
+	}
+}
+
+func newKey(seed byte) (ed25519.PublicKey, ed25519.PrivateKey) {
+	var d [32]byte
+	for i := range d {
+		d[i] = seed
+	}
+	pub, priv, _ := ed25519.GenerateKeyFromD(d)
+	return pub, priv
+}
+
+func newTime(offset int64) *time.Time {
+	t := time.Unix(1546300800+offset, 0)
+	return &t
+}
+
+// Generate builds the vectors with the given fork points, both Smart and Mint must be set.
+// fork.SetForkPoints must have been called with the same fork points beforehand.
+func Generate(forkPoints *config.ForkPoints) (*Vectors, error) {
+	v := &Vectors{
+		Version:    Version,
+		ForkPoints: forkPoints,
+	}
+
+	pubA, privA := newKey(1)
+	pubB, privB := newKey(2)
+	addrA := types.PubkeyToAddress(pubA)
+	addrB := types.PubkeyToAddress(pubB)
+	contractAddr := types.CreateContractAddress(addrA.Bytes(), []byte{1})
+	snapshotHash := types.DataHash([]byte("snapshot"))
+	logHash := types.DataHash([]byte("vm log list"))
+
+	send := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         1,
+		AccountAddress: addrA,
+		PublicKey:      pubA,
+		ToAddress:      addrB,
+		Amount:         big.NewInt(1e18),
+		TokenId:        ledger.ViteTokenId,
+		Fee:            big.NewInt(0),
+		SnapshotHash:   snapshotHash,
+		Data:           []byte("hello vite"),
+		Timestamp:      newTime(0),
+	}
+	receive := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		Height:         1,
+		AccountAddress: addrB,
+		PublicKey:      pubB,
+		Fee:            big.NewInt(0),
+		SnapshotHash:   snapshotHash,
+		Timestamp:      newTime(1),
+	}
+	withPow := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         2,
+		AccountAddress: addrA,
+		PublicKey:      pubA,
+		ToAddress:      addrB,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Fee:            big.NewInt(0),
+		SnapshotHash:   snapshotHash,
+		Timestamp:      newTime(2),
+		Difficulty:     big.NewInt(67108863),
+		Nonce:          []byte{1, 2, 3, 4, 5, 6, 7, 8},
+	}
+	create := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCreate,
+		Height:         3,
+		AccountAddress: addrA,
+		PublicKey:      pubA,
+		ToAddress:      contractAddr,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Fee:            new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18)),
+		SnapshotHash:   snapshotHash,
+		Data:           []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0x60, 0x80},
+		Timestamp:      newTime(3),
+	}
+	contractReceive := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		Height:         1,
+		AccountAddress: contractAddr,
+		Fee:            big.NewInt(0),
+		SnapshotHash:   snapshotHash,
+		Timestamp:      newTime(4),
+		LogHash:        &logHash,
+	}
+
+	signAccountBlock(send, privA, nil)
+	signAccountBlock(withPow, privA, send)
+	signAccountBlock(create, privA, withPow)
+	receive.FromBlockHash = send.Hash
+	signAccountBlock(receive, privB, nil)
+	contractReceive.FromBlockHash = create.Hash
+	signAccountBlock(contractReceive, nil, nil)
+
+	for _, item := range []struct {
+		name  string
+		block *ledger.AccountBlock
+	}{
+		{"send call", send},
+		{"receive", receive},
+		{"send call with pow", withPow},
+		{"send create", create},
+		{"contract receive with logs", contractReceive},
+	} {
+		buf, err := item.block.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		v.AccountBlocks = append(v.AccountBlocks, &AccountBlockVector{
+			Name:     item.name,
+			Block:    item.block,
+			Encoding: hex.EncodeToString(buf),
+			Hash:     item.block.Hash,
+		})
+	}
+
+	// one snapshot block right before and one at each fork point, the hash source changes at each of them
+	pubS, privS := newKey(3)
+	heights := []snapshotHeight{
+		{"genesis", 1},
+		{"before smart fork", forkPoints.Smart.Height - 1},
+		{"smart fork", forkPoints.Smart.Height},
+		{"mint fork", forkPoints.Mint.Height},
+	}
+	for i, item := range heights {
+		sb := &ledger.SnapshotBlock{
+			PrevHash:  types.DataHash([]byte{byte(i)}),
+			Height:    item.height,
+			PublicKey: pubS,
+			Timestamp: newTime(int64(item.height)),
+			StateHash: types.DataHash([]byte("state")),
+			SnapshotContent: ledger.SnapshotContent{
+				addrA:        {Hash: create.Hash, Height: create.Height},
+				addrB:        {Hash: receive.Hash, Height: receive.Height},
+				contractAddr: {Hash: contractReceive.Hash, Height: contractReceive.Height},
+			},
+		}
+		sb.Hash = sb.ComputeHash()
+		sb.Signature = ed25519.Sign(privS, sb.Hash.Bytes())
+
+		buf, err := sb.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		v.SnapshotBlocks = append(v.SnapshotBlocks, &SnapshotBlockVector{
+			Name:     item.name,
+			Block:    sb,
+			Encoding: hex.EncodeToString(buf),
+			Hash:     sb.Hash,
+		})
+	}
+
+	token := &types.TokenInfo{
+		TokenName:      "Test Token",
+		TokenSymbol:    "TEST",
+		TotalSupply:    new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18)),
+		Decimals:       18,
+		Owner:          addrA,
+		PledgeAmount:   big.NewInt(0),
+		PledgeAddr:     addrA,
+		WithdrawHeight: 0,
+		MaxSupply:      new(big.Int).Mul(big.NewInt(2e9), big.NewInt(1e18)),
+		OwnerBurnOnly:  true,
+		IsReIssuable:   true,
+	}
+	buf, err := packTokenInfo(token)
+	if err != nil {
+		return nil, err
+	}
+	v.Tokens = append(v.Tokens, &TokenVector{
+		Name:     "reissuable token",
+		TokenId:  abi.NewTokenId(addrA, send.Height, send.PrevHash, send.SnapshotHash),
+		Token:    token,
+		Encoding: hex.EncodeToString(buf),
+	})
+
+	return v, nil
+}
+
+type snapshotHeight struct {
+	name   string
+	height uint64
+}
+
+func signAccountBlock(block *ledger.AccountBlock, priv ed25519.PrivateKey, prev *ledger.AccountBlock) {
+	if prev != nil {
+		block.PrevHash = prev.Hash
+	}
+	block.Hash = block.ComputeHash()
+	if priv != nil {
+		block.Signature = ed25519.Sign(priv, block.Hash.Bytes())
+	}
+}
+
+func packTokenInfo(token *types.TokenInfo) ([]byte, error) {
+	return abi.ABIMintage.PackVariable(abi.VariableNameTokenInfo,
+		token.TokenName, token.TokenSymbol, token.TotalSupply, token.Decimals, token.Owner,
+		token.PledgeAmount, token.WithdrawHeight, token.PledgeAddr, token.IsReIssuable, token.MaxSupply, token.OwnerBurnOnly)
+}
+
+// Verify checks every vector against this implementation: the encoding must decode to a block
+// of the expected hash, and the block must encode to the expected encoding. fork.SetForkPoints
+// must have been called with v.ForkPoints beforehand.
+func Verify(v *Vectors) []error {
+	var errs []error
+	fail := func(kind, name string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s %q: %s", kind, name, fmt.Sprintf(format, args...)))
+	}
+
+	for _, vector := range v.AccountBlocks {
+		if vector.Block == nil {
+			fail("account block", vector.Name, "block is missing")
+			continue
+		}
+		buf, err := hex.DecodeString(vector.Encoding)
+		if err != nil {
+			fail("account block", vector.Name, "invalid encoding, %v", err)
+			continue
+		}
+		decoded := &ledger.AccountBlock{}
+		if err := decoded.Deserialize(buf); err != nil {
+			fail("account block", vector.Name, "deserialize failed, %v", err)
+			continue
+		}
+		if hash := decoded.ComputeHash(); hash != vector.Hash {
+			fail("account block", vector.Name, "hash of decoded block is %s, expected %s", hash, vector.Hash)
+		}
+		if hash := vector.Block.ComputeHash(); hash != vector.Hash {
+			fail("account block", vector.Name, "hash of block is %s, expected %s", hash, vector.Hash)
+		}
+		if encoded, err := vector.Block.Serialize(); err != nil || !bytes.Equal(encoded, buf) {
+			fail("account block", vector.Name, "encoding of block is %x, expected %s", encoded, vector.Encoding)
+		}
+		if len(vector.Block.Signature) > 0 && !vector.Block.VerifySignature() {
+			fail("account block", vector.Name, "invalid signature")
+		}
+	}
+
+	for _, vector := range v.SnapshotBlocks {
+		if vector.Block == nil {
+			fail("snapshot block", vector.Name, "block is missing")
+			continue
+		}
+		buf, err := hex.DecodeString(vector.Encoding)
+		if err != nil {
+			fail("snapshot block", vector.Name, "invalid encoding, %v", err)
+			continue
+		}
+		decoded := &ledger.SnapshotBlock{}
+		if err := decoded.Deserialize(buf); err != nil {
+			fail("snapshot block", vector.Name, "deserialize failed, %v", err)
+			continue
+		}
+		if hash := decoded.ComputeHash(); hash != vector.Hash {
+			fail("snapshot block", vector.Name, "hash of decoded block is %s, expected %s", hash, vector.Hash)
+		}
+		if hash := vector.Block.ComputeHash(); hash != vector.Hash {
+			fail("snapshot block", vector.Name, "hash of block is %s, expected %s", hash, vector.Hash)
+		}
+		if encoded, err := vector.Block.Serialize(); err != nil || !bytes.Equal(encoded, buf) {
+			fail("snapshot block", vector.Name, "encoding of block is %x, expected %s", encoded, vector.Encoding)
+		}
+		if !vector.Block.VerifySignature() {
+			fail("snapshot block", vector.Name, "invalid signature")
+		}
+	}
+
+	for _, vector := range v.Tokens {
+		if vector.Token == nil {
+			fail("token", vector.Name, "token is missing")
+			continue
+		}
+		buf, err := hex.DecodeString(vector.Encoding)
+		if err != nil {
+			fail("token", vector.Name, "invalid encoding, %v", err)
+			continue
+		}
+		if _, err := abi.ParseTokenInfo(buf); err != nil {
+			fail("token", vector.Name, "decode failed, %v", err)
+		}
+		if encoded, err := packTokenInfo(vector.Token); err != nil || !bytes.Equal(encoded, buf) {
+			fail("token", vector.Name, "encoding of token is %x, expected %s", encoded, vector.Encoding)
+		}
+	}
+	return errs
+}
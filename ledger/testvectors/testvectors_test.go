@@ -0,0 +1,40 @@
+package testvectors
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/fork"
+)
+
+func TestGenerateAndVerify(t *testing.T) {
+	forkPoints := MainNetForkPoints()
+	fork.SetForkPoints(forkPoints)
+
+	v, err := Generate(forkPoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &Vectors{}
+	if err := json.Unmarshal(buf, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if errs := Verify(decoded); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	again, _ := Generate(forkPoints)
+	if againBuf, _ := json.Marshal(again); string(againBuf) != string(buf) {
+		t.Error("generated vectors should be deterministic")
+	}
+
+	decoded.AccountBlocks[0].Block.Height++
+	decoded.SnapshotBlocks[0].Encoding = decoded.SnapshotBlocks[1].Encoding
+	if errs := Verify(decoded); len(errs) < 2 {
+		t.Errorf("tampered vectors should fail, got %v", errs)
+	}
+}
@@ -36,6 +36,8 @@ type Config struct {
 	IsEnable         bool
 	IsInfluxDBEnable bool
 	InfluxDBInfo     *InfluxDBConfig
+	// PrometheusAddr is the listen address of the /metrics endpoint scraped by Prometheus, empty means disabled
+	PrometheusAddr string
 }
 
 func InitMetrics(metricFlag, influxDBFlag bool) {
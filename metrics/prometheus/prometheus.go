@@ -0,0 +1,84 @@
+// Package prometheus exports the metrics of a registry in the text format of Prometheus.
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/vitelabs/go-vite/metrics"
+)
+
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99}
+
+// Handler serves the metrics of reg to Prometheus scrapes.
+func Handler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(Collect(reg))
+	})
+}
+
+// Collect renders the metrics of reg, a meter is exported as a counter of its count and gauges
+// of its rates, a histogram or timer as a summary. Other metric types are skipped.
+func Collect(reg metrics.Registry) []byte {
+	all := make(map[string]interface{})
+	reg.Each(func(name string, i interface{}) {
+		all[name] = i
+	})
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	for _, name := range names {
+		n := metricName(name)
+		switch metric := all[name].(type) {
+		case metrics.Counter:
+			writeValue(buf, n, "counter", float64(metric.Count()))
+		case metrics.Gauge:
+			writeValue(buf, n, "gauge", float64(metric.Snapshot().Value()))
+		case metrics.GaugeFloat64:
+			writeValue(buf, n, "gauge", metric.Snapshot().Value())
+		case metrics.Meter:
+			ms := metric.Snapshot()
+			writeValue(buf, n+"_total", "counter", float64(ms.Count()))
+			writeValue(buf, n+"_rate1", "gauge", ms.Rate1())
+			writeValue(buf, n+"_rate5", "gauge", ms.Rate5())
+			writeValue(buf, n+"_rate15", "gauge", ms.Rate15())
+		case metrics.Histogram:
+			ms := metric.Snapshot()
+			writeSummary(buf, n, ms.Percentiles(quantiles), ms.Sum(), ms.Count())
+		case metrics.Timer:
+			ms := metric.Snapshot()
+			writeSummary(buf, n, ms.Percentiles(quantiles), ms.Sum(), ms.Count())
+		}
+	}
+	return buf.Bytes()
+}
+
+// metricName replaces the characters not allowed in Prometheus metric names with underscores.
+func metricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func writeValue(buf *bytes.Buffer, name, typ string, value float64) {
+	fmt.Fprintf(buf, "# TYPE %s %s\n%s %v\n", name, typ, name, value)
+}
+
+func writeSummary(buf *bytes.Buffer, name string, ps []float64, sum, count int64) {
+	fmt.Fprintf(buf, "# TYPE %s summary\n", name)
+	for i, q := range quantiles {
+		fmt.Fprintf(buf, "%s{quantile=\"%v\"} %v\n", name, q, ps[i])
+	}
+	fmt.Fprintf(buf, "%s_sum %d\n%s_count %d\n", name, sum, name, count)
+}
@@ -0,0 +1,30 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vitelabs/go-vite/metrics"
+)
+
+func TestCollect(t *testing.T) {
+	metrics.MetricsEnabled = true
+	defer func() { metrics.MetricsEnabled = false }()
+
+	reg := metrics.NewRegistry()
+	metrics.NewRegisteredCounter("chain/blocks", reg).Inc(3)
+	metrics.NewRegisteredGaugeFloat64("stats.tps", reg).Update(1.5)
+	metrics.NewRegisteredHistogram("rpc-latency", reg, metrics.NewUniformSample(10)).Update(4)
+
+	out := string(Collect(reg))
+	for _, want := range []string{
+		"# TYPE chain_blocks counter\nchain_blocks 3\n",
+		"# TYPE stats_tps gauge\nstats_tps 1.5\n",
+		"rpc_latency{quantile=\"0.5\"} 4\n",
+		"rpc_latency_sum 4\nrpc_latency_count 1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in\n%s", want, out)
+		}
+	}
+}
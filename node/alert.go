@@ -0,0 +1,47 @@
+package node
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitelabs/go-vite/alert"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/rpcapi/api/filters"
+	"github.com/vitelabs/go-vite/vite"
+)
+
+// alertSource provides the values of the alerting rules from the vite server.
+type alertSource struct {
+	chain.Chain
+	vite *vite.Vite
+}
+
+func (s *alertSource) PeerCount() int {
+	return s.vite.Net().Info().PeerCount
+}
+
+func (s *alertSource) FilterBacklog() int {
+	return filters.Backlog()
+}
+
+func (node *Node) startAlert() {
+	if !node.config.AlertEnabled {
+		return
+	}
+	source := &alertSource{Chain: node.viteServer.Chain(), vite: node.viteServer}
+	engine := alert.NewEngine(source, node.config.makeAlertNotifiers(), time.Duration(node.config.AlertInterval)*time.Second)
+	for _, rule := range node.config.AlertRules {
+		if err := engine.AddRule(rule); err != nil {
+			log.Error(fmt.Sprintf("add alert rule %s error: %v", rule.Name, err))
+		}
+	}
+	engine.Start()
+	node.alertEngine = engine
+}
+
+func (node *Node) stopAlert() {
+	if node.alertEngine != nil {
+		node.alertEngine.Stop()
+		node.alertEngine = nil
+	}
+}
@@ -2,7 +2,9 @@ package node
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"github.com/vitelabs/go-vite/alert"
 	"github.com/vitelabs/go-vite/metrics"
 	"os"
 	"path/filepath"
@@ -15,6 +17,7 @@ import (
 
 	"encoding/json"
 	"math/big"
+	"net"
 
 	"github.com/vitelabs/go-vite/common"
 	"github.com/vitelabs/go-vite/common/types"
@@ -24,6 +27,9 @@ import (
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/p2p"
 	"github.com/vitelabs/go-vite/p2p/network"
+	"github.com/vitelabs/go-vite/pow"
+	"github.com/vitelabs/go-vite/pow/remote"
+	"github.com/vitelabs/go-vite/rpc"
 	"github.com/vitelabs/go-vite/wallet"
 )
 
@@ -40,6 +46,13 @@ type Config struct {
 	LedgerGcRetain       uint64 `json:"LedgerGcRetain"`
 	LedgerGc             *bool  `json:"LedgerGc"`
 	OpenFilterTokenIndex *bool  `json:"OpenFilterTokenIndex"`
+	VmLogRetainDays      uint64 `json:"VmLogRetainDays"`
+	CompressBlockBody    bool   `json:"CompressBlockBody"`
+	BlockFreezeDays      uint64 `json:"BlockFreezeDays"`
+	SplitKeyspaces       bool   `json:"SplitKeyspaces"`
+	// LedgerGcRetainDays and LedgerGcCheckpointInterval configure the states kept by the ledger gc
+	LedgerGcRetainDays         uint64 `json:"LedgerGcRetainDays"`
+	LedgerGcCheckpointInterval uint64 `json:"LedgerGcCheckpointInterval"`
 
 	// genesis
 	GenesisFile string `json:"GenesisFile"`
@@ -57,12 +70,24 @@ type Config struct {
 	NetID                uint     `json:"NetID"`
 	Discovery            bool     `json:"Discovery"`
 
+	// ListenHost is the host p2p listens on, "0.0.0.0" by default. If ListenHost6 is set, IPv6 is listened
+	// on it separately and ListenHost must be IPv4. IPPolicy is one of preferIPv4, preferIPv6, ipv4 and ipv6.
+	ListenHost  string `json:"ListenHost"`
+	ListenHost6 string `json:"ListenHost6"`
+	IPPolicy    string `json:"IPPolicy"`
+
+	// Proxy is the SOCKS5 proxy, e.g. tor at "127.0.0.1:9050", all the peers are dialed through, Discovery must
+	// be disabled with it. OnionAddr is the onion service address of the node published to the peers.
+	Proxy     string `json:"Proxy"`
+	OnionAddr string `json:"OnionAddr"`
+
 	//producer
 	EntropyStorePath     string `json:"EntropyStorePath"`
 	EntropyStorePassword string `json:"EntropyStorePassword"`
 	CoinBase             string `json:"CoinBase"`
 	MinerEnabled         bool   `json:"Miner"`
 	MinerInterval        int    `json:"MinerInterval"`
+	DevMining            bool   `json:"DevMining"`
 
 	//rpc
 	RPCEnabled bool `json:"RPCEnabled"`
@@ -85,6 +110,55 @@ type Config struct {
 	TestTokenTti        string   `json:"TestTokenTti"`
 
 	PowServerUrl string `json:"PowServerUrl”`
+	// PowProvider is "local" to calculate the PoW nonces of the generator with the CPU, or "remote" to offload them
+	// to the pow server at PowServerUrl, which takes PowServerWorkers requests at once in PowServerTimeout seconds
+	// and queues PowServerQueueSize more
+	PowProvider        string `json:"PowProvider"`
+	PowServerTimeout   int    `json:"PowServerTimeout"`
+	PowServerWorkers   int    `json:"PowServerWorkers"`
+	PowServerQueueSize int    `json:"PowServerQueueSize"`
+
+	//rpc qos
+	RpcPriorityApiKeys   []string `json:"RpcPriorityApiKeys"`
+	RpcPriorityWorkers   int      `json:"RpcPriorityWorkers"`
+	RpcPriorityQueueSize int      `json:"RpcPriorityQueueSize"`
+	RpcPublicWorkers     int      `json:"RpcPublicWorkers"`
+	RpcPublicQueueSize   int      `json:"RpcPublicQueueSize"`
+
+	//subscribe
+	SubscribeEnabled bool `json:"SubscribeEnabled"`
+	// seconds a polling filter is kept without being queried, and between the sweeps of the expired ones
+	SubscribeFilterDeadline int `json:"SubscribeFilterDeadline"`
+	SubscribeSweepInterval  int `json:"SubscribeSweepInterval"`
+	// max messages a polling filter buffers between polls, and "dropOldest" or "error" when it's full
+	SubscribeFilterMaxBuffered    int    `json:"SubscribeFilterMaxBuffered"`
+	SubscribeFilterOverflowPolicy string `json:"SubscribeFilterOverflowPolicy"`
+	// allow log subscriptions with an empty addrRange, which match the logs of all the addresses
+	SubscribeAnyAddrLogs bool `json:"SubscribeAnyAddrLogs"`
+	// max concurrent subscriptions of a websocket connection and max polling filters of the node, 0 means unlimited
+	SubscribeMaxConnSubscriptions int `json:"SubscribeMaxConnSubscriptions"`
+	SubscribeMaxFilters           int `json:"SubscribeMaxFilters"`
+
+	//alert
+	AlertEnabled bool `json:"AlertEnabled"`
+	// seconds between the evaluations of the rules
+	AlertInterval     int           `json:"AlertInterval"`
+	AlertRules        []*alert.Rule `json:"AlertRules"`
+	AlertWebhooks     []string      `json:"AlertWebhooks"`
+	AlertSmtpAddr     string        `json:"AlertSmtpAddr"`
+	AlertSmtpUsername string        `json:"AlertSmtpUsername"`
+	AlertSmtpPassword string        `json:"AlertSmtpPassword"`
+	AlertEmailFrom    string        `json:"AlertEmailFrom"`
+	AlertEmailTo      []string      `json:"AlertEmailTo"`
+
+	//stats
+	StatsEnabled bool `json:"StatsEnabled"`
+	// seconds the tps and quota rates are averaged over
+	StatsWindow int `json:"StatsWindow"`
+
+	//plugin
+	// paths of the plugin programs started with the node
+	Plugins []string `json:"Plugins"`
 
 	//Log level
 	LogLevel    string `json:"LogLevel"`
@@ -103,6 +177,13 @@ type Config struct {
 	TopologyReportInterval int      `json:"TopologyReportInterval"`
 	TopoEnabled            bool     `json:"TopoEnabled"`
 	DashboardTargetURL     string
+	// unix time until which the legacy versions of the vite protocol are served, 0 means no deadline
+	LegacyProtocolDeadline int64 `json:"LegacyProtocolDeadline"`
+	// metadata of the node signed and gossiped to the peers, the services are archive and light-server
+	NodeOperator string   `json:"NodeOperator"`
+	NodeContact  string   `json:"NodeContact"`
+	NodeRegion   string   `json:"NodeRegion"`
+	NodeServices []string `json:"NodeServices"`
 
 	// reward
 	RewardAddr string `json:"RewardAddr"`
@@ -115,6 +196,7 @@ type Config struct {
 	InfluxDBUsername *string `json:"InfluxDBUsername"`
 	InfluxDBPassword *string `json:"InfluxDBPassword"`
 	InfluxDBHostTag  *string `json:"InfluxDBHostTag"`
+	PrometheusAddr   string  `json:"PrometheusAddr"`
 }
 
 func (c *Config) makeWalletConfig() *wallet.Config {
@@ -138,8 +220,13 @@ func (c *Config) makeNetConfig() *config.Net {
 	fileAddress := "0.0.0.0:" + strconv.Itoa(c.FilePort)
 
 	return &config.Net{
-		Single:      c.Single,
-		FileAddress: fileAddress,
+		Single:                 c.Single,
+		FileAddress:            fileAddress,
+		LegacyProtocolDeadline: c.LegacyProtocolDeadline,
+		NodeOperator:           c.NodeOperator,
+		NodeContact:            c.NodeContact,
+		NodeRegion:             c.NodeRegion,
+		NodeServices:           c.NodeServices,
 	}
 }
 
@@ -178,16 +265,35 @@ func (c *Config) makeMetricsConfig() *metrics.Config {
 				HostTag:  *c.InfluxDBHostTag,
 			}
 		}
+		mc.PrometheusAddr = c.PrometheusAddr
 	}
 
 	return mc
 }
 
+func (c *Config) makeAlertNotifiers() []alert.Notifier {
+	var notifiers []alert.Notifier
+	for _, url := range c.AlertWebhooks {
+		notifiers = append(notifiers, alert.NewWebhookNotifier(url))
+	}
+	if c.AlertSmtpAddr != "" && len(c.AlertEmailTo) > 0 {
+		notifiers = append(notifiers, alert.NewEmailNotifier(alert.EmailConfig{
+			SmtpAddr: c.AlertSmtpAddr,
+			Username: c.AlertSmtpUsername,
+			Password: c.AlertSmtpPassword,
+			From:     c.AlertEmailFrom,
+			To:       c.AlertEmailTo,
+		}))
+	}
+	return notifiers
+}
+
 func (c *Config) makeMinerConfig() *config.Producer {
 	return &config.Producer{
 		Producer:         c.MinerEnabled,
 		Coinbase:         c.CoinBase,
 		EntropyStorePath: c.EntropyStorePath,
+		DevMining:        c.DevMining,
 	}
 }
 
@@ -196,7 +302,17 @@ func (c *Config) makeP2PConfig() *p2p.Config {
 		c.Port = 8483
 	}
 
-	addr := "0.0.0.0:" + strconv.Itoa(c.Port)
+	host := c.ListenHost
+	if host == "" {
+		host = "0.0.0.0"
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(c.Port))
+
+	var addr6 string
+	if c.ListenHost6 != "" {
+		addr6 = net.JoinHostPort(c.ListenHost6, strconv.Itoa(c.Port))
+	}
+
 	return &p2p.Config{
 		Name:            c.Identity,
 		NetID:           network.ID(c.NetID),
@@ -204,11 +320,15 @@ func (c *Config) makeP2PConfig() *p2p.Config {
 		MaxPendingPeers: c.MaxPendingPeers,
 		MaxInboundRatio: c.MaxPassivePeersRatio,
 		Addr:            addr,
+		Addr6:           addr6,
+		IPPolicy:        c.IPPolicy,
 		DataDir:         filepath.Join(c.DataDir, p2p.Dirname),
 		PeerKey:         c.GetPrivateKey(),
 		BootNodes:       c.BootNodes,
 		StaticNodes:     c.StaticNodes,
 		Discovery:       c.Discovery,
+		Proxy:           c.Proxy,
+		OnionAddr:       c.OnionAddr,
 	}
 }
 
@@ -339,6 +459,14 @@ func (c *Config) makeGenesisConfig() *config.Genesis {
 	// set fork points
 	genesisConfig.ForkPoints = c.makeForkPointsConfig(genesisConfig)
 
+	if genesisConfig.PledgeLockTiers == nil {
+		genesisConfig.PledgeLockTiers = []*config.PledgeLockTier{
+			{LockHeight: 3600 * 24 * 90, Multiplier: 120},
+			{LockHeight: 3600 * 24 * 180, Multiplier: 150},
+			{LockHeight: 3600 * 24 * 360, Multiplier: 200},
+		}
+	}
+
 	return genesisConfig
 }
 
@@ -383,6 +511,47 @@ func (c *Config) makeChainConfig() *config.Chain {
 		LedgerGcRetain:       c.LedgerGcRetain,
 		LedgerGc:             ledgerGc,
 		OpenFilterTokenIndex: openFilterTokenIndex,
+		VmLogRetainDays:      c.VmLogRetainDays,
+		CompressBlockBody:    c.CompressBlockBody,
+		BlockFreezeDays:      c.BlockFreezeDays,
+		SplitKeyspaces:       c.SplitKeyspaces,
+
+		LedgerGcRetainDays:         c.LedgerGcRetainDays,
+		LedgerGcCheckpointInterval: c.LedgerGcCheckpointInterval,
+	}
+}
+
+// RpcQos returns the qos config of the http and websocket endpoints, nil if no lane is limited.
+func (c *Config) RpcQos() *rpc.QosConfig {
+	if c.RpcPriorityWorkers <= 0 && c.RpcPublicWorkers <= 0 {
+		return nil
+	}
+	return &rpc.QosConfig{
+		PriorityApiKeys:   c.RpcPriorityApiKeys,
+		PriorityWorkers:   c.RpcPriorityWorkers,
+		PriorityQueueSize: c.RpcPriorityQueueSize,
+		PublicWorkers:     c.RpcPublicWorkers,
+		PublicQueueSize:   c.RpcPublicQueueSize,
+	}
+}
+
+// makePowProvider returns the provider of the PoW nonces of the generator, the CPU of the node by default.
+func (c *Config) makePowProvider() (pow.Provider, error) {
+	switch c.PowProvider {
+	case "", "local":
+		return pow.LocalProvider{}, nil
+	case "remote":
+		if c.PowServerUrl == "" {
+			return nil, errors.New("PowServerUrl is required by the remote pow provider")
+		}
+		return remote.NewProvider(remote.ProviderConfig{
+			Url:       c.PowServerUrl,
+			Timeout:   time.Duration(c.PowServerTimeout) * time.Second,
+			Workers:   c.PowServerWorkers,
+			QueueSize: c.PowServerQueueSize,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown pow provider %v", c.PowProvider)
 	}
 }
 
@@ -3,10 +3,13 @@ package node
 import (
 	"encoding/hex"
 	"fmt"
+	"github.com/vitelabs/go-vite/alert"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/metrics"
 	"github.com/vitelabs/go-vite/metrics/influxdb"
+	"github.com/vitelabs/go-vite/metrics/prometheus"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -21,10 +24,13 @@ import (
 	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/plugin"
 	"github.com/vitelabs/go-vite/pow"
 	"github.com/vitelabs/go-vite/pow/remote"
 	"github.com/vitelabs/go-vite/rpc"
 	"github.com/vitelabs/go-vite/rpcapi"
+	"github.com/vitelabs/go-vite/rpcapi/api/filters"
+	"github.com/vitelabs/go-vite/stats"
 	"github.com/vitelabs/go-vite/vite"
 	"github.com/vitelabs/go-vite/wallet"
 )
@@ -52,6 +58,16 @@ type Node struct {
 	// metrics
 	metricsConfig *metrics.Config
 	ifxReporter   *influxdb.Reporter
+	promServer    *http.Server
+
+	// stats
+	statsAggregator *stats.Aggregator
+
+	// alert
+	alertEngine *alert.Engine
+
+	// plugin
+	pluginHost *plugin.Host
 
 	// List of APIs currently provided by the node
 	rpcAPIs          []rpc.API
@@ -151,6 +167,9 @@ func (node *Node) Prepare() error {
 	}
 
 	//Initialize the vite server
+	if node.config.DevMining && node.config.NetID < 3 {
+		return errors.New("dev mining is only allowed in a dev network")
+	}
 	node.viteServer, err = vite.New(node.viteConfig, node.walletManager)
 	if err != nil {
 		log.Error(fmt.Sprintf("Vite new error: %v", err))
@@ -163,6 +182,11 @@ func (node *Node) Prepare() error {
 	//init rpc_PowServerUrl
 	remote.InitRawUrl(node.Config().PowServerUrl)
 	pow.Init(node.Config().VMTestParamEnabled)
+	powProvider, err := node.Config().makePowProvider()
+	if err != nil {
+		return err
+	}
+	pow.SetProvider(powProvider)
 
 	// Start vite
 	if err := node.viteServer.Init(); err != nil {
@@ -341,6 +365,18 @@ func (node *Node) startMetrics() {
 			log.Info("start influxdb export")
 			node.ifxReporter.Start()
 		}
+
+		if metricsCfg.PrometheusAddr != "" {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", prometheus.Handler(metrics.DefaultRegistry))
+			node.promServer = &http.Server{Addr: metricsCfg.PrometheusAddr, Handler: mux}
+			log.Info("start prometheus export", "addr", metricsCfg.PrometheusAddr)
+			go func(srv *http.Server) {
+				if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+					log.Error(fmt.Sprintf("prometheus export err: %v", err))
+				}
+			}(node.promServer)
+		}
 	}
 }
 
@@ -349,6 +385,11 @@ func (node *Node) stopMetrics() {
 		log.Info("stop influxdb export")
 		node.ifxReporter.Stop()
 	}
+	if node.promServer != nil {
+		log.Info("stop prometheus export")
+		node.promServer.Close()
+		node.promServer = nil
+	}
 }
 
 func (node *Node) startVite() error {
@@ -360,6 +401,31 @@ func (node *Node) startRPC() error {
 	// Init rpc log
 	rpcapi.Init(node.config.DataDir, node.config.LogLevel, node.config.TestTokenHexPrivKey, node.config.TestTokenTti, node.config.NetID)
 
+	rpcapi.InitSubscribe(
+		filters.WithDeadline(time.Duration(node.config.SubscribeFilterDeadline)*time.Second),
+		filters.WithSweepInterval(time.Duration(node.config.SubscribeSweepInterval)*time.Second),
+		filters.WithMaxBuffered(node.config.SubscribeFilterMaxBuffered, filters.OverflowPolicy(node.config.SubscribeFilterOverflowPolicy)),
+		filters.WithAnyAddrLogs(node.config.SubscribeAnyAddrLogs),
+		filters.WithMaxSubscriptions(node.config.SubscribeMaxConnSubscriptions),
+		filters.WithMaxFilters(node.config.SubscribeMaxFilters))
+
+	// Start the event system before subscribe apis are exposed
+	if node.config.SubscribeEnabled {
+		filters.Es = filters.NewEventSystem(node.viteServer)
+		filters.Es.Start()
+	}
+
+	// Start the alert engine before alert apis are exposed
+	node.startAlert()
+	rpcapi.InitAlert(node.alertEngine)
+
+	// Start the stats aggregator before stats apis are exposed
+	node.startStats()
+	rpcapi.InitStats(node.statsAggregator)
+
+	// Start the plugins with the chain events
+	node.startPlugins()
+
 	// Start the various API endpoints, terminating all in case of errors
 	if err := node.startInProcess(node.GetInProcessApis()); err != nil {
 		return err
@@ -460,6 +526,13 @@ func (node *Node) stopRPC() error {
 	node.stopWS()
 	node.stopHTTP()
 	node.stopIPC()
+	if filters.Es != nil {
+		filters.Es.Stop()
+		filters.Es = nil
+	}
+	node.stopAlert()
+	node.stopStats()
+	node.stopPlugins()
 	return nil
 }
 
@@ -0,0 +1,41 @@
+package node
+
+import (
+	"errors"
+
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/plugin"
+	"github.com/vitelabs/go-vite/verifier"
+)
+
+func (node *Node) startPlugins() {
+	if len(node.config.Plugins) == 0 {
+		return
+	}
+	host := plugin.NewHost(node.viteServer.Chain(), node.submitPluginBlock, node.config.Plugins)
+	if err := host.Start(); err != nil {
+		log.Error("start plugins failed, error is " + err.Error())
+		return
+	}
+	node.pluginHost = host
+}
+
+func (node *Node) stopPlugins() {
+	if node.pluginHost != nil {
+		node.pluginHost.Stop()
+		node.pluginHost = nil
+	}
+}
+
+// submitPluginBlock verifies a block sent by a plugin and adds it to the pool, as tx_sendRawTx does.
+func (node *Node) submitPluginBlock(block *ledger.AccountBlock) error {
+	v := verifier.NewAccountVerifier(node.viteServer.Chain(), node.viteServer.Consensus())
+	blocks, err := v.VerifyforRPC(block)
+	if err != nil {
+		return err
+	}
+	if len(blocks) == 0 || blocks[0] == nil {
+		return errors.New("generator gen an empty block")
+	}
+	return node.viteServer.Pool().AddDirectAccountBlock(block.AccountAddress, blocks[0])
+}
@@ -15,7 +15,7 @@ func (node *Node) GetInProcessApis() []rpc.API {
 
 //Ipc apis
 func (node *Node) GetIpcApis() []rpc.API {
-	return rpcapi.GetApis(node.viteServer, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx")
+	return rpcapi.GetApis(node.viteServer, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx", "alert")
 }
 
 //Http apis
@@ -71,7 +71,7 @@ func (node *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, c
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, exposeAll)
+	listener, handler, err := rpc.StartHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, exposeAll, node.config.RpcQos())
 	if err != nil {
 		return err
 	}
@@ -104,7 +104,7 @@ func (node *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsO
 	if endpoint == "" {
 		return nil
 	}
-	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll)
+	listener, handler, err := rpc.StartWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, node.config.RpcQos())
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,23 @@
+package node
+
+import (
+	"time"
+
+	"github.com/vitelabs/go-vite/stats"
+)
+
+func (node *Node) startStats() {
+	if !node.config.StatsEnabled {
+		return
+	}
+	aggregator := stats.NewAggregator(node.viteServer.Chain(), time.Duration(node.config.StatsWindow)*time.Second)
+	aggregator.Start()
+	node.statsAggregator = aggregator
+}
+
+func (node *Node) stopStats() {
+	if node.statsAggregator != nil {
+		node.statsAggregator.Stop()
+		node.statsAggregator = nil
+	}
+}
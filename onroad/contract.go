@@ -6,6 +6,7 @@ import (
 	"sync"
 
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/math"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/log15"
@@ -18,10 +19,11 @@ type ContractWorker struct {
 
 	uBlocksPool *model.OnroadBlocksPool
 
-	gid                 types.Gid
-	address             types.Address
-	accEvent            producerevent.AccountStartEvent
-	currentSnapshotHash types.Hash
+	gid                   types.Gid
+	address               types.Address
+	accEvent              producerevent.AccountStartEvent
+	currentSnapshotHash   types.Hash
+	currentSnapshotHeight uint64
 
 	status      int
 	statusMutex sync.Mutex
@@ -75,8 +77,10 @@ func (w *ContractWorker) Start(accEvent producerevent.AccountStartEvent) {
 	w.accEvent = accEvent
 	if sb := w.manager.chain.GetLatestSnapshotBlock(); sb != nil {
 		w.currentSnapshotHash = sb.Hash
+		w.currentSnapshotHeight = sb.Height
 	} else {
 		w.currentSnapshotHash = w.accEvent.SnapshotHash
+		w.currentSnapshotHeight = w.accEvent.SnapshotHeight
 	}
 
 	w.log = slog.New("worker", "c", "addr", accEvent.Address, "gid", accEvent.Gid)
@@ -297,7 +301,7 @@ func (w ContractWorker) Status() int {
 }
 
 func (w *ContractWorker) GetPledgeQuota(addr types.Address) uint64 {
-	if types.IsPrecompiledContractWithoutQuotaAddress(addr) {
+	if fork.IsPrecompiledContractWithoutQuotaAddress(addr, w.currentSnapshotHeight) {
 		return math.MaxUint64
 	}
 	quota, err := w.manager.Chain().GetPledgeQuota(w.currentSnapshotHash, addr)
@@ -312,7 +316,7 @@ func (w *ContractWorker) GetPledgeQuotas(beneficialList []types.Address) map[typ
 	if w.gid == types.DELEGATE_GID {
 		commonContractAddressList := make([]types.Address, 0, len(beneficialList))
 		for _, addr := range beneficialList {
-			if types.IsPrecompiledContractWithoutQuotaAddress(addr) {
+			if fork.IsPrecompiledContractWithoutQuotaAddress(addr, w.currentSnapshotHeight) {
 				quotas[addr] = math.MaxUint64
 			} else {
 				commonContractAddressList = append(commonContractAddressList, addr)
@@ -4,6 +4,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
@@ -27,6 +28,13 @@ func (access *UAccess) Init(chain chain.Chain) {
 	access.store = NewOnroadSet(chain)
 }
 
+func (access *UAccess) latestSnapshotHeight() uint64 {
+	if latestSnapshotBlock := access.Chain.GetLatestSnapshotBlock(); latestSnapshotBlock != nil {
+		return latestSnapshotBlock.Height
+	}
+	return 0
+}
+
 func (access *UAccess) GetContractAddrListByGid(gid *types.Gid) ([]types.Address, error) {
 	addrList, err := access.store.GetContractAddrList(gid)
 	if err != nil {
@@ -34,7 +42,7 @@ func (access *UAccess) GetContractAddrListByGid(gid *types.Gid) ([]types.Address
 		return nil, err
 	}
 	if *gid == types.DELEGATE_GID {
-		addrList = append(addrList, types.PrecompiledContractAddressList...)
+		addrList = append(addrList, fork.GetPrecompiledContractAddressList(access.latestSnapshotHeight())...)
 	}
 	return addrList, nil
 }
@@ -43,7 +51,7 @@ func (access *UAccess) WriteContractAddrToGid(batch *leveldb.Batch, gid types.Gi
 	var addrList []types.Address
 	var err error
 
-	if gid == types.DELEGATE_GID && types.IsPrecompiledContractAddress(address) {
+	if gid == types.DELEGATE_GID && fork.IsPrecompiledContractAddress(address, access.latestSnapshotHeight()) {
 		return nil
 	}
 
@@ -1,10 +1,14 @@
 package p2p
 
 import (
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/socks"
 	"github.com/vitelabs/go-vite/crypto/ed25519"
 	"github.com/vitelabs/go-vite/p2p/discovery"
 	"github.com/vitelabs/go-vite/p2p/network"
@@ -113,3 +117,36 @@ func parseNodes(urls []string) (nodes []*discovery.Node) {
 
 	return nodes[:i]
 }
+
+// onionNode is a static node at an onion address, which can`t be represented by discovery.Node
+type onionNode struct {
+	ID   discovery.NodeID
+	Addr string
+}
+
+// parseOnionNodes parses the urls like vnode://<hex node id>@<onion address>:<port>, the others are ignored.
+func parseOnionNodes(urls []string) (nodes []*onionNode) {
+	for _, nodeURL := range urls {
+		u, err := url.Parse(nodeURL)
+		if err != nil || u.Scheme != discovery.NodeURLScheme || u.User == nil {
+			continue
+		}
+
+		host, port, err := net.SplitHostPort(u.Host)
+		if err != nil || !socks.IsOnion(host) {
+			continue
+		}
+		if _, err = strconv.ParseUint(port, 10, 16); err != nil {
+			continue
+		}
+
+		id, err := discovery.HexStr2NodeID(u.User.String())
+		if err != nil {
+			continue
+		}
+
+		nodes = append(nodes, &onionNode{id, u.Host})
+	}
+
+	return
+}
@@ -21,6 +21,7 @@ var errIncompleteMsg = errors.New("send incomplete message")
 type agent struct {
 	self    *Node
 	conn    *net.UDPConn
+	conn6   *net.UDPConn // nil if IPv4 and IPv6 are read from conn
 	peerKey ed25519.PrivateKey
 	term    chan struct{}
 	handler func(*packet)
@@ -34,7 +35,16 @@ func (a *agent) start() {
 	a.pool.start()
 
 	a.wg.Add(1)
-	common.Go(a.readLoop)
+	common.Go(func() {
+		a.readLoop(a.conn)
+	})
+
+	if a.conn6 != nil {
+		a.wg.Add(1)
+		common.Go(func() {
+			a.readLoop(a.conn6)
+		})
+	}
 }
 
 func (a *agent) stop() {
@@ -45,6 +55,9 @@ func (a *agent) stop() {
 	if a.conn != nil {
 		a.conn.Close()
 	}
+	if a.conn6 != nil {
+		a.conn6.Close()
+	}
 
 	select {
 	case <-a.term:
@@ -140,9 +153,9 @@ func (a *agent) sendNeighbors(addr *net.UDPAddr, nodes []*Node) {
 	}
 }
 
-func (a *agent) readLoop() {
+func (a *agent) readLoop(conn *net.UDPConn) {
 	defer a.wg.Done()
-	defer a.conn.Close()
+	defer conn.Close()
 
 	buf := make([]byte, maxPacketLength)
 
@@ -156,7 +169,7 @@ func (a *agent) readLoop() {
 		default:
 		}
 
-		n, addr, err := a.conn.ReadFromUDP(buf)
+		n, addr, err := conn.ReadFromUDP(buf)
 
 		if err != nil {
 			if err, ok := err.(net.Error); ok && err.Temporary() {
@@ -217,7 +230,12 @@ func (a *agent) write(msg Message, addr *net.UDPAddr) (hash types.Hash, err erro
 		return
 	}
 
-	n, err := a.conn.WriteToUDP(data, addr)
+	conn := a.conn
+	if a.conn6 != nil && addr.IP.To4() == nil {
+		conn = a.conn6
+	}
+
+	n, err := conn.WriteToUDP(data, addr)
 
 	if err != nil {
 		a.log.Warn(fmt.Sprintf("write message %s to %s error: %v", msg, addr, err))
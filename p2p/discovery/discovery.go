@@ -41,8 +41,10 @@ type Config struct {
 	DBPath    string
 	BootNodes []*Node
 	Addr      string
+	Addr6     string // the IPv6 listen address if IPv4 and IPv6 are listened separately, Addr is IPv4 then
 	NetID     network.ID
 	Self      *Node
+	IPPolicy  IPPolicy
 }
 
 // Discovery is the interface to discovery other node
@@ -110,7 +112,7 @@ func (d *discovery) Delete(id NodeID) {
 func New(cfg *Config) Discovery {
 	d := &discovery{
 		Config:   cfg,
-		table:    newTable(cfg.Self.ID, cfg.NetID),
+		table:    newTable(cfg.Self.ID, cfg.NetID, cfg.IPPolicy),
 		pingList: unique_list.New(),
 		findList: unique_list.New(),
 		log:      log15.New("module", "p2p/discv"),
@@ -130,15 +132,19 @@ func New(cfg *Config) Discovery {
 func (d *discovery) Start() (err error) {
 	d.log.Info(fmt.Sprintf("discovery %s start", d.Self.ID))
 
-	udpAddr, err := net.ResolveUDPAddr("udp", d.Addr)
-	if err != nil {
-		return
-	}
-	conn, err := net.ListenUDP("udp", udpAddr)
-	if err != nil {
-		return
+	if d.Addr6 == "" {
+		if d.agent.conn, err = listenUDP("udp", d.Addr); err != nil {
+			return
+		}
+	} else {
+		if d.agent.conn, err = listenUDP("udp4", d.Addr); err != nil {
+			return
+		}
+		if d.agent.conn6, err = listenUDP("udp6", d.Addr6); err != nil {
+			d.agent.conn.Close()
+			return
+		}
 	}
-	d.agent.conn = conn
 
 	db, err := newDB(d.Config.DBPath, 3, d.Self.ID)
 	if err != nil {
@@ -162,6 +168,14 @@ func (d *discovery) Start() (err error) {
 	return
 }
 
+func listenUDP(network, addr string) (*net.UDPConn, error) {
+	udpAddr, err := net.ResolveUDPAddr(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return net.ListenUDP(network, udpAddr)
+}
+
 func (d *discovery) Stop() {
 	if d.term == nil {
 		return
@@ -335,7 +349,7 @@ Loop:
 func (d *discovery) handle(res *packet) {
 	node := &Node{
 		ID:  res.fromID,
-		IP:  res.from.IP,
+		IP:  CanonicalIP(res.from.IP),
 		UDP: uint16(res.from.Port),
 	}
 	if ping, ok := res.msg.(*Ping); ok {
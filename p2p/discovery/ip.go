@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"errors"
+	"net"
+)
+
+var errUnknownIPPolicy = errors.New("unknown ip policy")
+
+// IPPolicy decides the address families of the nodes discovered and dialed, and which address is kept
+// for a node seen at both an IPv4 and an IPv6 address.
+type IPPolicy string
+
+const (
+	PreferIPv4 IPPolicy = "preferIPv4"
+	PreferIPv6 IPPolicy = "preferIPv6"
+	IPv4Only   IPPolicy = "ipv4"
+	IPv6Only   IPPolicy = "ipv6"
+)
+
+// ParseIPPolicy parses str to IPPolicy, an empty str is PreferIPv4.
+func ParseIPPolicy(str string) (IPPolicy, error) {
+	switch p := IPPolicy(str); p {
+	case "":
+		return PreferIPv4, nil
+	case PreferIPv4, PreferIPv6, IPv4Only, IPv6Only:
+		return p, nil
+	default:
+		return "", errUnknownIPPolicy
+	}
+}
+
+// Accept reports whether the node at ip can be discovered and dialed, an empty ip is accepted.
+func (p IPPolicy) Accept(ip net.IP) bool {
+	if len(ip) == 0 {
+		return true
+	}
+
+	switch p {
+	case IPv4Only:
+		return ip.To4() != nil
+	case IPv6Only:
+		return ip.To4() == nil
+	default:
+		return true
+	}
+}
+
+// Prefer reports whether ip should replace old as the address of a node, it's true only if they are of
+// different families and the family of ip is the preferred one.
+func (p IPPolicy) Prefer(ip, old net.IP) bool {
+	if !p.Accept(ip) {
+		return false
+	}
+	if !p.Accept(old) {
+		return true
+	}
+
+	v4 := ip.To4() != nil
+	if v4 == (old.To4() != nil) {
+		return false
+	}
+	if p == PreferIPv6 {
+		return !v4
+	}
+	return v4
+}
+
+// CanonicalIP returns the 4-byte form of an IPv4 address, including the IPv4-mapped IPv6 ones read from
+// dual-stack sockets, so that an address is encoded and compared the same whichever socket it comes from.
+func CanonicalIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip
+}
+
+func sameFamily(a, b net.IP) bool {
+	return (a.To4() != nil) == (b.To4() != nil)
+}
+
+func validIP(ip net.IP) bool {
+	return len(ip) == 0 || len(ip) == net.IPv4len || len(ip) == net.IPv6len
+}
@@ -0,0 +1,103 @@
+package discovery
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIPPolicy(t *testing.T) {
+	v4 := net.ParseIP("1.2.3.4")
+	v6 := net.ParseIP("2001:db8::1")
+
+	if p, err := ParseIPPolicy(""); err != nil || p != PreferIPv4 {
+		t.Fatalf("unexpected default policy %q: %v", p, err)
+	}
+	if _, err := ParseIPPolicy("ipv5"); err != errUnknownIPPolicy {
+		t.Fatalf("expected errUnknownIPPolicy, got %v", err)
+	}
+
+	if !IPv4Only.Accept(v4) || IPv4Only.Accept(v6) || IPv6Only.Accept(v4) || !IPv6Only.Accept(v6) {
+		t.Fatal("unexpected accept of single-stack policies")
+	}
+	if !PreferIPv6.Accept(v4) || !PreferIPv4.Accept(v6) {
+		t.Fatal("dual-stack policies should accept both")
+	}
+
+	if !PreferIPv4.Prefer(v4, v6) || PreferIPv4.Prefer(v6, v4) || !PreferIPv6.Prefer(v6, v4) || PreferIPv6.Prefer(v4, v6) {
+		t.Fatal("unexpected preference")
+	}
+	if PreferIPv4.Prefer(v4, net.ParseIP("5.6.7.8")) {
+		t.Fatal("addresses of the same family are not preferred")
+	}
+	if !IPv6Only.Prefer(v6, v4) || IPv6Only.Prefer(v4, v6) {
+		t.Fatal("unexpected preference of single-stack policy")
+	}
+
+	if ip := CanonicalIP(v4); len(ip) != net.IPv4len {
+		t.Fatalf("unexpected canonical ip %v", []byte(ip))
+	}
+	if ip := CanonicalIP(v6); len(ip) != net.IPv6len {
+		t.Fatalf("unexpected canonical ip %v", []byte(ip))
+	}
+}
+
+func TestNode_IPv6(t *testing.T) {
+	n := &Node{ID: mockID(), IP: net.ParseIP("2001:db8::1"), UDP: 8483, TCP: 8484}
+
+	n2, err := ParseNode(n.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !n2.IP.Equal(n.IP) || n2.UDP != n.UDP || n2.TCP != n.TCP {
+		t.Fatalf("unexpected node %s parsed from %s", n2, n)
+	}
+
+	buf, err := n.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n2 = new(Node)
+	if err = n2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !n2.IP.Equal(n.IP) {
+		t.Fatalf("unexpected ip %s", n2.IP)
+	}
+
+	// ipv4 is encoded in 4 bytes
+	n.IP = net.ParseIP("1.2.3.4")
+	buf, _ = n.Serialize()
+	n2.Deserialize(buf)
+	if len(n2.IP) != net.IPv4len || !n2.IP.Equal(n.IP) {
+		t.Fatalf("unexpected ip %v", []byte(n2.IP))
+	}
+}
+
+func TestTable_DualStack(t *testing.T) {
+	tab := newTable(mockID(), 0, PreferIPv6)
+	id := mockID()
+
+	tab.addNode(&Node{ID: id, IP: net.ParseIP("1.2.3.4"), UDP: 8483})
+	tab.addNode(&Node{ID: id, IP: net.ParseIP("2001:db8::1"), UDP: 8483})
+	if tab.size() != 1 {
+		t.Fatalf("dual-stack node should be added once, got %d", tab.size())
+	}
+	if n := tab.resolveById(id); n == nil || n.IP.To4() != nil {
+		t.Fatalf("ipv6 address should be kept, got %v", n)
+	}
+	if tab.resolve("1.2.3.4:8483") != nil || tab.resolve("[2001:db8::1]:8483") == nil {
+		t.Fatal("unexpected addresses in table")
+	}
+
+	// ipv4 is not preferred
+	tab.addNode(&Node{ID: id, IP: net.ParseIP("1.2.3.4"), UDP: 8483})
+	if n := tab.resolveById(id); n.IP.To4() != nil {
+		t.Fatalf("ipv6 address should be kept, got %v", n)
+	}
+
+	tab = newTable(mockID(), 0, IPv4Only)
+	tab.addNode(&Node{ID: id, IP: net.ParseIP("2001:db8::1"), UDP: 8483})
+	if tab.size() != 0 {
+		t.Fatal("ipv6 node should not be accepted")
+	}
+}
@@ -151,7 +151,7 @@ func (p *Pong) serialize() ([]byte, error) {
 	pb := &protos.Pong{
 		ID:         p.ID[:],
 		Ping:       p.Ping[:],
-		IP:         p.IP,
+		IP:         CanonicalIP(p.IP),
 		Expiration: p.Expiration.Unix(),
 	}
 	return proto.Marshal(pb)
@@ -169,6 +169,10 @@ func (p *Pong) deserialize(buf []byte) error {
 		return err
 	}
 
+	if !validIP(pb.IP) {
+		return errInvalidIP
+	}
+
 	p.ID = id
 	p.IP = pb.IP
 	copy(p.Ping[:], pb.Ping)
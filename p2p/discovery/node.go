@@ -1,7 +1,6 @@
 package discovery
 
 import (
-	"bytes"
 	"encoding/hex"
 	"errors"
 	"net"
@@ -104,7 +103,7 @@ type Node struct {
 
 func (n *Node) Update(n2 *Node) {
 	n.ID = n2.ID
-	if len(n2.IP) > 0 && !bytes.Equal(n.IP, n2.IP) {
+	if len(n2.IP) > 0 && !n.IP.Equal(n2.IP) {
 		n.IP = n2.IP
 	}
 	if n.UDP != n2.UDP && n2.UDP > 0 {
@@ -124,7 +123,7 @@ func (n *Node) Update(n2 *Node) {
 func (n *Node) proto() *protos.Node {
 	return &protos.Node{
 		ID:  n.ID[:],
-		IP:  n.IP,
+		IP:  CanonicalIP(n.IP),
 		UDP: uint32(n.UDP),
 		TCP: uint32(n.TCP),
 		Net: uint32(n.Net),
@@ -138,6 +137,10 @@ func protoToNode(pb *protos.Node) (*Node, error) {
 		return nil, err
 	}
 
+	if !validIP(pb.IP) {
+		return nil, errInvalidIP
+	}
+
 	node := new(Node)
 	node.ID = id
 	node.IP = pb.IP
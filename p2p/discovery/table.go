@@ -153,13 +153,15 @@ type table struct {
 	buckets []*bucket
 	id      NodeID
 	netID   network.ID
+	policy  IPPolicy
 	chm     sync.Map // ch: bool
 }
 
-func newTable(id NodeID, netID network.ID) *table {
+func newTable(id NodeID, netID network.ID, policy IPPolicy) *table {
 	tab := &table{
 		id:      id,
 		netID:   netID,
+		policy:  policy,
 		buckets: make([]*bucket, N),
 	}
 
@@ -171,7 +173,7 @@ func newTable(id NodeID, netID network.ID) *table {
 }
 
 func (tab *table) acceptNode(node *Node) bool {
-	return node.Net == 0 || node.Net == tab.netID
+	return (node.Net == 0 || node.Net == tab.netID) && tab.policy.Accept(node.IP)
 }
 
 func (tab *table) addNode(node *Node) *Node {
@@ -196,12 +198,22 @@ func (tab *table) addNode(node *Node) *Node {
 		return nil
 	}
 
-	node.addAt = time.Now()
-	tab.m.Store(addr, node)
-
 	bkt := tab.getBucket(node.ID)
 
 	tab.mu.Lock()
+	// exist in table at another address, keep one address for a dual-stack node
+	if old := bkt.resolve(node.ID); old != nil {
+		if sameFamily(old.IP, node.IP) || tab.policy.Prefer(node.IP, old.IP) {
+			tab.m.Delete(old.UDPAddr().String())
+			old.IP, old.UDP = node.IP, node.UDP
+			tab.m.Store(addr, old)
+		}
+		tab.mu.Unlock()
+		return nil
+	}
+
+	node.addAt = time.Now()
+	tab.m.Store(addr, node)
 	oldest := bkt.add(node)
 	tab.mu.Unlock()
 
@@ -101,7 +101,7 @@ func TestBucket_Bubble(t *testing.T) {
 func TestTable_Find(t *testing.T) {
 	var id NodeID
 	rand.Read(id[:])
-	table := newTable(id, 0)
+	table := newTable(id, 0, PreferIPv4)
 	port := uint16(8483)
 	for i := 0; i < 10000; i++ {
 		var id2 NodeID
@@ -130,7 +130,7 @@ func TestTable_Find(t *testing.T) {
 func TestTable_Delete(t *testing.T) {
 	var id NodeID
 	rand.Read(id[:])
-	table := newTable(id, 0)
+	table := newTable(id, 0, PreferIPv4)
 
 	var id2 NodeID
 	port := uint16(8483)
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/socks"
 	"github.com/vitelabs/go-vite/crypto/ed25519"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/monitor"
@@ -21,6 +22,8 @@ import (
 )
 
 var errSvrStarted = errors.New("server has started")
+var errDiscoveryWithProxy = errors.New("discovery runs on UDP and can`t work through the SOCKS5 proxy")
+var errInvalidOnionAddr = errors.New("invalid onion address")
 var blockMinExpired = time.Minute
 var blockMaxExpired = 5 * time.Minute
 
@@ -53,12 +56,16 @@ type Config struct {
 	MaxPendingPeers uint               // max peers waiting for connect
 	MaxInboundRatio uint               // max inbound peers: MaxPeers / MaxInboundRatio
 	Addr            string             // TCP and UDP listen port
+	Addr6           string             // TCP and UDP listen address of IPv6 if listened separately, Addr must be IPv4 then
+	IPPolicy        string             // address families of the nodes discovered and dialed, see discovery.IPPolicy
 	DataDir         string             // the directory for storing node table, default is "~/viteisbest/p2p"
 	PeerKey         ed25519.PrivateKey // use for encrypt message, the corresponding public key use for NodeID
 	ExtNodeData     []byte             // extension data for Node
 	Protocols       []*Protocol        // protocols server supported
 	BootNodes       []string           // nodes as discovery seed
-	StaticNodes     []string           // nodes to connect
+	StaticNodes     []string           // nodes to connect, the ones at onion addresses are dialed through Proxy
+	Proxy           string             // SOCKS5 proxy all peers are dialed through, e.g. tor at "127.0.0.1:9050"
+	OnionAddr       string             // onion service address published in handshake, e.g. "xxx.onion:8483"
 }
 
 type Server interface {
@@ -81,7 +88,10 @@ type Server interface {
 type server struct {
 	config      *Config
 	addr        *net.TCPAddr
+	addr6       *net.TCPAddr
+	ipPolicy    discovery.IPPolicy
 	staticNodes []*discovery.Node
+	onionNodes  []*onionNode
 
 	running   int32          // atomic
 	wg        sync.WaitGroup // Wait for all jobs done
@@ -95,15 +105,21 @@ type server struct {
 	blockUtil *block.Block
 	self      *discovery.Node
 	ln        net.Listener
+	ln6       net.Listener
 	nodeChan  chan *discovery.Node // sub discovery nodes
 	log       log15.Logger
 
 	rw     sync.RWMutex // for block
-	dialer *net.Dialer
+	dialer dialer
 
 	plugins []Plugin
 }
 
+// dialer dials peers directly, or through the SOCKS5 proxy
+type dialer interface {
+	Dial(network, addr string) (net.Conn, error)
+}
+
 func (svr *server) Config() *Config {
 	return svr.config
 }
@@ -111,8 +127,31 @@ func (svr *server) Config() *Config {
 func New(cfg *Config) (Server, error) {
 	cfg = EnsureConfig(cfg)
 
+	if cfg.Proxy != "" && cfg.Discovery {
+		return nil, errDiscoveryWithProxy
+	}
+	if cfg.OnionAddr != "" {
+		if host, _, err := net.SplitHostPort(cfg.OnionAddr); err != nil || !socks.IsOnion(host) {
+			return nil, errInvalidOnionAddr
+		}
+	}
+
 	// tcp listener
-	tcpAddr, err := net.ResolveTCPAddr("tcp", cfg.Addr)
+	tcpNetwork := "tcp"
+	var tcpAddr6 *net.TCPAddr
+	if cfg.Addr6 != "" {
+		var err error
+		tcpNetwork = "tcp4"
+		if tcpAddr6, err = net.ResolveTCPAddr("tcp6", cfg.Addr6); err != nil {
+			return nil, err
+		}
+	}
+	tcpAddr, err := net.ResolveTCPAddr(tcpNetwork, cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ipPolicy, err := discovery.ParseIPPolicy(cfg.IPPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +173,10 @@ func New(cfg *Config) (Server, error) {
 	svr := &server{
 		config:      cfg,
 		addr:        tcpAddr,
+		addr6:       tcpAddr6,
+		ipPolicy:    ipPolicy,
 		staticNodes: parseNodes(cfg.StaticNodes),
+		onionNodes:  parseOnionNodes(cfg.StaticNodes),
 		peers:       NewPeerSet(),
 		pending:     make(chan struct{}, cfg.MaxPendingPeers),
 		addPeer:     make(chan *transport, 5),
@@ -149,14 +191,26 @@ func New(cfg *Config) (Server, error) {
 		},
 	}
 
+	if cfg.Proxy != "" {
+		// circuits of tor take much longer to build than tcp connections
+		svr.dialer = &socks.Dialer{
+			Proxy:   cfg.Proxy,
+			Timeout: 30 * time.Second,
+		}
+	} else if len(svr.onionNodes) > 0 {
+		svr.log.Warn("onion static nodes can`t be dialed without proxy")
+	}
+
 	if cfg.Discovery {
 		svr.discv = discovery.New(&discovery.Config{
 			PeerKey:   cfg.PeerKey,
 			DBPath:    cfg.DataDir,
 			BootNodes: parseNodes(cfg.BootNodes),
 			Addr:      cfg.Addr,
+			Addr6:     cfg.Addr6,
 			Self:      node,
 			NetID:     cfg.NetID,
+			IPPolicy:  ipPolicy,
 		})
 	}
 
@@ -173,23 +227,39 @@ func (svr *server) Start() error {
 	// setHandshake in method Start, because svr.Protocols may be modified
 	svr.setHandshake()
 
-	listener, err := net.ListenTCP("tcp", svr.addr)
+	tcpNetwork := "tcp"
+	if svr.addr6 != nil {
+		tcpNetwork = "tcp4"
+	}
+	listener, err := net.ListenTCP(tcpNetwork, svr.addr)
 	if err != nil {
 		return err
 	}
 	svr.log.Info(fmt.Sprintf("tcp listen at %s", svr.addr))
 	svr.ln = listener
 
+	if svr.addr6 != nil {
+		listener6, err := net.ListenTCP("tcp6", svr.addr6)
+		if err != nil {
+			svr.ln.Close()
+			return err
+		}
+		svr.log.Info(fmt.Sprintf("tcp listen at %s", svr.addr6))
+		svr.ln6 = listener6
+	}
+
 	svr.term = make(chan struct{})
 
 	svr.log.Info("p2p server start")
 
-	// mapping tcp
-	svr.wg.Add(1)
-	common.Go(func() {
-		nat.Map(svr.term, "tcp", int(svr.self.TCP), int(svr.self.TCP), "vite p2p tcp", 0, svr.updateNode)
-		svr.wg.Done()
-	})
+	// mapping tcp, not through proxy, the mapping would expose our ip
+	if svr.config.Proxy == "" {
+		svr.wg.Add(1)
+		common.Go(func() {
+			nat.Map(svr.term, "tcp", int(svr.self.TCP), int(svr.self.TCP), "vite p2p tcp", 0, svr.updateNode)
+			svr.wg.Done()
+		})
+	}
 
 	// discovery
 	if svr.config.Discovery {
@@ -205,7 +275,7 @@ func (svr *server) Start() error {
 
 		err = svr.discv.Start()
 		if err != nil {
-			svr.ln.Close()
+			svr.closeListeners()
 			return err
 		}
 	}
@@ -220,7 +290,16 @@ func (svr *server) Start() error {
 
 	// tcp listener
 	svr.wg.Add(1)
-	common.Go(svr.listenLoop)
+	common.Go(func() {
+		svr.listenLoop(svr.ln)
+	})
+
+	if svr.ln6 != nil {
+		svr.wg.Add(1)
+		common.Go(func() {
+			svr.listenLoop(svr.ln6)
+		})
+	}
 
 	// peer manager
 	svr.wg.Add(1)
@@ -242,9 +321,7 @@ func (svr *server) Stop() {
 
 		close(svr.term)
 
-		if svr.ln != nil {
-			svr.ln.Close()
-		}
+		svr.closeListeners()
 
 		if svr.discv != nil {
 			svr.discv.Stop()
@@ -257,6 +334,15 @@ func (svr *server) Stop() {
 	}
 }
 
+func (svr *server) closeListeners() {
+	if svr.ln != nil {
+		svr.ln.Close()
+	}
+	if svr.ln6 != nil {
+		svr.ln6.Close()
+	}
+}
+
 func (svr *server) AddPlugin(plugin Plugin) {
 	svr.plugins = append(svr.plugins, plugin)
 }
@@ -290,6 +376,7 @@ func (svr *server) setHandshake() {
 		ID:      svr.self.ID,
 		CmdSets: cmds,
 		Port:    uint16(svr.addr.Port),
+		Onion:   config.OnionAddr,
 	}
 }
 
@@ -305,7 +392,9 @@ func (svr *server) Block(id discovery.NodeID, ip net.IP, err error) {
 	defer svr.rw.Unlock()
 
 	svr.blockUtil.Block(id[:])
-	svr.blockUtil.Block(ip)
+	if len(ip) != 0 && !svr.relayed(ip) {
+		svr.blockUtil.Block(discovery.CanonicalIP(ip))
+	}
 	svr.log.Warn(fmt.Sprintf("block %s@%s: %v", id, ip, err))
 
 	if svr.discv != nil && id != discovery.ZERO_NODE_ID {
@@ -318,13 +407,25 @@ func (svr *server) unblock(id discovery.NodeID, ip net.IP) {
 	defer svr.rw.Unlock()
 
 	svr.blockUtil.UnBlock(id[:])
-	svr.blockUtil.UnBlock(ip)
+	svr.blockUtil.UnBlock(discovery.CanonicalIP(ip))
 	svr.log.Warn(fmt.Sprintf("unblock %s@%s", id, ip))
 }
 
+// relayed reports whether the connections with ip are relayed by the local tor, the ip is shared
+// by all the onion peers, so it must not be blocked.
+func (svr *server) relayed(ip net.IP) bool {
+	return (svr.config.Proxy != "" || svr.config.OnionAddr != "") && ip.IsLoopback()
+}
+
 func (svr *server) dialStatic() {
 	for _, node := range svr.staticNodes {
-		svr.dial(node.ID, node.TCPAddr(), static, nil)
+		svr.dial(node.ID, node.TCPAddr().String(), static, nil)
+	}
+
+	if svr.config.Proxy != "" {
+		for _, node := range svr.onionNodes {
+			svr.dial(node.ID, node.Addr, static, nil)
+		}
 	}
 }
 
@@ -349,12 +450,12 @@ func (svr *server) dialLoop() {
 				break
 			}
 
-			if svr.blocked(node.ID[:]) {
+			if svr.blocked(node.ID[:]) || !svr.ipPolicy.Accept(node.IP) {
 				break
 			}
 
 			dialing[node.ID] = struct{}{}
-			svr.dial(node.ID, node.TCPAddr(), outbound, dialDone)
+			svr.dial(node.ID, node.TCPAddr().String(), outbound, dialDone)
 
 		case id := <-dialDone:
 			delete(dialing, id)
@@ -365,7 +466,8 @@ func (svr *server) dialLoop() {
 // when peer is disconnected, maybe we want to reconnect it.
 // we can get ID and addr only from peer, but not Node
 // so dial(id, addr, flag) not dial(Node, flag)
-func (svr *server) dial(id discovery.NodeID, addr *net.TCPAddr, flag connFlag, done chan<- discovery.NodeID) {
+// addr is host:port, the host is an onion address if the node is an onion service
+func (svr *server) dial(id discovery.NodeID, addr string, flag connFlag, done chan<- discovery.NodeID) {
 	if err := svr.checkConn(id, flag); err != nil {
 		if done != nil {
 			done <- id
@@ -375,10 +477,11 @@ func (svr *server) dial(id discovery.NodeID, addr *net.TCPAddr, flag connFlag, d
 	}
 
 	common.Go(func() {
-		if conn, err := svr.dialer.Dial("tcp", addr.String()); err == nil {
-			svr.setupConn(conn, flag, id)
+		if conn, err := svr.dialer.Dial("tcp", addr); err == nil {
+			svr.setupConn(conn, flag, id, addr)
 		} else {
-			svr.Block(id, addr.IP, err)
+			host, _, _ := net.SplitHostPort(addr)
+			svr.Block(id, net.ParseIP(host), err)
 			svr.log.Warn(fmt.Sprintf("dial node %s@%s failed: %v", id, addr, err))
 		}
 
@@ -389,11 +492,11 @@ func (svr *server) dial(id discovery.NodeID, addr *net.TCPAddr, flag connFlag, d
 }
 
 func (svr *server) Connect(id discovery.NodeID, addr *net.TCPAddr) {
-	svr.dial(id, addr, static, nil)
+	svr.dial(id, addr.String(), static, nil)
 }
 
 // TCPListener will be closed in method: server.Stop()
-func (svr *server) listenLoop() {
+func (svr *server) listenLoop(ln net.Listener) {
 	defer svr.wg.Done()
 
 	var tempDelay time.Duration
@@ -407,7 +510,7 @@ func (svr *server) listenLoop() {
 			var err error
 
 			for {
-				if conn, err = svr.ln.Accept(); err != nil {
+				if conn, err = ln.Accept(); err != nil {
 					// temporary error
 					if ne, ok := err.(net.Error); ok && ne.Temporary() {
 						svr.log.Warn(fmt.Sprintf("listen temp error: %v", ne))
@@ -435,14 +538,14 @@ func (svr *server) listenLoop() {
 				break
 			}
 
-			if addr := conn.RemoteAddr().(*net.TCPAddr); svr.blocked(addr.IP) {
+			if addr := conn.RemoteAddr().(*net.TCPAddr); svr.blocked(discovery.CanonicalIP(addr.IP)) {
 				svr.log.Warn(fmt.Sprintf("%s has been blocked, will not setup", addr))
 				conn.Close()
 				// next pending
 				<-svr.pending
 			} else {
 				common.Go(func() {
-					svr.setupConn(conn, inbound, discovery.ZERO_NODE_ID)
+					svr.setupConn(conn, inbound, discovery.ZERO_NODE_ID, "")
 					<-svr.pending
 				})
 			}
@@ -453,7 +556,8 @@ func (svr *server) listenLoop() {
 	}
 }
 
-func (svr *server) setupConn(c net.Conn, flag connFlag, id discovery.NodeID) {
+// addr is the address dialed, it's empty if the connection is inbound
+func (svr *server) setupConn(c net.Conn, flag connFlag, id discovery.NodeID, addr string) {
 	var err error
 	if err = svr.checkHead(c); err != nil {
 		svr.log.Warn(fmt.Sprintf("HeadShake with %s error: %v, block it", c.RemoteAddr(), err))
@@ -463,8 +567,9 @@ func (svr *server) setupConn(c net.Conn, flag connFlag, id discovery.NodeID) {
 	}
 
 	ts := &transport{
-		Conn:  c,
-		flags: flag,
+		Conn:     c,
+		flags:    flag,
+		dialAddr: addr,
 	}
 
 	if err = svr.handleTS(ts, id); err != nil {
@@ -501,6 +606,7 @@ func (svr *server) handleTS(ts *transport, id discovery.NodeID) error {
 
 	ts.name = their.Name
 	ts.cmdSets = their.CmdSets
+	ts.onion = their.Onion
 
 	// use to describe the connection
 	ts.remoteID = their.ID
@@ -614,7 +720,7 @@ loop:
 			monitor.LogEvent("p2p/peer", "delete")
 
 			if p.ts.is(static) {
-				svr.dial(p.ID(), p.RemoteAddr(), static, nil)
+				svr.dial(p.ID(), p.ts.dialAddr, static, nil)
 			}
 
 		case <-checkTicker.C:
@@ -675,6 +781,7 @@ func (svr *server) NodeInfo() NodeInfo {
 		ID:    svr.self.ID.String(),
 		Name:  svr.config.Name,
 		Url:   svr.self.String(),
+		Onion: svr.config.OnionAddr,
 		NetID: svr.config.NetID,
 		Address: address{
 			IP:  svr.self.IP,
@@ -728,6 +835,7 @@ type NodeInfo struct {
 	ID        string        `json:"id"`
 	Name      string        `json:"name"`
 	Url       string        `json:"url"`
+	Onion     string        `json:"onion,omitempty"`
 	NetID     network.ID    `json:"netId"`
 	Address   address       `json:"address"`
 	Protocols []string      `json:"protocols"`
@@ -59,3 +59,43 @@ func TestBlock_F(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestParseOnionNodes(t *testing.T) {
+	var id discovery.NodeID
+	rand.Read(id[:])
+
+	nodes := parseOnionNodes([]string{
+		"vnode://" + id.String() + "@vitexxxxxxxxxxxx.onion:8483",
+		"vnode://" + id.String() + "@1.2.3.4:8483",
+		"vnode://vitexxxxxxxxxxxx.onion:8483",
+		"vnode://" + id.String() + "@vitexxxxxxxxxxxx.onion:65536",
+	})
+
+	if len(nodes) != 1 || nodes[0].ID != id || nodes[0].Addr != "vitexxxxxxxxxxxx.onion:8483" {
+		t.Fatalf("unexpected onion nodes %v", nodes)
+	}
+}
+
+func TestCreateProtoFrames(t *testing.T) {
+	ours := []*Protocol{
+		{Name: "vite", ID: 2},
+		{Name: "vite", ID: 3},
+		{Name: "topo", ID: 4},
+	}
+
+	// the newest version both sides support runs
+	pfs := createProtoFrames(ours, []CmdSet{2, 3, 4})
+	if len(pfs) != 2 || pfs[3] == nil || pfs[4] == nil {
+		t.Fatalf("unexpected protocols %v", pfs)
+	}
+
+	// a peer not upgraded yet speaks the legacy version
+	pfs = createProtoFrames(ours, []CmdSet{1, 2})
+	if len(pfs) != 1 || pfs[2] == nil {
+		t.Fatalf("unexpected protocols %v", pfs)
+	}
+
+	if pfs = createProtoFrames(ours, []CmdSet{5}); len(pfs) != 0 {
+		t.Fatalf("unexpected protocols %v", pfs)
+	}
+}
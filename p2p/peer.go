@@ -39,6 +39,10 @@ type transport struct {
 	remoteID   discovery.NodeID
 	remoteIP   net.IP
 	remotePort uint16
+	// onion service address published by the peer
+	onion string
+	// address dialed, differs from RemoteAddr if the peer is an onion service dialed through the proxy
+	dialAddr string
 }
 
 func (t *transport) ReadMsg() (*Msg, error) {
@@ -133,17 +137,25 @@ func (pf *ProtoFrame) WriteMsg(msg *Msg) (err error) {
 	return
 }
 
-// create multiple pfs above the rw
+// create multiple pfs above the rw, protocols with the same name are versions of one protocol,
+// only the highest version supported by both sides will run
 func createProtoFrames(ourSet []*Protocol, theirSet []CmdSet) pfMap {
-	pfs := make(pfMap)
+	shared := make(map[string]*Protocol)
 	for _, our := range ourSet {
 		for _, their := range theirSet {
 			if our.ID == their {
-				pfs[our.ID] = newProtoFrame(our)
+				if pt, ok := shared[our.Name]; !ok || our.ID > pt.ID {
+					shared[our.Name] = our
+				}
 			}
 		}
 	}
 
+	pfs := make(pfMap)
+	for _, pt := range shared {
+		pfs[pt.ID] = newProtoFrame(pt)
+	}
+
 	return pfs
 }
 
@@ -417,6 +429,11 @@ func (p *Peer) CmdSets() []CmdSet {
 	return p.ts.cmdSets
 }
 
+// Onion returns the onion service address published by the peer, it's empty if the peer has none.
+func (p *Peer) Onion() string {
+	return p.ts.onion
+}
+
 func (p *Peer) RemoteAddr() *net.TCPAddr {
 	return p.ts.RemoteAddr().(*net.TCPAddr)
 }
@@ -439,6 +456,7 @@ func (p *Peer) Info() *PeerInfo {
 		Name:    p.Name(),
 		CmdSets: caps,
 		Address: p.RemoteAddr().String(),
+		Onion:   p.ts.onion,
 		Inbound: p.ts.is(inbound),
 	}
 }
@@ -526,6 +544,7 @@ type PeerInfo struct {
 	Name    string   `json:"name"`
 	CmdSets []string `json:"cmdSets"`
 	Address string   `json:"address"`
+	Onion   string   `json:"onion,omitempty"`
 	Inbound bool     `json:"inbound"`
 }
 
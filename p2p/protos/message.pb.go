@@ -27,6 +27,7 @@ type Handshake struct {
 	RemoteIP             []byte   `protobuf:"bytes,4,opt,name=RemoteIP,proto3" json:"RemoteIP,omitempty"`
 	RemotePort           uint32   `protobuf:"varint,5,opt,name=RemotePort,proto3" json:"RemotePort,omitempty"`
 	Port                 uint32   `protobuf:"varint,6,opt,name=Port,proto3" json:"Port,omitempty"`
+	Onion                string   `protobuf:"bytes,7,opt,name=Onion,proto3" json:"Onion,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -99,6 +100,13 @@ func (m *Handshake) GetPort() uint32 {
 	return 0
 }
 
+func (m *Handshake) GetOnion() string {
+	if m != nil {
+		return m.Onion
+	}
+	return ""
+}
+
 type ConnProperty struct {
 	LocalID              string   `protobuf:"bytes,1,opt,name=LocalID,proto3" json:"LocalID,omitempty"`
 	LocalIP              []byte   `protobuf:"bytes,2,opt,name=LocalIP,proto3" json:"LocalIP,omitempty"`
@@ -242,23 +250,23 @@ func init() {
 func init() { proto.RegisterFile("message.proto", fileDescriptor_33c57e4bae7b9afd) }
 
 var fileDescriptor_33c57e4bae7b9afd = []byte{
-	// 280 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x91, 0xbf, 0x4a, 0xc4, 0x40,
-	0x10, 0xc6, 0xd9, 0xfc, 0xd3, 0x8c, 0x17, 0x8b, 0xe5, 0x8a, 0x45, 0x44, 0x42, 0xaa, 0x60, 0x71,
-	0x85, 0x3e, 0xc2, 0xa5, 0x30, 0x20, 0xb2, 0xac, 0x57, 0xda, 0x44, 0x6f, 0xd0, 0x43, 0x93, 0x09,
-	0xd9, 0x45, 0xf0, 0x51, 0x7c, 0x13, 0x1f, 0x4f, 0x32, 0x49, 0xee, 0xa2, 0xc5, 0x55, 0xf9, 0xbe,
-	0x9d, 0x61, 0xe6, 0xf7, 0x65, 0x20, 0xa9, 0xd1, 0xda, 0xea, 0x15, 0x57, 0x6d, 0x47, 0x8e, 0x64,
-	0xc4, 0x1f, 0x9b, 0x7d, 0x0b, 0x88, 0xef, 0xaa, 0x66, 0x6b, 0xdf, 0xaa, 0x77, 0x94, 0x12, 0x82,
-	0x87, 0xaa, 0x46, 0x25, 0x52, 0x91, 0xc7, 0x86, 0xb5, 0x3c, 0x07, 0xaf, 0x2c, 0x94, 0x97, 0x8a,
-	0x7c, 0x61, 0xbc, 0xb2, 0x90, 0x0a, 0x4e, 0xd6, 0xf5, 0xf6, 0x11, 0x9d, 0x55, 0x7e, 0xea, 0xe7,
-	0x89, 0x99, 0xac, 0xbc, 0x80, 0x53, 0x83, 0x35, 0x39, 0x2c, 0xb5, 0x0a, 0xb8, 0x7f, 0xef, 0xe5,
-	0x15, 0xc0, 0xa0, 0x35, 0x75, 0x4e, 0x85, 0xa9, 0xc8, 0x13, 0x33, 0x7b, 0xe9, 0x37, 0x73, 0x25,
-	0xe2, 0x0a, 0xeb, 0xec, 0x47, 0xc0, 0x62, 0x4d, 0x4d, 0xa3, 0x3b, 0x6a, 0xb1, 0x73, 0x5f, 0xfd,
-	0xea, 0x7b, 0x7a, 0xa9, 0x3e, 0xca, 0x62, 0x24, 0x9c, 0xec, 0xa1, 0xa2, 0x47, 0xd2, 0xc9, 0xca,
-	0x4b, 0x88, 0x59, 0xf2, 0x74, 0x9f, 0xa7, 0x1f, 0x1e, 0x66, 0xc8, 0x05, 0x23, 0xc7, 0x7b, 0xe4,
-	0xe2, 0x4f, 0x9c, 0xf0, 0x68, 0x9c, 0xe8, 0x7f, 0x9c, 0xec, 0x09, 0x82, 0x0d, 0xb5, 0x24, 0x97,
-	0x10, 0xea, 0xdd, 0x27, 0xb9, 0x91, 0x77, 0x30, 0xf2, 0x1a, 0x42, 0x8d, 0xd8, 0x59, 0xe5, 0xa5,
-	0x7e, 0x7e, 0x76, 0xb3, 0x1c, 0x6e, 0x62, 0x57, 0xf3, 0xb0, 0x66, 0x68, 0xe9, 0x7f, 0xcc, 0x66,
-	0x57, 0x23, 0xa3, 0xfb, 0x86, 0xf5, 0xf3, 0x70, 0xbc, 0xdb, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0xa7, 0x39, 0xee, 0x30, 0xd4, 0x01, 0x00, 0x00,
+	// 282 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x7d, 0x51, 0x3d, 0x4f, 0xc3, 0x30,
+	0x10, 0x55, 0x9a, 0x8f, 0x92, 0xa3, 0x61, 0xb0, 0x3a, 0x58, 0x08, 0xa1, 0x2a, 0x53, 0xc4, 0xd0,
+	0x01, 0x7e, 0x42, 0x33, 0x50, 0xa9, 0x02, 0xcb, 0x74, 0x64, 0x31, 0xd4, 0x82, 0x08, 0x92, 0x8b,
+	0x62, 0x0b, 0x89, 0x5f, 0xc5, 0xca, 0xcf, 0xc3, 0x39, 0x37, 0x6d, 0x60, 0x60, 0xf2, 0xfb, 0x38,
+	0xe9, 0xde, 0xf3, 0x41, 0x56, 0x6b, 0x63, 0xd4, 0x8b, 0x5e, 0xb6, 0x1d, 0x5a, 0x64, 0x09, 0x3d,
+	0x26, 0xff, 0x0a, 0x20, 0xbd, 0x55, 0xcd, 0xce, 0xbc, 0xaa, 0x37, 0xcd, 0x18, 0x44, 0x77, 0xaa,
+	0xd6, 0x3c, 0x58, 0x04, 0x45, 0x2a, 0x09, 0xb3, 0x33, 0x98, 0xac, 0x4b, 0x3e, 0x71, 0xca, 0x4c,
+	0x3a, 0xc4, 0x38, 0x4c, 0x57, 0xf5, 0xee, 0x41, 0x5b, 0xc3, 0xc3, 0x45, 0x58, 0x64, 0x72, 0xa0,
+	0xec, 0x1c, 0x4e, 0xa4, 0xae, 0xd1, 0xea, 0xb5, 0xe0, 0x11, 0xcd, 0x1f, 0x38, 0xbb, 0x04, 0xf0,
+	0x58, 0x60, 0x67, 0x79, 0xec, 0xdc, 0x4c, 0x8e, 0x94, 0x7e, 0x33, 0x39, 0x09, 0x39, 0x84, 0xd9,
+	0x1c, 0xe2, 0xfb, 0xa6, 0xc2, 0x86, 0x4f, 0x29, 0x8e, 0x27, 0xf9, 0x77, 0x00, 0xb3, 0x15, 0x36,
+	0x8d, 0xe8, 0xb0, 0xd5, 0x9d, 0xfd, 0xec, 0x03, 0x6d, 0xf0, 0x59, 0xbd, 0xbb, 0x94, 0x3e, 0xf7,
+	0x40, 0x8f, 0x8e, 0xd8, 0xe7, 0x1f, 0x28, 0xbb, 0x80, 0x94, 0x20, 0xed, 0x0c, 0x69, 0xe7, 0x51,
+	0x18, 0x15, 0x29, 0xa9, 0x48, 0x7a, 0x28, 0x52, 0xfe, 0x2a, 0x19, 0xff, 0x5b, 0x32, 0xf9, 0x5b,
+	0x32, 0x7f, 0x84, 0x68, 0x8b, 0x2d, 0xf6, 0xc5, 0x44, 0xf5, 0x81, 0x76, 0x9f, 0xd7, 0x13, 0x76,
+	0xe5, 0x54, 0xad, 0x3b, 0xe3, 0xb2, 0x86, 0xc5, 0xe9, 0xf5, 0xdc, 0x5f, 0xca, 0x2c, 0xc7, 0x65,
+	0xa5, 0x1f, 0xe9, 0xbf, 0x6b, 0x5b, 0xb9, 0x43, 0xf5, 0xd1, 0x43, 0x49, 0xf8, 0xc9, 0x9f, 0xf4,
+	0xe6, 0x07, 0xc8, 0xec, 0x9f, 0x38, 0xea, 0x01, 0x00, 0x00,
 }
@@ -22,7 +22,7 @@ const handshakeCmd = 0
 const discCmd = 1
 
 const headerLength = 32
-const maxPayloadSize = ^uint32(0) >> 8 // 15MB
+const MaxPayloadSize = ^uint32(0) >> 8 // 15MB
 const shakeTimeout = 10 * time.Second
 
 // head message is the first message in a tcp connection
@@ -102,7 +102,7 @@ func PackMsg(cmdSetId CmdSet, cmd Cmd, id uint64, s Serializable) (*Msg, error)
 	}
 
 	size := uint32(len(data))
-	if size > maxPayloadSize {
+	if size > MaxPayloadSize {
 		return nil, errMsgTooLarge
 	}
 
@@ -128,7 +128,7 @@ func ReadMsg(reader io.Reader) (msg *Msg, err error) {
 	msg.Id = binary.BigEndian.Uint64(head[6:14])
 	size := binary.BigEndian.Uint32(head[14:18])
 
-	if size > maxPayloadSize {
+	if size > MaxPayloadSize {
 		return nil, errMsgTooLarge
 	}
 
@@ -153,7 +153,7 @@ func WriteMsg(writer io.Writer, msg *Msg) (err error) {
 		return errMsgNull
 	}
 
-	if size > maxPayloadSize {
+	if size > MaxPayloadSize {
 		return errMsgTooLarge
 	}
 
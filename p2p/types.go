@@ -69,7 +69,8 @@ type Serializable interface {
 type Protocol struct {
 	// description of the protocol
 	Name string
-	// use for message command set, should be unique
+	// use for message command set, should be unique. Protocols with the same Name are versions of
+	// one protocol, a higher ID is a newer version
 	ID CmdSet
 	// read and write Msg with rw
 	Handle func(p *Peer, rw *ProtoFrame) error
@@ -93,6 +94,8 @@ type Handshake struct {
 	RemotePort uint16
 	// tell peer our tcp listen port, could use for update discovery info
 	Port uint16
+	// onion service address of ours, could be dialed through tor
+	Onion string
 }
 
 func (hs *Handshake) Serialize() ([]byte, error) {
@@ -103,6 +106,7 @@ func (hs *Handshake) Serialize() ([]byte, error) {
 		RemoteIP:   hs.RemoteIP,
 		RemotePort: uint32(hs.RemotePort),
 		Port:       uint32(hs.Port),
+		Onion:      hs.Onion,
 	})
 }
 
@@ -124,6 +128,7 @@ func (hs *Handshake) Deserialize(buf []byte) error {
 	hs.RemotePort = uint16(pb.RemotePort)
 	hs.CmdSets = pb.CmdSets
 	hs.Port = uint16(pb.Port)
+	hs.Onion = pb.Onion
 
 	return nil
 }
@@ -29,6 +29,7 @@ func mockHandshake() *Handshake {
 		RemoteIP:   ip,
 		RemotePort: uint16(mrand.Intn(65535)),
 		Port:       uint16(mrand.Intn(65535)),
+		Onion:      hex.EncodeToString(name) + ".onion:8483",
 	}
 
 	return hand
@@ -51,6 +52,10 @@ func compare(hand, hand2 *Handshake) bool {
 		return false
 	}
 
+	if hand2.Onion != hand.Onion {
+		return false
+	}
+
 	for i, cmd := range hand.CmdSets {
 		if hand2.CmdSets[i] != cmd {
 			return false
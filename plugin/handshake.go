@@ -0,0 +1,60 @@
+package plugin
+
+// plugin runs external programs beside the node and serves them the chain reads, the chain events and
+// the submission of blocks over net/rpc, so that indexers and other services can extend the node
+// without being built into it.
+//
+// The host starts a plugin with the magic cookie and the address of its node service in the
+// environment. The plugin dials the node service, listens on a local port of its own, and prints the
+// handshake line "<protocol version>|tcp|<address>" to its stdout, then the host dials it back.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ProtocolVersion is the version of the plugin protocol, the host refuses plugins of other versions
+	ProtocolVersion = 1
+
+	MagicCookieKey   = "VITE_PLUGIN_MAGIC_COOKIE"
+	MagicCookieValue = "3b1e3ea1c4a3f5f09c0b9c9e8d8b7d1ac0a2b6f4"
+
+	// NodeAddrKey is the env key of the address of the node service
+	NodeAddrKey = "VITE_PLUGIN_NODE_ADDR"
+)
+
+var (
+	ErrNotPlugin        = errors.New("this binary is a plugin of gvite, it is not meant to be executed directly")
+	ErrInvalidHandshake = errors.New("invalid plugin handshake")
+)
+
+type handshake struct {
+	version int
+	network string
+	addr    string
+}
+
+func (h *handshake) String() string {
+	return fmt.Sprintf("%d|%s|%s", h.version, h.network, h.addr)
+}
+
+func parseHandshake(line string) (*handshake, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 3 {
+		return nil, ErrInvalidHandshake
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, ErrInvalidHandshake
+	}
+	if version != ProtocolVersion {
+		return nil, fmt.Errorf("plugin protocol version %d is not supported, expected %d", version, ProtocolVersion)
+	}
+	if parts[1] != "tcp" || parts[2] == "" {
+		return nil, ErrInvalidHandshake
+	}
+	return &handshake{version: version, network: parts[1], addr: parts[2]}, nil
+}
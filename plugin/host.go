@@ -0,0 +1,328 @@
+package plugin
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+const (
+	STOP  = 1
+	START = 2
+)
+
+const (
+	handshakeTimeout = 10 * time.Second
+	shutdownTimeout  = 5 * time.Second
+
+	// eventBufferSize is the count of the events queued for a plugin, the events beyond it are dropped
+	eventBufferSize = 1000
+)
+
+type event struct {
+	method string
+	args   *EventArgs
+}
+
+// process is a plugin program started by the host.
+type process struct {
+	path   string
+	cmd    *exec.Cmd
+	client *rpc.Client
+	exited chan struct{}
+
+	events   chan *event
+	terminal chan struct{}
+	wg       sync.WaitGroup
+}
+
+// Host starts the plugin programs, serves them the node service and forwards them the chain events.
+type Host struct {
+	chain  chain.Chain
+	submit SubmitFunc
+	paths  []string
+	log    log15.Logger
+
+	listener  net.Listener
+	processes []*process
+
+	insertLid   uint64
+	deleteLid   uint64
+	insertSbLid uint64
+	deleteSbLid uint64
+
+	status     int
+	statusLock sync.Mutex
+}
+
+// NewHost creates a host of the plugin programs at paths, the blocks they send are submitted by submit.
+func NewHost(c chain.Chain, submit SubmitFunc, paths []string) *Host {
+	return &Host{
+		chain:  c,
+		submit: submit,
+		paths:  paths,
+		log:    log15.New("module", "plugin"),
+		status: STOP,
+	}
+}
+
+// Start starts the plugins. A plugin failing to start is logged and skipped, so that it doesn't stop the node.
+func (h *Host) Start() error {
+	h.statusLock.Lock()
+	defer h.statusLock.Unlock()
+	if h.status == START {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("Node", NewNodeService(h.chain, h.submit)); err != nil {
+		listener.Close()
+		return err
+	}
+	go server.Accept(listener)
+	h.listener = listener
+
+	for _, path := range h.paths {
+		p, err := h.launch(path)
+		if err != nil {
+			h.log.Error("start plugin failed, error is "+err.Error(), "path", path)
+			continue
+		}
+		h.processes = append(h.processes, p)
+		h.log.Info("plugin started", "path", path, "pid", p.cmd.Process.Pid)
+	}
+
+	h.insertLid = h.chain.RegisterInsertAccountBlocksSuccess(h.insertAccountBlocksSuccess)
+	h.deleteLid = h.chain.RegisterDeleteAccountBlocksSuccess(h.deleteAccountBlocksSuccess)
+	h.insertSbLid = h.chain.RegisterInsertSnapshotBlocksSuccess(h.insertSnapshotBlocksSuccess)
+	h.deleteSbLid = h.chain.RegisterDeleteSnapshotBlocksSuccess(h.deleteSnapshotBlocksSuccess)
+
+	h.status = START
+	h.log.Info("plugin host start", "plugins", len(h.processes))
+	return nil
+}
+
+func (h *Host) Stop() {
+	h.statusLock.Lock()
+	defer h.statusLock.Unlock()
+	if h.status == STOP {
+		return
+	}
+
+	h.chain.UnRegister(h.insertLid)
+	h.chain.UnRegister(h.deleteLid)
+	h.chain.UnRegister(h.insertSbLid)
+	h.chain.UnRegister(h.deleteSbLid)
+
+	for _, p := range h.processes {
+		h.shutdown(p)
+	}
+	h.processes = nil
+	h.listener.Close()
+
+	h.status = STOP
+	h.log.Info("plugin host stop")
+}
+
+func (h *Host) launch(path string) (*process, error) {
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(),
+		MagicCookieKey+"="+MagicCookieValue,
+		NodeAddrKey+"="+h.listener.Addr().String())
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &process{path: path, cmd: cmd, exited: make(chan struct{})}
+
+	lines := make(chan string, 1)
+	go h.readOutput(p, stdout, lines)
+	go func() {
+		cmd.Wait()
+		close(p.exited)
+	}()
+
+	var hs *handshake
+	select {
+	case line := <-lines:
+		hs, err = parseHandshake(line)
+	case <-p.exited:
+		err = errors.New("the plugin exited before the handshake")
+	case <-time.After(handshakeTimeout):
+		err = errors.New("the plugin handshake timed out")
+	}
+	if err == nil {
+		p.client, err = rpc.Dial(hs.network, hs.addr)
+	}
+	if err == nil {
+		err = p.client.Call("Plugin.Init", &Empty{}, &Empty{})
+	}
+	if err != nil {
+		if p.client != nil {
+			p.client.Close()
+		}
+		cmd.Process.Kill()
+		return nil, err
+	}
+
+	p.events = make(chan *event, eventBufferSize)
+	p.terminal = make(chan struct{})
+	p.wg.Add(1)
+	go h.forward(p)
+	return p, nil
+}
+
+// readOutput sends the first line of the plugin output, the handshake, to lines and logs the rest.
+func (h *Host) readOutput(p *process, stdout io.Reader, lines chan<- string) {
+	reader := bufio.NewReader(stdout)
+	first := true
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if first {
+				lines <- line
+				first = false
+			} else {
+				h.log.Info(line, "plugin", p.path)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// forward delivers the events of p one at a time, so a slow plugin only delays its own events.
+func (h *Host) forward(p *process) {
+	defer p.wg.Done()
+	for {
+		select {
+		case e := <-p.events:
+			if err := p.client.Call("Plugin."+e.method, e.args, &Empty{}); err != nil {
+				h.log.Error(fmt.Sprintf("deliver %v failed, error is %v", e.method, err), "plugin", p.path)
+			}
+		case <-p.terminal:
+			return
+		}
+	}
+}
+
+func (h *Host) shutdown(p *process) {
+	close(p.terminal)
+	p.wg.Wait()
+
+	call := p.client.Go("Plugin.Shutdown", &Empty{}, &Empty{}, nil)
+	select {
+	case <-call.Done:
+	case <-time.After(shutdownTimeout):
+		h.log.Warn("plugin shutdown timed out", "plugin", p.path)
+	}
+	p.client.Close()
+
+	select {
+	case <-p.exited:
+	case <-time.After(shutdownTimeout):
+		p.cmd.Process.Kill()
+		<-p.exited
+	}
+}
+
+func (h *Host) broadcast(method string, blocks [][]byte) {
+	e := &event{method: method, args: &EventArgs{Blocks: blocks}}
+	for _, p := range h.processes {
+		select {
+		case p.events <- e:
+		default:
+			h.log.Warn("plugin events overflow, the event is dropped", "plugin", p.path, "event", method)
+		}
+	}
+}
+
+func serializeAccountBlocks(blocks []*ledger.AccountBlock) ([][]byte, error) {
+	bufs := make([][]byte, 0, len(blocks))
+	for _, block := range blocks {
+		buf, err := block.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		bufs = append(bufs, buf)
+	}
+	return bufs, nil
+}
+
+func serializeSnapshotBlocks(blocks []*ledger.SnapshotBlock) ([][]byte, error) {
+	bufs := make([][]byte, 0, len(blocks))
+	for _, block := range blocks {
+		buf, err := block.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		bufs = append(bufs, buf)
+	}
+	return bufs, nil
+}
+
+func (h *Host) insertAccountBlocksSuccess(vmBlocks []*vm_context.VmAccountBlock) {
+	blocks := make([]*ledger.AccountBlock, 0, len(vmBlocks))
+	for _, vmBlock := range vmBlocks {
+		blocks = append(blocks, vmBlock.AccountBlock)
+	}
+	bufs, err := serializeAccountBlocks(blocks)
+	if err != nil {
+		h.log.Error("serialize account blocks failed, error is "+err.Error(), "method", "insertAccountBlocksSuccess")
+		return
+	}
+	h.broadcast("InsertAccountBlocks", bufs)
+}
+
+func (h *Host) deleteAccountBlocksSuccess(subLedger map[types.Address][]*ledger.AccountBlock) {
+	var blocks []*ledger.AccountBlock
+	for _, accountBlocks := range subLedger {
+		blocks = append(blocks, accountBlocks...)
+	}
+	bufs, err := serializeAccountBlocks(blocks)
+	if err != nil {
+		h.log.Error("serialize account blocks failed, error is "+err.Error(), "method", "deleteAccountBlocksSuccess")
+		return
+	}
+	h.broadcast("DeleteAccountBlocks", bufs)
+}
+
+func (h *Host) insertSnapshotBlocksSuccess(blocks []*ledger.SnapshotBlock) {
+	bufs, err := serializeSnapshotBlocks(blocks)
+	if err != nil {
+		h.log.Error("serialize snapshot blocks failed, error is "+err.Error(), "method", "insertSnapshotBlocksSuccess")
+		return
+	}
+	h.broadcast("InsertSnapshotBlocks", bufs)
+}
+
+func (h *Host) deleteSnapshotBlocksSuccess(blocks []*ledger.SnapshotBlock) {
+	bufs, err := serializeSnapshotBlocks(blocks)
+	if err != nil {
+		h.log.Error("serialize snapshot blocks failed, error is "+err.Error(), "method", "deleteSnapshotBlocksSuccess")
+		return
+	}
+	h.broadcast("DeleteSnapshotBlocks", bufs)
+}
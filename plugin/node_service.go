@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"errors"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// The blocks are passed between the node and the plugins as their Serialize bytes.
+
+type Empty struct{}
+
+type BlockReply struct {
+	// Block is nil if the block doesn't exist
+	Block []byte
+}
+
+type BlocksReply struct {
+	Blocks [][]byte
+}
+
+type HeightArgs struct {
+	Addr  types.Address
+	Start uint64
+	Count uint64
+}
+
+type SendArgs struct {
+	Block []byte
+}
+
+// SubmitFunc verifies a signed account block and adds it to the pool
+type SubmitFunc func(block *ledger.AccountBlock) error
+
+// NodeService is the rpc service of the node the plugins call, it's registered as "Node".
+type NodeService struct {
+	chain  chain.Chain
+	submit SubmitFunc
+}
+
+func NewNodeService(c chain.Chain, submit SubmitFunc) *NodeService {
+	return &NodeService{chain: c, submit: submit}
+}
+
+func (s *NodeService) GetLatestSnapshotBlock(args *Empty, reply *BlockReply) error {
+	block := s.chain.GetLatestSnapshotBlock()
+	if block == nil {
+		return nil
+	}
+	var err error
+	reply.Block, err = block.Serialize()
+	return err
+}
+
+func (s *NodeService) GetSnapshotBlockByHeight(height *uint64, reply *BlockReply) error {
+	block, err := s.chain.GetSnapshotBlockByHeight(*height)
+	if err != nil || block == nil {
+		return err
+	}
+	reply.Block, err = block.Serialize()
+	return err
+}
+
+func (s *NodeService) GetAccountBlockByHash(hash *types.Hash, reply *BlockReply) error {
+	block, err := s.chain.GetAccountBlockByHash(hash)
+	if err != nil || block == nil {
+		return err
+	}
+	reply.Block, err = block.Serialize()
+	return err
+}
+
+// GetAccountBlocksByHeight returns at most Count blocks of Addr from the height Start upwards.
+func (s *NodeService) GetAccountBlocksByHeight(args *HeightArgs, reply *BlocksReply) error {
+	blocks, err := s.chain.GetAccountBlocksByHeight(args.Addr, args.Start, args.Count, true)
+	if err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		buf, err := block.Serialize()
+		if err != nil {
+			return err
+		}
+		reply.Blocks = append(reply.Blocks, buf)
+	}
+	return nil
+}
+
+// SendRawBlock submits an account block signed by the plugin, as tx_sendRawTx does, and returns its hash.
+func (s *NodeService) SendRawBlock(args *SendArgs, reply *types.Hash) error {
+	if len(args.Block) == 0 {
+		return errors.New("empty block")
+	}
+	block := &ledger.AccountBlock{}
+	if err := block.Deserialize(args.Block); err != nil {
+		return err
+	}
+	if err := s.submit(block); err != nil {
+		return err
+	}
+	*reply = block.Hash
+	return nil
+}
@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"math/big"
+	"net"
+	"net/rpc"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestParseHandshake(t *testing.T) {
+	h := &handshake{version: ProtocolVersion, network: "tcp", addr: "127.0.0.1:4000"}
+	parsed, err := parseHandshake(h.String() + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *parsed != *h {
+		t.Fatalf("unexpected handshake %v", parsed)
+	}
+
+	for _, line := range []string{"", "1|tcp", "x|tcp|127.0.0.1:4000", "2|tcp|127.0.0.1:4000", "1|unix|/tmp/plugin", "1|tcp|"} {
+		if _, err := parseHandshake(line); err == nil {
+			t.Fatalf("handshake %q should be invalid", line)
+		}
+	}
+}
+
+type testPlugin struct {
+	inserted []*ledger.AccountBlock
+	deleted  []*ledger.SnapshotBlock
+	shutdown bool
+}
+
+func (p *testPlugin) Init(node *NodeClient) error { return nil }
+func (p *testPlugin) OnInsertAccountBlocks(blocks []*ledger.AccountBlock) {
+	p.inserted = append(p.inserted, blocks...)
+}
+func (p *testPlugin) OnDeleteAccountBlocks(blocks []*ledger.AccountBlock)   {}
+func (p *testPlugin) OnInsertSnapshotBlocks(blocks []*ledger.SnapshotBlock) {}
+func (p *testPlugin) OnDeleteSnapshotBlocks(blocks []*ledger.SnapshotBlock) {
+	p.deleted = append(p.deleted, blocks...)
+}
+func (p *testPlugin) Shutdown() { p.shutdown = true }
+
+func newTestAccountBlock(height uint64, data string) *ledger.AccountBlock {
+	now := time.Unix(1550000000, 0)
+	return &ledger.AccountBlock{
+		BlockType: ledger.BlockTypeSendCall,
+		Height:    height,
+		Hash:      types.DataHash([]byte(data)),
+		Amount:    big.NewInt(1),
+		Fee:       big.NewInt(0),
+		Timestamp: &now,
+	}
+}
+
+func newTestClient(t *testing.T, name string, service interface{}) *rpc.Client {
+	server := rpc.NewServer()
+	if err := server.RegisterName(name, service); err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	go server.ServeConn(serverConn)
+	return rpc.NewClient(clientConn)
+}
+
+func TestPluginService(t *testing.T) {
+	p := &testPlugin{}
+	service := NewPluginService(p, nil)
+	client := newTestClient(t, "Plugin", service)
+	defer client.Close()
+
+	if err := client.Call("Plugin.Init", &Empty{}, &Empty{}); err != nil {
+		t.Fatal(err)
+	}
+
+	accountBlock := newTestAccountBlock(2, "account")
+	bufs, err := serializeAccountBlocks([]*ledger.AccountBlock{accountBlock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call("Plugin.InsertAccountBlocks", &EventArgs{Blocks: bufs}, &Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.inserted) != 1 || p.inserted[0].Hash != accountBlock.Hash || p.inserted[0].Height != 2 {
+		t.Fatalf("unexpected inserted blocks %v", p.inserted)
+	}
+
+	now := time.Unix(1550000000, 0)
+	snapshotBlock := &ledger.SnapshotBlock{Height: 3, Hash: types.DataHash([]byte("snapshot")), Timestamp: &now}
+	bufs, err = serializeSnapshotBlocks([]*ledger.SnapshotBlock{snapshotBlock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Call("Plugin.DeleteSnapshotBlocks", &EventArgs{Blocks: bufs}, &Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.deleted) != 1 || p.deleted[0].Hash != snapshotBlock.Hash {
+		t.Fatalf("unexpected deleted blocks %v", p.deleted)
+	}
+
+	if err := client.Call("Plugin.Shutdown", &Empty{}, &Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-service.done:
+	default:
+		t.Fatal("the plugin should be done")
+	}
+	if !p.shutdown {
+		t.Fatal("the plugin should be shut down")
+	}
+}
+
+func TestNodeService_SendRawBlock(t *testing.T) {
+	var submitted *ledger.AccountBlock
+	service := NewNodeService(nil, func(block *ledger.AccountBlock) error {
+		submitted = block
+		return nil
+	})
+	node := NewNodeClient(newTestClient(t, "Node", service))
+	defer node.Close()
+
+	block := newTestAccountBlock(5, "send")
+	hash, err := node.SendRawBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != block.Hash || submitted == nil || submitted.Height != 5 {
+		t.Fatalf("unexpected submitted block %v, hash %v", submitted, hash)
+	}
+}
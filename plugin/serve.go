@@ -0,0 +1,226 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"os"
+	"sync"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// Plugin is implemented by the plugin programs. The events are delivered in order, one at a time, the
+// blocks deleted are the blocks rolled back.
+type Plugin interface {
+	Init(node *NodeClient) error
+	OnInsertAccountBlocks(blocks []*ledger.AccountBlock)
+	OnDeleteAccountBlocks(blocks []*ledger.AccountBlock)
+	OnInsertSnapshotBlocks(blocks []*ledger.SnapshotBlock)
+	OnDeleteSnapshotBlocks(blocks []*ledger.SnapshotBlock)
+	Shutdown()
+}
+
+type EventArgs struct {
+	Blocks [][]byte
+}
+
+// NodeClient calls the node service of the host.
+type NodeClient struct {
+	client *rpc.Client
+}
+
+func NewNodeClient(client *rpc.Client) *NodeClient {
+	return &NodeClient{client: client}
+}
+
+func (n *NodeClient) Close() error {
+	return n.client.Close()
+}
+
+func (n *NodeClient) GetLatestSnapshotBlock() (*ledger.SnapshotBlock, error) {
+	reply := &BlockReply{}
+	if err := n.client.Call("Node.GetLatestSnapshotBlock", &Empty{}, reply); err != nil {
+		return nil, err
+	}
+	return deserializeSnapshotBlock(reply.Block)
+}
+
+func (n *NodeClient) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	reply := &BlockReply{}
+	if err := n.client.Call("Node.GetSnapshotBlockByHeight", &height, reply); err != nil {
+		return nil, err
+	}
+	return deserializeSnapshotBlock(reply.Block)
+}
+
+func (n *NodeClient) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	reply := &BlockReply{}
+	if err := n.client.Call("Node.GetAccountBlockByHash", &hash, reply); err != nil {
+		return nil, err
+	}
+	return deserializeAccountBlock(reply.Block)
+}
+
+func (n *NodeClient) GetAccountBlocksByHeight(addr types.Address, start uint64, count uint64) ([]*ledger.AccountBlock, error) {
+	reply := &BlocksReply{}
+	if err := n.client.Call("Node.GetAccountBlocksByHeight", &HeightArgs{Addr: addr, Start: start, Count: count}, reply); err != nil {
+		return nil, err
+	}
+	return deserializeAccountBlocks(reply.Blocks)
+}
+
+// SendRawBlock submits an account block signed by the plugin and returns its hash.
+func (n *NodeClient) SendRawBlock(block *ledger.AccountBlock) (types.Hash, error) {
+	buf, err := block.Serialize()
+	if err != nil {
+		return types.Hash{}, err
+	}
+	var hash types.Hash
+	err = n.client.Call("Node.SendRawBlock", &SendArgs{Block: buf}, &hash)
+	return hash, err
+}
+
+func deserializeAccountBlock(buf []byte) (*ledger.AccountBlock, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	block := &ledger.AccountBlock{}
+	if err := block.Deserialize(buf); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func deserializeAccountBlocks(bufs [][]byte) ([]*ledger.AccountBlock, error) {
+	blocks := make([]*ledger.AccountBlock, 0, len(bufs))
+	for _, buf := range bufs {
+		block := &ledger.AccountBlock{}
+		if err := block.Deserialize(buf); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func deserializeSnapshotBlock(buf []byte) (*ledger.SnapshotBlock, error) {
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	block := &ledger.SnapshotBlock{}
+	if err := block.Deserialize(buf); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func deserializeSnapshotBlocks(bufs [][]byte) ([]*ledger.SnapshotBlock, error) {
+	blocks := make([]*ledger.SnapshotBlock, 0, len(bufs))
+	for _, buf := range bufs {
+		block := &ledger.SnapshotBlock{}
+		if err := block.Deserialize(buf); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// PluginService is the rpc service of a plugin the host calls, it's registered as "Plugin".
+type PluginService struct {
+	plugin Plugin
+	node   *NodeClient
+	done   chan struct{}
+	once   sync.Once
+}
+
+func NewPluginService(p Plugin, node *NodeClient) *PluginService {
+	return &PluginService{plugin: p, node: node, done: make(chan struct{})}
+}
+
+func (s *PluginService) Init(args *Empty, reply *Empty) error {
+	return s.plugin.Init(s.node)
+}
+
+func (s *PluginService) InsertAccountBlocks(args *EventArgs, reply *Empty) error {
+	blocks, err := deserializeAccountBlocks(args.Blocks)
+	if err != nil {
+		return err
+	}
+	s.plugin.OnInsertAccountBlocks(blocks)
+	return nil
+}
+
+func (s *PluginService) DeleteAccountBlocks(args *EventArgs, reply *Empty) error {
+	blocks, err := deserializeAccountBlocks(args.Blocks)
+	if err != nil {
+		return err
+	}
+	s.plugin.OnDeleteAccountBlocks(blocks)
+	return nil
+}
+
+func (s *PluginService) InsertSnapshotBlocks(args *EventArgs, reply *Empty) error {
+	blocks, err := deserializeSnapshotBlocks(args.Blocks)
+	if err != nil {
+		return err
+	}
+	s.plugin.OnInsertSnapshotBlocks(blocks)
+	return nil
+}
+
+func (s *PluginService) DeleteSnapshotBlocks(args *EventArgs, reply *Empty) error {
+	blocks, err := deserializeSnapshotBlocks(args.Blocks)
+	if err != nil {
+		return err
+	}
+	s.plugin.OnDeleteSnapshotBlocks(blocks)
+	return nil
+}
+
+func (s *PluginService) Shutdown(args *Empty, reply *Empty) error {
+	s.plugin.Shutdown()
+	s.once.Do(func() { close(s.done) })
+	return nil
+}
+
+// Serve is called by the main function of a plugin program. It connects p to the node which started the
+// program and returns when the node shuts the plugin down.
+func Serve(p Plugin) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		fmt.Fprintln(os.Stderr, ErrNotPlugin.Error())
+		return ErrNotPlugin
+	}
+	nodeAddr := os.Getenv(NodeAddrKey)
+	if nodeAddr == "" {
+		return errors.New("the address of the node service is missing")
+	}
+	client, err := rpc.Dial("tcp", nodeAddr)
+	if err != nil {
+		return err
+	}
+	node := NewNodeClient(client)
+	defer node.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	service := NewPluginService(p, node)
+	server := rpc.NewServer()
+	if err := server.RegisterName("Plugin", service); err != nil {
+		return err
+	}
+	go server.Accept(listener)
+
+	h := &handshake{version: ProtocolVersion, network: "tcp", addr: listener.Addr().String()}
+	fmt.Fprintln(os.Stdout, h.String())
+
+	<-service.done
+	return nil
+}
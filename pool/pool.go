@@ -11,7 +11,7 @@ import (
 	"sync/atomic"
 
 	"github.com/pkg/errors"
-	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/profile"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
@@ -281,10 +281,10 @@ func (self *pool) Start() {
 	self.pendingSc.Start()
 	self.log.Info("pool account parallel.", "parallel", ACCOUNT_PARALLEL)
 	for i := 0; i < ACCOUNT_PARALLEL; i++ {
-		common.Go(self.loopTryInsert)
+		profile.Go(profile.ModulePool, self.loopTryInsert)
 	}
-	common.Go(self.loopCompact)
-	common.Go(self.loopBroadcastAndDel)
+	profile.Go(profile.ModulePool, self.loopCompact)
+	profile.Go(profile.ModulePool, self.loopBroadcastAndDel)
 }
 func (self *pool) Stop() {
 	self.log.Info("pool stop.")
@@ -5,7 +5,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/profile"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
@@ -391,8 +391,8 @@ L:
 }
 func (self *snapshotPool) Start() {
 	self.closed = make(chan struct{})
-	common.Go(self.loop)
-	common.Go(self.loopCheckFork)
+	profile.Go(profile.ModulePool, self.loop)
+	profile.Go(profile.ModulePool, self.loopCheckFork)
 	self.log.Info("snapshot_pool started.")
 }
 func (self *snapshotPool) Stop() {
@@ -0,0 +1,44 @@
+package pow
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+// Provider calculates the PoW nonces of the blocks which need extra quota.
+type Provider interface {
+	// GetPowNonce returns a nonce which makes data + nonce reach the target of difficulty, data = Hash(address + prehash)
+	GetPowNonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error)
+}
+
+// LocalProvider calculates the nonces with the CPU of the node.
+type LocalProvider struct{}
+
+func (LocalProvider) GetPowNonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	return GetPowNonce(difficulty, dataHash)
+}
+
+var (
+	provider     Provider = LocalProvider{}
+	providerLock sync.RWMutex
+)
+
+// SetProvider replaces the provider of the nonces GenerateNonce returns, the CPU of the node if p is nil.
+func SetProvider(p Provider) {
+	if p == nil {
+		p = LocalProvider{}
+	}
+	providerLock.Lock()
+	provider = p
+	providerLock.Unlock()
+}
+
+// GenerateNonce returns a nonce of the provider set by SetProvider.
+func GenerateNonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	providerLock.RLock()
+	p := provider
+	providerLock.RUnlock()
+	return p.GetPowNonce(difficulty, dataHash)
+}
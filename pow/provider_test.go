@@ -0,0 +1,31 @@
+package pow_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/pow"
+)
+
+type fixedProvider []byte
+
+func (p fixedProvider) GetPowNonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	return p, nil
+}
+
+func TestSetProvider(t *testing.T) {
+	pow.SetProvider(fixedProvider{1, 2, 3})
+	defer pow.SetProvider(nil)
+	if nonce, err := pow.GenerateNonce(big.NewInt(1), types.Hash{}); err != nil || string(nonce) != string([]byte{1, 2, 3}) {
+		t.Fatalf("the nonce should be generated by the provider set, got %v, %v", nonce, err)
+	}
+
+	pow.SetProvider(nil)
+	difficulty := big.NewInt(1000)
+	dataHash := types.DataHash([]byte("local"))
+	nonce, err := pow.GenerateNonce(difficulty, dataHash)
+	if err != nil || !pow.CheckPowNonce(difficulty, nonce, dataHash.Bytes()) {
+		t.Fatalf("the local provider should calculate a valid nonce, got %v, %v", nonce, err)
+	}
+}
@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"encoding/binary"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/pow"
+)
+
+const (
+	DefaultProviderTimeout = 30 * time.Second
+	DefaultProviderWorkers = 4
+)
+
+var (
+	ErrProviderQueueFull = errors.New("pow server queue is full")
+	ErrProviderTimeout   = errors.New("pow server timeout")
+	errInvalidWork       = errors.New("pow server returns an invalid work")
+)
+
+// ProviderConfig configures a Provider of the pow server at Url.
+type ProviderConfig struct {
+	Url string
+	// Timeout bounds both the wait for a worker and the request to the pow server, DefaultProviderTimeout if 0
+	Timeout time.Duration
+	// Workers is the count of the concurrent requests to the pow server, DefaultProviderWorkers if 0
+	Workers int
+	// QueueSize is the count of the nonces waiting for a worker, more nonces fail with ErrProviderQueueFull
+	QueueSize int
+}
+
+// Provider offloads the nonces to a pow server over http, so that they are calculated by the GPUs of the server
+// instead of the CPU of the node.
+type Provider struct {
+	url     string
+	timeout time.Duration
+	client  *http.Client
+	workers chan struct{}
+	queue   chan struct{}
+}
+
+func NewProvider(cfg ProviderConfig) *Provider {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultProviderTimeout
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultProviderWorkers
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+	return &Provider{
+		url:     cfg.Url,
+		timeout: cfg.Timeout,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		workers: make(chan struct{}, cfg.Workers),
+		queue:   make(chan struct{}, cfg.Workers+cfg.QueueSize),
+	}
+}
+
+func (p *Provider) GetPowNonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	if pow.VMTestParamEnabled {
+		return pow.GetPowNonce(nil, dataHash)
+	}
+	if difficulty == nil {
+		return nil, errors.New("difficulty can't be nil")
+	}
+
+	select {
+	case p.queue <- struct{}{}:
+		defer func() { <-p.queue }()
+	default:
+		return nil, ErrProviderQueueFull
+	}
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	select {
+	case p.workers <- struct{}{}:
+		defer func() { <-p.workers }()
+	case <-timer.C:
+		return nil, ErrProviderTimeout
+	}
+
+	work, err := generateWork(p.client, p.url, dataHash.Bytes(), difficulty)
+	if err != nil {
+		if err, ok := errors.Cause(err).(interface{ Timeout() bool }); ok && err.Timeout() {
+			// stop the server calculating a nonce nobody waits for
+			go cancelWork(p.client, p.url, dataHash.Bytes())
+			return nil, ErrProviderTimeout
+		}
+		return nil, err
+	}
+	nonce, err := WorkToNonce(*work)
+	if err != nil {
+		return nil, err
+	}
+	if !pow.CheckPowNonce(difficulty, nonce, dataHash.Bytes()) {
+		return nil, errInvalidWork
+	}
+	return nonce, nil
+}
+
+// WorkToNonce converts the hex work of the pow server to the nonce of a block.
+func WorkToNonce(work string) ([]byte, error) {
+	nonceBig, ok := new(big.Int).SetString(work, 16)
+	if !ok {
+		return nil, errors.New("wrong nonce str")
+	}
+	nonce := make([]byte, 8)
+	binary.LittleEndian.PutUint64(nonce, nonceBig.Uint64())
+	return nonce, nil
+}
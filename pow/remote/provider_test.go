@@ -0,0 +1,82 @@
+package remote
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/pow"
+)
+
+// newTestPowServer returns a pow server calculating the nonces of difficulty on the CPU after the requests are
+// released, or replying work if it is not empty
+func newTestPowServer(difficulty *big.Int, work string, release <-chan struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ApiActionCancel {
+			json.NewEncoder(w).Encode(&ResponseJson{Data: workCancelResult{}})
+			return
+		}
+		if release != nil {
+			<-release
+		}
+		req := &workGenerate{}
+		json.NewDecoder(r.Body).Decode(req)
+		if work == "" {
+			dataHash, _ := types.HexToHash(req.DataHash)
+			nonce, _ := pow.GetPowNonce(difficulty, dataHash)
+			work = strconv.FormatUint(binary.LittleEndian.Uint64(nonce), 16)
+		}
+		json.NewEncoder(w).Encode(&ResponseJson{Data: &workGenerateResult{Work: work}})
+	}))
+}
+
+func TestProvider(t *testing.T) {
+	difficulty := big.NewInt(1000)
+	dataHash := types.DataHash([]byte("remote"))
+
+	server := newTestPowServer(difficulty, "", nil)
+	defer server.Close()
+	nonce, err := NewProvider(ProviderConfig{Url: server.URL}).GetPowNonce(difficulty, dataHash)
+	if err != nil || !pow.CheckPowNonce(difficulty, nonce, dataHash.Bytes()) {
+		t.Fatalf("the remote provider should return a valid nonce, got %v, %v", nonce, err)
+	}
+
+	invalidServer := newTestPowServer(difficulty, "zz", nil)
+	defer invalidServer.Close()
+	if _, err := NewProvider(ProviderConfig{Url: invalidServer.URL}).GetPowNonce(difficulty, dataHash); err == nil {
+		t.Fatal("an invalid work of the pow server should fail")
+	}
+}
+
+func TestProvider_QueueAndTimeout(t *testing.T) {
+	difficulty := big.NewInt(1000)
+	dataHash := types.DataHash([]byte("remote"))
+	release := make(chan struct{})
+	server := newTestPowServer(difficulty, "", release)
+	defer server.Close()
+	defer close(release)
+
+	provider := NewProvider(ProviderConfig{Url: server.URL, Timeout: 200 * time.Millisecond, Workers: 1, QueueSize: 1})
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := provider.GetPowNonce(difficulty, dataHash)
+			errs <- err
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := provider.GetPowNonce(difficulty, dataHash); err != ErrProviderQueueFull {
+		t.Fatalf("the nonce beyond the workers and the queue should fail, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != ErrProviderTimeout {
+			t.Fatalf("the nonce not calculated in time should fail, got %v", err)
+		}
+	}
+}
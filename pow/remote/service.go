@@ -29,6 +29,10 @@ func InitRawUrl(rawurl string) {
 }
 
 func GenerateWork(dataHash []byte, difficulty *big.Int) (*string, error) {
+	return generateWork(&http.Client{}, requestUrl, dataHash, difficulty)
+}
+
+func generateWork(client *http.Client, url string, dataHash []byte, difficulty *big.Int) (*string, error) {
 	threshold := pow.DifficultyToTarget(difficulty)
 	wg := &workGenerate{
 		Threshold: threshold.Text(16),
@@ -39,7 +43,7 @@ func GenerateWork(dataHash []byte, difficulty *big.Int) (*string, error) {
 		return nil, err
 	}
 	workResult := &workGenerateResult{}
-	if err := httpRequest(requestUrl+ApiActionGenerate, bytesData, workResult); err != nil {
+	if err := httpRequest(client, url+ApiActionGenerate, bytesData, workResult); err != nil {
 		return nil, err
 	}
 
@@ -47,6 +51,10 @@ func GenerateWork(dataHash []byte, difficulty *big.Int) (*string, error) {
 }
 
 func CancelWork(dataHash []byte) error {
+	return cancelWork(&http.Client{}, requestUrl, dataHash)
+}
+
+func cancelWork(client *http.Client, url string, dataHash []byte) error {
 	wg := &workCancel{
 		DataHash: hex.EncodeToString(dataHash),
 	}
@@ -54,7 +62,7 @@ func CancelWork(dataHash []byte) error {
 	if err != nil {
 		return err
 	}
-	if err := httpRequest(requestUrl+ApiActionCancel, bytesData, workCancelResult{}); err != nil {
+	if err := httpRequest(client, url+ApiActionCancel, bytesData, workCancelResult{}); err != nil {
 		return err
 	}
 	return nil
@@ -72,7 +80,7 @@ func VaildateWork(dataHash []byte, threshold *big.Int, work []byte) (bool, error
 		return false, err
 	}
 	validateResult := &workValidateResult{}
-	if err := httpRequest(requestUrl+ApiActionValidate, bytesData, validateResult); err != nil {
+	if err := httpRequest(&http.Client{}, requestUrl+ApiActionValidate, bytesData, validateResult); err != nil {
 		return false, err
 	}
 	if validateResult.Valid == "1" {
@@ -82,13 +90,12 @@ func VaildateWork(dataHash []byte, threshold *big.Int, work []byte) (bool, error
 	}
 }
 
-func httpRequest(requestPath string, bytesData []byte, responseInterface interface{}) error {
+func httpRequest(client *http.Client, requestPath string, bytesData []byte, responseInterface interface{}) error {
 	req, err := http.NewRequest("POST", requestPath, bytes.NewReader(bytesData))
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
 		return err
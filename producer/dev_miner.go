@@ -0,0 +1,86 @@
+package producer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/consensus"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// DevMinerInterval is the seconds between the snapshot blocks the DevMiner produces, unless the time is set
+const DevMinerInterval = time.Second
+
+// DevMiner produces the snapshot blocks of a dev network on demand, so that the integration tests of the dapps
+// advance the chain deterministically. Its clock stands still: every block is DevMinerInterval after the
+// previous one, unless the time of the next block is set.
+type DevMiner struct {
+	worker *worker
+	lock   sync.RWMutex // guards next only, it is not held while a block is produced
+	next   *time.Time   // the timestamp of the next block, nil for DevMinerInterval after the head
+}
+
+func newDevMiner(worker *worker) *DevMiner {
+	return &DevMiner{worker: worker}
+}
+
+// Coinbase returns the producer of the snapshot blocks
+func (m *DevMiner) Coinbase() types.Address {
+	return m.worker.coinbase.Address
+}
+
+// Now returns the timestamp of the next snapshot block
+func (m *DevMiner) Now() time.Time {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if m.next != nil {
+		return *m.next
+	}
+	return m.worker.tools.chain.GetLatestSnapshotBlock().Timestamp.Add(DevMinerInterval)
+}
+
+// SetTime sets the timestamp of the next snapshot block, which must be after the latest one.
+func (m *DevMiner) SetTime(t time.Time) error {
+	m.worker.mu.Lock()
+	defer m.worker.mu.Unlock()
+
+	head := m.worker.tools.chain.GetLatestSnapshotBlock()
+	if !t.After(*head.Timestamp) {
+		return fmt.Errorf("time %s is not after the latest snapshot block %d at %s", t, head.Height, head.Timestamp)
+	}
+	m.lock.Lock()
+	m.next = &t
+	m.lock.Unlock()
+	return nil
+}
+
+// Mine produces count snapshot blocks with all the account blocks not snapshotted yet, and returns them.
+func (m *DevMiner) Mine(count uint64) ([]*ledger.SnapshotBlock, error) {
+	m.worker.mu.Lock()
+	defer m.worker.mu.Unlock()
+
+	if err := m.worker.tools.checkAddressLock(m.worker.coinbase.Address, m.worker.coinbase); err != nil {
+		return nil, errors.Wrap(err, "coinbase must be unlocked")
+	}
+
+	blocks := make([]*ledger.SnapshotBlock, 0, count)
+	for i := uint64(0); i < count; i++ {
+		e := &consensus.Event{
+			Gid:       types.SNAPSHOT_GID,
+			Address:   m.worker.coinbase.Address,
+			Timestamp: m.Now(),
+		}
+		block, err := m.worker.generateAndInsert(e)
+		if err != nil {
+			return blocks, err
+		}
+		m.lock.Lock()
+		m.next = nil
+		m.lock.Unlock()
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
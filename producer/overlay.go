@@ -0,0 +1,100 @@
+package producer
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// accountOverlay is an in-memory copy-on-write view over the unconfirmed account chains,
+// the snapshot content is assembled speculatively on it. Accounts which can't be snapshotted
+// are dropped from the overlay only, the canonical chain is not touched while packing.
+type accountOverlay struct {
+	content     ledger.SnapshotContent
+	dropped     map[types.Address]*ledger.HashHeight
+	unconfirmed map[types.Address][]*ledger.AccountBlock
+	load        func(addr *types.Address) []*ledger.AccountBlock
+}
+
+func newAccountOverlay(content ledger.SnapshotContent, load func(addr *types.Address) []*ledger.AccountBlock) *accountOverlay {
+	cpy := make(ledger.SnapshotContent, len(content))
+	for k, v := range content {
+		cpy[k] = &ledger.HashHeight{Hash: v.Hash, Height: v.Height}
+	}
+	return &accountOverlay{
+		content:     cpy,
+		dropped:     make(map[types.Address]*ledger.HashHeight),
+		unconfirmed: make(map[types.Address][]*ledger.AccountBlock),
+		load:        load,
+	}
+}
+
+// drop removes addr from the snapshot content, hashH is the first block of addr to be rolled back.
+func (self *accountOverlay) drop(addr types.Address, hashH *ledger.HashHeight) {
+	delete(self.content, addr)
+	self.dropped[addr] = hashH
+}
+
+// unconfirmedBlocks returns the unconfirmed blocks of addr ordered by height ascending.
+func (self *accountOverlay) unconfirmedBlocks(addr types.Address) []*ledger.AccountBlock {
+	blocks, ok := self.unconfirmed[addr]
+	if !ok {
+		blocks = self.load(&addr)
+		self.unconfirmed[addr] = blocks
+	}
+	return blocks
+}
+
+// resolve truncates the accounts which receive from blocks left out of the snapshot content,
+// until every receive block in the content refers to a send block which is confirmed too.
+func (self *accountOverlay) resolve() {
+	if len(self.dropped) == 0 {
+		return
+	}
+	for {
+		excluded := self.excludedBlocks()
+		changed := false
+		for addr, hashH := range self.content {
+			blocks := self.unconfirmedBlocks(addr)
+			for i, b := range blocks {
+				if b.Height > hashH.Height {
+					break
+				}
+				if !b.IsReceiveBlock() || !excluded[b.FromBlockHash] {
+					continue
+				}
+				if i == 0 {
+					delete(self.content, addr)
+				} else {
+					self.content[addr] = &ledger.HashHeight{Hash: blocks[i-1].Hash, Height: blocks[i-1].Height}
+				}
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return
+		}
+	}
+}
+
+// excludedBlocks returns the hashes of the unconfirmed blocks which are not in the snapshot content.
+func (self *accountOverlay) excludedBlocks() map[types.Hash]bool {
+	excluded := make(map[types.Hash]bool)
+	for addr := range self.dropped {
+		for _, b := range self.unconfirmedBlocks(addr) {
+			excluded[b.Hash] = true
+		}
+	}
+	for addr, blocks := range self.unconfirmed {
+		if _, ok := self.dropped[addr]; ok {
+			continue
+		}
+		hashH, ok := self.content[addr]
+		for _, b := range blocks {
+			if !ok || b.Height > hashH.Height {
+				excluded[b.Hash] = true
+			}
+		}
+	}
+	return excluded
+}
@@ -0,0 +1,57 @@
+package producer
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestAccountOverlay_resolve(t *testing.T) {
+	addrA, _, _ := types.CreateAddress()
+	addrB, _, _ := types.CreateAddress()
+	addrC, _, _ := types.CreateAddress()
+
+	newBlock := func(addr types.Address, height uint64, blockType byte, from types.Hash) *ledger.AccountBlock {
+		b := &ledger.AccountBlock{AccountAddress: addr, Height: height, BlockType: blockType, FromBlockHash: from}
+		b.Hash = types.DataHash(append(addr.Bytes(), byte(height)))
+		return b
+	}
+	a1 := newBlock(addrA, 1, ledger.BlockTypeSendCall, types.Hash{})
+	b1 := newBlock(addrB, 1, ledger.BlockTypeSendCall, types.Hash{})
+	b2 := newBlock(addrB, 2, ledger.BlockTypeReceive, a1.Hash)
+	c1 := newBlock(addrC, 1, ledger.BlockTypeReceive, b1.Hash)
+	c2 := newBlock(addrC, 2, ledger.BlockTypeReceive, b2.Hash)
+
+	unconfirmed := map[types.Address][]*ledger.AccountBlock{
+		addrA: {a1},
+		addrB: {b1, b2},
+		addrC: {c1, c2},
+	}
+	content := ledger.SnapshotContent{
+		addrA: {Hash: a1.Hash, Height: a1.Height},
+		addrB: {Hash: b2.Hash, Height: b2.Height},
+		addrC: {Hash: c2.Hash, Height: c2.Height},
+	}
+	overlay := newAccountOverlay(content, func(addr *types.Address) []*ledger.AccountBlock {
+		return unconfirmed[*addr]
+	})
+	overlay.drop(addrA, content[addrA])
+	overlay.resolve()
+
+	if len(content) != 3 {
+		t.Fatal("the original content should not be modified")
+	}
+	if _, ok := overlay.content[addrA]; ok {
+		t.Error("dropped account should not be in content")
+	}
+	if hashH := overlay.content[addrB]; hashH == nil || hashH.Hash != b1.Hash {
+		t.Errorf("account B should be truncated to height 1, got %+v", hashH)
+	}
+	if hashH := overlay.content[addrC]; hashH == nil || hashH.Hash != c1.Hash {
+		t.Errorf("account C should be truncated to height 1, got %+v", hashH)
+	}
+	if hashH := overlay.dropped[addrA]; hashH == nil || hashH.Hash != a1.Hash {
+		t.Errorf("unexpected dropped %+v", overlay.dropped)
+	}
+}
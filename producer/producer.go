@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/chain"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/profile"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/consensus"
 	"github.com/vitelabs/go-vite/ledger"
@@ -80,6 +81,7 @@ type producer struct {
 	accountFn            func(producerevent.AccountEvent)
 	syncState            net.SyncState
 	netSyncId            int
+	devMining            bool // the snapshot blocks are produced by DevMiner only
 }
 
 // todo syncDone
@@ -135,12 +137,14 @@ func (self *producer) Start() error {
 	snapshotId := self.coinbase.Address.String() + "_snapshot"
 	contractId := self.coinbase.Address.String() + "_contract"
 
-	self.cs.Subscribe(types.SNAPSHOT_GID, snapshotId, &self.coinbase.Address, func(e consensus.Event) {
-		mLog.Info("snapshot producer trigger.", "addr", self.coinbase.Address, "syncState", self.syncState, "e", e)
-		if self.syncState == net.Syncdone {
-			self.worker.produceSnapshot(e)
-		}
-	})
+	if !self.devMining {
+		self.cs.Subscribe(types.SNAPSHOT_GID, snapshotId, &self.coinbase.Address, func(e consensus.Event) {
+			mLog.Info("snapshot producer trigger.", "addr", self.coinbase.Address, "syncState", self.syncState, "e", e)
+			if self.syncState == net.Syncdone {
+				self.worker.produceSnapshot(e)
+			}
+		})
+	}
 	self.cs.Subscribe(types.DELEGATE_GID, contractId, &self.coinbase.Address, func(e consensus.Event) {
 		mLog.Info("contract producer trigger.", "addr", self.coinbase.Address, "syncState", self.syncState, "e", e)
 		if self.syncState == net.Syncdone {
@@ -166,7 +170,9 @@ func (self *producer) Stop() error {
 	snapshotId := self.coinbase.Address.String() + "_snapshot"
 	contractId := self.coinbase.Address.String() + "_contract"
 
-	self.cs.UnSubscribe(types.SNAPSHOT_GID, snapshotId)
+	if !self.devMining {
+		self.cs.UnSubscribe(types.SNAPSHOT_GID, snapshotId)
+	}
 	self.cs.UnSubscribe(types.DELEGATE_GID, contractId)
 
 	self.subscriber.UnsubscribeSyncStatus(self.netSyncId)
@@ -204,12 +210,19 @@ func (self *producer) producerContract(e consensus.Event) {
 			SnapshotHeight: e.SnapshotHeight,
 			SnapshotHash:   e.SnapshotHash,
 		}
-		common.Go(func() {
+		profile.Go(profile.ModuleProducer, func() {
 			fn(tmpEvent)
 		})
 	}
 }
 
+// DevMiner turns the scheduled production of the snapshot blocks off, they are produced on demand by the
+// returned DevMiner instead. The contract blocks are still produced on the schedule. It must be called before Start.
+func (self *producer) DevMiner() *DevMiner {
+	self.devMining = true
+	return newDevMiner(self.worker)
+}
+
 func (self *producer) SetAccountEventFunc(accountFn func(producerevent.AccountEvent)) {
 	self.accountFn = accountFn
 }
@@ -30,15 +30,18 @@ func (self *tools) ledgerUnLock() {
 	self.pool.UnLock()
 }
 
-func (self *tools) generateSnapshot(e *consensus.Event, coinbase *AddressContext) (*ledger.SnapshotBlock, error) {
+// generateSnapshot packs a snapshot block on an account overlay, the accounts dropped from
+// the snapshot content are returned and should be rolled back after the block is inserted.
+func (self *tools) generateSnapshot(e *consensus.Event, coinbase *AddressContext) (*ledger.SnapshotBlock, map[types.Address]*ledger.HashHeight, error) {
 	head := self.chain.GetLatestSnapshotBlock()
-	accounts, err := self.generateAccounts(head)
+	overlay, err := self.generateAccounts(head)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	accounts := overlay.content
 	trie, err := self.chain.GenStateTrie(head.StateHash, accounts)
 	if err != nil {
-		return nil, err
+		return nil, overlay.dropped, err
 	}
 	block := &ledger.SnapshotBlock{
 		PrevHash:        head.Hash,
@@ -52,20 +55,20 @@ func (self *tools) generateSnapshot(e *consensus.Event, coinbase *AddressContext
 	block.Hash = block.ComputeHash()
 	manager, err := self.wt.GetEntropyStoreManager(coinbase.EntryPath)
 	if err != nil {
-		return nil, err
+		return nil, overlay.dropped, err
 	}
 	_, key, err := manager.DeriveForIndexPath(coinbase.Index)
 	if err != nil {
-		return nil, err
+		return nil, overlay.dropped, err
 	}
 	signedData, pubkey, err := key.SignData(block.Hash.Bytes())
 
 	if err != nil {
-		return nil, err
+		return nil, overlay.dropped, err
 	}
 	block.Signature = signedData
 	block.PublicKey = pubkey
-	return block, nil
+	return block, overlay.dropped, nil
 }
 func (self *tools) insertSnapshot(block *ledger.SnapshotBlock) error {
 	defer monitor.LogTime("producer", "snapshotInsert", time.Now())
@@ -87,52 +90,30 @@ func (self *tools) checkAddressLock(address types.Address, coinbase *AddressCont
 	return self.wt.MatchAddress(coinbase.EntryPath, coinbase.Address, coinbase.Index)
 }
 
-func (self *tools) generateAccounts(head *ledger.SnapshotBlock) (ledger.SnapshotContent, error) {
+func (self *tools) generateAccounts(head *ledger.SnapshotBlock) (*accountOverlay, error) {
+	overlay := newAccountOverlay(self.chain.GetNeedSnapshotContent(), self.chain.GetUnConfirmAccountBlocks)
 
-	needSnapshotAccounts := self.chain.GetNeedSnapshotContent()
-
-	// todo get block
-	for k, b := range needSnapshotAccounts {
+	for k, b := range overlay.content {
 		hashH, err := self.sVerifier.VerifyAccountTimeout(k, b, head.Height+1)
 		if err != nil {
 			self.log.Error("account verify timeout.", "addr", k, "accHash", b.Hash, "accHeight", b.Height, "err", err)
 			if hashH != nil {
-				err := self.pool.RollbackAccountTo(k, hashH.Hash, hashH.Height)
-				if err != nil {
-					self.log.Error("account rollback err1.", "addr", k, "accHash", hashH.Hash, "accHeight", hashH.Height, "err", err)
-					return nil, err
-				}
+				overlay.drop(k, hashH)
 			} else {
-				err := self.pool.RollbackAccountTo(k, b.Hash, b.Height)
-				if err != nil {
-					self.log.Error("account rollback err2.", "addr", k, "accHash", b.Hash, "accHeight", b.Height, "err", err)
-					return nil, err
-				}
+				overlay.drop(k, b)
 			}
 		}
 	}
+	overlay.resolve()
+	return overlay, nil
+}
 
-	// todo get block
-	needSnapshotAccounts = self.chain.GetNeedSnapshotContent()
-
-	var finalAccounts = make(map[types.Address]*ledger.HashHeight)
-
-	for k, b := range needSnapshotAccounts {
-
-		errB := b
-		hashH, err := self.sVerifier.VerifyAccountTimeout(k, b, head.Height+1)
-		if hashH != nil {
-			errB = hashH
-		}
+// rollbackAccounts rolls back the accounts dropped from the snapshot content.
+func (self *tools) rollbackAccounts(dropped map[types.Address]*ledger.HashHeight) {
+	for k, hashH := range dropped {
+		err := self.pool.RollbackAccountTo(k, hashH.Hash, hashH.Height)
 		if err != nil {
-			return nil, errors.Errorf(
-				"error account block, account:%s, blockHash:%s, blockHeight:%d, err:%s",
-				k.String(),
-				errB.Hash.String(),
-				errB.Height,
-				err)
+			self.log.Error("account rollback err.", "addr", k, "accHash", hashH.Hash, "accHeight", hashH.Height, "err", err)
 		}
-		finalAccounts[k] = &ledger.HashHeight{Hash: b.Hash, Height: b.Height}
 	}
-	return finalAccounts, nil
 }
@@ -6,8 +6,9 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/profile"
 	"github.com/vitelabs/go-vite/consensus"
+	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/monitor"
 )
@@ -60,7 +61,7 @@ func (self *worker) produceSnapshot(e consensus.Event) {
 		return
 	}
 	tmpE := &e
-	common.Go(func() {
+	profile.Go(profile.ModuleProducer, func() {
 		self.genAndInsert(tmpE)
 	})
 }
@@ -72,22 +73,29 @@ func (self *worker) genAndInsert(e *consensus.Event) {
 	defer self.wg.Done()
 	self.mu.Lock()
 	defer self.mu.Unlock()
+	if _, err := self.generateAndInsert(e); err != nil {
+		wLog.Error("produce snapshot block fail.", "err", err)
+	}
+}
+
+// generateAndInsert produces the snapshot block of e, the caller must hold self.mu
+func (self *worker) generateAndInsert(e *consensus.Event) (*ledger.SnapshotBlock, error) {
 	// lock pool
 	self.tools.ledgerLock()
 	// unlock pool
 	defer self.tools.ledgerUnLock()
 
 	// generate snapshot block
-	b, err := self.tools.generateSnapshot(e, self.coinbase)
+	b, dropped, err := self.tools.generateSnapshot(e, self.coinbase)
+	// roll back the accounts which were left out of the snapshot block
+	defer self.tools.rollbackAccounts(dropped)
 	if err != nil {
-		wLog.Error("produce snapshot block fail[generate].", "err", err)
-		return
+		return nil, errors.Wrap(err, "generate")
 	}
 
 	// insert snapshot block
-	err = self.tools.insertSnapshot(b)
-	if err != nil {
-		wLog.Error("produce snapshot block fail[insert].", "err", err)
-		return
+	if err = self.tools.insertSnapshot(b); err != nil {
+		return nil, errors.Wrap(err, "insert")
 	}
+	return b, nil
 }
@@ -64,3 +64,10 @@ type shutdownError struct{}
 func (e *shutdownError) ErrorCode() int { return -32000 }
 
 func (e *shutdownError) Error() string { return "server is shutting down" }
+
+// issued when the workers of the client's qos class are all busy and its queue is full.
+type serverBusyError struct{}
+
+func (e *serverBusyError) ErrorCode() int { return -32005 }
+
+func (e *serverBusyError) Error() string { return "server is busy" }
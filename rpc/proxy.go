@@ -0,0 +1,88 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/socks"
+	"golang.org/x/net/websocket"
+)
+
+// proxyDialTimeout is longer than defaultDialTimeout, circuits of tor take long to build
+const proxyDialTimeout = 30 * time.Second
+
+// DialProxy creates a new RPC client just like DialContext, but connects to the server through the
+// SOCKS5 proxy at proxy, e.g. tor, so that the server can be an onion service. Only "http", "https",
+// "ws" and "wss" URLs are supported, the host of the URL is resolved by the proxy.
+func DialProxy(ctx context.Context, rawurl, proxy string) (*Client, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &socks.Dialer{Proxy: proxy, Timeout: proxyDialTimeout}
+	switch u.Scheme {
+	case "http", "https":
+		client := &http.Client{
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+		}
+		return DialHTTPWithClient(rawurl, client)
+	case "ws", "wss":
+		origin, err := os.Hostname()
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme == "wss" {
+			origin = "https://" + strings.ToLower(origin)
+		} else {
+			origin = "http://" + strings.ToLower(origin)
+		}
+		config, err := websocket.NewConfig(rawurl, origin)
+		if err != nil {
+			return nil, err
+		}
+		return newClient(ctx, func(ctx context.Context) (net.Conn, error) {
+			return wsDialProxy(ctx, config, dialer)
+		})
+	default:
+		return nil, fmt.Errorf("no known transport through proxy for URL scheme %q", u.Scheme)
+	}
+}
+
+func wsDialProxy(ctx context.Context, config *websocket.Config, dialer *socks.Dialer) (*websocket.Conn, error) {
+	addr := wsDialAddress(config.Location)
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Location.Scheme == "wss" {
+		tlsConfig := new(tls.Config)
+		if config.TlsConfig != nil {
+			tlsConfig = config.TlsConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName, _, _ = net.SplitHostPort(addr)
+		}
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err = tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ws, nil
+}
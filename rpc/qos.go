@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// ApiKeyHeader is the http header carrying the api key which classifies a client.
+const ApiKeyHeader = "X-Api-Key"
+
+type apiKeyKey struct{}
+
+// QosClass is the class of service of a client, every class is served by dedicated
+// workers so that priority clients don't queue behind anonymous public traffic.
+type QosClass int
+
+const (
+	QosPublic QosClass = iota
+	QosPriority
+)
+
+// QosConfig maps api keys to the priority class and sizes the workers of each class.
+// A zero worker count leaves the class unlimited, a zero queue size never rejects requests.
+type QosConfig struct {
+	PriorityApiKeys   []string
+	PriorityWorkers   int
+	PublicWorkers     int
+	PublicQueueSize   int
+	PriorityQueueSize int
+}
+
+type lane struct {
+	workers  chan struct{}
+	queued   int32
+	maxQueue int32
+}
+
+func newLane(workers, maxQueue int) *lane {
+	l := &lane{maxQueue: int32(maxQueue)}
+	if workers > 0 {
+		l.workers = make(chan struct{}, workers)
+	}
+	return l
+}
+
+// acquire waits for a free worker of the lane and returns the func to release it.
+func (l *lane) acquire(ctx context.Context) (func(), Error) {
+	if l.workers == nil {
+		return func() {}, nil
+	}
+	if queued := atomic.AddInt32(&l.queued, 1); l.maxQueue > 0 && queued > l.maxQueue {
+		atomic.AddInt32(&l.queued, -1)
+		return nil, &serverBusyError{}
+	}
+	defer atomic.AddInt32(&l.queued, -1)
+	select {
+	case l.workers <- struct{}{}:
+		return func() { <-l.workers }, nil
+	case <-ctx.Done():
+		return nil, &serverBusyError{}
+	}
+}
+
+type qos struct {
+	apiKeys map[string]QosClass
+	lanes   map[QosClass]*lane
+}
+
+func newQos(cfg QosConfig) *qos {
+	q := &qos{
+		apiKeys: make(map[string]QosClass, len(cfg.PriorityApiKeys)),
+		lanes: map[QosClass]*lane{
+			QosPublic:   newLane(cfg.PublicWorkers, cfg.PublicQueueSize),
+			QosPriority: newLane(cfg.PriorityWorkers, cfg.PriorityQueueSize),
+		},
+	}
+	for _, key := range cfg.PriorityApiKeys {
+		if key != "" {
+			q.apiKeys[key] = QosPriority
+		}
+	}
+	return q
+}
+
+// classify returns the class of the client which sent the request of ctx.
+func (q *qos) classify(ctx context.Context) QosClass {
+	if key, ok := ctx.Value(apiKeyKey{}).(string); ok {
+		if class, ok := q.apiKeys[key]; ok {
+			return class
+		}
+	}
+	return QosPublic
+}
+
+func (q *qos) acquire(ctx context.Context) (func(), Error) {
+	if q == nil {
+		return func() {}, nil
+	}
+	return q.lanes[q.classify(ctx)].acquire(ctx)
+}
+
+// SetQos enables the priority lanes of the server, it must be called before serving requests.
+func (s *Server) SetQos(cfg QosConfig) {
+	s.qos = newQos(cfg)
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQos(t *testing.T) {
+	q := newQos(QosConfig{PriorityApiKeys: []string{"internal"}, PublicWorkers: 1, PublicQueueSize: 1, PriorityWorkers: 1})
+
+	publicCtx := context.WithValue(context.Background(), apiKeyKey{}, "unknown")
+	priorityCtx := context.WithValue(context.Background(), apiKeyKey{}, "internal")
+	if q.classify(publicCtx) != QosPublic || q.classify(priorityCtx) != QosPriority || q.classify(context.Background()) != QosPublic {
+		t.Fatal("unexpected qos class")
+	}
+
+	release, err := q.acquire(publicCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the public worker is busy, the priority lane is still served
+	releasePriority, err := q.acquire(priorityCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	releasePriority()
+
+	// one public request may wait in the queue, the next one is rejected
+	queued := make(chan error)
+	go func() {
+		r, err := q.acquire(publicCtx)
+		if err == nil {
+			r()
+		}
+		queued <- err
+	}()
+	for atomic.LoadInt32(&q.lanes[QosPublic].queued) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := q.acquire(publicCtx); err == nil {
+		t.Fatal("expected server busy error")
+	}
+
+	release()
+	if err := <-queued; err != nil {
+		t.Fatal(err)
+	}
+
+	var nilQos *qos
+	if _, err := nilQos.acquire(publicCtx); err != nil {
+		t.Fatal(err)
+	}
+}
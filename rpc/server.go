@@ -21,11 +21,13 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"runtime/pprof"
 	"strings"
 	"sync"
 	"sync/atomic"
 
 	mapset "github.com/deckarep/golang-set"
+	"github.com/vitelabs/go-vite/common/profile"
 	log "github.com/vitelabs/go-vite/log15"
 )
 
@@ -137,6 +139,10 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 		s.codecsMu.Unlock()
 	}()
 
+	// label the requests served here, the goroutines executing them inherit the label
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, profile.Labels(profile.ModuleRPC)))
+	defer pprof.SetGoroutineLabels(ctx)
+
 	//	ctx, cancel := context.WithCancel(context.Background())
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -327,6 +333,13 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 
 // exec executes the given request and writes the result back using the codec.
 func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
+	release, err := s.qos.acquire(ctx)
+	if err != nil {
+		codec.Write(codec.CreateErrorResponse(&req.id, err))
+		return
+	}
+	defer release()
+
 	var response interface{}
 	var callback func()
 	if req.err != nil {
@@ -349,6 +362,17 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 // execBatch executes the given requests and writes the result back using the codec.
 // It will only write the response back when the last request is processed.
 func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*serverRequest) {
+	release, err := s.qos.acquire(ctx)
+	if err != nil {
+		responses := make([]interface{}, len(requests))
+		for i, req := range requests {
+			responses[i] = codec.CreateErrorResponse(&req.id, err)
+		}
+		codec.Write(responses)
+		return
+	}
+	defer release()
+
 	responses := make([]interface{}, len(requests))
 	var callbacks []func()
 	for i, req := range requests {
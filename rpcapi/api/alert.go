@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/vitelabs/go-vite/alert"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+var ErrAlertDisabled = errors.New("alert is not enabled")
+
+// AlertApi manages the alerting rules of the node at runtime, the rules added are not
+// persisted and are lost when the node restarts.
+type AlertApi struct {
+	engine *alert.Engine
+	log    log15.Logger
+}
+
+func NewAlertApi(engine *alert.Engine) *AlertApi {
+	return &AlertApi{
+		engine: engine,
+		log:    log15.New("module", "rpc_api/alert_api"),
+	}
+}
+
+func (a AlertApi) String() string {
+	return "AlertApi"
+}
+
+func (a *AlertApi) AddRule(rule alert.Rule) error {
+	a.log.Info("AddRule", "name", rule.Name)
+	if a.engine == nil {
+		return ErrAlertDisabled
+	}
+	return a.engine.AddRule(&rule)
+}
+
+func (a *AlertApi) RemoveRule(name string) error {
+	a.log.Info("RemoveRule", "name", name)
+	if a.engine == nil {
+		return ErrAlertDisabled
+	}
+	return a.engine.RemoveRule(name)
+}
+
+func (a *AlertApi) GetRules() ([]*alert.Rule, error) {
+	if a.engine == nil {
+		return nil, ErrAlertDisabled
+	}
+	return a.engine.Rules(), nil
+}
+
+func (a *AlertApi) GetFiringAlerts() ([]*alert.Alert, error) {
+	if a.engine == nil {
+		return nil, ErrAlertDisabled
+	}
+	return a.engine.Firing(), nil
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+// AllowanceApi serves the built-in allowance contract, which lets contracts pull the tokens deposited by users
+// up to the amounts they approve.
+type AllowanceApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewAllowanceApi(vite *vite.Vite) *AllowanceApi {
+	return &AllowanceApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/allowance_api"),
+	}
+}
+
+func (a AllowanceApi) String() string {
+	return "AllowanceApi"
+}
+
+func (a *AllowanceApi) GetBalance(owner types.Address, tokenId types.TokenTypeId) (string, error) {
+	snapshotBlock := a.chain.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(a.chain, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return *bigIntToString(abi.GetAllowanceBalance(vmContext, owner, tokenId)), nil
+}
+
+func (a *AllowanceApi) GetAllowance(owner, spender types.Address, tokenId types.TokenTypeId) (string, error) {
+	snapshotBlock := a.chain.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(a.chain, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return *bigIntToString(abi.GetAllowance(vmContext, owner, spender, tokenId)), nil
+}
+
+func (a *AllowanceApi) GetDepositData() ([]byte, error) {
+	return abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceDeposit)
+}
+
+func (a *AllowanceApi) GetWithdrawData(tokenId types.TokenTypeId, amount string) ([]byte, error) {
+	bAmount, err := stringToBigInt(&amount)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceWithdraw, tokenId, bAmount)
+}
+
+func (a *AllowanceApi) GetApproveData(spender types.Address, tokenId types.TokenTypeId, amount string) ([]byte, error) {
+	bAmount, err := stringToBigInt(&amount)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceApprove, spender, tokenId, bAmount)
+}
+
+func (a *AllowanceApi) GetTransferFromData(owner types.Address, tokenId types.TokenTypeId, amount string, to types.Address) ([]byte, error) {
+	bAmount, err := stringToBigInt(&amount)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceTransferFrom, owner, tokenId, bAmount, to)
+}
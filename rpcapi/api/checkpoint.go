@@ -0,0 +1,156 @@
+package api
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+	"math/big"
+)
+
+// CheckpointApi serves the built-in checkpoint contract, where account owners vouch for the state of their
+// accounts at a height, so that wallets can restore an account from its latest checkpoint instead of its whole chain.
+type CheckpointApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewCheckpointApi(vite *vite.Vite) *CheckpointApi {
+	return &CheckpointApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/checkpoint_api"),
+	}
+}
+
+func (c CheckpointApi) String() string {
+	return "CheckpointApi"
+}
+
+type Checkpoint struct {
+	Height    string     `json:"height"`
+	StateHash types.Hash `json:"stateHash"`
+	SendHash  types.Hash `json:"sendHash"`
+}
+
+// CheckpointRestore is what a wallet needs to restore an account from its latest checkpoint. The wallet checks
+// the signature of SendBlock by the owner, that SendBlock is included by ConfirmSnapshotBlock, and that Block
+// has the checkpointed height and state hash. The blocks after the checkpoint are read by ledger_getBlocksByHeight.
+type CheckpointRestore struct {
+	Checkpoint *Checkpoint `json:"checkpoint"`
+	// SendBlock is the block sending the checkpoint, signed by the owner
+	SendBlock *AccountBlock `json:"sendBlock"`
+	// ConfirmSnapshotBlock is the first snapshot block confirming SendBlock
+	ConfirmSnapshotBlock *ledger.SnapshotBlock `json:"confirmSnapshotBlock"`
+	// Block is the block of the owner at the checkpoint height
+	Block *AccountBlock `json:"block"`
+	// Balances are the balances of the owner at Block, nil if the state of Block is garbage collected
+	Balances map[types.TokenTypeId]string `json:"balances"`
+	// LatestHeight is the height of the latest block of the owner
+	LatestHeight string `json:"latestHeight"`
+}
+
+func (c *CheckpointApi) GetCheckpointData(height uint64, stateHash types.Hash) ([]byte, error) {
+	return abi.ABICheckpoint.PackMethod(abi.MethodNameCheckpoint, height, stateHash)
+}
+
+func (c *CheckpointApi) getCheckpoint(owner types.Address) (*abi.AccountCheckpoint, error) {
+	snapshotBlock := c.chain.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(c.chain, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return abi.GetAccountCheckpoint(vmContext, owner), nil
+}
+
+func newCheckpoint(checkpoint *abi.AccountCheckpoint) *Checkpoint {
+	return &Checkpoint{
+		Height:    uint64ToString(checkpoint.Height),
+		StateHash: checkpoint.StateHash,
+		SendHash:  checkpoint.SendHash,
+	}
+}
+
+// GetCheckpoint returns the latest checkpoint of owner, nil if owner never checkpoints.
+func (c *CheckpointApi) GetCheckpoint(owner types.Address) (*Checkpoint, error) {
+	checkpoint, err := c.getCheckpoint(owner)
+	if err != nil || checkpoint == nil {
+		return nil, err
+	}
+	return newCheckpoint(checkpoint), nil
+}
+
+// GetRestoreInfo returns the latest checkpoint of owner with the blocks proving it, nil if owner never checkpoints
+// or the checkpoint is not confirmed yet.
+func (c *CheckpointApi) GetRestoreInfo(owner types.Address) (*CheckpointRestore, error) {
+	checkpoint, err := c.getCheckpoint(owner)
+	if err != nil || checkpoint == nil {
+		return nil, err
+	}
+
+	sendBlock, err := c.chain.GetAccountBlockByHash(&checkpoint.SendHash)
+	if err != nil {
+		return nil, err
+	}
+	if sendBlock == nil {
+		return nil, errors.New("the checkpoint send block is missing")
+	}
+	confirmBlock, err := c.chain.GetConfirmBlock(&checkpoint.SendHash)
+	if err != nil || confirmBlock == nil {
+		return nil, err
+	}
+	block, err := c.chain.GetAccountBlockByHeight(&owner, checkpoint.Height)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("the checkpoint block is missing")
+	}
+	latestBlock, err := c.chain.GetLatestAccountBlock(&owner)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := &CheckpointRestore{
+		Checkpoint:           newCheckpoint(checkpoint),
+		ConfirmSnapshotBlock: confirmBlock,
+		LatestHeight:         uint64ToString(latestBlock.Height),
+	}
+	if restore.SendBlock, err = LedgerToRpcBlock(sendBlock, c.chain); err != nil {
+		return nil, err
+	}
+	if restore.Block, err = LedgerToRpcBlock(block, c.chain); err != nil {
+		return nil, err
+	}
+	if restore.Balances, err = c.getBalances(&block.StateHash); err != nil {
+		return nil, err
+	}
+	return restore, nil
+}
+
+func (c *CheckpointApi) getBalances(stateHash *types.Hash) (map[types.TokenTypeId]string, error) {
+	if ok, err := c.chain.ShallowCheckStateTrie(stateHash); err != nil || !ok {
+		return nil, err
+	}
+	trie := c.chain.GetStateTrie(stateHash)
+	if trie == nil {
+		return nil, nil
+	}
+	iterator := trie.NewIterator(vm_context.STORAGE_KEY_BALANCE)
+	balances := make(map[types.TokenTypeId]string)
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		tokenId, err := types.BytesToTokenTypeId(key[len(vm_context.STORAGE_KEY_BALANCE):])
+		if err != nil {
+			return nil, err
+		}
+		balances[tokenId] = new(big.Int).SetBytes(value).String()
+	}
+	return balances, nil
+}
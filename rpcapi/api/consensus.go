@@ -0,0 +1,149 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/consensus"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+)
+
+type ConsensusApi struct {
+	v   *vite.Vite
+	log log15.Logger
+}
+
+func NewConsensusApi(v *vite.Vite) *ConsensusApi {
+	return &ConsensusApi{
+		v:   v,
+		log: log15.New("module", "rpc_api/consensus_api"),
+	}
+}
+
+func (c ConsensusApi) String() string {
+	return "ConsensusApi"
+}
+
+type RoundSlot struct {
+	Timestamp        int64          `json:"timestamp"`
+	ExpectedProducer *types.Address `json:"expectedProducer"`
+	ActualProducer   *types.Address `json:"actualProducer"`
+	BlockHash        *types.Hash    `json:"blockHash"`
+	BlockHeight      *string        `json:"blockHeight"`
+	Missed           bool           `json:"missed"`
+}
+
+type RoundInfo struct {
+	Index     string          `json:"index"`
+	STime     int64           `json:"stime"`
+	ETime     int64           `json:"etime"`
+	Producers []types.Address `json:"producers"`
+	Slots     []*RoundSlot    `json:"slots"`
+}
+
+// GetRoundInfo returns the producer plan of the snapshot consensus group round at height
+// or timestamp(unix seconds) and the snapshot blocks actually produced in each slot.
+// height takes precedence over timestamp, the latest round is used if both are zero.
+func (c ConsensusApi) GetRoundInfo(height uint64, timestamp int64) (*RoundInfo, error) {
+	c.log.Info("GetRoundInfo", "height", height, "timestamp", timestamp)
+	ch := c.v.Chain()
+	var t time.Time
+	if height > 0 {
+		block, err := ch.GetSnapshotBlockHeadByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, errors.New("snapshot block not found")
+		}
+		t = *block.Timestamp
+	} else if timestamp > 0 {
+		t = time.Unix(timestamp, 0)
+	} else {
+		t = *ch.GetLatestSnapshotBlock().Timestamp
+	}
+
+	cs := c.v.Consensus()
+	index, err := cs.VoteTimeToIndex(types.SNAPSHOT_GID, t)
+	if err != nil {
+		return nil, err
+	}
+	events, _, err := cs.ReadByIndex(types.SNAPSHOT_GID, index)
+	if err != nil {
+		return nil, err
+	}
+	stime, etime, err := cs.VoteIndexToTime(types.SNAPSHOT_GID, index)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*ledger.SnapshotBlock
+	block, err := ch.GetSnapshotBlockBeforeTime(etime)
+	if err != nil {
+		return nil, err
+	}
+	for block != nil && !block.Timestamp.Before(*stime) {
+		blocks = append(blocks, block)
+		if block.Height <= types.GenesisHeight {
+			break
+		}
+		if block, err = ch.GetSnapshotBlockHeadByHeight(block.Height - 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return &RoundInfo{
+		Index:     strconv.FormatUint(index, 10),
+		STime:     stime.Unix(),
+		ETime:     etime.Unix(),
+		Producers: roundProducers(events),
+		Slots:     mergeRoundSlots(events, blocks),
+	}, nil
+}
+
+// roundProducers returns the producers of a round in the order of their first slot.
+func roundProducers(events []*consensus.Event) []types.Address {
+	var producers []types.Address
+	seen := make(map[types.Address]bool)
+	for _, e := range events {
+		if !seen[e.Address] {
+			seen[e.Address] = true
+			producers = append(producers, e.Address)
+		}
+	}
+	return producers
+}
+
+// mergeRoundSlots matches the planned slots with the produced blocks by timestamp,
+// a block whose timestamp isn't planned is listed as a slot without expected producer.
+func mergeRoundSlots(events []*consensus.Event, blocks []*ledger.SnapshotBlock) []*RoundSlot {
+	var slots []*RoundSlot
+	slotMap := make(map[int64]*RoundSlot, len(events))
+	for _, e := range events {
+		producer := e.Address
+		slot := &RoundSlot{Timestamp: e.Timestamp.Unix(), ExpectedProducer: &producer, Missed: true}
+		slotMap[slot.Timestamp] = slot
+		slots = append(slots, slot)
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		slot, ok := slotMap[b.Timestamp.Unix()]
+		if !ok {
+			slot = &RoundSlot{Timestamp: b.Timestamp.Unix()}
+			slots = append(slots, slot)
+		}
+		producer := b.Producer()
+		hash := b.Hash
+		blockHeight := strconv.FormatUint(b.Height, 10)
+		slot.ActualProducer = &producer
+		slot.BlockHash = &hash
+		slot.BlockHeight = &blockHeight
+		slot.Missed = slot.ExpectedProducer != nil && *slot.ExpectedProducer != producer
+	}
+	return slots
+}
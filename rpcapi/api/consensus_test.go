@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/consensus"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestMergeRoundSlots(t *testing.T) {
+	pubA, _, _ := ed25519.GenerateKeyFromD([32]byte{1})
+	pubB, _, _ := ed25519.GenerateKeyFromD([32]byte{2})
+	addrA := types.PubkeyToAddress(pubA)
+	addrB := types.PubkeyToAddress(pubB)
+
+	start := time.Unix(1000, 0)
+	var events []*consensus.Event
+	for i, addr := range []types.Address{addrA, addrA, addrB, addrB} {
+		events = append(events, &consensus.Event{Address: addr, Timestamp: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	t1 := start.Add(time.Second)
+	t2 := start.Add(2 * time.Second)
+	t3 := start.Add(3 * time.Second)
+	blocks := []*ledger.SnapshotBlock{
+		{Height: 4, Timestamp: &t3, PublicKey: pubA},
+		{Height: 3, Timestamp: &t2, PublicKey: pubB},
+		{Height: 2, Timestamp: &t1, PublicKey: pubA},
+	}
+
+	producers := roundProducers(events)
+	if len(producers) != 2 || producers[0] != addrA || producers[1] != addrB {
+		t.Fatalf("unexpected producers %v", producers)
+	}
+
+	slots := mergeRoundSlots(events, blocks)
+	if len(slots) != 4 {
+		t.Fatalf("expected 4 slots, got %v", len(slots))
+	}
+	expectedMissed := []bool{true, false, false, true}
+	for i, slot := range slots {
+		if slot.Missed != expectedMissed[i] {
+			t.Errorf("slot %v missed is %v, expected %v", i, slot.Missed, expectedMissed[i])
+		}
+	}
+	if slots[0].BlockHash != nil {
+		t.Errorf("slot 0 should have no block")
+	}
+	if *slots[3].ActualProducer != addrA || *slots[2].BlockHeight != "3" {
+		t.Errorf("unexpected slot %+v", slots[3])
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/vitelabs/go-vite/vite"
 	"github.com/vitelabs/go-vite/vm"
 	"github.com/vitelabs/go-vite/vm/abi"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
 	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_context"
 	"strings"
@@ -40,6 +42,16 @@ func (c *ContractApi) GetCreateContractToAddress(selfAddr types.Address, heightS
 	return &addr, nil
 }
 
+// GetCreateContractToAddressBySalt returns the address of the contract created by a send create block of selfAddr
+// with salt and the create contract data
+func (c *ContractApi) GetCreateContractToAddressBySalt(selfAddr types.Address, salt types.Hash, data []byte) (*types.Address, error) {
+	if len(data) <= types.GidSize {
+		return nil, errors.New("invalid create contract data")
+	}
+	addr := util.NewContractAddressBySalt(selfAddr, salt, types.DataHash(util.GetCodeFromCreateContractData(data)))
+	return &addr, nil
+}
+
 func (c *ContractApi) GetCreateContractData(gid types.Gid, hexCode string, abiStr string, params []string) ([]byte, error) {
 	code, err := hex.DecodeString(hexCode)
 	if err != nil {
@@ -112,3 +124,97 @@ func (c *ContractApi) CallOffChainMethod(param CallOffChainMethodParam) ([]byte,
 	}
 	return vm.NewVM().OffChainReader(db, param.OffChainCode, param.Data)
 }
+
+// CallOffChainParam calls the contract Addr with Data at the snapshot block SnapshotHash, or at the latest
+// snapshot block if SnapshotHash is nil.
+type CallOffChainParam struct {
+	Addr         types.Address `json:"addr"`
+	SnapshotHash *types.Hash   `json:"snapshotHash"`
+	Data         []byte        `json:"data"`
+}
+
+// CallOffChain runs a method of a contract read only against the state confirmed by a snapshot block, which
+// creates no block and consumes no quota, and returns the abi encoded result of the method.
+func (c *ContractApi) CallOffChain(param CallOffChainParam) ([]byte, error) {
+	var prevHash *types.Hash
+	if param.SnapshotHash != nil {
+		snapshotBlock, err := c.chain.GetSnapshotBlockByHash(param.SnapshotHash)
+		if err != nil {
+			return nil, err
+		}
+		if snapshotBlock == nil {
+			return nil, errors.New("snapshot block not exists")
+		}
+		prevBlock, err := c.chain.GetConfirmAccountBlock(snapshotBlock.Height, &param.Addr)
+		if err != nil {
+			return nil, err
+		}
+		if prevBlock == nil {
+			return nil, util.ErrContractNotExists
+		}
+		prevHash = &prevBlock.Hash
+	}
+	db, err := vm_context.NewVmContext(c.chain, param.SnapshotHash, prevHash, &param.Addr)
+	if err != nil {
+		return nil, err
+	}
+	return vm.NewVM().OffChainCall(db, param.Data)
+}
+
+type QuotaUsed struct {
+	Hash      types.Hash `json:"hash"`
+	Height    string     `json:"height"`
+	BlockType byte       `json:"blockType"`
+	Quota     string     `json:"quota"`
+	PoW       bool       `json:"pow"`
+}
+
+// QuotaUsedList is the quota used by the blocks of an account referring to the latest snapshot block.
+type QuotaUsedList struct {
+	SnapshotHash types.Hash   `json:"snapshotHash"`
+	List         []*QuotaUsed `json:"list"`
+	QuotaUsed    string       `json:"quotaUsed"`
+	PledgeAmount string       `json:"pledgeAmount"`
+	// QuotaLeft is the quota the next block of the account gets by pledge when it refers to the snapshot block too,
+	// CanPoW is whether the next block can get quota by PoW, only one block of List calculates PoW
+	QuotaLeft string `json:"quotaLeft"`
+	CanPoW    bool   `json:"canPoW"`
+}
+
+// GetQuotaUsedList returns the blocks of addr which used the quota of its next block referring to the latest
+// snapshot block, in descending order of height.
+func (c *ContractApi) GetQuotaUsedList(addr types.Address) (*QuotaUsedList, error) {
+	db, err := vm_context.NewVmContext(c.chain, nil, nil, &addr)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := quota.GetQuotaUsedBlocks(db)
+	if err != nil {
+		return nil, err
+	}
+	pledgeAmount := cabi.GetPledgeBeneficialAmount(db, addr)
+	quotaLeft, _, err := quota.CalcQuotaV2(db, addr, pledgeAmount, helper.Big0)
+	if err != nil && err != util.ErrOutOfQuota {
+		return nil, err
+	}
+	list := &QuotaUsedList{
+		SnapshotHash: db.CurrentSnapshotBlock().Hash,
+		List:         make([]*QuotaUsed, len(blocks)),
+		PledgeAmount: pledgeAmount.String(),
+		QuotaLeft:    uint64ToString(quotaLeft),
+		CanPoW:       quota.CanPoW(db, addr),
+	}
+	quotaUsed := uint64(0)
+	for i, block := range blocks {
+		list.List[i] = &QuotaUsed{
+			Hash:      block.Hash,
+			Height:    uint64ToString(block.Height),
+			BlockType: block.BlockType,
+			Quota:     uint64ToString(block.Quota),
+			PoW:       quota.IsPoW(block.Nonce),
+		}
+		quotaUsed += block.Quota
+	}
+	list.QuotaUsed = uint64ToString(quotaUsed)
+	return list, nil
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"math/big"
+	"strconv"
 	"time"
 
 	"github.com/vitelabs/go-vite/common/fork"
@@ -16,6 +17,8 @@ import (
 	"github.com/vitelabs/go-vite/consensus/core"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm_context"
 )
 
 type DebugApi struct {
@@ -315,3 +318,69 @@ func (api DebugApi) SetGetTestTokenLimitSize(size int) error {
 func (api DebugApi) GetForkInfo() config.ForkPoints {
 	return fork.GetForkPoints()
 }
+
+// TraceResult is the execution of the receive block ReceiveBlockHash run again with a tracer. Quota is the
+// quota the receive block uses, and SendBlockList is the blocks sent by the contract in it.
+type TraceResult struct {
+	ReceiveBlockHash types.Hash             `json:"receiveBlockHash"`
+	Quota            string                 `json:"quota"`
+	Err              string                 `json:"err,omitempty"`
+	Steps            []*vm.TraceStep        `json:"steps"`
+	Storage          []*vm.TraceStorage     `json:"storage"`
+	SendBlockList    []*ledger.AccountBlock `json:"sendBlockList"`
+}
+
+// TraceSendBlock runs the receive block of the send block hash again on the state it was received with, and
+// returns the opcodes executed, the storage written, the blocks sent and the quota used.
+func (api DebugApi) TraceSendBlock(hash types.Hash) (*TraceResult, error) {
+	c := api.v.Chain()
+	sendBlock, err := c.GetAccountBlockByHash(&hash)
+	if err != nil {
+		return nil, err
+	}
+	if sendBlock == nil || !sendBlock.IsSendBlock() {
+		return nil, errors.New("send block not exists")
+	}
+	heights, err := c.GetReceiveBlockHeights(&hash)
+	if err != nil {
+		return nil, err
+	}
+	var receiveBlock *ledger.AccountBlock
+	for _, height := range heights {
+		block, err := c.GetAccountBlockByHeight(&sendBlock.ToAddress, height)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil && block.FromBlockHash == hash {
+			receiveBlock = block
+			break
+		}
+	}
+	if receiveBlock == nil {
+		return nil, errors.New("send block not received")
+	}
+
+	db, err := vm_context.NewVmContext(c, &receiveBlock.SnapshotHash, &receiveBlock.PrevHash, &receiveBlock.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+	tracer := vm.NewTracer()
+	v := vm.NewVM()
+	v.SetTracer(tracer)
+	blockList, _, err := v.Run(db, receiveBlock, sendBlock)
+	result := &TraceResult{
+		ReceiveBlockHash: receiveBlock.Hash,
+		Quota:            strconv.FormatUint(receiveBlock.Quota, 10),
+		Steps:            tracer.Steps,
+		Storage:          tracer.Storage,
+	}
+	if err != nil {
+		result.Err = err.Error()
+	}
+	for i, b := range blockList {
+		if i > 0 {
+			result.SendBlockList = append(result.SendBlockList, b.AccountBlock)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,302 @@
+package api
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+var ErrAmmPoolNotExist = errors.New("amm pool not exists")
+
+// DexApi serves the built-in amm contract, it reads the pools and packs the transaction data.
+type DexApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewDexApi(vite *vite.Vite) *DexApi {
+	return &DexApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/dex_api"),
+	}
+}
+
+func (d DexApi) String() string {
+	return "DexApi"
+}
+
+type AmmPool struct {
+	TokenA           types.TokenTypeId `json:"tokenA"`
+	TokenB           types.TokenTypeId `json:"tokenB"`
+	ReserveA         string            `json:"reserveA"`
+	ReserveB         string            `json:"reserveB"`
+	TotalShares      string            `json:"totalShares"`
+	PriceACumulative string            `json:"priceACumulative"`
+	PriceBCumulative string            `json:"priceBCumulative"`
+	LastTimestamp    int64             `json:"lastTimestamp"`
+}
+
+func newAmmPool(pool *abi.AmmPool) *AmmPool {
+	return &AmmPool{
+		TokenA:           pool.TokenA,
+		TokenB:           pool.TokenB,
+		ReserveA:         *bigIntToString(pool.ReserveA),
+		ReserveB:         *bigIntToString(pool.ReserveB),
+		TotalShares:      *bigIntToString(pool.TotalShares),
+		PriceACumulative: *bigIntToString(pool.PriceACumulative),
+		PriceBCumulative: *bigIntToString(pool.PriceBCumulative),
+		LastTimestamp:    int64(pool.LastTimestamp),
+	}
+}
+
+func (d *DexApi) latestVmContext() (vmctxt_interface.VmDatabase, error) {
+	snapshotBlock := d.chain.GetLatestSnapshotBlock()
+	return vm_context.NewVmContext(d.chain, &snapshotBlock.Hash, nil, nil)
+}
+
+// GetPool returns the pool of tokenA and tokenB at the latest snapshot block, the tokens can be in any order.
+func (d *DexApi) GetPool(tokenA, tokenB types.TokenTypeId) (*AmmPool, error) {
+	vmContext, err := d.latestVmContext()
+	if err != nil {
+		return nil, err
+	}
+	pool := abi.GetAmmPool(vmContext, tokenA, tokenB)
+	if pool == nil {
+		return nil, ErrAmmPoolNotExist
+	}
+	return newAmmPool(pool), nil
+}
+
+func (d *DexApi) GetPoolList() ([]*AmmPool, error) {
+	vmContext, err := d.latestVmContext()
+	if err != nil {
+		return nil, err
+	}
+	list := abi.GetAmmPoolList(vmContext)
+	pools := make([]*AmmPool, len(list))
+	for i, pool := range list {
+		pools[i] = newAmmPool(pool)
+	}
+	return pools, nil
+}
+
+// GetFund returns the amount of tokenId addr deposited and not added to any pool.
+func (d *DexApi) GetFund(addr types.Address, tokenId types.TokenTypeId) (string, error) {
+	vmContext, err := d.latestVmContext()
+	if err != nil {
+		return "", err
+	}
+	return *bigIntToString(abi.GetAmmFund(vmContext, addr, tokenId)), nil
+}
+
+// GetAccountFund returns the amounts of the tokens addr deposited and not added to any pool, by token.
+func (d *DexApi) GetAccountFund(addr types.Address) (map[types.TokenTypeId]string, error) {
+	vmContext, err := d.latestVmContext()
+	if err != nil {
+		return nil, err
+	}
+	fundList := make(map[types.TokenTypeId]string)
+	for tokenId, amount := range abi.GetAmmFundList(vmContext, addr) {
+		fundList[tokenId] = *bigIntToString(amount)
+	}
+	return fundList, nil
+}
+
+func (d *DexApi) GetShares(addr types.Address, tokenA, tokenB types.TokenTypeId) (string, error) {
+	vmContext, err := d.latestVmContext()
+	if err != nil {
+		return "", err
+	}
+	return *bigIntToString(abi.GetAmmShares(vmContext, tokenA, tokenB, addr)), nil
+}
+
+func (d *DexApi) GetDepositData() ([]byte, error) {
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmDeposit)
+}
+
+func (d *DexApi) GetWithdrawData(tokenId types.TokenTypeId, amount string) ([]byte, error) {
+	bAmount, err := stringToBigInt(&amount)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmWithdraw, tokenId, bAmount)
+}
+
+// GetWithdrawBatchData returns the data withdrawing amounts[i] of tokenIds[i] from the funds of the sender in one call.
+func (d *DexApi) GetWithdrawBatchData(tokenIds []types.TokenTypeId, amounts []string) ([]byte, error) {
+	if len(tokenIds) != len(amounts) {
+		return nil, errors.New("the counts of token ids and amounts differ")
+	}
+	bAmounts := make([]*big.Int, len(amounts))
+	for i := range amounts {
+		bAmount, err := stringToBigInt(&amounts[i])
+		if err != nil {
+			return nil, err
+		}
+		bAmounts[i] = bAmount
+	}
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmWithdrawBatch, tokenIds, bAmounts)
+}
+
+// GetTransferData returns the data moving amount of tokenId from the funds of the sender to the funds of to.
+func (d *DexApi) GetTransferData(to types.Address, tokenId types.TokenTypeId, amount string) ([]byte, error) {
+	bAmount, err := stringToBigInt(&amount)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmTransfer, to, tokenId, bAmount)
+}
+
+func (d *DexApi) GetCreatePoolData(tokenA, tokenB types.TokenTypeId) ([]byte, error) {
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmCreatePool, tokenA, tokenB)
+}
+
+type AddLiquidityParams struct {
+	TokenA    types.TokenTypeId
+	TokenB    types.TokenTypeId
+	AmountA   string
+	AmountB   string
+	MinShares string
+}
+
+func (d *DexApi) GetAddLiquidityData(param AddLiquidityParams) ([]byte, error) {
+	amountA, err := stringToBigInt(&param.AmountA)
+	if err != nil {
+		return nil, err
+	}
+	amountB, err := stringToBigInt(&param.AmountB)
+	if err != nil {
+		return nil, err
+	}
+	minShares, err := stringToBigInt(&param.MinShares)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmAddLiquidity, param.TokenA, param.TokenB, amountA, amountB, minShares)
+}
+
+type RemoveLiquidityParams struct {
+	TokenA     types.TokenTypeId
+	TokenB     types.TokenTypeId
+	Shares     string
+	MinAmountA string
+	MinAmountB string
+}
+
+func (d *DexApi) GetRemoveLiquidityData(param RemoveLiquidityParams) ([]byte, error) {
+	shares, err := stringToBigInt(&param.Shares)
+	if err != nil {
+		return nil, err
+	}
+	minAmountA, err := stringToBigInt(&param.MinAmountA)
+	if err != nil {
+		return nil, err
+	}
+	minAmountB, err := stringToBigInt(&param.MinAmountB)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmRemoveLiquidity, param.TokenA, param.TokenB, shares, minAmountA, minAmountB)
+}
+
+// GetSwapData packs the data of a swap, the token to swap in is the token of the transaction.
+func (d *DexApi) GetSwapData(tokenOut types.TokenTypeId, minAmountOut string) ([]byte, error) {
+	bMinAmountOut, err := stringToBigInt(&minAmountOut)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmSwap, tokenOut, bMinAmountOut)
+}
+
+type AmmReward struct {
+	RewardToken     types.TokenTypeId `json:"rewardToken"`
+	Owner           types.Address     `json:"owner"`
+	RewardPerSecond string            `json:"rewardPerSecond"`
+	RewardBalance   string            `json:"rewardBalance"`
+	LastTimestamp   int64             `json:"lastTimestamp"`
+}
+
+// GetPoolReward returns the liquidity mining reward of the pool of tokenA and tokenB, RewardBalance
+// is the reward not emitted yet at LastTimestamp.
+func (d *DexApi) GetPoolReward(tokenA, tokenB types.TokenTypeId) (*AmmReward, error) {
+	vmContext, err := d.latestVmContext()
+	if err != nil {
+		return nil, err
+	}
+	reward := abi.GetAmmReward(vmContext, tokenA, tokenB)
+	if reward == nil {
+		return nil, nil
+	}
+	return &AmmReward{
+		RewardToken:     reward.RewardToken,
+		Owner:           reward.Owner,
+		RewardPerSecond: *bigIntToString(reward.RewardPerSecond),
+		RewardBalance:   *bigIntToString(reward.RewardBalance),
+		LastTimestamp:   int64(reward.LastTimestamp),
+	}, nil
+}
+
+// GetPendingReward returns the reward addr can claim from the pool of tokenA and tokenB at the latest snapshot block.
+func (d *DexApi) GetPendingReward(addr types.Address, tokenA, tokenB types.TokenTypeId) (string, error) {
+	snapshotBlock := d.chain.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(d.chain, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return *bigIntToString(abi.GetAmmPendingReward(vmContext, tokenA, tokenB, addr, uint64(snapshotBlock.Timestamp.Unix()))), nil
+}
+
+func (d *DexApi) GetSetRewardData(tokenA, tokenB types.TokenTypeId, rewardPerSecond string) ([]byte, error) {
+	bRewardPerSecond, err := stringToBigInt(&rewardPerSecond)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmSetReward, tokenA, tokenB, bRewardPerSecond)
+}
+
+func (d *DexApi) GetClaimRewardData(tokenA, tokenB types.TokenTypeId) ([]byte, error) {
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmClaimReward, tokenA, tokenB)
+}
+
+type AmmDividend struct {
+	DividendToken types.TokenTypeId            `json:"dividendToken"`
+	Owner         types.Address                `json:"owner"`
+	LastTimestamp int64                        `json:"lastTimestamp"`
+	Fees          map[types.TokenTypeId]string `json:"fees"`
+}
+
+// GetDividend returns the dividend of the swap fees, Fees are the fees collected and not distributed yet.
+func (d *DexApi) GetDividend() (*AmmDividend, error) {
+	vmContext, err := d.latestVmContext()
+	if err != nil {
+		return nil, err
+	}
+	dividend := abi.GetAmmDividend(vmContext)
+	if dividend == nil {
+		return nil, nil
+	}
+	fees := make(map[types.TokenTypeId]string)
+	for _, fee := range abi.GetAmmFeePoolList(vmContext) {
+		fees[fee.TokenId] = *bigIntToString(fee.Amount)
+	}
+	return &AmmDividend{
+		DividendToken: dividend.DividendToken,
+		Owner:         dividend.Owner,
+		LastTimestamp: int64(dividend.LastTimestamp),
+		Fees:          fees,
+	}, nil
+}
+
+func (d *DexApi) GetSetDividendTokenData(tokenId types.TokenTypeId) ([]byte, error) {
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmSetDividendToken, tokenId)
+}
+
+func (d *DexApi) GetDividendData() ([]byte, error) {
+	return abi.ABIAmm.PackMethod(abi.MethodNameAmmDividend)
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+	vmabi "github.com/vitelabs/go-vite/vm/abi"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+// EventRegistryApi serves the built-in event registry contract, where contracts or their deployers register
+// the definitions of their events, so that the logs of the contracts can be described by event names.
+type EventRegistryApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewEventRegistryApi(vite *vite.Vite) *EventRegistryApi {
+	return &EventRegistryApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/event_registry_api"),
+	}
+}
+
+func (e EventRegistryApi) String() string {
+	return "EventRegistryApi"
+}
+
+// GetRegisterEventData packs the call data to register definition, the json abi of one event of contract. The
+// createHash is the hash of the create block of contract, required if the sender is not the contract itself.
+func (e *EventRegistryApi) GetRegisterEventData(contract types.Address, createHash types.Hash, definition string) ([]byte, error) {
+	if _, err := abi.ParseEventDefinition(definition); err != nil {
+		return nil, err
+	}
+	return abi.ABIEventRegistry.PackMethod(abi.MethodNameRegisterEvent, contract, createHash, definition)
+}
+
+func (e *EventRegistryApi) GetEvent(contract types.Address, topic types.Hash) (*LogEvent, error) {
+	resolver, err := NewLogEventResolver(e.chain)
+	if err != nil {
+		return nil, err
+	}
+	return resolver.resolveTopic(contract, topic), nil
+}
+
+// LogEvent describes a vm log by the event registered for its first topic.
+type LogEvent struct {
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+	// IndexedParams are the names of the params in the topics after the first one
+	IndexedParams []string `json:"indexedParams"`
+	// Params are the names of the params packed in the data
+	Params []string `json:"params"`
+}
+
+// VmLog is a vm log with the event it is resolved to, Event is nil if the event is not registered.
+type VmLog struct {
+	*ledger.VmLog
+	Event *LogEvent `json:"event,omitempty"`
+}
+
+func newLogEvent(event *vmabi.Event) *LogEvent {
+	e := &LogEvent{
+		Name:          event.Name,
+		Signature:     event.String(),
+		IndexedParams: make([]string, 0),
+		Params:        make([]string, 0),
+	}
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			e.IndexedParams = append(e.IndexedParams, input.Name)
+		} else {
+			e.Params = append(e.Params, input.Name)
+		}
+	}
+	return e
+}
+
+// LogEventResolver resolves the events of vm logs from the event registry at the latest snapshot block,
+// the events resolved are cached, so a resolver is meant to serve one request.
+type LogEventResolver struct {
+	db    abi.StorageDatabase
+	cache map[types.Address]map[types.Hash]*LogEvent
+}
+
+func NewLogEventResolver(c chain.Chain) (*LogEventResolver, error) {
+	snapshotBlock := c.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(c, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &LogEventResolver{db: vmContext, cache: make(map[types.Address]map[types.Hash]*LogEvent)}, nil
+}
+
+// Resolve returns the event of log emitted by contract, nil if the event is not registered or doesn't
+// match the log.
+func (r *LogEventResolver) Resolve(contract types.Address, log *ledger.VmLog) *LogEvent {
+	if log == nil || len(log.Topics) == 0 {
+		return nil
+	}
+	e := r.resolveTopic(contract, log.Topics[0])
+	if e == nil || len(e.IndexedParams) != len(log.Topics)-1 {
+		return nil
+	}
+	return e
+}
+
+func (r *LogEventResolver) resolveTopic(contract types.Address, topic types.Hash) *LogEvent {
+	events, ok := r.cache[contract]
+	if !ok {
+		events = make(map[types.Hash]*LogEvent)
+		r.cache[contract] = events
+	}
+	if e, ok := events[topic]; ok {
+		return e
+	}
+	var e *LogEvent
+	if event := abi.GetEventDefinition(r.db, contract, topic); event != nil {
+		e = newLogEvent(event)
+	}
+	events[topic] = e
+	return e
+}
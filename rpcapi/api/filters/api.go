@@ -0,0 +1,1409 @@
+package filters
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/vite"
+)
+
+const (
+	defaultDeadline      = 5 * time.Minute
+	defaultSweepInterval = 5 * time.Minute
+)
+
+type filter struct {
+	typ             FilterType
+	created         time.Time
+	deadline        *time.Timer
+	s               *RpcSubscription
+	blocks          []*AccountBlocksMsg
+	logs            []*LogsMsg
+	onroadMsgs      []*OnroadMsg
+	confirmedBlocks []*AccountBlocksMsg
+	confirmedLogs   []*LogsMsg
+	snapshotBlocks  []*SnapshotBlocksMsg
+	reorgs          []*ReorgMsg
+	syncStates      []*SyncStateMsg
+	peerEvents      []*PeerEventMsg
+	contractEvents  []*ContractEventMsg
+	receipts        []*ReceiptMsg
+
+	maxBuffered int
+	policy      OverflowPolicy
+	overflowed  bool
+
+	// first is the sequence of the oldest buffered message, it's the cursor of paged polls
+	first uint64
+}
+
+// makeRoom applies the overflow policy before n messages are appended to the buffered ones,
+// it returns how many buffered messages are dropped from the head and how many of the new
+// messages are kept from the tail.
+func (f *filter) makeRoom(buffered, n int) (drop, keep int) {
+	if f.maxBuffered <= 0 || buffered+n <= f.maxBuffered {
+		return 0, n
+	}
+	f.overflowed = true
+	if f.policy == OverflowError {
+		// the buffer is discarded on the next poll anyway, stop growing it
+		atomic.AddUint64(&droppedNotifications, uint64(n))
+		return 0, 0
+	}
+	if n >= f.maxBuffered {
+		drop, keep = buffered, f.maxBuffered
+	} else {
+		drop, keep = buffered+n-f.maxBuffered, n
+	}
+	f.first += uint64(drop)
+	atomic.AddUint64(&droppedNotifications, uint64(drop+n-keep))
+	return drop, keep
+}
+
+// OverflowPolicy decides what a polling filter does when its buffer is full.
+type OverflowPolicy string
+
+const (
+	// OverflowDropOldest drops the oldest buffered messages, the next poll is flagged as overflowed
+	OverflowDropOldest OverflowPolicy = "dropOldest"
+	// OverflowError discards the buffer, the next poll fails with ErrFilterOverflowed
+	OverflowError OverflowPolicy = "error"
+)
+
+// FilterChanges is the result of a poll, Overflowed is set if messages were dropped since the last poll.
+type FilterChanges struct {
+	Changes    interface{} `json:"changes"`
+	Overflowed bool        `json:"overflowed"`
+}
+
+// FilterPage is the result of a paged poll, the next page is polled with NextCursor.
+// More is set if there are buffered messages after this page.
+type FilterPage struct {
+	Changes    interface{} `json:"changes"`
+	NextCursor string      `json:"nextCursor"`
+	More       bool        `json:"more"`
+	Overflowed bool        `json:"overflowed"`
+}
+
+type SubscribeApi struct {
+	vite      *vite.Vite
+	log       log15.Logger
+	filtersMu sync.Mutex
+	filters   map[rpc.ID]*filter
+
+	deadline       time.Duration
+	sweepInterval  time.Duration
+	maxBuffered    int
+	overflowPolicy OverflowPolicy
+	anyAddrLogs    bool
+
+	maxSubscriptions int
+	maxFilters       int
+}
+
+// Option configures the polling filters of a SubscribeApi.
+type Option func(*SubscribeApi)
+
+// WithDeadline sets how long a polling filter is kept without being queried, a non-positive d is ignored.
+func WithDeadline(d time.Duration) Option {
+	return func(s *SubscribeApi) {
+		if d > 0 {
+			s.deadline = d
+		}
+	}
+}
+
+// WithSweepInterval sets how often the expired polling filters are uninstalled, a non-positive d is ignored.
+func WithSweepInterval(d time.Duration) Option {
+	return func(s *SubscribeApi) {
+		if d > 0 {
+			s.sweepInterval = d
+		}
+	}
+}
+
+// WithMaxBuffered sets how many messages a polling filter buffers between polls and what it
+// does when the buffer is full, a non-positive max means unlimited.
+func WithMaxBuffered(max int, policy OverflowPolicy) Option {
+	return func(s *SubscribeApi) {
+		s.maxBuffered = max
+		if policy != "" {
+			s.overflowPolicy = policy
+		}
+	}
+}
+
+// WithAnyAddrLogs allows the log subscriptions and filters with an empty addrRange, which match
+// the logs of all the addresses. GetLogs still needs addresses since it scans the account chains.
+func WithAnyAddrLogs(enable bool) Option {
+	return func(s *SubscribeApi) {
+		s.anyAddrLogs = enable
+	}
+}
+
+// WithMaxSubscriptions limits the concurrent subscriptions of a connection, a non-positive max means unlimited.
+func WithMaxSubscriptions(max int) Option {
+	return func(s *SubscribeApi) {
+		s.maxSubscriptions = max
+	}
+}
+
+// WithMaxFilters limits the polling filters installed on the node, a non-positive max means unlimited.
+func WithMaxFilters(max int) Option {
+	return func(s *SubscribeApi) {
+		s.maxFilters = max
+	}
+}
+
+func NewSubscribeApi(vite *vite.Vite, opts ...Option) *SubscribeApi {
+	s := &SubscribeApi{
+		vite:           vite,
+		log:            log15.New("module", "rpc_api/subscribe_api"),
+		filters:        make(map[rpc.ID]*filter),
+		deadline:       defaultDeadline,
+		sweepInterval:  defaultSweepInterval,
+		overflowPolicy: OverflowDropOldest,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	go s.timeoutLoop()
+
+	apisLock.Lock()
+	apis = append(apis, s)
+	apisLock.Unlock()
+	return s
+}
+
+var (
+	apisLock sync.Mutex
+	apis     []*SubscribeApi
+)
+
+var (
+	// installedFilters is the count of polling filters of all subscribe apis, including the ones being installed
+	installedFilters int64
+
+	connSubsLock sync.Mutex
+	connSubs     = make(map[*rpc.Notifier]int)
+
+	// droppedNotifications is the count of messages dropped by the overflow policies of polling filters
+	// and the ones failed to be notified to subscriptions
+	droppedNotifications uint64
+)
+
+// reserveFilter counts a polling filter to be installed, it fails if max filters are installed already.
+func reserveFilter(max int) error {
+	if n := atomic.AddInt64(&installedFilters, 1); max > 0 && n > int64(max) {
+		atomic.AddInt64(&installedFilters, -1)
+		return ErrTooManyFilters
+	}
+	return nil
+}
+
+// acquireSubscription counts a subscription of the connection of notifier, it fails if the connection
+// has max subscriptions already.
+func acquireSubscription(notifier *rpc.Notifier, max int) error {
+	connSubsLock.Lock()
+	defer connSubsLock.Unlock()
+	if max > 0 && connSubs[notifier] >= max {
+		return ErrTooManySubscriptions
+	}
+	connSubs[notifier]++
+	return nil
+}
+
+func releaseSubscription(notifier *rpc.Notifier) {
+	connSubsLock.Lock()
+	defer connSubsLock.Unlock()
+	if connSubs[notifier]--; connSubs[notifier] <= 0 {
+		delete(connSubs, notifier)
+	}
+}
+
+// Backlog returns the count of messages buffered by the polling filters of all subscribe apis.
+func Backlog() int {
+	apisLock.Lock()
+	defer apisLock.Unlock()
+	total := 0
+	for _, s := range apis {
+		s.filtersMu.Lock()
+		for _, f := range s.filters {
+			total += f.buffered()
+		}
+		s.filtersMu.Unlock()
+	}
+	return total
+}
+
+// notify sends data to the subscription of id, counting it as dropped if it fails.
+func notify(notifier *rpc.Notifier, id rpc.ID, data interface{}) {
+	if err := notifier.Notify(id, data); err != nil {
+		atomic.AddUint64(&droppedNotifications, 1)
+	}
+}
+
+// FilterTypeStatus is the count of polling filters of a type and the messages they buffer.
+type FilterTypeStatus struct {
+	Filters  int `json:"filters"`
+	Buffered int `json:"buffered"`
+}
+
+// SubscribeStatus tells how the subscriptions and polling filters of all subscribe apis are delivered.
+// OldestFilterAge is the age in seconds of the oldest polling filter, 0 if there is none.
+type SubscribeStatus struct {
+	Filters              int                          `json:"filters"`
+	Buffered             int                          `json:"buffered"`
+	Types                map[string]*FilterTypeStatus `json:"types"`
+	Subscriptions        int                          `json:"subscriptions"`
+	DroppedNotifications uint64                       `json:"droppedNotifications"`
+	OldestFilterAge      int64                        `json:"oldestFilterAge"`
+}
+
+// Status returns the delivery status of the subscriptions and polling filters of all subscribe apis.
+func Status() *SubscribeStatus {
+	status := &SubscribeStatus{
+		Types:                make(map[string]*FilterTypeStatus, len(filterTypes)),
+		DroppedNotifications: atomic.LoadUint64(&droppedNotifications),
+	}
+	for _, typ := range filterTypes {
+		status.Types[typ.String()] = &FilterTypeStatus{}
+	}
+
+	now := time.Now()
+	apisLock.Lock()
+	for _, s := range apis {
+		s.filtersMu.Lock()
+		for _, f := range s.filters {
+			buffered := f.buffered()
+			status.Filters++
+			status.Buffered += buffered
+			if typeStatus, ok := status.Types[f.typ.String()]; ok {
+				typeStatus.Filters++
+				typeStatus.Buffered += buffered
+			}
+			if age := int64(now.Sub(f.created) / time.Second); age > status.OldestFilterAge {
+				status.OldestFilterAge = age
+			}
+		}
+		s.filtersMu.Unlock()
+	}
+	apisLock.Unlock()
+
+	connSubsLock.Lock()
+	for _, n := range connSubs {
+		status.Subscriptions += n
+	}
+	connSubsLock.Unlock()
+	return status
+}
+
+func (s *SubscribeApi) String() string {
+	return "SubscribeApi"
+}
+
+// timeoutLoop uninstalls polling filters which have not been queried for s.deadline.
+func (s *SubscribeApi) timeoutLoop() {
+	ticker := time.NewTicker(s.sweepInterval)
+	defer ticker.Stop()
+	for {
+		<-ticker.C
+		s.filtersMu.Lock()
+		for id, f := range s.filters {
+			select {
+			case <-f.deadline.C:
+				s.deleteFilter(id)
+				go f.s.Unsubscribe()
+			default:
+				continue
+			}
+		}
+		s.filtersMu.Unlock()
+	}
+}
+
+func (s *SubscribeApi) installFilter(typ FilterType, sub *RpcSubscription) {
+	s.filtersMu.Lock()
+	s.filters[sub.ID] = &filter{typ: typ, created: time.Now(), deadline: time.NewTimer(s.deadline), s: sub, maxBuffered: s.maxBuffered, policy: s.overflowPolicy}
+	s.filtersMu.Unlock()
+}
+
+func (s *SubscribeApi) removeFilter(id rpc.ID) {
+	s.filtersMu.Lock()
+	s.deleteFilter(id)
+	s.filtersMu.Unlock()
+}
+
+// deleteFilter deletes the filter of id and releases its count, filtersMu must be held.
+func (s *SubscribeApi) deleteFilter(id rpc.ID) {
+	if _, found := s.filters[id]; found {
+		delete(s.filters, id)
+		atomic.AddInt64(&installedFilters, -1)
+	}
+}
+
+func (s *SubscribeApi) NewAccountBlocksFilter(param *RpcAccountBlocksParam) (rpc.ID, error) {
+	s.log.Info("NewAccountBlocksFilter")
+	return s.newAccountBlocksFilter(AccountBlocksSubscription, nil, param)
+}
+
+// NewAccountBlocksByAddrFilter is NewAccountBlocksFilter scoped to the blocks sent from or to the addresses in addrList.
+func (s *SubscribeApi) NewAccountBlocksByAddrFilter(addrList []types.Address, param *RpcAccountBlocksParam) (rpc.ID, error) {
+	s.log.Info("NewAccountBlocksByAddrFilter")
+	addrSet, err := toAddrSet(addrList)
+	if err != nil {
+		return "", err
+	}
+	return s.newAccountBlocksFilter(AccountBlocksSubscription, addrSet, param)
+}
+
+func (s *SubscribeApi) NewConfirmedAccountBlocksFilter(param *RpcAccountBlocksParam) (rpc.ID, error) {
+	s.log.Info("NewConfirmedAccountBlocksFilter")
+	return s.newAccountBlocksFilter(ConfirmedAccountBlocksSubscription, nil, param)
+}
+
+func (s *SubscribeApi) newAccountBlocksFilter(typ FilterType, addrSet map[types.Address]struct{}, param *RpcAccountBlocksParam) (rpc.ID, error) {
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	p, err := param.toAccountBlocksParam()
+	if err != nil {
+		return "", err
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	acCh := make(chan []*AccountBlocksMsg)
+	acSub := Es.subscribeAccountBlocks(typ, p, addrSet, acCh)
+	s.installFilter(typ, acSub)
+
+	go func() {
+		for {
+			select {
+			case msgs := <-acCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[acSub.ID]; found {
+					if typ == ConfirmedAccountBlocksSubscription {
+						drop, keep := f.makeRoom(len(f.confirmedBlocks), len(msgs))
+						f.confirmedBlocks = append(f.confirmedBlocks[drop:], msgs[len(msgs)-keep:]...)
+					} else {
+						drop, keep := f.makeRoom(len(f.blocks), len(msgs))
+						f.blocks = append(f.blocks[drop:], msgs[len(msgs)-keep:]...)
+					}
+				}
+				s.filtersMu.Unlock()
+			case <-acSub.Err():
+				s.removeFilter(acSub.ID)
+				return
+			}
+		}
+	}()
+	return acSub.ID, nil
+}
+
+func (s *SubscribeApi) NewLogsFilter(param RpcFilterParam) (rpc.ID, error) {
+	s.log.Info("NewLogsFilter")
+	return s.newLogsFilter(LogsSubscription, param)
+}
+
+func (s *SubscribeApi) NewConfirmedLogsFilter(param RpcFilterParam) (rpc.ID, error) {
+	s.log.Info("NewConfirmedLogsFilter")
+	return s.newLogsFilter(ConfirmedLogsSubscription, param)
+}
+
+func (s *SubscribeApi) newLogsFilter(typ FilterType, param RpcFilterParam) (rpc.ID, error) {
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	p, err := param.toFilterParam(s.anyAddrLogs)
+	if err != nil {
+		return "", err
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	logsCh := make(chan []*LogsMsg)
+	logsSub := Es.subscribeLogs(typ, p, logsCh)
+	s.installFilter(typ, logsSub)
+
+	go func() {
+		for {
+			select {
+			case msgs := <-logsCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[logsSub.ID]; found {
+					if typ == ConfirmedLogsSubscription {
+						drop, keep := f.makeRoom(len(f.confirmedLogs), len(msgs))
+						f.confirmedLogs = append(f.confirmedLogs[drop:], msgs[len(msgs)-keep:]...)
+					} else {
+						drop, keep := f.makeRoom(len(f.logs), len(msgs))
+						f.logs = append(f.logs[drop:], msgs[len(msgs)-keep:]...)
+					}
+				}
+				s.filtersMu.Unlock()
+			case <-logsSub.Err():
+				s.removeFilter(logsSub.ID)
+				return
+			}
+		}
+	}()
+	return logsSub.ID, nil
+}
+
+func (s *SubscribeApi) NewOnroadBlocksFilter(param RpcOnroadFilterParam) (rpc.ID, error) {
+	s.log.Info("NewOnroadBlocksFilter")
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	p, err := param.toFilterParam()
+	if err != nil {
+		return "", err
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	onroadCh := make(chan []*OnroadMsg)
+	onroadSub := Es.SubscribeOnroadBlocks(p, onroadCh)
+	s.installFilter(OnroadBlocksSubscription, onroadSub)
+
+	go func() {
+		for {
+			select {
+			case msgs := <-onroadCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[onroadSub.ID]; found {
+					drop, keep := f.makeRoom(len(f.onroadMsgs), len(msgs))
+					f.onroadMsgs = append(f.onroadMsgs[drop:], msgs[len(msgs)-keep:]...)
+				}
+				s.filtersMu.Unlock()
+			case <-onroadSub.Err():
+				s.removeFilter(onroadSub.ID)
+				return
+			}
+		}
+	}()
+	return onroadSub.ID, nil
+}
+
+func (s *SubscribeApi) NewSnapshotBlocksFilter() (rpc.ID, error) {
+	s.log.Info("NewSnapshotBlocksFilter")
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	sbCh := make(chan []*SnapshotBlocksMsg)
+	sbSub := Es.SubscribeSnapshotBlocks(sbCh)
+	s.installFilter(SnapshotBlocksSubscription, sbSub)
+
+	go func() {
+		for {
+			select {
+			case msgs := <-sbCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[sbSub.ID]; found {
+					drop, keep := f.makeRoom(len(f.snapshotBlocks), len(msgs))
+					f.snapshotBlocks = append(f.snapshotBlocks[drop:], msgs[len(msgs)-keep:]...)
+				}
+				s.filtersMu.Unlock()
+			case <-sbSub.Err():
+				s.removeFilter(sbSub.ID)
+				return
+			}
+		}
+	}()
+	return sbSub.ID, nil
+}
+
+// NewReorgFilter creates a polling filter which buffers a message for each rollback of the snapshot chain.
+func (s *SubscribeApi) NewReorgFilter() (rpc.ID, error) {
+	s.log.Info("NewReorgFilter")
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	reorgCh := make(chan *ReorgMsg)
+	reorgSub := Es.SubscribeReorg(reorgCh)
+	s.installFilter(ReorgSubscription, reorgSub)
+
+	go func() {
+		for {
+			select {
+			case msg := <-reorgCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[reorgSub.ID]; found {
+					drop, keep := f.makeRoom(len(f.reorgs), 1)
+					f.reorgs = f.reorgs[drop:]
+					if keep > 0 {
+						f.reorgs = append(f.reorgs, msg)
+					}
+				}
+				s.filtersMu.Unlock()
+			case <-reorgSub.Err():
+				s.removeFilter(reorgSub.ID)
+				return
+			}
+		}
+	}()
+	return reorgSub.ID, nil
+}
+
+// NewSyncStateFilter creates a polling filter which buffers a message for each transition of the
+// sync state, the current state is buffered as the first message.
+func (s *SubscribeApi) NewSyncStateFilter() (rpc.ID, error) {
+	s.log.Info("NewSyncStateFilter")
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	syncStateCh := make(chan *SyncStateMsg)
+	syncStateSub := Es.SubscribeSyncState(syncStateCh)
+	s.installFilter(SyncStateSubscription, syncStateSub)
+	if msg := Es.SyncState(); msg != nil {
+		s.filtersMu.Lock()
+		s.filters[syncStateSub.ID].syncStates = []*SyncStateMsg{msg}
+		s.filtersMu.Unlock()
+	}
+
+	go func() {
+		for {
+			select {
+			case msg := <-syncStateCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[syncStateSub.ID]; found {
+					drop, keep := f.makeRoom(len(f.syncStates), 1)
+					f.syncStates = f.syncStates[drop:]
+					if keep > 0 {
+						f.syncStates = append(f.syncStates, msg)
+					}
+				}
+				s.filtersMu.Unlock()
+			case <-syncStateSub.Err():
+				s.removeFilter(syncStateSub.ID)
+				return
+			}
+		}
+	}()
+	return syncStateSub.ID, nil
+}
+
+// NewPeerEventsFilter creates a polling filter which buffers a message for each peer added or dropped.
+func (s *SubscribeApi) NewPeerEventsFilter() (rpc.ID, error) {
+	s.log.Info("NewPeerEventsFilter")
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	peerCh := make(chan *PeerEventMsg)
+	peerSub := Es.SubscribePeerEvents(peerCh)
+	s.installFilter(PeerEventsSubscription, peerSub)
+
+	go func() {
+		for {
+			select {
+			case msg := <-peerCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[peerSub.ID]; found {
+					drop, keep := f.makeRoom(len(f.peerEvents), 1)
+					f.peerEvents = f.peerEvents[drop:]
+					if keep > 0 {
+						f.peerEvents = append(f.peerEvents, msg)
+					}
+				}
+				s.filtersMu.Unlock()
+			case <-peerSub.Err():
+				s.removeFilter(peerSub.ID)
+				return
+			}
+		}
+	}()
+	return peerSub.ID, nil
+}
+
+// NewContractEventsFilter creates a polling filter which buffers the decoded state changes of the
+// register, vote and pledge contracts, a nil param matches all events.
+func (s *SubscribeApi) NewContractEventsFilter(param *RpcContractEventsParam) (rpc.ID, error) {
+	s.log.Info("NewContractEventsFilter")
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	p, err := param.toFilterParam()
+	if err != nil {
+		return "", err
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	contractCh := make(chan []*ContractEventMsg)
+	contractSub := Es.SubscribeContractEvents(p, contractCh)
+	s.installFilter(ContractEventsSubscription, contractSub)
+
+	go func() {
+		for {
+			select {
+			case msgs := <-contractCh:
+				s.filtersMu.Lock()
+				if f, found := s.filters[contractSub.ID]; found {
+					drop, keep := f.makeRoom(len(f.contractEvents), len(msgs))
+					f.contractEvents = append(f.contractEvents[drop:], msgs[len(msgs)-keep:]...)
+				}
+				s.filtersMu.Unlock()
+			case <-contractSub.Err():
+				s.removeFilter(contractSub.ID)
+				return
+			}
+		}
+	}()
+	return contractSub.ID, nil
+}
+
+// NewReceiptFilter creates a polling filter which buffers the receipt of the send block param identifies
+// once its receive block is confirmed, including the receive block hash and the execution status.
+func (s *SubscribeApi) NewReceiptFilter(param *RpcReceiptParam) (rpc.ID, error) {
+	s.log.Info("NewReceiptFilter")
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	sendHash, err := param.toSendBlockHash(s.vite.Chain())
+	if err != nil {
+		return "", err
+	}
+	if err := reserveFilter(s.maxFilters); err != nil {
+		return "", err
+	}
+	receiptCh := make(chan *ReceiptMsg, 1)
+	receiptSub := Es.SubscribeReceipt(sendHash, receiptCh)
+	s.installFilter(ReceiptSubscription, receiptSub)
+
+	go func() {
+		// the receive block may be confirmed before the subscription is installed
+		msg, err := Es.Receipt(sendHash)
+		if err != nil {
+			s.log.Error("Receipt failed, error is "+err.Error(), "method", "NewReceiptFilter")
+		}
+		received := false
+		for {
+			if msg != nil && !received {
+				received = true
+				s.filtersMu.Lock()
+				if f, found := s.filters[receiptSub.ID]; found {
+					f.receipts = append(f.receipts, msg)
+				}
+				s.filtersMu.Unlock()
+			}
+			select {
+			case msg = <-receiptCh:
+			case <-receiptSub.Err():
+				s.removeFilter(receiptSub.ID)
+				return
+			}
+		}
+	}()
+	return receiptSub.ID, nil
+}
+
+// Status returns the count of the polling filters by type, the messages they buffer, the notifications
+// dropped and the age of the oldest filter, so that operators can watch the delivery of subscriptions.
+func (s *SubscribeApi) Status() *SubscribeStatus {
+	return Status()
+}
+
+func (s *SubscribeApi) UninstallFilter(id rpc.ID) bool {
+	s.log.Info("UninstallFilter", "id", id)
+	s.filtersMu.Lock()
+	f, found := s.filters[id]
+	if found {
+		s.deleteFilter(id)
+	}
+	s.filtersMu.Unlock()
+	if !found {
+		return s.uninstallDurableFilter(id)
+	}
+	f.s.Unsubscribe()
+	return true
+}
+
+// GetFilterChanges returns the messages buffered by the polling filter since the last poll.
+// If page is given, at most page.Limit messages from page.Cursor are returned as a *FilterPage,
+// and they are kept in the buffer until the next page acknowledges them, so a lost response
+// can be polled again with the same cursor. Durable filters are polled the same way, see
+// NewDurableLogsFilter.
+func (s *SubscribeApi) GetFilterChanges(id rpc.ID, page *RpcPageParam) (interface{}, error) {
+	s.log.Info("GetFilterChanges", "id", id)
+	if page != nil {
+		return s.getFilterPage(id, page)
+	}
+	changes, err := s.getFilterChanges(id)
+	if err != nil {
+		return nil, err
+	}
+	return changes.Changes, nil
+}
+
+// GetFilterChangesWithStatus is GetFilterChanges which also tells whether messages were dropped since the last poll.
+func (s *SubscribeApi) GetFilterChangesWithStatus(id rpc.ID) (*FilterChanges, error) {
+	s.log.Info("GetFilterChangesWithStatus", "id", id)
+	return s.getFilterChanges(id)
+}
+
+// pollFilter returns the filter of id and resets its deadline, filtersMu must be held.
+func (s *SubscribeApi) pollFilter(id rpc.ID) (*filter, error) {
+	f, found := s.filters[id]
+	if !found {
+		return nil, ErrFilterNotFound
+	}
+	if !f.deadline.Stop() {
+		// timer expired but filter is not yet removed in timeout loop
+		// receive timer value and reset timer
+		<-f.deadline.C
+	}
+	f.deadline.Reset(s.deadline)
+	return f, nil
+}
+
+func (s *SubscribeApi) getFilterChanges(id rpc.ID) (*FilterChanges, error) {
+	if !s.installed(id) {
+		page, err := s.pollDurableFilter(id, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &FilterChanges{Changes: page.Changes}, nil
+	}
+
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+
+	f, err := s.pollFilter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &FilterChanges{Changes: f.take(), Overflowed: f.overflowed}
+	f.overflowed = false
+	if changes.Overflowed && f.policy == OverflowError {
+		return nil, ErrFilterOverflowed
+	}
+	return changes, nil
+}
+
+func (s *SubscribeApi) getFilterPage(id rpc.ID, page *RpcPageParam) (*FilterPage, error) {
+	if !s.installed(id) {
+		return s.pollDurableFilter(id, page)
+	}
+
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+
+	f, err := s.pollFilter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := f.buffered()
+	if page.Cursor != "" {
+		cursor, err := strconv.ParseUint(page.Cursor, 10, 64)
+		if err != nil || cursor > f.first+uint64(buffered) {
+			return nil, ErrInvalidCursor
+		}
+		// the messages before first were dropped by the overflow policy, which is flagged by Overflowed
+		if cursor > f.first {
+			f.ack(int(cursor - f.first))
+			buffered = f.buffered()
+		}
+	}
+
+	overflowed := f.overflowed
+	f.overflowed = false
+	if overflowed && f.policy == OverflowError {
+		f.ack(buffered)
+		return nil, ErrFilterOverflowed
+	}
+
+	n := buffered
+	if page.Limit > 0 && page.Limit < n {
+		n = page.Limit
+	}
+	return &FilterPage{
+		Changes:    f.peek(n),
+		NextCursor: strconv.FormatUint(f.first+uint64(n), 10),
+		More:       n < buffered,
+		Overflowed: overflowed,
+	}, nil
+}
+
+func (f *filter) buffered() int {
+	return len(f.blocks) + len(f.logs) + len(f.onroadMsgs) + len(f.confirmedBlocks) +
+		len(f.confirmedLogs) + len(f.snapshotBlocks) + len(f.reorgs) + len(f.syncStates) + len(f.peerEvents) +
+		len(f.contractEvents) + len(f.receipts)
+}
+
+// take returns the buffered messages and empties the buffer.
+func (f *filter) take() interface{} {
+	f.first += uint64(f.buffered())
+	switch f.typ {
+	case AccountBlocksSubscription:
+		blocks := f.blocks
+		f.blocks = nil
+		return blocks
+	case LogsSubscription:
+		logs := f.logs
+		f.logs = nil
+		return logs
+	case OnroadBlocksSubscription:
+		onroadMsgs := f.onroadMsgs
+		f.onroadMsgs = nil
+		return onroadMsgs
+	case ConfirmedAccountBlocksSubscription:
+		blocks := f.confirmedBlocks
+		f.confirmedBlocks = nil
+		return blocks
+	case ConfirmedLogsSubscription:
+		logs := f.confirmedLogs
+		f.confirmedLogs = nil
+		return logs
+	case SnapshotBlocksSubscription:
+		blocks := f.snapshotBlocks
+		f.snapshotBlocks = nil
+		return blocks
+	case ReorgSubscription:
+		reorgs := f.reorgs
+		f.reorgs = nil
+		return reorgs
+	case SyncStateSubscription:
+		syncStates := f.syncStates
+		f.syncStates = nil
+		return syncStates
+	case PeerEventsSubscription:
+		peerEvents := f.peerEvents
+		f.peerEvents = nil
+		return peerEvents
+	case ContractEventsSubscription:
+		contractEvents := f.contractEvents
+		f.contractEvents = nil
+		return contractEvents
+	case ReceiptSubscription:
+		receipts := f.receipts
+		f.receipts = nil
+		return receipts
+	}
+	return nil
+}
+
+// peek returns the n oldest buffered messages without removing them.
+func (f *filter) peek(n int) interface{} {
+	switch f.typ {
+	case AccountBlocksSubscription:
+		return f.blocks[:n:n]
+	case LogsSubscription:
+		return f.logs[:n:n]
+	case OnroadBlocksSubscription:
+		return f.onroadMsgs[:n:n]
+	case ConfirmedAccountBlocksSubscription:
+		return f.confirmedBlocks[:n:n]
+	case ConfirmedLogsSubscription:
+		return f.confirmedLogs[:n:n]
+	case SnapshotBlocksSubscription:
+		return f.snapshotBlocks[:n:n]
+	case ReorgSubscription:
+		return f.reorgs[:n:n]
+	case SyncStateSubscription:
+		return f.syncStates[:n:n]
+	case PeerEventsSubscription:
+		return f.peerEvents[:n:n]
+	case ContractEventsSubscription:
+		return f.contractEvents[:n:n]
+	case ReceiptSubscription:
+		return f.receipts[:n:n]
+	}
+	return nil
+}
+
+// ack removes the n oldest buffered messages.
+func (f *filter) ack(n int) {
+	switch f.typ {
+	case AccountBlocksSubscription:
+		f.blocks = f.blocks[n:]
+	case LogsSubscription:
+		f.logs = f.logs[n:]
+	case OnroadBlocksSubscription:
+		f.onroadMsgs = f.onroadMsgs[n:]
+	case ConfirmedAccountBlocksSubscription:
+		f.confirmedBlocks = f.confirmedBlocks[n:]
+	case ConfirmedLogsSubscription:
+		f.confirmedLogs = f.confirmedLogs[n:]
+	case SnapshotBlocksSubscription:
+		f.snapshotBlocks = f.snapshotBlocks[n:]
+	case ReorgSubscription:
+		f.reorgs = f.reorgs[n:]
+	case SyncStateSubscription:
+		f.syncStates = f.syncStates[n:]
+	case PeerEventsSubscription:
+		f.peerEvents = f.peerEvents[n:]
+	case ContractEventsSubscription:
+		f.contractEvents = f.contractEvents[n:]
+	case ReceiptSubscription:
+		f.receipts = f.receipts[n:]
+	}
+	f.first += uint64(n)
+}
+
+// GetLogs returns the vm logs already in chain which match param, it doesn't need the event system.
+// The addrRange of param must not be empty.
+func (s *SubscribeApi) GetLogs(param RpcFilterParam) ([]*LogsMsg, error) {
+	s.log.Info("GetLogs")
+	p, err := param.toFilterParam(false)
+	if err != nil {
+		return nil, err
+	}
+	return getLogs(s.vite.Chain(), p)
+}
+
+func (s *SubscribeApi) NewAccountBlocks(ctx context.Context, param *RpcAccountBlocksParam) (*rpc.Subscription, error) {
+	s.log.Info("NewAccountBlocks")
+	return s.newAccountBlocks(ctx, AccountBlocksSubscription, nil, param)
+}
+
+// NewAccountBlocksByAddr is NewAccountBlocks scoped to the blocks sent from or to the addresses in addrList.
+func (s *SubscribeApi) NewAccountBlocksByAddr(ctx context.Context, addrList []types.Address, param *RpcAccountBlocksParam) (*rpc.Subscription, error) {
+	s.log.Info("NewAccountBlocksByAddr")
+	addrSet, err := toAddrSet(addrList)
+	if err != nil {
+		return nil, err
+	}
+	return s.newAccountBlocks(ctx, AccountBlocksSubscription, addrSet, param)
+}
+
+func (s *SubscribeApi) NewConfirmedAccountBlocks(ctx context.Context, param *RpcAccountBlocksParam) (*rpc.Subscription, error) {
+	s.log.Info("NewConfirmedAccountBlocks")
+	return s.newAccountBlocks(ctx, ConfirmedAccountBlocksSubscription, nil, param)
+}
+
+func (s *SubscribeApi) newAccountBlocks(ctx context.Context, typ FilterType, addrSet map[types.Address]struct{}, param *RpcAccountBlocksParam) (*rpc.Subscription, error) {
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	p, err := param.toAccountBlocksParam()
+	if err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		acCh := make(chan []*AccountBlocksMsg, 128)
+		acSub := Es.subscribeAccountBlocks(typ, p, addrSet, acCh)
+		defer acSub.Unsubscribe()
+
+		for {
+			select {
+			case msgs := <-acCh:
+				notify(notifier, rpcSub.ID, msgs)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-acSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.Subscription, error) {
+	s.log.Info("NewLogs")
+	return s.newLogs(ctx, LogsSubscription, param)
+}
+
+func (s *SubscribeApi) NewConfirmedLogs(ctx context.Context, param RpcFilterParam) (*rpc.Subscription, error) {
+	s.log.Info("NewConfirmedLogs")
+	return s.newLogs(ctx, ConfirmedLogsSubscription, param)
+}
+
+func (s *SubscribeApi) newLogs(ctx context.Context, typ FilterType, param RpcFilterParam) (*rpc.Subscription, error) {
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	p, err := param.toFilterParam(s.anyAddrLogs)
+	if err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		logsCh := make(chan []*LogsMsg, 128)
+		logsSub := Es.subscribeLogs(typ, p, logsCh)
+		defer logsSub.Unsubscribe()
+
+		for {
+			select {
+			case msgs := <-logsCh:
+				notify(notifier, rpcSub.ID, msgs)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-logsSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+func (s *SubscribeApi) NewOnroadBlocks(ctx context.Context, param RpcOnroadFilterParam) (*rpc.Subscription, error) {
+	s.log.Info("NewOnroadBlocks")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	p, err := param.toFilterParam()
+	if err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		onroadCh := make(chan []*OnroadMsg, 128)
+		onroadSub := Es.SubscribeOnroadBlocks(p, onroadCh)
+		defer onroadSub.Unsubscribe()
+
+		for {
+			select {
+			case msgs := <-onroadCh:
+				notify(notifier, rpcSub.ID, msgs)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-onroadSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+func (s *SubscribeApi) NewSnapshotBlocks(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("NewSnapshotBlocks")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		sbCh := make(chan []*SnapshotBlocksMsg, 128)
+		sbSub := Es.SubscribeSnapshotBlocks(sbCh)
+		defer sbSub.Unsubscribe()
+
+		for {
+			select {
+			case msgs := <-sbCh:
+				notify(notifier, rpcSub.ID, msgs)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-sbSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// NewReorg notifies a message for each rollback of the snapshot chain, with the fork point and
+// the reverted snapshot blocks, so that indexers need not infer rollbacks from removed messages.
+func (s *SubscribeApi) NewReorg(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("NewReorg")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		reorgCh := make(chan *ReorgMsg, 128)
+		reorgSub := Es.SubscribeReorg(reorgCh)
+		defer reorgSub.Unsubscribe()
+
+		for {
+			select {
+			case msg := <-reorgCh:
+				notify(notifier, rpcSub.ID, msg)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-reorgSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// NewSyncState notifies the current sync state and then a message for each transition of it,
+// so that orchestration tools can hold traffic back until the node finishes syncing.
+func (s *SubscribeApi) NewSyncState(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("NewSyncState")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		syncStateCh := make(chan *SyncStateMsg, 128)
+		syncStateSub := Es.SubscribeSyncState(syncStateCh)
+		defer syncStateSub.Unsubscribe()
+
+		if msg := Es.SyncState(); msg != nil {
+			notify(notifier, rpcSub.ID, msg)
+		}
+		for {
+			select {
+			case msg := <-syncStateCh:
+				notify(notifier, rpcSub.ID, msg)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-syncStateSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// NewPeerEvents notifies a message for each peer added or dropped, with the reason a peer is dropped,
+// so that operators can alert on peer churn.
+func (s *SubscribeApi) NewPeerEvents(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("NewPeerEvents")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		peerCh := make(chan *PeerEventMsg, 128)
+		peerSub := Es.SubscribePeerEvents(peerCh)
+		defer peerSub.Unsubscribe()
+
+		for {
+			select {
+			case msg := <-peerCh:
+				notify(notifier, rpcSub.ID, msg)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-peerSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// NewContractEvents notifies the decoded registrations, votes and pledges of the built-in contracts,
+// so that clients don't need to subscribe the raw blocks of the contracts and decode their calls.
+func (s *SubscribeApi) NewContractEvents(ctx context.Context, param *RpcContractEventsParam) (*rpc.Subscription, error) {
+	s.log.Info("NewContractEvents")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	p, err := param.toFilterParam()
+	if err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		contractCh := make(chan []*ContractEventMsg, 128)
+		contractSub := Es.SubscribeContractEvents(p, contractCh)
+		defer contractSub.Unsubscribe()
+
+		for {
+			select {
+			case msgs := <-contractCh:
+				notify(notifier, rpcSub.ID, msgs)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-contractSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// NewReceipt notifies the receipt of the send block param identifies once, when its receive block is
+// confirmed, so that dApps need not poll ledger_getBlockByHash to learn whether a call succeeded.
+// It's notified at once if the receive block is confirmed already.
+func (s *SubscribeApi) NewReceipt(ctx context.Context, param *RpcReceiptParam) (*rpc.Subscription, error) {
+	s.log.Info("NewReceipt")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	sendHash, err := param.toSendBlockHash(s.vite.Chain())
+	if err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		receiptCh := make(chan *ReceiptMsg, 1)
+		receiptSub := Es.SubscribeReceipt(sendHash, receiptCh)
+		defer receiptSub.Unsubscribe()
+
+		// the receive block may be confirmed before the subscription is installed
+		msg, err := Es.Receipt(sendHash)
+		if err != nil {
+			s.log.Error("Receipt failed, error is "+err.Error(), "method", "NewReceipt")
+		}
+		if msg == nil {
+			select {
+			case msg = <-receiptCh:
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-receiptSub.Err():
+				return
+			}
+		}
+		notify(notifier, rpcSub.ID, msg)
+	}()
+	return rpcSub, nil
+}
+
+// NewQuotaEvents notifies the changes of the pledge amount benefiting addr and the changes of the quota
+// of addr crossing the thresholds in param, checked at every snapshot block, so that bots can pledge
+// more before they run out of quota. param may be nil to be notified of the pledge changes only.
+func (s *SubscribeApi) NewQuotaEvents(ctx context.Context, addr types.Address, param *RpcQuotaEventsParam) (*rpc.Subscription, error) {
+	s.log.Info("NewQuotaEvents")
+	if Es == nil {
+		return nil, ErrSubscribeDisabled
+	}
+	p, err := param.toQuotaEventsParam(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err = Es.initQuotaEventsParam(p); err != nil {
+		return nil, err
+	}
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	if err := acquireSubscription(notifier, s.maxSubscriptions); err != nil {
+		return nil, err
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		defer releaseSubscription(notifier)
+		quotaCh := make(chan []*QuotaEventMsg, 128)
+		quotaSub := Es.SubscribeQuotaEvents(p, quotaCh)
+		defer quotaSub.Unsubscribe()
+
+		for {
+			select {
+			case msgs := <-quotaCh:
+				notify(notifier, rpcSub.ID, msgs)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-quotaSub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
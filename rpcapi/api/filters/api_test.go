@@ -0,0 +1,277 @@
+package filters
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/vite/net"
+)
+
+func TestSubscribeApi_GetFilterChanges(t *testing.T) {
+	s := &SubscribeApi{log: log15.New("module", "test"), filters: make(map[rpc.ID]*filter), deadline: defaultDeadline}
+	blockMsg := &AccountBlocksMsg{Hash: types.DataHash([]byte("block"))}
+	logMsg := &LogsMsg{Log: &ledger.VmLog{}, AccountBlockHash: types.DataHash([]byte("log"))}
+
+	s.filters["ab"] = &filter{typ: AccountBlocksSubscription, deadline: time.NewTimer(defaultDeadline), blocks: []*AccountBlocksMsg{blockMsg}}
+	s.filters["logs"] = &filter{typ: LogsSubscription, deadline: time.NewTimer(defaultDeadline), logs: []*LogsMsg{logMsg}}
+	s.filters["cab"] = &filter{typ: ConfirmedAccountBlocksSubscription, deadline: time.NewTimer(defaultDeadline), confirmedBlocks: []*AccountBlocksMsg{blockMsg}}
+	s.filters["clogs"] = &filter{typ: ConfirmedLogsSubscription, deadline: time.NewTimer(defaultDeadline), confirmedLogs: []*LogsMsg{logMsg}}
+
+	for _, id := range []rpc.ID{"ab", "cab"} {
+		changes, err := s.GetFilterChanges(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blocks, ok := changes.([]*AccountBlocksMsg); !ok || len(blocks) != 1 || blocks[0] != blockMsg {
+			t.Fatalf("filter %v: unexpected changes %v", id, changes)
+		}
+		changes, _ = s.GetFilterChanges(id, nil)
+		if blocks := changes.([]*AccountBlocksMsg); len(blocks) != 0 {
+			t.Fatalf("filter %v: changes are not drained", id)
+		}
+	}
+
+	for _, id := range []rpc.ID{"logs", "clogs"} {
+		changes, err := s.GetFilterChanges(id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if logs, ok := changes.([]*LogsMsg); !ok || len(logs) != 1 || logs[0] != logMsg {
+			t.Fatalf("filter %v: unexpected changes %v", id, changes)
+		}
+		changes, _ = s.GetFilterChanges(id, nil)
+		if logs := changes.([]*LogsMsg); len(logs) != 0 {
+			t.Fatalf("filter %v: changes are not drained", id)
+		}
+	}
+
+	if _, err := s.GetFilterChanges("unknown", nil); err != ErrFilterNotFound {
+		t.Fatalf("expected ErrFilterNotFound, got %v", err)
+	}
+}
+
+func TestConfirmedEvents(t *testing.T) {
+	events := []*AccountChainEvent{{Hash: types.DataHash([]byte("1")), Confirmed: true}, {Hash: types.DataHash([]byte("2"))}}
+	if confirmed := confirmedEvents(events); len(confirmed) != 1 || confirmed[0] != events[0] {
+		t.Fatalf("unexpected confirmed events %v", confirmed)
+	}
+}
+
+func TestHandleSnapshotEvent(t *testing.T) {
+	es := &EventSystem{stop: make(chan struct{})}
+	ch := make(chan []*SnapshotBlocksMsg, 1)
+	subs := map[rpc.ID]*subscription{"sb": {typ: SnapshotBlocksSubscription, snapshotCh: ch}}
+
+	now := time.Unix(1000, 0)
+	blocks := []*ledger.SnapshotBlock{{Hash: types.DataHash([]byte("sb")), Height: 10, Timestamp: &now}}
+	es.handleSnapshotEvent(subs, blocks, true)
+
+	msgs := <-ch
+	if len(msgs) != 1 || msgs[0].Hash != blocks[0].Hash || msgs[0].Height != "10" || msgs[0].Timestamp != 1000 || !msgs[0].Removed {
+		t.Fatalf("unexpected msgs %+v", msgs)
+	}
+}
+
+func TestHandleSyncStateEvent(t *testing.T) {
+	es := &EventSystem{stop: make(chan struct{})}
+	ch := make(chan *SyncStateMsg, 1)
+	subs := map[rpc.ID]*subscription{"sync": {typ: SyncStateSubscription, syncStateCh: ch}}
+
+	es.handleSyncStateEvent(subs, net.Syncdone)
+	if msg := <-ch; msg.State != uint(net.Syncdone) || msg.Status != net.Syncdone.String() || !msg.Done {
+		t.Fatalf("unexpected msg %+v", msg)
+	}
+	if msg := newSyncStateMsg(net.Syncerr); msg.Done {
+		t.Fatalf("unexpected msg %+v", msg)
+	}
+	if es.SyncState() != nil {
+		t.Fatal("sync state should be nil without net")
+	}
+}
+
+func TestHandlePeerEvent(t *testing.T) {
+	es := &EventSystem{stop: make(chan struct{})}
+	ch := make(chan *PeerEventMsg, 1)
+	subs := map[rpc.ID]*subscription{"peer": {typ: PeerEventsSubscription, peerCh: ch}}
+
+	info := net.PeerInfo{ID: "abc", Addr: "127.0.0.1:8483", Height: 100}
+	es.handlePeerEvent(subs, net.PeerEvent{Peer: info, Count: 2, Reason: "read timeout"})
+	if msg := <-ch; msg.Event != PeerDropped || msg.ID != info.ID || msg.Addr != info.Addr || msg.Height != "100" ||
+		msg.PeerCount != 2 || msg.Reason != "read timeout" {
+		t.Fatalf("unexpected msg %+v", msg)
+	}
+	if msg := newPeerEventMsg(net.PeerEvent{Added: true, Peer: info, Count: 3}); msg.Event != PeerAdded || msg.Reason != "" {
+		t.Fatalf("unexpected msg %+v", msg)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	atomic.StoreInt64(&installedFilters, 0)
+	s := NewSubscribeApi(nil, WithMaxFilters(1), WithMaxSubscriptions(1))
+	if err := reserveFilter(s.maxFilters); err != nil {
+		t.Fatal(err)
+	}
+	if err := reserveFilter(s.maxFilters); err != ErrTooManyFilters {
+		t.Fatalf("expected ErrTooManyFilters, got %v", err)
+	}
+	s.filters["f"] = &filter{}
+	s.removeFilter("f")
+	if err := reserveFilter(s.maxFilters); err != nil {
+		t.Fatalf("filter count should be released, got %v", err)
+	}
+
+	n1, n2 := &rpc.Notifier{}, &rpc.Notifier{}
+	if err := acquireSubscription(n1, s.maxSubscriptions); err != nil {
+		t.Fatal(err)
+	}
+	if err := acquireSubscription(n1, s.maxSubscriptions); err != ErrTooManySubscriptions {
+		t.Fatalf("expected ErrTooManySubscriptions, got %v", err)
+	}
+	if err := acquireSubscription(n2, s.maxSubscriptions); err != nil {
+		t.Fatalf("subscriptions of another connection should not be limited, got %v", err)
+	}
+	releaseSubscription(n1)
+	if err := acquireSubscription(n1, s.maxSubscriptions); err != nil {
+		t.Fatalf("subscription count should be released, got %v", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	s := NewSubscribeApi(nil, WithMaxBuffered(1, OverflowDropOldest))
+	before := Status()
+
+	reserveFilter(0)
+	reserveFilter(0)
+	s.installFilter(ReorgSubscription, &RpcSubscription{ID: "reorg"})
+	s.installFilter(SnapshotBlocksSubscription, &RpcSubscription{ID: "sb"})
+	s.filtersMu.Lock()
+	s.filters["reorg"].created = time.Now().Add(-time.Minute)
+	f := s.filters["sb"]
+	drop, keep := f.makeRoom(len(f.snapshotBlocks), 3)
+	f.snapshotBlocks = append(f.snapshotBlocks[drop:], make([]*SnapshotBlocksMsg, keep)...)
+	s.filtersMu.Unlock()
+
+	status := Status()
+	if status.Filters != before.Filters+2 || status.Buffered != before.Buffered+1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if sb := status.Types[SnapshotBlocksSubscription.String()]; sb.Filters != before.Types["snapshotBlocks"].Filters+1 || sb.Buffered < 1 {
+		t.Fatalf("unexpected snapshot blocks status %+v", sb)
+	}
+	if status.DroppedNotifications != before.DroppedNotifications+2 || status.OldestFilterAge < 60 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	s.removeFilter("reorg")
+	s.removeFilter("sb")
+	if status := Status(); status.Filters != before.Filters {
+		t.Fatalf("unexpected status %+v", status)
+	}
+}
+
+func TestNewSubscribeApi_options(t *testing.T) {
+	s := NewSubscribeApi(nil, WithDeadline(time.Hour), WithSweepInterval(0))
+	if s.deadline != time.Hour || s.sweepInterval != defaultSweepInterval {
+		t.Fatalf("unexpected deadline %v, sweep interval %v", s.deadline, s.sweepInterval)
+	}
+}
+
+func TestSubscribeApi_overflow(t *testing.T) {
+	s := &SubscribeApi{log: log15.New("module", "test"), filters: make(map[rpc.ID]*filter), deadline: defaultDeadline}
+	msgs := func(from, to int) []*SnapshotBlocksMsg {
+		var list []*SnapshotBlocksMsg
+		for i := from; i < to; i++ {
+			list = append(list, &SnapshotBlocksMsg{Hash: types.DataHash([]byte{byte(i)})})
+		}
+		return list
+	}
+	push := func(f *filter, list []*SnapshotBlocksMsg) {
+		drop, keep := f.makeRoom(len(f.snapshotBlocks), len(list))
+		f.snapshotBlocks = append(f.snapshotBlocks[drop:], list[len(list)-keep:]...)
+	}
+
+	dropOldest := &filter{typ: SnapshotBlocksSubscription, deadline: time.NewTimer(defaultDeadline), maxBuffered: 3, policy: OverflowDropOldest}
+	s.filters["dropOldest"] = dropOldest
+	push(dropOldest, msgs(0, 2))
+	push(dropOldest, msgs(2, 4))
+	changes, err := s.GetFilterChangesWithStatus("dropOldest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := changes.Changes.([]*SnapshotBlocksMsg)
+	if !changes.Overflowed || len(blocks) != 3 || blocks[0].Hash != types.DataHash([]byte{1}) {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	push(dropOldest, msgs(0, 5))
+	changes, _ = s.GetFilterChangesWithStatus("dropOldest")
+	if blocks = changes.Changes.([]*SnapshotBlocksMsg); len(blocks) != 3 || blocks[2].Hash != types.DataHash([]byte{4}) {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	if changes, _ = s.GetFilterChangesWithStatus("dropOldest"); changes.Overflowed {
+		t.Fatal("overflowed should be reset after poll")
+	}
+
+	errorPolicy := &filter{typ: SnapshotBlocksSubscription, deadline: time.NewTimer(defaultDeadline), maxBuffered: 3, policy: OverflowError}
+	s.filters["error"] = errorPolicy
+	push(errorPolicy, msgs(0, 4))
+	if _, err := s.GetFilterChanges("error", nil); err != ErrFilterOverflowed {
+		t.Fatalf("expected ErrFilterOverflowed, got %v", err)
+	}
+	push(errorPolicy, msgs(0, 1))
+	if changes, err := s.GetFilterChanges("error", nil); err != nil || len(changes.([]*SnapshotBlocksMsg)) != 1 {
+		t.Fatalf("unexpected changes %v %v", changes, err)
+	}
+}
+
+func TestSubscribeApi_GetFilterChangesPaged(t *testing.T) {
+	s := &SubscribeApi{log: log15.New("module", "test"), filters: make(map[rpc.ID]*filter), deadline: defaultDeadline}
+	var logs []*LogsMsg
+	for i := 0; i < 5; i++ {
+		logs = append(logs, &LogsMsg{Log: &ledger.VmLog{}, AccountBlockHash: types.DataHash([]byte{byte(i)})})
+	}
+	f := &filter{typ: LogsSubscription, deadline: time.NewTimer(defaultDeadline), logs: logs}
+	s.filters["logs"] = f
+
+	page := func(cursor string, limit int) *FilterPage {
+		changes, err := s.GetFilterChanges("logs", &RpcPageParam{Cursor: cursor, Limit: limit})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changes.(*FilterPage)
+	}
+
+	p := page("", 2)
+	if got := p.Changes.([]*LogsMsg); len(got) != 2 || got[0] != logs[0] || p.NextCursor != "2" || !p.More {
+		t.Fatalf("unexpected page %+v", p)
+	}
+	// a page is kept until it is acknowledged by the next cursor
+	if p = page("", 2); p.Changes.([]*LogsMsg)[0] != logs[0] {
+		t.Fatalf("unacknowledged page is removed")
+	}
+	p = page("2", 2)
+	if got := p.Changes.([]*LogsMsg); len(got) != 2 || got[0] != logs[2] || p.NextCursor != "4" || !p.More {
+		t.Fatalf("unexpected page %+v", p)
+	}
+	p = page("4", 0)
+	if got := p.Changes.([]*LogsMsg); len(got) != 1 || got[0] != logs[4] || p.NextCursor != "5" || p.More {
+		t.Fatalf("unexpected page %+v", p)
+	}
+
+	if _, err := s.GetFilterChanges("logs", &RpcPageParam{Cursor: "6"}); err != ErrInvalidCursor {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+
+	// unpaged polls move the cursor too
+	f.logs = append(f.logs, logs[0], logs[1])
+	if changes, _ := s.GetFilterChanges("logs", nil); len(changes.([]*LogsMsg)) != 3 {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	if p = page("5", 0); len(p.Changes.([]*LogsMsg)) != 0 || p.NextCursor != "7" || p.More {
+		t.Fatalf("unexpected page %+v", p)
+	}
+}
@@ -0,0 +1,236 @@
+package filters
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/rpc"
+)
+
+const (
+	maxDurableFilterIdLen = 64
+	// durableReplaySize limits the count of snapshot blocks whose confirmed logs are returned by one poll
+	durableReplaySize = 100
+)
+
+// durableLock serializes the polls of durable filters, which read and write their heights in the chain db
+var durableLock sync.Mutex
+
+// durableFilter is the persisted state of a durable filter, Height is the height of the latest
+// snapshot block whose confirmed logs have been delivered.
+type durableFilter struct {
+	Param  RpcFilterParam `json:"param"`
+	Height uint64         `json:"height"`
+}
+
+// validDurableFilterId reports whether id is made of at most 64 letters, digits, '-' and '_'.
+func validDurableFilterId(id string) bool {
+	if len(id) == 0 || len(id) > maxDurableFilterIdLen {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func loadDurableFilter(c chain.Chain, id string) (*durableFilter, error) {
+	data, err := c.GetRpcFilter(id)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	f := new(durableFilter)
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func saveDurableFilter(c chain.Chain, id string, f *durableFilter) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return c.SaveRpcFilter(id, data)
+}
+
+// NewDurableLogsFilter installs a confirmed logs filter identified by id, which is persisted in the chain db
+// with the height of the latest snapshot block it has delivered, so that the logs confirmed while the node or
+// the client is down are returned by the next poll. It never expires and is removed by UninstallFilter only.
+func (s *SubscribeApi) NewDurableLogsFilter(id string, param RpcFilterParam) (rpc.ID, error) {
+	s.log.Info("NewDurableLogsFilter", "id", id)
+	if Es == nil {
+		return "", ErrSubscribeDisabled
+	}
+	if !validDurableFilterId(id) {
+		return "", ErrInvalidFilterId
+	}
+	if _, err := param.toFilterParam(s.anyAddrLogs); err != nil {
+		return "", err
+	}
+
+	durableLock.Lock()
+	defer durableLock.Unlock()
+
+	c := s.vite.Chain()
+	if s.installed(rpc.ID(id)) {
+		return "", ErrFilterExists
+	}
+	if f, err := loadDurableFilter(c, id); err != nil {
+		return "", err
+	} else if f != nil {
+		return "", ErrFilterExists
+	}
+	f := &durableFilter{Param: param, Height: c.GetLatestSnapshotBlock().Height}
+	if err := saveDurableFilter(c, id, f); err != nil {
+		return "", err
+	}
+	return rpc.ID(id), nil
+}
+
+// installed reports whether id is a polling filter kept in memory, durable filters are not.
+func (s *SubscribeApi) installed(id rpc.ID) bool {
+	s.filtersMu.Lock()
+	defer s.filtersMu.Unlock()
+	_, found := s.filters[id]
+	return found
+}
+
+func (s *SubscribeApi) uninstallDurableFilter(id rpc.ID) bool {
+	if Es == nil {
+		return false
+	}
+
+	durableLock.Lock()
+	defer durableLock.Unlock()
+
+	c := s.vite.Chain()
+	if f, err := loadDurableFilter(c, string(id)); err != nil || f == nil {
+		return false
+	}
+	if err := c.DeleteRpcFilter(string(id)); err != nil {
+		s.log.Error("DeleteRpcFilter failed, error is "+err.Error(), "method", "uninstallDurableFilter")
+		return false
+	}
+	return true
+}
+
+// durableRange returns the snapshot heights (from, to] whose confirmed logs a poll returns. from is the
+// cursor of the poll, or height if it has none, and it must not be below height, the delivered ones
+// are never polled again.
+func durableRange(height, latest uint64, cursor string) (from, to uint64, err error) {
+	from = height
+	if cursor != "" {
+		if from, err = strconv.ParseUint(cursor, 10, 64); err != nil || from < height || from > latest {
+			return 0, 0, ErrInvalidCursor
+		}
+	}
+	// the snapshot blocks above latest were rolled back, the logs confirmed again are delivered again
+	if from > latest {
+		from = latest
+	}
+	to = latest
+	if to-from > durableReplaySize {
+		to = from + durableReplaySize
+	}
+	return from, to, nil
+}
+
+// pollDurableFilter returns the logs confirmed after the last delivered snapshot block of the durable filter id.
+// Without page, the logs returned are acknowledged at once. With page, they are acknowledged by the cursor of
+// the next page, page.Limit is ignored because a page holds the logs of at most 100 snapshot blocks.
+func (s *SubscribeApi) pollDurableFilter(id rpc.ID, page *RpcPageParam) (*FilterPage, error) {
+	if Es == nil {
+		return nil, ErrFilterNotFound
+	}
+
+	durableLock.Lock()
+	defer durableLock.Unlock()
+
+	c := s.vite.Chain()
+	f, err := loadDurableFilter(c, string(id))
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrFilterNotFound
+	}
+	p, err := f.Param.toFilterParam(s.anyAddrLogs)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := c.GetLatestSnapshotBlock().Height
+	var cursor string
+	if page != nil {
+		cursor = page.Cursor
+	}
+	from, to, err := durableRange(f.Height, latest, cursor)
+	if err != nil {
+		return nil, err
+	}
+	msgs, err := confirmedLogs(c, p, from+1, to)
+	if err != nil {
+		return nil, err
+	}
+
+	acked := from
+	if page == nil {
+		acked = to
+	}
+	if acked != f.Height {
+		f.Height = acked
+		if err := saveDurableFilter(c, string(id), f); err != nil {
+			return nil, err
+		}
+	}
+	return &FilterPage{Changes: msgs, NextCursor: strconv.FormatUint(to, 10), More: to < latest}, nil
+}
+
+// confirmedLogs returns the logs matching param of the account blocks confirmed by the snapshot blocks
+// from fromHeight to toHeight, ordered by snapshot block, address and account block height.
+func confirmedLogs(c chain.Chain, param *filterParam, fromHeight, toHeight uint64) ([]*LogsMsg, error) {
+	msgs := make([]*LogsMsg, 0)
+	if fromHeight > toHeight {
+		return msgs, nil
+	}
+	snapshotBlocks, err := c.GetSnapshotBlocksByHeight(fromHeight, toHeight-fromHeight+1, true, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, sb := range snapshotBlocks {
+		subLedger, err := c.GetConfirmSubLedgerBySnapshotBlocks([]*ledger.SnapshotBlock{sb})
+		if err != nil {
+			return nil, err
+		}
+		addrList := make([]types.Address, 0, len(subLedger))
+		for addr := range subLedger {
+			addrList = append(addrList, addr)
+		}
+		sort.Slice(addrList, func(i, j int) bool { return bytes.Compare(addrList[i].Bytes(), addrList[j].Bytes()) < 0 })
+
+		var events []*AccountChainEvent
+		for _, addr := range addrList {
+			for _, b := range subLedger[addr] {
+				if b.LogHash == nil || !param.matchAddr(b.AccountAddress, b.Height) {
+					continue
+				}
+				logs, err := c.GetVmLogList(b.LogHash)
+				if err != nil {
+					return nil, err
+				}
+				events = append(events, newAccountChainEvent(b, logs))
+			}
+		}
+		msgs = append(msgs, Es.filterLogs(events, param, false)...)
+	}
+	return resolveLogEvents(c, msgs), nil
+}
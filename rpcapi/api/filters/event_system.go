@@ -0,0 +1,1203 @@
+package filters
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vite/net"
+	"github.com/vitelabs/go-vite/vm"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+type FilterType byte
+
+const (
+	AccountBlocksSubscription FilterType = iota
+	LogsSubscription
+	OnroadBlocksSubscription
+	ConfirmedAccountBlocksSubscription
+	ConfirmedLogsSubscription
+	SnapshotBlocksSubscription
+	ReorgSubscription
+	SyncStateSubscription
+	PeerEventsSubscription
+	ContractEventsSubscription
+	ReceiptSubscription
+	QuotaEventsSubscription
+)
+
+var filterTypes = []FilterType{AccountBlocksSubscription, LogsSubscription, OnroadBlocksSubscription,
+	ConfirmedAccountBlocksSubscription, ConfirmedLogsSubscription, SnapshotBlocksSubscription, ReorgSubscription, SyncStateSubscription, PeerEventsSubscription, ContractEventsSubscription,
+	ReceiptSubscription, QuotaEventsSubscription}
+
+var filterTypeNames = map[FilterType]string{
+	AccountBlocksSubscription:          "accountBlocks",
+	LogsSubscription:                   "logs",
+	OnroadBlocksSubscription:           "onroadBlocks",
+	ConfirmedAccountBlocksSubscription: "confirmedAccountBlocks",
+	ConfirmedLogsSubscription:          "confirmedLogs",
+	SnapshotBlocksSubscription:         "snapshotBlocks",
+	ReorgSubscription:                  "reorg",
+	SyncStateSubscription:              "syncState",
+	PeerEventsSubscription:             "peerEvents",
+	ContractEventsSubscription:         "contractEvents",
+	ReceiptSubscription:                "receipt",
+	QuotaEventsSubscription:            "quotaEvents",
+}
+
+func (t FilterType) String() string {
+	if name, ok := filterTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+const (
+	acChanSize = 100
+)
+
+// Es is the event system shared by all subscribe apis, it is nil if subscribe is disabled.
+var Es *EventSystem
+
+type AccountChainEvent struct {
+	Hash   types.Hash
+	Height uint64
+	Addr   types.Address
+	Block  *ledger.AccountBlock
+	Logs   []*ledger.VmLog
+
+	// Confirmed is set on deleted blocks which had been confirmed by a snapshot block
+	Confirmed bool
+}
+
+type subscription struct {
+	id          rpc.ID
+	typ         FilterType
+	createTime  time.Time
+	param       *filterParam
+	onroadParam *onroadFilterParam
+	blockParam  *accountBlocksParam
+	eventParam  *contractEventsParam
+	addrSet     map[types.Address]struct{}
+	sendHash    types.Hash
+	quotaParam  *quotaEventsParam
+
+	accountBlockCh chan []*AccountBlocksMsg
+	logsCh         chan []*LogsMsg
+	onroadCh       chan []*OnroadMsg
+	snapshotCh     chan []*SnapshotBlocksMsg
+	reorgCh        chan *ReorgMsg
+	syncStateCh    chan *SyncStateMsg
+	peerCh         chan *PeerEventMsg
+	contractCh     chan []*ContractEventMsg
+	receiptCh      chan *ReceiptMsg
+	quotaCh        chan []*QuotaEventMsg
+
+	installed chan struct{}
+	err       chan error
+}
+
+type RpcSubscription struct {
+	ID        rpc.ID
+	sub       *subscription
+	es        *EventSystem
+	unsubOnce sync.Once
+}
+
+func (s *RpcSubscription) Err() <-chan error {
+	return s.sub.err
+}
+
+func (s *RpcSubscription) Unsubscribe() {
+	s.unsubOnce.Do(func() {
+	uninstallLoop:
+		for {
+			select {
+			case s.es.uninstall <- s.sub:
+				break uninstallLoop
+			case <-s.es.stop:
+				return
+			// drain the channels the event loop may be blocked on
+			case <-s.sub.accountBlockCh:
+			case <-s.sub.logsCh:
+			case <-s.sub.onroadCh:
+			case <-s.sub.snapshotCh:
+			case <-s.sub.reorgCh:
+			case <-s.sub.syncStateCh:
+			case <-s.sub.peerCh:
+			case <-s.sub.contractCh:
+			case <-s.sub.receiptCh:
+			case <-s.sub.quotaCh:
+			}
+		}
+		<-s.Err()
+	})
+}
+
+type EventSystem struct {
+	chain chain.Chain
+	net   net.Net
+
+	install   chan *subscription
+	uninstall chan *subscription
+	acCh      chan []*AccountChainEvent
+	acDelCh   chan []*AccountChainEvent
+	confirmCh chan []*AccountChainEvent
+	sbCh      chan []*ledger.SnapshotBlock
+	sbDelCh   chan []*ledger.SnapshotBlock
+	syncCh    chan net.SyncState
+	peerCh    chan net.PeerEvent
+	stop      chan struct{}
+	wg        sync.WaitGroup
+
+	insertSuccLid         uint64
+	deleteLid             uint64
+	deleteSuccLid         uint64
+	insertSnapshotSuccLid uint64
+	deleteSnapshotSuccLid uint64
+	syncSubId             int
+	peerSubId             int
+
+	deletedLogsLock sync.Mutex
+	deletedLogs     map[types.Hash]ledger.VmLogList
+	deletedConfirm  map[types.Hash]bool
+
+	log log15.Logger
+}
+
+func NewEventSystem(v *vite.Vite) *EventSystem {
+	return &EventSystem{
+		chain:          v.Chain(),
+		net:            v.Net(),
+		install:        make(chan *subscription),
+		uninstall:      make(chan *subscription),
+		acCh:           make(chan []*AccountChainEvent, acChanSize),
+		acDelCh:        make(chan []*AccountChainEvent, acChanSize),
+		confirmCh:      make(chan []*AccountChainEvent, acChanSize),
+		sbCh:           make(chan []*ledger.SnapshotBlock, acChanSize),
+		sbDelCh:        make(chan []*ledger.SnapshotBlock, acChanSize),
+		syncCh:         make(chan net.SyncState, acChanSize),
+		peerCh:         make(chan net.PeerEvent, acChanSize),
+		stop:           make(chan struct{}),
+		deletedLogs:    make(map[types.Hash]ledger.VmLogList),
+		deletedConfirm: make(map[types.Hash]bool),
+		log:            log15.New("module", "rpc_api/event_system"),
+	}
+}
+
+func (es *EventSystem) Start() {
+	es.insertSuccLid = es.chain.RegisterInsertAccountBlocksSuccess(es.insertAccountBlocksSuccess)
+	es.deleteLid = es.chain.RegisterDeleteAccountBlocks(es.prepareDeleteAccountBlocks)
+	es.deleteSuccLid = es.chain.RegisterDeleteAccountBlocksSuccess(es.deleteAccountBlocksSuccess)
+	es.insertSnapshotSuccLid = es.chain.RegisterInsertSnapshotBlocksSuccess(es.insertSnapshotBlocksSuccess)
+	es.deleteSnapshotSuccLid = es.chain.RegisterDeleteSnapshotBlocksSuccess(es.deleteSnapshotBlocksSuccess)
+	if es.net != nil {
+		es.syncSubId = es.net.SubscribeSyncStatus(es.syncStateChanged)
+		es.peerSubId = es.net.SubscribePeerEvent(es.peerChanged)
+	}
+
+	es.wg.Add(1)
+	go es.eventLoop()
+	es.log.Info("event system start")
+}
+
+func (es *EventSystem) Stop() {
+	es.chain.UnRegister(es.insertSuccLid)
+	es.chain.UnRegister(es.deleteLid)
+	es.chain.UnRegister(es.deleteSuccLid)
+	es.chain.UnRegister(es.insertSnapshotSuccLid)
+	es.chain.UnRegister(es.deleteSnapshotSuccLid)
+	if es.net != nil {
+		es.net.UnsubscribeSyncStatus(es.syncSubId)
+		es.net.UnsubscribePeerEvent(es.peerSubId)
+	}
+
+	close(es.stop)
+	es.wg.Wait()
+	es.log.Info("event system stop")
+}
+
+func (es *EventSystem) insertAccountBlocksSuccess(blocks []*vm_context.VmAccountBlock) {
+	events := make([]*AccountChainEvent, 0, len(blocks))
+	for _, b := range blocks {
+		var logs []*ledger.VmLog
+		if b.VmContext != nil && b.VmContext.UnsavedCache() != nil {
+			logs = b.VmContext.UnsavedCache().LogList()
+		}
+		events = append(events, newAccountChainEvent(b.AccountBlock, logs))
+	}
+	es.send(es.acCh, events)
+}
+
+// prepareDeleteAccountBlocks keeps the vm logs of blocks to be deleted, because
+// they are no longer readable from chain once deletion is done.
+func (es *EventSystem) prepareDeleteAccountBlocks(batch *leveldb.Batch, subLedger map[types.Address][]*ledger.AccountBlock) error {
+	es.deletedLogsLock.Lock()
+	defer es.deletedLogsLock.Unlock()
+
+	for _, blocks := range subLedger {
+		for _, b := range blocks {
+			confirmBlock, err := es.chain.GetConfirmBlock(&b.Hash)
+			if err != nil {
+				es.log.Error("GetConfirmBlock failed, error is "+err.Error(), "method", "prepareDeleteAccountBlocks")
+			}
+			if confirmBlock != nil {
+				es.deletedConfirm[b.Hash] = true
+			}
+
+			if b.LogHash == nil {
+				continue
+			}
+			logList, err := es.chain.GetVmLogList(b.LogHash)
+			if err != nil {
+				es.log.Error("GetVmLogList failed, error is "+err.Error(), "method", "prepareDeleteAccountBlocks")
+				continue
+			}
+			es.deletedLogs[b.Hash] = logList
+		}
+	}
+	return nil
+}
+
+func (es *EventSystem) insertSnapshotBlocksSuccess(snapshotBlocks []*ledger.SnapshotBlock) {
+	es.sendSnapshotBlocks(es.sbCh, snapshotBlocks)
+
+	subLedger, err := es.chain.GetConfirmSubLedgerBySnapshotBlocks(snapshotBlocks)
+	if err != nil {
+		es.log.Error("GetConfirmSubLedgerBySnapshotBlocks failed, error is "+err.Error(), "method", "insertSnapshotBlocksSuccess")
+		return
+	}
+	var events []*AccountChainEvent
+	for _, blocks := range subLedger {
+		for _, b := range blocks {
+			var logs []*ledger.VmLog
+			if b.LogHash != nil {
+				if logs, err = es.chain.GetVmLogList(b.LogHash); err != nil {
+					es.log.Error("GetVmLogList failed, error is "+err.Error(), "method", "insertSnapshotBlocksSuccess")
+				}
+			}
+			events = append(events, newAccountChainEvent(b, logs))
+		}
+	}
+	es.send(es.confirmCh, events)
+}
+
+func (es *EventSystem) deleteSnapshotBlocksSuccess(snapshotBlocks []*ledger.SnapshotBlock) {
+	es.sendSnapshotBlocks(es.sbDelCh, snapshotBlocks)
+}
+
+func (es *EventSystem) deleteAccountBlocksSuccess(subLedger map[types.Address][]*ledger.AccountBlock) {
+	es.deletedLogsLock.Lock()
+	var events []*AccountChainEvent
+	for _, blocks := range subLedger {
+		for _, b := range blocks {
+			e := newAccountChainEvent(b, es.deletedLogs[b.Hash])
+			e.Confirmed = es.deletedConfirm[b.Hash]
+			events = append(events, e)
+			delete(es.deletedLogs, b.Hash)
+			delete(es.deletedConfirm, b.Hash)
+		}
+	}
+	es.deletedLogsLock.Unlock()
+
+	es.send(es.acDelCh, events)
+}
+
+func newAccountChainEvent(block *ledger.AccountBlock, logs []*ledger.VmLog) *AccountChainEvent {
+	return &AccountChainEvent{
+		Hash:   block.Hash,
+		Height: block.Height,
+		Addr:   block.AccountAddress,
+		Block:  block,
+		Logs:   logs,
+	}
+}
+
+func (es *EventSystem) send(ch chan<- []*AccountChainEvent, events []*AccountChainEvent) {
+	if len(events) == 0 {
+		return
+	}
+	select {
+	case ch <- events:
+	case <-es.stop:
+	}
+}
+
+func (es *EventSystem) sendSnapshotBlocks(ch chan<- []*ledger.SnapshotBlock, blocks []*ledger.SnapshotBlock) {
+	if len(blocks) == 0 {
+		return
+	}
+	select {
+	case ch <- blocks:
+	case <-es.stop:
+	}
+}
+
+func (es *EventSystem) syncStateChanged(st net.SyncState) {
+	select {
+	case es.syncCh <- st:
+	case <-es.stop:
+	}
+}
+
+func (es *EventSystem) peerChanged(e net.PeerEvent) {
+	select {
+	case es.peerCh <- e:
+	case <-es.stop:
+	}
+}
+
+// SyncState returns the current sync state of net, it is nil if net is not available.
+func (es *EventSystem) SyncState() *SyncStateMsg {
+	if es.net == nil {
+		return nil
+	}
+	return newSyncStateMsg(es.net.SyncState())
+}
+
+func (es *EventSystem) subscribe(sub *subscription) *RpcSubscription {
+	select {
+	case es.install <- sub:
+		<-sub.installed
+	case <-es.stop:
+		close(sub.err)
+	}
+	return &RpcSubscription{ID: sub.id, sub: sub, es: es}
+}
+
+func (es *EventSystem) SubscribeAccountBlocks(p *accountBlocksParam, ch chan []*AccountBlocksMsg) *RpcSubscription {
+	return es.subscribeAccountBlocks(AccountBlocksSubscription, p, nil, ch)
+}
+
+func (es *EventSystem) SubscribeConfirmedAccountBlocks(p *accountBlocksParam, ch chan []*AccountBlocksMsg) *RpcSubscription {
+	return es.subscribeAccountBlocks(ConfirmedAccountBlocksSubscription, p, nil, ch)
+}
+
+// subscribeAccountBlocks subscribes the account blocks of typ, only the blocks sent from or to
+// the addresses in addrSet are notified unless addrSet is nil.
+func (es *EventSystem) subscribeAccountBlocks(typ FilterType, p *accountBlocksParam, addrSet map[types.Address]struct{}, ch chan []*AccountBlocksMsg) *RpcSubscription {
+	sub := &subscription{
+		id:             rpc.NewID(),
+		typ:            typ,
+		createTime:     time.Now(),
+		blockParam:     p,
+		addrSet:        addrSet,
+		accountBlockCh: ch,
+		installed:      make(chan struct{}),
+		err:            make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) SubscribeLogs(p *filterParam, ch chan []*LogsMsg) *RpcSubscription {
+	return es.subscribeLogs(LogsSubscription, p, ch)
+}
+
+func (es *EventSystem) SubscribeConfirmedLogs(p *filterParam, ch chan []*LogsMsg) *RpcSubscription {
+	return es.subscribeLogs(ConfirmedLogsSubscription, p, ch)
+}
+
+func (es *EventSystem) subscribeLogs(typ FilterType, p *filterParam, ch chan []*LogsMsg) *RpcSubscription {
+	sub := &subscription{
+		id:         rpc.NewID(),
+		typ:        typ,
+		createTime: time.Now(),
+		param:      p,
+		logsCh:     ch,
+		installed:  make(chan struct{}),
+		err:        make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) SubscribeOnroadBlocks(p *onroadFilterParam, ch chan []*OnroadMsg) *RpcSubscription {
+	sub := &subscription{
+		id:          rpc.NewID(),
+		typ:         OnroadBlocksSubscription,
+		createTime:  time.Now(),
+		onroadParam: p,
+		onroadCh:    ch,
+		installed:   make(chan struct{}),
+		err:         make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) SubscribeSnapshotBlocks(ch chan []*SnapshotBlocksMsg) *RpcSubscription {
+	sub := &subscription{
+		id:         rpc.NewID(),
+		typ:        SnapshotBlocksSubscription,
+		createTime: time.Now(),
+		snapshotCh: ch,
+		installed:  make(chan struct{}),
+		err:        make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) SubscribeReorg(ch chan *ReorgMsg) *RpcSubscription {
+	sub := &subscription{
+		id:         rpc.NewID(),
+		typ:        ReorgSubscription,
+		createTime: time.Now(),
+		reorgCh:    ch,
+		installed:  make(chan struct{}),
+		err:        make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) SubscribeSyncState(ch chan *SyncStateMsg) *RpcSubscription {
+	sub := &subscription{
+		id:          rpc.NewID(),
+		typ:         SyncStateSubscription,
+		createTime:  time.Now(),
+		syncStateCh: ch,
+		installed:   make(chan struct{}),
+		err:         make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) SubscribePeerEvents(ch chan *PeerEventMsg) *RpcSubscription {
+	sub := &subscription{
+		id:         rpc.NewID(),
+		typ:        PeerEventsSubscription,
+		createTime: time.Now(),
+		peerCh:     ch,
+		installed:  make(chan struct{}),
+		err:        make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeContractEvents subscribes the state changes of the register, vote and pledge contracts.
+func (es *EventSystem) SubscribeContractEvents(p *contractEventsParam, ch chan []*ContractEventMsg) *RpcSubscription {
+	sub := &subscription{
+		id:         rpc.NewID(),
+		typ:        ContractEventsSubscription,
+		createTime: time.Now(),
+		eventParam: p,
+		contractCh: ch,
+		installed:  make(chan struct{}),
+		err:        make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeReceipt subscribes the confirmed receive block of the send block sendHash, which is
+// notified once, the subscription is removed from the event system then.
+func (es *EventSystem) SubscribeReceipt(sendHash types.Hash, ch chan *ReceiptMsg) *RpcSubscription {
+	sub := &subscription{
+		id:         rpc.NewID(),
+		typ:        ReceiptSubscription,
+		createTime: time.Now(),
+		sendHash:   sendHash,
+		receiptCh:  ch,
+		installed:  make(chan struct{}),
+		err:        make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribeQuotaEvents subscribes the changes of the pledge amount and the quota of p.addr, which are
+// compared to the state in p at every snapshot block.
+func (es *EventSystem) SubscribeQuotaEvents(p *quotaEventsParam, ch chan []*QuotaEventMsg) *RpcSubscription {
+	sub := &subscription{
+		id:         rpc.NewID(),
+		typ:        QuotaEventsSubscription,
+		createTime: time.Now(),
+		quotaParam: p,
+		quotaCh:    ch,
+		installed:  make(chan struct{}),
+		err:        make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+type filterIndex map[FilterType]map[rpc.ID]*subscription
+
+func (es *EventSystem) eventLoop() {
+	defer es.wg.Done()
+
+	index := make(filterIndex)
+	for _, t := range filterTypes {
+		index[t] = make(map[rpc.ID]*subscription)
+	}
+
+	for {
+		select {
+		case events := <-es.acCh:
+			es.handleAccountChainEvent(index[AccountBlocksSubscription], index[LogsSubscription], events, false)
+			es.handleOnroadEvent(index[OnroadBlocksSubscription], events, false)
+			es.handleContractEvent(index[ContractEventsSubscription], events, false)
+		case events := <-es.acDelCh:
+			es.handleAccountChainEvent(index[AccountBlocksSubscription], index[LogsSubscription], events, true)
+			es.handleOnroadEvent(index[OnroadBlocksSubscription], events, true)
+			es.handleContractEvent(index[ContractEventsSubscription], events, true)
+			es.handleAccountChainEvent(index[ConfirmedAccountBlocksSubscription], index[ConfirmedLogsSubscription], confirmedEvents(events), true)
+		case events := <-es.confirmCh:
+			es.handleAccountChainEvent(index[ConfirmedAccountBlocksSubscription], index[ConfirmedLogsSubscription], events, false)
+			es.handleReceiptEvent(index[ReceiptSubscription], events)
+		case blocks := <-es.sbCh:
+			es.handleSnapshotEvent(index[SnapshotBlocksSubscription], blocks, false)
+			es.handleQuotaEvent(index[QuotaEventsSubscription], blocks[len(blocks)-1])
+		case blocks := <-es.sbDelCh:
+			es.handleSnapshotEvent(index[SnapshotBlocksSubscription], blocks, true)
+			es.handleReorgEvent(index[ReorgSubscription], blocks)
+			es.handleQuotaEvent(index[QuotaEventsSubscription], es.chain.GetLatestSnapshotBlock())
+		case st := <-es.syncCh:
+			es.handleSyncStateEvent(index[SyncStateSubscription], st)
+		case e := <-es.peerCh:
+			es.handlePeerEvent(index[PeerEventsSubscription], e)
+		case sub := <-es.install:
+			index[sub.typ][sub.id] = sub
+			close(sub.installed)
+		case sub := <-es.uninstall:
+			delete(index[sub.typ], sub.id)
+			close(sub.err)
+		case <-es.stop:
+			for _, subs := range index {
+				for _, sub := range subs {
+					close(sub.err)
+				}
+			}
+			return
+		}
+	}
+}
+
+func confirmedEvents(events []*AccountChainEvent) []*AccountChainEvent {
+	var confirmed []*AccountChainEvent
+	for _, e := range events {
+		if e.Confirmed {
+			confirmed = append(confirmed, e)
+		}
+	}
+	return confirmed
+}
+
+func (es *EventSystem) handleAccountChainEvent(acSubs, logsSubs map[rpc.ID]*subscription, events []*AccountChainEvent, removed bool) {
+	if len(events) == 0 {
+		return
+	}
+	if len(acSubs) > 0 {
+		var hashMsgs []*AccountBlocksMsg
+		rpcBlocks := make(map[types.Hash]*api.AccountBlock)
+		for _, sub := range acSubs {
+			subEvents := events
+			if sub.addrSet != nil {
+				if subEvents = filterEventsByAddr(events, sub.addrSet); len(subEvents) == 0 {
+					continue
+				}
+			}
+			var msgs []*AccountBlocksMsg
+			if sub.blockParam == nil && sub.addrSet == nil {
+				if hashMsgs == nil {
+					hashMsgs = accountBlocksMsgs(events, nil, removed, nil)
+				}
+				msgs = hashMsgs
+			} else {
+				if sub.blockParam != nil && sub.blockParam.full {
+					es.fillRpcBlocks(rpcBlocks, subEvents)
+				}
+				msgs = accountBlocksMsgs(subEvents, sub.blockParam, removed, rpcBlocks)
+			}
+			select {
+			case sub.accountBlockCh <- msgs:
+			case <-es.stop:
+				return
+			}
+		}
+	}
+	for _, sub := range logsSubs {
+		if msgs := es.filterLogs(events, sub.param, removed); len(msgs) > 0 {
+			select {
+			case sub.logsCh <- resolveLogEvents(es.chain, msgs):
+			case <-es.stop:
+				return
+			}
+		}
+	}
+}
+
+// filterEventsByAddr returns the events of the blocks whose account address, or to address
+// of send blocks, is in addrSet.
+func filterEventsByAddr(events []*AccountChainEvent, addrSet map[types.Address]struct{}) []*AccountChainEvent {
+	var filtered []*AccountChainEvent
+	for _, e := range events {
+		if _, ok := addrSet[e.Addr]; ok {
+			filtered = append(filtered, e)
+			continue
+		}
+		if e.Block != nil && e.Block.IsSendBlock() {
+			if _, ok := addrSet[e.Block.ToAddress]; ok {
+				filtered = append(filtered, e)
+			}
+		}
+	}
+	return filtered
+}
+
+// fillRpcBlocks converts the blocks of events which are not in rpcBlocks yet to rpc account blocks.
+func (es *EventSystem) fillRpcBlocks(rpcBlocks map[types.Hash]*api.AccountBlock, events []*AccountChainEvent) {
+	for _, e := range events {
+		if _, ok := rpcBlocks[e.Hash]; ok || e.Block == nil {
+			continue
+		}
+		// the block is shared by all subscriptions, convert a copy of it
+		block := *e.Block
+		rpcBlock, err := api.LedgerToRpcBlock(&block, es.chain)
+		if err != nil {
+			es.log.Error("LedgerToRpcBlock failed, error is "+err.Error(), "method", "fillRpcBlocks")
+		}
+		rpcBlocks[e.Hash] = rpcBlock
+	}
+}
+
+func accountBlocksMsgs(events []*AccountChainEvent, param *accountBlocksParam, removed bool, rpcBlocks map[types.Hash]*api.AccountBlock) []*AccountBlocksMsg {
+	msgs := make([]*AccountBlocksMsg, len(events))
+	for i, e := range events {
+		msg := &AccountBlocksMsg{Hash: e.Hash, Removed: removed}
+		msgs[i] = msg
+		if param == nil || e.Block == nil {
+			continue
+		}
+		b := e.Block
+		if param.full {
+			msg.Block = rpcBlocks[e.Hash]
+		}
+		if param.fields[FieldAddress] {
+			msg.Address = &b.AccountAddress
+		}
+		if param.fields[FieldHeight] {
+			height := strconv.FormatUint(b.Height, 10)
+			msg.Height = &height
+		}
+		if param.fields[FieldTokenId] {
+			msg.TokenId = &b.TokenId
+		}
+		if param.fields[FieldAmount] && b.Amount != nil {
+			amount := b.Amount.String()
+			msg.Amount = &amount
+		}
+		if param.fields[FieldBlockType] {
+			msg.BlockType = &b.BlockType
+		}
+	}
+	return msgs
+}
+
+func (es *EventSystem) handleOnroadEvent(onroadSubs map[rpc.ID]*subscription, events []*AccountChainEvent, removed bool) {
+	for _, sub := range onroadSubs {
+		if msgs := es.filterOnroad(events, sub.onroadParam, removed); len(msgs) > 0 {
+			select {
+			case sub.onroadCh <- msgs:
+			case <-es.stop:
+				return
+			}
+		}
+	}
+}
+
+func (es *EventSystem) handleSnapshotEvent(sbSubs map[rpc.ID]*subscription, blocks []*ledger.SnapshotBlock, removed bool) {
+	if len(sbSubs) == 0 {
+		return
+	}
+	msgs := make([]*SnapshotBlocksMsg, len(blocks))
+	for i, b := range blocks {
+		msgs[i] = &SnapshotBlocksMsg{Hash: b.Hash, Height: strconv.FormatUint(b.Height, 10), Removed: removed}
+		if b.Timestamp != nil {
+			msgs[i].Timestamp = b.Timestamp.Unix()
+		}
+	}
+	for _, sub := range sbSubs {
+		select {
+		case sub.snapshotCh <- msgs:
+		case <-es.stop:
+			return
+		}
+	}
+}
+
+// handleReorgEvent notifies one message for one rollback, blocks are all the snapshot blocks reverted by it.
+func (es *EventSystem) handleReorgEvent(reorgSubs map[rpc.ID]*subscription, blocks []*ledger.SnapshotBlock) {
+	if len(reorgSubs) == 0 || len(blocks) == 0 {
+		return
+	}
+	msg := newReorgMsg(blocks)
+	for _, sub := range reorgSubs {
+		select {
+		case sub.reorgCh <- msg:
+		case <-es.stop:
+			return
+		}
+	}
+}
+
+// newReorgMsg takes the parent of the lowest reverted block as the fork point, the reverted
+// blocks are listed in ascending order of height.
+func newReorgMsg(blocks []*ledger.SnapshotBlock) *ReorgMsg {
+	sorted := make([]*ledger.SnapshotBlock, len(blocks))
+	copy(sorted, blocks)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Height < sorted[j].Height })
+
+	lowest := sorted[0]
+	msg := &ReorgMsg{
+		ForkHeight: strconv.FormatUint(lowest.Height-1, 10),
+		ForkHash:   lowest.PrevHash,
+		Reverted:   make([]*SnapshotBlocksMsg, len(sorted)),
+	}
+	for i, b := range sorted {
+		msg.Reverted[i] = &SnapshotBlocksMsg{Hash: b.Hash, Height: strconv.FormatUint(b.Height, 10), Removed: true}
+		if b.Timestamp != nil {
+			msg.Reverted[i].Timestamp = b.Timestamp.Unix()
+		}
+	}
+	return msg
+}
+
+func (es *EventSystem) handleSyncStateEvent(syncStateSubs map[rpc.ID]*subscription, st net.SyncState) {
+	if len(syncStateSubs) == 0 {
+		return
+	}
+	msg := newSyncStateMsg(st)
+	for _, sub := range syncStateSubs {
+		select {
+		case sub.syncStateCh <- msg:
+		case <-es.stop:
+			return
+		}
+	}
+}
+
+func newSyncStateMsg(st net.SyncState) *SyncStateMsg {
+	return &SyncStateMsg{State: uint(st), Status: st.String(), Done: st == net.Syncdone}
+}
+
+func (es *EventSystem) handlePeerEvent(peerSubs map[rpc.ID]*subscription, e net.PeerEvent) {
+	if len(peerSubs) == 0 {
+		return
+	}
+	msg := newPeerEventMsg(e)
+	for _, sub := range peerSubs {
+		select {
+		case sub.peerCh <- msg:
+		case <-es.stop:
+			return
+		}
+	}
+}
+
+func newPeerEventMsg(e net.PeerEvent) *PeerEventMsg {
+	msg := &PeerEventMsg{
+		Event:     PeerDropped,
+		ID:        e.Peer.ID,
+		Addr:      e.Peer.Addr,
+		Height:    strconv.FormatUint(e.Peer.Height, 10),
+		Version:   e.Peer.Version,
+		PeerCount: e.Count,
+		Reason:    e.Reason,
+	}
+	if e.Added {
+		msg.Event = PeerAdded
+	}
+	return msg
+}
+
+func (es *EventSystem) handleContractEvent(contractSubs map[rpc.ID]*subscription, events []*AccountChainEvent, removed bool) {
+	if len(contractSubs) == 0 {
+		return
+	}
+	all := es.contractEventMsgs(events, removed)
+	if len(all) == 0 {
+		return
+	}
+	for _, sub := range contractSubs {
+		var msgs []*ContractEventMsg
+		for _, msg := range all {
+			if sub.eventParam.match(msg) {
+				msgs = append(msgs, msg)
+			}
+		}
+		if len(msgs) == 0 {
+			continue
+		}
+		select {
+		case sub.contractCh <- msgs:
+		case <-es.stop:
+			return
+		}
+	}
+}
+
+// contractEventMsgs decodes the successful receive blocks of the register, vote and pledge contracts
+// in events. The send blocks are looked up in events first like filterLogs does.
+func (es *EventSystem) contractEventMsgs(events []*AccountChainEvent, removed bool) []*ContractEventMsg {
+	var msgs []*ContractEventMsg
+	var sendBlocks map[types.Hash]*ledger.AccountBlock
+	for _, e := range events {
+		b := e.Block
+		if b == nil || b.IsSendBlock() || !isEventContract(b.AccountAddress) {
+			continue
+		}
+		if len(b.Data) != types.HashSize+1 || b.Data[types.HashSize] != vm.ResultSuccess {
+			continue
+		}
+		if sendBlocks == nil {
+			sendBlocks = make(map[types.Hash]*ledger.AccountBlock, len(events))
+			for _, e := range events {
+				if e.Block != nil && e.Block.IsSendBlock() {
+					sendBlocks[e.Hash] = e.Block
+				}
+			}
+		}
+		sendBlock, ok := sendBlocks[b.FromBlockHash]
+		if !ok {
+			var err error
+			if sendBlock, err = es.chain.GetAccountBlockByHash(&b.FromBlockHash); err != nil {
+				es.log.Error("GetAccountBlockByHash failed, error is "+err.Error(), "method", "contractEventMsgs")
+			}
+		}
+		if sendBlock == nil {
+			continue
+		}
+		if msg := newContractEventMsg(b, sendBlock); msg != nil {
+			msg.Removed = removed
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
+func isEventContract(addr types.Address) bool {
+	return addr == types.AddressRegister || addr == types.AddressVote || addr == types.AddressPledge
+}
+
+// newContractEventMsg decodes the call of sendBlock received by block, it returns nil if the
+// call is not one of the contract events.
+func newContractEventMsg(block, sendBlock *ledger.AccountBlock) *ContractEventMsg {
+	msg := &ContractEventMsg{
+		Contract:      block.AccountAddress,
+		Addr:          sendBlock.AccountAddress,
+		Hash:          block.Hash,
+		SendBlockHash: sendBlock.Hash,
+	}
+	switch block.AccountAddress {
+	case types.AddressRegister:
+		param := new(cabi.ParamRegister)
+		if cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameRegister, sendBlock.Data) == nil {
+			msg.Event = EventRegister
+			msg.Amount = amountString(sendBlock)
+		} else if cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameUpdateRegistration, sendBlock.Data) == nil {
+			msg.Event = EventUpdateRegistration
+		} else {
+			cancelParam := new(cabi.ParamCancelRegister)
+			if cabi.ABIRegister.UnpackMethod(cancelParam, cabi.MethodNameCancelRegister, sendBlock.Data) != nil {
+				return nil
+			}
+			msg.Event = EventCancelRegister
+			msg.Gid, msg.Name = &cancelParam.Gid, cancelParam.Name
+			return msg
+		}
+		msg.Gid, msg.Name, msg.NodeAddr = &param.Gid, param.Name, &param.NodeAddr
+	case types.AddressVote:
+		param := new(cabi.ParamVote)
+		gid := new(types.Gid)
+		if cabi.ABIVote.UnpackMethod(param, cabi.MethodNameVote, sendBlock.Data) == nil {
+			msg.Event = EventVote
+			msg.Gid, msg.Name = &param.Gid, param.NodeName
+		} else if cabi.ABIVote.UnpackMethod(gid, cabi.MethodNameCancelVote, sendBlock.Data) == nil {
+			msg.Event = EventCancelVote
+			msg.Gid = gid
+		} else {
+			return nil
+		}
+	case types.AddressPledge:
+		beneficial := new(types.Address)
+		param := new(cabi.ParamCancelPledge)
+		if cabi.ABIPledge.UnpackMethod(beneficial, cabi.MethodNamePledge, sendBlock.Data) == nil {
+			msg.Event = EventPledge
+			msg.Beneficial, msg.Amount = beneficial, amountString(sendBlock)
+		} else if cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameCancelPledge, sendBlock.Data) == nil {
+			msg.Event = EventCancelPledge
+			msg.Beneficial = &param.Beneficial
+			if param.Amount != nil {
+				amount := param.Amount.String()
+				msg.Amount = &amount
+			}
+		} else {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return msg
+}
+
+func amountString(b *ledger.AccountBlock) *string {
+	if b.Amount == nil {
+		return nil
+	}
+	amount := b.Amount.String()
+	return &amount
+}
+
+// filterLogs returns the logs of events matching param. The send blocks of receive blocks are looked
+// up in events first, because they may be deleted from chain together in a rollback.
+func (es *EventSystem) filterLogs(events []*AccountChainEvent, param *filterParam, removed bool) []*LogsMsg {
+	var msgs []*LogsMsg
+	var sendBlocks map[types.Hash]*ledger.AccountBlock
+	for _, e := range events {
+		if !param.matchAddr(e.Addr, e.Height) || len(e.Logs) == 0 {
+			continue
+		}
+		if param.tokenIdSet != nil {
+			if sendBlocks == nil {
+				sendBlocks = make(map[types.Hash]*ledger.AccountBlock, len(events))
+				for _, e := range events {
+					if e.Block != nil && e.Block.IsSendBlock() {
+						sendBlocks[e.Hash] = e.Block
+					}
+				}
+			}
+			tokenId, err := blockTokenId(es.chain, e.Block, sendBlocks)
+			if err != nil {
+				es.log.Error("blockTokenId failed, error is "+err.Error(), "method", "filterLogs")
+			}
+			if tokenId == nil || !matchTokenId(param.tokenIdSet, *tokenId) {
+				continue
+			}
+		}
+		for _, l := range e.Logs {
+			if matchTopics(l, param.topics) {
+				msgs = append(msgs, &LogsMsg{Log: l, AccountBlockHash: e.Hash, Addr: e.Addr, Removed: removed})
+			}
+		}
+	}
+	return msgs
+}
+
+// handleReceiptEvent notifies the subscriptions of the send blocks received by the confirmed
+// events and removes them from receiptSubs, so that a receipt is notified once.
+func (es *EventSystem) handleReceiptEvent(receiptSubs map[rpc.ID]*subscription, events []*AccountChainEvent) {
+	if len(receiptSubs) == 0 || len(events) == 0 {
+		return
+	}
+	received := make(map[types.Hash]*ledger.AccountBlock)
+	for _, e := range events {
+		if e.Block != nil && e.Block.IsReceiveBlock() {
+			received[e.Block.FromBlockHash] = e.Block
+		}
+	}
+	for id, sub := range receiptSubs {
+		block, ok := received[sub.sendHash]
+		if !ok {
+			continue
+		}
+		snapshotBlock, err := es.chain.GetConfirmBlock(&block.Hash)
+		if err != nil {
+			es.log.Error("GetConfirmBlock failed, error is "+err.Error(), "method", "handleReceiptEvent")
+		}
+		select {
+		case sub.receiptCh <- newReceiptMsg(block, snapshotBlock):
+			delete(receiptSubs, id)
+		case <-es.stop:
+			return
+		}
+	}
+}
+
+// Receipt returns the receipt of the send block sendHash if it has been received and the receive
+// block is confirmed, or nil otherwise.
+func (es *EventSystem) Receipt(sendHash types.Hash) (*ReceiptMsg, error) {
+	sendBlock, err := es.chain.GetAccountBlockByHash(&sendHash)
+	if err != nil || sendBlock == nil || !sendBlock.IsSendBlock() {
+		return nil, err
+	}
+	heights, err := es.chain.GetReceiveBlockHeights(&sendHash)
+	if err != nil {
+		return nil, err
+	}
+	for _, height := range heights {
+		block, err := es.chain.GetAccountBlockByHeight(&sendBlock.ToAddress, height)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil || block.FromBlockHash != sendHash {
+			continue
+		}
+		snapshotBlock, err := es.chain.GetConfirmBlock(&block.Hash)
+		if err != nil || snapshotBlock == nil {
+			return nil, err
+		}
+		return newReceiptMsg(block, snapshotBlock), nil
+	}
+	return nil, nil
+}
+
+func newReceiptMsg(block *ledger.AccountBlock, snapshotBlock *ledger.SnapshotBlock) *ReceiptMsg {
+	msg := &ReceiptMsg{
+		SendBlockHash:    block.FromBlockHash,
+		ReceiveBlockHash: block.Hash,
+		Addr:             block.AccountAddress,
+		Height:           strconv.FormatUint(block.Height, 10),
+		Status:           ReceiptSuccess,
+	}
+	// the receive block of a contract keeps the execution result after the storage hash, and the
+	// reason after the result if it fails since the revert reason fork
+	if len(block.Data) == types.HashSize+1 {
+		switch block.Data[types.HashSize] {
+		case vm.ResultFail:
+			msg.Status = ReceiptFail
+		case vm.ResultDepthErr:
+			msg.Status = ReceiptDepthError
+		}
+	} else if reason, ok := vm.GetRevertReason(block.Data); ok {
+		msg.Status = ReceiptFail
+		msg.RevertReason = reason
+	}
+	if snapshotBlock != nil {
+		msg.SnapshotHash = snapshotBlock.Hash
+		msg.SnapshotHeight = strconv.FormatUint(snapshotBlock.Height, 10)
+	}
+	return msg
+}
+
+// blockTokenId returns the token transferred by block, which is the token of the send block for
+// a receive block, or nil if the send block is not found.
+func blockTokenId(c chain.Chain, block *ledger.AccountBlock, sendBlocks map[types.Hash]*ledger.AccountBlock) (*types.TokenTypeId, error) {
+	if block == nil {
+		return nil, nil
+	}
+	if block.IsSendBlock() {
+		return &block.TokenId, nil
+	}
+	if sendBlock, ok := sendBlocks[block.FromBlockHash]; ok {
+		return &sendBlock.TokenId, nil
+	}
+	sendBlock, err := c.GetAccountBlockByHash(&block.FromBlockHash)
+	if err != nil || sendBlock == nil {
+		return nil, err
+	}
+	return &sendBlock.TokenId, nil
+}
+
+func matchTopics(l *ledger.VmLog, topics [][]types.Hash) bool {
+	if len(topics) > len(l.Topics) {
+		return false
+	}
+	for i, position := range topics {
+		if len(position) == 0 {
+			continue
+		}
+		match := false
+		for _, topic := range position {
+			if topic == l.Topics[i] {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func (es *EventSystem) filterOnroad(events []*AccountChainEvent, param *onroadFilterParam, removed bool) []*OnroadMsg {
+	var msgs []*OnroadMsg
+	for _, e := range events {
+		b := e.Block
+		if b.IsSendBlock() {
+			if param.matchAddr(b.ToAddress) && param.matchTokenId(b.TokenId) {
+				msgs = append(msgs, &OnroadMsg{Hash: b.Hash, Addr: b.ToAddress, TokenId: b.TokenId, Removed: removed})
+			}
+			continue
+		}
+		if !param.matchAddr(b.AccountAddress) {
+			continue
+		}
+		msg := &OnroadMsg{Hash: b.FromBlockHash, Addr: b.AccountAddress, Closed: true, Removed: removed}
+		sendBlock, err := es.chain.GetAccountBlockByHash(&b.FromBlockHash)
+		if err != nil {
+			es.log.Error("GetAccountBlockByHash failed, error is "+err.Error(), "method", "filterOnroad")
+		}
+		if sendBlock != nil {
+			msg.TokenId = sendBlock.TokenId
+			if !param.matchTokenId(sendBlock.TokenId) {
+				continue
+			}
+		} else if param.tokenIdSet != nil {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// handleQuotaEvent notifies the changes of the pledge amount and the quota of the subscribed addresses
+// at snapshotBlock, the latest snapshot block.
+func (es *EventSystem) handleQuotaEvent(quotaSubs map[rpc.ID]*subscription, snapshotBlock *ledger.SnapshotBlock) {
+	if len(quotaSubs) == 0 || snapshotBlock == nil {
+		return
+	}
+	for _, sub := range quotaSubs {
+		pledgeAmount, quota, err := es.quotaState(sub.quotaParam.addr, snapshotBlock.Hash)
+		if err != nil {
+			es.log.Error("quotaState failed, error is "+err.Error(), "method", "handleQuotaEvent")
+			continue
+		}
+		msgs := sub.quotaParam.update(pledgeAmount, quota, snapshotBlock)
+		if len(msgs) == 0 {
+			continue
+		}
+		select {
+		case sub.quotaCh <- msgs:
+		case <-es.stop:
+			return
+		}
+	}
+}
+
+// initQuotaEventsParam sets the state p is compared to with the pledge amount and the quota of p.addr
+// at the latest snapshot block.
+func (es *EventSystem) initQuotaEventsParam(p *quotaEventsParam) error {
+	snapshotBlock := es.chain.GetLatestSnapshotBlock()
+	if snapshotBlock == nil {
+		return nil
+	}
+	pledgeAmount, quota, err := es.quotaState(p.addr, snapshotBlock.Hash)
+	if err != nil {
+		return err
+	}
+	p.pledgeAmount, p.quota = pledgeAmount, quota
+	return nil
+}
+
+func (es *EventSystem) quotaState(addr types.Address, snapshotHash types.Hash) (*big.Int, uint64, error) {
+	pledgeAmount, err := es.chain.GetPledgeAmount(snapshotHash, addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	if pledgeAmount == nil {
+		pledgeAmount = new(big.Int)
+	}
+	quota, err := es.chain.GetPledgeQuota(snapshotHash, addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	return pledgeAmount, quota, nil
+}
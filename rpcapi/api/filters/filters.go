@@ -0,0 +1,103 @@
+package filters
+
+import (
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+const (
+	getLogsBatchSize = 100
+	// maxGetLogsRange limits the count of account blocks scanned for one address in one query
+	maxGetLogsRange = 10000
+)
+
+// getLogs scans the account chains in param for vm logs which are already
+// inserted into chain, a zero toHeight means up to the latest block.
+func getLogs(c chain.Chain, param *filterParam) ([]*LogsMsg, error) {
+	var msgs []*LogsMsg
+	for addr, hr := range param.addrRange {
+		addrMsgs, err := getLogsByAddress(c, addr, hr, param.topics, param.tokenIdSet)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, addrMsgs...)
+	}
+	return resolveLogEvents(c, msgs), nil
+}
+
+// resolveLogEvents annotates msgs with the events registered in the event registry, msgs are left
+// as they are if the registry can't be read.
+func resolveLogEvents(c chain.Chain, msgs []*LogsMsg) []*LogsMsg {
+	if len(msgs) == 0 {
+		return msgs
+	}
+	resolver, err := api.NewLogEventResolver(c)
+	if err != nil {
+		return msgs
+	}
+	for _, msg := range msgs {
+		msg.Event = resolver.Resolve(msg.Addr, msg.Log)
+	}
+	return msgs
+}
+
+func getLogsByAddress(c chain.Chain, addr types.Address, hr heightRange, topics [][]types.Hash, tokenIdSet map[types.TokenTypeId]struct{}) ([]*LogsMsg, error) {
+	latestBlock, err := c.GetLatestAccountBlock(&addr)
+	if err != nil {
+		return nil, err
+	}
+	if latestBlock == nil {
+		return nil, nil
+	}
+	startHeight := hr.fromHeight
+	if startHeight == 0 {
+		startHeight = 1
+	}
+	endHeight := hr.toHeight
+	if endHeight == 0 || endHeight > latestBlock.Height {
+		endHeight = latestBlock.Height
+	}
+	if startHeight > endHeight {
+		return nil, nil
+	}
+	if endHeight-startHeight+1 > maxGetLogsRange {
+		return nil, ErrRangeTooLarge
+	}
+
+	var msgs []*LogsMsg
+	for start := startHeight; start <= endHeight; start += getLogsBatchSize {
+		count := uint64(getLogsBatchSize)
+		if start+count-1 > endHeight {
+			count = endHeight - start + 1
+		}
+		blocks, err := c.GetAccountBlocksByHeight(addr, start, count, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			if block.LogHash == nil {
+				continue
+			}
+			if tokenIdSet != nil {
+				tokenId, err := blockTokenId(c, block, nil)
+				if err != nil {
+					return nil, err
+				}
+				if tokenId == nil || !matchTokenId(tokenIdSet, *tokenId) {
+					continue
+				}
+			}
+			logList, err := c.GetVmLogList(block.LogHash)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range logList {
+				if matchTopics(l, topics) {
+					msgs = append(msgs, &LogsMsg{Log: l, AccountBlockHash: block.Hash, Addr: addr})
+				}
+			}
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,348 @@
+package filters
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+)
+
+func TestRpcFilterParam_toFilterParam(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	topic := types.DataHash([]byte("topic"))
+
+	if _, err := (&RpcFilterParam{}).toFilterParam(false); err != ErrEmptyAddrRange {
+		t.Fatalf("expected ErrEmptyAddrRange, got %v", err)
+	}
+	if _, err := (&RpcFilterParam{AddrRange: map[string]*Range{addr.String(): {FromHeight: "10", ToHeight: "1"}}}).toFilterParam(false); err != ErrInvalidRange {
+		t.Fatalf("expected ErrInvalidRange, got %v", err)
+	}
+	if _, err := (&RpcFilterParam{AddrRange: map[string]*Range{addr.String(): nil}, Topics: make([][]types.Hash, ledger.MaxVmLogTopics+1)}).toFilterParam(false); err != ErrInvalidTopics {
+		t.Fatalf("expected ErrInvalidTopics, got %v", err)
+	}
+
+	p, err := (&RpcFilterParam{AddrRange: map[string]*Range{addr.String(): {FromHeight: "1", ToHeight: "10"}}, Topics: [][]types.Hash{{topic}}}).toFilterParam(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hr := p.addrRange[addr]; hr.fromHeight != 1 || hr.toHeight != 10 {
+		t.Fatalf("unexpected height range %v", hr)
+	}
+}
+
+func TestFilterLogs(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	topicA := types.DataHash([]byte("a"))
+	topicB := types.DataHash([]byte("b"))
+
+	events := []*AccountChainEvent{
+		{Hash: types.DataHash([]byte("1")), Height: 1, Addr: addr, Logs: []*ledger.VmLog{{Topics: []types.Hash{topicA}}}},
+		{Hash: types.DataHash([]byte("2")), Height: 2, Addr: addr, Logs: []*ledger.VmLog{{Topics: []types.Hash{topicB}}}},
+		{Hash: types.DataHash([]byte("3")), Height: 3, Addr: types.AddressPledge, Logs: []*ledger.VmLog{{Topics: []types.Hash{topicA}}}},
+	}
+
+	es := &EventSystem{}
+
+	param := &filterParam{addrRange: map[types.Address]heightRange{addr: {}}}
+	if msgs := es.filterLogs(events, param, false); len(msgs) != 2 {
+		t.Fatalf("expected 2 logs, got %v", len(msgs))
+	}
+
+	param = &filterParam{addrRange: map[types.Address]heightRange{addr: {fromHeight: 2}}}
+	if msgs := es.filterLogs(events, param, true); len(msgs) != 1 || msgs[0].AccountBlockHash != events[1].Hash || !msgs[0].Removed {
+		t.Fatalf("unexpected logs %v", msgs)
+	}
+
+	param = &filterParam{addrRange: map[types.Address]heightRange{addr: {}}, topics: [][]types.Hash{{topicA}}}
+	if msgs := es.filterLogs(events, param, false); len(msgs) != 1 || msgs[0].AccountBlockHash != events[0].Hash {
+		t.Fatalf("unexpected logs %v", msgs)
+	}
+
+	param = &filterParam{addrRange: map[types.Address]heightRange{addr: {}}, topics: [][]types.Hash{{topicA, topicB}}}
+	if msgs := es.filterLogs(events, param, false); len(msgs) != 2 {
+		t.Fatalf("expected 2 logs, got %v", len(msgs))
+	}
+
+	param = &filterParam{addrRange: map[types.Address]heightRange{addr: {}}, topics: [][]types.Hash{{}}}
+	if msgs := es.filterLogs(events, param, false); len(msgs) != 2 {
+		t.Fatalf("expected 2 logs, got %v", len(msgs))
+	}
+
+	param = &filterParam{addrRange: map[types.Address]heightRange{addr: {}}, topics: [][]types.Hash{{}, {topicA}}}
+	if msgs := es.filterLogs(events, param, false); len(msgs) != 0 {
+		t.Fatalf("expected no logs, got %v", len(msgs))
+	}
+
+	param, err := (&RpcFilterParam{}).toFilterParam(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgs := es.filterLogs(events, param, false); len(msgs) != 3 {
+		t.Fatalf("empty addrRange should match any address, got %v logs", len(msgs))
+	}
+}
+
+func TestFilterLogsByTokenId(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	otherTokenId, _ := types.HexToTokenTypeId("tti_2d95b4ae402bbcf1429aa1e5")
+
+	send := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("1")), AccountAddress: types.AddressPledge, ToAddress: addr, TokenId: otherTokenId}
+	receive := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.DataHash([]byte("2")), AccountAddress: addr, Height: 1, FromBlockHash: send.Hash}
+	viteSend := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("3")), AccountAddress: addr, Height: 2, TokenId: ledger.ViteTokenId}
+	events := []*AccountChainEvent{
+		newAccountChainEvent(send, nil),
+		newAccountChainEvent(receive, []*ledger.VmLog{{}}),
+		newAccountChainEvent(viteSend, []*ledger.VmLog{{}}),
+	}
+	es := &EventSystem{}
+
+	param, err := (&RpcFilterParam{AddrRange: map[string]*Range{addr.String(): nil}, TokenIds: []types.TokenTypeId{otherTokenId}}).toFilterParam(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgs := es.filterLogs(events, param, true); len(msgs) != 1 || msgs[0].AccountBlockHash != receive.Hash {
+		t.Fatalf("unexpected logs %v", msgs)
+	}
+
+	param.tokenIdSet = toTokenIdSet([]types.TokenTypeId{ledger.ViteTokenId})
+	if msgs := es.filterLogs(events, param, false); len(msgs) != 1 || msgs[0].AccountBlockHash != viteSend.Hash {
+		t.Fatalf("unexpected logs %v", msgs)
+	}
+}
+
+func TestFilterOnroad(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	otherTokenId, _ := types.HexToTokenTypeId("tti_2d95b4ae402bbcf1429aa1e5")
+
+	events := []*AccountChainEvent{
+		{Block: &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("1")), ToAddress: addr, TokenId: ledger.ViteTokenId, Amount: big.NewInt(1)}},
+		{Block: &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("2")), ToAddress: addr, TokenId: otherTokenId, Amount: big.NewInt(1)}},
+		{Block: &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("3")), ToAddress: types.AddressPledge, TokenId: ledger.ViteTokenId, Amount: big.NewInt(1)}},
+	}
+	es := &EventSystem{}
+
+	param, err := (&RpcOnroadFilterParam{AddrList: []types.Address{addr}}).toFilterParam()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgs := es.filterOnroad(events, param, false); len(msgs) != 2 {
+		t.Fatalf("expected 2 onroad blocks, got %v", len(msgs))
+	}
+
+	param, err = (&RpcOnroadFilterParam{AddrList: []types.Address{addr}, TokenIdList: []types.TokenTypeId{otherTokenId}}).toFilterParam()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgs := es.filterOnroad(events, param, false); len(msgs) != 1 || msgs[0].Hash != events[1].Block.Hash {
+		t.Fatalf("unexpected onroad blocks %v", msgs)
+	}
+
+	if _, err := (&RpcOnroadFilterParam{}).toFilterParam(); err != ErrEmptyAddrList {
+		t.Fatalf("expected ErrEmptyAddrList, got %v", err)
+	}
+}
+
+func TestAccountBlocksMsgs(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	block := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("1")), AccountAddress: addr, Height: 3, TokenId: ledger.ViteTokenId, Amount: big.NewInt(10)}
+	events := []*AccountChainEvent{newAccountChainEvent(block, nil)}
+
+	if _, err := (&RpcAccountBlocksParam{Fields: []string{"unknown"}}).toAccountBlocksParam(); err != ErrUnknownField {
+		t.Fatalf("expected ErrUnknownField, got %v", err)
+	}
+	if p, err := (&RpcAccountBlocksParam{}).toAccountBlocksParam(); p != nil || err != nil {
+		t.Fatalf("empty param should select hash only, got %v %v", p, err)
+	}
+
+	msgs := accountBlocksMsgs(events, nil, false, nil)
+	if len(msgs) != 1 || msgs[0].Hash != block.Hash || msgs[0].Address != nil || msgs[0].Block != nil {
+		t.Fatalf("unexpected msgs %+v", msgs[0])
+	}
+
+	p, err := (&RpcAccountBlocksParam{Fields: []string{FieldAddress, FieldHeight, FieldAmount}}).toAccountBlocksParam()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs = accountBlocksMsgs(events, p, true, nil)
+	msg := msgs[0]
+	if *msg.Address != addr || *msg.Height != "3" || *msg.Amount != "10" || msg.TokenId != nil || msg.BlockType != nil || !msg.Removed {
+		t.Fatalf("unexpected msg %+v", msg)
+	}
+}
+
+func TestFilterEventsByAddr(t *testing.T) {
+	addr1, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	addr2, _ := types.HexToAddress("vite_00000000000000000000000000000000000000042d7ef71894")
+	addr3, _ := types.HexToAddress("vite_0000000000000000000000000000000000000001c9e9f25417")
+	send := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("1")), AccountAddress: addr1, ToAddress: addr2}
+	receive := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.DataHash([]byte("2")), AccountAddress: addr2, FromBlockHash: send.Hash}
+	other := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("3")), AccountAddress: addr3, ToAddress: addr1}
+	events := []*AccountChainEvent{newAccountChainEvent(send, nil), newAccountChainEvent(receive, nil), newAccountChainEvent(other, nil)}
+
+	if _, err := toAddrSet(nil); err != ErrEmptyAddrList {
+		t.Fatalf("expected ErrEmptyAddrList, got %v", err)
+	}
+	addrSet, _ := toAddrSet([]types.Address{addr2})
+	filtered := filterEventsByAddr(events, addrSet)
+	if len(filtered) != 2 || filtered[0].Hash != send.Hash || filtered[1].Hash != receive.Hash {
+		t.Fatalf("unexpected filtered events %+v", filtered)
+	}
+	addrSet, _ = toAddrSet([]types.Address{addr3})
+	if filtered = filterEventsByAddr(events, addrSet); len(filtered) != 1 || filtered[0].Hash != other.Hash {
+		t.Fatalf("unexpected filtered events %+v", filtered)
+	}
+}
+
+func TestNewReorgMsg(t *testing.T) {
+	now := time.Unix(1000, 0)
+	fork := &ledger.SnapshotBlock{Hash: types.DataHash([]byte("fork")), Height: 10}
+	b11 := &ledger.SnapshotBlock{Hash: types.DataHash([]byte("11")), PrevHash: fork.Hash, Height: 11, Timestamp: &now}
+	b12 := &ledger.SnapshotBlock{Hash: types.DataHash([]byte("12")), PrevHash: b11.Hash, Height: 12}
+
+	msg := newReorgMsg([]*ledger.SnapshotBlock{b12, b11})
+	if msg.ForkHeight != "10" || msg.ForkHash != fork.Hash {
+		t.Fatalf("unexpected fork point %v %v", msg.ForkHeight, msg.ForkHash)
+	}
+	if len(msg.Reverted) != 2 || msg.Reverted[0].Hash != b11.Hash || msg.Reverted[1].Hash != b12.Hash {
+		t.Fatalf("unexpected reverted blocks %+v", msg.Reverted)
+	}
+	if !msg.Reverted[0].Removed || msg.Reverted[0].Timestamp != now.Unix() {
+		t.Fatalf("unexpected reverted block %+v", msg.Reverted[0])
+	}
+}
+
+func TestContractEventMsgs(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	voteData, _ := cabi.ABIVote.PackMethod(cabi.MethodNameVote, types.SNAPSHOT_GID, "s1")
+	pledgeData, _ := cabi.ABIPledge.PackMethod(cabi.MethodNamePledge, addr)
+
+	voteSend := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("1")), AccountAddress: addr, ToAddress: types.AddressVote, Data: voteData}
+	pledgeSend := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.DataHash([]byte("2")), AccountAddress: addr, ToAddress: types.AddressPledge, Amount: big.NewInt(10), Data: pledgeData}
+	voteReceive := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.DataHash([]byte("3")), AccountAddress: types.AddressVote, FromBlockHash: voteSend.Hash, Data: append(types.Hash{}.Bytes(), vm.ResultSuccess)}
+	pledgeReceive := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.DataHash([]byte("4")), AccountAddress: types.AddressPledge, FromBlockHash: pledgeSend.Hash, Data: append(types.Hash{}.Bytes(), vm.ResultSuccess)}
+	failedReceive := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.DataHash([]byte("5")), AccountAddress: types.AddressVote, FromBlockHash: voteSend.Hash, Data: append(types.Hash{}.Bytes(), vm.ResultFail)}
+	events := []*AccountChainEvent{
+		newAccountChainEvent(voteSend, nil),
+		newAccountChainEvent(pledgeSend, nil),
+		newAccountChainEvent(voteReceive, nil),
+		newAccountChainEvent(pledgeReceive, nil),
+		newAccountChainEvent(failedReceive, nil),
+	}
+	es := &EventSystem{}
+
+	msgs := es.contractEventMsgs(events, true)
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 events, got %v", len(msgs))
+	}
+	if vote := msgs[0]; vote.Event != EventVote || vote.Addr != addr || vote.Name != "s1" || *vote.Gid != types.SNAPSHOT_GID ||
+		vote.SendBlockHash != voteSend.Hash || !vote.Removed {
+		t.Fatalf("unexpected vote event %+v", vote)
+	}
+	if pledge := msgs[1]; pledge.Event != EventPledge || *pledge.Beneficial != addr || *pledge.Amount != "10" {
+		t.Fatalf("unexpected pledge event %+v", pledge)
+	}
+
+	param, err := (&RpcContractEventsParam{Events: []string{EventPledge}}).toFilterParam()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if param.match(msgs[0]) || !param.match(msgs[1]) {
+		t.Fatal("unexpected match by event")
+	}
+	if _, err := (&RpcContractEventsParam{Events: []string{"reward"}}).toFilterParam(); err != ErrUnknownEvent {
+		t.Fatalf("expected ErrUnknownEvent, got %v", err)
+	}
+}
+
+func TestDurableRange(t *testing.T) {
+	for _, c := range []struct {
+		height, latest uint64
+		cursor         string
+		from, to       uint64
+		err            error
+	}{
+		{height: 10, latest: 10, from: 10, to: 10},
+		{height: 10, latest: 50, from: 10, to: 50},
+		{height: 10, latest: 500, from: 10, to: 10 + durableReplaySize},
+		{height: 10, latest: 50, cursor: "30", from: 30, to: 50},
+		{height: 10, latest: 50, cursor: "5", err: ErrInvalidCursor},
+		{height: 10, latest: 50, cursor: "60", err: ErrInvalidCursor},
+		{height: 10, latest: 50, cursor: "x", err: ErrInvalidCursor},
+		// rolled back
+		{height: 60, latest: 50, from: 50, to: 50},
+	} {
+		from, to, err := durableRange(c.height, c.latest, c.cursor)
+		if err != c.err || from != c.from || to != c.to {
+			t.Fatalf("durableRange(%v, %v, %q) = %v, %v, %v", c.height, c.latest, c.cursor, from, to, err)
+		}
+	}
+
+	for id, valid := range map[string]bool{"consumer_1-a": true, "": false, "a b": false, strings.Repeat("a", maxDurableFilterIdLen+1): false} {
+		if validDurableFilterId(id) != valid {
+			t.Fatalf("validDurableFilterId(%q) should be %v", id, valid)
+		}
+	}
+}
+
+func TestNewReceiptMsg(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	sendHash := types.DataHash([]byte("send"))
+	snapshotBlock := &ledger.SnapshotBlock{Hash: types.DataHash([]byte("snapshot")), Height: 20}
+
+	block := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.DataHash([]byte("receive")), Height: 3, AccountAddress: addr, FromBlockHash: sendHash}
+	msg := newReceiptMsg(block, snapshotBlock)
+	if msg.SendBlockHash != sendHash || msg.ReceiveBlockHash != block.Hash || msg.Addr != addr || msg.Height != "3" ||
+		msg.Status != ReceiptSuccess || msg.SnapshotHash != snapshotBlock.Hash || msg.SnapshotHeight != "20" {
+		t.Fatalf("unexpected receipt %+v", msg)
+	}
+
+	for result, status := range map[byte]string{vm.ResultSuccess: ReceiptSuccess, vm.ResultFail: ReceiptFail, vm.ResultDepthErr: ReceiptDepthError} {
+		block.Data = append(types.DataHash([]byte("storage")).Bytes(), result)
+		if msg := newReceiptMsg(block, nil); msg.Status != status || msg.SnapshotHeight != "" {
+			t.Fatalf("unexpected receipt %+v", msg)
+		}
+	}
+
+	if _, err := (&RpcReceiptParam{Addr: &addr}).toSendBlockHash(nil); err != ErrInvalidReceipt {
+		t.Fatalf("expected ErrInvalidReceipt, got %v", err)
+	}
+	if hash, err := (&RpcReceiptParam{SendBlockHash: &sendHash}).toSendBlockHash(nil); err != nil || hash != sendHash {
+		t.Fatalf("unexpected send block hash %v: %v", hash, err)
+	}
+}
+
+func TestQuotaEventsParam_update(t *testing.T) {
+	addr, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	if _, err := (&RpcQuotaEventsParam{Thresholds: make([]string, maxQuotaThresholds+1)}).toQuotaEventsParam(addr); err != ErrTooManyThresholds {
+		t.Fatalf("expected ErrTooManyThresholds, got %v", err)
+	}
+	p, err := (&RpcQuotaEventsParam{Thresholds: []string{"42000", "21000"}}).toQuotaEventsParam(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.pledgeAmount, p.quota = big.NewInt(100), 50000
+
+	sb := &ledger.SnapshotBlock{Hash: types.DataHash([]byte("sb")), Height: 10}
+	if msgs := p.update(big.NewInt(100), 45000, sb); len(msgs) != 0 {
+		t.Fatalf("unexpected msgs %+v", msgs)
+	}
+
+	msgs := p.update(big.NewInt(10), 20000, sb)
+	if len(msgs) != 3 || msgs[0].Event != QuotaPledgeChanged || msgs[0].PrevPledgeAmount != "100" || msgs[0].PledgeAmount != "10" {
+		t.Fatalf("unexpected msgs %+v", msgs)
+	}
+	if msgs[1].Event != QuotaBelowThreshold || msgs[1].Threshold != "21000" || msgs[2].Threshold != "42000" ||
+		msgs[1].PrevQuota != "45000" || msgs[1].Quota != "20000" || msgs[1].SnapshotHeight != "10" {
+		t.Fatalf("unexpected msgs %+v %+v", msgs[1], msgs[2])
+	}
+
+	msgs = p.update(big.NewInt(10), 30000, sb)
+	if len(msgs) != 1 || msgs[0].Event != QuotaAboveThreshold || msgs[0].Threshold != "21000" {
+		t.Fatalf("unexpected msgs %+v", msgs)
+	}
+}
@@ -0,0 +1,541 @@
+package filters
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+var (
+	ErrSubscribeDisabled = errors.New("subscribe is not enabled")
+	ErrFilterNotFound    = errors.New("filter not found")
+	ErrFilterOverflowed  = errors.New("filter buffer overflowed, changes since the last poll are discarded")
+	ErrInvalidRange      = errors.New("invalid height range")
+	ErrInvalidTopics     = errors.New("too many topic positions")
+	ErrEmptyAddrRange    = errors.New("addrRange must not be empty")
+	ErrEmptyAddrList     = errors.New("addrList must not be empty")
+	ErrRangeTooLarge     = errors.New("height range is too large")
+	ErrUnknownField      = errors.New("unknown account block field")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrUnknownEvent      = errors.New("unknown built-in contract event")
+	ErrInvalidFilterId   = errors.New("invalid durable filter id")
+	ErrFilterExists      = errors.New("filter already exists")
+	ErrInvalidReceipt    = errors.New("either sendBlockHash or addr and height must be given")
+	ErrSendBlockNotFound = errors.New("send block not found")
+	ErrTooManyThresholds = errors.New("too many quota thresholds")
+
+	ErrTooManySubscriptions = errors.New("too many subscriptions on the connection")
+	ErrTooManyFilters       = errors.New("too many filters installed on the node")
+)
+
+const (
+	FieldAddress   = "address"
+	FieldHeight    = "height"
+	FieldTokenId   = "tokenId"
+	FieldAmount    = "amount"
+	FieldBlockType = "blockType"
+)
+
+var accountBlockFields = []string{FieldAddress, FieldHeight, FieldTokenId, FieldAmount, FieldBlockType}
+
+// RpcFilterParam filters the vm logs of the accounts in AddrRange. Topics are matched by position,
+// an empty position matches any topic and the hashes in one position are ORed. If TokenIds is not
+// empty, only the logs of the blocks transferring one of the tokens are matched, the token of a
+// receive block is the one of its send block.
+type RpcFilterParam struct {
+	AddrRange map[string]*Range   `json:"addrRange"`
+	Topics    [][]types.Hash      `json:"topics"`
+	TokenIds  []types.TokenTypeId `json:"tokenIds"`
+}
+
+type Range struct {
+	FromHeight string `json:"fromHeight"`
+	ToHeight   string `json:"toHeight"`
+}
+
+type RpcOnroadFilterParam struct {
+	AddrList    []types.Address     `json:"addrList"`
+	TokenIdList []types.TokenTypeId `json:"tokenIdList"`
+}
+
+// RpcAccountBlocksParam selects the payload of account block messages, which carry only the
+// block hash by default. Full adds the whole rpc account block, Fields adds the named fields.
+type RpcAccountBlocksParam struct {
+	Full   bool     `json:"full"`
+	Fields []string `json:"fields"`
+}
+
+// RpcPageParam pages the changes of a polling filter. Cursor is the nextCursor of the previous
+// page, the messages before it are acknowledged and removed from the buffer. Limit is the max
+// count of messages in a page, 0 means no limit.
+type RpcPageParam struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type AccountBlocksMsg struct {
+	Hash    types.Hash `json:"hash"`
+	Removed bool       `json:"removed"`
+
+	Address   *types.Address     `json:"address,omitempty"`
+	Height    *string            `json:"height,omitempty"`
+	TokenId   *types.TokenTypeId `json:"tokenId,omitempty"`
+	Amount    *string            `json:"amount,omitempty"`
+	BlockType *byte              `json:"blockType,omitempty"`
+
+	Block *api.AccountBlock `json:"block,omitempty"`
+}
+
+type SnapshotBlocksMsg struct {
+	Hash      types.Hash `json:"hash"`
+	Height    string     `json:"height"`
+	Timestamp int64      `json:"timestamp"`
+	Removed   bool       `json:"removed"`
+}
+
+// ReorgMsg describes a rollback of the snapshot chain, ForkHeight and ForkHash identify the
+// block the chain is rolled back to and Reverted holds the snapshot blocks removed above it.
+type ReorgMsg struct {
+	ForkHeight string               `json:"forkHeight"`
+	ForkHash   types.Hash           `json:"forkHash"`
+	Reverted   []*SnapshotBlocksMsg `json:"reverted"`
+}
+
+// SyncStateMsg describes a transition of the sync state of net, State is the numeric value of
+// net.SyncState and Done is set once the node has caught up with its peers.
+type SyncStateMsg struct {
+	State  uint   `json:"state"`
+	Status string `json:"status"`
+	Done   bool   `json:"done"`
+}
+
+const (
+	PeerAdded   = "added"
+	PeerDropped = "dropped"
+)
+
+// PeerEventMsg describes a peer added or dropped, Event is PeerAdded or PeerDropped and Reason is
+// the error the peer is dropped for. PeerCount is the number of peers after the event.
+type PeerEventMsg struct {
+	Event     string `json:"event"`
+	ID        string `json:"id"`
+	Addr      string `json:"addr"`
+	Height    string `json:"height"`
+	Version   string `json:"version"`
+	PeerCount int    `json:"peerCount"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+const (
+	EventRegister           = "register"
+	EventUpdateRegistration = "updateRegistration"
+	EventCancelRegister     = "cancelRegister"
+	EventVote               = "vote"
+	EventCancelVote         = "cancelVote"
+	EventPledge             = "pledge"
+	EventCancelPledge       = "cancelPledge"
+)
+
+var contractEvents = []string{EventRegister, EventUpdateRegistration, EventCancelRegister,
+	EventVote, EventCancelVote, EventPledge, EventCancelPledge}
+
+// RpcContractEventsParam selects the built-in contract events by name, any event is matched if
+// Events is empty. Only the events of the accounts in AddrList are matched unless it is empty.
+type RpcContractEventsParam struct {
+	Events   []string        `json:"events"`
+	AddrList []types.Address `json:"addrList"`
+}
+
+// ContractEventMsg describes a state change of the register, vote or pledge contract decoded from
+// a successful receive block of the contract. Addr is the account sending the call, Hash is the
+// receive block. Name is the SBP name registered or voted for, Amount is the pledge amount locked
+// by a register or pledge, or withdrawn by a cancelPledge.
+type ContractEventMsg struct {
+	Event         string        `json:"event"`
+	Contract      types.Address `json:"contract"`
+	Addr          types.Address `json:"addr"`
+	Hash          types.Hash    `json:"hash"`
+	SendBlockHash types.Hash    `json:"sendBlockHash"`
+	Removed       bool          `json:"removed"`
+
+	Gid        *types.Gid     `json:"gid,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	NodeAddr   *types.Address `json:"nodeAddr,omitempty"`
+	Beneficial *types.Address `json:"beneficial,omitempty"`
+	Amount     *string        `json:"amount,omitempty"`
+}
+
+const (
+	ReceiptSuccess    = "success"
+	ReceiptFail       = "fail"
+	ReceiptDepthError = "depthError"
+)
+
+// RpcReceiptParam identifies a send block by SendBlockHash, or by the Addr and Height of it if
+// SendBlockHash is nil, the send block must exist then.
+type RpcReceiptParam struct {
+	SendBlockHash *types.Hash    `json:"sendBlockHash"`
+	Addr          *types.Address `json:"addr"`
+	Height        string         `json:"height"`
+}
+
+// ReceiptMsg describes the confirmed receive block of a send block. Status is the execution result
+// of a call to a contract, which is ReceiptSuccess for a transfer to a general account. RevertReason
+// is the reason kept in a failed receive block since the revert reason fork.
+type ReceiptMsg struct {
+	SendBlockHash    types.Hash    `json:"sendBlockHash"`
+	ReceiveBlockHash types.Hash    `json:"receiveBlockHash"`
+	Addr             types.Address `json:"addr"`
+	Height           string        `json:"height"`
+	Status           string        `json:"status"`
+	SnapshotHash     types.Hash    `json:"snapshotHash"`
+	SnapshotHeight   string        `json:"snapshotHeight"`
+	RevertReason     string        `json:"revertReason,omitempty"`
+}
+
+const (
+	QuotaPledgeChanged  = "pledgeChanged"
+	QuotaBelowThreshold = "belowThreshold"
+	QuotaAboveThreshold = "aboveThreshold"
+	maxQuotaThresholds  = 16
+)
+
+// RpcQuotaEventsParam sets the quotas, in decimal, the quota of the address is watched to cross.
+type RpcQuotaEventsParam struct {
+	Thresholds []string `json:"thresholds"`
+}
+
+// QuotaEventMsg describes a change of the pledge amount benefiting Addr, or a change of the quota of Addr
+// crossing Threshold, at the snapshot block SnapshotHash. Event is QuotaPledgeChanged, QuotaBelowThreshold
+// or QuotaAboveThreshold.
+type QuotaEventMsg struct {
+	Event            string        `json:"event"`
+	Addr             types.Address `json:"addr"`
+	Threshold        string        `json:"threshold,omitempty"`
+	PledgeAmount     string        `json:"pledgeAmount"`
+	PrevPledgeAmount string        `json:"prevPledgeAmount"`
+	Quota            string        `json:"quota"`
+	PrevQuota        string        `json:"prevQuota"`
+	SnapshotHash     types.Hash    `json:"snapshotHash"`
+	SnapshotHeight   string        `json:"snapshotHeight"`
+}
+
+// LogsMsg describes a vm log emitted by the contract Addr. Event is the event registered for the log in the
+// event registry, nil if not registered.
+type LogsMsg struct {
+	Log              *ledger.VmLog `json:"log"`
+	Event            *api.LogEvent `json:"event,omitempty"`
+	AccountBlockHash types.Hash    `json:"accountBlockHash"`
+	Addr             types.Address `json:"addr"`
+	Removed          bool          `json:"removed"`
+}
+
+// OnroadMsg describes a change of an onroad send block. Closed is set when the
+// send block has been received, Removed when the change has been rolled back.
+type OnroadMsg struct {
+	Hash    types.Hash        `json:"hash"`
+	Addr    types.Address     `json:"addr"`
+	TokenId types.TokenTypeId `json:"tokenId"`
+	Closed  bool              `json:"closed"`
+	Removed bool              `json:"removed"`
+}
+
+type heightRange struct {
+	fromHeight uint64
+	toHeight   uint64
+}
+
+func (r heightRange) contains(height uint64) bool {
+	if r.fromHeight != 0 && height < r.fromHeight {
+		return false
+	}
+	if r.toHeight != 0 && height > r.toHeight {
+		return false
+	}
+	return true
+}
+
+type accountBlocksParam struct {
+	full   bool
+	fields map[string]bool
+}
+
+func (p *RpcAccountBlocksParam) toAccountBlocksParam() (*accountBlocksParam, error) {
+	if p == nil || (!p.Full && len(p.Fields) == 0) {
+		return nil, nil
+	}
+	param := &accountBlocksParam{full: p.Full, fields: make(map[string]bool, len(p.Fields))}
+	for _, field := range p.Fields {
+		known := false
+		for _, f := range accountBlockFields {
+			if f == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, ErrUnknownField
+		}
+		param.fields[field] = true
+	}
+	return param, nil
+}
+
+func toAddrSet(addrList []types.Address) (map[types.Address]struct{}, error) {
+	if len(addrList) == 0 {
+		return nil, ErrEmptyAddrList
+	}
+	addrSet := make(map[types.Address]struct{}, len(addrList))
+	for _, addr := range addrList {
+		addrSet[addr] = struct{}{}
+	}
+	return addrSet, nil
+}
+
+// filterParam matches any address if addrRange is nil.
+type filterParam struct {
+	addrRange  map[types.Address]heightRange
+	topics     [][]types.Hash
+	tokenIdSet map[types.TokenTypeId]struct{}
+}
+
+// toFilterParam converts p, an empty addrRange is converted to nil which matches any address
+// if anyAddr is set.
+func (p *RpcFilterParam) toFilterParam(anyAddr bool) (*filterParam, error) {
+	if len(p.AddrRange) == 0 && !anyAddr {
+		return nil, ErrEmptyAddrRange
+	}
+	var addrRange map[types.Address]heightRange
+	if len(p.AddrRange) > 0 {
+		addrRange = make(map[types.Address]heightRange, len(p.AddrRange))
+	}
+	for hexAddr, r := range p.AddrRange {
+		addr, err := types.HexToAddress(hexAddr)
+		if err != nil {
+			return nil, err
+		}
+		hr, err := r.toHeightRange()
+		if err != nil {
+			return nil, err
+		}
+		addrRange[addr] = hr
+	}
+	if len(p.Topics) > ledger.MaxVmLogTopics {
+		return nil, ErrInvalidTopics
+	}
+	return &filterParam{addrRange: addrRange, topics: p.Topics, tokenIdSet: toTokenIdSet(p.TokenIds)}, nil
+}
+
+func (p *filterParam) matchAddr(addr types.Address, height uint64) bool {
+	if p.addrRange == nil {
+		return true
+	}
+	hr, ok := p.addrRange[addr]
+	return ok && hr.contains(height)
+}
+
+func (r *Range) toHeightRange() (heightRange, error) {
+	hr := heightRange{}
+	if r == nil {
+		return hr, nil
+	}
+	var err error
+	if r.FromHeight != "" {
+		if hr.fromHeight, err = strconv.ParseUint(r.FromHeight, 10, 64); err != nil {
+			return hr, err
+		}
+	}
+	if r.ToHeight != "" {
+		if hr.toHeight, err = strconv.ParseUint(r.ToHeight, 10, 64); err != nil {
+			return hr, err
+		}
+	}
+	if hr.toHeight != 0 && hr.fromHeight > hr.toHeight {
+		return hr, ErrInvalidRange
+	}
+	return hr, nil
+}
+
+type onroadFilterParam struct {
+	addrSet    map[types.Address]struct{}
+	tokenIdSet map[types.TokenTypeId]struct{}
+}
+
+func (p *RpcOnroadFilterParam) toFilterParam() (*onroadFilterParam, error) {
+	addrSet, err := toAddrSet(p.AddrList)
+	if err != nil {
+		return nil, err
+	}
+	return &onroadFilterParam{addrSet: addrSet, tokenIdSet: toTokenIdSet(p.TokenIdList)}, nil
+}
+
+// toTokenIdSet returns nil for an empty list, which matches any token.
+func toTokenIdSet(tokenIdList []types.TokenTypeId) map[types.TokenTypeId]struct{} {
+	if len(tokenIdList) == 0 {
+		return nil
+	}
+	tokenIdSet := make(map[types.TokenTypeId]struct{}, len(tokenIdList))
+	for _, tokenId := range tokenIdList {
+		tokenIdSet[tokenId] = struct{}{}
+	}
+	return tokenIdSet
+}
+
+func matchTokenId(tokenIdSet map[types.TokenTypeId]struct{}, tokenId types.TokenTypeId) bool {
+	if tokenIdSet == nil {
+		return true
+	}
+	_, ok := tokenIdSet[tokenId]
+	return ok
+}
+
+func (p *onroadFilterParam) matchAddr(addr types.Address) bool {
+	_, ok := p.addrSet[addr]
+	return ok
+}
+
+func (p *onroadFilterParam) matchTokenId(tokenId types.TokenTypeId) bool {
+	return matchTokenId(p.tokenIdSet, tokenId)
+}
+
+// contractEventsParam matches any event if eventSet is nil and any account if addrSet is nil.
+type contractEventsParam struct {
+	eventSet map[string]struct{}
+	addrSet  map[types.Address]struct{}
+}
+
+func (p *RpcContractEventsParam) toFilterParam() (*contractEventsParam, error) {
+	param := &contractEventsParam{}
+	if p == nil {
+		return param, nil
+	}
+	if len(p.Events) > 0 {
+		param.eventSet = make(map[string]struct{}, len(p.Events))
+	}
+	for _, event := range p.Events {
+		known := false
+		for _, e := range contractEvents {
+			if e == event {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, ErrUnknownEvent
+		}
+		param.eventSet[event] = struct{}{}
+	}
+	if len(p.AddrList) > 0 {
+		param.addrSet, _ = toAddrSet(p.AddrList)
+	}
+	return param, nil
+}
+
+func (p *contractEventsParam) match(msg *ContractEventMsg) bool {
+	if p.eventSet != nil {
+		if _, ok := p.eventSet[msg.Event]; !ok {
+			return false
+		}
+	}
+	if p.addrSet != nil {
+		if _, ok := p.addrSet[msg.Addr]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// toSendBlockHash returns the hash of the send block p identifies.
+func (p *RpcReceiptParam) toSendBlockHash(c chain.Chain) (types.Hash, error) {
+	if p == nil {
+		return types.Hash{}, ErrInvalidReceipt
+	}
+	if p.SendBlockHash != nil {
+		return *p.SendBlockHash, nil
+	}
+	if p.Addr == nil || p.Height == "" {
+		return types.Hash{}, ErrInvalidReceipt
+	}
+	height, err := strconv.ParseUint(p.Height, 10, 64)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	block, err := c.GetAccountBlockByHeight(p.Addr, height)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	if block == nil || !block.IsSendBlock() {
+		return types.Hash{}, ErrSendBlockNotFound
+	}
+	return block.Hash, nil
+}
+
+type quotaEventsParam struct {
+	addr       types.Address
+	thresholds []uint64
+
+	// the pledge amount and the quota last seen
+	pledgeAmount *big.Int
+	quota        uint64
+}
+
+func (p *RpcQuotaEventsParam) toQuotaEventsParam(addr types.Address) (*quotaEventsParam, error) {
+	param := &quotaEventsParam{addr: addr, pledgeAmount: new(big.Int)}
+	if p == nil {
+		return param, nil
+	}
+	if len(p.Thresholds) > maxQuotaThresholds {
+		return nil, ErrTooManyThresholds
+	}
+	for _, s := range p.Thresholds {
+		threshold, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		param.thresholds = append(param.thresholds, threshold)
+	}
+	sort.Slice(param.thresholds, func(i, j int) bool {
+		return param.thresholds[i] < param.thresholds[j]
+	})
+	return param, nil
+}
+
+// update returns the events between the state last seen and the pledge amount and the quota at
+// snapshotBlock, which become the state last seen.
+func (p *quotaEventsParam) update(pledgeAmount *big.Int, quota uint64, snapshotBlock *ledger.SnapshotBlock) []*QuotaEventMsg {
+	var msgs []*QuotaEventMsg
+	newMsg := func(event string) *QuotaEventMsg {
+		msg := &QuotaEventMsg{
+			Event:            event,
+			Addr:             p.addr,
+			PledgeAmount:     pledgeAmount.String(),
+			PrevPledgeAmount: p.pledgeAmount.String(),
+			Quota:            strconv.FormatUint(quota, 10),
+			PrevQuota:        strconv.FormatUint(p.quota, 10),
+			SnapshotHash:     snapshotBlock.Hash,
+			SnapshotHeight:   strconv.FormatUint(snapshotBlock.Height, 10),
+		}
+		msgs = append(msgs, msg)
+		return msg
+	}
+
+	if pledgeAmount.Cmp(p.pledgeAmount) != 0 {
+		newMsg(QuotaPledgeChanged)
+	}
+	for _, threshold := range p.thresholds {
+		if p.quota >= threshold && quota < threshold {
+			newMsg(QuotaBelowThreshold).Threshold = strconv.FormatUint(threshold, 10)
+		} else if p.quota < threshold && quota >= threshold {
+			newMsg(QuotaAboveThreshold).Threshold = strconv.FormatUint(threshold, 10)
+		}
+	}
+
+	p.pledgeAmount, p.quota = pledgeAmount, quota
+	return msgs
+}
@@ -1,15 +1,19 @@
 package api
 
 import (
+	"bytes"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/chain"
 	"github.com/vitelabs/go-vite/chain/trie_gc"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/generator"
 	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/ledger/statement"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm_context"
 	"strconv"
+	"time"
 )
 
 // !!! Block = Transaction = TX
@@ -30,6 +34,12 @@ type GcStatus struct {
 
 	ClearedHeight uint64 `json:"clearedHeight"`
 	MarkedHeight  uint64 `json:"markedHeight"`
+
+	// RetainMinHeight is the min height of the snapshot blocks whose states are retained, the states below it are
+	// kept only at the checkpoints
+	RetainMinHeight uint64 `json:"retainMinHeight"`
+	DeletedNodes    uint64 `json:"deletedNodes"`
+	TrieDbSize      uint64 `json:"trieDbSize"`
 }
 
 type LedgerApi struct {
@@ -42,7 +52,7 @@ func (l LedgerApi) String() string {
 }
 
 func (l *LedgerApi) ledgerBlockToRpcBlock(block *ledger.AccountBlock) (*AccountBlock, error) {
-	return ledgerToRpcBlock(block, l.chain)
+	return LedgerToRpcBlock(block, l.chain)
 }
 
 func (l *LedgerApi) ledgerBlocksToRpcBlocks(list []*ledger.AccountBlock) ([]*AccountBlock, error) {
@@ -202,6 +212,20 @@ func (l *LedgerApi) GetBlocksByAccAddr(addr types.Address, index int, count int)
 	}
 }
 
+// GetBlocksByDestinationTag returns the send blocks to addr whose data carries the destination tag, exchanges
+// use it to credit the deposits of sub accounts sharing one address.
+func (l *LedgerApi) GetBlocksByDestinationTag(addr types.Address, tag uint64, index int, count int) ([]*AccountBlock, error) {
+	list, err := l.chain.GetAccountBlocksByDestinationTag(&addr, tag, index, count)
+	if err != nil {
+		l.log.Error("GetAccountBlocksByDestinationTag failed, error is "+err.Error(), "method", "GetBlocksByDestinationTag")
+		return nil, err
+	}
+	if len(list) <= 0 {
+		return nil, nil
+	}
+	return l.ledgerBlocksToRpcBlocks(list)
+}
+
 func (l *LedgerApi) GetAccountByAccAddr(addr types.Address) (*RpcAccountInfo, error) {
 	l.log.Info("GetAccountByAccAddr")
 
@@ -439,7 +463,39 @@ func (l *LedgerApi) AccountType(addr types.Address) (uint64, error) {
 	return l.chain.AccountType(&addr)
 }
 
-func (l *LedgerApi) GetVmLogList(blockHash types.Hash) (ledger.VmLogList, error) {
+// GetStatement returns the statement of addr between the unix times from and to, 0 leaves the range open on
+// that side, in format "csv" or "ofx". Empty tokenIds selects all the tokens, the entries carry no fiat values.
+func (l *LedgerApi) GetStatement(addr types.Address, from, to int64, tokenIds []types.TokenTypeId, format string) (string, error) {
+	filter := statement.Filter{TokenIds: tokenIds}
+	if from > 0 {
+		filter.From = time.Unix(from, 0)
+	}
+	if to > 0 {
+		filter.To = time.Unix(to, 0)
+	}
+	s, err := statement.Build(l.chain, addr, filter, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "csv":
+		err = statement.WriteCSV(&buf, s)
+	case "ofx":
+		err = statement.WriteOFX(&buf, s)
+	default:
+		return "", errors.New("unknown statement format " + format)
+	}
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// GetVmLogList returns the vm logs of the block, annotated with the events registered by the contract in the
+// event registry.
+func (l *LedgerApi) GetVmLogList(blockHash types.Hash) ([]*VmLog, error) {
 	block, err := l.chain.GetAccountBlockByHash(&blockHash)
 	if block == nil {
 		if err != nil {
@@ -457,14 +513,32 @@ func (l *LedgerApi) GetVmLogList(blockHash types.Hash) (ledger.VmLogList, error)
 		}
 		return nil, nil
 	}
-	return l.chain.GetVmLogList(block.LogHash)
+	logList, err := l.chain.GetVmLogList(block.LogHash)
+	if err != nil || len(logList) == 0 {
+		return nil, err
+	}
+	resolver, err := NewLogEventResolver(l.chain)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*VmLog, len(logList))
+	for i, vmLog := range logList {
+		list[i] = &VmLog{VmLog: vmLog, Event: resolver.Resolve(block.AccountAddress, vmLog)}
+	}
+	return list, nil
 }
 
 func (l *LedgerApi) GetGcStatus() *GcStatus {
 	statusCode := l.chain.TrieGc().Status()
+	stats := l.chain.TrieGc().Stats()
 
 	gStatus := &GcStatus{
 		Code: statusCode,
+
+		ClearedHeight:   stats.PrunedHeight,
+		RetainMinHeight: l.chain.TrieGc().RetainMinHeight(),
+		DeletedNodes:    stats.DeletedNodes,
+		TrieDbSize:      stats.TrieDbSize,
 	}
 	switch statusCode {
 	case trie_gc.STATUS_STOPPED:
@@ -476,3 +550,89 @@ func (l *LedgerApi) GetGcStatus() *GcStatus {
 	}
 	return gStatus
 }
+
+type StorageProof struct {
+	SnapshotHash   types.Hash `json:"snapshotHash"`
+	SnapshotHeight string     `json:"snapshotHeight"`
+	// StateHash is the state hash of the snapshot block, the root the proof is checked against
+	StateHash types.Hash `json:"stateHash"`
+	// Value is the value of the key, nil if it is absent from the storage
+	Value []byte `json:"value"`
+	// AccountProof proves the state hash of the account in the state of the snapshot block
+	AccountProof [][]byte `json:"accountProof"`
+	// StorageProof proves the value in the state of the account, nil if the account has no state
+	StorageProof [][]byte `json:"storageProof"`
+}
+
+// GetStorageProof returns the value of key in the storage of addr at the snapshot block of snapshotHash, the latest
+// snapshot block if nil, with the proof of it against the state hash of the snapshot block. The proof is checked by
+// vm_context.StorageProof.Verify.
+func (l *LedgerApi) GetStorageProof(addr types.Address, key []byte, snapshotHash *types.Hash) (*StorageProof, error) {
+	l.log.Info("GetStorageProof")
+	var snapshotBlock *ledger.SnapshotBlock
+	if snapshotHash == nil {
+		snapshotBlock = l.chain.GetLatestSnapshotBlock()
+	} else {
+		var err error
+		if snapshotBlock, err = l.chain.GetSnapshotBlockHeadByHash(snapshotHash); err != nil {
+			return nil, err
+		}
+		if snapshotBlock == nil {
+			return nil, errors.New("snapshot block not found")
+		}
+	}
+	if err := l.checkStateRetained(snapshotBlock.Height); err != nil {
+		return nil, err
+	}
+	proof, err := vm_context.NewStorageProof(l.chain, snapshotBlock, addr, key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := proof.Verify(snapshotBlock.StateHash, addr, key)
+	if err != nil {
+		return nil, err
+	}
+	return &StorageProof{
+		SnapshotHash:   snapshotBlock.Hash,
+		SnapshotHeight: strconv.FormatUint(snapshotBlock.Height, 10),
+		StateHash:      snapshotBlock.StateHash,
+		Value:          value,
+		AccountProof:   proof.AccountProof,
+		StorageProof:   proof.StorageProof,
+	}, nil
+}
+
+// GetValueAt returns the value of key in the storage of addr at the snapshot block of snapshotHeight, nil if it is
+// absent. The state of a past snapshot block is kept only on an archive node, where the ledger gc is disabled.
+func (l *LedgerApi) GetValueAt(addr types.Address, key []byte, snapshotHeight uint64) ([]byte, error) {
+	l.log.Info("GetValueAt")
+	if err := l.checkStateRetained(snapshotHeight); err != nil {
+		return nil, err
+	}
+	vmContext, err := vm_context.NewVmContextAt(l.chain, snapshotHeight, &addr)
+	if err != nil {
+		return nil, err
+	}
+	return vmContext.GetStorage(&addr, key), nil
+}
+
+// GetBalanceAt returns the balance of tokenId of addr at the snapshot block of snapshotHeight, like GetValueAt
+func (l *LedgerApi) GetBalanceAt(addr types.Address, tokenId types.TokenTypeId, snapshotHeight uint64) (string, error) {
+	l.log.Info("GetBalanceAt")
+	if err := l.checkStateRetained(snapshotHeight); err != nil {
+		return "", err
+	}
+	vmContext, err := vm_context.NewVmContextAt(l.chain, snapshotHeight, &addr)
+	if err != nil {
+		return "", err
+	}
+	return *bigIntToString(vmContext.GetBalance(&addr, &tokenId)), nil
+}
+
+// checkStateRetained refuses the queries of the states the ledger gc has pruned
+func (l *LedgerApi) checkStateRetained(snapshotHeight uint64) error {
+	if l.chain.TrieGc().IsPruned(snapshotHeight) {
+		return vm_context.ErrStatePruned
+	}
+	return nil
+}
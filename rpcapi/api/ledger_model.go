@@ -6,6 +6,7 @@ import (
 	"github.com/vitelabs/go-vite/chain/sender"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm"
 	"math/big"
 	"strconv"
 	"time"
@@ -29,6 +30,8 @@ type AccountBlock struct {
 	TokenInfo      *RpcTokenInfo `json:"tokenInfo"`
 
 	ReceiveBlockHeights []string `json:"receiveBlockHeights"`
+
+	RevertReason *string `json:"revertReason,omitempty"`
 }
 
 func (ab *AccountBlock) LedgerAccountBlock() (*ledger.AccountBlock, error) {
@@ -214,7 +217,7 @@ func createKafkaProducerInfo(producer *sender.Producer) *KafkaProducerInfo {
 	return producerInfo
 }
 
-func ledgerToRpcBlock(block *ledger.AccountBlock, chain chain.Chain) (*AccountBlock, error) {
+func LedgerToRpcBlock(block *ledger.AccountBlock, chain chain.Chain) (*AccountBlock, error) {
 	confirmTimes, err := chain.GetConfirmTimes(&block.Hash)
 
 	if err != nil {
@@ -244,6 +247,9 @@ func ledgerToRpcBlock(block *ledger.AccountBlock, chain chain.Chain) (*AccountBl
 	rpcAccountBlock := createAccountBlock(block, token, confirmTimes)
 	rpcAccountBlock.FromAddress = fromAddress
 	rpcAccountBlock.ToAddress = toAddress
+	if reason, ok := vm.GetRevertReason(block.Data); ok && block.IsReceiveBlock() {
+		rpcAccountBlock.RevertReason = &reason
+	}
 
 	if block.IsSendBlock() {
 		if block.Meta == nil {
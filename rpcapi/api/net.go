@@ -46,6 +46,17 @@ func (n *NetApi) SyncInfo() SyncInfo {
 	}
 }
 
+// PeerVersions returns how many peers run each release and which forks they advertise
+func (n *NetApi) PeerVersions() net.PeerVersions {
+	return n.net.PeerVersions()
+}
+
+// PeerMetadata returns the operator, contact, region and services the other nodes signed and gossiped,
+// for light clients and explorers to select the peers
+func (n *NetApi) PeerMetadata() []net.PeerMetadata {
+	return n.net.PeerMetadata()
+}
+
 func (n *NetApi) SyncDetail() net.SyncDetail {
 	return n.net.Detail()
 }
@@ -98,7 +98,7 @@ func (o PrivateOnroadApi) GetOnroadBlocksByAddress(address types.Address, index
 	sum := 0
 	for k, v := range blockList {
 		if v != nil {
-			accountBlock, e := ledgerToRpcBlock(v, o.manager.DbAccess().Chain)
+			accountBlock, e := LedgerToRpcBlock(v, o.manager.DbAccess().Chain)
 			if e != nil {
 				return nil, e
 			}
@@ -5,6 +5,7 @@ import (
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts"
 	"github.com/vitelabs/go-vite/vm/contracts/abi"
 	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_context"
@@ -41,6 +42,50 @@ func (p *PledgeApi) GetCancelPledgeData(beneficialAddr types.Address, amount str
 	}
 }
 
+// GetAgentPledgeData returns the data of a pledge the sender pays on behalf of pledgeAddr, the sender
+// cancels it and gets the ViteToken back.
+func (p *PledgeApi) GetAgentPledgeData(pledgeAddr types.Address, beneficialAddr types.Address) ([]byte, error) {
+	return abi.ABIPledge.PackMethod(abi.MethodNameAgentPledge, pledgeAddr, beneficialAddr)
+}
+
+func (p *PledgeApi) GetAgentCancelPledgeData(pledgeAddr types.Address, beneficialAddr types.Address, amount string) ([]byte, error) {
+	if bAmount, err := stringToBigInt(&amount); err == nil {
+		return abi.ABIPledge.PackMethod(abi.MethodNameAgentCancelPledge, pledgeAddr, beneficialAddr, bAmount)
+	} else {
+		return nil, err
+	}
+}
+
+// GetLockPledgeData returns the data of a pledge locked for tier, see GetPledgeLockTiers.
+func (p *PledgeApi) GetLockPledgeData(beneficialAddr types.Address, tier uint8) ([]byte, error) {
+	return abi.ABIPledge.PackMethod(abi.MethodNameLockPledge, beneficialAddr, tier)
+}
+
+func (p *PledgeApi) GetCancelLockPledgeData(beneficialAddr types.Address, tier uint8, amount string) ([]byte, error) {
+	if bAmount, err := stringToBigInt(&amount); err == nil {
+		return abi.ABIPledge.PackMethod(abi.MethodNameCancelLockPledge, beneficialAddr, tier, bAmount)
+	} else {
+		return nil, err
+	}
+}
+
+type PledgeLockTier struct {
+	Tier       uint8  `json:"tier"`
+	LockHeight string `json:"lockHeight"`
+	Multiplier string `json:"multiplier"`
+}
+
+// GetPledgeLockTiers returns the lock tiers of the pledges, a pledge locked for a tier gets the quota of
+// Multiplier percent of its amount.
+func (p *PledgeApi) GetPledgeLockTiers() []*PledgeLockTier {
+	tiers := contracts.GetPledgeLockTiers()
+	list := make([]*PledgeLockTier, len(tiers))
+	for i, tier := range tiers {
+		list[i] = &PledgeLockTier{uint8(i + 1), uint64ToString(tier.LockHeight), uint64ToString(tier.Multiplier)}
+	}
+	return list
+}
+
 type QuotaAndTxNum struct {
 	Quota string `json:"quota"`
 	TxNum string `json:"txNum"`
@@ -68,6 +113,9 @@ type PledgeInfo struct {
 	WithdrawHeight string        `json:"withdrawHeight"`
 	BeneficialAddr types.Address `json:"beneficialAddr"`
 	WithdrawTime   int64         `json:"withdrawTime"`
+	Agent          bool          `json:"agent"`
+	AgentAddr      types.Address `json:"agentAddr"`
+	LockTier       uint8         `json:"lockTier"`
 }
 type byWithdrawHeight []*abi.PledgeInfo
 
@@ -75,6 +123,9 @@ func (a byWithdrawHeight) Len() int      { return len(a) }
 func (a byWithdrawHeight) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a byWithdrawHeight) Less(i, j int) bool {
 	if a[i].WithdrawHeight == a[j].WithdrawHeight {
+		if a[i].BeneficialAddr == a[j].BeneficialAddr {
+			return a[i].AgentAddr.String() < a[j].AgentAddr.String()
+		}
 		return a[i].BeneficialAddr.String() < a[j].BeneficialAddr.String()
 	}
 	return a[i].WithdrawHeight < a[j].WithdrawHeight
@@ -101,7 +152,10 @@ func (p *PledgeApi) GetPledgeList(addr types.Address, index int, count int) (*Pl
 			*bigIntToString(info.Amount),
 			uint64ToString(info.WithdrawHeight),
 			info.BeneficialAddr,
-			getWithdrawTime(snapshotBlock.Timestamp, snapshotBlock.Height, info.WithdrawHeight)}
+			getWithdrawTime(snapshotBlock.Timestamp, snapshotBlock.Height, info.WithdrawHeight),
+			info.IsAgent,
+			info.AgentAddr,
+			info.LockTier}
 	}
 	return &PledgeInfoList{*bigIntToString(amount), len(list), targetList}, nil
 }
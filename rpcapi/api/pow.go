@@ -1,7 +1,6 @@
 package api
 
 import (
-	"encoding/binary"
 	"errors"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/pow"
@@ -30,14 +29,10 @@ func (p Pow) GetPowNonce(difficulty string, data types.Hash) ([]byte, error) {
 		return nil, e
 	}
 
-	nonceStr := *work
-	nonceBig, ok := new(big.Int).SetString(nonceStr, 16)
-	if !ok {
-		return nil, errors.New("wrong nonce str")
+	nn, e := remote.WorkToNonce(*work)
+	if e != nil {
+		return nil, e
 	}
-	nonceUint64 := nonceBig.Uint64()
-	nn := make([]byte, 8)
-	binary.LittleEndian.PutUint64(nn[:], nonceUint64)
 
 	bd, ok := new(big.Int).SetString(difficulty, 10)
 	if !ok {
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+type QuotaApi struct {
+	chain chain.Chain
+}
+
+func NewQuotaApi(vite *vite.Vite) *QuotaApi {
+	return &QuotaApi{
+		chain: vite.Chain(),
+	}
+}
+
+func (q QuotaApi) String() string {
+	return "QuotaApi"
+}
+
+type QuotaEstimate struct {
+	Quota string `json:"quota"`
+	// PledgeAmount is the least pledge amount with which an account can send such a transaction in every snapshot
+	// block without PoW, nil if the quota is more than pledge can get or the pledge is not asked for
+	PledgeAmount *string `json:"pledgeAmount"`
+	// Difficulty is the PoW difficulty to get the quota with no pledge, nil if the quota is more than PoW can get
+	Difficulty *string `json:"difficulty"`
+}
+
+// Estimate returns the quota of a send block with data to toAddress at the latest snapshot block, a nil toAddress
+// creates a contract, and the pledge amount or the PoW difficulty to get the quota.
+func (q *QuotaApi) Estimate(data []byte, toAddress *types.Address, usePledge bool) (*QuotaEstimate, error) {
+	log.Info("Estimate")
+	quotaRequired, err := calcSendQuotaRequired(q.chain.GetLatestSnapshotBlock().Height, toAddress, data)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &QuotaEstimate{
+		Quota:      uint64ToString(quotaRequired),
+		Difficulty: bigIntToString(quota.CalcPoWDifficulty(quotaRequired)),
+	}
+	if usePledge {
+		estimate.PledgeAmount = bigIntToString(quota.CalcPledgeAmount(quotaRequired))
+	}
+	return estimate, nil
+}
+
+type Congestion struct {
+	SnapshotHash   types.Hash `json:"snapshotHash"`
+	SnapshotHeight string     `json:"snapshotHeight"`
+	// Fullness is the count of the accounts snapshotted by the latest snapshot blocks of the window, the quota is
+	// scaled down when it is beyond Capacity
+	Fullness string `json:"fullness"`
+	Capacity string `json:"capacity"`
+	// Scale is the per mille the quota of the accounts is scaled by
+	Scale string `json:"scale"`
+}
+
+// GetCongestion returns the congestion of the net at the latest snapshot block and the scale of the quota it makes.
+func (q *QuotaApi) GetCongestion() (*Congestion, error) {
+	log.Info("GetCongestion")
+	db, err := vm_context.NewVmContext(q.chain, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	fullness, scale := quota.GetCongestion(db)
+	current := db.CurrentSnapshotBlock()
+	return &Congestion{
+		SnapshotHash:   current.Hash,
+		SnapshotHeight: uint64ToString(current.Height),
+		Fullness:       uint64ToString(fullness),
+		Capacity:       uint64ToString(quota.CongestionCapacity),
+		Scale:          uint64ToString(scale),
+	}, nil
+}
+
+func (q *QuotaApi) GetLeaseQuotaData(beneficial types.Address, amount string, period uint64) ([]byte, error) {
+	amountBig, err := stringToBigInt(&amount)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIQuotaLease.PackMethod(abi.MethodNameLeaseQuota, beneficial, amountBig, period)
+}
+
+type QuotaLease struct {
+	Lessor           types.Address `json:"lessor"`
+	Beneficial       types.Address `json:"beneficial"`
+	Amount           string        `json:"amount"`
+	ExpirationHeight string        `json:"expirationHeight"`
+}
+
+type QuotaLeaseList struct {
+	SnapshotHeight string `json:"snapshotHeight"`
+	// LeasedOut are the active leases of the address, their amounts are taken from the pledge amount of the address
+	LeasedOut       []*QuotaLease `json:"leasedOut"`
+	LeasedOutAmount string        `json:"leasedOutAmount"`
+	// LeasedIn are the active leases to the address, their amounts are added to the pledge amount of the address
+	LeasedIn       []*QuotaLease `json:"leasedIn"`
+	LeasedInAmount string        `json:"leasedInAmount"`
+}
+
+// GetLeaseList returns the leases of quota from and to addr in effect at the latest snapshot block.
+func (q *QuotaApi) GetLeaseList(addr types.Address) (*QuotaLeaseList, error) {
+	log.Info("GetLeaseList")
+	db, err := vm_context.NewVmContext(q.chain, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	snapshotHeight := db.CurrentSnapshotBlock().Height
+	leasedOut := abi.GetQuotaLeaseOutList(db, addr)
+	leasedIn := abi.GetQuotaLeaseInList(db, addr)
+	return &QuotaLeaseList{
+		SnapshotHeight:  uint64ToString(snapshotHeight),
+		LeasedOut:       newActiveQuotaLeaseList(leasedOut, snapshotHeight),
+		LeasedOutAmount: *bigIntToString(abi.GetActiveQuotaLeaseAmount(leasedOut, snapshotHeight)),
+		LeasedIn:        newActiveQuotaLeaseList(leasedIn, snapshotHeight),
+		LeasedInAmount:  *bigIntToString(abi.GetActiveQuotaLeaseAmount(leasedIn, snapshotHeight)),
+	}, nil
+}
+
+func newActiveQuotaLeaseList(leaseList []*abi.QuotaLease, snapshotHeight uint64) []*QuotaLease {
+	list := make([]*QuotaLease, 0, len(leaseList))
+	for _, lease := range leaseList {
+		if lease.IsActive(snapshotHeight) {
+			list = append(list, &QuotaLease{
+				Lessor:           lease.Lessor,
+				Beneficial:       lease.Beneficial,
+				Amount:           *bigIntToString(lease.Amount),
+				ExpirationHeight: uint64ToString(lease.ExpirationHeight),
+			})
+		}
+	}
+	return list
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/util"
+)
+
+func TestCalcSendQuotaRequired(t *testing.T) {
+	data := []byte{0, 1, 2}
+	to := types.AddressPledge
+	if _, err := calcSendQuotaRequired(1, &to, data); err == nil {
+		t.Fatal("data of no precompiled contract method should be invalid")
+	}
+	userAddr := types.Address{1}
+	call, err := calcSendQuotaRequired(1, &userAddr, data)
+	if err != nil || call != util.TxGas+2*util.InitialGasTable.TxDataNonZeroGas+util.InitialGasTable.TxDataZeroGas {
+		t.Fatalf("unexpected quota of a call %v, %v", call, err)
+	}
+	create, err := calcSendQuotaRequired(1, nil, data)
+	if err != nil || create != call-util.TxGas+util.InitialGasTable.TxContractCreationGas {
+		t.Fatalf("unexpected quota of a create %v, %v", create, err)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"math/big"
 	"sort"
 	"time"
 
@@ -9,7 +10,9 @@ import (
 	"github.com/vitelabs/go-vite/consensus"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts"
 	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_context"
 )
 
@@ -43,6 +46,15 @@ func (r *RegisterApi) GetRewardData(gid types.Gid, name string, beneficialAddr t
 func (r *RegisterApi) GetUpdateRegistrationData(gid types.Gid, name string, nodeAddr types.Address) ([]byte, error) {
 	return abi.ABIRegister.PackMethod(abi.MethodNameUpdateRegistration, gid, name, nodeAddr)
 }
+func (r *RegisterApi) GetUpdateDisplayNameData(gid types.Gid, name string, displayName string) ([]byte, error) {
+	return abi.ABIRegister.PackMethod(abi.MethodNameUpdateDisplayName, gid, name, displayName)
+}
+
+// GetUpdateRewardWithdrawAddressData returns the data setting the address which may withdraw the reward besides
+// the pledge address, the pledge address itself removes it.
+func (r *RegisterApi) GetUpdateRewardWithdrawAddressData(gid types.Gid, name string, withdrawAddr types.Address) ([]byte, error) {
+	return abi.ABIRegister.PackMethod(abi.MethodNameUpdateRewardWithdrawAddress, gid, name, withdrawAddr)
+}
 
 type RegistrationInfo struct {
 	Name           string        `json:"name"`
@@ -98,6 +110,57 @@ func (r *RegisterApi) GetRegistration(name string, gid types.Gid) (*types.Regist
 	return abi.GetRegistration(vmContext, gid, name), nil
 }
 
+type RegistrationDetail struct {
+	Name               string          `json:"name"`
+	DisplayName        string          `json:"displayName"`
+	NodeAddr           types.Address   `json:"nodeAddr"`
+	PledgeAddr         types.Address   `json:"pledgeAddr"`
+	RewardWithdrawAddr *types.Address  `json:"rewardWithdrawAddr"`
+	PledgeAmount       string          `json:"pledgeAmount"`
+	WithdrawHeight     string          `json:"withdrawHeight"`
+	WithdrawTime       int64           `json:"withdrawTime"`
+	CancelHeight       string          `json:"cancelHeight"`
+	HisAddrList        []types.Address `json:"hisAddrList"`
+	PendingReward      string          `json:"pendingReward"`
+}
+
+// GetRegistrationDetail returns the registration of name in gid with its display name, reward withdraw address and
+// the reward it can withdraw at the latest snapshot block.
+func (r *RegisterApi) GetRegistrationDetail(name string, gid types.Gid) (*RegistrationDetail, error) {
+	snapshotBlock := r.chain.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(r.chain, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	registration := abi.GetRegistration(vmContext, gid, name)
+	if registration == nil {
+		return nil, nil
+	}
+	pendingReward := big.NewInt(0)
+	if util.IsSnapshotGid(gid) {
+		_, _, reward, _, err := contracts.CalcReward(vmContext, registration, gid)
+		if err != nil {
+			return nil, err
+		}
+		if reward != nil {
+			pendingReward = reward
+		}
+	}
+	return &RegistrationDetail{
+		Name:               registration.Name,
+		DisplayName:        abi.GetDisplayName(vmContext, gid, name),
+		NodeAddr:           registration.NodeAddr,
+		PledgeAddr:         registration.PledgeAddr,
+		RewardWithdrawAddr: abi.GetRewardWithdrawAddr(vmContext, gid, name),
+		PledgeAmount:       *bigIntToString(registration.Amount),
+		WithdrawHeight:     uint64ToString(registration.WithdrawHeight),
+		WithdrawTime:       getWithdrawTime(snapshotBlock.Timestamp, snapshotBlock.Height, registration.WithdrawHeight),
+		CancelHeight:       uint64ToString(registration.CancelHeight),
+		HisAddrList:        registration.HisAddrList,
+		PendingReward:      *bigIntToString(pendingReward),
+	}, nil
+}
+
 // Deprecated: Use GetRegistration instead
 func (r *RegisterApi) GetRegisterPledgeAddr(name string, gid *types.Gid) (*types.Address, error) {
 	var g types.Gid
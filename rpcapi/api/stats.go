@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/stats"
+)
+
+var ErrStatsDisabled = errors.New("stats is not enabled")
+
+// StatsApi serves the rolling chain statistics kept by the stats aggregator of the node.
+type StatsApi struct {
+	aggregator *stats.Aggregator
+	log        log15.Logger
+}
+
+func NewStatsApi(aggregator *stats.Aggregator) *StatsApi {
+	return &StatsApi{
+		aggregator: aggregator,
+		log:        log15.New("module", "rpc_api/stats_api"),
+	}
+}
+
+func (s StatsApi) String() string {
+	return "StatsApi"
+}
+
+func (s *StatsApi) GetChainStats() (*stats.ChainStats, error) {
+	if s.aggregator == nil {
+		return nil, ErrStatsDisabled
+	}
+	return s.aggregator.Stats(), nil
+}
+
+// GetTps returns the account blocks per second averaged over the window of the aggregator.
+func (s *StatsApi) GetTps() (float64, error) {
+	if s.aggregator == nil {
+		return 0, ErrStatsDisabled
+	}
+	return s.aggregator.Stats().Tps, nil
+}
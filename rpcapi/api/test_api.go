@@ -71,9 +71,15 @@ func (t TestApi) GetTestToken(ctx context.Context, ToAddr types.Address) (string
 }
 
 func (t TestApi) CreateTxWithPrivKey(params CreateTxWithPrivKeyParmsTest) error {
+	_, err := t.createTxWithPrivKey(params)
+	return err
+}
+
+// createTxWithPrivKey is CreateTxWithPrivKey returning the send block added to the pool
+func (t TestApi) createTxWithPrivKey(params CreateTxWithPrivKeyParmsTest) (*ledger.AccountBlock, error) {
 	amount, ok := new(big.Int).SetString(params.Amount, 10)
 	if !ok {
-		return ErrStrToBigInt
+		return nil, ErrStrToBigInt
 	}
 
 	msg := &generator.IncomingMessage{
@@ -88,11 +94,11 @@ func (t TestApi) CreateTxWithPrivKey(params CreateTxWithPrivKeyParmsTest) error
 	}
 	_, fitestSnapshotBlockHash, err := generator.GetFittestGeneratorSnapshotHash(t.walletApi.chain, &msg.AccountAddress, nil, true)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	g, e := generator.NewGenerator(t.walletApi.chain, fitestSnapshotBlockHash, nil, &params.SelfAddr)
 	if e != nil {
-		return e
+		return nil, e
 	}
 	result, e := g.GenerateWithMessage(msg, func(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
 		var privkey ed25519.PrivateKey
@@ -106,16 +112,19 @@ func (t TestApi) CreateTxWithPrivKey(params CreateTxWithPrivKeyParmsTest) error
 	})
 	if e != nil {
 		newerr, _ := TryMakeConcernedError(e)
-		return newerr
+		return nil, newerr
 	}
 	if result.Err != nil {
 		newerr, _ := TryMakeConcernedError(result.Err)
-		return newerr
+		return nil, newerr
 	}
 	if len(result.BlockGenList) > 0 && result.BlockGenList[0] != nil {
-		return t.walletApi.pool.AddDirectAccountBlock(params.SelfAddr, result.BlockGenList[0])
+		if err := t.walletApi.pool.AddDirectAccountBlock(params.SelfAddr, result.BlockGenList[0]); err != nil {
+			return nil, err
+		}
+		return result.BlockGenList[0].AccountBlock, nil
 	} else {
-		return errors.New("generator gen an empty block")
+		return nil, errors.New("generator gen an empty block")
 	}
 
 }
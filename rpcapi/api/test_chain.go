@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/producer"
+	"github.com/vitelabs/go-vite/vite"
+)
+
+var ErrDevMiningDisabled = errors.New("dev mining is disabled, start the node of a dev network with --devmining")
+
+// TestChainApi drives the chain of a dev network for the integration tests of the dapps, the snapshot blocks
+// are produced only when MineSnapshot is called.
+type TestChainApi struct {
+	chain    chain.Chain
+	devMiner *producer.DevMiner
+	testApi  *TestApi
+	log      log15.Logger
+}
+
+func NewTestChainApi(vite *vite.Vite) *TestChainApi {
+	return &TestChainApi{
+		chain:    vite.Chain(),
+		devMiner: vite.DevMiner(),
+		testApi:  NewTestApi(NewWalletApi(vite)),
+		log:      log15.New("module", "rpc_api/test_chain_api"),
+	}
+}
+
+func (t TestChainApi) String() string {
+	return "TestChainApi"
+}
+
+type MinedSnapshotBlock struct {
+	Hash      types.Hash `json:"hash"`
+	Height    string     `json:"height"`
+	Timestamp int64      `json:"timestamp"`
+}
+
+// MineSnapshot produces count snapshot blocks at once, with all the account blocks not snapshotted yet
+// in the first one.
+func (t *TestChainApi) MineSnapshot(count uint64) ([]*MinedSnapshotBlock, error) {
+	if t.devMiner == nil {
+		return nil, ErrDevMiningDisabled
+	}
+	if count == 0 {
+		count = 1
+	}
+	blocks, err := t.devMiner.Mine(count)
+	mined := make([]*MinedSnapshotBlock, len(blocks))
+	for i, block := range blocks {
+		mined[i] = &MinedSnapshotBlock{
+			Hash:      block.Hash,
+			Height:    uint64ToString(block.Height),
+			Timestamp: block.Timestamp.Unix(),
+		}
+	}
+	return mined, err
+}
+
+// SetTime sets the timestamp in seconds of the next snapshot block, it must be after the latest one.
+func (t *TestChainApi) SetTime(timestamp int64) error {
+	if t.devMiner == nil {
+		return ErrDevMiningDisabled
+	}
+	return t.devMiner.SetTime(time.Unix(timestamp, 0))
+}
+
+type SetBalanceParams struct {
+	Address    types.Address
+	TokenId    types.TokenTypeId
+	Amount     string
+	PrivateKey string
+}
+
+// SetBalance brings the balance of tokenId of the address to amount. The ledger is never rewritten, the
+// difference is sent from the test token account and received by the address, or sent back to it, so
+// the private key of the address is required. The blocks are snapshotted by the next MineSnapshot.
+func (t *TestChainApi) SetBalance(params SetBalanceParams) error {
+	if t.devMiner == nil {
+		return ErrDevMiningDisabled
+	}
+	amount, err := stringToBigInt(&params.Amount)
+	if err != nil {
+		return err
+	}
+	if amount.Sign() < 0 {
+		return errors.New("amount can not be negative")
+	}
+	privKey, err := ed25519.HexToPrivateKey(params.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if types.PrikeyToAddress(privKey) != params.Address {
+		return errors.New("private key does not match the address")
+	}
+	faucetKey, err := ed25519.HexToPrivateKey(testapi_hexPrivKey)
+	if err != nil {
+		return err
+	}
+	faucet := types.PrikeyToAddress(faucetKey)
+	if faucet == params.Address {
+		return errors.New("can not set the balance of the test token account")
+	}
+
+	balance, err := t.chain.GetAccountBalanceByTokenId(&params.Address, &params.TokenId)
+	if err != nil {
+		return err
+	}
+	diff := new(big.Int).Sub(amount, balance)
+	switch diff.Sign() {
+	case 0:
+		return nil
+	case -1:
+		_, err = t.testApi.createTxWithPrivKey(CreateTxWithPrivKeyParmsTest{
+			SelfAddr:    params.Address,
+			ToAddr:      faucet,
+			TokenTypeId: params.TokenId,
+			PrivateKey:  params.PrivateKey,
+			Amount:      diff.Neg(diff).String(),
+		})
+		return err
+	}
+
+	sendBlock, err := t.testApi.createTxWithPrivKey(CreateTxWithPrivKeyParmsTest{
+		SelfAddr:    faucet,
+		ToAddr:      params.Address,
+		TokenTypeId: params.TokenId,
+		PrivateKey:  testapi_hexPrivKey,
+		Amount:      diff.String(),
+	})
+	if err != nil {
+		return err
+	}
+	return t.testApi.ReceiveOnroadTx(CreateReceiveTxParms{
+		SelfAddr:   params.Address,
+		FromHash:   sendBlock.Hash,
+		PrivKeyStr: params.PrivateKey,
+	})
+}
+
+type ContractStateInfo struct {
+	Address        types.Address `json:"address"`
+	SnapshotHeight string        `json:"snapshotHeight"`
+	SnapshotHash   types.Hash    `json:"snapshotHash"`
+	StateHash      types.Hash    `json:"stateHash"`
+	KeyCount       int           `json:"keyCount"`
+}
+
+// ExportContractState writes the storage of the contract at the snapshot block of snapshotHeight to fileName on
+// the node, for ImportContractState of a dev network. It is served by the nodes of any network.
+func (t *TestChainApi) ExportContractState(addr types.Address, snapshotHeight uint64, fileName string) (*ContractStateInfo, error) {
+	state, err := t.chain.ExportContractState(&addr, snapshotHeight, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return &ContractStateInfo{
+		Address:        state.Address,
+		SnapshotHeight: uint64ToString(state.SnapshotHeight),
+		SnapshotHash:   state.SnapshotHash,
+		StateHash:      state.StateHash,
+		KeyCount:       len(state.Storage),
+	}, nil
+}
+
+// ImportContractState replaces the storage of a contract with the one exported to fileName on the node, and
+// returns the hash of the account block written. The block is snapshotted by the next MineSnapshot.
+func (t *TestChainApi) ImportContractState(fileName string) (*types.Hash, error) {
+	if t.devMiner == nil {
+		return nil, ErrDevMiningDisabled
+	}
+	block, err := t.chain.ImportContractState(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return &block.Hash, nil
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"errors"
 	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/crypto/ed25519"
@@ -141,7 +142,7 @@ func (t Tx) SendTxWithPrivateKey(param SendTxWithPrivateKeyParam) (*AccountBlock
 		if err := t.vite.Pool().AddDirectAccountBlock(*param.SelfAddr, result.BlockGenList[0]); err != nil {
 			return nil, err
 		}
-		return ledgerToRpcBlock(result.BlockGenList[0].AccountBlock, t.vite.Chain())
+		return LedgerToRpcBlock(result.BlockGenList[0].AccountBlock, t.vite.Chain())
 
 	} else {
 		return nil, errors.New("generator gen an empty block")
@@ -174,32 +175,33 @@ type CalcPoWDifficultyParam struct {
 }
 
 func (t Tx) CalcPoWDifficulty(param CalcPoWDifficultyParam) (difficulty string, err error) {
+	db, err := vm_context.NewVmContext(t.vite.Chain(), &param.SnapshotHash, &param.PrevHash, &param.SelfAddr)
+	if err != nil {
+		return "", err
+	}
+	gasTable := util.GasTableByHeight(db.CurrentSnapshotBlock().Height)
 	var quotaRequired uint64
 	if param.BlockType == ledger.BlockTypeSendCreate {
-		quotaRequired, _ = util.IntrinsicGasCost(param.Data, false)
+		quotaRequired, _ = gasTable.IntrinsicGasCost(param.Data, false)
 	} else if param.BlockType == ledger.BlockTypeReceive {
-		quotaRequired, _ = util.IntrinsicGasCost(nil, false)
+		quotaRequired, _ = gasTable.IntrinsicGasCost(nil, false)
 	} else if param.BlockType == ledger.BlockTypeSendCall {
 		if param.ToAddr == nil {
 			return "", errors.New("toAddr is nil")
 		}
-		if types.IsPrecompiledContractAddress(*param.ToAddr) {
-			if method, ok, err := vm.GetPrecompiledContract(*param.ToAddr, param.Data); !ok || err != nil {
+		if fork.IsPrecompiledContractAddress(*param.ToAddr, db.CurrentSnapshotBlock().Height) {
+			if method, ok, err := vm.GetPrecompiledContract(*param.ToAddr, param.Data, db.CurrentSnapshotBlock().Height); !ok || err != nil {
 				return "", errors.New("precompiled contract method not exists")
 			} else {
 				quotaRequired = method.GetQuota()
 			}
 		} else {
-			quotaRequired, _ = util.IntrinsicGasCost(param.Data, false)
+			quotaRequired, _ = gasTable.IntrinsicGasCost(param.Data, false)
 		}
 	} else {
 		return "", errors.New("block type not supported")
 	}
 
-	db, err := vm_context.NewVmContext(t.vite.Chain(), &param.SnapshotHash, &param.PrevHash, &param.SelfAddr)
-	if err != nil {
-		return "", err
-	}
 	if param.UsePledgeQuota {
 		pledgeAmount := abi.GetPledgeBeneficialAmount(db, param.SelfAddr)
 		quotaLeft, _, err := quota.CalcQuotaV2(db, param.SelfAddr, pledgeAmount, helper.Big0)
@@ -216,6 +218,9 @@ func (t Tx) CalcPoWDifficulty(param CalcPoWDifficultyParam) (difficulty string,
 	}
 	// TODO optimize part use quota left
 	d := quota.CalcPoWDifficulty(quotaRequired)
+	if d == nil {
+		return "", util.ErrOutOfQuota
+	}
 	return d.String(), nil
 }
 
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+type UtilApi struct {
+	chain     chain.Chain
+	ledgerApi *LedgerApi
+}
+
+func NewUtilApi(vite *vite.Vite) *UtilApi {
+	return &UtilApi{
+		chain:     vite.Chain(),
+		ledgerApi: NewLedgerApi(vite),
+	}
+}
+
+type ProtocolLimits struct {
+	MaxAccountBlockDataSize int    `json:"maxAccountBlockDataSize"`
+	MaxAccountBlockSize     int    `json:"maxAccountBlockSize"`
+	MaxVmLogDataSize        int    `json:"maxVmLogDataSize"`
+	MaxVmLogTopics          int    `json:"maxVmLogTopics"`
+	MaxP2PMessageSize       uint32 `json:"maxP2PMessageSize"`
+}
+
+func (u UtilApi) String() string {
+	return "UtilApi"
+}
+
+// GetProtocolLimits returns the size limits enforced on blocks, vm logs and p2p messages, in bytes.
+func (u UtilApi) GetProtocolLimits() *ProtocolLimits {
+	log.Info("GetProtocolLimits")
+	return &ProtocolLimits{
+		MaxAccountBlockDataSize: ledger.MaxAccountBlockDataSize,
+		MaxAccountBlockSize:     ledger.MaxAccountBlockSize,
+		MaxVmLogDataSize:        ledger.MaxVmLogDataSize,
+		MaxVmLogTopics:          ledger.MaxVmLogTopics,
+		MaxP2PMessageSize:       p2p.MaxPayloadSize,
+	}
+}
+
+type SendCost struct {
+	Quota string `json:"quota"`
+	// PledgeQuota is the quota addr gets by its pledge of PledgeAmount now, PledgeCovers is whether it is enough
+	PledgeAmount string `json:"pledgeAmount"`
+	PledgeQuota  string `json:"pledgeQuota"`
+	PledgeCovers bool   `json:"pledgeCovers"`
+	// Difficulty is the PoW difficulty to get the quota if the pledge doesn't cover it, empty if addr can't
+	// calculate PoW for its next block
+	CanPoW     bool   `json:"canPoW"`
+	Difficulty string `json:"difficulty,omitempty"`
+	// PledgeAmountWithoutPoW is the pledge amount with which addr can send such a transaction in every snapshot
+	// block without PoW, nil if the quota is more than pledge can get
+	PledgeAmountWithoutPoW *string `json:"pledgeAmountWithoutPoW"`
+}
+
+// EstimateSendCost returns the quota of sending a call with data from addr to the address to as the next block of addr,
+// and how addr can get the quota, by its pledge or by PoW.
+func (u *UtilApi) EstimateSendCost(addr types.Address, to types.Address, data []byte) (*SendCost, error) {
+	log.Info("EstimateSendCost")
+	quotaRequired, err := calcSendQuotaRequired(u.chain.GetLatestSnapshotBlock().Height, &to, data)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotHash, err := u.ledgerApi.GetFittestSnapshotHash(&addr, nil)
+	if err != nil {
+		return nil, err
+	}
+	db, err := vm_context.NewVmContext(u.chain, snapshotHash, nil, &addr)
+	if err != nil {
+		return nil, err
+	}
+	pledgeAmount := abi.GetPledgeBeneficialAmount(db, addr)
+	pledgeQuota, _, err := quota.CalcQuotaV2(db, addr, pledgeAmount, helper.Big0)
+	if err != nil && err != util.ErrOutOfQuota {
+		return nil, err
+	}
+
+	cost := &SendCost{
+		Quota:                  uint64ToString(quotaRequired),
+		PledgeAmount:           pledgeAmount.String(),
+		PledgeQuota:            uint64ToString(pledgeQuota),
+		PledgeCovers:           pledgeQuota >= quotaRequired,
+		CanPoW:                 quota.CanPoW(db, addr),
+		PledgeAmountWithoutPoW: bigIntToString(quota.CalcPledgeAmount(quotaRequired)),
+	}
+	if !cost.PledgeCovers && cost.CanPoW {
+		if difficulty := quota.CalcPoWDifficulty(quotaRequired); difficulty != nil {
+			cost.Difficulty = difficulty.String()
+		}
+	}
+	return cost, nil
+}
+
+// calcSendQuotaRequired returns the quota of a send block with data to the address to at the snapshot block height,
+// a nil to is a send block creating a contract
+func calcSendQuotaRequired(height uint64, to *types.Address, data []byte) (uint64, error) {
+	if to != nil && fork.IsPrecompiledContractAddress(*to, height) {
+		method, ok, err := vm.GetPrecompiledContract(*to, data, height)
+		if !ok || err != nil {
+			return 0, errors.New("precompiled contract method not exists")
+		}
+		return method.GetQuota(), nil
+	}
+	return util.GasTableByHeight(height).IntrinsicGasCost(data, to == nil)
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"strings"
+
 	"github.com/vitelabs/go-vite/chain"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
@@ -33,6 +35,12 @@ func (v *VoteApi) GetCancelVoteData(gid types.Gid) ([]byte, error) {
 	return abi.ABIVote.PackMethod(abi.MethodNameCancelVote, gid)
 }
 
+// GetSplitVoteData returns the data splitting the votes across the nodes of nameList, weightList are the shares
+// of the balance in units of 1/10000 summing up to 10000
+func (v *VoteApi) GetSplitVoteData(gid types.Gid, nameList []string, weightList []uint64) ([]byte, error) {
+	return abi.ABIVote.PackMethod(abi.MethodNameSplitVote, gid, strings.Join(nameList, abi.SplitVoteNodeNameSeparator), weightList)
+}
+
 var (
 	NodeStatusActive   uint8 = 1
 	NodeStatusInActive uint8 = 2
@@ -62,3 +70,36 @@ func (v *VoteApi) GetVoteInfo(gid types.Gid, addr types.Address) (*VoteInfo, err
 	}
 	return nil, nil
 }
+
+type SplitVoteInfo struct {
+	Name       string `json:"nodeName"`
+	NodeStatus uint8  `json:"nodeStatus"`
+	Weight     uint64 `json:"weight"`
+	Balance    string `json:"balance"`
+}
+
+// GetSplitVoteInfo returns the votes of addr split across several nodes with the share of the balance counted for
+// each node
+func (v *VoteApi) GetSplitVoteInfo(gid types.Gid, addr types.Address) ([]*SplitVoteInfo, error) {
+	vmContext, err := vm_context.NewVmContext(v.chain, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	voteInfoList := abi.GetSplitVote(vmContext, gid, addr)
+	if len(voteInfoList) == 0 {
+		return nil, nil
+	}
+	balance, err := v.chain.GetAccountBalanceByTokenId(&addr, &ledger.ViteTokenId)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*SplitVoteInfo, len(voteInfoList))
+	for i, voteInfo := range voteInfoList {
+		nodeStatus := NodeStatusInActive
+		if abi.IsActiveRegistration(vmContext, voteInfo.NodeName, gid) {
+			nodeStatus = NodeStatusActive
+		}
+		result[i] = &SplitVoteInfo{voteInfo.NodeName, nodeStatus, voteInfo.Weight, *bigIntToString(voteInfo.WeightedBalance(balance))}
+	}
+	return result, nil
+}
@@ -1,11 +1,35 @@
 package rpcapi
 
 import (
+	"github.com/vitelabs/go-vite/alert"
 	"github.com/vitelabs/go-vite/rpc"
 	"github.com/vitelabs/go-vite/rpcapi/api"
+	"github.com/vitelabs/go-vite/rpcapi/api/filters"
+	"github.com/vitelabs/go-vite/stats"
 	"github.com/vitelabs/go-vite/vite"
 )
 
+var subscribeOptions []filters.Option
+
+// InitSubscribe sets the options of the polling filters of the subscribe api.
+func InitSubscribe(opts ...filters.Option) {
+	subscribeOptions = opts
+}
+
+var alertEngine *alert.Engine
+
+// InitAlert sets the engine managed by the alert api, the api fails if it's nil.
+func InitAlert(engine *alert.Engine) {
+	alertEngine = engine
+}
+
+var statsAggregator *stats.Aggregator
+
+// InitStats sets the aggregator served by the stats api, the api fails if it's nil.
+func InitStats(aggregator *stats.Aggregator) {
+	statsAggregator = aggregator
+}
+
 func Init(dir, lvl string, testApi_prikey, testApi_tti string, netId uint) {
 	api.InitLog(dir, lvl)
 	api.InitTestAPIParams(testApi_prikey, testApi_tti)
@@ -39,6 +63,20 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Public:    true,
 		}
 
+	case "util":
+		return rpc.API{
+			Namespace: "util",
+			Version:   "1.0",
+			Service:   api.NewUtilApi(vite),
+			Public:    true,
+		}
+	case "quota":
+		return rpc.API{
+			Namespace: "quota",
+			Version:   "1.0",
+			Service:   api.NewQuotaApi(vite),
+			Public:    true,
+		}
 	case "ledger":
 		return rpc.API{
 			Namespace: "ledger",
@@ -88,6 +126,34 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewMintageApi(vite),
 			Public:    true,
 		}
+	case "allowance":
+		return rpc.API{
+			Namespace: "allowance",
+			Version:   "1.0",
+			Service:   api.NewAllowanceApi(vite),
+			Public:    true,
+		}
+	case "eventRegistry":
+		return rpc.API{
+			Namespace: "eventRegistry",
+			Version:   "1.0",
+			Service:   api.NewEventRegistryApi(vite),
+			Public:    true,
+		}
+	case "checkpoint":
+		return rpc.API{
+			Namespace: "checkpoint",
+			Version:   "1.0",
+			Service:   api.NewCheckpointApi(vite),
+			Public:    true,
+		}
+	case "dex":
+		return rpc.API{
+			Namespace: "dex",
+			Version:   "1.0",
+			Service:   api.NewDexApi(vite),
+			Public:    true,
+		}
 	case "pledge":
 		return rpc.API{
 			Namespace: "pledge",
@@ -102,6 +168,13 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewConsensusGroupApi(vite),
 			Public:    true,
 		}
+	case "consensus":
+		return rpc.API{
+			Namespace: "consensus",
+			Version:   "1.0",
+			Service:   api.NewConsensusApi(vite),
+			Public:    true,
+		}
 	case "tx":
 		return rpc.API{
 			Namespace: "tx",
@@ -117,6 +190,13 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewTestApi(api.NewWalletApi(vite)),
 			Public:    true,
 		}
+	case "test":
+		return rpc.API{
+			Namespace: "test",
+			Version:   "1.0",
+			Service:   api.NewTestChainApi(vite),
+			Public:    false,
+		}
 	case "debug":
 		return rpc.API{
 			Namespace: "debug",
@@ -131,6 +211,27 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewDashboardApi(vite),
 			Public:    true,
 		}
+	case "subscribe":
+		return rpc.API{
+			Namespace: "subscribe",
+			Version:   "1.0",
+			Service:   filters.NewSubscribeApi(vite, subscribeOptions...),
+			Public:    true,
+		}
+	case "alert":
+		return rpc.API{
+			Namespace: "alert",
+			Version:   "1.0",
+			Service:   api.NewAlertApi(alertEngine),
+			Public:    false,
+		}
+	case "stats":
+		return rpc.API{
+			Namespace: "stats",
+			Version:   "1.0",
+			Service:   api.NewStatsApi(statsAggregator),
+			Public:    true,
+		}
 	case "vmdebug":
 		return rpc.API{
 			Namespace: "vmdebug",
@@ -152,9 +253,9 @@ func GetApis(vite *vite.Vite, apiModule ...string) []rpc.API {
 }
 
 func GetPublicApis(vite *vite.Vite) []rpc.API {
-	return GetApis(vite, "ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard")
+	return GetApis(vite, "ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "dex", "allowance", "eventRegistry", "checkpoint", "consensusGroup", "consensus", "testapi", "pow", "tx", "debug", "dashboard", "subscribe", "stats", "util", "quota")
 }
 
 func GetAllApis(vite *vite.Vite) []rpc.API {
-	return GetApis(vite, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard", "vmdebug")
+	return GetApis(vite, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "dex", "allowance", "eventRegistry", "checkpoint", "consensusGroup", "consensus", "testapi", "test", "pow", "tx", "debug", "dashboard", "subscribe", "stats", "vmdebug", "util", "quota", "alert")
 }
@@ -1,4 +1,266 @@
 package stats
 
 // stats means vite stats
-// including monitor info
\ No newline at end of file
+// including monitor info
+
+import (
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/metrics"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+const (
+	STOP  = 1
+	START = 2
+)
+
+const (
+	defaultWindow = time.Minute
+	activeWindow  = 24 * time.Hour
+	pruneInterval = time.Minute
+
+	// snapshotSamples is the count of the latest snapshot blocks the average interval is taken over
+	snapshotSamples = 100
+)
+
+// ChainStats is the rolling statistics of the chain. Tps and QuotaPerSecond are averaged over the
+// last WindowSeconds, AvgSnapshotInterval is in seconds. DailyActiveAddresses is the count of the
+// accounts producing blocks in the last 24 hours, it's refreshed every minute.
+type ChainStats struct {
+	WindowSeconds        int64   `json:"windowSeconds"`
+	Tps                  float64 `json:"tps"`
+	QuotaPerSecond       float64 `json:"quotaPerSecond"`
+	AvgSnapshotInterval  float64 `json:"avgSnapshotInterval"`
+	DailyActiveAddresses int     `json:"dailyActiveAddresses"`
+	TotalAccountBlocks   uint64  `json:"totalAccountBlocks"`
+	TotalQuota           uint64  `json:"totalQuota"`
+}
+
+type bucket struct {
+	second int64
+	blocks uint64
+	quota  uint64
+}
+
+type snapshotSample struct {
+	height    uint64
+	timestamp int64
+}
+
+// Aggregator keeps the chain statistics up to date from the blocks inserted, so that they are
+// read without polling and diffing the chain. Account blocks are counted when they are inserted
+// into the node, rollbacks of account blocks are not subtracted.
+type Aggregator struct {
+	chain  chain.Chain
+	window time.Duration
+	log    log15.Logger
+
+	lock          sync.Mutex
+	buckets       []bucket
+	snapshots     []snapshotSample
+	activeAddrs   map[types.Address]int64
+	totalBlocks   uint64
+	totalQuota    uint64
+	insertLid     uint64
+	insertSbLid   uint64
+	deleteSbLid   uint64
+	registerGauge sync.Once
+
+	status     int
+	statusLock sync.Mutex
+	ticker     *time.Ticker
+	terminal   chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewAggregator creates an aggregator of c, the rates are averaged over window.
+func NewAggregator(c chain.Chain, window time.Duration) *Aggregator {
+	if window < time.Second {
+		window = defaultWindow
+	}
+	return &Aggregator{
+		chain:       c,
+		window:      window,
+		log:         log15.New("module", "stats"),
+		buckets:     make([]bucket, int(window/time.Second)),
+		activeAddrs: make(map[types.Address]int64),
+		status:      STOP,
+	}
+}
+
+func (a *Aggregator) Start() {
+	a.statusLock.Lock()
+	defer a.statusLock.Unlock()
+	if a.status == START {
+		return
+	}
+
+	a.insertLid = a.chain.RegisterInsertAccountBlocksSuccess(a.insertAccountBlocksSuccess)
+	a.insertSbLid = a.chain.RegisterInsertSnapshotBlocksSuccess(a.insertSnapshotBlocksSuccess)
+	a.deleteSbLid = a.chain.RegisterDeleteSnapshotBlocksSuccess(a.deleteSnapshotBlocksSuccess)
+	a.registerGauge.Do(a.registerGauges)
+
+	a.ticker = time.NewTicker(pruneInterval)
+	a.terminal = make(chan struct{})
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		for {
+			select {
+			case now := <-a.ticker.C:
+				a.pruneActiveAddrs(now)
+			case <-a.terminal:
+				return
+			}
+		}
+	}()
+
+	a.status = START
+	a.log.Info("stats aggregator start")
+}
+
+func (a *Aggregator) Stop() {
+	a.statusLock.Lock()
+	defer a.statusLock.Unlock()
+	if a.status == STOP {
+		return
+	}
+
+	a.chain.UnRegister(a.insertLid)
+	a.chain.UnRegister(a.insertSbLid)
+	a.chain.UnRegister(a.deleteSbLid)
+	a.ticker.Stop()
+	close(a.terminal)
+	a.wg.Wait()
+	a.status = STOP
+	a.log.Info("stats aggregator stop")
+}
+
+// registerGauges exports the statistics to the default metrics registry, they are recorded only
+// if metrics are enabled.
+func (a *Aggregator) registerGauges() {
+	metrics.NewRegisteredFunctionalGaugeFloat64("stats/tps", nil, func() float64 {
+		return a.Stats().Tps
+	})
+	metrics.NewRegisteredFunctionalGaugeFloat64("stats/quotaPerSecond", nil, func() float64 {
+		return a.Stats().QuotaPerSecond
+	})
+	metrics.NewRegisteredFunctionalGaugeFloat64("stats/avgSnapshotInterval", nil, func() float64 {
+		return a.Stats().AvgSnapshotInterval
+	})
+	metrics.NewRegisteredFunctionalGauge("stats/dailyActiveAddresses", nil, func() int64 {
+		return int64(a.Stats().DailyActiveAddresses)
+	})
+}
+
+func (a *Aggregator) insertAccountBlocksSuccess(blocks []*vm_context.VmAccountBlock) {
+	accountBlocks := make([]*ledger.AccountBlock, len(blocks))
+	for i, b := range blocks {
+		accountBlocks[i] = b.AccountBlock
+	}
+	a.addAccountBlocks(time.Now(), accountBlocks)
+}
+
+func (a *Aggregator) insertSnapshotBlocksSuccess(blocks []*ledger.SnapshotBlock) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for _, b := range blocks {
+		if b.Timestamp == nil {
+			continue
+		}
+		a.snapshots = append(a.snapshots, snapshotSample{height: b.Height, timestamp: b.Timestamp.Unix()})
+	}
+	if len(a.snapshots) > snapshotSamples {
+		a.snapshots = append(a.snapshots[:0], a.snapshots[len(a.snapshots)-snapshotSamples:]...)
+	}
+}
+
+// deleteSnapshotBlocksSuccess drops the samples of the snapshot blocks rolled back.
+func (a *Aggregator) deleteSnapshotBlocksSuccess(blocks []*ledger.SnapshotBlock) {
+	if len(blocks) == 0 {
+		return
+	}
+	lowest := blocks[0].Height
+	for _, b := range blocks {
+		if b.Height < lowest {
+			lowest = b.Height
+		}
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for i, s := range a.snapshots {
+		if s.height >= lowest {
+			a.snapshots = a.snapshots[:i]
+			break
+		}
+	}
+}
+
+func (a *Aggregator) addAccountBlocks(now time.Time, blocks []*ledger.AccountBlock) {
+	second := now.Unix()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	b := &a.buckets[second%int64(len(a.buckets))]
+	if b.second != second {
+		*b = bucket{second: second}
+	}
+	for _, block := range blocks {
+		b.blocks++
+		b.quota += block.Quota
+		a.totalBlocks++
+		a.totalQuota += block.Quota
+		a.activeAddrs[block.AccountAddress] = second
+	}
+}
+
+func (a *Aggregator) pruneActiveAddrs(now time.Time) {
+	expired := now.Add(-activeWindow).Unix()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for addr, second := range a.activeAddrs {
+		if second <= expired {
+			delete(a.activeAddrs, addr)
+		}
+	}
+}
+
+// Stats returns the current statistics of the chain.
+func (a *Aggregator) Stats() *ChainStats {
+	return a.stats(time.Now())
+}
+
+func (a *Aggregator) stats(now time.Time) *ChainStats {
+	windowSeconds := int64(len(a.buckets))
+	from := now.Unix() - windowSeconds
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	s := &ChainStats{
+		WindowSeconds:        windowSeconds,
+		DailyActiveAddresses: len(a.activeAddrs),
+		TotalAccountBlocks:   a.totalBlocks,
+		TotalQuota:           a.totalQuota,
+	}
+	var blocks, quota uint64
+	for _, b := range a.buckets {
+		if b.second > from {
+			blocks += b.blocks
+			quota += b.quota
+		}
+	}
+	s.Tps = float64(blocks) / float64(windowSeconds)
+	s.QuotaPerSecond = float64(quota) / float64(windowSeconds)
+	if n := len(a.snapshots); n > 1 {
+		s.AvgSnapshotInterval = float64(a.snapshots[n-1].timestamp-a.snapshots[0].timestamp) / float64(n-1)
+	}
+	return s
+}
@@ -0,0 +1,44 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestAggregator_stats(t *testing.T) {
+	a := NewAggregator(nil, 10*time.Second)
+	addrA, _ := types.BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 10})
+	addrB, _ := types.BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 11})
+
+	now := time.Unix(1000, 0)
+	a.addAccountBlocks(now.Add(-20*time.Second), []*ledger.AccountBlock{{AccountAddress: addrA, Quota: 100}})
+	a.addAccountBlocks(now.Add(-time.Second), []*ledger.AccountBlock{{AccountAddress: addrB, Quota: 10}, {AccountAddress: addrB, Quota: 30}})
+
+	var sbs []*ledger.SnapshotBlock
+	for i, offset := range []time.Duration{0, time.Second, 4 * time.Second} {
+		ts := now.Add(offset)
+		sbs = append(sbs, &ledger.SnapshotBlock{Height: uint64(i + 1), Timestamp: &ts})
+	}
+	a.insertSnapshotBlocksSuccess(sbs)
+
+	s := a.stats(now)
+	if s.WindowSeconds != 10 || s.Tps != 0.2 || s.QuotaPerSecond != 4 || s.TotalAccountBlocks != 3 || s.TotalQuota != 140 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if s.DailyActiveAddresses != 2 || s.AvgSnapshotInterval != 2 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	a.deleteSnapshotBlocksSuccess(sbs[1:])
+	if s := a.stats(now); s.AvgSnapshotInterval != 0 {
+		t.Fatalf("unexpected snapshot interval %v", s.AvgSnapshotInterval)
+	}
+
+	a.pruneActiveAddrs(now.Add(activeWindow - 10*time.Second))
+	if s := a.stats(now); s.DailyActiveAddresses != 1 {
+		t.Fatalf("unexpected active addresses %v", s.DailyActiveAddresses)
+	}
+}
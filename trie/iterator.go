@@ -2,117 +2,96 @@ package trie
 
 import (
 	"bytes"
+	"sort"
 )
 
-type middleKeyAndNode struct {
-	key        []byte
-	middleNode *TrieNode
-}
-
-type leafKeyAndNode struct {
-	key      []byte
-	leafNode *TrieNode
+type keyAndNode struct {
+	key  []byte
+	node *TrieNode
 }
 
+// Iterator walks the leaves under prefix in key order
 type Iterator struct {
 	prefix []byte
 	trie   *Trie
 
-	middleNodes []middleKeyAndNode
-	leafNodes   []leafKeyAndNode
+	// the nodes to visit, the last one is visited first
+	nodes []keyAndNode
 }
 
 func NewIterator(trie *Trie, prefix []byte) *Iterator {
-	return &Iterator{
+	iterator := &Iterator{
 		trie:   trie,
 		prefix: prefix,
-		middleNodes: []middleKeyAndNode{{
-			key:        []byte{},
-			middleNode: trie.Root,
-		}},
+	}
 
-		leafNodes: make([]leafKeyAndNode, 0),
+	if root := trie.Root; root != nil {
+		switch root.NodeType() {
+		case TRIE_FULL_NODE, TRIE_SHORT_NODE:
+			iterator.nodes = append(iterator.nodes, keyAndNode{key: []byte{}, node: root})
+		default:
+			// If root is leafNode
+			iterator.push(root.key, root)
+		}
 	}
+	return iterator
 }
 
 func (iterator *Iterator) Next() (key, value []byte, ok bool) {
-	for {
-		if len(iterator.leafNodes) > 0 {
-			node := iterator.leafNodes[0]
-			iterator.leafNodes = iterator.leafNodes[1:]
-
-			returnKey := make([]byte, len(node.key))
-			copy(returnKey, node.key)
-
-			return returnKey, iterator.trie.LeafNodeValue(node.leafNode), true
-		}
-
-		if len(iterator.middleNodes) <= 0 {
-			return nil, nil, false
-		}
-
-		node := iterator.middleNodes[0]
-		if node.middleNode == nil {
-			return nil, nil, false
-		}
-
-		iterator.middleNodes = iterator.middleNodes[1:]
-
-		var keys [][]byte
-		var children []*TrieNode
+	for len(iterator.nodes) > 0 {
+		last := len(iterator.nodes) - 1
+		current := iterator.nodes[last]
+		iterator.nodes = iterator.nodes[:last]
 
-		currentMiddleNode := node.middleNode
-		switch currentMiddleNode.NodeType() {
+		node := current.node
+		switch node.NodeType() {
 		case TRIE_FULL_NODE:
-			if currentMiddleNode.child != nil {
-				keys = append(keys, nil)
-				children = append(children, currentMiddleNode.child)
+			childKeys := make([]int, 0, len(node.children))
+			for childKey := range node.children {
+				childKeys = append(childKeys, int(childKey))
 			}
+			sort.Ints(childKeys)
 
-			for key, childNode := range currentMiddleNode.children {
-				keys = append(keys, []byte{key})
-				children = append(children, childNode)
+			// pushed in reverse order, so the smaller keys are visited first
+			for i := len(childKeys) - 1; i >= 0; i-- {
+				childKey := byte(childKeys[i])
+				iterator.push(joinKey(current.key, []byte{childKey}), node.children[childKey])
+			}
+			// the value of the key itself is ahead of the longer keys
+			if node.child != nil {
+				iterator.push(current.key, node.child)
 			}
 		case TRIE_SHORT_NODE:
-			keys = append(keys, currentMiddleNode.key)
-			children = append(children, currentMiddleNode.child)
+			iterator.push(joinKey(current.key, node.key), node.child)
 		default:
-			// If root is leafNode
-			keys = append(keys, currentMiddleNode.key)
-			children = append(children, currentMiddleNode)
-		}
-
-		for index, key := range keys {
-			child := children[index]
-
-			newKey := make([]byte, len(node.key))
-			copy(newKey, node.key)
-
-			if len(key) > 0 {
-				newKey = append(newKey, key...)
-			}
-
-			if child.NodeType() == TRIE_FULL_NODE ||
-				child.NodeType() == TRIE_SHORT_NODE {
-				if !bytes.HasPrefix(newKey, iterator.prefix) &&
-					!bytes.HasPrefix(iterator.prefix, newKey) {
-					continue
-				}
+			returnKey := make([]byte, len(current.key))
+			copy(returnKey, current.key)
 
-				iterator.middleNodes = append(iterator.middleNodes, middleKeyAndNode{
-					key:        newKey,
-					middleNode: child,
-				})
-			} else {
-				if !bytes.HasPrefix(newKey, iterator.prefix) {
-					continue
-				}
+			return returnKey, iterator.trie.LeafNodeValue(node), true
+		}
+	}
+	return nil, nil, false
+}
 
-				iterator.leafNodes = append(iterator.leafNodes, leafKeyAndNode{
-					key:      newKey,
-					leafNode: child,
-				})
-			}
+func (iterator *Iterator) push(key []byte, node *TrieNode) {
+	if node.NodeType() == TRIE_FULL_NODE ||
+		node.NodeType() == TRIE_SHORT_NODE {
+		if !bytes.HasPrefix(key, iterator.prefix) &&
+			!bytes.HasPrefix(iterator.prefix, key) {
+			return
 		}
+	} else if !bytes.HasPrefix(key, iterator.prefix) {
+		return
 	}
+
+	iterator.nodes = append(iterator.nodes, keyAndNode{
+		key:  key,
+		node: node,
+	})
+}
+
+func joinKey(key, suffix []byte) []byte {
+	newKey := make([]byte, len(key), len(key)+len(suffix))
+	copy(newKey, key)
+	return append(newKey, suffix...)
 }
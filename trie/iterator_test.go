@@ -6,7 +6,7 @@ import (
 )
 
 func TestNewIterator(t *testing.T) {
-	trie, _, close := getTrieOfNewContext()
+	trie, _, close := getTrieOfNewContext(t)
 	defer close()
 
 	var key1 []byte
@@ -86,3 +86,28 @@ func TestNewIterator(t *testing.T) {
 	}
 	fmt.Println()
 }
+
+func TestIterator_Order(t *testing.T) {
+	trie := NewTrie(nil, nil, NewTrieNodePool())
+	keys := []string{"tes", "a", "tesab", "t", "b1", "tesa", "b", "te", "tesb", "tesabcd", "c"}
+	for _, key := range keys {
+		trie.SetValue([]byte(key), []byte("value"+key))
+	}
+
+	check := func(prefix string, expected []string) {
+		iterator := trie.NewIterator([]byte(prefix))
+		for _, e := range expected {
+			key, value, ok := iterator.Next()
+			if !ok || string(key) != e || string(value) != "value"+e {
+				t.Fatalf("prefix %v, expected %v, got %s %s %v", prefix, e, key, value, ok)
+			}
+		}
+		if key, _, ok := iterator.Next(); ok {
+			t.Fatalf("prefix %v, unexpected key %s", prefix, key)
+		}
+	}
+	check("", []string{"a", "b", "b1", "c", "t", "te", "tes", "tesa", "tesab", "tesabcd", "tesb"})
+	check("tes", []string{"tes", "tesa", "tesab", "tesabcd", "tesb"})
+	check("tesab", []string{"tesab", "tesabcd"})
+	check("x", nil)
+}
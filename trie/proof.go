@@ -0,0 +1,117 @@
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto"
+)
+
+var ErrInvalidProof = errors.New("invalid trie proof")
+
+// Prove returns the nodes on the path of key from the root, serialized as they are saved in the db. The value
+// referred to by a hash node is appended after it. The nodes prove the absence of key as well, the path then ends
+// where key leaves the trie.
+func (trie *Trie) Prove(key []byte) ([][]byte, error) {
+	var proof [][]byte
+	node := trie.Root
+	for node != nil {
+		data, err := node.DbSerialize()
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, data)
+
+		switch node.NodeType() {
+		case TRIE_FULL_NODE:
+			if len(key) == 0 {
+				node = node.child
+			} else {
+				node = node.children[key[0]]
+				key = key[1:]
+			}
+		case TRIE_SHORT_NODE:
+			if !bytes.HasPrefix(key, node.key) {
+				return proof, nil
+			}
+			key = key[len(node.key):]
+			node = node.child
+		case TRIE_HASH_NODE:
+			if len(key) > 0 {
+				return proof, nil
+			}
+			value, err := trie.getRefValue(node.value)
+			if err != nil {
+				return nil, err
+			}
+			return append(proof, value), nil
+		default:
+			return proof, nil
+		}
+	}
+	return proof, nil
+}
+
+// VerifyProof checks proof made by Prove against rootHash and returns the value of key, nil if key is absent.
+// A value node is hashed without its node type, so a value of 32 bytes proved by a value node can't be told from the
+// hash of a longer value.
+func VerifyProof(rootHash types.Hash, key []byte, proof [][]byte) ([]byte, error) {
+	expectedHash := rootHash
+	for i := 0; i < len(proof); i++ {
+		node := &TrieNode{}
+		if err := node.DbDeserialize(proof[i]); err != nil {
+			return nil, ErrInvalidProof
+		}
+		if *node.Hash() != expectedHash {
+			return nil, ErrInvalidProof
+		}
+		isLast := i == len(proof)-1
+
+		var next *TrieNode
+		switch node.NodeType() {
+		case TRIE_FULL_NODE:
+			if len(key) == 0 {
+				next = node.child
+			} else {
+				next = node.children[key[0]]
+				key = key[1:]
+			}
+		case TRIE_SHORT_NODE:
+			if bytes.HasPrefix(key, node.key) {
+				key = key[len(node.key):]
+				next = node.child
+			}
+		case TRIE_VALUE_NODE:
+			if !isLast {
+				return nil, ErrInvalidProof
+			}
+			if len(key) > 0 {
+				return nil, nil
+			}
+			return node.value, nil
+		case TRIE_HASH_NODE:
+			if len(key) > 0 {
+				if !isLast {
+					return nil, ErrInvalidProof
+				}
+				return nil, nil
+			}
+			if i+2 != len(proof) || !bytes.Equal(crypto.Hash256(proof[i+1]), node.value) {
+				return nil, ErrInvalidProof
+			}
+			return proof[i+1], nil
+		default:
+			return nil, ErrInvalidProof
+		}
+
+		if next == nil {
+			if !isLast {
+				return nil, ErrInvalidProof
+			}
+			return nil, nil
+		}
+		expectedHash = *next.Hash()
+	}
+	// the proof ends before the leaf of key or the trie is empty
+	return nil, ErrInvalidProof
+}
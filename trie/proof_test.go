@@ -0,0 +1,65 @@
+package trie
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"testing"
+)
+
+func TestTrie_Prove(t *testing.T) {
+	trie := NewTrie(nil, nil, NewTrieNodePool())
+	values := map[string][]byte{
+		"a":     []byte("value of a"),
+		"ab":    []byte("value of ab"),
+		"abc":   bytes.Repeat([]byte("long value of abc"), 4),
+		"abd":   []byte("value of abd"),
+		"b":     bytes.Repeat([]byte("long value of b"), 4),
+		"bcdef": []byte("value of bcdef"),
+	}
+	for key, value := range values {
+		trie.SetValue([]byte(key), value)
+	}
+	rootHash := *trie.Hash()
+
+	for key, value := range values {
+		proof, err := trie.Prove([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := VerifyProof(rootHash, []byte(key), proof)
+		if err != nil || !bytes.Equal(got, value) {
+			t.Fatalf("verify %v, expected %s, got %s, %v", key, value, got, err)
+		}
+		// the proof of a key doesn't prove another value
+		if _, err := VerifyProof(types.DataHash([]byte("other root")), []byte(key), proof); err != ErrInvalidProof {
+			t.Fatalf("verify %v against another root, got %v", key, err)
+		}
+		tampered := make([][]byte, len(proof))
+		copy(tampered, proof)
+		last := append([]byte{}, tampered[len(tampered)-1]...)
+		last[len(last)-1] ^= 1
+		tampered[len(tampered)-1] = last
+		if got, err := VerifyProof(rootHash, []byte(key), tampered); err == nil && bytes.Equal(got, value) {
+			t.Fatalf("tampered proof of %v verified", key)
+		}
+		if len(proof) > 1 {
+			if _, err := VerifyProof(rootHash, []byte(key), proof[:len(proof)-1]); err != ErrInvalidProof {
+				t.Fatalf("truncated proof of %v, got %v", key, err)
+			}
+		}
+	}
+
+	for _, key := range []string{"", "abcd", "ac", "bc", "bcdefg", "c"} {
+		proof, err := trie.Prove([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := VerifyProof(rootHash, []byte(key), proof); err != nil || got != nil {
+			t.Fatalf("verify absent %q, got %s, %v", key, got, err)
+		}
+	}
+
+	if proof, _ := NewTrie(nil, nil, nil).Prove([]byte("a")); len(proof) != 0 {
+		t.Fatal("an empty trie has no proof")
+	}
+}
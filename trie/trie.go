@@ -356,6 +356,84 @@ func (trie *Trie) setValue(node *TrieNode, key []byte, leafNode *TrieNode) *Trie
 	return nil
 }
 
+// DeleteValue removes key from the trie. The nodes left are merged, so the trie is the same as the one the key is
+// never set in.
+func (trie *Trie) DeleteValue(key []byte) {
+	trie.Root = trie.deleteValue(trie.Root, key)
+}
+
+// deleteValue returns node itself if key is not in node, nil if nothing is left in node
+func (trie *Trie) deleteValue(node *TrieNode, key []byte) *TrieNode {
+	if node == nil {
+		return nil
+	}
+
+	switch node.NodeType() {
+	case TRIE_FULL_NODE:
+		newNode := node.Copy(false)
+		if len(key) > 0 {
+			child := node.children[key[0]]
+			if child == nil {
+				return node
+			}
+			newChild := trie.deleteValue(child, key[1:])
+			if newChild == child {
+				return node
+			}
+			if newChild == nil {
+				delete(newNode.children, key[0])
+			} else {
+				newNode.children[key[0]] = newChild
+			}
+		} else {
+			if node.child == nil {
+				return node
+			}
+			trie.deleteUnSavedRefValueMap(node.child)
+			newNode.child = nil
+		}
+
+		// a full node keeps two branches at least
+		if len(newNode.children) == 0 {
+			return newNode.child
+		}
+		if len(newNode.children) == 1 && newNode.child == nil {
+			for char, child := range newNode.children {
+				return trie.joinShortNode([]byte{char}, child)
+			}
+		}
+		return newNode
+	case TRIE_SHORT_NODE:
+		if !bytes.HasPrefix(key, node.key) {
+			return node
+		}
+		newChild := trie.deleteValue(node.child, key[len(node.key):])
+		if newChild == node.child {
+			return node
+		}
+		if newChild == nil {
+			return nil
+		}
+		return trie.joinShortNode(node.key, newChild)
+	default:
+		if len(key) > 0 {
+			return node
+		}
+		trie.deleteUnSavedRefValueMap(node)
+		return nil
+	}
+}
+
+// joinShortNode returns a short node of key followed by child, a short node child is merged into it
+func (trie *Trie) joinShortNode(key []byte, child *TrieNode) *TrieNode {
+	if child.NodeType() != TRIE_SHORT_NODE {
+		return NewShortNode(key, child)
+	}
+	joinedKey := make([]byte, 0, len(key)+len(child.key))
+	joinedKey = append(append(joinedKey, key...), child.key...)
+	return NewShortNode(joinedKey, child.child)
+}
+
 func (trie *Trie) LeafNodeValue(leafNode *TrieNode) []byte {
 	if leafNode == nil {
 		return nil
@@ -380,6 +458,11 @@ func (trie *Trie) GetValue(key []byte) []byte {
 	return trie.LeafNodeValue(leafNode)
 }
 
+// HasKey returns whether key is set in the trie, an empty value counts as set
+func (trie *Trie) HasKey(key []byte) bool {
+	return trie.getLeafNode(trie.Root, key) != nil
+}
+
 func (trie *Trie) NewNodeIterator() *NodeIterator {
 	return NewNodeIterator(trie)
 }
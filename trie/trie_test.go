@@ -5,29 +5,42 @@ import (
 	"fmt"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/vitelabs/go-vite/chain_db/database"
-	"github.com/vitelabs/go-vite/common"
 	"github.com/vitelabs/go-vite/common/types"
+	"io/ioutil"
 	"os"
-	"path/filepath"
 	"strconv"
 	"sync"
 	"testing"
 	"time"
 )
 
-func getTrieOfNewContext() (*Trie, *leveldb.DB, func()) {
-	trieDbFile := filepath.Join(common.GoViteTestDataDir(), "trie")
-	os.RemoveAll(trieDbFile)
+// newTestDb opens a leveldb in a temporary dir, which is removed by the returned close func.
+func newTestDb(t *testing.T) (*leveldb.DB, func()) {
+	dbDir, err := ioutil.TempDir("", "trie_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.NewLevelDb(dbDir)
+	if err != nil {
+		os.RemoveAll(dbDir)
+		t.Fatal(err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dbDir)
+	}
+}
 
-	db, _ := database.NewLevelDb(trieDbFile)
+func getTrieOfNewContext(t *testing.T) (*Trie, *leveldb.DB, func()) {
+	db, close := newTestDb(t)
 
 	pool := NewTrieNodePool()
 
-	return NewTrie(db, nil, pool), db, func() { db.Close() }
+	return NewTrie(db, nil, pool), db, close
 }
 
 func TestSetGetCase1(t *testing.T) {
-	trie, _, close := getTrieOfNewContext()
+	trie, _, close := getTrieOfNewContext(t)
 	defer close()
 
 	key := []byte("tesabcd")
@@ -52,7 +65,7 @@ func TestSetGetCase1(t *testing.T) {
 }
 
 func TestSetGetCase2(t *testing.T) {
-	trie, _, close := getTrieOfNewContext()
+	trie, _, close := getTrieOfNewContext(t)
 	defer close()
 
 	var key1 []byte
@@ -83,8 +96,8 @@ func TestSetGetCase2(t *testing.T) {
 }
 
 func TestNewTrie(t *testing.T) {
-	db, _ := database.NewLevelDb(filepath.Join(common.GoViteTestDataDir(), "trie"))
-	defer db.Close()
+	db, close := newTestDb(t)
+	defer close()
 
 	pool := NewTrieNodePool()
 
@@ -383,8 +396,8 @@ func TestNewTrie(t *testing.T) {
 }
 
 func TestTrieHash(t *testing.T) {
-	db, _ := database.NewLevelDb(filepath.Join(common.GoViteTestDataDir(), "trie"))
-	defer db.Close()
+	db, close := newTestDb(t)
+	defer close()
 
 	pool := NewTrieNodePool()
 	trie := NewTrie(db, nil, pool)
@@ -448,11 +461,8 @@ func TestTrieHash(t *testing.T) {
 }
 
 func TestTrieSaveAndLoadCase1(t *testing.T) {
-	trieDbFile := filepath.Join(common.GoViteTestDataDir(), "trie")
-	os.RemoveAll(trieDbFile)
-
-	db, _ := database.NewLevelDb(trieDbFile)
-	defer db.Close()
+	db, close := newTestDb(t)
+	defer close()
 
 	pool := NewTrieNodePool()
 
@@ -510,11 +520,8 @@ func TestTrieSaveAndLoadCase1(t *testing.T) {
 }
 
 func TestTrieSaveAndLoad(t *testing.T) {
-	trieDbFile := filepath.Join(common.GoViteTestDataDir(), "trie")
-	os.RemoveAll(trieDbFile)
-
-	db, _ := database.NewLevelDb(filepath.Join(common.GoViteTestDataDir(), "trie"))
-	defer db.Close()
+	db, close := newTestDb(t)
+	defer close()
 
 	pool := NewTrieNodePool()
 
@@ -619,8 +626,8 @@ func TestTrieSaveAndLoad(t *testing.T) {
 }
 
 func TestTrieConcurrence(t *testing.T) {
-	db, _ := database.NewLevelDb(filepath.Join(common.GoViteTestDataDir(), "trie"))
-	defer db.Close()
+	db, close := newTestDb(t)
+	defer close()
 
 	pool := NewTrieNodePool()
 
@@ -675,3 +682,69 @@ func TestTrieConcurrence(t *testing.T) {
 	fmt.Printf("%s\n", trie2.GetValue([]byte("tesab")))
 	fmt.Println(trie2.Hash())
 }
+
+func TestTrie_DeleteValue(t *testing.T) {
+	values := map[string][]byte{
+		"":      []byte("value of nil"),
+		"a":     []byte("value of a"),
+		"ab":    []byte("value of ab"),
+		"abc":   bytes.Repeat([]byte("long value of abc"), 4),
+		"abd":   []byte("value of abd"),
+		"abdef": []byte("value of abdef"),
+		"b":     bytes.Repeat([]byte("long value of b"), 4),
+		"bcdef": []byte("value of bcdef"),
+		"bcxyz": []byte("value of bcxyz"),
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	newTrie := func(deleted map[string]bool) *Trie {
+		trie := NewTrie(nil, nil, NewTrieNodePool())
+		for key, value := range values {
+			if !deleted[key] {
+				trie.SetValue([]byte(key), value)
+			}
+		}
+		return trie
+	}
+
+	for i := 0; i < 100; i++ {
+		trie := newTrie(nil)
+		deleted := make(map[string]bool)
+		for _, key := range keys {
+			if (i+len(key))%3 != 0 {
+				trie.DeleteValue([]byte(key))
+				deleted[key] = true
+			}
+		}
+		trie.DeleteValue([]byte("absent"))
+
+		expected := newTrie(deleted)
+		if expected.Root == nil {
+			if trie.Root != nil {
+				t.Fatalf("deleted %v, the trie should be empty", deleted)
+			}
+		} else if trie.Root == nil || *trie.Hash() != *expected.Hash() {
+			t.Fatalf("deleted %v, the trie differs from the one the keys are never set in", deleted)
+		}
+		for key, value := range values {
+			got := trie.GetValue([]byte(key))
+			if deleted[key] && got != nil || !deleted[key] && !bytes.Equal(got, value) {
+				t.Fatalf("deleted %v, unexpected value of %q %v", deleted, key, got)
+			}
+		}
+		if len(trie.unSavedRefValueMap) != len(expected.unSavedRefValueMap) {
+			t.Fatalf("the ref values of the deleted keys should not be saved")
+		}
+	}
+
+	trie := newTrie(nil)
+	for _, key := range keys {
+		trie.DeleteValue([]byte(key))
+	}
+	if trie.Root != nil || len(trie.unSavedRefValueMap) != 0 {
+		t.Fatal("the trie should be empty after all the keys are deleted")
+	}
+}
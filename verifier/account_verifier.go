@@ -151,9 +151,32 @@ func (verifier *AccountVerifier) VerifyforVM(block *ledger.AccountBlock) (blocks
 	if err := verifier.verifyVMResult(block, genResult.BlockGenList[0].AccountBlock); err != nil {
 		return nil, errors.New(ErrVerifyWithVmResultFailed.Error() + "," + err.Error())
 	}
+	for _, genBlock := range genResult.BlockGenList {
+		if err := verifyVmResultSize(genBlock); err != nil {
+			return nil, err
+		}
+	}
 	return genResult.BlockGenList, nil
 }
 
+// verifyVmResultSize checks the vm logs and the storage growth of a generated block against the limits activated at
+// the snapshot block it refers to
+func verifyVmResultSize(genBlock *vm_context.VmAccountBlock) error {
+	sbHeight := genBlock.VmContext.CurrentSnapshotBlock().Height
+	unsavedCache := genBlock.VmContext.UnsavedCache()
+	if fork.IsSizeLimitFork(sbHeight) {
+		if err := unsavedCache.LogList().CheckSize(); err != nil {
+			return err
+		}
+	}
+	if fork.IsStorageSizeLimitFork(sbHeight) {
+		if err := unsavedCache.StorageDelta().CheckSize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // referredBlock' snapshotBlock's sbHeight can't lower than thisBlock
 func (verifier *AccountVerifier) VerifySnapshotOfReferredBlock(thisBlock *ledger.AccountBlock, referredBlock *ledger.AccountBlock) (VerifyResult, error) {
 	thisSnapshotBlock, _ := verifier.chain.GetSnapshotBlockHeadByHash(&thisBlock.SnapshotHash)
@@ -474,6 +497,12 @@ func (verifier *AccountVerifier) verifyDatasIntergrity(block *ledger.AccountBloc
 		return errors.New("block timestamp can't be nil")
 	}
 
+	if fork.IsSizeLimitFork(vite1Height) {
+		if err := block.CheckSize(); err != nil {
+			return err
+		}
+	}
+
 	if block.Amount == nil {
 		block.Amount = big.NewInt(0)
 	} else {
@@ -504,6 +533,15 @@ func (verifier *AccountVerifier) verifyDatasIntergrity(block *ledger.AccountBloc
 		}
 	}
 
+	if block.Salt != nil {
+		if block.BlockType != ledger.BlockTypeSendCreate {
+			return errors.New("block salt can't be anything other than nil except a send create block")
+		}
+		if !fork.IsDeterministicAddressFork(vite1Height) {
+			return errors.New("block salt is not supported before the deterministic address fork")
+		}
+	}
+
 	return nil
 }
 
@@ -10,7 +10,9 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/test_tools"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/crypto"
@@ -19,9 +21,11 @@ import (
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/onroad"
 	"github.com/vitelabs/go-vite/pow"
+	"github.com/vitelabs/go-vite/trie"
 	"github.com/vitelabs/go-vite/vm"
 	"github.com/vitelabs/go-vite/vm/contracts/abi"
 	"github.com/vitelabs/go-vite/vm_context"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
 	"github.com/vitelabs/go-vite/wallet"
 	"os"
 	"path/filepath"
@@ -453,6 +457,73 @@ func TestAccountVerifier_VerifyDataValidity(t *testing.T) {
 	return
 }
 
+func TestAccountVerifier_VerifyDataValidity_SizeLimit(t *testing.T) {
+	forkPoints := fork.GetForkPoints()
+	defer fork.SetForkPoints(&forkPoints)
+	fork.SetForkPoints(&config.ForkPoints{
+		Smart:     &config.ForkPoint{Height: 2},
+		Mint:      &config.ForkPoint{Height: 2},
+		SizeLimit: &config.ForkPoint{Height: 10},
+	})
+
+	ts := time.Now()
+	block := &ledger.AccountBlock{
+		BlockType: ledger.BlockTypeSendCall,
+		Timestamp: &ts,
+		Data:      make([]byte, ledger.MaxAccountBlockDataSize+1),
+	}
+	v := &AccountVerifier{}
+	// the blocks before the fork are accepted as the nodes before the limit did
+	if err := v.verifyDatasIntergrity(block, 9); err != nil {
+		t.Fatalf("the data size should not be limited before the fork, got %v", err)
+	}
+	if err := v.verifyDatasIntergrity(block, 10); err == nil {
+		t.Fatal("the data size should be limited since the fork")
+	}
+}
+
+type sizeLimitVmDatabase struct {
+	test_tools.MockVmDatabase
+	snapshotBlock *ledger.SnapshotBlock
+	unsavedCache  vmctxt_interface.UnsavedCache
+}
+
+func (db *sizeLimitVmDatabase) CurrentSnapshotBlock() *ledger.SnapshotBlock {
+	return db.snapshotBlock
+}
+
+func (db *sizeLimitVmDatabase) UnsavedCache() vmctxt_interface.UnsavedCache {
+	return db.unsavedCache
+}
+
+func TestVerifyVmResultSize(t *testing.T) {
+	forkPoints := fork.GetForkPoints()
+	defer fork.SetForkPoints(&forkPoints)
+	fork.SetForkPoints(&config.ForkPoints{
+		Smart:            &config.ForkPoint{Height: 2},
+		Mint:             &config.ForkPoint{Height: 2},
+		StorageSizeLimit: &config.ForkPoint{Height: 10},
+	})
+
+	unsavedCache := vm_context.NewUnsavedCache(trie.NewTrie(nil, nil, trie.NewTrieNodePool()))
+	unsavedCache.SetStorage([]byte("key"), make([]byte, ledger.MaxStorageSizeDelta))
+	genBlock := func(sbHeight uint64) *vm_context.VmAccountBlock {
+		return &vm_context.VmAccountBlock{
+			VmContext: &sizeLimitVmDatabase{
+				snapshotBlock: &ledger.SnapshotBlock{Height: sbHeight},
+				unsavedCache:  unsavedCache,
+			},
+		}
+	}
+	// the blocks before the fork are accepted as the nodes before the limit did
+	if err := verifyVmResultSize(genBlock(9)); err != nil {
+		t.Fatalf("the storage growth should not be limited before the fork, got %v", err)
+	}
+	if err := verifyVmResultSize(genBlock(10)); err == nil {
+		t.Fatal("the storage growth should be limited since the fork")
+	}
+}
+
 func TestAccountVerifier_VerifySigature(t *testing.T) {
 	var hashString = ""
 	var pubKeyString = "=="
@@ -134,7 +134,7 @@ func (b *broadcaster) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	case NewSnapshotBlockCode:
 		block := new(ledger.SnapshotBlock)
 		if err = block.Deserialize(msg.Payload); err != nil {
-			return err
+			return errDecode(err)
 		}
 
 		sender.SeeBlock(block.Hash)
@@ -170,7 +170,7 @@ func (b *broadcaster) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	case NewAccountBlockCode:
 		block := new(ledger.AccountBlock)
 		if err = block.Deserialize(msg.Payload); err != nil {
-			return err
+			return errDecode(err)
 		}
 
 		sender.SeeBlock(block.Hash)
@@ -0,0 +1,204 @@
+package net
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/p2p"
+)
+
+var errBudgetExhausted = errors.New("error budget exhausted")
+
+// errClass is the kind of a bad message, every class has its own budget
+type errClass int
+
+const (
+	errClassDecode  errClass = iota // the payload can not be deserialized
+	errClassInvalid                 // the message is deserialized but can not be handled, eg. a block fails the verification
+	errClassCount
+)
+
+var errClassNames = [errClassCount]string{"decode", "invalid"}
+
+func (c errClass) String() string {
+	return errClassNames[c]
+}
+
+const (
+	errWindow       = time.Minute
+	errBanDuration  = 10 * time.Minute
+	errBanMax       = 24 * time.Hour
+	errParoleWindow = time.Hour // a peer misbehaving again within it after the ban is banned twice as long
+)
+
+// errBudgets are the errors of each class a peer may make within errWindow
+var errBudgets = [errClassCount]int{
+	errClassDecode:  10,
+	errClassInvalid: 5,
+}
+
+// decodeError marks the error of deserializing a message payload
+type decodeError struct {
+	err error
+}
+
+func (e decodeError) Error() string {
+	return "decode message error: " + e.err.Error()
+}
+
+func errDecode(err error) error {
+	return decodeError{err}
+}
+
+// queryError is the error of handling a query message, which is reported to the goroutine handling the peer
+// to be checked by errPolicy
+type queryError struct {
+	err error
+}
+
+func (e queryError) Error() string {
+	return e.err.Error()
+}
+
+// classifyError returns the class of a handler error, fatal is true if the peer should be disconnected at once
+func classifyError(err error) (class errClass, fatal bool) {
+	switch err.(type) {
+	case decodeError:
+		return errClassDecode, false
+	case p2p.DiscReason:
+		return 0, true
+	}
+	if err == errLegacyProtocolExpired {
+		return 0, true
+	}
+	return errClassInvalid, false
+}
+
+// errBudget counts the errors of a peer in fixed windows. It is a hysteresis: exhausting a budget puts the
+// peer on probation first, and only exhausting a budget again on probation gets it disconnected. The
+// probation is lifted after a window with no more than half of every budget used.
+// It is used by the goroutine reading the messages of the peer only.
+type errBudget struct {
+	start     time.Time
+	counts    [errClassCount]int
+	probation bool
+}
+
+// record counts an error of class at now, and returns false if the peer should be disconnected
+func (b *errBudget) record(class errClass, now time.Time) bool {
+	if now.Sub(b.start) >= errWindow {
+		if b.probation {
+			b.probation = !b.calm()
+		}
+		b.start = now
+		b.counts = [errClassCount]int{}
+	}
+
+	b.counts[class]++
+	if b.counts[class] <= errBudgets[class] {
+		return true
+	}
+	if b.probation {
+		return false
+	}
+
+	b.probation = true
+	b.counts = [errClassCount]int{}
+	return true
+}
+
+// calm reports whether no more than half of every budget is used in the window
+func (b *errBudget) calm() bool {
+	for class, count := range b.counts {
+		if count > errBudgets[class]/2 {
+			return false
+		}
+	}
+	return true
+}
+
+type errBan struct {
+	until    time.Time
+	duration time.Duration
+}
+
+// errPolicy decides whether a peer is disconnected for the errors of its messages, and bans the peers
+// disconnected for a while. A ban is lifted automatically, the peer is on parole for errParoleWindow
+// after it, and banned twice as long if it is disconnected again by then.
+type errPolicy struct {
+	lock sync.Mutex
+	bans map[string]*errBan
+	log  log15.Logger
+}
+
+func newErrPolicy() *errPolicy {
+	return &errPolicy{
+		bans: make(map[string]*errBan),
+		log:  log15.New("module", "net/errPolicy"),
+	}
+}
+
+// check returns nil if err of the message from p is tolerated, or the error p is disconnected for
+func (e *errPolicy) check(p *peer, err error, now time.Time) error {
+	class, fatal := classifyError(err)
+	if fatal {
+		return err
+	}
+
+	if p.budget.record(class, now) {
+		e.log.Warn(fmt.Sprintf("tolerate %s error of peer %s: %v, probation %t", class, p, err, p.budget.probation))
+		return nil
+	}
+
+	e.ban(p.ID(), now)
+	e.log.Error(fmt.Sprintf("disconnect peer %s for %s error: %v", p, class, err))
+	return errBudgetExhausted
+}
+
+func (e *errPolicy) ban(id string, now time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	b, ok := e.bans[id]
+	if ok && now.Before(b.until.Add(errParoleWindow)) {
+		b.duration *= 2
+		if b.duration > errBanMax {
+			b.duration = errBanMax
+		}
+	} else {
+		b = &errBan{duration: errBanDuration}
+		e.bans[id] = b
+	}
+	b.until = now.Add(b.duration)
+}
+
+// banned reports whether the peer id is banned at now, the bans after the parole are removed
+func (e *errPolicy) banned(id string, now time.Time) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	b, ok := e.bans[id]
+	if !ok {
+		return false
+	}
+	if !now.Before(b.until.Add(errParoleWindow)) {
+		delete(e.bans, id)
+		return false
+	}
+	return now.Before(b.until)
+}
+
+// clean removes the bans after the parole, so that the peers seen once are not kept forever
+func (e *errPolicy) clean(now time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	for id, b := range e.bans {
+		if !now.Before(b.until.Add(errParoleWindow)) {
+			delete(e.bans, id)
+		}
+	}
+}
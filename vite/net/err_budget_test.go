@@ -0,0 +1,98 @@
+package net
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/p2p"
+)
+
+func TestClassifyError(t *testing.T) {
+	if class, fatal := classifyError(errDecode(errors.New("bad payload"))); class != errClassDecode || fatal {
+		t.Errorf("unexpected class %s of decode error", class)
+	}
+	if class, fatal := classifyError(errors.New("verify failed")); class != errClassInvalid || fatal {
+		t.Errorf("unexpected class %s of handle error", class)
+	}
+	if _, fatal := classifyError(p2p.DiscQuitting); !fatal {
+		t.Error("disconnect reason should be fatal")
+	}
+	if _, fatal := classifyError(errLegacyProtocolExpired); !fatal {
+		t.Error("legacy protocol expired should be fatal")
+	}
+}
+
+func TestErrBudget(t *testing.T) {
+	var b errBudget
+	now := time.Unix(1e9, 0)
+
+	// the first exhaustion puts the peer on probation
+	for i := 0; i <= errBudgets[errClassInvalid]; i++ {
+		if !b.record(errClassInvalid, now) {
+			t.Fatalf("disconnected at error %d before probation", i)
+		}
+	}
+	if !b.probation {
+		t.Fatal("should be on probation")
+	}
+
+	// a busy window keeps the probation
+	for i := 0; i < errBudgets[errClassDecode]; i++ {
+		b.record(errClassDecode, now)
+	}
+	now = now.Add(errWindow)
+	b.record(errClassDecode, now)
+	if !b.probation {
+		t.Fatal("probation should not be lifted after a busy window")
+	}
+
+	// a calm window lifts it
+	now = now.Add(errWindow)
+	b.record(errClassDecode, now)
+	if b.probation {
+		t.Fatal("probation should be lifted after a calm window")
+	}
+
+	// exhausting on probation disconnects
+	for i := 0; i <= 2*errBudgets[errClassDecode]; i++ {
+		if !b.record(errClassDecode, now) {
+			if !b.probation || i != 2*errBudgets[errClassDecode] {
+				t.Fatalf("disconnected at error %d", i)
+			}
+			return
+		}
+	}
+	t.Fatal("should be disconnected on probation")
+}
+
+func TestErrPolicy_ban(t *testing.T) {
+	e := newErrPolicy()
+	now := time.Unix(1e9, 0)
+
+	e.ban("a", now)
+	if !e.banned("a", now.Add(errBanDuration-time.Second)) || e.banned("b", now) {
+		t.Fatal("a should be banned only")
+	}
+
+	// on parole after the ban, banned twice as long again
+	now = now.Add(errBanDuration)
+	if e.banned("a", now) {
+		t.Fatal("a should be on parole")
+	}
+	e.ban("a", now)
+	if !e.banned("a", now.Add(2*errBanDuration-time.Second)) || e.banned("a", now.Add(2*errBanDuration)) {
+		t.Fatal("a should be banned twice as long")
+	}
+
+	// forgotten after the parole
+	now = now.Add(2*errBanDuration + errParoleWindow)
+	e.clean(now)
+	if len(e.bans) != 0 {
+		t.Fatalf("bans should be cleaned: %d", len(e.bans))
+	}
+	e.ban("a", now)
+	if e.bans["a"].duration != errBanDuration {
+		t.Fatalf("unexpected ban duration %s after parole", e.bans["a"].duration)
+	}
+}
@@ -283,7 +283,7 @@ func (f *fetcher) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	case SnapshotBlocksCode:
 		bs := new(message.SnapshotBlocks)
 		if err = bs.Deserialize(msg.Payload); err != nil {
-			return err
+			return errDecode(err)
 		}
 
 		for _, block := range bs.Blocks {
@@ -301,7 +301,7 @@ func (f *fetcher) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	case AccountBlocksCode:
 		bs := new(message.AccountBlocks)
 		if err = bs.Deserialize(msg.Payload); err != nil {
-			return err
+			return errDecode(err)
 		}
 
 		for _, block := range bs.Blocks {
@@ -41,6 +41,7 @@ type Verifier interface {
 type SnapshotBlockCallback = func(block *ledger.SnapshotBlock, source types.BlockSource)
 type AccountblockCallback = func(addr types.Address, block *ledger.AccountBlock, source types.BlockSource)
 type SyncStateCallback = func(SyncState)
+type PeerEventCallback = func(PeerEvent)
 
 // A BlockSubscriber implementation can be subscribed and Unsubscribed, when got a block, should notify subscribers
 type BlockSubscriber interface {
@@ -65,6 +66,13 @@ type SyncStateSubscriber interface {
 	SyncState() SyncState
 }
 
+type PeerEventSubscriber interface {
+	// SubscribePeerEvent return the subId, always larger than 0, use to unsubscribe
+	SubscribePeerEvent(fn PeerEventCallback) (subId int)
+	// UnsubscribePeerEvent, if subId is 0, then ignore
+	UnsubscribePeerEvent(subId int)
+}
+
 type Subscriber interface {
 	BlockSubscriber
 	SyncStateSubscriber
@@ -101,9 +109,12 @@ type Net interface {
 	Fetcher
 	Broadcaster
 	BlockSubscriber
+	PeerEventSubscriber
 	Protocols() []*p2p.Protocol
 	Start(svr p2p.Server) error
 	Stop()
 	Info() NodeInfo
+	PeerVersions() PeerVersions
+	PeerMetadata() []PeerMetadata
 	AddPlugin(plugin p2p.Plugin)
 }
@@ -6,11 +6,20 @@ import (
 	"github.com/vitelabs/go-vite/vitepb"
 )
 
+// ForkPoint is a fork the node supports, identified by its name and activation height
+type ForkPoint struct {
+	Name   string `json:"name"`
+	Height uint64 `json:"height"`
+}
+
 type HandShake struct {
 	Height  uint64
 	Port    uint16
 	Current types.Hash
 	Genesis types.Hash
+	// Version and Forks are empty if the peer runs an older release
+	Version string
+	Forks   []ForkPoint
 }
 
 func (h *HandShake) Serialize() ([]byte, error) {
@@ -20,6 +29,12 @@ func (h *HandShake) Serialize() ([]byte, error) {
 	pb.Port = uint32(h.Port)
 	pb.Current = h.Current[:]
 	pb.Genesis = h.Genesis[:]
+	pb.Version = h.Version
+
+	pb.Forks = make([]*vitepb.ForkPoint, len(h.Forks))
+	for i, f := range h.Forks {
+		pb.Forks[i] = &vitepb.ForkPoint{Name: f.Name, Height: f.Height}
+	}
 
 	return proto.Marshal(pb)
 }
@@ -36,6 +51,12 @@ func (h *HandShake) Deserialize(data []byte) error {
 	h.Port = uint16(pb.Port)
 	copy(h.Current[:], pb.Current)
 	copy(h.Genesis[:], pb.Genesis)
+	h.Version = pb.Version
+
+	h.Forks = make([]ForkPoint, len(pb.Forks))
+	for i, f := range pb.Forks {
+		h.Forks[i] = ForkPoint{Name: f.Name, Height: f.Height}
+	}
 
 	return nil
 }
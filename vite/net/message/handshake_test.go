@@ -0,0 +1,52 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vitepb"
+)
+
+func TestHandShake_Serialize(t *testing.T) {
+	h := &HandShake{
+		Height:  10,
+		Port:    8484,
+		Current: types.DataHash([]byte("current")),
+		Genesis: types.DataHash([]byte("genesis")),
+		Version: "v1.3.1",
+		Forks:   []ForkPoint{{"Smart", 5788912}, {"Mint", 9453262}},
+	}
+
+	data, err := h.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h2 := new(HandShake)
+	if err = h2.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+	if h2.Height != h.Height || h2.Port != h.Port || h2.Current != h.Current || h2.Genesis != h.Genesis || h2.Version != h.Version {
+		t.Fatalf("unexpected handshake %+v", h2)
+	}
+	if len(h2.Forks) != 2 || h2.Forks[0] != h.Forks[0] || h2.Forks[1] != h.Forks[1] {
+		t.Fatalf("unexpected forks %+v", h2.Forks)
+	}
+}
+
+// the handshake of older releases has no version and forks
+func TestHandShake_DeserializeOld(t *testing.T) {
+	data, err := proto.Marshal(&vitepb.Handshake{Height: 10, Port: 8484})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := new(HandShake)
+	if err = h.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+	if h.Height != 10 || h.Version != "" || len(h.Forks) != 0 {
+		t.Fatalf("unexpected handshake %+v", h)
+	}
+}
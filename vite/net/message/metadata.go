@@ -0,0 +1,117 @@
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+)
+
+// MaxMetadataFieldLength is the max length in bytes of the operator, contact and region of a NodeMetadata
+const MaxMetadataFieldLength = 64
+
+var errMetadataTooLong = fmt.Errorf("node metadata field longer than %d bytes", MaxMetadataFieldLength)
+
+// Services is the bitmask of the services a node provides besides the vite protocol.
+type Services uint32
+
+const (
+	ServiceArchive     Services = 1 << iota // keeps the full history of the ledger
+	ServiceLightServer                      // serves light clients
+)
+
+var serviceNames = []struct {
+	service Services
+	name    string
+}{
+	{ServiceArchive, "archive"},
+	{ServiceLightServer, "light-server"},
+}
+
+// Names returns the names of the known services in s.
+func (s Services) Names() []string {
+	names := make([]string, 0, len(serviceNames))
+	for _, sn := range serviceNames {
+		if s&sn.service != 0 {
+			names = append(names, sn.name)
+		}
+	}
+	return names
+}
+
+func (s Services) String() string {
+	return strings.Join(s.Names(), ",")
+}
+
+// ParseServices parses the service names like "archive" and "light-server".
+func ParseServices(names []string) (s Services, err error) {
+loop:
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		for _, sn := range serviceNames {
+			if sn.name == name {
+				s |= sn.service
+				continue loop
+			}
+		}
+		return 0, errors.New("unknown node service " + name)
+	}
+	return s, nil
+}
+
+// NodeMetadata is the information the operator of a node publishes with the node endpoint, so that
+// light clients and explorers can select the peers. It is signed by the node key apart from the endpoint.
+type NodeMetadata struct {
+	Operator string
+	Contact  string
+	Region   string
+	Services Services
+}
+
+// Check returns an error if a field of m is too long.
+func (m *NodeMetadata) Check() error {
+	if len(m.Operator) > MaxMetadataFieldLength || len(m.Contact) > MaxMetadataFieldLength || len(m.Region) > MaxMetadataFieldLength {
+		return errMetadataTooLong
+	}
+	return nil
+}
+
+// IsEmpty reports whether m carries nothing.
+func (m *NodeMetadata) IsEmpty() bool {
+	return *m == NodeMetadata{}
+}
+
+// metadataSignPayload binds m to the node id and the timestamp of the endpoint, so that it can not be replayed
+// with an older endpoint
+func metadataSignPayload(id [32]byte, timestamp int64, m *NodeMetadata) []byte {
+	payload := make([]byte, 0, 32+8+3*(1+MaxMetadataFieldLength)+4)
+	payload = append(payload, id[:]...)
+
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(timestamp))
+	payload = append(payload, buf...)
+
+	for _, field := range []string{m.Operator, m.Contact, m.Region} {
+		payload = append(payload, byte(len(field)))
+		payload = append(payload, field...)
+	}
+
+	binary.BigEndian.PutUint32(buf, uint32(m.Services))
+	return append(payload, buf[:4]...)
+}
+
+func signMetadata(priv ed25519.PrivateKey, id [32]byte, timestamp int64, m *NodeMetadata) []byte {
+	return ed25519.Sign(priv, metadataSignPayload(id, timestamp, m))
+}
+
+func verifyMetadata(id [32]byte, timestamp int64, m *NodeMetadata, signature []byte) bool {
+	if m.Check() != nil {
+		return false
+	}
+	return ed25519.Verify(ed25519.PublicKey(id[:]), metadataSignPayload(id, timestamp, m), signature)
+}
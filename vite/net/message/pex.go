@@ -0,0 +1,150 @@
+package message
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/vitepb"
+)
+
+var errInvalidEndpointID = errors.New("invalid peer endpoint id")
+
+// PeerEndpoint is the listening endpoint of a node signed by the node itself, so that it can be
+// relayed by other peers without being forged. IP is empty if the node doesn't know its public ip,
+// then ObservedIP is the ip the relaying peer sees the node from, which is not signed.
+// Metadata is signed apart, by MetadataSignature, so that the nodes not knowing it still verify the endpoint.
+type PeerEndpoint struct {
+	ID                [32]byte
+	IP                net.IP
+	Port              uint16
+	Timestamp         int64
+	Signature         []byte
+	ObservedIP        net.IP
+	Metadata          *NodeMetadata
+	MetadataSignature []byte
+}
+
+func (e *PeerEndpoint) signPayload() []byte {
+	payload := make([]byte, 0, 32+net.IPv6len+2+8)
+	payload = append(payload, e.ID[:]...)
+	if len(e.IP) > 0 {
+		payload = append(payload, e.IP.To16()...)
+	}
+	payload = append(payload, byte(e.Port>>8), byte(e.Port))
+
+	ts := make([]byte, 8)
+	binary.BigEndian.PutUint64(ts, uint64(e.Timestamp))
+	return append(payload, ts...)
+}
+
+// Sign signs the endpoint and its metadata if any with the private key of the node, whose public key must be ID.
+func (e *PeerEndpoint) Sign(priv ed25519.PrivateKey) {
+	e.Signature = ed25519.Sign(priv, e.signPayload())
+	if e.Metadata != nil {
+		e.MetadataSignature = signMetadata(priv, e.ID, e.Timestamp, e.Metadata)
+	}
+}
+
+// Verify reports whether the endpoint is signed by the node ID.
+func (e *PeerEndpoint) Verify() bool {
+	return ed25519.Verify(ed25519.PublicKey(e.ID[:]), e.signPayload(), e.Signature)
+}
+
+// VerifyMetadata reports whether the endpoint carries metadata signed by the node ID.
+func (e *PeerEndpoint) VerifyMetadata() bool {
+	if e.Metadata == nil {
+		return false
+	}
+	return verifyMetadata(e.ID, e.Timestamp, e.Metadata, e.MetadataSignature)
+}
+
+// Addr returns the address to dial the node, it is nil if neither IP nor ObservedIP is known.
+func (e *PeerEndpoint) Addr() *net.TCPAddr {
+	ip := e.IP
+	if len(ip) == 0 || ip.IsUnspecified() {
+		ip = e.ObservedIP
+	}
+	if len(ip) == 0 || ip.IsUnspecified() {
+		return nil
+	}
+	return &net.TCPAddr{IP: ip, Port: int(e.Port)}
+}
+
+func (e *PeerEndpoint) proto() *vitepb.PeerEndpoint {
+	pb := &vitepb.PeerEndpoint{
+		ID:         e.ID[:],
+		IP:         e.IP,
+		Port:       uint32(e.Port),
+		Timestamp:  e.Timestamp,
+		Signature:  e.Signature,
+		ObservedIP: e.ObservedIP,
+	}
+	if e.Metadata != nil {
+		pb.Operator = e.Metadata.Operator
+		pb.Contact = e.Metadata.Contact
+		pb.Region = e.Metadata.Region
+		pb.Services = uint32(e.Metadata.Services)
+		pb.MetadataSignature = e.MetadataSignature
+	}
+	return pb
+}
+
+func (e *PeerEndpoint) deProto(pb *vitepb.PeerEndpoint) error {
+	if len(pb.ID) != len(e.ID) {
+		return errInvalidEndpointID
+	}
+	copy(e.ID[:], pb.ID)
+	e.IP = pb.IP
+	e.Port = uint16(pb.Port)
+	e.Timestamp = pb.Timestamp
+	e.Signature = pb.Signature
+	e.ObservedIP = pb.ObservedIP
+	if len(pb.MetadataSignature) > 0 {
+		e.Metadata = &NodeMetadata{
+			Operator: pb.Operator,
+			Contact:  pb.Contact,
+			Region:   pb.Region,
+			Services: Services(pb.Services),
+		}
+		e.MetadataSignature = pb.MetadataSignature
+	}
+	return nil
+}
+
+// PeerExchange carries a sample of the endpoints of the peers connected to the sender, the first
+// endpoint is the sender's own.
+type PeerExchange struct {
+	Endpoints []*PeerEndpoint
+}
+
+func (p *PeerExchange) Serialize() ([]byte, error) {
+	pb := new(vitepb.PeerExchange)
+
+	pb.Endpoints = make([]*vitepb.PeerEndpoint, len(p.Endpoints))
+	for i, e := range p.Endpoints {
+		pb.Endpoints[i] = e.proto()
+	}
+
+	return proto.Marshal(pb)
+}
+
+func (p *PeerExchange) Deserialize(buf []byte) error {
+	pb := new(vitepb.PeerExchange)
+	err := proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	p.Endpoints = make([]*PeerEndpoint, len(pb.Endpoints))
+	for i, e := range pb.Endpoints {
+		p.Endpoints[i] = new(PeerEndpoint)
+		if err = p.Endpoints[i].deProto(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
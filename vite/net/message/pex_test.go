@@ -0,0 +1,102 @@
+package message
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+)
+
+func TestPeerExchange_Serialize(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &PeerEndpoint{Port: 8483, Timestamp: 1550000000}
+	copy(e.ID[:], pub)
+	e.Sign(priv)
+	e.ObservedIP = net.ParseIP("10.0.0.1")
+
+	data, err := (&PeerExchange{Endpoints: []*PeerEndpoint{e}}).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := new(PeerExchange)
+	if err = p.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Endpoints) != 1 || !p.Endpoints[0].Verify() {
+		t.Fatal("endpoint should be verified")
+	}
+	if addr := p.Endpoints[0].Addr(); addr == nil || addr.String() != "10.0.0.1:8483" {
+		t.Fatalf("unexpected addr %v", addr)
+	}
+
+	// the observed ip is not signed, but the port is
+	p.Endpoints[0].Port = 8484
+	if p.Endpoints[0].Verify() {
+		t.Fatal("tampered endpoint should not be verified")
+	}
+}
+
+func TestPeerEndpoint_Metadata(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := &PeerEndpoint{Port: 8483, Timestamp: 1550000000}
+	copy(e.ID[:], pub)
+	e.Metadata = &NodeMetadata{Operator: "vite", Contact: "ops@vite.org", Region: "eu", Services: ServiceArchive | ServiceLightServer}
+	e.Sign(priv)
+
+	data, err := (&PeerExchange{Endpoints: []*PeerEndpoint{e}}).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := new(PeerExchange)
+	if err = p.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+	r := p.Endpoints[0]
+	if !r.Verify() || !r.VerifyMetadata() || *r.Metadata != *e.Metadata {
+		t.Fatalf("unexpected metadata %+v", r.Metadata)
+	}
+
+	// the metadata is bound to the timestamp of the endpoint
+	r.Timestamp++
+	if r.VerifyMetadata() {
+		t.Fatal("replayed metadata should not be verified")
+	}
+	r.Timestamp--
+	r.Metadata.Region = "us"
+	if r.VerifyMetadata() {
+		t.Fatal("tampered metadata should not be verified")
+	}
+
+	// an endpoint without metadata
+	e.Metadata, e.MetadataSignature = nil, nil
+	e.Sign(priv)
+	data, _ = (&PeerExchange{Endpoints: []*PeerEndpoint{e}}).Serialize()
+	if err = p.Deserialize(data); err != nil {
+		t.Fatal(err)
+	}
+	if !p.Endpoints[0].Verify() || p.Endpoints[0].Metadata != nil || p.Endpoints[0].VerifyMetadata() {
+		t.Fatal("endpoint should have no metadata")
+	}
+}
+
+func TestParseServices(t *testing.T) {
+	s, err := ParseServices([]string{"archive", " light-server", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != ServiceArchive|ServiceLightServer || s.String() != "archive,light-server" {
+		t.Fatalf("unexpected services %v", s)
+	}
+	if _, err = ParseServices([]string{"miner"}); err == nil {
+		t.Fatal("unknown service should fail")
+	}
+}
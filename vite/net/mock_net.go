@@ -15,6 +15,7 @@ type mockNet struct {
 	*broadcaster
 	chain Chain
 	BlockSubscriber
+	*peerFeed
 }
 
 func (n *mockNet) AddPlugin(plugin p2p.Plugin) {
@@ -24,6 +25,14 @@ func (n *mockNet) Info() NodeInfo {
 	return NodeInfo{}
 }
 
+func (n *mockNet) PeerVersions() PeerVersions {
+	return PeerVersions{}
+}
+
+func (n *mockNet) PeerMetadata() []PeerMetadata {
+	return nil
+}
+
 func (n *mockNet) Protocols() []*p2p.Protocol {
 	return nil
 }
@@ -89,5 +98,6 @@ func mock(cfg *Config) Net {
 			log:      log15.New("module", "mocknet/broadcaster"),
 		},
 		BlockSubscriber: feed,
+		peerFeed:        newPeerFeed(),
 	}
 }
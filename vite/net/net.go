@@ -6,7 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/vitelabs/go-vite"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/profile"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/monitor"
@@ -21,6 +23,11 @@ type Config struct {
 	FileAddress string
 	Chain       Chain
 	Verifier    Verifier
+	// LegacyDeadline ends the transition window, the legacy versions of the vite protocol are served
+	// until then, a zero LegacyDeadline means no deadline
+	LegacyDeadline time.Time
+	// Metadata is published with the endpoint of the node to the peers, nil if none
+	Metadata *message.NodeMetadata
 }
 
 const DefaultPort uint16 = 8484
@@ -41,6 +48,10 @@ type net struct {
 	fs        *fileServer
 	handlers  map[ViteCmd]MsgHandler
 	plugins   []p2p.Plugin
+	forks     *forkWarner
+	pex       *pex
+	errPolicy *errPolicy
+	*peerFeed
 }
 
 func New(cfg *Config) Net {
@@ -74,6 +85,10 @@ func New(cfg *Config) Net {
 		fs:              newFileServer(cfg.FileAddress, cfg.Chain),
 		handlers:        make(map[ViteCmd]MsgHandler),
 		log:             netLog,
+		forks:           newForkWarner(),
+		peerFeed:        newPeerFeed(),
+		pex:             newPex(peers, cfg.Metadata),
+		errPolicy:       newErrPolicy(),
 	}
 
 	n.addHandler(_statusHandler(statusHandler))
@@ -82,16 +97,9 @@ func New(cfg *Config) Net {
 	n.addHandler(syncer)      // FileListCode, SubLedgerCode
 	n.addHandler(broadcaster) // NewSnapshotBlockCode, NewAccountBlockCode
 	n.addHandler(fetcher)     // SnapshotBlocksCode, AccountBlocksCode
+	n.addHandler(n.pex)       // PeerExchangeCode
 
-	n.protocols = append(n.protocols, &p2p.Protocol{
-		Name: Vite,
-		ID:   CmdSet,
-		Handle: func(p *p2p.Peer, rw *p2p.ProtoFrame) error {
-			// will be called by p2p.Peer.runProtocols use goroutine
-			peer := newPeer(p, rw, CmdSet)
-			return n.handlePeer(peer)
-		},
-	})
+	n.mountProtocols()
 
 	return n
 }
@@ -130,6 +138,10 @@ func (n *net) Start(svr p2p.Server) (err error) {
 		return
 	}
 
+	if err = n.pex.start(svr); err != nil {
+		return
+	}
+
 	n.wg.Add(1)
 	common.Go(n.heartbeat)
 
@@ -158,12 +170,19 @@ func (n *net) Stop() {
 
 		n.fetcher.stop()
 
+		n.pex.stop()
+
 		n.wg.Wait()
 	}
 }
 
 // will be called by p2p.server, run as goroutine
 func (n *net) handlePeer(p *peer) (err error) {
+	if n.errPolicy.banned(p.ID(), time.Now()) {
+		n.log.Warn(fmt.Sprintf("refuse banned peer %s", p))
+		return p2p.DiscProtocolError
+	}
+
 	current := n.Chain.GetLatestSnapshotBlock()
 	genesis := n.Chain.GetGenesisSnapshotBlock()
 
@@ -187,6 +206,8 @@ func (n *net) handlePeer(p *peer) (err error) {
 		Port:    port,
 		Current: current.Hash,
 		Genesis: genesis.Hash,
+		Version: govite.VITE_BUILD_VERSION,
+		Forks:   localForks(),
 	})
 
 	if err != nil {
@@ -204,9 +225,25 @@ func (n *net) startPeer(p *peer) (err error) {
 		return err
 	}
 
-	defer n.peers.Del(p)
+	n.peerFeed.notify(PeerEvent{Added: true, Peer: p.Info(), Count: n.peers.Count()})
+
+	defer func() {
+		n.peers.Del(p)
+		e := PeerEvent{Peer: p.Info(), Count: n.peers.Count()}
+		if err != nil {
+			e.Reason = err.Error()
+		}
+		n.peerFeed.notify(e)
+	}()
+
+	n.forks.check(n.PeerVersions())
+
+	// tell the new peer our endpoint and the ones of the other peers without waiting for the next round
+	common.Go(func() {
+		n.pex.exchange(p)
+	})
 
-	common.Go(n.syncer.Start)
+	profile.Go(profile.ModuleSync, n.syncer.Start)
 
 loop:
 	for {
@@ -216,6 +253,9 @@ loop:
 			break loop
 
 		case err = <-p.errChan:
+			if qe, ok := err.(queryError); ok {
+				err = n.errPolicy.check(p, qe.err, time.Now())
+			}
 			if err != nil {
 				p.log.Error(fmt.Sprintf("peer %s error: %v", p.RemoteAddr(), err))
 				break loop
@@ -244,7 +284,9 @@ func (n *net) heartbeat() {
 		case <-n.term:
 			return
 
-		case <-ticker.C:
+		case now := <-ticker.C:
+			n.errPolicy.clean(now)
+
 			l := n.peers.Peers()
 			if len(l) == 0 {
 				break
@@ -278,6 +320,15 @@ func (n *net) handleMsg(p *peer) (err error) {
 		return
 	}
 
+	if p.shim != nil {
+		if n.legacyExpired(time.Now()) {
+			return errLegacyProtocolExpired
+		}
+		if !p.shim.in(msg) {
+			return nil
+		}
+	}
+
 	code := ViteCmd(msg.Cmd)
 
 	// before syncDone, ignore GetAccountBlocksCode
@@ -289,7 +340,10 @@ func (n *net) handleMsg(p *peer) (err error) {
 	}
 
 	if handler, ok := n.handlers[code]; ok && handler != nil {
-		return handler.Handle(msg, p)
+		if err = handler.Handle(msg, p); err != nil {
+			// a single bad message does not disconnect the peer until its error budget runs out
+			return n.errPolicy.check(p, err, time.Now())
+		}
 	}
 
 	return nil
@@ -315,6 +369,16 @@ func (n *net) Info() NodeInfo {
 	}
 }
 
+// PeerVersions returns the statistics of the versions and forks advertised by the peers
+func (n *net) PeerVersions() PeerVersions {
+	return peerVersions(n.peers.Info(), localForks())
+}
+
+// PeerMetadata returns the metadata the operators of the other nodes signed and gossiped
+func (n *net) PeerMetadata() []PeerMetadata {
+	return n.pex.peerMetadata()
+}
+
 type NodeInfo struct {
 	PeerCount int           `json:"peerCount"`
 	Peers     []PeerInfo    `json:"peers"`
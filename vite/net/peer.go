@@ -46,10 +46,13 @@ type peer struct {
 	head        types.Hash // hash of the top snapshotblock in snapshotchain
 	height      uint64     // height of the snapshotchain
 	filePort    uint16     // fileServer port, for request file
+	version     string     // release version, empty if the peer runs an older release
+	forks       []message.ForkPoint
 	CmdSet      p2p.CmdSet // which cmdSet it belongs
+	shim        msgShim    // translates the messages if CmdSet is a legacy version
 	knownBlocks blockFilter
 	errChan     chan error
-	once        sync.Once
+	budget      errBudget // errors of the messages from the peer, see errPolicy
 
 	log log15.Logger
 }
@@ -78,10 +81,12 @@ func newPeer(p *p2p.Peer, mrw *p2p.ProtoFrame, cmdSet p2p.CmdSet) *peer {
 	}
 }
 
+// Report passes err to the goroutine handling the peer, the errors reported while one is pending are dropped
 func (p *peer) Report(err error) {
-	p.once.Do(func() {
-		p.errChan <- err
-	})
+	select {
+	case p.errChan <- err:
+	default:
+	}
 }
 
 func (p *peer) FileAddress() *net2.TCPAddr {
@@ -111,6 +116,8 @@ func (p *peer) Handshake(our *message.HandShake) error {
 	}
 
 	p.SetHead(their.Current, their.Height)
+	p.version = their.Version
+	p.forks = their.Forks
 	p.filePort = their.Port
 	if p.filePort == 0 {
 		p.filePort = DefaultPort
@@ -178,23 +185,34 @@ func (p *peer) Send(code ViteCmd, msgId uint64, payload p2p.Serializable) (err e
 
 	if msg, err = p2p.PackMsg(p.CmdSet, p2p.Cmd(code), msgId, payload); err != nil {
 		return err
-	} else if err = p.mrw.WriteMsg(msg); err != nil {
-		return err
 	}
 
-	return nil
+	return p.SendMsg(msg)
 }
 
 func (p *peer) SendMsg(msg *p2p.Msg) (err error) {
+	if p.shim != nil && msg.CmdSet != p.CmdSet {
+		// msg may be packed for the current version and shared by peers
+		m := *msg
+		m.CmdSet = p.CmdSet
+		msg = &m
+	}
+	if p.shim != nil && !p.shim.out(msg) {
+		return nil
+	}
 	return p.mrw.WriteMsg(msg)
 }
 
 type PeerInfo struct {
-	ID      string `json:"id"`
-	Addr    string `json:"addr"`
-	Head    string `json:"head"`
-	Height  uint64 `json:"height"`
-	Created string `json:"created"`
+	ID      string              `json:"id"`
+	Addr    string              `json:"addr"`
+	Head    string              `json:"head"`
+	Height  uint64              `json:"height"`
+	Created string              `json:"created"`
+	Version string              `json:"version"`
+	Forks   []message.ForkPoint `json:"forks"`
+	// Protocol is the version of the vite protocol the peer speaks
+	Protocol p2p.CmdSet `json:"protocol"`
 }
 
 func (p *PeerInfo) String() string {
@@ -203,11 +221,14 @@ func (p *PeerInfo) String() string {
 
 func (p *peer) Info() PeerInfo {
 	return PeerInfo{
-		ID:      p.id,
-		Addr:    p.RemoteAddr().String(),
-		Head:    p.head.String(),
-		Height:  p.height,
-		Created: p.Created.Format("2006-01-02 15:04:05"),
+		ID:       p.id,
+		Addr:     p.RemoteAddr().String(),
+		Head:     p.head.String(),
+		Height:   p.height,
+		Created:  p.Created.Format("2006-01-02 15:04:05"),
+		Version:  p.version,
+		Forks:    p.forks,
+		Protocol: p.CmdSet,
 	}
 }
 
@@ -0,0 +1,57 @@
+package net
+
+import (
+	"sync"
+)
+
+// PeerEvent is notified when a peer is added or dropped, Count is the number of peers after the event,
+// Reason is the error the peer is dropped for.
+type PeerEvent struct {
+	Added  bool
+	Peer   PeerInfo
+	Count  int
+	Reason string
+}
+
+type peerFeed struct {
+	mu        sync.RWMutex
+	subs      map[int]PeerEventCallback
+	currentId int
+}
+
+func newPeerFeed() *peerFeed {
+	return &peerFeed{
+		subs: make(map[int]PeerEventCallback),
+	}
+}
+
+func (pf *peerFeed) SubscribePeerEvent(fn PeerEventCallback) (subId int) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	pf.currentId++
+	pf.subs[pf.currentId] = fn
+	return pf.currentId
+}
+
+func (pf *peerFeed) UnsubscribePeerEvent(subId int) {
+	if subId <= 0 {
+		return
+	}
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	delete(pf.subs, subId)
+}
+
+func (pf *peerFeed) notify(e PeerEvent) {
+	pf.mu.RLock()
+	defer pf.mu.RUnlock()
+
+	for _, fn := range pf.subs {
+		if fn != nil {
+			fn(e)
+		}
+	}
+}
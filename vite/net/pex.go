@@ -0,0 +1,319 @@
+package net
+
+import (
+	"fmt"
+	"math/rand"
+	net2 "net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/p2p/discovery"
+	"github.com/vitelabs/go-vite/vite/net/message"
+)
+
+const (
+	pexInterval     = 2 * time.Minute
+	pexSampleSize   = 16
+	pexMaxDials     = 4
+	pexExpiration   = 24 * time.Hour
+	pexRedialPeriod = 10 * time.Minute
+	pexMaxMetadata  = 1000
+)
+
+// dialer is the part of p2p.Server pex dials the endpoints it learns with.
+type dialer interface {
+	Connect(id discovery.NodeID, addr *net2.TCPAddr)
+}
+
+// PeerMetadata is the metadata an operator signed with the endpoint of its node.
+type PeerMetadata struct {
+	ID       string   `json:"id"`
+	Operator string   `json:"operator,omitempty"`
+	Contact  string   `json:"contact,omitempty"`
+	Region   string   `json:"region,omitempty"`
+	Services []string `json:"services"`
+	// Timestamp is the unix time the metadata was signed at
+	Timestamp int64 `json:"timestamp"`
+	Connected bool  `json:"connected"`
+}
+
+// pex exchanges the endpoints of the connected peers, so that the mesh becomes dense without
+// relying only on discovery and bootnodes. Every endpoint is signed by the node it belongs to,
+// only the endpoints a peer signed itself are kept and relayed, the others are dialed.
+// The metadata signed with the endpoints are kept until expired, whether the nodes are connected or not.
+type pex struct {
+	peers    *peerSet
+	dialer   dialer
+	maxPeers int
+	priv     ed25519.PrivateKey
+	self     *message.PeerEndpoint
+	metadata *message.NodeMetadata // metadata of our own, nil if none
+
+	lock    sync.Mutex
+	records map[string]*message.PeerEndpoint // own endpoints of the connected peers, keyed by peer id
+	dialed  map[discovery.NodeID]time.Time
+	metas   map[discovery.NodeID]*message.PeerEndpoint // the latest endpoints with verified metadata
+
+	term chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newPex(peers *peerSet, metadata *message.NodeMetadata) *pex {
+	if metadata != nil && metadata.IsEmpty() {
+		metadata = nil
+	}
+	return &pex{
+		peers:    peers,
+		metadata: metadata,
+		records:  make(map[string]*message.PeerEndpoint),
+		dialed:   make(map[discovery.NodeID]time.Time),
+		metas:    make(map[discovery.NodeID]*message.PeerEndpoint),
+	}
+}
+
+func (x *pex) ID() string {
+	return "pex handler"
+}
+
+func (x *pex) Cmds() []ViteCmd {
+	return []ViteCmd{PeerExchangeCode}
+}
+
+func (x *pex) start(svr p2p.Server) error {
+	cfg := svr.Config()
+	id, err := discovery.Priv2NodeID(cfg.PeerKey)
+	if err != nil {
+		return err
+	}
+
+	addr := svr.NodeInfo().Address
+	x.self = &message.PeerEndpoint{ID: id, Port: addr.TCP, Metadata: x.metadata}
+	if !addr.IP.IsUnspecified() {
+		x.self.IP = addr.IP
+	}
+	x.priv = cfg.PeerKey
+	x.dialer = svr
+	x.maxPeers = int(cfg.MaxPeers)
+
+	x.term = make(chan struct{})
+	x.wg.Add(1)
+	common.Go(x.loop)
+
+	return nil
+}
+
+func (x *pex) stop() {
+	if x.term == nil {
+		return
+	}
+
+	select {
+	case <-x.term:
+	default:
+		close(x.term)
+		x.wg.Wait()
+	}
+}
+
+func (x *pex) loop() {
+	defer x.wg.Done()
+
+	ticker := time.NewTicker(pexInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-x.term:
+			return
+		case <-ticker.C:
+			for _, p := range x.peers.Peers() {
+				x.exchange(p)
+			}
+			x.clean()
+		}
+	}
+}
+
+// exchange sends our endpoint and a sample of the endpoints of the other peers to p.
+func (x *pex) exchange(p Peer) {
+	if x.self == nil {
+		return
+	}
+
+	msg := &message.PeerExchange{Endpoints: []*message.PeerEndpoint{x.signSelf()}}
+	msg.Endpoints = append(msg.Endpoints, x.sample(p.ID(), pexSampleSize)...)
+
+	if err := p.Send(PeerExchangeCode, 0, msg); err != nil {
+		netLog.Warn(fmt.Sprintf("send PeerExchangeMsg to %s error: %v", p.RemoteAddr(), err))
+	}
+}
+
+func (x *pex) signSelf() *message.PeerEndpoint {
+	self := *x.self
+	self.Timestamp = time.Now().Unix()
+	self.Sign(x.priv)
+	return &self
+}
+
+// sample returns at most n endpoints of the connected peers except the peer exclude.
+func (x *pex) sample(exclude string, n int) []*message.PeerEndpoint {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	l := make([]*message.PeerEndpoint, 0, len(x.records))
+	for id, e := range x.records {
+		if id != exclude && x.peers.Get(id) != nil {
+			l = append(l, e)
+		}
+	}
+
+	rand.Shuffle(len(l), func(i, j int) { l[i], l[j] = l[j], l[i] })
+	if len(l) > n {
+		l = l[:n]
+	}
+	return l
+}
+
+// clean removes the endpoints of the disconnected peers, the dial records older than pexRedialPeriod
+// and the expired metadata.
+func (x *pex) clean() {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	for id := range x.records {
+		if x.peers.Get(id) == nil {
+			delete(x.records, id)
+		}
+	}
+
+	now := time.Now()
+	for id, t := range x.dialed {
+		if now.Sub(t) > pexRedialPeriod {
+			delete(x.dialed, id)
+		}
+	}
+
+	for id, e := range x.metas {
+		if time.Unix(e.Timestamp, 0).Before(now.Add(-pexExpiration)) {
+			delete(x.metas, id)
+		}
+	}
+}
+
+// saveMetadata keeps the metadata of e if it's newer than the one known, the metadata of the nodes
+// unknown are dropped when pexMaxMetadata nodes are known already.
+func (x *pex) saveMetadata(e *message.PeerEndpoint) {
+	if !e.VerifyMetadata() {
+		return
+	}
+
+	id := discovery.NodeID(e.ID)
+
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	if old, ok := x.metas[id]; ok {
+		if old.Timestamp < e.Timestamp {
+			x.metas[id] = e
+		}
+	} else if len(x.metas) < pexMaxMetadata {
+		x.metas[id] = e
+	}
+}
+
+// peerMetadata returns the metadata known of the other nodes.
+func (x *pex) peerMetadata() []PeerMetadata {
+	x.lock.Lock()
+	defer x.lock.Unlock()
+
+	l := make([]PeerMetadata, 0, len(x.metas))
+	for id, e := range x.metas {
+		l = append(l, PeerMetadata{
+			ID:        id.String(),
+			Operator:  e.Metadata.Operator,
+			Contact:   e.Metadata.Contact,
+			Region:    e.Metadata.Region,
+			Services:  e.Metadata.Services.Names(),
+			Timestamp: e.Timestamp,
+			Connected: x.peers.Get(id.String()) != nil,
+		})
+	}
+	sort.Slice(l, func(i, j int) bool {
+		return l[i].ID < l[j].ID
+	})
+	return l
+}
+
+func (x *pex) Handle(msg *p2p.Msg, sender Peer) error {
+	res := new(message.PeerExchange)
+	if err := res.Deserialize(msg.Payload); err != nil {
+		return errDecode(err)
+	}
+
+	if len(res.Endpoints) > pexSampleSize+1 {
+		res.Endpoints = res.Endpoints[:pexSampleSize+1]
+	}
+
+	now := time.Now()
+	dials := 0
+	for _, e := range res.Endpoints {
+		if !x.valid(e, now) {
+			continue
+		}
+
+		id := discovery.NodeID(e.ID)
+		if x.self != nil && id != discovery.NodeID(x.self.ID) {
+			x.saveMetadata(e)
+		}
+		if id.String() == sender.ID() {
+			if len(e.IP) == 0 {
+				e.ObservedIP = sender.RemoteAddr().IP
+			}
+			x.lock.Lock()
+			x.records[sender.ID()] = e
+			x.lock.Unlock()
+			continue
+		}
+
+		if dials < pexMaxDials && x.dial(id, e.Addr(), now) {
+			dials++
+		}
+	}
+
+	return nil
+}
+
+// valid reports whether e is signed by its node and is not expired.
+func (x *pex) valid(e *message.PeerEndpoint, now time.Time) bool {
+	t := time.Unix(e.Timestamp, 0)
+	if t.Before(now.Add(-pexExpiration)) || t.After(now.Add(pexExpiration)) {
+		return false
+	}
+	return e.Verify()
+}
+
+// dial connects the node id at addr unless it is ourself, connected, dialed recently or
+// the peers are enough already.
+func (x *pex) dial(id discovery.NodeID, addr *net2.TCPAddr, now time.Time) bool {
+	if addr == nil || x.dialer == nil || id == discovery.NodeID(x.self.ID) {
+		return false
+	}
+	if x.peers.Get(id.String()) != nil || x.peers.Count() >= x.maxPeers {
+		return false
+	}
+
+	x.lock.Lock()
+	if t, ok := x.dialed[id]; ok && now.Sub(t) < pexRedialPeriod {
+		x.lock.Unlock()
+		return false
+	}
+	x.dialed[id] = now
+	x.lock.Unlock()
+
+	x.dialer.Connect(id, addr)
+	return true
+}
@@ -0,0 +1,171 @@
+package net
+
+import (
+	"crypto/rand"
+	net2 "net"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/p2p/discovery"
+	"github.com/vitelabs/go-vite/vite/net/message"
+)
+
+type pexPeer struct {
+	*MockPeer
+	id   string
+	addr *net2.TCPAddr
+}
+
+func (p *pexPeer) ID() string {
+	return p.id
+}
+
+func (p *pexPeer) RemoteAddr() *net2.TCPAddr {
+	return p.addr
+}
+
+type pexDialer struct {
+	dialed map[discovery.NodeID]*net2.TCPAddr
+}
+
+func (d *pexDialer) Connect(id discovery.NodeID, addr *net2.TCPAddr) {
+	d.dialed[id] = addr
+}
+
+func newPexEndpoint(t *testing.T, ip net2.IP, now time.Time) (*message.PeerEndpoint, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &message.PeerEndpoint{IP: ip, Port: 8483, Timestamp: now.Unix()}
+	copy(e.ID[:], pub)
+	e.Sign(priv)
+	return e, priv
+}
+
+func TestPex_Handle(t *testing.T) {
+	now := time.Now()
+	self, priv := newPexEndpoint(t, nil, now)
+	d := &pexDialer{dialed: make(map[discovery.NodeID]*net2.TCPAddr)}
+
+	x := newPex(newPeerSet(), nil)
+	x.self, x.priv, x.dialer, x.maxPeers = self, priv, d, 10
+
+	// sender listens on an unspecified ip, it's filled with the observed one
+	sender, _ := newPexEndpoint(t, nil, now)
+	sp := &pexPeer{
+		MockPeer: NewMockPeer(),
+		id:       discovery.NodeID(sender.ID).String(),
+		addr:     &net2.TCPAddr{IP: net2.IPv4(1, 2, 3, 4), Port: 50000},
+	}
+	x.peers.m[sp.id] = sp
+
+	other, _ := newPexEndpoint(t, net2.IPv4(5, 6, 7, 8), now)
+	expired, _ := newPexEndpoint(t, net2.IPv4(5, 6, 7, 9), now.Add(-2*pexExpiration))
+	tampered, _ := newPexEndpoint(t, net2.IPv4(5, 6, 7, 10), now)
+	tampered.Port++
+
+	data, err := (&message.PeerExchange{Endpoints: []*message.PeerEndpoint{sender, other, expired, tampered, self}}).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &p2p.Msg{Cmd: p2p.Cmd(PeerExchangeCode), Payload: data}
+
+	if err = x.Handle(msg, sp); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := x.records[sp.id]
+	if rec == nil || !rec.ObservedIP.Equal(sp.addr.IP) {
+		t.Fatalf("unexpected sender record %+v", rec)
+	}
+	if addr := rec.Addr(); addr == nil || !addr.IP.Equal(sp.addr.IP) || addr.Port != 8483 {
+		t.Fatalf("unexpected sender address %v", addr)
+	}
+
+	if len(d.dialed) != 1 {
+		t.Fatalf("dialed %d nodes, want 1", len(d.dialed))
+	}
+	if addr := d.dialed[discovery.NodeID(other.ID)]; addr == nil || !addr.IP.Equal(other.IP) {
+		t.Fatalf("unexpected dialed address %v", addr)
+	}
+
+	// dialed recently
+	delete(d.dialed, discovery.NodeID(other.ID))
+	if err = x.Handle(msg, sp); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.dialed) != 0 {
+		t.Fatalf("redial %d nodes in %s", len(d.dialed), pexRedialPeriod)
+	}
+
+	if l := x.sample(sp.id, pexSampleSize); len(l) != 0 {
+		t.Fatalf("sample should exclude the receiver")
+	}
+	if l := x.sample("", pexSampleSize); len(l) != 1 || l[0] != rec {
+		t.Fatalf("unexpected sample %v", l)
+	}
+
+	delete(x.peers.m, sp.id)
+	x.clean()
+	if len(x.records) != 0 {
+		t.Fatalf("record of disconnected peer should be removed")
+	}
+}
+
+func TestPex_Metadata(t *testing.T) {
+	now := time.Now()
+	self, priv := newPexEndpoint(t, nil, now)
+
+	x := newPex(newPeerSet(), &message.NodeMetadata{Operator: "self"})
+	x.self, x.priv, x.maxPeers = self, priv, 10
+
+	sp := &pexPeer{MockPeer: NewMockPeer(), addr: &net2.TCPAddr{IP: net2.IPv4(1, 2, 3, 4), Port: 50000}}
+	sender, senderPriv := newPexEndpoint(t, nil, now)
+	sp.id = discovery.NodeID(sender.ID).String()
+	x.peers.m[sp.id] = sp
+	sender.Metadata = &message.NodeMetadata{Operator: "vite", Region: "eu", Services: message.ServiceArchive}
+	sender.Sign(senderPriv)
+
+	other, otherPriv := newPexEndpoint(t, net2.IPv4(5, 6, 7, 8), now)
+	other.Metadata = &message.NodeMetadata{Operator: "other", Services: message.ServiceLightServer}
+	other.Sign(otherPriv)
+	other.Metadata.Operator = "forged"
+
+	handle := func(endpoints ...*message.PeerEndpoint) {
+		data, err := (&message.PeerExchange{Endpoints: endpoints}).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = x.Handle(&p2p.Msg{Cmd: p2p.Cmd(PeerExchangeCode), Payload: data}, sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handle(sender, other, x.signSelf())
+
+	l := x.peerMetadata()
+	if len(l) != 1 || l[0].ID != sp.id || l[0].Operator != "vite" || l[0].Region != "eu" || !l[0].Connected {
+		t.Fatalf("unexpected metadata %+v", l)
+	}
+	if len(l[0].Services) != 1 || l[0].Services[0] != "archive" {
+		t.Fatalf("unexpected services %v", l[0].Services)
+	}
+
+	// the older one is ignored
+	older := *sender
+	older.Timestamp--
+	older.Metadata = &message.NodeMetadata{Operator: "older"}
+	older.Sign(senderPriv)
+	handle(&older)
+	if l = x.peerMetadata(); l[0].Operator != "vite" {
+		t.Fatalf("metadata replaced by an older one %+v", l[0])
+	}
+
+	delete(x.peers.m, sp.id)
+	x.clean()
+	if l = x.peerMetadata(); len(l) != 1 || l[0].Connected {
+		t.Fatalf("metadata of the disconnected node should be kept %+v", l)
+	}
+}
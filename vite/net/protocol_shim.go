@@ -0,0 +1,79 @@
+package net
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vitelabs/go-vite/p2p"
+)
+
+var errLegacyProtocolExpired = errors.New("the transition window of the legacy vite protocol is over")
+
+// msgShim translates the messages of the current version of the vite protocol to and from a legacy
+// version, so that a node can serve peers which are not upgraded yet during a transition window.
+type msgShim interface {
+	// out translates msg sent to a legacy peer, false if the legacy version has no such message
+	out(msg *p2p.Msg) bool
+	// in translates msg received from a legacy peer, false if msg should be discarded
+	in(msg *p2p.Msg) bool
+}
+
+// protocolShims are the legacy versions of the vite protocol served besides CmdSet, by their ids.
+// p2p runs the highest version supported by both sides with a peer.
+var protocolShims = map[p2p.CmdSet]msgShim{
+	2: shimV2{},
+}
+
+// shimV2 serves the version before PeerExchangeCode.
+type shimV2 struct{}
+
+func (shimV2) known(cmd ViteCmd) bool {
+	return cmd < PeerExchangeCode || cmd == ExceptionCode
+}
+
+func (s shimV2) out(msg *p2p.Msg) bool {
+	return s.known(ViteCmd(msg.Cmd))
+}
+
+func (s shimV2) in(msg *p2p.Msg) bool {
+	return s.known(ViteCmd(msg.Cmd))
+}
+
+// legacyExpired reports whether the transition window of the legacy versions is over at now
+func (n *net) legacyExpired(now time.Time) bool {
+	return !n.LegacyDeadline.IsZero() && now.After(n.LegacyDeadline)
+}
+
+// mountProtocols mounts the current version of the vite protocol, and the legacy versions until
+// LegacyDeadline.
+func (n *net) mountProtocols() {
+	n.protocols = append(n.protocols, &p2p.Protocol{
+		Name: Vite,
+		ID:   CmdSet,
+		Handle: func(p *p2p.Peer, rw *p2p.ProtoFrame) error {
+			// will be called by p2p.Peer.runProtocols use goroutine
+			peer := newPeer(p, rw, CmdSet)
+			return n.handlePeer(peer)
+		},
+	})
+
+	if n.legacyExpired(time.Now()) {
+		return
+	}
+
+	for id, shim := range protocolShims {
+		id, shim := id, shim
+		n.protocols = append(n.protocols, &p2p.Protocol{
+			Name: Vite,
+			ID:   id,
+			Handle: func(p *p2p.Peer, rw *p2p.ProtoFrame) error {
+				if n.legacyExpired(time.Now()) {
+					return p2p.DiscIncompatibleVersion
+				}
+				peer := newPeer(p, rw, id)
+				peer.shim = shim
+				return n.handlePeer(peer)
+			},
+		})
+	}
+}
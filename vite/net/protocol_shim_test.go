@@ -0,0 +1,42 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/p2p"
+)
+
+func TestShimV2(t *testing.T) {
+	shim := protocolShims[2]
+	for _, code := range []ViteCmd{HandshakeCode, NewAccountBlockCode, ExceptionCode} {
+		if !shim.out(&p2p.Msg{Cmd: p2p.Cmd(code)}) || !shim.in(&p2p.Msg{Cmd: p2p.Cmd(code)}) {
+			t.Fatalf("%s should be translated", code)
+		}
+	}
+	if shim.out(&p2p.Msg{Cmd: p2p.Cmd(PeerExchangeCode)}) {
+		t.Fatal("legacy peer doesn't know PeerExchangeCode")
+	}
+}
+
+func TestNet_MountProtocols(t *testing.T) {
+	n := &net{Config: &Config{}}
+	n.mountProtocols()
+	if len(n.protocols) != len(protocolShims)+1 {
+		t.Fatalf("legacy versions should be served without deadline, got %d protocols", len(n.protocols))
+	}
+	for _, pt := range n.protocols {
+		if pt.Name != Vite || pt.ID > CmdSet {
+			t.Fatalf("unexpected protocol %s", pt)
+		}
+	}
+
+	n = &net{Config: &Config{LegacyDeadline: time.Now().Add(-time.Second)}}
+	n.mountProtocols()
+	if len(n.protocols) != 1 || n.protocols[0].ID != CmdSet {
+		t.Fatalf("only the current version should be served after deadline, got %d protocols", len(n.protocols))
+	}
+	if !n.legacyExpired(time.Now()) || (&net{Config: &Config{}}).legacyExpired(time.Now()) {
+		t.Fatal("unexpected legacyExpired")
+	}
+}
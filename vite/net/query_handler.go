@@ -17,7 +17,8 @@ import (
 
 const Vite = "vite"
 
-const CmdSet = 2
+// CmdSet is the id of the current version of the vite protocol, see protocolShims for the legacy versions
+const CmdSet = 4
 
 type ViteCmd p2p.Cmd
 
@@ -45,6 +46,7 @@ const (
 	AccountBlocksCode
 	NewSnapshotBlockCode
 	NewAccountBlockCode
+	PeerExchangeCode
 
 	ExceptionCode = 127
 )
@@ -73,6 +75,7 @@ var msgNames = [...]string{
 	AccountBlocksCode:                  "AccountBlocksMsg",
 	NewSnapshotBlockCode:               "NewSnapshotBlockMsg",
 	NewAccountBlockCode:                "NewAccountBlockMsg",
+	PeerExchangeCode:                   "PeerExchangeMsg",
 }
 
 func (t ViteCmd) String() string {
@@ -80,7 +83,7 @@ func (t ViteCmd) String() string {
 		return "ExceptionMsg"
 	}
 
-	if t > NewAccountBlockCode {
+	if t > PeerExchangeCode {
 		return "UnkownMsg"
 	}
 
@@ -102,7 +105,7 @@ func statusHandler(msg *p2p.Msg, sender Peer) error {
 	status := new(ledger.HashHeight)
 
 	if err := status.Deserialize(msg.Payload); err != nil {
-		return err
+		return errDecode(err)
 	}
 
 	sender.SetHead(status.Hash, status.Height)
@@ -250,7 +253,7 @@ func (q *queryHandler) loop() {
 				cmd := ViteCmd(event.Msg.Cmd)
 				if h, ok := q.handlers[cmd]; ok {
 					if err := h.Handle(event.Msg, event.Sender); err != nil {
-						event.Sender.Report(err)
+						event.Sender.Report(queryError{err})
 					}
 				}
 			}
@@ -280,7 +283,7 @@ func (s *getSubLedgerHandler) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	req := new(message.GetSnapshotBlocks)
 
 	if err = req.Deserialize(msg.Payload); err != nil {
-		return
+		return errDecode(err)
 	}
 
 	netLog.Info(fmt.Sprintf("receive %s from %s", req, sender.RemoteAddr()))
@@ -376,7 +379,7 @@ func (s *getSnapshotBlocksHandler) Handle(msg *p2p.Msg, sender Peer) (err error)
 	req := new(message.GetSnapshotBlocks)
 
 	if err = req.Deserialize(msg.Payload); err != nil {
-		return
+		return errDecode(err)
 	}
 
 	netLog.Info(fmt.Sprintf("receive %s from %s", req, sender.RemoteAddr()))
@@ -456,7 +459,7 @@ func (a *getAccountBlocksHandler) Handle(msg *p2p.Msg, sender Peer) (err error)
 	req := new(message.GetAccountBlocks)
 
 	if err = req.Deserialize(msg.Payload); err != nil {
-		return
+		return errDecode(err)
 	}
 
 	netLog.Info(fmt.Sprintf("receive %s from %s", req, sender.RemoteAddr()))
@@ -540,7 +543,7 @@ func (c *getChunkHandler) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	req := new(message.GetChunk)
 	err = req.Deserialize(msg.Payload)
 	if err != nil {
-		return err
+		return errDecode(err)
 	}
 
 	netLog.Info(fmt.Sprintf("receive %s from %s", req, sender.RemoteAddr()))
@@ -1,6 +1,8 @@
 package net
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"sync"
@@ -49,6 +51,12 @@ const waitEnoughPeers = 10 * time.Second
 const enoughPeers = 3
 const chainGrowInterval = time.Second
 
+// a node restarting after a short downtime catches up by chunks instead of files
+const catchUpChunk = 500
+const catchUpTimeout = 5 * time.Minute
+
+var errSyncCanceled = errors.New("sync canceled")
+
 func shouldSync(from, to uint64) bool {
 	if to >= from+minHeightDifference {
 		return true
@@ -253,11 +261,21 @@ wait:
 	// p is not all enough, no need to sync
 	if current.Height+minHeightDifference > syncPeerHeight {
 		if current.Height < syncPeerHeight {
-			syncPeer.Send(GetSnapshotBlocksCode, 0, &message.GetSnapshotBlocks{
-				From:    ledger.HashHeight{Height: syncPeerHeight},
-				Count:   1,
-				Forward: true,
-			})
+			if err := s.catchUp(current.Height+1, syncPeerHeight); err != nil {
+				if err == errSyncCanceled {
+					s.log.Warn("sync cancel")
+					s.setState(SyncCancel)
+					return
+				}
+
+				// let the fetcher fill the gap from the latest block of syncPeer
+				s.log.Warn(fmt.Sprintf("catch up to %d error: %v", syncPeerHeight, err))
+				syncPeer.Send(GetSnapshotBlocksCode, 0, &message.GetSnapshotBlocks{
+					From:    ledger.HashHeight{Height: syncPeerHeight},
+					Count:   1,
+					Forward: true,
+				})
+			}
 		}
 
 		s.log.Info(fmt.Sprintf("sync done: syncPeer %s at %d, our height: %d", syncPeer.RemoteAddr(), syncPeerHeight, current.Height))
@@ -333,6 +351,55 @@ wait:
 	}
 }
 
+// catchUp is the fast path of a node missing less than minHeightDifference snapshot blocks,
+// it downloads only the missing snapshot blocks and the account blocks confirmed by them,
+// and waits until they are inserted into chain.
+func (s *syncer) catchUp(from, to uint64) error {
+	s.from, s.to, s.current = from, to, from-1
+	s.setState(Syncing)
+	s.log.Info(fmt.Sprintf("catch up from %d to %d", from, to))
+
+	ctx, cancel := context.WithTimeout(context.Background(), catchUpTimeout)
+	defer cancel()
+
+	s.pool.start()
+	var results []<-chan error
+	for _, ck := range splitChunk(from, to, catchUpChunk) {
+		results = append(results, s.pool.download(ctx, ck[0], ck[1]))
+	}
+
+	for _, result := range results {
+		select {
+		case err := <-result:
+			if err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.term:
+			return errSyncCanceled
+		}
+	}
+
+	ticker := time.NewTicker(chainGrowInterval)
+	defer ticker.Stop()
+
+	for {
+		if current := s.chain.GetLatestSnapshotBlock(); current.Height >= to {
+			s.log.Info(fmt.Sprintf("catch up done, current height: %d", current.Height))
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.term:
+			return errSyncCanceled
+		}
+	}
+}
+
 // this method will be called when our target Height changed, (eg. the best peer disconnected)
 func (s *syncer) setTarget(to uint64) {
 	atomic.StoreUint64(&s.to, to)
@@ -456,7 +523,7 @@ func (s *syncer) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	case FileListCode:
 		res := new(message.FileList)
 		if err = res.Deserialize(msg.Payload); err != nil {
-			return err
+			return errDecode(err)
 		}
 
 		s.log.Info(fmt.Sprintf("receive %s from %s", res, sender.RemoteAddr()))
@@ -0,0 +1,116 @@
+package net
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/vitelabs/go-vite"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite/net/message"
+)
+
+// unknownVersion stands for the peers running a release which doesn't tell its version
+const unknownVersion = "unknown"
+
+// ForkReadiness is how many peers advertise a fork, Supported is false if the local node
+// doesn't know the fork or knows it at another height.
+type ForkReadiness struct {
+	Name      string `json:"name"`
+	Height    uint64 `json:"height"`
+	Peers     int    `json:"peers"`
+	Supported bool   `json:"supported"`
+}
+
+type PeerVersions struct {
+	PeerCount int             `json:"peerCount"`
+	Version   string          `json:"version"`
+	Versions  map[string]int  `json:"versions"`
+	Forks     []ForkReadiness `json:"forks"`
+}
+
+// localForks returns the fork points the local node supports, the ones not activated are omitted
+func localForks() []message.ForkPoint {
+	var forks []message.ForkPoint
+	for _, item := range fork.GetForkPointList() {
+		if item.Height > 0 {
+			forks = append(forks, message.ForkPoint{Name: item.Name(), Height: item.Height})
+		}
+	}
+	return forks
+}
+
+func peerVersions(infos []PeerInfo, local []message.ForkPoint) PeerVersions {
+	supported := make(map[message.ForkPoint]bool, len(local))
+	for _, f := range local {
+		supported[f] = true
+	}
+
+	pv := PeerVersions{
+		PeerCount: len(infos),
+		Version:   govite.VITE_BUILD_VERSION,
+		Versions:  make(map[string]int),
+	}
+	counts := make(map[message.ForkPoint]int)
+	for _, info := range infos {
+		version := info.Version
+		if version == "" {
+			version = unknownVersion
+		}
+		pv.Versions[version]++
+		for _, f := range info.Forks {
+			counts[f]++
+		}
+	}
+	for f, n := range counts {
+		pv.Forks = append(pv.Forks, ForkReadiness{Name: f.Name, Height: f.Height, Peers: n, Supported: supported[f]})
+	}
+	sort.Slice(pv.Forks, func(i, j int) bool {
+		if pv.Forks[i].Height != pv.Forks[j].Height {
+			return pv.Forks[i].Height < pv.Forks[j].Height
+		}
+		return pv.Forks[i].Name < pv.Forks[j].Name
+	})
+	return pv
+}
+
+// forkWarner warns once when a majority of peers advertise a fork the local node doesn't support,
+// and again if the fork loses the majority and gains it later.
+type forkWarner struct {
+	mu     sync.Mutex
+	warned map[message.ForkPoint]bool
+	log    log15.Logger
+}
+
+func newForkWarner() *forkWarner {
+	return &forkWarner{
+		warned: make(map[message.ForkPoint]bool),
+		log:    log15.New("module", "net/versions"),
+	}
+}
+
+// check returns the unsupported forks advertised by a majority of peers which are not warned yet
+func (w *forkWarner) check(pv PeerVersions) (warn []ForkReadiness) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	majority := make(map[message.ForkPoint]bool)
+	for _, f := range pv.Forks {
+		if f.Supported || f.Peers*2 <= pv.PeerCount {
+			continue
+		}
+		key := message.ForkPoint{Name: f.Name, Height: f.Height}
+		majority[key] = true
+		if !w.warned[key] {
+			warn = append(warn, f)
+		}
+	}
+	w.warned = majority
+
+	for _, f := range warn {
+		w.log.Warn(fmt.Sprintf("%d of %d peers advertise fork %s at height %d which is not supported by this node %s, please upgrade",
+			f.Peers, pv.PeerCount, f.Name, f.Height, pv.Version))
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/vite/net/message"
+)
+
+func TestPeerVersions(t *testing.T) {
+	local := []message.ForkPoint{{Name: "Smart", Height: 100}}
+	infos := []PeerInfo{
+		{Version: "v1.3.1", Forks: []message.ForkPoint{{Name: "Smart", Height: 100}, {Name: "Mint", Height: 200}}},
+		{Version: "v1.3.1", Forks: []message.ForkPoint{{Name: "Smart", Height: 100}, {Name: "Mint", Height: 200}}},
+		{},
+	}
+
+	pv := peerVersions(infos, local)
+	if pv.PeerCount != 3 || pv.Versions["v1.3.1"] != 2 || pv.Versions[unknownVersion] != 1 {
+		t.Fatalf("unexpected versions %+v", pv)
+	}
+	if len(pv.Forks) != 2 {
+		t.Fatalf("unexpected forks %+v", pv.Forks)
+	}
+	if f := pv.Forks[0]; f.Name != "Smart" || f.Peers != 2 || !f.Supported {
+		t.Fatalf("unexpected fork %+v", f)
+	}
+	if f := pv.Forks[1]; f.Name != "Mint" || f.Peers != 2 || f.Supported {
+		t.Fatalf("unexpected fork %+v", f)
+	}
+
+	w := newForkWarner()
+	if warn := w.check(pv); len(warn) != 1 || warn[0].Name != "Mint" {
+		t.Fatalf("unexpected warnings %+v", warn)
+	}
+	// warned once while the majority holds
+	if warn := w.check(pv); len(warn) != 0 {
+		t.Fatalf("unexpected warnings %+v", warn)
+	}
+
+	infos = append(infos, PeerInfo{}, PeerInfo{})
+	if warn := w.check(peerVersions(infos, local)); len(warn) != 0 {
+		t.Fatalf("unexpected warnings %+v", warn)
+	}
+	infos = infos[:3]
+	if warn := w.check(peerVersions(infos, local)); len(warn) != 1 {
+		t.Fatalf("fork gaining the majority again should be warned")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/vitelabs/go-vite/vite/net/sbpn"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/consensus"
+	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/onroad"
 	"github.com/vitelabs/go-vite/p2p"
@@ -20,7 +22,9 @@ import (
 	"github.com/vitelabs/go-vite/producer"
 	"github.com/vitelabs/go-vite/verifier"
 	"github.com/vitelabs/go-vite/vite/net"
+	"github.com/vitelabs/go-vite/vite/net/message"
 	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm/contracts"
 	"github.com/vitelabs/go-vite/wallet"
 )
 
@@ -41,11 +45,15 @@ type Vite struct {
 	consensus        consensus.Consensus
 	onRoad           *onroad.Manager
 	p2p              p2p.Server
+	devMiner         *producer.DevMiner
 }
 
 func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err error) {
 	// set fork points
 	fork.SetForkPoints(cfg.ForkPoints)
+	if err := contracts.SetPledgeLockTiers(cfg.PledgeLockTiers); err != nil {
+		return nil, err
+	}
 
 	// chain
 	chain := chain.NewChain(cfg)
@@ -59,16 +67,44 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 
 	// sb verifier
 	aVerifier := verifier.NewAccountVerifier(chain, cs)
-	sbVerifier := verifier.NewSnapshotVerifier(chain, cs)
+	var sbCs consensus.Verifier = cs
+	if cfg.DevMining {
+		if !cfg.Producer.Producer {
+			return nil, errors.New("dev mining needs the producer enabled")
+		}
+		coinbase, _, err := parseCoinbase(cfg.Producer.Coinbase)
+		if err != nil {
+			return nil, err
+		}
+		sbCs = &devVerifier{Verifier: cs, coinbase: *coinbase}
+	}
+	sbVerifier := verifier.NewSnapshotVerifier(chain, sbCs)
 
 	// net
 	netVerifier := verifier.NewNetVerifier(sbVerifier, aVerifier)
-	net := net.New(&net.Config{
+	netCfg := &net.Config{
 		Single:      cfg.Single,
 		FileAddress: cfg.FileAddress,
 		Chain:       chain,
 		Verifier:    netVerifier,
-	})
+	}
+	if cfg.LegacyProtocolDeadline > 0 {
+		netCfg.LegacyDeadline = time.Unix(cfg.LegacyProtocolDeadline, 0)
+	}
+	services, err := message.ParseServices(cfg.NodeServices)
+	if err != nil {
+		return nil, err
+	}
+	netCfg.Metadata = &message.NodeMetadata{
+		Operator: cfg.NodeOperator,
+		Contact:  cfg.NodeContact,
+		Region:   cfg.NodeRegion,
+		Services: services,
+	}
+	if err = netCfg.Metadata.Check(); err != nil {
+		return nil, err
+	}
+	net := net.New(netCfg)
 
 	// vite
 	vite = &Vite{
@@ -101,7 +137,12 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 			Address:   *coinbase,
 			Index:     index,
 		}
-		vite.producer = producer.NewProducer(chain, net, addressContext, cs, sbVerifier, walletManager, pl)
+		p := producer.NewProducer(chain, net, addressContext, cs, sbVerifier, walletManager, pl)
+		if cfg.DevMining {
+			vite.devMiner = p.DevMiner()
+			sbVerifier.SetClock(vite.devClock)
+		}
+		vite.producer = p
 
 		net.AddPlugin(sbpn.New(*coinbase, cs))
 	}
@@ -195,6 +236,11 @@ func (v *Vite) Producer() producer.Producer {
 	return v.producer
 }
 
+// DevMiner returns the on demand producer of the snapshot blocks, nil unless dev mining is enabled
+func (v *Vite) DevMiner() *producer.DevMiner {
+	return v.devMiner
+}
+
 func (v *Vite) Pool() pool.BlockPool {
 	return v.pool
 }
@@ -231,3 +277,22 @@ func parseCoinbase(coinbaseCfg string) (*types.Address, uint32, error) {
 
 	return &addr, uint32(i), nil
 }
+
+// devVerifier accepts the snapshot blocks produced by the coinbase at any time, the dev miner does not follow
+// the schedule of the consensus
+type devVerifier struct {
+	consensus.Verifier
+	coinbase types.Address
+}
+
+func (v *devVerifier) VerifySnapshotProducer(block *ledger.SnapshotBlock) (bool, error) {
+	return block.Producer() == v.coinbase, nil
+}
+
+// devClock is the clock of the snapshot verifier with dev mining, the time set by the tests may be ahead of now
+func (v *Vite) devClock() time.Time {
+	if t := v.devMiner.Now(); t.After(time.Now()) {
+		return t
+	}
+	return time.Now()
+}
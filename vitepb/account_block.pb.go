@@ -39,6 +39,7 @@ type AccountBlock struct {
 	Difficulty           []byte   `protobuf:"bytes,18,opt,name=difficulty,proto3" json:"difficulty,omitempty"`
 	Nonce                []byte   `protobuf:"bytes,19,opt,name=nonce,proto3" json:"nonce,omitempty"`
 	Signature            []byte   `protobuf:"bytes,20,opt,name=signature,proto3" json:"signature,omitempty"`
+	Salt                 []byte   `protobuf:"bytes,21,opt,name=salt,proto3" json:"salt,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -208,6 +209,13 @@ func (m *AccountBlock) GetSignature() []byte {
 	return nil
 }
 
+func (m *AccountBlock) GetSalt() []byte {
+	if m != nil {
+		return m.Salt
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*AccountBlock)(nil), "vitepb.AccountBlock")
 }
@@ -217,28 +225,28 @@ func init() {
 }
 
 var fileDescriptor_account_block_12363a10e9f27447 = []byte{
-	// 354 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x5c, 0x92, 0x41, 0x6f, 0xa3, 0x30,
-	0x10, 0x85, 0xc5, 0x86, 0x90, 0x64, 0x96, 0x64, 0xb3, 0xde, 0x68, 0x35, 0x8a, 0xaa, 0x0a, 0x45,
-	0x55, 0xc5, 0xa9, 0x3d, 0xf4, 0x17, 0xa4, 0xa7, 0x56, 0xbd, 0xd1, 0xde, 0x2b, 0x03, 0x26, 0xa0,
-	0x00, 0xa6, 0xd8, 0x44, 0xca, 0x5f, 0xeb, 0xaf, 0xab, 0x3c, 0x26, 0xa4, 0xe9, 0x6d, 0xde, 0xfb,
-	0x9e, 0x98, 0x37, 0x16, 0xb0, 0x3e, 0x14, 0x5a, 0x34, 0xf1, 0x3d, 0x4f, 0x12, 0xd9, 0xd5, 0xfa,
-	0x3d, 0x2e, 0x65, 0xb2, 0xbf, 0x6b, 0x5a, 0xa9, 0x25, 0xf3, 0x2c, 0xdb, 0x7c, 0xba, 0xe0, 0x6f,
-	0x2d, 0x7f, 0x34, 0x98, 0x5d, 0xc1, 0x8c, 0x72, 0x6f, 0xc7, 0x46, 0xa0, 0x13, 0x38, 0xe1, 0x3c,
-	0x3a, 0x1b, 0x8c, 0x81, 0x9b, 0x73, 0x95, 0xe3, 0xaf, 0xc0, 0x09, 0xfd, 0x88, 0x66, 0xf6, 0x1f,
-	0xbc, 0x5c, 0x14, 0xbb, 0x5c, 0xe3, 0x28, 0x70, 0x42, 0x37, 0xea, 0x15, 0x5b, 0xc3, 0xb4, 0x69,
-	0xc5, 0xe1, 0xc9, 0xe4, 0x5d, 0xca, 0x0f, 0x9a, 0xdd, 0xc2, 0xa2, 0x6f, 0xb5, 0x4d, 0xd3, 0x56,
-	0x28, 0x85, 0x63, 0x4a, 0xfc, 0x70, 0x4d, 0x9b, 0xa6, 0x8b, 0xcb, 0x22, 0x79, 0x11, 0x47, 0xf4,
-	0x28, 0x72, 0x36, 0x0c, 0xd5, 0xf2, 0xf4, 0x81, 0x89, 0xa5, 0x83, 0xc1, 0x6e, 0x60, 0x9e, 0xb5,
-	0xb2, 0xa2, 0xb3, 0xa8, 0xc4, 0x94, 0x12, 0x97, 0xa6, 0x69, 0xcf, 0x2b, 0xb3, 0x12, 0x67, 0x84,
-	0x7b, 0xc5, 0x10, 0x26, 0x5a, 0xee, 0x45, 0xfd, 0x9c, 0x22, 0x10, 0x38, 0x49, 0xb6, 0x82, 0xf1,
-	0x47, 0x27, 0x35, 0xc7, 0xdf, 0x74, 0xae, 0x15, 0x6c, 0x09, 0xa3, 0x4c, 0x08, 0xf4, 0x29, 0x6b,
-	0x46, 0xb6, 0x01, 0x5f, 0xd5, 0xbc, 0x51, 0xb9, 0xd4, 0xb4, 0x7e, 0x4e, 0xe8, 0xc2, 0x33, 0xef,
-	0x99, 0x72, 0xcd, 0x71, 0x61, 0xdf, 0xd3, 0xcc, 0x74, 0x55, 0x51, 0x09, 0xa5, 0x79, 0xd5, 0xe0,
-	0x9f, 0xc0, 0x09, 0x47, 0xd1, 0xd9, 0x30, 0xf4, 0x55, 0x73, 0x2d, 0xe8, 0x93, 0x4b, 0x7b, 0xf3,
-	0x60, 0x98, 0xd6, 0xa5, 0xdc, 0x11, 0xfb, 0x6b, 0x5b, 0xf7, 0x92, 0x5d, 0x03, 0xa4, 0x45, 0x96,
-	0x15, 0x49, 0x57, 0xea, 0x23, 0x32, 0x82, 0xdf, 0x1c, 0x73, 0x55, 0x2d, 0xeb, 0x44, 0xe0, 0x3f,
-	0x42, 0x56, 0x98, 0x6d, 0xaa, 0xd8, 0xd5, 0x5c, 0x77, 0xad, 0xc0, 0x95, 0xdd, 0x36, 0x18, 0xb1,
-	0x47, 0xff, 0xd2, 0xc3, 0x57, 0x00, 0x00, 0x00, 0xff, 0xff, 0x21, 0x62, 0x04, 0x0a, 0x69, 0x02,
-	0x00, 0x00,
+	// 353 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x5d, 0x92, 0x41, 0x4f, 0x83, 0x40,
+	0x10, 0x85, 0x53, 0x4b, 0x69, 0xbb, 0xd2, 0x5a, 0xd7, 0x6a, 0x26, 0x8d, 0x31, 0xa6, 0x31, 0xc6,
+	0x93, 0x1e, 0xfc, 0x05, 0xf5, 0xa4, 0xf1, 0x86, 0xde, 0xcd, 0x02, 0x4b, 0x21, 0x05, 0x16, 0xd9,
+	0xa5, 0x49, 0xff, 0xa4, 0xbf, 0x49, 0x66, 0x96, 0x52, 0xeb, 0x6d, 0xde, 0xfb, 0x5e, 0x76, 0xde,
+	0x10, 0xd8, 0x62, 0x9b, 0x1a, 0x59, 0x06, 0x4f, 0x22, 0x0c, 0x55, 0x5d, 0x98, 0xaf, 0x20, 0x53,
+	0xe1, 0xe6, 0xb1, 0xac, 0x94, 0x51, 0xdc, 0xb5, 0x6c, 0xf9, 0xe3, 0x30, 0x6f, 0x65, 0xf9, 0x0b,
+	0x62, 0x7e, 0xcd, 0xc6, 0x94, 0xfb, 0xdc, 0x95, 0x12, 0x7a, 0xb7, 0xbd, 0x87, 0x89, 0x7f, 0x30,
+	0x38, 0x67, 0x4e, 0x22, 0x74, 0x02, 0x27, 0x0d, 0xf0, 0x7c, 0x9a, 0xf9, 0x15, 0x73, 0x13, 0x99,
+	0xae, 0x13, 0x03, 0xfd, 0xc6, 0x75, 0xfc, 0x56, 0xf1, 0x05, 0x1b, 0x95, 0x95, 0xdc, 0xbe, 0x62,
+	0xde, 0xa1, 0x7c, 0xa7, 0xf9, 0x3d, 0x9b, 0xb6, 0xad, 0x56, 0x51, 0x54, 0x49, 0xad, 0x61, 0x40,
+	0x89, 0x7f, 0x2e, 0xb6, 0x29, 0xeb, 0x20, 0x4b, 0xc3, 0x77, 0xb9, 0x03, 0x97, 0x22, 0x07, 0x03,
+	0xa9, 0x51, 0xfb, 0x07, 0x86, 0x96, 0x76, 0x06, 0xbf, 0x63, 0x93, 0xb8, 0x52, 0x39, 0x9d, 0x45,
+	0x25, 0x46, 0x94, 0x38, 0x36, 0xb1, 0xbd, 0xc8, 0x71, 0x25, 0x8c, 0x09, 0xb7, 0x8a, 0x03, 0x1b,
+	0x1a, 0xb5, 0x91, 0xc5, 0x5b, 0x04, 0x8c, 0xc0, 0x5e, 0xf2, 0x39, 0x1b, 0x7c, 0xd7, 0xca, 0x08,
+	0x38, 0xa5, 0x73, 0xad, 0xe0, 0x33, 0xd6, 0x8f, 0xa5, 0x04, 0x8f, 0xb2, 0x38, 0xf2, 0x25, 0xf3,
+	0x74, 0x21, 0x4a, 0x9d, 0x28, 0x43, 0xeb, 0x27, 0x84, 0x8e, 0x3c, 0xfc, 0x9e, 0x91, 0x68, 0x9e,
+	0x9a, 0xda, 0xef, 0x89, 0x33, 0x5d, 0x95, 0xe6, 0x52, 0x1b, 0x91, 0x97, 0x70, 0xd6, 0x80, 0xbe,
+	0x7f, 0x30, 0x90, 0x7e, 0x18, 0x61, 0x24, 0x3d, 0x39, 0xb3, 0x37, 0x77, 0x06, 0xb6, 0xce, 0xd4,
+	0x9a, 0xd8, 0xb9, 0x6d, 0xdd, 0x4a, 0x7e, 0xc3, 0x58, 0x94, 0xc6, 0x71, 0x1a, 0xd6, 0x99, 0xd9,
+	0x01, 0x27, 0xf8, 0xc7, 0xc1, 0xab, 0x0a, 0x55, 0x84, 0x12, 0x2e, 0x08, 0x59, 0x81, 0xdb, 0x74,
+	0xba, 0x2e, 0x84, 0xa9, 0x2b, 0x09, 0x73, 0xbb, 0xad, 0x33, 0xb0, 0xbd, 0x16, 0x99, 0x81, 0x4b,
+	0xdb, 0x1e, 0xe7, 0xc0, 0xa5, 0xff, 0xeb, 0xf9, 0x17, 0xb3, 0xe0, 0x51, 0x4f, 0x7d, 0x02, 0x00,
+	0x00,
 }
@@ -19,14 +19,16 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type Handshake struct {
-	CmdSet               uint64   `protobuf:"varint,1,opt,name=CmdSet,proto3" json:"CmdSet,omitempty"`
-	Height               uint64   `protobuf:"varint,2,opt,name=Height,proto3" json:"Height,omitempty"`
-	Port                 uint32   `protobuf:"varint,3,opt,name=Port,proto3" json:"Port,omitempty"`
-	Current              []byte   `protobuf:"bytes,4,opt,name=Current,proto3" json:"Current,omitempty"`
-	Genesis              []byte   `protobuf:"bytes,5,opt,name=Genesis,proto3" json:"Genesis,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	CmdSet               uint64       `protobuf:"varint,1,opt,name=CmdSet,proto3" json:"CmdSet,omitempty"`
+	Height               uint64       `protobuf:"varint,2,opt,name=Height,proto3" json:"Height,omitempty"`
+	Port                 uint32       `protobuf:"varint,3,opt,name=Port,proto3" json:"Port,omitempty"`
+	Current              []byte       `protobuf:"bytes,4,opt,name=Current,proto3" json:"Current,omitempty"`
+	Genesis              []byte       `protobuf:"bytes,5,opt,name=Genesis,proto3" json:"Genesis,omitempty"`
+	Version              string       `protobuf:"bytes,6,opt,name=Version,proto3" json:"Version,omitempty"`
+	Forks                []*ForkPoint `protobuf:"bytes,7,rep,name=Forks,proto3" json:"Forks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
 }
 
 func (m *Handshake) Reset()         { *m = Handshake{} }
@@ -88,6 +90,20 @@ func (m *Handshake) GetGenesis() []byte {
 	return nil
 }
 
+func (m *Handshake) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Handshake) GetForks() []*ForkPoint {
+	if m != nil {
+		return m.Forks
+	}
+	return nil
+}
+
 type BlockID struct {
 	Hash                 []byte   `protobuf:"bytes,1,opt,name=Hash,proto3" json:"Hash,omitempty"`
 	Height               uint64   `protobuf:"varint,2,opt,name=Height,proto3" json:"Height,omitempty"`
@@ -596,6 +612,208 @@ func (m *AccountBlocks) GetBlocks() []*AccountBlock {
 	return nil
 }
 
+type ForkPoint struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=Name,proto3" json:"Name,omitempty"`
+	Height               uint64   `protobuf:"varint,2,opt,name=Height,proto3" json:"Height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ForkPoint) Reset()         { *m = ForkPoint{} }
+func (m *ForkPoint) String() string { return proto.CompactTextString(m) }
+func (*ForkPoint) ProtoMessage()    {}
+func (*ForkPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_message_ab411b0053a36526, []int{11}
+}
+func (m *ForkPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ForkPoint.Unmarshal(m, b)
+}
+func (m *ForkPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ForkPoint.Marshal(b, m, deterministic)
+}
+func (dst *ForkPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ForkPoint.Merge(dst, src)
+}
+func (m *ForkPoint) XXX_Size() int {
+	return xxx_messageInfo_ForkPoint.Size(m)
+}
+func (m *ForkPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_ForkPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ForkPoint proto.InternalMessageInfo
+
+func (m *ForkPoint) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ForkPoint) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+type PeerEndpoint struct {
+	ID                   []byte   `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	IP                   []byte   `protobuf:"bytes,2,opt,name=IP,proto3" json:"IP,omitempty"`
+	Port                 uint32   `protobuf:"varint,3,opt,name=Port,proto3" json:"Port,omitempty"`
+	Timestamp            int64    `protobuf:"varint,4,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
+	Signature            []byte   `protobuf:"bytes,5,opt,name=Signature,proto3" json:"Signature,omitempty"`
+	ObservedIP           []byte   `protobuf:"bytes,6,opt,name=ObservedIP,proto3" json:"ObservedIP,omitempty"`
+	Operator             string   `protobuf:"bytes,7,opt,name=Operator,proto3" json:"Operator,omitempty"`
+	Contact              string   `protobuf:"bytes,8,opt,name=Contact,proto3" json:"Contact,omitempty"`
+	Region               string   `protobuf:"bytes,9,opt,name=Region,proto3" json:"Region,omitempty"`
+	Services             uint32   `protobuf:"varint,10,opt,name=Services,proto3" json:"Services,omitempty"`
+	MetadataSignature    []byte   `protobuf:"bytes,11,opt,name=MetadataSignature,proto3" json:"MetadataSignature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PeerEndpoint) Reset()         { *m = PeerEndpoint{} }
+func (m *PeerEndpoint) String() string { return proto.CompactTextString(m) }
+func (*PeerEndpoint) ProtoMessage()    {}
+func (*PeerEndpoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_message_ab411b0053a36526, []int{12}
+}
+func (m *PeerEndpoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerEndpoint.Unmarshal(m, b)
+}
+func (m *PeerEndpoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerEndpoint.Marshal(b, m, deterministic)
+}
+func (dst *PeerEndpoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerEndpoint.Merge(dst, src)
+}
+func (m *PeerEndpoint) XXX_Size() int {
+	return xxx_messageInfo_PeerEndpoint.Size(m)
+}
+func (m *PeerEndpoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerEndpoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerEndpoint proto.InternalMessageInfo
+
+func (m *PeerEndpoint) GetID() []byte {
+	if m != nil {
+		return m.ID
+	}
+	return nil
+}
+
+func (m *PeerEndpoint) GetIP() []byte {
+	if m != nil {
+		return m.IP
+	}
+	return nil
+}
+
+func (m *PeerEndpoint) GetPort() uint32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *PeerEndpoint) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *PeerEndpoint) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *PeerEndpoint) GetObservedIP() []byte {
+	if m != nil {
+		return m.ObservedIP
+	}
+	return nil
+}
+
+func (m *PeerEndpoint) GetOperator() string {
+	if m != nil {
+		return m.Operator
+	}
+	return ""
+}
+
+func (m *PeerEndpoint) GetContact() string {
+	if m != nil {
+		return m.Contact
+	}
+	return ""
+}
+
+func (m *PeerEndpoint) GetRegion() string {
+	if m != nil {
+		return m.Region
+	}
+	return ""
+}
+
+func (m *PeerEndpoint) GetServices() uint32 {
+	if m != nil {
+		return m.Services
+	}
+	return 0
+}
+
+func (m *PeerEndpoint) GetMetadataSignature() []byte {
+	if m != nil {
+		return m.MetadataSignature
+	}
+	return nil
+}
+
+type PeerExchange struct {
+	Endpoints            []*PeerEndpoint `protobuf:"bytes,1,rep,name=Endpoints,proto3" json:"Endpoints,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *PeerExchange) Reset()         { *m = PeerExchange{} }
+func (m *PeerExchange) String() string { return proto.CompactTextString(m) }
+func (*PeerExchange) ProtoMessage()    {}
+func (*PeerExchange) Descriptor() ([]byte, []int) {
+	return fileDescriptor_message_ab411b0053a36526, []int{13}
+}
+func (m *PeerExchange) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PeerExchange.Unmarshal(m, b)
+}
+func (m *PeerExchange) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PeerExchange.Marshal(b, m, deterministic)
+}
+func (dst *PeerExchange) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PeerExchange.Merge(dst, src)
+}
+func (m *PeerExchange) XXX_Size() int {
+	return xxx_messageInfo_PeerExchange.Size(m)
+}
+func (m *PeerExchange) XXX_DiscardUnknown() {
+	xxx_messageInfo_PeerExchange.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PeerExchange proto.InternalMessageInfo
+
+func (m *PeerExchange) GetEndpoints() []*PeerEndpoint {
+	if m != nil {
+		return m.Endpoints
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Handshake)(nil), "vitepb.Handshake")
 	proto.RegisterType((*BlockID)(nil), "vitepb.BlockID")
@@ -608,45 +826,60 @@ func init() {
 	proto.RegisterType((*SnapshotBlocks)(nil), "vitepb.SnapshotBlocks")
 	proto.RegisterType((*GetAccountBlocks)(nil), "vitepb.GetAccountBlocks")
 	proto.RegisterType((*AccountBlocks)(nil), "vitepb.AccountBlocks")
+	proto.RegisterType((*ForkPoint)(nil), "vitepb.ForkPoint")
+	proto.RegisterType((*PeerEndpoint)(nil), "vitepb.PeerEndpoint")
+	proto.RegisterType((*PeerExchange)(nil), "vitepb.PeerExchange")
 }
 
 func init() { proto.RegisterFile("vitepb/message.proto", fileDescriptor_message_ab411b0053a36526) }
 
 var fileDescriptor_message_ab411b0053a36526 = []byte{
-	// 548 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x94, 0x4d, 0x6f, 0xd3, 0x4c,
-	0x10, 0xc7, 0xe5, 0xd8, 0x79, 0x9b, 0xa4, 0xcf, 0x53, 0x56, 0x01, 0x59, 0x81, 0x83, 0x65, 0x2e,
-	0x39, 0x40, 0x8a, 0x82, 0xe0, 0x86, 0x50, 0x08, 0x4d, 0x82, 0x54, 0x2a, 0xb4, 0xfe, 0x00, 0x68,
-	0x1d, 0x8f, 0x62, 0x93, 0xda, 0x8e, 0x76, 0xd7, 0x20, 0x71, 0x43, 0xdc, 0xf8, 0x34, 0x7c, 0x44,
-	0xb4, 0x2f, 0x4e, 0xe2, 0x42, 0xa5, 0xde, 0xf6, 0x3f, 0x33, 0xff, 0x1d, 0xff, 0x76, 0x26, 0x81,
-	0xd1, 0xd7, 0x4c, 0xe2, 0x3e, 0xbe, 0xc8, 0x51, 0x08, 0xb6, 0xc5, 0xe9, 0x9e, 0x97, 0xb2, 0x24,
-	0x1d, 0x13, 0x1d, 0x8f, 0x6d, 0x96, 0x6d, 0x36, 0x65, 0x55, 0xc8, 0xcf, 0xf1, 0x4d, 0xb9, 0xd9,
-	0x99, 0x9a, 0xf1, 0x63, 0x9b, 0x13, 0x05, 0xdb, 0x8b, 0xb4, 0x6c, 0x24, 0xc3, 0x9f, 0x0e, 0xf4,
-	0xd7, 0xac, 0x48, 0x44, 0xca, 0x76, 0x48, 0x1e, 0x41, 0x67, 0x91, 0x27, 0x11, 0x4a, 0xdf, 0x09,
-	0x9c, 0x89, 0x47, 0xad, 0x52, 0xf1, 0x35, 0x66, 0xdb, 0x54, 0xfa, 0x2d, 0x13, 0x37, 0x8a, 0x10,
-	0xf0, 0x3e, 0x95, 0x5c, 0xfa, 0x6e, 0xe0, 0x4c, 0xce, 0xa8, 0x3e, 0x13, 0x1f, 0xba, 0x8b, 0x8a,
-	0x73, 0x2c, 0xa4, 0xef, 0x05, 0xce, 0x64, 0x48, 0x6b, 0xa9, 0x32, 0x2b, 0x2c, 0x50, 0x64, 0xc2,
-	0x6f, 0x9b, 0x8c, 0x95, 0xe1, 0x2b, 0xe8, 0xbe, 0x53, 0x1f, 0xf5, 0xe1, 0xbd, 0xba, 0x72, 0xcd,
-	0x44, 0xaa, 0x3f, 0x60, 0x48, 0xf5, 0xf9, 0xae, 0xf6, 0xe1, 0x6f, 0x07, 0xc8, 0xa2, 0xcc, 0xf7,
-	0x1c, 0x85, 0xc0, 0x64, 0x99, 0xdd, 0xe0, 0x47, 0x94, 0x8c, 0x04, 0x30, 0x88, 0x24, 0xe3, 0xd2,
-	0x7a, 0x0c, 0xca, 0x69, 0x88, 0x3c, 0x81, 0xfe, 0x65, 0x91, 0x34, 0xee, 0x3c, 0x06, 0xc8, 0x18,
-	0x7a, 0xea, 0xae, 0x82, 0xe5, 0xa8, 0xc9, 0xfa, 0xf4, 0xa0, 0xeb, 0x5c, 0x94, 0x7d, 0x47, 0x8d,
-	0xe7, 0xd2, 0x83, 0x26, 0x21, 0x0c, 0x35, 0xc5, 0x75, 0x95, 0xc7, 0xc8, 0x0d, 0xa4, 0x47, 0x1b,
-	0xb1, 0xf0, 0x8b, 0xf1, 0x5f, 0x65, 0x42, 0x92, 0x17, 0xd0, 0x56, 0x67, 0xe1, 0x3b, 0x81, 0x3b,
-	0x19, 0xcc, 0xc6, 0x53, 0x33, 0xa8, 0xe9, 0xdf, 0x48, 0xd4, 0x14, 0xea, 0xf9, 0xa4, 0x55, 0xb1,
-	0x13, 0x7e, 0x2b, 0x70, 0xf5, 0x7c, 0xb4, 0x22, 0x23, 0x68, 0x5f, 0x97, 0xc5, 0xc6, 0x7c, 0xae,
-	0x47, 0x8d, 0x08, 0x5f, 0x43, 0x6f, 0x85, 0xd2, 0x38, 0x55, 0x05, 0xcb, 0x6d, 0xaf, 0x3e, 0x35,
-	0xe2, 0xe8, 0x6b, 0x9d, 0xfa, 0x66, 0xda, 0xa7, 0xaf, 0x56, 0x15, 0xfa, 0xe1, 0xec, 0x2b, 0x1a,
-	0x41, 0xce, 0xc1, 0xbd, 0x2c, 0x12, 0xeb, 0x52, 0xc7, 0xf0, 0x97, 0x03, 0xfd, 0xa8, 0x8a, 0xaf,
-	0x30, 0xd9, 0x22, 0x27, 0x17, 0xd0, 0x8d, 0x34, 0x76, 0xcd, 0xf6, 0xb0, 0x66, 0x8b, 0xec, 0x12,
-	0xea, 0x2c, 0xad, 0xab, 0xc8, 0x14, 0xba, 0x73, 0x6b, 0x68, 0x69, 0xc3, 0xa8, 0x36, 0xcc, 0xcd,
-	0x46, 0xdb, 0x7a, 0x5b, 0xa4, 0x06, 0x38, 0x8f, 0xed, 0xbb, 0x5a, 0xe8, 0x63, 0x20, 0x4c, 0xe1,
-	0xc1, 0x0a, 0x65, 0xa3, 0x95, 0x20, 0x4f, 0xc1, 0x5b, 0xf2, 0x32, 0xd7, 0x20, 0x83, 0xd9, 0xff,
-	0xf5, 0xfd, 0x76, 0xef, 0xa8, 0x4e, 0x2a, 0xdc, 0x85, 0x6a, 0x57, 0x3f, 0x88, 0x16, 0x6a, 0x71,
-	0x97, 0x25, 0xff, 0xc6, 0x78, 0xa2, 0x7b, 0xf5, 0x68, 0x2d, 0xc3, 0xb7, 0xf0, 0xdf, 0xad, 0x36,
-	0xcf, 0xa1, 0x73, 0x1f, 0x72, 0x5b, 0x14, 0xfe, 0x70, 0xe0, 0x7c, 0x85, 0xf2, 0x94, 0x52, 0xa8,
-	0x7e, 0xf3, 0x24, 0x51, 0x2b, 0x60, 0x7f, 0x06, 0xb5, 0x3c, 0x40, 0xb4, 0xee, 0x05, 0xe1, 0xde,
-	0x01, 0xe1, 0x35, 0x21, 0xde, 0xc0, 0x59, 0xb3, 0xff, 0xb3, 0x5b, 0x0c, 0xff, 0x1e, 0x86, 0xad,
-	0x89, 0x3b, 0xfa, 0x9f, 0xe4, 0xe5, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x82, 0x97, 0xa9, 0x9d,
-	0xa2, 0x04, 0x00, 0x00,
+	// 750 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0x8d, 0x55, 0x4b, 0x6f, 0x13, 0x31,
+	0x10, 0x56, 0x1e, 0x4d, 0xb2, 0x93, 0xb4, 0xb4, 0x56, 0x41, 0xab, 0x80, 0x50, 0xb5, 0x1c, 0xe8,
+	0x01, 0x52, 0x14, 0x04, 0x9c, 0x10, 0x4a, 0x53, 0xfa, 0x90, 0x4a, 0x1b, 0x79, 0x11, 0x57, 0xe4,
+	0xec, 0x5a, 0xc9, 0xd2, 0xee, 0x43, 0x5e, 0xa7, 0x20, 0x6e, 0x5c, 0xf9, 0x35, 0x9c, 0xf8, 0x09,
+	0xfc, 0x2e, 0xec, 0xb1, 0x9d, 0x47, 0x1f, 0x52, 0x6f, 0xfe, 0xe6, 0x61, 0xcf, 0xf7, 0xed, 0xcc,
+	0x2c, 0x6c, 0x5f, 0x25, 0x92, 0x17, 0xe3, 0xbd, 0x94, 0x97, 0x25, 0x9b, 0xf0, 0x5e, 0x21, 0x72,
+	0x99, 0x93, 0x86, 0xb1, 0x76, 0xbb, 0xd6, 0xcb, 0xa2, 0x28, 0x9f, 0x65, 0xf2, 0xeb, 0xf8, 0x32,
+	0x8f, 0x2e, 0x4c, 0x4c, 0xf7, 0xb1, 0xf5, 0x95, 0x19, 0x2b, 0xca, 0x69, 0xbe, 0xe2, 0x0c, 0xfe,
+	0x55, 0xc0, 0x3b, 0x66, 0x59, 0x5c, 0x4e, 0xd9, 0x05, 0x27, 0x8f, 0xa0, 0x31, 0x4c, 0xe3, 0x90,
+	0x4b, 0xbf, 0xb2, 0x53, 0xd9, 0xad, 0x53, 0x8b, 0xb4, 0xfd, 0x98, 0x27, 0x93, 0xa9, 0xf4, 0xab,
+	0xc6, 0x6e, 0x10, 0x21, 0x50, 0x1f, 0xe5, 0x42, 0xfa, 0x35, 0x65, 0x5d, 0xa7, 0x78, 0x26, 0x3e,
+	0x34, 0x87, 0x33, 0x21, 0x78, 0x26, 0xfd, 0xba, 0x32, 0x77, 0xa8, 0x83, 0xda, 0x73, 0xc4, 0x33,
+	0x5e, 0x26, 0xa5, 0xbf, 0x66, 0x3c, 0x16, 0x6a, 0xcf, 0x17, 0x2e, 0xca, 0x24, 0xcf, 0xfc, 0x86,
+	0xf2, 0x78, 0xd4, 0x41, 0xf2, 0x1c, 0xd6, 0x0e, 0x73, 0x71, 0x51, 0xfa, 0xcd, 0x9d, 0xda, 0x6e,
+	0xbb, 0xbf, 0xd5, 0x33, 0x64, 0x7a, 0xda, 0x38, 0xca, 0x93, 0x4c, 0x52, 0xe3, 0x0f, 0xde, 0x40,
+	0x73, 0x5f, 0xf3, 0x3a, 0x39, 0xd0, 0x55, 0x1d, 0xb3, 0x72, 0x8a, 0x1c, 0x3a, 0x14, 0xcf, 0x77,
+	0x31, 0x08, 0xfe, 0x54, 0x80, 0x0c, 0xf3, 0xb4, 0x10, 0x4a, 0x56, 0x1e, 0x1f, 0x26, 0x97, 0xfc,
+	0x13, 0x97, 0x8c, 0xec, 0x40, 0x3b, 0x94, 0x4c, 0x48, 0x9b, 0x63, 0xd4, 0x58, 0x36, 0x91, 0x27,
+	0xe0, 0x7d, 0xcc, 0xe2, 0x95, 0x3b, 0x17, 0x06, 0xd2, 0x85, 0x96, 0xbe, 0x2b, 0x63, 0x29, 0x47,
+	0x71, 0x3c, 0x3a, 0xc7, 0xce, 0x17, 0x26, 0x3f, 0x39, 0x2a, 0x54, 0xa3, 0x73, 0x4c, 0x02, 0xe8,
+	0x20, 0x8b, 0xb3, 0x59, 0x3a, 0x56, 0x0a, 0xa0, 0x4e, 0x75, 0xba, 0x62, 0x0b, 0xbe, 0x99, 0xfc,
+	0xd3, 0xa4, 0x94, 0xe4, 0x95, 0x92, 0x47, 0x9d, 0x4b, 0x55, 0xa1, 0x96, 0xa7, 0xeb, 0xe4, 0xb9,
+	0x49, 0x89, 0x9a, 0x40, 0xfc, 0xc4, 0xd3, 0x59, 0xa6, 0x14, 0xad, 0xaa, 0x14, 0xfd, 0x89, 0x11,
+	0x91, 0x6d, 0x58, 0x3b, 0xcb, 0xb3, 0xc8, 0x94, 0x5b, 0xa7, 0x06, 0x04, 0x6f, 0xa1, 0x75, 0xc4,
+	0xa5, 0xc9, 0xd4, 0x11, 0xaa, 0x7e, 0xf3, 0x96, 0x47, 0x0d, 0x58, 0xe4, 0x55, 0x97, 0xf3, 0xfa,
+	0x98, 0x87, 0x57, 0xeb, 0x08, 0x14, 0xce, 0xaa, 0x68, 0x00, 0xd9, 0x84, 0x9a, 0x92, 0xcb, 0x66,
+	0xe9, 0x63, 0xf0, 0x5b, 0xb5, 0x62, 0x38, 0x1b, 0x9f, 0xf2, 0x78, 0xc2, 0x05, 0xd9, 0x83, 0x66,
+	0x88, 0xb4, 0x1d, 0xb7, 0x87, 0x8e, 0x5b, 0x68, 0xfb, 0x18, 0xbd, 0xd4, 0x45, 0x91, 0x1e, 0x34,
+	0x07, 0x36, 0xa1, 0x8a, 0x09, 0xdb, 0x2e, 0x61, 0x60, 0x86, 0xc2, 0xc6, 0xdb, 0x20, 0xfd, 0x01,
+	0x07, 0x63, 0xab, 0xab, 0x25, 0xbd, 0x30, 0x04, 0x53, 0xd8, 0x52, 0x04, 0x56, 0x9e, 0x2a, 0xc9,
+	0x33, 0xa8, 0x1f, 0x8a, 0x3c, 0x45, 0x22, 0xed, 0xfe, 0x03, 0x77, 0xbf, 0xed, 0x3b, 0x8a, 0x4e,
+	0x4d, 0x77, 0xa8, 0x9f, 0x73, 0x82, 0x20, 0xd0, 0x1d, 0xae, 0xfa, 0xf4, 0x3b, 0x13, 0x31, 0xbe,
+	0xd5, 0xa2, 0x0e, 0x06, 0x1f, 0x60, 0xe3, 0xda, 0x33, 0x2f, 0xa1, 0x71, 0x1f, 0xe6, 0x36, 0x28,
+	0xf8, 0x55, 0x81, 0x4d, 0x55, 0xeb, 0x32, 0x4b, 0x9c, 0xa8, 0x41, 0x1c, 0xeb, 0x16, 0xb0, 0x63,
+	0xe0, 0xe0, 0x9c, 0x44, 0xf5, 0x5e, 0x24, 0x6a, 0x77, 0x90, 0xa8, 0xaf, 0x92, 0x78, 0x0f, 0xeb,
+	0xab, 0xef, 0xbf, 0xb8, 0xc6, 0xe1, 0xf6, 0x8f, 0xe1, 0x28, 0xbc, 0x03, 0x6f, 0x3e, 0xd0, 0x7a,
+	0x7c, 0x75, 0x6b, 0x61, 0xdd, 0x1e, 0xc5, 0xf3, 0x9d, 0xe3, 0xfb, 0xb7, 0x0a, 0x9d, 0x11, 0xe7,
+	0x42, 0xf5, 0x4f, 0x81, 0xc9, 0x1b, 0x50, 0x3d, 0x39, 0xb0, 0x94, 0xd5, 0x09, 0xf1, 0x08, 0x93,
+	0x34, 0x1e, 0xdd, 0xba, 0xb1, 0x54, 0x27, 0x7c, 0x4e, 0x54, 0x2f, 0x4b, 0x96, 0x16, 0x76, 0x22,
+	0x17, 0x06, 0xed, 0x0d, 0x93, 0x49, 0xc6, 0xe4, 0x4c, 0x70, 0xbb, 0xb7, 0x16, 0x06, 0xf2, 0x14,
+	0xe0, 0x7c, 0x5c, 0x72, 0x71, 0xc5, 0x63, 0xf5, 0x4e, 0x03, 0xdd, 0x4b, 0x16, 0x3d, 0xec, 0xe7,
+	0x05, 0x17, 0x4c, 0xe6, 0x42, 0xad, 0x30, 0x5c, 0x04, 0x0e, 0xe3, 0xa6, 0xcc, 0x33, 0xc9, 0x22,
+	0xe9, 0xb7, 0xcc, 0xd6, 0xb3, 0x50, 0xd3, 0xa5, 0x7c, 0xa2, 0xd7, 0xa1, 0x87, 0x0e, 0x8b, 0xf4,
+	0x6d, 0xa1, 0xba, 0x39, 0x89, 0xd4, 0x14, 0x02, 0x32, 0x98, 0x63, 0xa5, 0xf8, 0x96, 0x9e, 0xf3,
+	0x98, 0x49, 0xb6, 0xa8, 0xb7, 0x8d, 0x05, 0xdd, 0x74, 0x04, 0xfb, 0x56, 0xb7, 0x1f, 0xd1, 0x94,
+	0x65, 0x13, 0x4e, 0xfa, 0xb8, 0xce, 0x50, 0xc3, 0x1b, 0x9f, 0x6c, 0x59, 0x60, 0xba, 0x08, 0x1b,
+	0x37, 0xf0, 0x17, 0xf2, 0xfa, 0x3f, 0xa0, 0x51, 0x4d, 0xca, 0x9b, 0x06, 0x00, 0x00,
 }
@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/vm/abi"
 	"github.com/vitelabs/go-vite/vm/contracts"
@@ -19,7 +20,9 @@ var simpleContracts = map[types.Address]*precompiledContract{
 			cabi.MethodNameRegister:       &contracts.MethodRegister{},
 			cabi.MethodNameCancelRegister: &contracts.MethodCancelRegister{},
 			// TODO not support reward this version cabi.MethodNameReward:             &contracts.MethodReward{},
-			cabi.MethodNameUpdateRegistration: &contracts.MethodUpdateRegistration{},
+			cabi.MethodNameUpdateRegistration:          &contracts.MethodUpdateRegistration{},
+			cabi.MethodNameUpdateDisplayName:           &contracts.MethodUpdateDisplayName{},
+			cabi.MethodNameUpdateRewardWithdrawAddress: &contracts.MethodUpdateRewardWithdrawAddress{},
 		},
 		cabi.ABIRegister,
 	},
@@ -27,13 +30,18 @@ var simpleContracts = map[types.Address]*precompiledContract{
 		map[string]contracts.PrecompiledContractMethod{
 			cabi.MethodNameVote:       &contracts.MethodVote{},
 			cabi.MethodNameCancelVote: &contracts.MethodCancelVote{},
+			cabi.MethodNameSplitVote:  &contracts.MethodSplitVote{},
 		},
 		cabi.ABIVote,
 	},
 	types.AddressPledge: {
 		map[string]contracts.PrecompiledContractMethod{
-			cabi.MethodNamePledge:       &contracts.MethodPledge{},
-			cabi.MethodNameCancelPledge: &contracts.MethodCancelPledge{},
+			cabi.MethodNamePledge:            &contracts.MethodPledge{},
+			cabi.MethodNameCancelPledge:      &contracts.MethodCancelPledge{},
+			cabi.MethodNameAgentPledge:       &contracts.MethodAgentPledge{},
+			cabi.MethodNameAgentCancelPledge: &contracts.MethodAgentCancelPledge{},
+			cabi.MethodNameLockPledge:        &contracts.MethodLockPledge{},
+			cabi.MethodNameCancelLockPledge:  &contracts.MethodCancelLockPledge{},
 		},
 		cabi.ABIPledge,
 	},
@@ -58,9 +66,58 @@ var simpleContracts = map[types.Address]*precompiledContract{
 		},
 		cabi.ABIMintage,
 	},
+	types.AddressAmm: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameAmmDeposit:          &contracts.MethodAmmDeposit{},
+			cabi.MethodNameAmmWithdraw:         &contracts.MethodAmmWithdraw{},
+			cabi.MethodNameAmmWithdrawBatch:    &contracts.MethodAmmWithdrawBatch{},
+			cabi.MethodNameAmmTransfer:         &contracts.MethodAmmTransfer{},
+			cabi.MethodNameAmmCreatePool:       &contracts.MethodAmmCreatePool{},
+			cabi.MethodNameAmmAddLiquidity:     &contracts.MethodAmmAddLiquidity{},
+			cabi.MethodNameAmmRemoveLiquidity:  &contracts.MethodAmmRemoveLiquidity{},
+			cabi.MethodNameAmmSwap:             &contracts.MethodAmmSwap{},
+			cabi.MethodNameAmmSetReward:        &contracts.MethodAmmSetReward{},
+			cabi.MethodNameAmmClaimReward:      &contracts.MethodAmmClaimReward{},
+			cabi.MethodNameAmmSetDividendToken: &contracts.MethodAmmSetDividendToken{},
+			cabi.MethodNameAmmDividend:         &contracts.MethodAmmDividend{},
+		},
+		cabi.ABIAmm,
+	},
+	types.AddressAllowance: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameAllowanceDeposit:      &contracts.MethodAllowanceDeposit{},
+			cabi.MethodNameAllowanceWithdraw:     &contracts.MethodAllowanceWithdraw{},
+			cabi.MethodNameAllowanceApprove:      &contracts.MethodAllowanceApprove{},
+			cabi.MethodNameAllowanceTransferFrom: &contracts.MethodAllowanceTransferFrom{},
+		},
+		cabi.ABIAllowance,
+	},
+	types.AddressEventRegistry: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameRegisterEvent: &contracts.MethodRegisterEvent{},
+		},
+		cabi.ABIEventRegistry,
+	},
+	types.AddressCheckpoint: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameCheckpoint: &contracts.MethodCheckpoint{},
+		},
+		cabi.ABICheckpoint,
+	},
+	types.AddressQuotaLease: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameLeaseQuota: &contracts.MethodLeaseQuota{},
+		},
+		cabi.ABIQuotaLease,
+	},
 }
 
-func GetPrecompiledContract(addr types.Address, methodSelector []byte) (contracts.PrecompiledContractMethod, bool, error) {
+// GetPrecompiledContract returns the method of the built-in contract addr at the snapshot block height, the contracts
+// added by a fork are plain addresses before it
+func GetPrecompiledContract(addr types.Address, methodSelector []byte, snapshotHeight uint64) (contracts.PrecompiledContractMethod, bool, error) {
+	if !fork.IsPrecompiledContractAddress(addr, snapshotHeight) {
+		return nil, false, nil
+	}
 	p, ok := simpleContracts[addr]
 	if ok {
 		if method, err := p.abi.MethodById(methodSelector); err == nil {
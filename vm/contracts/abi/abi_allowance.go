@@ -0,0 +1,87 @@
+package abi
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"math/big"
+	"strings"
+)
+
+const (
+	jsonAllowance = `
+	[
+		{"type":"function","name":"Deposit","inputs":[]},
+		{"type":"function","name":"Withdraw","inputs":[{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"Approve","inputs":[{"name":"spender","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"TransferFrom","inputs":[{"name":"owner","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"},{"name":"to","type":"address"}]},
+		{"type":"variable","name":"allowanceAmount","inputs":[{"name":"amount","type":"uint256"}]},
+		{"type":"event","name":"approval","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]},
+		{"type":"event","name":"transfer","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"spender","type":"address","indexed":true},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"},{"name":"to","type":"address"}]}
+	]`
+
+	MethodNameAllowanceDeposit      = "Deposit"
+	MethodNameAllowanceWithdraw     = "Withdraw"
+	MethodNameAllowanceApprove      = "Approve"
+	MethodNameAllowanceTransferFrom = "TransferFrom"
+	VariableNameAllowanceAmount     = "allowanceAmount"
+	EventNameAllowanceApproval      = "approval"
+	EventNameAllowanceTransfer      = "transfer"
+)
+
+// storage key prefixes of allowance contract
+const (
+	allowanceBalanceKeyPrefix byte = 1
+	allowanceKeyPrefix        byte = 2
+)
+
+var (
+	ABIAllowance, _ = abi.JSONToABIContract(strings.NewReader(jsonAllowance))
+)
+
+type ParamAllowanceWithdraw struct {
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+}
+type ParamAllowanceApprove struct {
+	Spender types.Address
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+}
+type ParamAllowanceTransferFrom struct {
+	Owner   types.Address
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+	To      types.Address
+}
+
+type VariableAllowanceAmount struct {
+	Amount *big.Int
+}
+
+func GetAllowanceBalanceKey(owner types.Address, tokenId types.TokenTypeId) []byte {
+	key := append([]byte{allowanceBalanceKeyPrefix}, owner.Bytes()...)
+	return append(key, tokenId.Bytes()...)
+}
+func GetAllowanceKey(owner, spender types.Address, tokenId types.TokenTypeId) []byte {
+	key := append([]byte{allowanceKeyPrefix}, owner.Bytes()...)
+	key = append(key, spender.Bytes()...)
+	return append(key, tokenId.Bytes()...)
+}
+
+func getAllowanceAmount(db StorageDatabase, key []byte) *big.Int {
+	amount := new(VariableAllowanceAmount)
+	if err := ABIAllowance.UnpackVariable(amount, VariableNameAllowanceAmount, db.GetStorageBySnapshotHash(&types.AddressAllowance, key, nil)); err == nil {
+		return amount.Amount
+	}
+	return big.NewInt(0)
+}
+
+// GetAllowanceBalance returns the amount of tokenId owner deposited to allowance contract, which approved spenders can pull
+func GetAllowanceBalance(db StorageDatabase, owner types.Address, tokenId types.TokenTypeId) *big.Int {
+	return getAllowanceAmount(db, GetAllowanceBalanceKey(owner, tokenId))
+}
+
+// GetAllowance returns the amount of tokenId spender is approved to pull from the balance of owner
+func GetAllowance(db StorageDatabase, owner, spender types.Address, tokenId types.TokenTypeId) *big.Int {
+	return getAllowanceAmount(db, GetAllowanceKey(owner, spender, tokenId))
+}
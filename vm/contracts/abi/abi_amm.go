@@ -0,0 +1,429 @@
+package abi
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"math/big"
+	"strings"
+)
+
+const (
+	jsonAmm = `
+	[
+		{"type":"function","name":"Deposit","inputs":[]},
+		{"type":"function","name":"Withdraw","inputs":[{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"WithdrawBatch","inputs":[{"name":"tokenIds","type":"tokenId[]"},{"name":"amounts","type":"uint256[]"}]},
+		{"type":"function","name":"Transfer","inputs":[{"name":"to","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"CreatePool","inputs":[{"name":"tokenA","type":"tokenId"},{"name":"tokenB","type":"tokenId"}]},
+		{"type":"function","name":"AddLiquidity","inputs":[{"name":"tokenA","type":"tokenId"},{"name":"tokenB","type":"tokenId"},{"name":"amountA","type":"uint256"},{"name":"amountB","type":"uint256"},{"name":"minShares","type":"uint256"}]},
+		{"type":"function","name":"RemoveLiquidity","inputs":[{"name":"tokenA","type":"tokenId"},{"name":"tokenB","type":"tokenId"},{"name":"shares","type":"uint256"},{"name":"minAmountA","type":"uint256"},{"name":"minAmountB","type":"uint256"}]},
+		{"type":"function","name":"Swap","inputs":[{"name":"tokenOut","type":"tokenId"},{"name":"minAmountOut","type":"uint256"}]},
+		{"type":"function","name":"SetReward","inputs":[{"name":"tokenA","type":"tokenId"},{"name":"tokenB","type":"tokenId"},{"name":"rewardPerSecond","type":"uint256"}]},
+		{"type":"function","name":"ClaimReward","inputs":[{"name":"tokenA","type":"tokenId"},{"name":"tokenB","type":"tokenId"}]},
+		{"type":"function","name":"SetDividendToken","inputs":[{"name":"tokenId","type":"tokenId"}]},
+		{"type":"function","name":"Dividend","inputs":[]},
+		{"type":"variable","name":"ammPool","inputs":[{"name":"tokenA","type":"tokenId"},{"name":"tokenB","type":"tokenId"},{"name":"reserveA","type":"uint256"},{"name":"reserveB","type":"uint256"},{"name":"totalShares","type":"uint256"},{"name":"priceACumulative","type":"uint256"},{"name":"priceBCumulative","type":"uint256"},{"name":"lastTimestamp","type":"uint64"}]},
+		{"type":"variable","name":"ammAmount","inputs":[{"name":"amount","type":"uint256"}]},
+		{"type":"variable","name":"ammReward","inputs":[{"name":"rewardToken","type":"tokenId"},{"name":"owner","type":"address"},{"name":"rewardPerSecond","type":"uint256"},{"name":"rewardBalance","type":"uint256"},{"name":"accRewardPerShare","type":"uint256"},{"name":"lastTimestamp","type":"uint64"}]},
+		{"type":"variable","name":"ammRewardDebt","inputs":[{"name":"rewardDebt","type":"uint256"},{"name":"pending","type":"uint256"}]},
+		{"type":"variable","name":"ammDividend","inputs":[{"name":"dividendToken","type":"tokenId"},{"name":"owner","type":"address"},{"name":"lastTimestamp","type":"uint64"}]},
+		{"type":"event","name":"createPool","inputs":[{"name":"tokenA","type":"tokenId","indexed":true},{"name":"tokenB","type":"tokenId","indexed":true}]},
+		{"type":"event","name":"addLiquidity","inputs":[{"name":"tokenA","type":"tokenId","indexed":true},{"name":"tokenB","type":"tokenId","indexed":true},{"name":"address","type":"address"},{"name":"amountA","type":"uint256"},{"name":"amountB","type":"uint256"},{"name":"shares","type":"uint256"}]},
+		{"type":"event","name":"removeLiquidity","inputs":[{"name":"tokenA","type":"tokenId","indexed":true},{"name":"tokenB","type":"tokenId","indexed":true},{"name":"address","type":"address"},{"name":"amountA","type":"uint256"},{"name":"amountB","type":"uint256"},{"name":"shares","type":"uint256"}]},
+		{"type":"event","name":"swap","inputs":[{"name":"tokenIn","type":"tokenId","indexed":true},{"name":"tokenOut","type":"tokenId","indexed":true},{"name":"address","type":"address"},{"name":"amountIn","type":"uint256"},{"name":"amountOut","type":"uint256"}]},
+		{"type":"event","name":"setReward","inputs":[{"name":"tokenA","type":"tokenId","indexed":true},{"name":"tokenB","type":"tokenId","indexed":true},{"name":"rewardToken","type":"tokenId"},{"name":"rewardPerSecond","type":"uint256"},{"name":"amount","type":"uint256"}]},
+		{"type":"event","name":"transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]},
+		{"type":"event","name":"claimReward","inputs":[{"name":"tokenA","type":"tokenId","indexed":true},{"name":"tokenB","type":"tokenId","indexed":true},{"name":"address","type":"address"},{"name":"amount","type":"uint256"}]},
+		{"type":"event","name":"setDividendToken","inputs":[{"name":"dividendToken","type":"tokenId","indexed":true},{"name":"owner","type":"address"}]},
+		{"type":"event","name":"dividend","inputs":[{"name":"tokenId","type":"tokenId","indexed":true},{"name":"dividendToken","type":"tokenId","indexed":true},{"name":"amount","type":"uint256"},{"name":"holders","type":"uint64"}]}
+	]`
+
+	MethodNameAmmDeposit          = "Deposit"
+	MethodNameAmmWithdraw         = "Withdraw"
+	MethodNameAmmWithdrawBatch    = "WithdrawBatch"
+	MethodNameAmmTransfer         = "Transfer"
+	MethodNameAmmCreatePool       = "CreatePool"
+	MethodNameAmmAddLiquidity     = "AddLiquidity"
+	MethodNameAmmRemoveLiquidity  = "RemoveLiquidity"
+	MethodNameAmmSwap             = "Swap"
+	MethodNameAmmSetReward        = "SetReward"
+	MethodNameAmmClaimReward      = "ClaimReward"
+	MethodNameAmmSetDividendToken = "SetDividendToken"
+	MethodNameAmmDividend         = "Dividend"
+	VariableNameAmmPool           = "ammPool"
+	VariableNameAmmAmount         = "ammAmount"
+	VariableNameAmmReward         = "ammReward"
+	VariableNameAmmRewardDebt     = "ammRewardDebt"
+	VariableNameAmmDividend       = "ammDividend"
+	EventNameAmmCreatePool        = "createPool"
+	EventNameAmmAddLiquidity      = "addLiquidity"
+	EventNameAmmRemoveLiquidity   = "removeLiquidity"
+	EventNameAmmSwap              = "swap"
+	EventNameAmmSetReward         = "setReward"
+	EventNameAmmClaimReward       = "claimReward"
+	EventNameAmmTransfer          = "transfer"
+	EventNameAmmSetDividendToken  = "setDividendToken"
+	EventNameAmmDividend          = "dividend"
+)
+
+// storage key prefixes of amm contract, a pool is keyed by its token pair in ascending order
+const (
+	ammPoolKeyPrefix       byte = 1
+	ammFundKeyPrefix       byte = 2
+	ammShareKeyPrefix      byte = 3
+	ammRewardKeyPrefix     byte = 4
+	ammRewardDebtKeyPrefix byte = 5
+	ammFeePoolKeyPrefix    byte = 6
+	ammDividendKeyPrefix   byte = 7
+)
+
+var (
+	ABIAmm, _ = abi.JSONToABIContract(strings.NewReader(jsonAmm))
+)
+
+type ParamAmmWithdraw struct {
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+}
+type ParamAmmWithdrawBatch struct {
+	TokenIds []types.TokenTypeId
+	Amounts  []*big.Int
+}
+type ParamAmmTransfer struct {
+	To      types.Address
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+}
+type ParamAmmCreatePool struct {
+	TokenA types.TokenTypeId
+	TokenB types.TokenTypeId
+}
+type ParamAmmAddLiquidity struct {
+	TokenA    types.TokenTypeId
+	TokenB    types.TokenTypeId
+	AmountA   *big.Int
+	AmountB   *big.Int
+	MinShares *big.Int
+}
+type ParamAmmRemoveLiquidity struct {
+	TokenA     types.TokenTypeId
+	TokenB     types.TokenTypeId
+	Shares     *big.Int
+	MinAmountA *big.Int
+	MinAmountB *big.Int
+}
+type ParamAmmSwap struct {
+	TokenOut     types.TokenTypeId
+	MinAmountOut *big.Int
+}
+type ParamAmmClaimReward struct {
+	TokenA types.TokenTypeId
+	TokenB types.TokenTypeId
+}
+type ParamAmmSetDividendToken struct {
+	TokenId types.TokenTypeId
+}
+type ParamAmmSetReward struct {
+	TokenA          types.TokenTypeId
+	TokenB          types.TokenTypeId
+	RewardPerSecond *big.Int
+}
+
+// AmmPool is a constant-product pool of TokenA and TokenB. PriceACumulative is the sum of
+// the price of TokenA in TokenB multiplied by the seconds it lasts, in 64.64 fixed point,
+// the time weighted average price between two observations is the difference of the sums
+// divided by the seconds between them. PriceBCumulative is the same for TokenB.
+type AmmPool struct {
+	TokenA           types.TokenTypeId
+	TokenB           types.TokenTypeId
+	ReserveA         *big.Int
+	ReserveB         *big.Int
+	TotalShares      *big.Int
+	PriceACumulative *big.Int
+	PriceBCumulative *big.Int
+	LastTimestamp    uint64
+}
+
+type VariableAmmAmount struct {
+	Amount *big.Int
+}
+
+// AmmReward is the liquidity mining reward of a pool. RewardPerSecond of RewardToken is emitted
+// to the liquidity shares of the pool until RewardBalance runs out, AccRewardPerShare is the sum
+// of the reward emitted per share in 64.64 fixed point. Only Owner can change the reward.
+type AmmReward struct {
+	RewardToken       types.TokenTypeId
+	Owner             types.Address
+	RewardPerSecond   *big.Int
+	RewardBalance     *big.Int
+	AccRewardPerShare *big.Int
+	LastTimestamp     uint64
+}
+
+// Accrue emits the reward from LastTimestamp to now among totalShares, nothing is emitted while
+// the pool has no shares.
+func (r *AmmReward) Accrue(totalShares *big.Int, now uint64) {
+	if now <= r.LastTimestamp {
+		return
+	}
+	if totalShares.Sign() > 0 && r.RewardPerSecond.Sign() > 0 && r.RewardBalance.Sign() > 0 {
+		emitted := new(big.Int).Mul(r.RewardPerSecond, new(big.Int).SetUint64(now-r.LastTimestamp))
+		if emitted.Cmp(r.RewardBalance) > 0 {
+			emitted.Set(r.RewardBalance)
+		}
+		r.RewardBalance = new(big.Int).Sub(r.RewardBalance, emitted)
+		perShare := new(big.Int).Lsh(emitted, 64)
+		r.AccRewardPerShare = new(big.Int).Add(r.AccRewardPerShare, perShare.Div(perShare, totalShares))
+	}
+	r.LastTimestamp = now
+}
+
+// Earned returns the reward emitted to shares since the reward was set, in 64.64 fixed point
+func (r *AmmReward) Earned(shares *big.Int) *big.Int {
+	return new(big.Int).Mul(shares, r.AccRewardPerShare)
+}
+
+// AmmRewardDebt is the reward accounting of an address in a pool, RewardDebt is the reward earned
+// by its shares which is settled already, Pending is the settled reward not claimed yet. Both are
+// in 64.64 fixed point, so that no more than the emitted reward is claimed by rounding.
+type AmmRewardDebt struct {
+	RewardDebt *big.Int
+	Pending    *big.Int
+}
+
+// AmmDividend is the dividend of the swap fees to the holders of DividendToken, by their funds in the
+// contract. Only Owner can change DividendToken, LastTimestamp is when the fees were distributed last.
+type AmmDividend struct {
+	DividendToken types.TokenTypeId
+	Owner         types.Address
+	LastTimestamp uint64
+}
+
+// AmmHolding is an amount of a token held by an address.
+type AmmHolding struct {
+	Address types.Address
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+}
+
+// SortTokenPair returns the token pair in the order a pool is stored with, swapped is true
+// if the order is changed.
+func SortTokenPair(tokenA, tokenB types.TokenTypeId) (types.TokenTypeId, types.TokenTypeId, bool) {
+	if bytes.Compare(tokenA.Bytes(), tokenB.Bytes()) > 0 {
+		return tokenB, tokenA, true
+	}
+	return tokenA, tokenB, false
+}
+
+func GetAmmPoolKey(tokenA, tokenB types.TokenTypeId) []byte {
+	tokenA, tokenB, _ = SortTokenPair(tokenA, tokenB)
+	key := append([]byte{ammPoolKeyPrefix}, tokenA.Bytes()...)
+	return append(key, tokenB.Bytes()...)
+}
+func GetAmmFundKey(addr types.Address, tokenId types.TokenTypeId) []byte {
+	key := append([]byte{ammFundKeyPrefix}, addr.Bytes()...)
+	return append(key, tokenId.Bytes()...)
+}
+func GetAmmShareKey(tokenA, tokenB types.TokenTypeId, addr types.Address) []byte {
+	key := GetAmmPoolKey(tokenA, tokenB)
+	key[0] = ammShareKeyPrefix
+	return append(key, addr.Bytes()...)
+}
+
+func GetAmmRewardKey(tokenA, tokenB types.TokenTypeId) []byte {
+	key := GetAmmPoolKey(tokenA, tokenB)
+	key[0] = ammRewardKeyPrefix
+	return key
+}
+func GetAmmRewardDebtKey(tokenA, tokenB types.TokenTypeId, addr types.Address) []byte {
+	key := GetAmmShareKey(tokenA, tokenB, addr)
+	key[0] = ammRewardDebtKeyPrefix
+	return key
+}
+
+func GetAmmFeePoolKey(tokenId types.TokenTypeId) []byte {
+	return append([]byte{ammFeePoolKeyPrefix}, tokenId.Bytes()...)
+}
+func GetAmmDividendKey() []byte {
+	return []byte{ammDividendKeyPrefix}
+}
+
+func GetAmmPool(db StorageDatabase, tokenA, tokenB types.TokenTypeId) *AmmPool {
+	data := db.GetStorageBySnapshotHash(&types.AddressAmm, GetAmmPoolKey(tokenA, tokenB), nil)
+	if len(data) > 0 {
+		pool := new(AmmPool)
+		if err := ABIAmm.UnpackVariable(pool, VariableNameAmmPool, data); err == nil {
+			return pool
+		}
+	}
+	return nil
+}
+
+func GetAmmPoolList(db StorageDatabase) []*AmmPool {
+	iterator := db.NewStorageIteratorBySnapshotHash(&types.AddressAmm, []byte{ammPoolKeyPrefix}, nil)
+	poolList := make([]*AmmPool, 0)
+	if iterator == nil {
+		return poolList
+	}
+	for {
+		_, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		pool := new(AmmPool)
+		if err := ABIAmm.UnpackVariable(pool, VariableNameAmmPool, value); err == nil {
+			poolList = append(poolList, pool)
+		}
+	}
+	return poolList
+}
+
+func getAmmAmount(db StorageDatabase, key []byte) *big.Int {
+	amount := new(VariableAmmAmount)
+	if err := ABIAmm.UnpackVariable(amount, VariableNameAmmAmount, db.GetStorageBySnapshotHash(&types.AddressAmm, key, nil)); err == nil {
+		return amount.Amount
+	}
+	return big.NewInt(0)
+}
+
+// GetAmmFund returns the amount of tokenId addr deposited to amm contract and not used yet
+func GetAmmFund(db StorageDatabase, addr types.Address, tokenId types.TokenTypeId) *big.Int {
+	return getAmmAmount(db, GetAmmFundKey(addr, tokenId))
+}
+
+// GetAmmFundList returns the amounts of the tokens addr deposited to amm contract and not used yet, the tokens used up
+// are left out
+func GetAmmFundList(db StorageDatabase, addr types.Address) map[types.TokenTypeId]*big.Int {
+	prefix := append([]byte{ammFundKeyPrefix}, addr.Bytes()...)
+	iterator := db.NewStorageIteratorBySnapshotHash(&types.AddressAmm, prefix, nil)
+	fundList := make(map[types.TokenTypeId]*big.Int)
+	if iterator == nil {
+		return fundList
+	}
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		tokenId, err := types.BytesToTokenTypeId(key[len(prefix):])
+		if err != nil {
+			continue
+		}
+		amount := new(VariableAmmAmount)
+		if err := ABIAmm.UnpackVariable(amount, VariableNameAmmAmount, value); err == nil && amount.Amount.Sign() > 0 {
+			fundList[tokenId] = amount.Amount
+		}
+	}
+	return fundList
+}
+
+// GetAmmShares returns the liquidity shares of addr in the pool of tokenA and tokenB
+func GetAmmShares(db StorageDatabase, tokenA, tokenB types.TokenTypeId, addr types.Address) *big.Int {
+	return getAmmAmount(db, GetAmmShareKey(tokenA, tokenB, addr))
+}
+
+// GetAmmReward returns the liquidity mining reward of the pool of tokenA and tokenB, nil if it is not set
+func GetAmmReward(db StorageDatabase, tokenA, tokenB types.TokenTypeId) *AmmReward {
+	data := db.GetStorageBySnapshotHash(&types.AddressAmm, GetAmmRewardKey(tokenA, tokenB), nil)
+	if len(data) > 0 {
+		reward := new(AmmReward)
+		if err := ABIAmm.UnpackVariable(reward, VariableNameAmmReward, data); err == nil {
+			return reward
+		}
+	}
+	return nil
+}
+
+func GetAmmRewardDebt(db StorageDatabase, tokenA, tokenB types.TokenTypeId, addr types.Address) *AmmRewardDebt {
+	debt := new(AmmRewardDebt)
+	if err := ABIAmm.UnpackVariable(debt, VariableNameAmmRewardDebt, db.GetStorageBySnapshotHash(&types.AddressAmm, GetAmmRewardDebtKey(tokenA, tokenB, addr), nil)); err == nil {
+		return debt
+	}
+	return &AmmRewardDebt{big.NewInt(0), big.NewInt(0)}
+}
+
+// GetAmmPendingReward returns the reward addr can claim from the pool of tokenA and tokenB at timestamp now
+func GetAmmPendingReward(db StorageDatabase, tokenA, tokenB types.TokenTypeId, addr types.Address, now uint64) *big.Int {
+	pool := GetAmmPool(db, tokenA, tokenB)
+	reward := GetAmmReward(db, tokenA, tokenB)
+	if pool == nil || reward == nil {
+		return big.NewInt(0)
+	}
+	reward.Accrue(pool.TotalShares, now)
+	debt := GetAmmRewardDebt(db, tokenA, tokenB, addr)
+	pending := reward.Earned(GetAmmShares(db, tokenA, tokenB, addr))
+	pending.Sub(pending, debt.RewardDebt).Add(pending, debt.Pending)
+	return pending.Rsh(pending, 64)
+}
+
+// GetAmmDividend returns the dividend of the swap fees, nil if the dividend token is not set
+func GetAmmDividend(db StorageDatabase) *AmmDividend {
+	data := db.GetStorageBySnapshotHash(&types.AddressAmm, GetAmmDividendKey(), nil)
+	if len(data) > 0 {
+		dividend := new(AmmDividend)
+		if err := ABIAmm.UnpackVariable(dividend, VariableNameAmmDividend, data); err == nil {
+			return dividend
+		}
+	}
+	return nil
+}
+
+// GetAmmFeePool returns the swap fee of tokenId collected for the dividend and not distributed yet
+func GetAmmFeePool(db StorageDatabase, tokenId types.TokenTypeId) *big.Int {
+	return getAmmAmount(db, GetAmmFeePoolKey(tokenId))
+}
+
+// GetAmmFeePoolList returns the swap fees collected for the dividend and not distributed yet, in the order of
+// the storage keys
+func GetAmmFeePoolList(db StorageDatabase) []*AmmHolding {
+	prefix := []byte{ammFeePoolKeyPrefix}
+	iterator := db.NewStorageIteratorBySnapshotHash(&types.AddressAmm, prefix, nil)
+	feeList := make([]*AmmHolding, 0)
+	if iterator == nil {
+		return feeList
+	}
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		tokenId, err := types.BytesToTokenTypeId(key[len(prefix):])
+		if err != nil {
+			continue
+		}
+		amount := new(VariableAmmAmount)
+		if err := ABIAmm.UnpackVariable(amount, VariableNameAmmAmount, value); err == nil && amount.Amount.Sign() > 0 {
+			feeList = append(feeList, &AmmHolding{types.AddressAmm, tokenId, amount.Amount})
+		}
+	}
+	return feeList
+}
+
+// GetAmmFundHolders returns the funds of tokenId of all the addresses, in the order of the storage keys.
+// It iterates the funds of all the tokens.
+func GetAmmFundHolders(db StorageDatabase, tokenId types.TokenTypeId) []*AmmHolding {
+	iterator := db.NewStorageIteratorBySnapshotHash(&types.AddressAmm, []byte{ammFundKeyPrefix}, nil)
+	holders := make([]*AmmHolding, 0)
+	if iterator == nil {
+		return holders
+	}
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		if len(key) != 1+types.AddressSize+types.TokenTypeIdSize || !bytes.Equal(key[1+types.AddressSize:], tokenId.Bytes()) {
+			continue
+		}
+		addr, err := types.BytesToAddress(key[1 : 1+types.AddressSize])
+		if err != nil {
+			continue
+		}
+		amount := new(VariableAmmAmount)
+		if err := ABIAmm.UnpackVariable(amount, VariableNameAmmAmount, value); err == nil && amount.Amount.Sign() > 0 {
+			holders = append(holders, &AmmHolding{addr, tokenId, amount.Amount})
+		}
+	}
+	return holders
+}
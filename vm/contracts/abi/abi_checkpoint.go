@@ -0,0 +1,55 @@
+package abi
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"strings"
+)
+
+const (
+	jsonCheckpoint = `
+	[
+		{"type":"function","name":"Checkpoint","inputs":[{"name":"height","type":"uint64"},{"name":"stateHash","type":"bytes32"}]},
+		{"type":"variable","name":"accountCheckpoint","inputs":[{"name":"height","type":"uint64"},{"name":"stateHash","type":"bytes32"},{"name":"sendHash","type":"bytes32"}]},
+		{"type":"event","name":"checkpointed","inputs":[{"name":"owner","type":"address","indexed":true},{"name":"height","type":"uint64"}]}
+	]`
+
+	MethodNameCheckpoint          = "Checkpoint"
+	VariableNameAccountCheckpoint = "accountCheckpoint"
+	EventNameCheckpointed         = "checkpointed"
+)
+
+// storage key prefixes of checkpoint contract
+const (
+	checkpointKeyPrefix byte = 1
+)
+
+var (
+	ABICheckpoint, _ = abi.JSONToABIContract(strings.NewReader(jsonCheckpoint))
+)
+
+type ParamCheckpoint struct {
+	Height    uint64
+	StateHash types.Hash
+}
+
+// AccountCheckpoint is the latest checkpoint of an account, the state hash of its block at Height, which the owner
+// vouched for by signing the send block SendHash.
+type AccountCheckpoint struct {
+	Height    uint64
+	StateHash types.Hash
+	SendHash  types.Hash
+}
+
+func GetCheckpointKey(owner types.Address) []byte {
+	return append([]byte{checkpointKeyPrefix}, owner.Bytes()...)
+}
+
+// GetAccountCheckpoint returns the latest checkpoint of owner, nil if owner never checkpoints
+func GetAccountCheckpoint(db StorageDatabase, owner types.Address) *AccountCheckpoint {
+	checkpoint := new(AccountCheckpoint)
+	if err := ABICheckpoint.UnpackVariable(checkpoint, VariableNameAccountCheckpoint, db.GetStorageBySnapshotHash(&types.AddressCheckpoint, GetCheckpointKey(owner), nil)); err != nil {
+		return nil
+	}
+	return checkpoint
+}
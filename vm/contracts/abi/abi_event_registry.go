@@ -0,0 +1,88 @@
+package abi
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"strings"
+)
+
+const (
+	jsonEventRegistry = `
+	[
+		{"type":"function","name":"RegisterEvent","inputs":[{"name":"contract","type":"address"},{"name":"createHash","type":"bytes32"},{"name":"definition","type":"string"}]},
+		{"type":"variable","name":"eventDefinition","inputs":[{"name":"definition","type":"string"}]},
+		{"type":"event","name":"eventRegistered","inputs":[{"name":"contract","type":"address","indexed":true},{"name":"topic","type":"bytes32","indexed":true}]}
+	]`
+
+	MethodNameRegisterEvent          = "RegisterEvent"
+	VariableNameEventDefinition      = "eventDefinition"
+	EventNameEventRegistryRegistered = "eventRegistered"
+
+	// MaxEventDefinitionLength limits the length of the json definition of an event registered
+	MaxEventDefinitionLength = 1024
+)
+
+// storage key prefixes of event registry contract
+const (
+	eventDefinitionKeyPrefix byte = 1
+)
+
+var (
+	ABIEventRegistry, _ = abi.JSONToABIContract(strings.NewReader(jsonEventRegistry))
+
+	errInvalidEventDefinition = errors.New("event definition should be the json abi of exactly one non-anonymous event")
+)
+
+type ParamRegisterEvent struct {
+	Contract   types.Address
+	CreateHash types.Hash
+	Definition string
+}
+
+type VariableEventDefinition struct {
+	Definition string
+}
+
+func GetEventDefinitionKey(contract types.Address, topic types.Hash) []byte {
+	key := append([]byte{eventDefinitionKeyPrefix}, contract.Bytes()...)
+	return append(key, topic.Bytes()...)
+}
+
+// ParseEventDefinition parses the json abi of one event, e.g.
+// {"type":"event","name":"transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"amount","type":"uint256"}]}
+func ParseEventDefinition(definition string) (*abi.Event, error) {
+	if len(definition) == 0 || len(definition) > MaxEventDefinitionLength {
+		return nil, errInvalidEventDefinition
+	}
+	var fields []json.RawMessage
+	if err := json.Unmarshal([]byte("["+definition+"]"), &fields); err != nil || len(fields) != 1 {
+		return nil, errInvalidEventDefinition
+	}
+	contract, err := abi.JSONToABIContract(strings.NewReader("[" + definition + "]"))
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range contract.Events {
+		if event.Anonymous || len(event.Name) == 0 {
+			return nil, errInvalidEventDefinition
+		}
+		return &event, nil
+	}
+	return nil, errInvalidEventDefinition
+}
+
+// GetEventDefinition returns the event registered by contract whose id is topic, which is the first topic of its logs,
+// nil if not registered
+func GetEventDefinition(db StorageDatabase, contract types.Address, topic types.Hash) *abi.Event {
+	definition := new(VariableEventDefinition)
+	if err := ABIEventRegistry.UnpackVariable(definition, VariableNameEventDefinition, db.GetStorageBySnapshotHash(&types.AddressEventRegistry, GetEventDefinitionKey(contract, topic), nil)); err != nil {
+		return nil
+	}
+	event, err := ParseEventDefinition(definition.Definition)
+	if err != nil {
+		return nil
+	}
+	return event
+}
@@ -12,14 +12,24 @@ const (
 	[
 		{"type":"function","name":"Pledge", "inputs":[{"name":"beneficial","type":"address"}]},
 		{"type":"function","name":"CancelPledge","inputs":[{"name":"beneficial","type":"address"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"AgentPledge", "inputs":[{"name":"pledgeAddr","type":"address"},{"name":"beneficial","type":"address"}]},
+		{"type":"function","name":"AgentCancelPledge","inputs":[{"name":"pledgeAddr","type":"address"},{"name":"beneficial","type":"address"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"LockPledge", "inputs":[{"name":"beneficial","type":"address"},{"name":"tier","type":"uint8"}]},
+		{"type":"function","name":"CancelLockPledge","inputs":[{"name":"beneficial","type":"address"},{"name":"tier","type":"uint8"},{"name":"amount","type":"uint256"}]},
 		{"type":"variable","name":"pledgeInfo","inputs":[{"name":"amount","type":"uint256"},{"name":"withdrawHeight","type":"uint64"}]},
-		{"type":"variable","name":"pledgeBeneficial","inputs":[{"name":"amount","type":"uint256"}]}
+		{"type":"variable","name":"pledgeBeneficial","inputs":[{"name":"amount","type":"uint256"}]},
+		{"type":"variable","name":"pledgeBeneficialBonus","inputs":[{"name":"amount","type":"uint256"}]}
 	]`
 
-	MethodNamePledge             = "Pledge"
-	MethodNameCancelPledge       = "CancelPledge"
-	VariableNamePledgeInfo       = "pledgeInfo"
-	VariableNamePledgeBeneficial = "pledgeBeneficial"
+	MethodNamePledge                  = "Pledge"
+	MethodNameCancelPledge            = "CancelPledge"
+	MethodNameAgentPledge             = "AgentPledge"
+	MethodNameAgentCancelPledge       = "AgentCancelPledge"
+	MethodNameLockPledge              = "LockPledge"
+	MethodNameCancelLockPledge        = "CancelLockPledge"
+	VariableNamePledgeInfo            = "pledgeInfo"
+	VariableNamePledgeBeneficial      = "pledgeBeneficial"
+	VariableNamePledgeBeneficialBonus = "pledgeBeneficialBonus"
 )
 
 var (
@@ -33,10 +43,33 @@ type ParamCancelPledge struct {
 	Beneficial types.Address
 	Amount     *big.Int
 }
+type ParamAgentPledge struct {
+	PledgeAddr types.Address
+	Beneficial types.Address
+}
+type ParamAgentCancelPledge struct {
+	PledgeAddr types.Address
+	Beneficial types.Address
+	Amount     *big.Int
+}
+type ParamLockPledge struct {
+	Beneficial types.Address
+	Tier       uint8
+}
+type ParamCancelLockPledge struct {
+	Beneficial types.Address
+	Tier       uint8
+	Amount     *big.Int
+}
 type PledgeInfo struct {
 	Amount         *big.Int
 	WithdrawHeight uint64
 	BeneficialAddr types.Address
+	// Agent pledged on behalf of the pledge address, it is the only one to cancel and gets the funds back
+	IsAgent   bool
+	AgentAddr types.Address
+	// LockTier is the lock tier of a locked pledge, 0 if it is not locked
+	LockTier uint8
 }
 
 func GetPledgeBeneficialKey(beneficial types.Address) []byte {
@@ -48,8 +81,37 @@ func GetPledgeKey(addr types.Address, pledgeBeneficialKey []byte) []byte {
 func IsPledgeKey(key []byte) bool {
 	return len(key) == 2*types.AddressSize
 }
+
+// GetPledgeBeneficialBonusKey returns the key of the bonus of the locked pledges for beneficial
+func GetPledgeBeneficialBonusKey(beneficial types.Address) []byte {
+	return append(beneficial.Bytes(), 0)
+}
+
+// GetLockPledgeKey returns the key of the pledge of addr locked for tier
+func GetLockPledgeKey(addr types.Address, pledgeBeneficialKey []byte, tier uint8) []byte {
+	return append(GetPledgeKey(addr, pledgeBeneficialKey), tier)
+}
+func IsLockPledgeKey(key []byte) bool {
+	return len(key) == 2*types.AddressSize+1
+}
+func GetTierFromLockPledgeKey(key []byte) uint8 {
+	return key[2*types.AddressSize]
+}
+
+// GetAgentPledgeKey returns the key of the pledge agent made on behalf of addr, it is prefixed by the pledge key
+// so that the pledges of an address are iterated together.
+func GetAgentPledgeKey(addr types.Address, pledgeBeneficialKey []byte, agent types.Address) []byte {
+	return append(GetPledgeKey(addr, pledgeBeneficialKey), agent.Bytes()...)
+}
+func IsAgentPledgeKey(key []byte) bool {
+	return len(key) == 3*types.AddressSize
+}
+func GetAgentFromPledgeKey(key []byte) types.Address {
+	address, _ := types.BytesToAddress(key[2*types.AddressSize:])
+	return address
+}
 func GetBeneficialFromPledgeKey(key []byte) types.Address {
-	address, _ := types.BytesToAddress(key[types.AddressSize:])
+	address, _ := types.BytesToAddress(key[types.AddressSize : 2*types.AddressSize])
 	return address
 }
 
@@ -67,6 +129,19 @@ func GetPledgeBeneficialAmount(db StorageDatabase, beneficial types.Address) *bi
 	return big.NewInt(0)
 }
 
+// GetPledgeBeneficialBonus returns the bonus of the locked pledges for beneficial, the quota is calculated with the
+// pledge amount plus the bonus
+func GetPledgeBeneficialBonus(db StorageDatabase, beneficial types.Address) *big.Int {
+	return UnpackPledgeBeneficialBonus(db.GetStorageBySnapshotHash(&types.AddressPledge, GetPledgeBeneficialBonusKey(beneficial), nil))
+}
+func UnpackPledgeBeneficialBonus(data []byte) *big.Int {
+	bonus := new(VariablePledgeBeneficial)
+	if err := ABIPledge.UnpackVariable(bonus, VariableNamePledgeBeneficialBonus, data); err == nil {
+		return bonus.Amount
+	}
+	return big.NewInt(0)
+}
+
 func GetPledgeInfoList(db StorageDatabase, addr types.Address) ([]*PledgeInfo, *big.Int) {
 	pledgeAmount := big.NewInt(0)
 	iterator := db.NewStorageIteratorBySnapshotHash(&types.AddressPledge, addr.Bytes(), nil)
@@ -79,10 +154,16 @@ func GetPledgeInfoList(db StorageDatabase, addr types.Address) ([]*PledgeInfo, *
 		if !ok {
 			break
 		}
-		if IsPledgeKey(key) {
+		if IsPledgeKey(key) || IsAgentPledgeKey(key) || IsLockPledgeKey(key) {
 			pledgeInfo := new(PledgeInfo)
 			if err := ABIPledge.UnpackVariable(pledgeInfo, VariableNamePledgeInfo, value); err == nil && pledgeInfo.Amount != nil && pledgeInfo.Amount.Sign() > 0 {
 				pledgeInfo.BeneficialAddr = GetBeneficialFromPledgeKey(key)
+				if IsAgentPledgeKey(key) {
+					pledgeInfo.IsAgent = true
+					pledgeInfo.AgentAddr = GetAgentFromPledgeKey(key)
+				} else if IsLockPledgeKey(key) {
+					pledgeInfo.LockTier = GetTierFromLockPledgeKey(key)
+				}
 				pledgeInfoList = append(pledgeInfoList, pledgeInfo)
 				pledgeAmount.Add(pledgeAmount, pledgeInfo.Amount)
 			}
@@ -0,0 +1,131 @@
+package abi
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+	"strings"
+)
+
+const (
+	jsonQuotaLease = `
+	[
+		{"type":"function","name":"LeaseQuota","inputs":[{"name":"beneficial","type":"address"},{"name":"amount","type":"uint256"},{"name":"period","type":"uint64"}]},
+		{"type":"variable","name":"quotaLease","inputs":[{"name":"amount","type":"uint256"},{"name":"expirationHeight","type":"uint64"}]},
+		{"type":"event","name":"quotaLeased","inputs":[{"name":"lessor","type":"address","indexed":true},{"name":"beneficial","type":"address","indexed":true},{"name":"amount","type":"uint256"},{"name":"expirationHeight","type":"uint64"}]}
+	]`
+
+	MethodNameLeaseQuota   = "LeaseQuota"
+	VariableNameQuotaLease = "quotaLease"
+	EventNameQuotaLeased   = "quotaLeased"
+)
+
+// storage key prefixes of quota lease contract, a lease is kept under both the lessor and the beneficial, so that
+// the leases of an address are iterated either way
+const (
+	quotaLeaseOutKeyPrefix byte = 1
+	quotaLeaseInKeyPrefix  byte = 2
+)
+
+var (
+	ABIQuotaLease, _ = abi.JSONToABIContract(strings.NewReader(jsonQuotaLease))
+)
+
+type ParamLeaseQuota struct {
+	Beneficial types.Address
+	Amount     *big.Int
+	Period     uint64
+}
+
+// QuotaLease is a part of the pledge amount of Lessor leased to Beneficial, Beneficial gets the quota of Amount
+// and Lessor loses it until the snapshot block of ExpirationHeight.
+type QuotaLease struct {
+	Lessor           types.Address
+	Beneficial       types.Address
+	Amount           *big.Int
+	ExpirationHeight uint64
+}
+
+// IsActive tells whether the lease is in effect at the snapshot block of snapshotHeight
+func (l *QuotaLease) IsActive(snapshotHeight uint64) bool {
+	return l.ExpirationHeight > snapshotHeight
+}
+
+func GetQuotaLeaseOutPrefix(lessor types.Address) []byte {
+	return append([]byte{quotaLeaseOutKeyPrefix}, lessor.Bytes()...)
+}
+func GetQuotaLeaseInPrefix(beneficial types.Address) []byte {
+	return append([]byte{quotaLeaseInKeyPrefix}, beneficial.Bytes()...)
+}
+func GetQuotaLeaseOutKey(lessor, beneficial types.Address) []byte {
+	return append(GetQuotaLeaseOutPrefix(lessor), beneficial.Bytes()...)
+}
+func GetQuotaLeaseInKey(beneficial, lessor types.Address) []byte {
+	return append(GetQuotaLeaseInPrefix(beneficial), lessor.Bytes()...)
+}
+
+// GetQuotaLease returns the latest lease of lessor to beneficial, nil if lessor never leases to beneficial
+func GetQuotaLease(db StorageDatabase, lessor, beneficial types.Address) *QuotaLease {
+	return unpackQuotaLease(GetQuotaLeaseOutKey(lessor, beneficial), db.GetStorageBySnapshotHash(&types.AddressQuotaLease, GetQuotaLeaseOutKey(lessor, beneficial), nil))
+}
+
+// GetQuotaLeaseOutList returns the leases of lessor, including the expired ones not replaced yet
+func GetQuotaLeaseOutList(db StorageDatabase, lessor types.Address) []*QuotaLease {
+	return UnpackQuotaLeaseList(db.NewStorageIteratorBySnapshotHash(&types.AddressQuotaLease, GetQuotaLeaseOutPrefix(lessor), nil))
+}
+
+// GetQuotaLeaseInList returns the leases to beneficial, including the expired ones not replaced yet
+func GetQuotaLeaseInList(db StorageDatabase, beneficial types.Address) []*QuotaLease {
+	return UnpackQuotaLeaseList(db.NewStorageIteratorBySnapshotHash(&types.AddressQuotaLease, GetQuotaLeaseInPrefix(beneficial), nil))
+}
+
+// UnpackQuotaLeaseList reads the leases of an iterator over the storage of quota lease contract, iterator may be nil
+func UnpackQuotaLeaseList(iterator vmctxt_interface.StorageIterator) []*QuotaLease {
+	leaseList := make([]*QuotaLease, 0)
+	if iterator == nil {
+		return leaseList
+	}
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		if lease := unpackQuotaLease(key, value); lease != nil {
+			leaseList = append(leaseList, lease)
+		}
+	}
+	return leaseList
+}
+
+func unpackQuotaLease(key, value []byte) *QuotaLease {
+	if len(key) != 1+2*types.AddressSize || len(value) == 0 {
+		return nil
+	}
+	lease := new(QuotaLease)
+	if err := ABIQuotaLease.UnpackVariable(lease, VariableNameQuotaLease, value); err != nil {
+		return nil
+	}
+	first, _ := types.BytesToAddress(key[1 : 1+types.AddressSize])
+	second, _ := types.BytesToAddress(key[1+types.AddressSize:])
+	switch key[0] {
+	case quotaLeaseOutKeyPrefix:
+		lease.Lessor, lease.Beneficial = first, second
+	case quotaLeaseInKeyPrefix:
+		lease.Lessor, lease.Beneficial = second, first
+	default:
+		return nil
+	}
+	return lease
+}
+
+// GetActiveQuotaLeaseAmount sums the amounts of the leases in effect at the snapshot block of snapshotHeight
+func GetActiveQuotaLeaseAmount(leaseList []*QuotaLease, snapshotHeight uint64) *big.Int {
+	amount := big.NewInt(0)
+	for _, lease := range leaseList {
+		if lease.IsActive(snapshotHeight) {
+			amount.Add(amount, lease.Amount)
+		}
+	}
+	return amount
+}
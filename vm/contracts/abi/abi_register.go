@@ -16,16 +16,24 @@ const (
 		{"type":"function","name":"UpdateRegistration", "inputs":[{"name":"gid","type":"gid"},{"Name":"name","type":"string"},{"name":"nodeAddr","type":"address"}]},
 		{"type":"function","name":"CancelRegister","inputs":[{"name":"gid","type":"gid"}, {"name":"name","type":"string"}]},
 		{"type":"function","name":"Reward","inputs":[{"name":"gid","type":"gid"},{"name":"name","type":"string"},{"name":"beneficialAddr","type":"address"}]},
+		{"type":"function","name":"UpdateDisplayName","inputs":[{"name":"gid","type":"gid"},{"name":"name","type":"string"},{"name":"displayName","type":"string"}]},
+		{"type":"function","name":"UpdateRewardWithdrawAddress","inputs":[{"name":"gid","type":"gid"},{"name":"name","type":"string"},{"name":"withdrawAddr","type":"address"}]},
 		{"type":"variable","name":"registration","inputs":[{"name":"name","type":"string"},{"name":"nodeAddr","type":"address"},{"name":"pledgeAddr","type":"address"},{"name":"amount","type":"uint256"},{"name":"withdrawHeight","type":"uint64"},{"name":"rewardIndex","type":"uint64"},{"name":"cancelHeight","type":"uint64"},{"name":"hisAddrList","type":"address[]"}]},
-		{"type":"variable","name":"hisName","inputs":[{"name":"name","type":"string"}]}
+		{"type":"variable","name":"hisName","inputs":[{"name":"name","type":"string"}]},
+		{"type":"variable","name":"displayName","inputs":[{"name":"displayName","type":"string"}]},
+		{"type":"variable","name":"rewardWithdrawAddr","inputs":[{"name":"withdrawAddr","type":"address"}]}
 	]`
 
-	MethodNameRegister           = "Register"
-	MethodNameCancelRegister     = "CancelRegister"
-	MethodNameReward             = "Reward"
-	MethodNameUpdateRegistration = "UpdateRegistration"
-	VariableNameRegistration     = "registration"
-	VariableNameHisName          = "hisName"
+	MethodNameRegister                    = "Register"
+	MethodNameCancelRegister              = "CancelRegister"
+	MethodNameReward                      = "Reward"
+	MethodNameUpdateRegistration          = "UpdateRegistration"
+	MethodNameUpdateDisplayName           = "UpdateDisplayName"
+	MethodNameUpdateRewardWithdrawAddress = "UpdateRewardWithdrawAddress"
+	VariableNameRegistration              = "registration"
+	VariableNameHisName                   = "hisName"
+	VariableNameDisplayName               = "displayName"
+	VariableNameRewardWithdrawAddr        = "rewardWithdrawAddr"
 )
 
 var (
@@ -46,11 +54,33 @@ type ParamReward struct {
 	Name           string
 	BeneficialAddr types.Address
 }
+type ParamUpdateDisplayName struct {
+	Gid         types.Gid
+	Name        string
+	DisplayName string
+}
+type ParamUpdateRewardWithdrawAddress struct {
+	Gid          types.Gid
+	Name         string
+	WithdrawAddr types.Address
+}
 
 func GetRegisterKey(name string, gid types.Gid) []byte {
 	return append(gid.Bytes(), types.DataHash([]byte(name)).Bytes()[types.GidSize:]...)
 }
 
+// GetDisplayNameKey returns the key of the display name of a registration, the name registered never changes
+// for the votes refer to it
+func GetDisplayNameKey(name string, gid types.Gid) []byte {
+	return append(GetRegisterKey(name, gid), 1)
+}
+
+// GetRewardWithdrawAddrKey returns the key of the address which may withdraw the reward of a registration
+// besides the pledge address
+func GetRewardWithdrawAddrKey(name string, gid types.Gid) []byte {
+	return append(GetRegisterKey(name, gid), 2)
+}
+
 func GetHisNameKey(addr types.Address, gid types.Gid) []byte {
 	return append(addr.Bytes(), gid.Bytes()...)
 }
@@ -132,3 +162,21 @@ func GetRegistration(db StorageDatabase, gid types.Gid, name string) *types.Regi
 	}
 	return nil
 }
+
+// GetDisplayName returns the display name of a registration, empty if it is not set
+func GetDisplayName(db StorageDatabase, gid types.Gid, name string) string {
+	displayName := new(string)
+	if err := ABIRegister.UnpackVariable(displayName, VariableNameDisplayName, db.GetStorageBySnapshotHash(&types.AddressRegister, GetDisplayNameKey(name, gid), nil)); err == nil {
+		return *displayName
+	}
+	return ""
+}
+
+// GetRewardWithdrawAddr returns the reward withdraw address of a registration, nil if it is not set
+func GetRewardWithdrawAddr(db StorageDatabase, gid types.Gid, name string) *types.Address {
+	withdrawAddr := new(types.Address)
+	if err := ABIRegister.UnpackVariable(withdrawAddr, VariableNameRewardWithdrawAddr, db.GetStorageBySnapshotHash(&types.AddressRegister, GetRewardWithdrawAddrKey(name, gid), nil)); err == nil {
+		return withdrawAddr
+	}
+	return nil
+}
@@ -14,12 +14,20 @@ const (
 	[
 		{"type":"function","name":"Vote", "inputs":[{"name":"gid","type":"gid"},{"name":"nodeName","type":"string"}]},
 		{"type":"function","name":"CancelVote","inputs":[{"name":"gid","type":"gid"}]},
-		{"type":"variable","name":"voteStatus","inputs":[{"name":"nodeName","type":"string"}]}
+		{"type":"function","name":"SplitVote","inputs":[{"name":"gid","type":"gid"},{"name":"nodeNames","type":"string"},{"name":"weightList","type":"uint64[]"}]},
+		{"type":"variable","name":"voteStatus","inputs":[{"name":"nodeName","type":"string"}]},
+		{"type":"variable","name":"splitVoteStatus","inputs":[{"name":"nodeNames","type":"string"},{"name":"weightList","type":"uint64[]"}]}
 	]`
 
-	MethodNameVote         = "Vote"
-	MethodNameCancelVote   = "CancelVote"
-	VariableNameVoteStatus = "voteStatus"
+	MethodNameVote              = "Vote"
+	MethodNameCancelVote        = "CancelVote"
+	MethodNameSplitVote         = "SplitVote"
+	VariableNameVoteStatus      = "voteStatus"
+	VariableNameSplitVoteStatus = "splitVoteStatus"
+
+	// SplitVoteNodeNameSeparator joins the node names of a split vote, the abi does not unpack string arrays and
+	// a registration name never contains it
+	SplitVoteNodeNameSeparator = ","
 )
 
 var (
@@ -31,12 +39,41 @@ type ParamVote struct {
 	NodeName string
 }
 
+type ParamSplitVote struct {
+	Gid        types.Gid
+	NodeNames  string
+	WeightList []uint64
+}
+
+type VariableSplitVoteStatus struct {
+	NodeNames  string
+	WeightList []uint64
+}
+
+// SplitVoteNodeNameList returns the node names joined by SplitVoteNodeNameSeparator
+func SplitVoteNodeNameList(nodeNames string) []string {
+	if len(nodeNames) == 0 {
+		return nil
+	}
+	return strings.Split(nodeNames, SplitVoteNodeNameSeparator)
+}
+
 func GetVoteKey(addr types.Address, gid types.Gid) []byte {
 	return append(gid.Bytes(), addr.Bytes()...)
 }
 
+// GetSplitVoteKey returns the key of the votes of an address split across several nodes, an address either votes
+// for a single node or splits its votes
+func GetSplitVoteKey(addr types.Address, gid types.Gid) []byte {
+	return append(GetVoteKey(addr, gid), 1)
+}
+
+func IsSplitVoteKey(key []byte) bool {
+	return len(key) == types.GidSize+types.AddressSize+1
+}
+
 func GetAddrFromVoteKey(key []byte) types.Address {
-	addr, _ := types.BytesToAddress(key[types.GidSize:])
+	addr, _ := types.BytesToAddress(key[types.GidSize : types.GidSize+types.AddressSize])
 	return addr
 }
 
@@ -46,11 +83,37 @@ func GetVote(db StorageDatabase, gid types.Gid, addr types.Address) *types.VoteI
 	if len(data) > 0 {
 		nodeName := new(string)
 		ABIVote.UnpackVariable(nodeName, VariableNameVoteStatus, data)
-		return &types.VoteInfo{addr, *nodeName}
+		return &types.VoteInfo{VoterAddr: addr, NodeName: *nodeName, Weight: types.VoteWeightTotal}
 	}
 	return nil
 }
 
+// GetSplitVote returns the votes of an address split across several nodes, nil if the votes are not split
+func GetSplitVote(db StorageDatabase, gid types.Gid, addr types.Address) []*types.VoteInfo {
+	defer monitor.LogTime("vm", "GetSplitVote", time.Now())
+	data := db.GetStorageBySnapshotHash(&types.AddressVote, GetSplitVoteKey(addr, gid), nil)
+	if len(data) > 0 {
+		return unpackSplitVote(addr, data)
+	}
+	return nil
+}
+
+func unpackSplitVote(addr types.Address, data []byte) []*types.VoteInfo {
+	status := new(VariableSplitVoteStatus)
+	if err := ABIVote.UnpackVariable(status, VariableNameSplitVoteStatus, data); err != nil {
+		return nil
+	}
+	nodeNameList := SplitVoteNodeNameList(status.NodeNames)
+	if len(nodeNameList) != len(status.WeightList) {
+		return nil
+	}
+	voteInfoList := make([]*types.VoteInfo, len(nodeNameList))
+	for i, nodeName := range nodeNameList {
+		voteInfoList[i] = &types.VoteInfo{VoterAddr: addr, NodeName: nodeName, Weight: status.WeightList[i]}
+	}
+	return voteInfoList
+}
+
 func GetVoteList(db StorageDatabase, gid types.Gid, snapshotHash *types.Hash) []*types.VoteInfo {
 	defer monitor.LogTime("vm", "GetVoteList", time.Now())
 	var iterator vmctxt_interface.StorageIterator
@@ -69,9 +132,13 @@ func GetVoteList(db StorageDatabase, gid types.Gid, snapshotHash *types.Hash) []
 			break
 		}
 		voterAddr := GetAddrFromVoteKey(key)
+		if IsSplitVoteKey(key) {
+			voteInfoList = append(voteInfoList, unpackSplitVote(voterAddr, value)...)
+			continue
+		}
 		nodeName := new(string)
 		if err := ABIVote.UnpackVariable(nodeName, VariableNameVoteStatus, value); err == nil {
-			voteInfoList = append(voteInfoList, &types.VoteInfo{voterAddr, *nodeName})
+			voteInfoList = append(voteInfoList, &types.VoteInfo{VoterAddr: voterAddr, NodeName: *nodeName, Weight: types.VoteWeightTotal})
 		}
 	}
 	return voteInfoList
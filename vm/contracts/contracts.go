@@ -3,6 +3,7 @@ package contracts
 import (
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
 	"math/big"
 )
@@ -34,8 +35,8 @@ type PrecompiledContractMethod interface {
 	GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error)
 	// calc and use quota, check tx data
 	DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error)
-	// check status, update state
-	DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error)
+	// check status, update state, use quota of meter in proportion to the work done
+	DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error)
 	// refund data at receive error
 	GetRefundData() []byte
 	GetQuota() uint64
@@ -0,0 +1,199 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+var (
+	errAllowanceInsufficientBalance   = errors.New("insufficient allowance balance")
+	errAllowanceInsufficientAllowance = errors.New("insufficient allowance")
+)
+
+// checkAllowanceSend checks the common conditions of sending a transaction to allowance contract
+func checkAllowanceSend(db vmctxt_interface.VmDatabase, quotaLeft, quota uint64) (uint64, error) {
+	if !fork.IsAllowanceFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	return util.UseQuota(quotaLeft, quota)
+}
+
+func saveAllowanceAmount(db vmctxt_interface.VmDatabase, key []byte, amount *big.Int) {
+	if amount.Sign() == 0 {
+		db.SetStorage(key, nil)
+		return
+	}
+	data, _ := cabi.ABIAllowance.PackVariable(cabi.VariableNameAllowanceAmount, amount)
+	db.SetStorage(key, data)
+}
+
+type MethodAllowanceDeposit struct{}
+
+func (p *MethodAllowanceDeposit) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAllowanceDeposit) GetRefundData() []byte {
+	return []byte{1}
+}
+func (p *MethodAllowanceDeposit) GetQuota() uint64 {
+	return AllowanceDepositGas
+}
+func (p *MethodAllowanceDeposit) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAllowanceSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() <= 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAllowance.PackMethod(cabi.MethodNameAllowanceDeposit)
+	return quotaLeft, nil
+}
+func (p *MethodAllowanceDeposit) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	balance := cabi.GetAllowanceBalance(db, sendBlock.AccountAddress, sendBlock.TokenId)
+	saveAllowanceAmount(db, cabi.GetAllowanceBalanceKey(sendBlock.AccountAddress, sendBlock.TokenId), balance.Add(balance, sendBlock.Amount))
+	return nil, nil
+}
+
+type MethodAllowanceWithdraw struct{}
+
+func (p *MethodAllowanceWithdraw) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAllowanceWithdraw) GetRefundData() []byte {
+	return []byte{2}
+}
+func (p *MethodAllowanceWithdraw) GetQuota() uint64 {
+	return AllowanceWithdrawGas
+}
+func (p *MethodAllowanceWithdraw) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAllowanceSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAllowanceWithdraw)
+	if err = cabi.ABIAllowance.UnpackMethod(param, cabi.MethodNameAllowanceWithdraw, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.Amount.Sign() <= 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAllowance.PackMethod(cabi.MethodNameAllowanceWithdraw, param.TokenId, param.Amount)
+	return quotaLeft, nil
+}
+func (p *MethodAllowanceWithdraw) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAllowanceWithdraw)
+	cabi.ABIAllowance.UnpackMethod(param, cabi.MethodNameAllowanceWithdraw, sendBlock.Data)
+	balance := cabi.GetAllowanceBalance(db, sendBlock.AccountAddress, param.TokenId)
+	if balance.Cmp(param.Amount) < 0 {
+		return nil, errAllowanceInsufficientBalance
+	}
+	saveAllowanceAmount(db, cabi.GetAllowanceBalanceKey(sendBlock.AccountAddress, param.TokenId), balance.Sub(balance, param.Amount))
+	return []*SendBlock{
+		{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			param.Amount,
+			param.TokenId,
+			[]byte{},
+		},
+	}, nil
+}
+
+type MethodAllowanceApprove struct{}
+
+func (p *MethodAllowanceApprove) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAllowanceApprove) GetRefundData() []byte {
+	return []byte{3}
+}
+func (p *MethodAllowanceApprove) GetQuota() uint64 {
+	return AllowanceApproveGas
+}
+func (p *MethodAllowanceApprove) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAllowanceSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAllowanceApprove)
+	if err = cabi.ABIAllowance.UnpackMethod(param, cabi.MethodNameAllowanceApprove, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.Spender == block.AccountAddress {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAllowance.PackMethod(cabi.MethodNameAllowanceApprove, param.Spender, param.TokenId, param.Amount)
+	return quotaLeft, nil
+}
+
+// DoReceive replaces the allowance of the spender, a zero amount revokes it.
+func (p *MethodAllowanceApprove) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAllowanceApprove)
+	cabi.ABIAllowance.UnpackMethod(param, cabi.MethodNameAllowanceApprove, sendBlock.Data)
+	saveAllowanceAmount(db, cabi.GetAllowanceKey(sendBlock.AccountAddress, param.Spender, param.TokenId), param.Amount)
+	db.AddLog(util.NewLog(cabi.ABIAllowance, cabi.EventNameAllowanceApproval, sendBlock.AccountAddress, param.Spender, param.TokenId, param.Amount))
+	return nil, nil
+}
+
+type MethodAllowanceTransferFrom struct{}
+
+func (p *MethodAllowanceTransferFrom) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAllowanceTransferFrom) GetRefundData() []byte {
+	return []byte{4}
+}
+func (p *MethodAllowanceTransferFrom) GetQuota() uint64 {
+	return AllowanceTransferFromGas
+}
+func (p *MethodAllowanceTransferFrom) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAllowanceSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAllowanceTransferFrom)
+	if err = cabi.ABIAllowance.UnpackMethod(param, cabi.MethodNameAllowanceTransferFrom, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.Amount.Sign() <= 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAllowance.PackMethod(cabi.MethodNameAllowanceTransferFrom, param.Owner, param.TokenId, param.Amount, param.To)
+	return quotaLeft, nil
+}
+
+// DoReceive pulls the amount from the deposited balance of the owner to the address To, the sender
+// must be approved by the owner for at least the amount.
+func (p *MethodAllowanceTransferFrom) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAllowanceTransferFrom)
+	cabi.ABIAllowance.UnpackMethod(param, cabi.MethodNameAllowanceTransferFrom, sendBlock.Data)
+	allowance := cabi.GetAllowance(db, param.Owner, sendBlock.AccountAddress, param.TokenId)
+	if allowance.Cmp(param.Amount) < 0 {
+		return nil, errAllowanceInsufficientAllowance
+	}
+	balance := cabi.GetAllowanceBalance(db, param.Owner, param.TokenId)
+	if balance.Cmp(param.Amount) < 0 {
+		return nil, errAllowanceInsufficientBalance
+	}
+	saveAllowanceAmount(db, cabi.GetAllowanceKey(param.Owner, sendBlock.AccountAddress, param.TokenId), allowance.Sub(allowance, param.Amount))
+	saveAllowanceAmount(db, cabi.GetAllowanceBalanceKey(param.Owner, param.TokenId), balance.Sub(balance, param.Amount))
+
+	db.AddLog(util.NewLog(cabi.ABIAllowance, cabi.EventNameAllowanceTransfer, param.Owner, sendBlock.AccountAddress, param.TokenId, param.Amount, param.To))
+	return []*SendBlock{
+		{
+			block,
+			param.To,
+			ledger.BlockTypeSendCall,
+			param.Amount,
+			param.TokenId,
+			[]byte{},
+		},
+	}, nil
+}
@@ -0,0 +1,857 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+var (
+	errAmmPoolExist          = errors.New("amm pool already exists")
+	errAmmPoolNotExist       = errors.New("amm pool not exists")
+	errAmmInsufficientFund   = errors.New("insufficient amm fund")
+	errAmmInsufficientShares = errors.New("insufficient amm shares")
+	errAmmInsufficientOutput = errors.New("insufficient amm output amount")
+	errAmmRewardNotOwner     = errors.New("not the owner of amm reward")
+	errAmmDividendNotOwner   = errors.New("not the owner of amm dividend")
+	errAmmDividendNotSet     = errors.New("amm dividend token not set")
+	errAmmDividendTooEarly   = errors.New("amm dividend period not passed")
+)
+
+// checkAmmSend checks the common conditions of sending a transaction to amm contract
+func checkAmmSend(db vmctxt_interface.VmDatabase, quotaLeft, quota uint64) (uint64, error) {
+	if !fork.IsAmmFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	return util.UseQuota(quotaLeft, quota)
+}
+
+func ammTimestamp(db vmctxt_interface.VmDatabase) uint64 {
+	return uint64(db.CurrentSnapshotBlock().Timestamp.Unix())
+}
+
+// updateAmmCumulative accumulates the prices of the reserves before they are changed at timestamp now
+func updateAmmCumulative(pool *cabi.AmmPool, now uint64) {
+	if now <= pool.LastTimestamp {
+		return
+	}
+	if pool.ReserveA.Sign() > 0 && pool.ReserveB.Sign() > 0 {
+		elapsed := new(big.Int).SetUint64(now - pool.LastTimestamp)
+		priceA := new(big.Int).Lsh(pool.ReserveB, 64)
+		priceA.Div(priceA, pool.ReserveA)
+		priceB := new(big.Int).Lsh(pool.ReserveA, 64)
+		priceB.Div(priceB, pool.ReserveB)
+		// overflow is desired, the differences of the sums are still right
+		pool.PriceACumulative = new(big.Int).And(priceA.Mul(priceA, elapsed).Add(priceA, pool.PriceACumulative), helper.Tt256m1)
+		pool.PriceBCumulative = new(big.Int).And(priceB.Mul(priceB, elapsed).Add(priceB, pool.PriceBCumulative), helper.Tt256m1)
+	}
+	pool.LastTimestamp = now
+}
+
+// getAmmAmountOut returns the output amount of swapping amountIn into a pool, the fee is
+// taken from amountIn and left in the pool.
+func getAmmAmountOut(amountIn, reserveIn, reserveOut *big.Int) *big.Int {
+	amountInWithFee := new(big.Int).Mul(amountIn, new(big.Int).Sub(ammFeeBase, ammFeeRate))
+	numerator := new(big.Int).Mul(amountInWithFee, reserveOut)
+	denominator := new(big.Int).Mul(reserveIn, ammFeeBase)
+	denominator.Add(denominator, amountInWithFee)
+	return numerator.Div(numerator, denominator)
+}
+
+func saveAmmPool(db vmctxt_interface.VmDatabase, pool *cabi.AmmPool) {
+	data, _ := cabi.ABIAmm.PackVariable(
+		cabi.VariableNameAmmPool,
+		pool.TokenA,
+		pool.TokenB,
+		pool.ReserveA,
+		pool.ReserveB,
+		pool.TotalShares,
+		pool.PriceACumulative,
+		pool.PriceBCumulative,
+		pool.LastTimestamp)
+	db.SetStorage(cabi.GetAmmPoolKey(pool.TokenA, pool.TokenB), data)
+}
+
+func saveAmmAmount(db vmctxt_interface.VmDatabase, key []byte, amount *big.Int) {
+	if amount.Sign() == 0 {
+		db.SetStorage(key, nil)
+		return
+	}
+	data, _ := cabi.ABIAmm.PackVariable(cabi.VariableNameAmmAmount, amount)
+	db.SetStorage(key, data)
+}
+
+func saveAmmReward(db vmctxt_interface.VmDatabase, tokenA, tokenB types.TokenTypeId, reward *cabi.AmmReward) {
+	data, _ := cabi.ABIAmm.PackVariable(
+		cabi.VariableNameAmmReward,
+		reward.RewardToken,
+		reward.Owner,
+		reward.RewardPerSecond,
+		reward.RewardBalance,
+		reward.AccRewardPerShare,
+		reward.LastTimestamp)
+	db.SetStorage(cabi.GetAmmRewardKey(tokenA, tokenB), data)
+}
+
+// settleAmmReward accrues the reward of pool and settles the reward earned by oldShares of addr,
+// the reward debt is reset to newShares. It must be called before the shares of pool change.
+// If claim is true, the whole units of the settled reward are returned and the fraction is kept
+// pending, otherwise all of it is kept pending.
+func settleAmmReward(db vmctxt_interface.VmDatabase, pool *cabi.AmmPool, addr types.Address, oldShares, newShares *big.Int, claim bool) (*cabi.AmmReward, *big.Int) {
+	reward := cabi.GetAmmReward(db, pool.TokenA, pool.TokenB)
+	if reward == nil {
+		return nil, big.NewInt(0)
+	}
+	reward.Accrue(pool.TotalShares, ammTimestamp(db))
+	saveAmmReward(db, pool.TokenA, pool.TokenB, reward)
+
+	debt := cabi.GetAmmRewardDebt(db, pool.TokenA, pool.TokenB, addr)
+	pending := reward.Earned(oldShares)
+	pending.Sub(pending, debt.RewardDebt).Add(pending, debt.Pending)
+	debt.RewardDebt = reward.Earned(newShares)
+	debt.Pending = pending
+	amount := big.NewInt(0)
+	if claim {
+		amount.Rsh(pending, 64)
+		debt.Pending = new(big.Int).Sub(pending, new(big.Int).Lsh(amount, 64))
+	}
+	key := cabi.GetAmmRewardDebtKey(pool.TokenA, pool.TokenB, addr)
+	if debt.RewardDebt.Sign() == 0 && debt.Pending.Sign() == 0 {
+		db.SetStorage(key, nil)
+	} else {
+		data, _ := cabi.ABIAmm.PackVariable(cabi.VariableNameAmmRewardDebt, debt.RewardDebt, debt.Pending)
+		db.SetStorage(key, data)
+	}
+	return reward, amount
+}
+
+func saveAmmDividend(db vmctxt_interface.VmDatabase, dividend *cabi.AmmDividend) {
+	data, _ := cabi.ABIAmm.PackVariable(cabi.VariableNameAmmDividend, dividend.DividendToken, dividend.Owner, dividend.LastTimestamp)
+	db.SetStorage(cabi.GetAmmDividendKey(), data)
+}
+
+func isTokenExist(db vmctxt_interface.VmDatabase, tokenId types.TokenTypeId) bool {
+	return cabi.GetTokenById(db, tokenId) != nil
+}
+
+type MethodAmmDeposit struct{}
+
+func (p *MethodAmmDeposit) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmDeposit) GetRefundData() []byte {
+	return []byte{1}
+}
+func (p *MethodAmmDeposit) GetQuota() uint64 {
+	return AmmDepositGas
+}
+func (p *MethodAmmDeposit) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() <= 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmDeposit)
+	return quotaLeft, nil
+}
+func (p *MethodAmmDeposit) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	fund := cabi.GetAmmFund(db, sendBlock.AccountAddress, sendBlock.TokenId)
+	saveAmmAmount(db, cabi.GetAmmFundKey(sendBlock.AccountAddress, sendBlock.TokenId), fund.Add(fund, sendBlock.Amount))
+	return nil, nil
+}
+
+type MethodAmmWithdraw struct{}
+
+func (p *MethodAmmWithdraw) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmWithdraw) GetRefundData() []byte {
+	return []byte{2}
+}
+func (p *MethodAmmWithdraw) GetQuota() uint64 {
+	return AmmWithdrawGas
+}
+func (p *MethodAmmWithdraw) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmWithdraw)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmWithdraw, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.Amount.Sign() <= 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmWithdraw, param.TokenId, param.Amount)
+	return quotaLeft, nil
+}
+func (p *MethodAmmWithdraw) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmWithdraw)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmWithdraw, sendBlock.Data)
+	fund := cabi.GetAmmFund(db, sendBlock.AccountAddress, param.TokenId)
+	if fund.Cmp(param.Amount) < 0 {
+		return nil, errAmmInsufficientFund
+	}
+	saveAmmAmount(db, cabi.GetAmmFundKey(sendBlock.AccountAddress, param.TokenId), fund.Sub(fund, param.Amount))
+	return []*SendBlock{
+		{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			param.Amount,
+			param.TokenId,
+			[]byte{},
+		},
+	}, nil
+}
+
+// MethodAmmWithdrawBatch withdraws several tokens from the funds at once, all or none of them.
+type MethodAmmWithdrawBatch struct{}
+
+func (p *MethodAmmWithdrawBatch) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmWithdrawBatch) GetRefundData() []byte {
+	return []byte{9}
+}
+func (p *MethodAmmWithdrawBatch) GetQuota() uint64 {
+	return AmmWithdrawBatchGas
+}
+func (p *MethodAmmWithdrawBatch) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmWithdrawBatch)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmWithdrawBatch, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || len(param.TokenIds) == 0 || len(param.TokenIds) > ammWithdrawBatchMax || len(param.TokenIds) != len(param.Amounts) {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	tokenIds := make(map[types.TokenTypeId]struct{}, len(param.TokenIds))
+	for i, tokenId := range param.TokenIds {
+		if _, ok := tokenIds[tokenId]; ok || param.Amounts[i].Sign() <= 0 {
+			return quotaLeft, util.ErrInvalidMethodParam
+		}
+		tokenIds[tokenId] = struct{}{}
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmWithdrawBatch, param.TokenIds, param.Amounts)
+	return quotaLeft, nil
+}
+func (p *MethodAmmWithdrawBatch) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmWithdrawBatch)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmWithdrawBatch, sendBlock.Data)
+	if err := meter.Use(AmmWithdrawPerTokenGas, uint64(len(param.TokenIds))); err != nil {
+		return nil, err
+	}
+	funds := make([]*big.Int, len(param.TokenIds))
+	for i, tokenId := range param.TokenIds {
+		funds[i] = cabi.GetAmmFund(db, sendBlock.AccountAddress, tokenId)
+		if funds[i].Cmp(param.Amounts[i]) < 0 {
+			return nil, errAmmInsufficientFund
+		}
+	}
+	sendBlocks := make([]*SendBlock, 0, len(param.TokenIds))
+	for i, tokenId := range param.TokenIds {
+		saveAmmAmount(db, cabi.GetAmmFundKey(sendBlock.AccountAddress, tokenId), funds[i].Sub(funds[i], param.Amounts[i]))
+		sendBlocks = append(sendBlocks, &SendBlock{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			param.Amounts[i],
+			tokenId,
+			[]byte{},
+		})
+	}
+	return sendBlocks, nil
+}
+
+// MethodAmmTransfer moves funds of the sender to another address inside the contract, without sending the tokens.
+type MethodAmmTransfer struct{}
+
+func (p *MethodAmmTransfer) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmTransfer) GetRefundData() []byte {
+	return []byte{10}
+}
+func (p *MethodAmmTransfer) GetQuota() uint64 {
+	return AmmTransferGas
+}
+func (p *MethodAmmTransfer) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmTransfer)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmTransfer, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.Amount.Sign() <= 0 || param.To == block.AccountAddress {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmTransfer, param.To, param.TokenId, param.Amount)
+	return quotaLeft, nil
+}
+
+// DoReceive only moves the funds of the signer of the send block, so no other permission is checked.
+func (p *MethodAmmTransfer) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmTransfer)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmTransfer, sendBlock.Data)
+	fund := cabi.GetAmmFund(db, sendBlock.AccountAddress, param.TokenId)
+	if fund.Cmp(param.Amount) < 0 {
+		return nil, errAmmInsufficientFund
+	}
+	saveAmmAmount(db, cabi.GetAmmFundKey(sendBlock.AccountAddress, param.TokenId), fund.Sub(fund, param.Amount))
+	toFund := cabi.GetAmmFund(db, param.To, param.TokenId)
+	saveAmmAmount(db, cabi.GetAmmFundKey(param.To, param.TokenId), toFund.Add(toFund, param.Amount))
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmTransfer, sendBlock.AccountAddress, param.To, param.TokenId, param.Amount))
+	return nil, nil
+}
+
+type MethodAmmCreatePool struct{}
+
+func (p *MethodAmmCreatePool) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmCreatePool) GetRefundData() []byte {
+	return []byte{3}
+}
+func (p *MethodAmmCreatePool) GetQuota() uint64 {
+	return AmmCreatePoolGas
+}
+func (p *MethodAmmCreatePool) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmCreatePool)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmCreatePool, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.TokenA == param.TokenB ||
+		!isTokenExist(db, param.TokenA) || !isTokenExist(db, param.TokenB) {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	tokenA, tokenB, _ := cabi.SortTokenPair(param.TokenA, param.TokenB)
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmCreatePool, tokenA, tokenB)
+	return quotaLeft, nil
+}
+func (p *MethodAmmCreatePool) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmCreatePool)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmCreatePool, sendBlock.Data)
+	if cabi.GetAmmPool(db, param.TokenA, param.TokenB) != nil {
+		return nil, errAmmPoolExist
+	}
+	saveAmmPool(db, &cabi.AmmPool{
+		TokenA:           param.TokenA,
+		TokenB:           param.TokenB,
+		ReserveA:         big.NewInt(0),
+		ReserveB:         big.NewInt(0),
+		TotalShares:      big.NewInt(0),
+		PriceACumulative: big.NewInt(0),
+		PriceBCumulative: big.NewInt(0),
+		LastTimestamp:    ammTimestamp(db),
+	})
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmCreatePool, param.TokenA, param.TokenB))
+	return nil, nil
+}
+
+type MethodAmmAddLiquidity struct{}
+
+func (p *MethodAmmAddLiquidity) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmAddLiquidity) GetRefundData() []byte {
+	return []byte{4}
+}
+func (p *MethodAmmAddLiquidity) GetQuota() uint64 {
+	return AmmAddLiquidityGas
+}
+func (p *MethodAmmAddLiquidity) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmAddLiquidity)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmAddLiquidity, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.TokenA == param.TokenB ||
+		param.AmountA.Sign() <= 0 || param.AmountB.Sign() <= 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if _, _, swapped := cabi.SortTokenPair(param.TokenA, param.TokenB); swapped {
+		param.TokenA, param.TokenB = param.TokenB, param.TokenA
+		param.AmountA, param.AmountB = param.AmountB, param.AmountA
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmAddLiquidity, param.TokenA, param.TokenB, param.AmountA, param.AmountB, param.MinShares)
+	return quotaLeft, nil
+}
+
+// DoReceive adds the most of AmountA and AmountB in the ratio of the reserves from the fund of
+// the sender, the rest is left in the fund.
+func (p *MethodAmmAddLiquidity) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmAddLiquidity)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmAddLiquidity, sendBlock.Data)
+	pool := cabi.GetAmmPool(db, param.TokenA, param.TokenB)
+	if pool == nil {
+		return nil, errAmmPoolNotExist
+	}
+
+	amountA, amountB := param.AmountA, param.AmountB
+	var shares *big.Int
+	first := pool.TotalShares.Sign() == 0
+	if first {
+		shares = new(big.Int).Mul(amountA, amountB)
+		shares.Sqrt(shares).Sub(shares, ammMinShares)
+	} else {
+		if optimalB := new(big.Int).Div(new(big.Int).Mul(amountA, pool.ReserveB), pool.ReserveA); optimalB.Cmp(amountB) <= 0 {
+			amountB = optimalB
+		} else {
+			amountA = new(big.Int).Div(new(big.Int).Mul(amountB, pool.ReserveA), pool.ReserveB)
+		}
+		shares = new(big.Int).Div(new(big.Int).Mul(amountA, pool.TotalShares), pool.ReserveA)
+		if sharesB := new(big.Int).Div(new(big.Int).Mul(amountB, pool.TotalShares), pool.ReserveB); sharesB.Cmp(shares) < 0 {
+			shares = sharesB
+		}
+	}
+	if shares.Sign() <= 0 || shares.Cmp(param.MinShares) < 0 {
+		return nil, errAmmInsufficientShares
+	}
+
+	fundA := cabi.GetAmmFund(db, sendBlock.AccountAddress, param.TokenA)
+	fundB := cabi.GetAmmFund(db, sendBlock.AccountAddress, param.TokenB)
+	if fundA.Cmp(amountA) < 0 || fundB.Cmp(amountB) < 0 {
+		return nil, errAmmInsufficientFund
+	}
+	saveAmmAmount(db, cabi.GetAmmFundKey(sendBlock.AccountAddress, param.TokenA), fundA.Sub(fundA, amountA))
+	saveAmmAmount(db, cabi.GetAmmFundKey(sendBlock.AccountAddress, param.TokenB), fundB.Sub(fundB, amountB))
+
+	oldShares := cabi.GetAmmShares(db, param.TokenA, param.TokenB, sendBlock.AccountAddress)
+	newShares := new(big.Int).Add(oldShares, shares)
+	settleAmmReward(db, pool, sendBlock.AccountAddress, oldShares, newShares, false)
+	saveAmmAmount(db, cabi.GetAmmShareKey(param.TokenA, param.TokenB, sendBlock.AccountAddress), newShares)
+
+	updateAmmCumulative(pool, ammTimestamp(db))
+	pool.ReserveA = new(big.Int).Add(pool.ReserveA, amountA)
+	pool.ReserveB = new(big.Int).Add(pool.ReserveB, amountB)
+	pool.TotalShares = new(big.Int).Add(pool.TotalShares, shares)
+	if first {
+		// the first shares are locked forever, so the pool is never drained to zero shares
+		pool.TotalShares.Add(pool.TotalShares, ammMinShares)
+	}
+	saveAmmPool(db, pool)
+
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmAddLiquidity, param.TokenA, param.TokenB, sendBlock.AccountAddress, amountA, amountB, shares))
+	return nil, nil
+}
+
+type MethodAmmRemoveLiquidity struct{}
+
+func (p *MethodAmmRemoveLiquidity) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmRemoveLiquidity) GetRefundData() []byte {
+	return []byte{5}
+}
+func (p *MethodAmmRemoveLiquidity) GetQuota() uint64 {
+	return AmmRemoveLiquidityGas
+}
+func (p *MethodAmmRemoveLiquidity) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmRemoveLiquidity)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmRemoveLiquidity, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.TokenA == param.TokenB || param.Shares.Sign() <= 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if _, _, swapped := cabi.SortTokenPair(param.TokenA, param.TokenB); swapped {
+		param.TokenA, param.TokenB = param.TokenB, param.TokenA
+		param.MinAmountA, param.MinAmountB = param.MinAmountB, param.MinAmountA
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmRemoveLiquidity, param.TokenA, param.TokenB, param.Shares, param.MinAmountA, param.MinAmountB)
+	return quotaLeft, nil
+}
+func (p *MethodAmmRemoveLiquidity) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmRemoveLiquidity)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmRemoveLiquidity, sendBlock.Data)
+	pool := cabi.GetAmmPool(db, param.TokenA, param.TokenB)
+	if pool == nil {
+		return nil, errAmmPoolNotExist
+	}
+	shares := cabi.GetAmmShares(db, param.TokenA, param.TokenB, sendBlock.AccountAddress)
+	if shares.Cmp(param.Shares) < 0 {
+		return nil, errAmmInsufficientShares
+	}
+
+	amountA := new(big.Int).Div(new(big.Int).Mul(param.Shares, pool.ReserveA), pool.TotalShares)
+	amountB := new(big.Int).Div(new(big.Int).Mul(param.Shares, pool.ReserveB), pool.TotalShares)
+	if amountA.Cmp(param.MinAmountA) < 0 || amountB.Cmp(param.MinAmountB) < 0 {
+		return nil, errAmmInsufficientOutput
+	}
+	newShares := new(big.Int).Sub(shares, param.Shares)
+	settleAmmReward(db, pool, sendBlock.AccountAddress, shares, newShares, false)
+	saveAmmAmount(db, cabi.GetAmmShareKey(param.TokenA, param.TokenB, sendBlock.AccountAddress), newShares)
+
+	updateAmmCumulative(pool, ammTimestamp(db))
+	pool.ReserveA = new(big.Int).Sub(pool.ReserveA, amountA)
+	pool.ReserveB = new(big.Int).Sub(pool.ReserveB, amountB)
+	pool.TotalShares = new(big.Int).Sub(pool.TotalShares, param.Shares)
+	saveAmmPool(db, pool)
+
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmRemoveLiquidity, param.TokenA, param.TokenB, sendBlock.AccountAddress, amountA, amountB, param.Shares))
+
+	var sendBlocks []*SendBlock
+	for _, out := range []struct {
+		tokenId types.TokenTypeId
+		amount  *big.Int
+	}{{param.TokenA, amountA}, {param.TokenB, amountB}} {
+		if out.amount.Sign() > 0 {
+			sendBlocks = append(sendBlocks, &SendBlock{
+				block,
+				sendBlock.AccountAddress,
+				ledger.BlockTypeSendCall,
+				out.amount,
+				out.tokenId,
+				[]byte{},
+			})
+		}
+	}
+	return sendBlocks, nil
+}
+
+type MethodAmmSwap struct{}
+
+func (p *MethodAmmSwap) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmSwap) GetRefundData() []byte {
+	return []byte{6}
+}
+func (p *MethodAmmSwap) GetQuota() uint64 {
+	return AmmSwapGas
+}
+func (p *MethodAmmSwap) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmSwap)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmSwap, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() <= 0 || param.TokenOut == block.TokenId {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmSwap, param.TokenOut, param.MinAmountOut)
+	return quotaLeft, nil
+}
+
+// DoReceive swaps the amount of the send block into TokenOut, which is sent back to the sender.
+func (p *MethodAmmSwap) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmSwap)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmSwap, sendBlock.Data)
+	pool := cabi.GetAmmPool(db, sendBlock.TokenId, param.TokenOut)
+	if pool == nil {
+		return nil, errAmmPoolNotExist
+	}
+
+	reserveIn, reserveOut := pool.ReserveA, pool.ReserveB
+	if sendBlock.TokenId != pool.TokenA {
+		reserveIn, reserveOut = pool.ReserveB, pool.ReserveA
+	}
+	if reserveIn.Sign() == 0 || reserveOut.Sign() == 0 {
+		return nil, errAmmInsufficientOutput
+	}
+	amountOut := getAmmAmountOut(sendBlock.Amount, reserveIn, reserveOut)
+	if amountOut.Sign() <= 0 || amountOut.Cmp(param.MinAmountOut) < 0 {
+		return nil, errAmmInsufficientOutput
+	}
+
+	// part of the fee is collected for the dividend instead of left in the pool
+	amountIn := sendBlock.Amount
+	if cabi.GetAmmDividend(db) != nil {
+		dividendFee := new(big.Int).Mul(sendBlock.Amount, ammDividendFeeRate)
+		dividendFee.Div(dividendFee, ammFeeBase)
+		if dividendFee.Sign() > 0 {
+			amountIn = new(big.Int).Sub(amountIn, dividendFee)
+			feePool := cabi.GetAmmFeePool(db, sendBlock.TokenId)
+			saveAmmAmount(db, cabi.GetAmmFeePoolKey(sendBlock.TokenId), feePool.Add(feePool, dividendFee))
+		}
+	}
+
+	updateAmmCumulative(pool, ammTimestamp(db))
+	if sendBlock.TokenId == pool.TokenA {
+		pool.ReserveA = new(big.Int).Add(pool.ReserveA, amountIn)
+		pool.ReserveB = new(big.Int).Sub(pool.ReserveB, amountOut)
+	} else {
+		pool.ReserveB = new(big.Int).Add(pool.ReserveB, amountIn)
+		pool.ReserveA = new(big.Int).Sub(pool.ReserveA, amountOut)
+	}
+	saveAmmPool(db, pool)
+
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmSwap, sendBlock.TokenId, param.TokenOut, sendBlock.AccountAddress, sendBlock.Amount, amountOut))
+	return []*SendBlock{
+		{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			amountOut,
+			param.TokenOut,
+			[]byte{},
+		},
+	}, nil
+}
+
+type MethodAmmSetReward struct{}
+
+func (p *MethodAmmSetReward) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmSetReward) GetRefundData() []byte {
+	return []byte{7}
+}
+func (p *MethodAmmSetReward) GetQuota() uint64 {
+	return AmmSetRewardGas
+}
+func (p *MethodAmmSetReward) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmSetReward)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmSetReward, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if param.TokenA == param.TokenB {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	tokenA, tokenB, _ := cabi.SortTokenPair(param.TokenA, param.TokenB)
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmSetReward, tokenA, tokenB, param.RewardPerSecond)
+	return quotaLeft, nil
+}
+
+// DoReceive sets the reward emission of a pool and adds the amount of the send block to the
+// reward balance. The sender who sets the reward of a pool first owns it, and the token it sends
+// is the reward token.
+func (p *MethodAmmSetReward) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmSetReward)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmSetReward, sendBlock.Data)
+	pool := cabi.GetAmmPool(db, param.TokenA, param.TokenB)
+	if pool == nil {
+		return nil, errAmmPoolNotExist
+	}
+	reward := cabi.GetAmmReward(db, param.TokenA, param.TokenB)
+	if reward == nil {
+		reward = &cabi.AmmReward{
+			RewardToken:       sendBlock.TokenId,
+			Owner:             sendBlock.AccountAddress,
+			RewardPerSecond:   big.NewInt(0),
+			RewardBalance:     big.NewInt(0),
+			AccRewardPerShare: big.NewInt(0),
+			LastTimestamp:     ammTimestamp(db),
+		}
+	} else if reward.Owner != sendBlock.AccountAddress {
+		return nil, errAmmRewardNotOwner
+	} else if sendBlock.Amount.Sign() > 0 && sendBlock.TokenId != reward.RewardToken {
+		return nil, util.ErrInvalidMethodParam
+	}
+
+	// the reward emitted so far is at the old rate
+	reward.Accrue(pool.TotalShares, ammTimestamp(db))
+	reward.RewardPerSecond = param.RewardPerSecond
+	reward.RewardBalance = new(big.Int).Add(reward.RewardBalance, sendBlock.Amount)
+	saveAmmReward(db, param.TokenA, param.TokenB, reward)
+
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmSetReward, param.TokenA, param.TokenB, reward.RewardToken, param.RewardPerSecond, sendBlock.Amount))
+	return nil, nil
+}
+
+type MethodAmmClaimReward struct{}
+
+func (p *MethodAmmClaimReward) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmClaimReward) GetRefundData() []byte {
+	return []byte{8}
+}
+func (p *MethodAmmClaimReward) GetQuota() uint64 {
+	return AmmClaimRewardGas
+}
+func (p *MethodAmmClaimReward) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmClaimReward)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmClaimReward, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.TokenA == param.TokenB {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	tokenA, tokenB, _ := cabi.SortTokenPair(param.TokenA, param.TokenB)
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmClaimReward, tokenA, tokenB)
+	return quotaLeft, nil
+}
+
+// DoReceive sends the reward earned by the liquidity shares of the sender in a pool to the sender.
+func (p *MethodAmmClaimReward) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmClaimReward)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmClaimReward, sendBlock.Data)
+	pool := cabi.GetAmmPool(db, param.TokenA, param.TokenB)
+	if pool == nil {
+		return nil, errAmmPoolNotExist
+	}
+	shares := cabi.GetAmmShares(db, param.TokenA, param.TokenB, sendBlock.AccountAddress)
+	reward, amount := settleAmmReward(db, pool, sendBlock.AccountAddress, shares, shares, true)
+	if amount.Sign() == 0 {
+		return nil, nil
+	}
+
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmClaimReward, param.TokenA, param.TokenB, sendBlock.AccountAddress, amount))
+	return []*SendBlock{
+		{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			amount,
+			reward.RewardToken,
+			[]byte{},
+		},
+	}, nil
+}
+
+type MethodAmmSetDividendToken struct{}
+
+func (p *MethodAmmSetDividendToken) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmSetDividendToken) GetRefundData() []byte {
+	return []byte{11}
+}
+func (p *MethodAmmSetDividendToken) GetQuota() uint64 {
+	return AmmSetDividendTokenGas
+}
+func (p *MethodAmmSetDividendToken) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamAmmSetDividendToken)
+	if err = cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmSetDividendToken, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmSetDividendToken, param.TokenId)
+	return quotaLeft, nil
+}
+
+// DoReceive sets the token whose holders share the swap fees collected for the dividend. The sender
+// who sets it first owns the dividend, the swap fees are collected from then on.
+func (p *MethodAmmSetDividendToken) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAmmSetDividendToken)
+	cabi.ABIAmm.UnpackMethod(param, cabi.MethodNameAmmSetDividendToken, sendBlock.Data)
+	if !isTokenExist(db, param.TokenId) {
+		return nil, util.ErrInvalidMethodParam
+	}
+	dividend := cabi.GetAmmDividend(db)
+	if dividend == nil {
+		dividend = &cabi.AmmDividend{
+			Owner:         sendBlock.AccountAddress,
+			LastTimestamp: ammTimestamp(db),
+		}
+	} else if dividend.Owner != sendBlock.AccountAddress {
+		return nil, errAmmDividendNotOwner
+	}
+	dividend.DividendToken = param.TokenId
+	saveAmmDividend(db, dividend)
+
+	db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmSetDividendToken, param.TokenId, dividend.Owner))
+	return nil, nil
+}
+
+// MethodAmmDividend distributes the swap fees collected for the dividend to the holders of the dividend token.
+type MethodAmmDividend struct{}
+
+func (p *MethodAmmDividend) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodAmmDividend) GetRefundData() []byte {
+	return []byte{12}
+}
+func (p *MethodAmmDividend) GetQuota() uint64 {
+	return AmmDividendGas
+}
+func (p *MethodAmmDividend) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	quotaLeft, err := checkAmmSend(db, quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() > 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIAmm.PackMethod(cabi.MethodNameAmmDividend)
+	return quotaLeft, nil
+}
+
+// DoReceive credits the funds of the holders of the dividend token with the collected fees of each token,
+// in proportion to their funds of the dividend token. Anyone can trigger it once per ammDividendPeriod,
+// the remainders of the division are kept for the next dividend.
+func (p *MethodAmmDividend) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	dividend := cabi.GetAmmDividend(db)
+	if dividend == nil {
+		return nil, errAmmDividendNotSet
+	}
+	now := ammTimestamp(db)
+	if now < dividend.LastTimestamp+ammDividendPeriod {
+		return nil, errAmmDividendTooEarly
+	}
+
+	// the holdings are read before any fund is credited, since the dividend token may be one of the fees
+	holders := cabi.GetAmmFundHolders(db, dividend.DividendToken)
+	fees := cabi.GetAmmFeePoolList(db)
+	if err := meter.Use(AmmDividendPerHolderGas, uint64(len(holders))*uint64(len(fees))); err != nil {
+		return nil, err
+	}
+	total := big.NewInt(0)
+	for _, holder := range holders {
+		total.Add(total, holder.Amount)
+	}
+	if total.Sign() > 0 {
+		for _, fee := range fees {
+			distributed := big.NewInt(0)
+			for _, holder := range holders {
+				amount := new(big.Int).Mul(fee.Amount, holder.Amount)
+				if amount.Div(amount, total).Sign() == 0 {
+					continue
+				}
+				fund := cabi.GetAmmFund(db, holder.Address, fee.TokenId)
+				saveAmmAmount(db, cabi.GetAmmFundKey(holder.Address, fee.TokenId), fund.Add(fund, amount))
+				distributed.Add(distributed, amount)
+			}
+			saveAmmAmount(db, cabi.GetAmmFeePoolKey(fee.TokenId), new(big.Int).Sub(fee.Amount, distributed))
+			db.AddLog(util.NewLog(cabi.ABIAmm, cabi.EventNameAmmDividend, fee.TokenId, dividend.DividendToken, distributed, uint64(len(holders))))
+		}
+	}
+	dividend.LastTimestamp = now
+	saveAmmDividend(db, dividend)
+	return nil, nil
+}
@@ -0,0 +1,63 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+var errCheckpointNotAhead = errors.New("checkpoint height should be above the latest checkpoint")
+
+type MethodCheckpoint struct{}
+
+func (p *MethodCheckpoint) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodCheckpoint) GetRefundData() []byte {
+	return []byte{1}
+}
+func (p *MethodCheckpoint) GetQuota() uint64 {
+	return CheckpointGas
+}
+
+// DoSend checks the checkpoint against the chain of the sender, the height must be of a block before the send block
+// and the state hash must be the one of that block.
+func (p *MethodCheckpoint) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsCheckpointFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamCheckpoint)
+	if err = cabi.ABICheckpoint.UnpackMethod(param, cabi.MethodNameCheckpoint, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 || param.Height == 0 || param.Height >= block.Height {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	checkpointBlock := db.GetSelfAccountBlockByHeight(param.Height)
+	if checkpointBlock == nil || checkpointBlock.StateHash != param.StateHash {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABICheckpoint.PackMethod(cabi.MethodNameCheckpoint, param.Height, param.StateHash)
+	return quotaLeft, nil
+}
+
+// DoReceive replaces the checkpoint of the sender, checkpoints can only move forward.
+func (p *MethodCheckpoint) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamCheckpoint)
+	cabi.ABICheckpoint.UnpackMethod(param, cabi.MethodNameCheckpoint, sendBlock.Data)
+	if old := cabi.GetAccountCheckpoint(db, sendBlock.AccountAddress); old != nil && old.Height >= param.Height {
+		return nil, errCheckpointNotAhead
+	}
+	data, _ := cabi.ABICheckpoint.PackVariable(cabi.VariableNameAccountCheckpoint, param.Height, param.StateHash, sendBlock.Hash)
+	db.SetStorage(cabi.GetCheckpointKey(sendBlock.AccountAddress), data)
+	db.AddLog(util.NewLog(cabi.ABICheckpoint, cabi.EventNameCheckpointed, sendBlock.AccountAddress, param.Height))
+	return nil, nil
+}
@@ -94,13 +94,16 @@ func checkCondition(db vmctxt_interface.VmDatabase, conditionId uint8, condition
 	}
 	return nil
 }
-func (p *MethodCreateConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodCreateConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(types.ConsensusGroupInfo)
 	cabi.ABIConsensusGroup.UnpackMethod(param, cabi.MethodNameCreateConsensusGroup, sendBlock.Data)
 	key := cabi.GetConsensusGroupKey(param.Gid)
 	if len(db.GetStorage(&block.AccountAddress, key)) > 0 {
 		return nil, util.ErrIdCollision
 	}
+	if err := meter.Use(ConditionParamPerWordGas, conditionParamWords(param)); err != nil {
+		return nil, err
+	}
 	groupInfo, _ := cabi.ABIConsensusGroup.PackVariable(
 		cabi.VariableNameConsensusGroupInfo,
 		param.NodeCount,
@@ -161,7 +164,7 @@ func (p *MethodCancelConsensusGroup) DoSend(db vmctxt_interface.VmDatabase, bloc
 	block.Data, _ = cabi.ABIConsensusGroup.PackMethod(cabi.MethodNameCancelConsensusGroup, *gid)
 	return quotaLeft, nil
 }
-func (p *MethodCancelConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodCancelConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	gid := new(types.Gid)
 	cabi.ABIConsensusGroup.UnpackMethod(gid, cabi.MethodNameCancelConsensusGroup, sendBlock.Data)
 	key := cabi.GetConsensusGroupKey(*gid)
@@ -240,7 +243,7 @@ func (p *MethodReCreateConsensusGroup) DoSend(db vmctxt_interface.VmDatabase, bl
 	block.Data, _ = cabi.ABIConsensusGroup.PackMethod(cabi.MethodNameReCreateConsensusGroup, *gid)
 	return quotaLeft, nil
 }
-func (p *MethodReCreateConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodReCreateConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	gid := new(types.Gid)
 	cabi.ABIConsensusGroup.UnpackMethod(gid, cabi.MethodNameReCreateConsensusGroup, sendBlock.Data)
 	key := cabi.GetConsensusGroupKey(*gid)
@@ -249,6 +252,9 @@ func (p *MethodReCreateConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase,
 		groupInfo.IsActive() {
 		return nil, errors.New("consensus group is active")
 	}
+	if err := meter.Use(ConditionParamPerWordGas, conditionParamWords(groupInfo)); err != nil {
+		return nil, err
+	}
 	newGroupInfo, _ := cabi.ABIConsensusGroup.PackVariable(
 		cabi.VariableNameConsensusGroupInfo,
 		groupInfo.NodeCount,
@@ -268,6 +274,11 @@ func (p *MethodReCreateConsensusGroup) DoReceive(db vmctxt_interface.VmDatabase,
 	return nil, nil
 }
 
+// conditionParamWords returns the count of 32-byte words of the register and vote condition params of group
+func conditionParamWords(group *types.ConsensusGroupInfo) uint64 {
+	return (uint64(len(group.RegisterConditionParam))+31)/32 + (uint64(len(group.VoteConditionParam))+31)/32
+}
+
 type createConsensusGroupCondition interface {
 	checkParam(param []byte, db vmctxt_interface.VmDatabase) bool
 	checkData(paramData []byte, db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, blockParamInterface interface{}, method string) bool
@@ -0,0 +1,72 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+var errEventRegistryNotAuthorized = errors.New("only the contract or its deployer can register its events")
+
+type MethodRegisterEvent struct{}
+
+func (p *MethodRegisterEvent) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodRegisterEvent) GetRefundData() []byte {
+	return []byte{1}
+}
+func (p *MethodRegisterEvent) GetQuota() uint64 {
+	return RegisterEventGas
+}
+func (p *MethodRegisterEvent) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsEventRegistryFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamRegisterEvent)
+	if err = cabi.ABIEventRegistry.UnpackMethod(param, cabi.MethodNameRegisterEvent, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if _, err = cabi.ParseEventDefinition(param.Definition); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIEventRegistry.PackMethod(cabi.MethodNameRegisterEvent, param.Contract, param.CreateHash, param.Definition)
+	return quotaLeft, nil
+}
+
+// DoReceive saves the definition of an event of the contract by its id, replacing the one registered before.
+// The sender must be the contract itself, or the address which sent the create block CreateHash of the contract.
+func (p *MethodRegisterEvent) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamRegisterEvent)
+	cabi.ABIEventRegistry.UnpackMethod(param, cabi.MethodNameRegisterEvent, sendBlock.Data)
+	if sendBlock.AccountAddress != param.Contract {
+		createBlock := db.GetAccountBlockByHash(&param.CreateHash)
+		if createBlock == nil || createBlock.BlockType != ledger.BlockTypeSendCreate ||
+			createBlock.AccountAddress != sendBlock.AccountAddress || createBlock.ToAddress != param.Contract {
+			return nil, errEventRegistryNotAuthorized
+		}
+	}
+	event, err := cabi.ParseEventDefinition(param.Definition)
+	if err != nil {
+		return nil, err
+	}
+	if err = meter.Use(EventDefinitionPerWordGas, helper.ToWordSize(uint64(len(param.Definition)))); err != nil {
+		return nil, err
+	}
+	data, _ := cabi.ABIEventRegistry.PackVariable(cabi.VariableNameEventDefinition, param.Definition)
+	db.SetStorage(cabi.GetEventDefinitionKey(param.Contract, event.Id()), data)
+	db.AddLog(util.NewLog(cabi.ABIEventRegistry, cabi.EventNameEventRegistryRegistered, param.Contract, event.Id()))
+	return nil, nil
+}
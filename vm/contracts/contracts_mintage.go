@@ -79,7 +79,7 @@ func CheckToken(param cabi.ParamMintage) error {
 	}
 	return nil
 }
-func (p *MethodMintage) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodMintage) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamMintage)
 	cabi.ABIMintage.UnpackMethod(param, cabi.MethodNameMintage, sendBlock.Data)
 	key := cabi.GetMintageKey(param.TokenId)
@@ -150,7 +150,7 @@ func (p *MethodMintageCancelPledge) DoSend(db vmctxt_interface.VmDatabase, block
 	block.Data, _ = cabi.ABIMintage.PackMethod(cabi.MethodNameMintageCancelPledge, *tokenId)
 	return quotaLeft, nil
 }
-func (p *MethodMintageCancelPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodMintageCancelPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	tokenId := new(types.TokenTypeId)
 	cabi.ABIMintage.UnpackMethod(tokenId, cabi.MethodNameMintageCancelPledge, sendBlock.Data)
 	tokenInfo := cabi.GetTokenById(db, *tokenId)
@@ -262,7 +262,7 @@ func CheckMintToken(param cabi.ParamMintage) error {
 	}
 	return nil
 }
-func (p *MethodMint) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodMint) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamMintage)
 	cabi.ABIMintage.UnpackMethod(param, cabi.MethodNameMint, sendBlock.Data)
 	key := cabi.GetMintageKey(param.TokenId)
@@ -353,7 +353,7 @@ func (p *MethodIssue) DoSend(db vmctxt_interface.VmDatabase, block *ledger.Accou
 	block.Data, _ = cabi.ABIMintage.PackMethod(cabi.MethodNameIssue, param.TokenId, param.Amount, param.Beneficial)
 	return quotaLeft, nil
 }
-func (p *MethodIssue) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodIssue) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamIssue)
 	cabi.ABIMintage.UnpackMethod(param, cabi.MethodNameIssue, sendBlock.Data)
 	oldTokenInfo := cabi.GetTokenById(db, param.TokenId)
@@ -419,7 +419,7 @@ func (p *MethodBurn) DoSend(db vmctxt_interface.VmDatabase, block *ledger.Accoun
 	block.Data, _ = cabi.ABIMintage.PackMethod(cabi.MethodNameBurn)
 	return quotaLeft, nil
 }
-func (p *MethodBurn) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodBurn) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	oldTokenInfo := cabi.GetTokenById(db, sendBlock.TokenId)
 	if oldTokenInfo == nil || !oldTokenInfo.IsReIssuable ||
 		(oldTokenInfo.OwnerBurnOnly && oldTokenInfo.Owner != sendBlock.AccountAddress) {
@@ -482,7 +482,7 @@ func (p *MethodTransferOwner) DoSend(db vmctxt_interface.VmDatabase, block *ledg
 	block.Data, _ = cabi.ABIMintage.PackMethod(cabi.MethodNameTransferOwner, param.TokenId, param.NewOwner)
 	return quotaLeft, nil
 }
-func (p *MethodTransferOwner) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodTransferOwner) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamTransferOwner)
 	cabi.ABIMintage.UnpackMethod(param, cabi.MethodNameTransferOwner, sendBlock.Data)
 	oldTokenInfo := cabi.GetTokenById(db, param.TokenId)
@@ -549,7 +549,7 @@ func (p *MethodChangeTokenType) DoSend(db vmctxt_interface.VmDatabase, block *le
 	block.Data, _ = cabi.ABIMintage.PackMethod(cabi.MethodNameChangeTokenType, &tokenId)
 	return quotaLeft, nil
 }
-func (p *MethodChangeTokenType) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodChangeTokenType) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	tokenId := new(types.TokenTypeId)
 	cabi.ABIMintage.UnpackMethod(tokenId, cabi.MethodNameChangeTokenType, sendBlock.Data)
 	oldTokenInfo := cabi.GetTokenById(db, *tokenId)
@@ -2,8 +2,10 @@ package contracts
 
 import (
 	"errors"
+	"fmt"
 	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/ledger"
 	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
 	"github.com/vitelabs/go-vite/vm/util"
@@ -45,11 +47,18 @@ func (p *MethodPledge) DoSend(db vmctxt_interface.VmDatabase, block *ledger.Acco
 	block.Data, _ = cabi.ABIPledge.PackMethod(cabi.MethodNamePledge, *beneficialAddr)
 	return quotaLeft, nil
 }
-func (p *MethodPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	beneficialAddr := new(types.Address)
 	cabi.ABIPledge.UnpackMethod(beneficialAddr, cabi.MethodNamePledge, sendBlock.Data)
 	beneficialKey := cabi.GetPledgeBeneficialKey(*beneficialAddr)
 	pledgeKey := cabi.GetPledgeKey(sendBlock.AccountAddress, beneficialKey)
+	addPledge(db, block, pledgeKey, beneficialKey, sendBlock.Amount, nodeConfig.params.MinPledgeHeight)
+	return nil, nil
+}
+
+// addPledge adds amount to the pledge of pledgeKey and the pledge amount of the beneficial, the pledge is due
+// lockHeight later again
+func addPledge(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, pledgeKey, beneficialKey []byte, pledgeAmount *big.Int, lockHeight uint64) {
 	oldPledgeData := db.GetStorage(&block.AccountAddress, pledgeKey)
 	amount := big.NewInt(0)
 	if len(oldPledgeData) > 0 {
@@ -57,8 +66,8 @@ func (p *MethodPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.A
 		cabi.ABIPledge.UnpackVariable(oldPledge, cabi.VariableNamePledgeInfo, oldPledgeData)
 		amount = oldPledge.Amount
 	}
-	amount.Add(amount, sendBlock.Amount)
-	pledgeInfo, _ := cabi.ABIPledge.PackVariable(cabi.VariableNamePledgeInfo, amount, db.CurrentSnapshotBlock().Height+nodeConfig.params.MinPledgeHeight)
+	amount.Add(amount, pledgeAmount)
+	pledgeInfo, _ := cabi.ABIPledge.PackVariable(cabi.VariableNamePledgeInfo, amount, db.CurrentSnapshotBlock().Height+lockHeight)
 	db.SetStorage(pledgeKey, pledgeInfo)
 
 	oldBeneficialData := db.GetStorage(&block.AccountAddress, beneficialKey)
@@ -68,10 +77,9 @@ func (p *MethodPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.A
 		cabi.ABIPledge.UnpackVariable(oldBeneficial, cabi.VariableNamePledgeBeneficial, oldBeneficialData)
 		beneficialAmount = oldBeneficial.Amount
 	}
-	beneficialAmount.Add(beneficialAmount, sendBlock.Amount)
+	beneficialAmount.Add(beneficialAmount, pledgeAmount)
 	beneficialData, _ := cabi.ABIPledge.PackVariable(cabi.VariableNamePledgeBeneficial, beneficialAmount)
 	db.SetStorage(beneficialKey, beneficialData)
-	return nil, nil
 }
 
 type MethodCancelPledge struct{}
@@ -109,25 +117,46 @@ func (p *MethodCancelPledge) DoSend(db vmctxt_interface.VmDatabase, block *ledge
 	return quotaLeft, nil
 }
 
-func (p *MethodCancelPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodCancelPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamCancelPledge)
 	cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameCancelPledge, sendBlock.Data)
 	beneficialKey := cabi.GetPledgeBeneficialKey(param.Beneficial)
 	pledgeKey := cabi.GetPledgeKey(sendBlock.AccountAddress, beneficialKey)
+	if err := subPledge(db, block, pledgeKey, beneficialKey, param.Amount); err != nil {
+		return nil, err
+	}
+	return []*SendBlock{
+		{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			param.Amount,
+			ledger.ViteTokenId,
+			[]byte{},
+		},
+	}, nil
+}
+
+// subPledge subtracts amount from the due pledge of pledgeKey and the pledge amount of the beneficial
+func subPledge(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, pledgeKey, beneficialKey []byte, amount *big.Int) error {
 	oldPledge := new(cabi.PledgeInfo)
 	err := cabi.ABIPledge.UnpackVariable(oldPledge, cabi.VariableNamePledgeInfo, db.GetStorage(&block.AccountAddress, pledgeKey))
-	if err != nil || oldPledge.WithdrawHeight > db.CurrentSnapshotBlock().Height || oldPledge.Amount.Cmp(param.Amount) < 0 {
-		return nil, errors.New("pledge not yet due")
+	if err != nil || oldPledge.WithdrawHeight > db.CurrentSnapshotBlock().Height || oldPledge.Amount.Cmp(amount) < 0 {
+		return errors.New("pledge not yet due")
 	}
-	oldPledge.Amount.Sub(oldPledge.Amount, param.Amount)
+	oldPledge.Amount.Sub(oldPledge.Amount, amount)
 	oldBeneficial := new(cabi.VariablePledgeBeneficial)
 	err = cabi.ABIPledge.UnpackVariable(oldBeneficial, cabi.VariableNamePledgeBeneficial, db.GetStorage(&block.AccountAddress, beneficialKey))
-	if err != nil || oldBeneficial.Amount.Cmp(param.Amount) < 0 {
-		return nil, errors.New("invalid pledge amount")
+	if err != nil || oldBeneficial.Amount.Cmp(amount) < 0 {
+		return errors.New("invalid pledge amount")
 	}
-	oldBeneficial.Amount.Sub(oldBeneficial.Amount, param.Amount)
+	oldBeneficial.Amount.Sub(oldBeneficial.Amount, amount)
 	if fork.IsMintFork(db.CurrentSnapshotBlock().Height) && oldBeneficial.Amount.Sign() != 0 && oldBeneficial.Amount.Cmp(pledgeAmountMin2) < 0 {
-		return nil, errors.New("invalid pledge amount")
+		return errors.New("invalid pledge amount")
+	}
+	beneficial, _ := types.BytesToAddress(beneficialKey)
+	if err = checkPledgeNotLeasedOut(db, beneficial, oldBeneficial.Amount); err != nil {
+		return err
 	}
 
 	if oldPledge.Amount.Sign() == 0 {
@@ -143,6 +172,261 @@ func (p *MethodCancelPledge) DoReceive(db vmctxt_interface.VmDatabase, block *le
 		pledgeBeneficial, _ := cabi.ABIPledge.PackVariable(cabi.VariableNamePledgeBeneficial, oldBeneficial.Amount)
 		db.SetStorage(beneficialKey, pledgeBeneficial)
 	}
+	return nil
+}
+
+type MethodAgentPledge struct{}
+
+func (p *MethodAgentPledge) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodAgentPledge) GetRefundData() []byte {
+	return []byte{3}
+}
+
+func (p *MethodAgentPledge) GetQuota() uint64 {
+	return AgentPledgeGas
+}
+
+// pledge ViteToken on behalf of a pledge address for a beneficial, the agent can be a contract, eg. an exchange
+// pledging the funds of its users
+func (p *MethodAgentPledge) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsAgentPledgeFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Cmp(pledgeAmountMin2) < 0 || !util.IsViteToken(block.TokenId) {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamAgentPledge)
+	if err = cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameAgentPledge, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIPledge.PackMethod(cabi.MethodNameAgentPledge, param.PledgeAddr, param.Beneficial)
+	return quotaLeft, nil
+}
+func (p *MethodAgentPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAgentPledge)
+	cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameAgentPledge, sendBlock.Data)
+	beneficialKey := cabi.GetPledgeBeneficialKey(param.Beneficial)
+	pledgeKey := cabi.GetAgentPledgeKey(param.PledgeAddr, beneficialKey, sendBlock.AccountAddress)
+	addPledge(db, block, pledgeKey, beneficialKey, sendBlock.Amount, nodeConfig.params.MinPledgeHeight)
+	return nil, nil
+}
+
+type MethodAgentCancelPledge struct{}
+
+func (p *MethodAgentCancelPledge) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodAgentCancelPledge) GetRefundData() []byte {
+	return []byte{4}
+}
+
+func (p *MethodAgentCancelPledge) GetQuota() uint64 {
+	return AgentCancelPledgeGas
+}
+
+// cancel the pledge made by the agent, the ViteToken is returned to the agent
+func (p *MethodAgentCancelPledge) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsAgentPledgeFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() > 0 {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamAgentCancelPledge)
+	if err = cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameAgentCancelPledge, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if param.Amount.Sign() == 0 {
+		return quotaLeft, errors.New("cancel pledge amount is 0")
+	}
+	block.Data, _ = cabi.ABIPledge.PackMethod(cabi.MethodNameAgentCancelPledge, param.PledgeAddr, param.Beneficial, param.Amount)
+	return quotaLeft, nil
+}
+
+func (p *MethodAgentCancelPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamAgentCancelPledge)
+	cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameAgentCancelPledge, sendBlock.Data)
+	beneficialKey := cabi.GetPledgeBeneficialKey(param.Beneficial)
+	pledgeKey := cabi.GetAgentPledgeKey(param.PledgeAddr, beneficialKey, sendBlock.AccountAddress)
+	if err := subPledge(db, block, pledgeKey, beneficialKey, param.Amount); err != nil {
+		return nil, err
+	}
+	return []*SendBlock{
+		{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			param.Amount,
+			ledger.ViteTokenId,
+			[]byte{},
+		},
+	}, nil
+}
+
+// pledgeLockTiers are the lock tiers of the pledges from the genesis config, tier i is pledgeLockTiers[i-1]
+var pledgeLockTiers []*config.PledgeLockTier
+
+// SetPledgeLockTiers sets the lock tiers of the pledges, the lock heights must ascend and the multipliers must be
+// at least 100 percent.
+func SetPledgeLockTiers(tiers []*config.PledgeLockTier) error {
+	if len(tiers) > 255 {
+		return errors.New("too many pledge lock tiers")
+	}
+	for i, tier := range tiers {
+		if tier == nil || tier.LockHeight == 0 || tier.Multiplier < 100 ||
+			(i > 0 && tier.LockHeight <= tiers[i-1].LockHeight) {
+			return fmt.Errorf("invalid pledge lock tier %d", i+1)
+		}
+	}
+	pledgeLockTiers = tiers
+	return nil
+}
+
+// GetPledgeLockTiers returns the lock tiers of the pledges, tier i is the (i-1)th
+func GetPledgeLockTiers() []*config.PledgeLockTier {
+	return pledgeLockTiers
+}
+
+func getPledgeLockTier(tier uint8) *config.PledgeLockTier {
+	if tier == 0 || int(tier) > len(pledgeLockTiers) {
+		return nil
+	}
+	return pledgeLockTiers[tier-1]
+}
+
+// pledgeLockBonus returns the amount a pledge of amount locked for tier gets quota for beyond amount
+func pledgeLockBonus(tier *config.PledgeLockTier, amount *big.Int) *big.Int {
+	bonus := new(big.Int).Mul(amount, new(big.Int).SetUint64(tier.Multiplier-100))
+	return bonus.Quo(bonus, big.NewInt(100))
+}
+
+// updatePledgeBonus adds delta to the bonus of the locked pledges for beneficial, the bonus never gets negative
+func updatePledgeBonus(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, beneficial types.Address, delta *big.Int) {
+	bonusKey := cabi.GetPledgeBeneficialBonusKey(beneficial)
+	bonus := cabi.UnpackPledgeBeneficialBonus(db.GetStorage(&block.AccountAddress, bonusKey))
+	bonus.Add(bonus, delta)
+	if bonus.Sign() <= 0 {
+		db.SetStorage(bonusKey, nil)
+		return
+	}
+	bonusData, _ := cabi.ABIPledge.PackVariable(cabi.VariableNamePledgeBeneficialBonus, bonus)
+	db.SetStorage(bonusKey, bonusData)
+}
+
+type MethodLockPledge struct{}
+
+func (p *MethodLockPledge) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodLockPledge) GetRefundData() []byte {
+	return []byte{5}
+}
+
+func (p *MethodLockPledge) GetQuota() uint64 {
+	return LockPledgeGas
+}
+
+// pledge ViteToken for a beneficial locked for a tier, the beneficial gets the quota of the multiplier of the tier
+func (p *MethodLockPledge) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsPledgeLockFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Cmp(pledgeAmountMin2) < 0 ||
+		!util.IsViteToken(block.TokenId) ||
+		!util.IsUserAccount(db, block.AccountAddress) {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamLockPledge)
+	if err = cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameLockPledge, block.Data); err != nil ||
+		getPledgeLockTier(param.Tier) == nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIPledge.PackMethod(cabi.MethodNameLockPledge, param.Beneficial, param.Tier)
+	return quotaLeft, nil
+}
+func (p *MethodLockPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamLockPledge)
+	cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameLockPledge, sendBlock.Data)
+	tier := getPledgeLockTier(param.Tier)
+	if tier == nil {
+		return nil, util.ErrInvalidMethodParam
+	}
+	beneficialKey := cabi.GetPledgeBeneficialKey(param.Beneficial)
+	pledgeKey := cabi.GetLockPledgeKey(sendBlock.AccountAddress, beneficialKey, param.Tier)
+	addPledge(db, block, pledgeKey, beneficialKey, sendBlock.Amount, tier.LockHeight)
+	updatePledgeBonus(db, block, param.Beneficial, pledgeLockBonus(tier, sendBlock.Amount))
+	return nil, nil
+}
+
+type MethodCancelLockPledge struct{}
+
+func (p *MethodCancelLockPledge) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodCancelLockPledge) GetRefundData() []byte {
+	return []byte{6}
+}
+
+func (p *MethodCancelLockPledge) GetQuota() uint64 {
+	return CancelLockPledgeGas
+}
+
+// cancel the locked pledge after the lock of its tier
+func (p *MethodCancelLockPledge) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsPledgeLockFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() > 0 ||
+		!util.IsUserAccount(db, block.AccountAddress) {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamCancelLockPledge)
+	if err = cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameCancelLockPledge, block.Data); err != nil ||
+		getPledgeLockTier(param.Tier) == nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if param.Amount.Sign() == 0 {
+		return quotaLeft, errors.New("cancel pledge amount is 0")
+	}
+	block.Data, _ = cabi.ABIPledge.PackMethod(cabi.MethodNameCancelLockPledge, param.Beneficial, param.Tier, param.Amount)
+	return quotaLeft, nil
+}
+
+func (p *MethodCancelLockPledge) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamCancelLockPledge)
+	cabi.ABIPledge.UnpackMethod(param, cabi.MethodNameCancelLockPledge, sendBlock.Data)
+	tier := getPledgeLockTier(param.Tier)
+	if tier == nil {
+		return nil, util.ErrInvalidMethodParam
+	}
+	beneficialKey := cabi.GetPledgeBeneficialKey(param.Beneficial)
+	pledgeKey := cabi.GetLockPledgeKey(sendBlock.AccountAddress, beneficialKey, param.Tier)
+	if err := subPledge(db, block, pledgeKey, beneficialKey, param.Amount); err != nil {
+		return nil, err
+	}
+	updatePledgeBonus(db, block, param.Beneficial, new(big.Int).Neg(pledgeLockBonus(tier, param.Amount)))
 	return []*SendBlock{
 		{
 			block,
@@ -0,0 +1,104 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+var (
+	errQuotaLeaseInsufficientPledge = errors.New("insufficient pledge amount to lease")
+	errQuotaLeaseShortened          = errors.New("an active quota lease can only be extended")
+	errPledgeLeasedOut              = errors.New("pledge amount is leased out")
+)
+
+type MethodLeaseQuota struct{}
+
+func (p *MethodLeaseQuota) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodLeaseQuota) GetRefundData() []byte {
+	return []byte{1}
+}
+func (p *MethodLeaseQuota) GetQuota() uint64 {
+	return LeaseQuotaGas
+}
+
+// DoSend checks that the pledge amount of the sender not leased out yet covers the lease.
+func (p *MethodLeaseQuota) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsQuotaLeaseFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	param := new(cabi.ParamLeaseQuota)
+	if err = cabi.ABIQuotaLease.UnpackMethod(param, cabi.MethodNameLeaseQuota, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if block.Amount.Sign() > 0 ||
+		param.Amount.Sign() <= 0 ||
+		param.Period < quotaLeasePeriodMin || param.Period > quotaLeasePeriodMax ||
+		param.Beneficial == block.AccountAddress {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if err = checkQuotaLease(db, block.AccountAddress, param); err != nil {
+		return quotaLeft, err
+	}
+	block.Data, _ = cabi.ABIQuotaLease.PackMethod(cabi.MethodNameLeaseQuota, param.Beneficial, param.Amount, param.Period)
+	return quotaLeft, nil
+}
+
+// DoReceive checks the lease again at the snapshot block of the receive block and keeps it until the period passes,
+// a new lease to the same beneficial replaces the old one.
+func (p *MethodLeaseQuota) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamLeaseQuota)
+	cabi.ABIQuotaLease.UnpackMethod(param, cabi.MethodNameLeaseQuota, sendBlock.Data)
+	if err := checkQuotaLease(db, sendBlock.AccountAddress, param); err != nil {
+		return nil, err
+	}
+	expirationHeight := db.CurrentSnapshotBlock().Height + param.Period
+	data, _ := cabi.ABIQuotaLease.PackVariable(cabi.VariableNameQuotaLease, param.Amount, expirationHeight)
+	db.SetStorage(cabi.GetQuotaLeaseOutKey(sendBlock.AccountAddress, param.Beneficial), data)
+	db.SetStorage(cabi.GetQuotaLeaseInKey(param.Beneficial, sendBlock.AccountAddress), data)
+	db.AddLog(util.NewLog(cabi.ABIQuotaLease, cabi.EventNameQuotaLeased, sendBlock.AccountAddress, param.Beneficial, param.Amount, expirationHeight))
+	return nil, nil
+}
+
+// checkQuotaLease checks that an active lease of lessor to the beneficial is not shortened, and that the pledge
+// amount of lessor covers the lease besides its other active leases.
+func checkQuotaLease(db vmctxt_interface.VmDatabase, lessor types.Address, param *cabi.ParamLeaseQuota) error {
+	snapshotHeight := db.CurrentSnapshotBlock().Height
+	if old := cabi.GetQuotaLease(db, lessor, param.Beneficial); old != nil && old.IsActive(snapshotHeight) &&
+		(old.Amount.Cmp(param.Amount) > 0 || old.ExpirationHeight > snapshotHeight+param.Period) {
+		return errQuotaLeaseShortened
+	}
+	leasedOut := big.NewInt(0)
+	for _, lease := range cabi.GetQuotaLeaseOutList(db, lessor) {
+		if lease.Beneficial != param.Beneficial && lease.IsActive(snapshotHeight) {
+			leasedOut.Add(leasedOut, lease.Amount)
+		}
+	}
+	if cabi.GetPledgeBeneficialAmount(db, lessor).Cmp(leasedOut.Add(leasedOut, param.Amount)) < 0 {
+		return errQuotaLeaseInsufficientPledge
+	}
+	return nil
+}
+
+// checkPledgeNotLeasedOut checks that beneficialAmount left by a cancel still covers the active leases of beneficial
+func checkPledgeNotLeasedOut(db vmctxt_interface.VmDatabase, beneficial types.Address, beneficialAmount *big.Int) error {
+	snapshotHeight := db.CurrentSnapshotBlock().Height
+	if !fork.IsQuotaLeaseFork(snapshotHeight) {
+		return nil
+	}
+	if beneficialAmount.Cmp(cabi.GetActiveQuotaLeaseAmount(cabi.GetQuotaLeaseOutList(db, beneficial), snapshotHeight)) < 0 {
+		return errPledgeLeasedOut
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package contracts
 
 import (
 	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/consensus/core"
@@ -10,6 +11,7 @@ import (
 	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
 	"math/big"
+	"regexp"
 	"time"
 )
 
@@ -60,7 +62,7 @@ func checkRegisterData(methodName string, db vmctxt_interface.VmDatabase, block
 	return nil
 }
 
-func (p *MethodRegister) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodRegister) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamRegister)
 	cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameRegister, sendBlock.Data)
 
@@ -161,7 +163,7 @@ func (p *MethodCancelRegister) DoSend(db vmctxt_interface.VmDatabase, block *led
 	block.Data, _ = cabi.ABIRegister.PackMethod(cabi.MethodNameCancelRegister, param.Gid, param.Name)
 	return quotaLeft, nil
 }
-func (p *MethodCancelRegister) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodCancelRegister) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamCancelRegister)
 	cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameCancelRegister, sendBlock.Data)
 
@@ -239,20 +241,23 @@ func (p *MethodReward) DoSend(db vmctxt_interface.VmDatabase, block *ledger.Acco
 	block.Data, _ = cabi.ABIRegister.PackMethod(cabi.MethodNameReward, param.Gid, param.Name, param.BeneficialAddr)
 	return quotaLeft, nil
 }
-func (p *MethodReward) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodReward) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamReward)
 	cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameReward, sendBlock.Data)
 	key := cabi.GetRegisterKey(param.Name, param.Gid)
 	old := new(types.Registration)
 	err := cabi.ABIRegister.UnpackVariable(old, cabi.VariableNameRegistration, db.GetStorage(&block.AccountAddress, key))
-	if err != nil || sendBlock.AccountAddress != old.PledgeAddr {
+	if err != nil || (sendBlock.AccountAddress != old.PledgeAddr && !isRewardWithdrawAddr(db, block, param.Gid, param.Name, sendBlock.AccountAddress)) {
 		return nil, errors.New("invalid owner")
 	}
-	_, endIndex, reward, _, err := CalcReward(db, old, param.Gid)
+	_, endIndex, reward, periodTime, err := CalcReward(db, old, param.Gid)
 	if err != nil {
 		panic(err)
 	}
 	if endIndex != old.RewardIndex {
+		if err = meter.Use(RewardPerDayGas, rewardDays(old.RewardIndex, endIndex, nodeConfig.params.RewardTimeUnit/periodTime)); err != nil {
+			return nil, err
+		}
 		registerInfo, _ := cabi.ABIRegister.PackVariable(
 			cabi.VariableNameRegistration,
 			old.Name,
@@ -281,6 +286,13 @@ func (p *MethodReward) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.A
 	return nil, nil
 }
 
+// isRewardWithdrawAddr reports whether addr is the reward withdraw address set for the registration
+func isRewardWithdrawAddr(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, gid types.Gid, name string, addr types.Address) bool {
+	withdrawAddr := new(types.Address)
+	err := cabi.ABIRegister.UnpackVariable(withdrawAddr, cabi.VariableNameRewardWithdrawAddr, db.GetStorage(&block.AccountAddress, cabi.GetRewardWithdrawAddrKey(name, gid)))
+	return err == nil && *withdrawAddr == addr
+}
+
 func IndexToTime(index uint64, genesisTime int64, periodTime uint64) int64 {
 	return genesisTime + int64(periodTime*index)
 }
@@ -400,6 +412,16 @@ func CalcReward(db vmctxt_interface.VmDatabase, old *types.Registration, gid typ
 	return old.RewardIndex, endIndex, reward, periodTime, nil
 }
 
+// rewardDays returns the count of days CalcReward settles from the index after rewardIndex to endIndex,
+// the first one may be partial.
+func rewardDays(rewardIndex, endIndex, indexPerDay uint64) uint64 {
+	if endIndex <= rewardIndex {
+		return 0
+	}
+	startDayIndex := ((rewardIndex+indexPerDay)/indexPerDay-1)*indexPerDay + 1
+	return (endIndex-startDayIndex)/indexPerDay + 1
+}
+
 func getPeriodIndex(startIndex, endIndex, indexPerDay, startDayIndex uint64) (periodEndIndex, count uint64) {
 	preCount := startIndex - startDayIndex
 	if preCount < indexPerDay {
@@ -443,7 +465,7 @@ func (p *MethodUpdateRegistration) DoSend(db vmctxt_interface.VmDatabase, block
 	block.Data, _ = cabi.ABIRegister.PackMethod(cabi.MethodNameUpdateRegistration, param.Gid, param.Name, param.NodeAddr)
 	return quotaLeft, nil
 }
-func (p *MethodUpdateRegistration) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodUpdateRegistration) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamRegister)
 	cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameUpdateRegistration, sendBlock.Data)
 
@@ -480,3 +502,120 @@ func (p *MethodUpdateRegistration) DoReceive(db vmctxt_interface.VmDatabase, blo
 	db.SetStorage(key, registerInfo)
 	return nil, nil
 }
+
+// checkRegistrationOwner returns the active registration of name in gid if it belongs to the pledge address addr
+func checkRegistrationOwner(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, gid types.Gid, name string, addr types.Address) (*types.Registration, error) {
+	old := new(types.Registration)
+	err := cabi.ABIRegister.UnpackVariable(old, cabi.VariableNameRegistration, db.GetStorage(&block.AccountAddress, cabi.GetRegisterKey(name, gid)))
+	if err != nil || !old.IsActive() || old.PledgeAddr != addr {
+		return nil, errors.New("register not exist or already canceled")
+	}
+	return old, nil
+}
+
+type MethodUpdateDisplayName struct {
+}
+
+func (p *MethodUpdateDisplayName) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodUpdateDisplayName) GetRefundData() []byte {
+	return []byte{5}
+}
+func (p *MethodUpdateDisplayName) GetQuota() uint64 {
+	return UpdateDisplayNameGas
+}
+
+// update the display name of a registration, the name registered is kept for the votes
+func (p *MethodUpdateDisplayName) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsRegistrationDetailFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() != 0 ||
+		!util.IsUserAccount(db, block.AccountAddress) {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamUpdateDisplayName)
+	if err = cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameUpdateDisplayName, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if param.Gid == types.DELEGATE_GID ||
+		len(param.DisplayName) == 0 ||
+		len(param.DisplayName) > registrationNameLengthMax {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if ok, _ := regexp.MatchString("^([0-9a-zA-Z_.]+[ ]?)*[0-9a-zA-Z_.]$", param.DisplayName); !ok {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIRegister.PackMethod(cabi.MethodNameUpdateDisplayName, param.Gid, param.Name, param.DisplayName)
+	return quotaLeft, nil
+}
+func (p *MethodUpdateDisplayName) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamUpdateDisplayName)
+	cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameUpdateDisplayName, sendBlock.Data)
+	if _, err := checkRegistrationOwner(db, block, param.Gid, param.Name, sendBlock.AccountAddress); err != nil {
+		return nil, err
+	}
+	displayNameData, _ := cabi.ABIRegister.PackVariable(cabi.VariableNameDisplayName, param.DisplayName)
+	db.SetStorage(cabi.GetDisplayNameKey(param.Name, param.Gid), displayNameData)
+	return nil, nil
+}
+
+type MethodUpdateRewardWithdrawAddress struct {
+}
+
+func (p *MethodUpdateRewardWithdrawAddress) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodUpdateRewardWithdrawAddress) GetRefundData() []byte {
+	return []byte{6}
+}
+func (p *MethodUpdateRewardWithdrawAddress) GetQuota() uint64 {
+	return UpdateRewardWithdrawGas
+}
+
+// update the address which may withdraw the reward of a registration besides the pledge address, the pledge
+// address itself removes it
+func (p *MethodUpdateRewardWithdrawAddress) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsRegistrationDetailFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() != 0 ||
+		!util.IsUserAccount(db, block.AccountAddress) {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamUpdateRewardWithdrawAddress)
+	if err = cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameUpdateRewardWithdrawAddress, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if !util.IsSnapshotGid(param.Gid) {
+		return quotaLeft, errors.New("consensus group has no reward")
+	}
+	block.Data, _ = cabi.ABIRegister.PackMethod(cabi.MethodNameUpdateRewardWithdrawAddress, param.Gid, param.Name, param.WithdrawAddr)
+	return quotaLeft, nil
+}
+func (p *MethodUpdateRewardWithdrawAddress) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamUpdateRewardWithdrawAddress)
+	cabi.ABIRegister.UnpackMethod(param, cabi.MethodNameUpdateRewardWithdrawAddress, sendBlock.Data)
+	if _, err := checkRegistrationOwner(db, block, param.Gid, param.Name, sendBlock.AccountAddress); err != nil {
+		return nil, err
+	}
+	key := cabi.GetRewardWithdrawAddrKey(param.Name, param.Gid)
+	if param.WithdrawAddr == sendBlock.AccountAddress {
+		db.SetStorage(key, nil)
+		return nil, nil
+	}
+	withdrawAddrData, _ := cabi.ABIRegister.PackVariable(cabi.VariableNameRewardWithdrawAddr, param.WithdrawAddr)
+	db.SetStorage(key, withdrawAddrData)
+	return nil, nil
+}
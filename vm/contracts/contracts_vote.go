@@ -2,6 +2,7 @@ package contracts
 
 import (
 	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
@@ -58,12 +59,13 @@ func (p *MethodVote) DoSend(db vmctxt_interface.VmDatabase, block *ledger.Accoun
 	return quotaLeft, nil
 }
 
-func (p *MethodVote) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodVote) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	param := new(cabi.ParamVote)
 	cabi.ABIVote.UnpackMethod(param, cabi.MethodNameVote, sendBlock.Data)
 	voteKey := cabi.GetVoteKey(sendBlock.AccountAddress, param.Gid)
 	voteStatus, _ := cabi.ABIVote.PackVariable(cabi.VariableNameVoteStatus, param.NodeName)
 	db.SetStorage(voteKey, voteStatus)
+	clearSplitVote(db, block, sendBlock.AccountAddress, param.Gid)
 	return nil, nil
 }
 
@@ -101,10 +103,105 @@ func (p *MethodCancelVote) DoSend(db vmctxt_interface.VmDatabase, block *ledger.
 	return quotaLeft, nil
 }
 
-func (p *MethodCancelVote) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+func (p *MethodCancelVote) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
 	gid := new(types.Gid)
 	cabi.ABIVote.UnpackMethod(gid, cabi.MethodNameCancelVote, sendBlock.Data)
 	voteKey := cabi.GetVoteKey(sendBlock.AccountAddress, *gid)
 	db.SetStorage(voteKey, nil)
+	clearSplitVote(db, block, sendBlock.AccountAddress, *gid)
+	return nil, nil
+}
+
+// clearSplitVote removes the votes of addr split across several nodes, a single vote replaces them
+func clearSplitVote(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, addr types.Address, gid types.Gid) {
+	splitVoteKey := cabi.GetSplitVoteKey(addr, gid)
+	if len(db.GetStorage(&block.AccountAddress, splitVoteKey)) > 0 {
+		db.SetStorage(splitVoteKey, nil)
+	}
+}
+
+type MethodSplitVote struct {
+}
+
+func (p *MethodSplitVote) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodSplitVote) GetRefundData() []byte {
+	return []byte{3}
+}
+func (p *MethodSplitVote) GetQuota() uint64 {
+	return SplitVoteGas
+}
+
+// split the votes of an address across several super nodes of a consensus group, each node counts the share of
+// the balance by its weight, the weights sum up to types.VoteWeightTotal
+func (p *MethodSplitVote) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsSplitVoteFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+
+	param := new(cabi.ParamSplitVote)
+	if err = cabi.ABIVote.UnpackMethod(param, cabi.MethodNameSplitVote, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if param.Gid == types.DELEGATE_GID {
+		return quotaLeft, errors.New("cannot vote consensus group")
+	}
+	nodeNameList := cabi.SplitVoteNodeNameList(param.NodeNames)
+	if len(nodeNameList) == 0 || len(nodeNameList) > splitVoteNodeCountMax ||
+		len(nodeNameList) != len(param.WeightList) {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	quotaLeft, err = util.UseQuota(quotaLeft, SplitVotePerNodeGas*uint64(len(nodeNameList)))
+	if err != nil {
+		return quotaLeft, err
+	}
+
+	consensusGroupInfo := cabi.GetConsensusGroup(db, param.Gid)
+	if consensusGroupInfo == nil {
+		return quotaLeft, errors.New("consensus group not exist")
+	}
+
+	weightTotal := uint64(0)
+	nodeNameSet := make(map[string]bool, len(nodeNameList))
+	for i, nodeName := range nodeNameList {
+		if len(nodeName) == 0 || nodeNameSet[nodeName] ||
+			param.WeightList[i] == 0 || param.WeightList[i] > types.VoteWeightTotal {
+			return quotaLeft, util.ErrInvalidMethodParam
+		}
+		nodeNameSet[nodeName] = true
+		weightTotal = weightTotal + param.WeightList[i]
+		if !cabi.IsActiveRegistration(db, nodeName, param.Gid) {
+			return quotaLeft, errors.New("registration not exist")
+		}
+	}
+	if weightTotal != types.VoteWeightTotal {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+
+	if condition, ok := getConsensusGroupCondition(consensusGroupInfo.VoteConditionId, cabi.VoteConditionPrefix); !ok {
+		return quotaLeft, errors.New("consensus group vote condition not exist")
+	} else if !condition.checkData(consensusGroupInfo.VoteConditionParam, db, block, param, cabi.MethodNameSplitVote) {
+		return quotaLeft, errors.New("check vote condition failed")
+	}
+
+	block.Data, _ = cabi.ABIVote.PackMethod(cabi.MethodNameSplitVote, param.Gid, param.NodeNames, param.WeightList)
+	return quotaLeft, nil
+}
+
+func (p *MethodSplitVote) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, meter *util.QuotaMeter) ([]*SendBlock, error) {
+	param := new(cabi.ParamSplitVote)
+	cabi.ABIVote.UnpackMethod(param, cabi.MethodNameSplitVote, sendBlock.Data)
+	voteStatus, _ := cabi.ABIVote.PackVariable(cabi.VariableNameSplitVoteStatus, param.NodeNames, param.WeightList)
+	db.SetStorage(cabi.GetSplitVoteKey(sendBlock.AccountAddress, param.Gid), voteStatus)
+	voteKey := cabi.GetVoteKey(sendBlock.AccountAddress, param.Gid)
+	if len(db.GetStorage(&block.AccountAddress, voteKey)) > 0 {
+		db.SetStorage(voteKey, nil)
+	}
 	return nil, nil
 }
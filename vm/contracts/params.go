@@ -8,12 +8,20 @@ import (
 const (
 	RegisterGas               uint64 = 62200
 	UpdateRegistrationGas     uint64 = 62200
+	UpdateDisplayNameGas      uint64 = 62200
+	UpdateRewardWithdrawGas   uint64 = 62200
 	CancelRegisterGas         uint64 = 83200
 	RewardGas                 uint64 = 238800
 	VoteGas                   uint64 = 62000
 	CancelVoteGas             uint64 = 62000
+	SplitVoteGas              uint64 = 62000
+	SplitVotePerNodeGas       uint64 = 5000 // Per node the votes are split across
 	PledgeGas                 uint64 = 21000
 	CancelPledgeGas           uint64 = 21000
+	AgentPledgeGas            uint64 = 21000
+	AgentCancelPledgeGas      uint64 = 21000
+	LockPledgeGas             uint64 = 21000
+	CancelLockPledgeGas       uint64 = 21000
 	CreateConsensusGroupGas   uint64 = 62200
 	CancelConsensusGroupGas   uint64 = 83200
 	ReCreateConsensusGroupGas uint64 = 62200
@@ -24,6 +32,32 @@ const (
 	BurnGas                   uint64 = 48837
 	TransferOwnerGas          uint64 = 58981
 	ChangeTokenTypeGas        uint64 = 63125
+	AmmDepositGas             uint64 = 21000
+	AmmWithdrawGas            uint64 = 21000
+	AmmWithdrawBatchGas       uint64 = 21000
+	AmmTransferGas            uint64 = 21000
+	AmmCreatePoolGas          uint64 = 62200
+	AmmAddLiquidityGas        uint64 = 62200
+	AmmRemoveLiquidityGas     uint64 = 62200
+	AmmSwapGas                uint64 = 42000
+	AmmSetRewardGas           uint64 = 62200
+	AmmClaimRewardGas         uint64 = 42000
+	AmmSetDividendTokenGas    uint64 = 62200
+	AmmDividendGas            uint64 = 42000
+	AllowanceDepositGas       uint64 = 21000
+	AllowanceWithdrawGas      uint64 = 21000
+	AllowanceApproveGas       uint64 = 21000
+	AllowanceTransferFromGas  uint64 = 42000
+	RegisterEventGas          uint64 = 62200
+	CheckpointGas             uint64 = 21000
+	LeaseQuotaGas             uint64 = 42000
+
+	// Quota used at receive for each unit of work, metered since the ReceiveQuota fork
+	RewardPerDayGas           uint64 = 5000 // Per day of reward settled
+	ConditionParamPerWordGas  uint64 = 5000 // Per 32 bytes of register and vote condition params of consensus group stored
+	EventDefinitionPerWordGas uint64 = 5000 // Per 32 bytes of event definition registered
+	AmmWithdrawPerTokenGas    uint64 = 5000 // Per token withdrawn by a batch withdraw of amm
+	AmmDividendPerHolderGas   uint64 = 5000 // Per holder of the dividend token and token of the fees distributed by amm
 
 	cgNodeCountMin   uint8 = 3       // Minimum node count of consensus group
 	cgNodeCountMax   uint8 = 101     // Maximum node count of consensus group
@@ -39,6 +73,14 @@ const (
 
 	registrationNameLengthMax int = 40
 
+	splitVoteNodeCountMax int = 5 // Maximum count of nodes the votes of an address are split across
+
+	ammWithdrawBatchMax int    = 16    // Maximum count of tokens withdrawn by a batch withdraw of amm
+	ammDividendPeriod   uint64 = 86400 // Minimum seconds between two dividends of the swap fees of amm
+
+	quotaLeasePeriodMin uint64 = 75             // Minimum snapshot blocks a quota lease lasts
+	quotaLeasePeriodMax uint64 = 3600 * 24 * 30 // Maximum snapshot blocks a quota lease lasts
+
 	tokenNameLengthMax   int = 40 // Maximum length of a token name(include)
 	tokenSymbolLengthMax int = 10 // Maximum length of a token symbol(include)
 )
@@ -52,6 +94,11 @@ var (
 	mintagePledgeAmount              = new(big.Int).Mul(big.NewInt(1e5), util.AttovPerVite) // Mintage cost choice 2, pledge ViteToken for 3 month
 	createConsensusGroupPledgeAmount = new(big.Int).Mul(big.NewInt(1000), util.AttovPerVite)
 
+	ammFeeRate         = big.NewInt(3) // 3/1000 of the input amount of a swap is left in the pool for liquidity providers
+	ammFeeBase         = big.NewInt(1000)
+	ammMinShares       = big.NewInt(1000) // Shares locked at the first deposit of a pool, so that the pool never runs out
+	ammDividendFeeRate = big.NewInt(1)    // 1/1000 of the input amount of a swap is taken out of the fee for the dividend once the dividend token is set
+
 	float1                = new(big.Float).SetPrec(rewardPrecForFloat).SetInt64(1)
 	additionForVoteReward = new(big.Int).Mul(big.NewInt(5e5), util.AttovPerVite)
 )
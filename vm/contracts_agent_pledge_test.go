@@ -0,0 +1,80 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestContractsAgentPledge(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, AgentPledge: &config.ForkPoint{Height: 2}})
+	defer initFork()
+	contracts.InitContractsConfig(true)
+	defer contracts.InitContractsConfig(false)
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, agent, _, _, _, _ := prepareDb(viteTotalSupply)
+	pledgeAddr, _, _ := types.CreateAddress()
+	beneficial, _, _ := types.CreateAddress()
+	pledgeAmount := new(big.Int).Mul(big.NewInt(1000), util.AttovPerVite)
+
+	run := func(method contracts.PrecompiledContractMethod, from types.Address, amount *big.Int, data []byte) ([]*contracts.SendBlock, error) {
+		sendBlock := &ledger.AccountBlock{Height: 3, AccountAddress: from, ToAddress: types.AddressPledge, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: amount, Data: data, Hash: types.DataHash(data)}
+		db.addr = from
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return nil, err
+		}
+		db.addr = types.AddressPledge
+		return method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressPledge}, sendBlock, util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit))
+	}
+
+	data, _ := abi.ABIPledge.PackMethod(abi.MethodNameAgentPledge, pledgeAddr, beneficial)
+	if _, err := run(&contracts.MethodAgentPledge{}, agent, pledgeAmount, data); err != nil {
+		t.Fatal(err)
+	}
+	db.addr = types.AddressPledge
+	if amount := abi.GetPledgeBeneficialAmount(db, beneficial); amount.Cmp(pledgeAmount) != 0 {
+		t.Fatalf("unexpected beneficial amount %v", amount)
+	}
+	if list, amount := abi.GetPledgeInfoList(db, pledgeAddr); len(list) != 1 || amount.Cmp(pledgeAmount) != 0 ||
+		!list[0].IsAgent || list[0].AgentAddr != agent || list[0].BeneficialAddr != beneficial {
+		t.Fatalf("unexpected pledge info list %v", list)
+	}
+	if list, _ := abi.GetPledgeInfoList(db, agent); len(list) != 0 {
+		t.Fatalf("agent should have no pledge of its own, got %v", list)
+	}
+
+	// only the agent cancels, and not before the pledge is due
+	data, _ = abi.ABIPledge.PackMethod(abi.MethodNameAgentCancelPledge, pledgeAddr, beneficial, pledgeAmount)
+	if _, err := run(&contracts.MethodAgentCancelPledge{}, agent, big.NewInt(0), data); err == nil {
+		t.Fatal("pledge should not be due")
+	}
+	now := time.Now()
+	db.snapshotBlockList = append(db.snapshotBlockList, &ledger.SnapshotBlock{Height: db.CurrentSnapshotBlock().Height + 1, Timestamp: &now})
+	if _, err := run(&contracts.MethodAgentCancelPledge{}, pledgeAddr, big.NewInt(0), data); err == nil {
+		t.Fatal("pledge address should not cancel the pledge of the agent")
+	}
+	cancelData, _ := abi.ABIPledge.PackMethod(abi.MethodNameCancelPledge, beneficial, pledgeAmount)
+	if _, err := run(&contracts.MethodCancelPledge{}, pledgeAddr, big.NewInt(0), cancelData); err == nil {
+		t.Fatal("pledge address should not cancel the pledge of the agent")
+	}
+
+	sendBlocks, err := run(&contracts.MethodAgentCancelPledge{}, agent, big.NewInt(0), data)
+	if err != nil || len(sendBlocks) != 1 || sendBlocks[0].ToAddress != agent || sendBlocks[0].Amount.Cmp(pledgeAmount) != 0 {
+		t.Fatalf("unexpected cancel result %v, %v", sendBlocks, err)
+	}
+	db.addr = types.AddressPledge
+	if amount := abi.GetPledgeBeneficialAmount(db, beneficial); amount.Sign() != 0 {
+		t.Fatalf("unexpected beneficial amount %v after cancel", amount)
+	}
+	if list, _ := abi.GetPledgeInfoList(db, pledgeAddr); len(list) != 0 {
+		t.Fatalf("unexpected pledge info list %v after cancel", list)
+	}
+}
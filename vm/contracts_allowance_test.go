@@ -0,0 +1,88 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+)
+
+func TestContractsAllowance(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, Allowance: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+	spender, _ := types.BytesToAddress([]byte{2})
+	receiver, _ := types.BytesToAddress([]byte{3})
+
+	call := func(method contracts.PrecompiledContractMethod, from types.Address, amount *big.Int, data []byte) ([]*contracts.SendBlock, error) {
+		sendBlock := &ledger.AccountBlock{AccountAddress: from, ToAddress: types.AddressAllowance, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: amount, Data: data}
+		db.addr = from
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return nil, err
+		}
+		db.addr = types.AddressAllowance
+		return method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressAllowance}, sendBlock, nil)
+	}
+
+	data, _ := abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceDeposit)
+	if _, err := call(&contracts.MethodAllowanceDeposit{}, addr1, big.NewInt(1000), data); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceApprove, addr1, ledger.ViteTokenId, big.NewInt(600))
+	if _, err := call(&contracts.MethodAllowanceApprove{}, addr1, big.NewInt(0), data); err != util.ErrInvalidMethodParam {
+		t.Fatalf("approve self should fail, got %v", err)
+	}
+	data, _ = abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceApprove, spender, ledger.ViteTokenId, big.NewInt(600))
+	if _, err := call(&contracts.MethodAllowanceApprove{}, addr1, big.NewInt(0), data); err != nil {
+		t.Fatal(err)
+	}
+
+	// the spender pulls from the deposit of addr1 to the receiver
+	data, _ = abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceTransferFrom, addr1, ledger.ViteTokenId, big.NewInt(400), receiver)
+	sendBlocks, err := call(&contracts.MethodAllowanceTransferFrom{}, spender, big.NewInt(0), data)
+	if err != nil || len(sendBlocks) != 1 || sendBlocks[0].ToAddress != receiver || sendBlocks[0].Amount.Cmp(big.NewInt(400)) != 0 {
+		t.Fatalf("unexpected transfer result %v %v", sendBlocks, err)
+	}
+	if allowance := abi.GetAllowance(db, addr1, spender, ledger.ViteTokenId); allowance.Cmp(big.NewInt(200)) != 0 {
+		t.Fatalf("unexpected allowance %v", allowance)
+	}
+	data, _ = abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceTransferFrom, addr1, ledger.ViteTokenId, big.NewInt(300), receiver)
+	if _, err := call(&contracts.MethodAllowanceTransferFrom{}, spender, big.NewInt(0), data); err == nil {
+		t.Fatal("transfer more than the allowance should fail")
+	}
+	if _, err := call(&contracts.MethodAllowanceTransferFrom{}, receiver, big.NewInt(0), data); err == nil {
+		t.Fatal("transfer without approval should fail")
+	}
+
+	// the rest of the deposit can be withdrawn
+	data, _ = abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceWithdraw, ledger.ViteTokenId, big.NewInt(700))
+	if _, err := call(&contracts.MethodAllowanceWithdraw{}, addr1, big.NewInt(0), data); err == nil {
+		t.Fatal("withdraw more than the balance should fail")
+	}
+	data, _ = abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceWithdraw, ledger.ViteTokenId, big.NewInt(600))
+	sendBlocks, err = call(&contracts.MethodAllowanceWithdraw{}, addr1, big.NewInt(0), data)
+	if err != nil || len(sendBlocks) != 1 || sendBlocks[0].ToAddress != addr1 || abi.GetAllowanceBalance(db, addr1, ledger.ViteTokenId).Sign() != 0 {
+		t.Fatalf("unexpected withdraw result %v %v", sendBlocks, err)
+	}
+	if len(db.logList) != 2 || db.logList[1].Topics[0] != abi.ABIAllowance.Events[abi.EventNameAllowanceTransfer].Id() {
+		t.Fatalf("unexpected logs %v", db.logList)
+	}
+}
+
+func TestContractsAllowanceBeforeFork(t *testing.T) {
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+	data, _ := abi.ABIAllowance.PackMethod(abi.MethodNameAllowanceDeposit)
+	block := &ledger.AccountBlock{AccountAddress: addr1, ToAddress: types.AddressAllowance, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: big.NewInt(1), Data: data}
+	db.addr = addr1
+	if _, err := (&contracts.MethodAllowanceDeposit{}).DoSend(db, block, 1e6); err != util.ErrVersionNotSupport {
+		t.Fatalf("expected ErrVersionNotSupport, got %v", err)
+	}
+}
@@ -0,0 +1,357 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestContractsAmm(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, Amm: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, timestamp := prepareDb(viteTotalSupply)
+	tokenA := ledger.ViteTokenId
+	tokenB := abi.NewTokenId(addr1, 3, hash12, snapshot2.Hash)
+	db.storageMap[types.AddressMintage][string(abi.GetMintageKey(tokenB))], _ = abi.ABIMintage.PackVariable(abi.VariableNameMintage, "test token", "t", big.NewInt(1e18), uint8(18), addr1, big.NewInt(0), uint64(0))
+	if _, _, swapped := abi.SortTokenPair(tokenA, tokenB); swapped {
+		tokenA, tokenB = tokenB, tokenA
+	}
+
+	send := func(method contracts.PrecompiledContractMethod, tokenId types.TokenTypeId, amount *big.Int, data []byte) *ledger.AccountBlock {
+		block := &ledger.AccountBlock{AccountAddress: addr1, ToAddress: types.AddressAmm, BlockType: ledger.BlockTypeSendCall, TokenId: tokenId, Amount: amount, Data: data}
+		db.addr = addr1
+		if _, err := method.DoSend(db, block, 1e6); err != nil {
+			t.Fatalf("send %T failed, %v", method, err)
+		}
+		return block
+	}
+	receive := func(method contracts.PrecompiledContractMethod, sendBlock *ledger.AccountBlock) ([]*contracts.SendBlock, error) {
+		db.addr = types.AddressAmm
+		return method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressAmm}, sendBlock, nil)
+	}
+
+	// create pool with tokens in any order
+	data, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmCreatePool, tokenB, tokenA)
+	createBlock := send(&contracts.MethodAmmCreatePool{}, ledger.ViteTokenId, big.NewInt(0), data)
+	if _, err := receive(&contracts.MethodAmmCreatePool{}, createBlock); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := receive(&contracts.MethodAmmCreatePool{}, createBlock); err == nil {
+		t.Fatal("create existing pool should fail")
+	}
+	pool := abi.GetAmmPool(db, tokenA, tokenB)
+	if pool == nil || pool.TokenA != tokenA || pool.TokenB != tokenB || pool.LastTimestamp != uint64(timestamp) {
+		t.Fatalf("unexpected pool %+v", pool)
+	}
+
+	// deposit and add liquidity
+	deposit, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmDeposit)
+	for _, d := range []struct {
+		tokenId types.TokenTypeId
+		amount  int64
+	}{{tokenA, 2e6}, {tokenB, 4e6}} {
+		if _, err := receive(&contracts.MethodAmmDeposit{}, send(&contracts.MethodAmmDeposit{}, d.tokenId, big.NewInt(d.amount), deposit)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmAddLiquidity, tokenA, tokenB, big.NewInt(1e6), big.NewInt(4e6), big.NewInt(0))
+	if _, err := receive(&contracts.MethodAmmAddLiquidity{}, send(&contracts.MethodAmmAddLiquidity{}, tokenA, big.NewInt(0), data)); err != nil {
+		t.Fatal(err)
+	}
+	shares := abi.GetAmmShares(db, tokenA, tokenB, addr1)
+	if shares.Cmp(big.NewInt(2e6-1000)) != 0 ||
+		abi.GetAmmFund(db, addr1, tokenA).Cmp(big.NewInt(1e6)) != 0 ||
+		abi.GetAmmFund(db, addr1, tokenB).Sign() != 0 {
+		t.Fatalf("unexpected shares %v or funds", shares)
+	}
+	if fundList := abi.GetAmmFundList(db, addr1); len(fundList) != 1 || fundList[tokenA].Cmp(big.NewInt(1e6)) != 0 {
+		t.Fatalf("unexpected fund list %v", fundList)
+	}
+
+	// tokenB is used up
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmAddLiquidity, tokenA, tokenB, big.NewInt(1e6), big.NewInt(4e6), big.NewInt(0))
+	if _, err := receive(&contracts.MethodAmmAddLiquidity{}, send(&contracts.MethodAmmAddLiquidity{}, tokenA, big.NewInt(0), data)); err == nil {
+		t.Fatal("add liquidity without enough fund should fail")
+	}
+
+	// swap 10 seconds later
+	t3 := time.Unix(timestamp+10, 0)
+	db.snapshotBlockList = append(db.snapshotBlockList, &ledger.SnapshotBlock{Height: 3, Timestamp: &t3, Hash: types.DataHash([]byte{10, 3})})
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmSwap, tokenB, big.NewInt(0))
+	sendBlocks, err := receive(&contracts.MethodAmmSwap{}, send(&contracts.MethodAmmSwap{}, tokenA, big.NewInt(1e4), data))
+	if err != nil || len(sendBlocks) != 1 || sendBlocks[0].TokenId != tokenB || sendBlocks[0].Amount.Cmp(big.NewInt(39486)) != 0 {
+		t.Fatalf("unexpected swap result %v %v", sendBlocks, err)
+	}
+	pool = abi.GetAmmPool(db, tokenA, tokenB)
+	priceACumulative := new(big.Int).Lsh(big.NewInt(40), 64)
+	if pool.ReserveA.Cmp(big.NewInt(1e6+1e4)) != 0 || pool.ReserveB.Cmp(big.NewInt(4e6-39486)) != 0 ||
+		pool.PriceACumulative.Cmp(priceACumulative) != 0 || pool.LastTimestamp != uint64(timestamp+10) {
+		t.Fatalf("unexpected pool after swap %+v", pool)
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmSwap, tokenB, big.NewInt(1e6))
+	if _, err := receive(&contracts.MethodAmmSwap{}, send(&contracts.MethodAmmSwap{}, tokenA, big.NewInt(1e4), data)); err == nil {
+		t.Fatal("swap below min amount out should fail")
+	}
+
+	// remove all the liquidity of addr1
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmRemoveLiquidity, tokenB, tokenA, shares, big.NewInt(0), big.NewInt(0))
+	sendBlocks, err = receive(&contracts.MethodAmmRemoveLiquidity{}, send(&contracts.MethodAmmRemoveLiquidity{}, tokenA, big.NewInt(0), data))
+	if err != nil || len(sendBlocks) != 2 || sendBlocks[0].TokenId != tokenA || sendBlocks[1].TokenId != tokenB {
+		t.Fatalf("unexpected remove liquidity result %v %v", sendBlocks, err)
+	}
+	if pool = abi.GetAmmPool(db, tokenA, tokenB); pool.TotalShares.Cmp(big.NewInt(1000)) != 0 ||
+		abi.GetAmmShares(db, tokenA, tokenB, addr1).Sign() != 0 {
+		t.Fatalf("unexpected pool after remove liquidity %+v", pool)
+	}
+
+	// withdraw the fund
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmWithdraw, tokenA, big.NewInt(2e6))
+	if _, err := receive(&contracts.MethodAmmWithdraw{}, send(&contracts.MethodAmmWithdraw{}, tokenA, big.NewInt(0), data)); err == nil {
+		t.Fatal("withdraw more than the fund should fail")
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmWithdraw, tokenA, big.NewInt(1e6))
+	sendBlocks, err = receive(&contracts.MethodAmmWithdraw{}, send(&contracts.MethodAmmWithdraw{}, tokenA, big.NewInt(0), data))
+	if err != nil || len(sendBlocks) != 1 || sendBlocks[0].Amount.Cmp(big.NewInt(1e6)) != 0 || abi.GetAmmFund(db, addr1, tokenA).Sign() != 0 {
+		t.Fatalf("unexpected withdraw result %v %v", sendBlocks, err)
+	}
+
+	// withdraw several tokens at once, all or none of them
+	for _, tokenId := range []types.TokenTypeId{tokenA, tokenB} {
+		if _, err := receive(&contracts.MethodAmmDeposit{}, send(&contracts.MethodAmmDeposit{}, tokenId, big.NewInt(3e5), deposit)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmWithdrawBatch, []types.TokenTypeId{tokenA, tokenA}, []*big.Int{big.NewInt(1), big.NewInt(1)})
+	if _, err := (&contracts.MethodAmmWithdrawBatch{}).DoSend(db, &ledger.AccountBlock{AccountAddress: addr1, Amount: big.NewInt(0), Data: data}, 1e6); err == nil {
+		t.Fatal("withdraw a token twice in a batch should fail")
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmWithdrawBatch, []types.TokenTypeId{tokenA, tokenB}, []*big.Int{big.NewInt(1e5), big.NewInt(4e5)})
+	if _, err := receive(&contracts.MethodAmmWithdrawBatch{}, send(&contracts.MethodAmmWithdrawBatch{}, tokenA, big.NewInt(0), data)); err == nil {
+		t.Fatal("batch withdraw more than the fund should fail")
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmWithdrawBatch, []types.TokenTypeId{tokenA, tokenB}, []*big.Int{big.NewInt(1e5), big.NewInt(3e5)})
+	sendBlocks, err = receive(&contracts.MethodAmmWithdrawBatch{}, send(&contracts.MethodAmmWithdrawBatch{}, tokenA, big.NewInt(0), data))
+	if err != nil || len(sendBlocks) != 2 || sendBlocks[0].TokenId != tokenA || sendBlocks[1].Amount.Cmp(big.NewInt(3e5)) != 0 {
+		t.Fatalf("unexpected batch withdraw result %v %v", sendBlocks, err)
+	}
+	if fundList := abi.GetAmmFundList(db, addr1); len(fundList) != 1 || fundList[tokenA].Cmp(big.NewInt(2e5)) != 0 {
+		t.Fatalf("unexpected fund list after batch withdraw %v", fundList)
+	}
+
+	// transfer the fund inside the contract
+	to := types.Address{10}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmTransfer, addr1, tokenA, big.NewInt(1e5))
+	if _, err := (&contracts.MethodAmmTransfer{}).DoSend(db, &ledger.AccountBlock{AccountAddress: addr1, Amount: big.NewInt(0), Data: data}, 1e6); err == nil {
+		t.Fatal("transfer to the sender itself should fail")
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmTransfer, to, tokenA, big.NewInt(3e5))
+	if _, err := receive(&contracts.MethodAmmTransfer{}, send(&contracts.MethodAmmTransfer{}, tokenA, big.NewInt(0), data)); err == nil {
+		t.Fatal("transfer more than the fund should fail")
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmTransfer, to, tokenA, big.NewInt(1e5))
+	sendBlocks, err = receive(&contracts.MethodAmmTransfer{}, send(&contracts.MethodAmmTransfer{}, tokenA, big.NewInt(0), data))
+	if err != nil || len(sendBlocks) != 0 ||
+		abi.GetAmmFund(db, addr1, tokenA).Cmp(big.NewInt(1e5)) != 0 || abi.GetAmmFund(db, to, tokenA).Cmp(big.NewInt(1e5)) != 0 {
+		t.Fatalf("unexpected transfer result %v %v", sendBlocks, err)
+	}
+
+	if pools := abi.GetAmmPoolList(db); len(pools) != 1 {
+		t.Fatalf("unexpected pool list %v", pools)
+	}
+	if len(db.logList) != 5 || db.logList[2].Topics[0] != abi.ABIAmm.Events[abi.EventNameAmmSwap].Id() ||
+		db.logList[4].Topics[0] != abi.ABIAmm.Events[abi.EventNameAmmTransfer].Id() {
+		t.Fatalf("unexpected logs %v", db.logList)
+	}
+}
+
+func TestContractsAmmBeforeFork(t *testing.T) {
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+	data, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmDeposit)
+	block := &ledger.AccountBlock{AccountAddress: addr1, ToAddress: types.AddressAmm, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: big.NewInt(1), Data: data}
+	db.addr = addr1
+	if _, err := (&contracts.MethodAmmDeposit{}).DoSend(db, block, 1e6); err != util.ErrVersionNotSupport {
+		t.Fatalf("expected ErrVersionNotSupport, got %v", err)
+	}
+}
+
+func TestContractsAmmReward(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, Amm: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, _ := prepareDb(viteTotalSupply)
+	addr2, _ := types.BytesToAddress([]byte{2})
+	tokenA := ledger.ViteTokenId
+	tokenB := abi.NewTokenId(addr1, 3, hash12, snapshot2.Hash)
+	db.storageMap[types.AddressMintage][string(abi.GetMintageKey(tokenB))], _ = abi.ABIMintage.PackVariable(abi.VariableNameMintage, "test token", "t", big.NewInt(1e18), uint8(18), addr1, big.NewInt(0), uint64(0))
+	if _, _, swapped := abi.SortTokenPair(tokenA, tokenB); swapped {
+		tokenA, tokenB = tokenB, tokenA
+	}
+
+	call := func(method contracts.PrecompiledContractMethod, from types.Address, tokenId types.TokenTypeId, amount *big.Int, data []byte) ([]*contracts.SendBlock, error) {
+		sendBlock := &ledger.AccountBlock{AccountAddress: from, ToAddress: types.AddressAmm, BlockType: ledger.BlockTypeSendCall, TokenId: tokenId, Amount: amount, Data: data}
+		db.addr = from
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			t.Fatalf("send %T failed, %v", method, err)
+		}
+		db.addr = types.AddressAmm
+		return method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressAmm}, sendBlock, nil)
+	}
+	nextSnapshot := func(seconds int64) uint64 {
+		last := db.snapshotBlockList[len(db.snapshotBlockList)-1]
+		next := last.Timestamp.Add(time.Duration(seconds) * time.Second)
+		db.snapshotBlockList = append(db.snapshotBlockList, &ledger.SnapshotBlock{Height: last.Height + 1, Timestamp: &next, Hash: types.DataHash([]byte{10, byte(last.Height + 1)})})
+		return uint64(next.Unix())
+	}
+
+	data, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmCreatePool, tokenA, tokenB)
+	if _, err := call(&contracts.MethodAmmCreatePool{}, addr1, ledger.ViteTokenId, big.NewInt(0), data); err != nil {
+		t.Fatal(err)
+	}
+
+	// rewards are emitted only to the shares in the pool
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmSetReward, tokenB, tokenA, big.NewInt(10))
+	if _, err := call(&contracts.MethodAmmSetReward{}, addr1, ledger.ViteTokenId, big.NewInt(1000), data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(&contracts.MethodAmmSetReward{}, addr2, ledger.ViteTokenId, big.NewInt(0), data); err == nil {
+		t.Fatal("set reward by others should fail")
+	}
+	nextSnapshot(10)
+	if reward := abi.GetAmmReward(db, tokenA, tokenB); reward == nil || reward.Owner != addr1 || reward.RewardBalance.Cmp(big.NewInt(1000)) != 0 {
+		t.Fatalf("unexpected reward %+v", reward)
+	}
+
+	deposit, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmDeposit)
+	call(&contracts.MethodAmmDeposit{}, addr1, tokenA, big.NewInt(1e6), deposit)
+	call(&contracts.MethodAmmDeposit{}, addr1, tokenB, big.NewInt(4e6), deposit)
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmAddLiquidity, tokenA, tokenB, big.NewInt(1e6), big.NewInt(4e6), big.NewInt(0))
+	if _, err := call(&contracts.MethodAmmAddLiquidity{}, addr1, tokenA, big.NewInt(0), data); err != nil {
+		t.Fatal(err)
+	}
+
+	// 100 is emitted in 10 seconds, 1000 of the 2e6 shares are locked
+	now := nextSnapshot(10)
+	if pending := abi.GetAmmPendingReward(db, tokenA, tokenB, addr1, now); pending.Cmp(big.NewInt(99)) != 0 {
+		t.Fatalf("unexpected pending reward %v", pending)
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmClaimReward, tokenB, tokenA)
+	sendBlocks, err := call(&contracts.MethodAmmClaimReward{}, addr1, tokenA, big.NewInt(0), data)
+	if err != nil || len(sendBlocks) != 1 || sendBlocks[0].TokenId != ledger.ViteTokenId || sendBlocks[0].Amount.Cmp(big.NewInt(99)) != 0 {
+		t.Fatalf("unexpected claim result %v %v", sendBlocks, err)
+	}
+	if pending := abi.GetAmmPendingReward(db, tokenA, tokenB, addr1, now); pending.Sign() != 0 {
+		t.Fatalf("unexpected pending reward after claim %v", pending)
+	}
+
+	// the reward earned before removing liquidity is kept pending, together with the fraction left by the claim
+	nextSnapshot(50)
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmRemoveLiquidity, tokenA, tokenB, big.NewInt(1999000), big.NewInt(0), big.NewInt(0))
+	if _, err := call(&contracts.MethodAmmRemoveLiquidity{}, addr1, tokenA, big.NewInt(0), data); err != nil {
+		t.Fatal(err)
+	}
+	now = nextSnapshot(1000)
+	if pending := abi.GetAmmPendingReward(db, tokenA, tokenB, addr1, now); pending.Cmp(big.NewInt(500)) != 0 {
+		t.Fatalf("unexpected pending reward after remove liquidity %v", pending)
+	}
+	if reward := abi.GetAmmReward(db, tokenA, tokenB); reward.RewardBalance.Cmp(big.NewInt(400)) != 0 {
+		t.Fatalf("unexpected reward balance %v", reward.RewardBalance)
+	}
+}
+
+func TestContractsAmmDividend(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, Amm: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, _ := prepareDb(viteTotalSupply)
+	addr2, _ := types.BytesToAddress([]byte{2})
+	tokenA := ledger.ViteTokenId
+	tokenB := abi.NewTokenId(addr1, 3, hash12, snapshot2.Hash)
+	db.storageMap[types.AddressMintage][string(abi.GetMintageKey(tokenB))], _ = abi.ABIMintage.PackVariable(abi.VariableNameMintage, "test token", "t", big.NewInt(1e18), uint8(18), addr1, big.NewInt(0), uint64(0))
+	if _, _, swapped := abi.SortTokenPair(tokenA, tokenB); swapped {
+		tokenA, tokenB = tokenB, tokenA
+	}
+
+	call := func(method contracts.PrecompiledContractMethod, from types.Address, tokenId types.TokenTypeId, amount *big.Int, data []byte) ([]*contracts.SendBlock, error) {
+		sendBlock := &ledger.AccountBlock{AccountAddress: from, ToAddress: types.AddressAmm, BlockType: ledger.BlockTypeSendCall, TokenId: tokenId, Amount: amount, Data: data}
+		db.addr = from
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			t.Fatalf("send %T failed, %v", method, err)
+		}
+		db.addr = types.AddressAmm
+		return method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressAmm}, sendBlock, nil)
+	}
+	nextSnapshot := func(seconds int64) {
+		last := db.snapshotBlockList[len(db.snapshotBlockList)-1]
+		next := last.Timestamp.Add(time.Duration(seconds) * time.Second)
+		db.snapshotBlockList = append(db.snapshotBlockList, &ledger.SnapshotBlock{Height: last.Height + 1, Timestamp: &next, Hash: types.DataHash([]byte{10, byte(last.Height + 1)})})
+	}
+
+	data, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmCreatePool, tokenA, tokenB)
+	call(&contracts.MethodAmmCreatePool{}, addr1, ledger.ViteTokenId, big.NewInt(0), data)
+	deposit, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmDeposit)
+	call(&contracts.MethodAmmDeposit{}, addr1, tokenA, big.NewInt(1e6), deposit)
+	call(&contracts.MethodAmmDeposit{}, addr1, tokenB, big.NewInt(4e6+3000), deposit)
+	call(&contracts.MethodAmmDeposit{}, addr2, tokenB, big.NewInt(1000), deposit)
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmAddLiquidity, tokenA, tokenB, big.NewInt(1e6), big.NewInt(4e6), big.NewInt(0))
+	if _, err := call(&contracts.MethodAmmAddLiquidity{}, addr1, tokenA, big.NewInt(0), data); err != nil {
+		t.Fatal(err)
+	}
+
+	// no fee is collected before the dividend token is set
+	dividendData, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmDividend)
+	if _, err := call(&contracts.MethodAmmDividend{}, addr2, ledger.ViteTokenId, big.NewInt(0), dividendData); err == nil {
+		t.Fatal("dividend before the dividend token is set should fail")
+	}
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmSetDividendToken, tokenB)
+	if _, err := call(&contracts.MethodAmmSetDividendToken{}, addr1, ledger.ViteTokenId, big.NewInt(0), data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(&contracts.MethodAmmSetDividendToken{}, addr2, ledger.ViteTokenId, big.NewInt(0), data); err == nil {
+		t.Fatal("set dividend token by others should fail")
+	}
+
+	// 10 of the 30 fee of the swap is collected for the dividend
+	data, _ = abi.ABIAmm.PackMethod(abi.MethodNameAmmSwap, tokenB, big.NewInt(0))
+	if _, err := call(&contracts.MethodAmmSwap{}, addr2, tokenA, big.NewInt(10000), data); err != nil {
+		t.Fatal(err)
+	}
+	if fee := abi.GetAmmFeePool(db, tokenA); fee.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("unexpected fee pool %v", fee)
+	}
+	if pool := abi.GetAmmPool(db, tokenA, tokenB); pool.ReserveA.Cmp(big.NewInt(1e6+9990)) != 0 {
+		t.Fatalf("unexpected reserve %v", pool.ReserveA)
+	}
+	if _, err := call(&contracts.MethodAmmDividend{}, addr2, ledger.ViteTokenId, big.NewInt(0), dividendData); err == nil {
+		t.Fatal("dividend within the period should fail")
+	}
+
+	// the fee is distributed by the funds of tokenB 3000 and 1000, the remainder is kept
+	nextSnapshot(86400)
+	if _, err := call(&contracts.MethodAmmDividend{}, addr2, ledger.ViteTokenId, big.NewInt(0), dividendData); err != nil {
+		t.Fatal(err)
+	}
+	if fund1, fund2 := abi.GetAmmFund(db, addr1, tokenA), abi.GetAmmFund(db, addr2, tokenA); fund1.Cmp(big.NewInt(7)) != 0 || fund2.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("unexpected dividend %v %v", fund1, fund2)
+	}
+	if fee := abi.GetAmmFeePool(db, tokenA); fee.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("unexpected fee pool after dividend %v", fee)
+	}
+	if dividend := abi.GetAmmDividend(db); dividend == nil || dividend.Owner != addr1 || dividend.DividendToken != tokenB {
+		t.Fatalf("unexpected dividend %+v", dividend)
+	}
+	if _, err := call(&contracts.MethodAmmDividend{}, addr2, ledger.ViteTokenId, big.NewInt(0), dividendData); err == nil {
+		t.Fatal("dividend within the period should fail")
+	}
+}
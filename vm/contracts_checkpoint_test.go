@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+)
+
+func TestContractsCheckpoint(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, Checkpoint: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, _, _ := prepareDb(viteTotalSupply)
+	block12 := db.accountBlockMap[addr1][hash12]
+	block12.StateHash = types.DataHash([]byte("state12"))
+
+	method := &contracts.MethodCheckpoint{}
+	checkpoint := func(height uint64, stateHash types.Hash) error {
+		data, err := abi.ABICheckpoint.PackMethod(abi.MethodNameCheckpoint, height, stateHash)
+		if err != nil {
+			return err
+		}
+		sendBlock := &ledger.AccountBlock{Height: 3, AccountAddress: addr1, ToAddress: types.AddressCheckpoint, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: big.NewInt(0), Data: data, Hash: types.DataHash(data)}
+		db.addr = addr1
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return err
+		}
+		db.addr = types.AddressCheckpoint
+		_, err = method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressCheckpoint}, sendBlock, util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit))
+		return err
+	}
+
+	for _, invalid := range []struct {
+		height    uint64
+		stateHash types.Hash
+	}{
+		{0, types.Hash{}},
+		{3, types.Hash{}},
+		{2, types.DataHash([]byte("other"))},
+	} {
+		if err := checkpoint(invalid.height, invalid.stateHash); err != util.ErrInvalidMethodParam {
+			t.Fatalf("checkpoint %v should be invalid, got %v", invalid, err)
+		}
+	}
+
+	if err := checkpoint(2, block12.StateHash); err != nil {
+		t.Fatal(err)
+	}
+	if c := abi.GetAccountCheckpoint(db, addr1); c == nil || c.Height != 2 || c.StateHash != block12.StateHash || c.SendHash == (types.Hash{}) {
+		t.Fatalf("unexpected checkpoint %v", c)
+	}
+	if err := checkpoint(1, types.Hash{}); err == nil {
+		t.Fatal("checkpoint should not move backward")
+	}
+	if len(db.logList) != 1 {
+		t.Fatalf("unexpected logs %v", db.logList)
+	}
+}
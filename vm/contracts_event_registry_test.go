@@ -0,0 +1,87 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+)
+
+func TestContractsEventRegistry(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, EventRegistry: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+	contract, _ := types.BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1})
+	other, _, _ := types.CreateAddress()
+	createBlock := &ledger.AccountBlock{AccountAddress: addr1, ToAddress: contract, BlockType: ledger.BlockTypeSendCreate, Hash: types.DataHash([]byte("create"))}
+	db.accountBlockMap[addr1][createBlock.Hash] = createBlock
+
+	method := &contracts.MethodRegisterEvent{}
+	register := func(from types.Address, createHash types.Hash, definition string) error {
+		data, err := abi.ABIEventRegistry.PackMethod(abi.MethodNameRegisterEvent, contract, createHash, definition)
+		if err != nil {
+			return err
+		}
+		sendBlock := &ledger.AccountBlock{AccountAddress: from, ToAddress: types.AddressEventRegistry, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: big.NewInt(0), Data: data}
+		db.addr = from
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return err
+		}
+		db.addr = types.AddressEventRegistry
+		meter := util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit)
+		if _, err := method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressEventRegistry}, sendBlock, meter); err != nil {
+			return err
+		}
+		if meter.Used() == 0 {
+			t.Fatal("register should use quota at receive")
+		}
+		return nil
+	}
+
+	definition := `{"type":"event","name":"transfer","inputs":[{"name":"from","type":"address","indexed":true},{"name":"amount","type":"uint256"}]}`
+	for _, invalid := range []string{
+		`{"type":"function","name":"transfer","inputs":[]}`,
+		`{"type":"event","name":"transfer","anonymous":true,"inputs":[]}`,
+		definition + "," + definition,
+		`{"type":"event","name":"transfer"`,
+	} {
+		if err := register(addr1, createBlock.Hash, invalid); err != util.ErrInvalidMethodParam {
+			t.Fatalf("definition %v should be invalid, got %v", invalid, err)
+		}
+	}
+	if err := register(other, createBlock.Hash, definition); err == nil {
+		t.Fatal("register by other address should fail")
+	}
+	if err := register(addr1, types.DataHash([]byte("other")), definition); err == nil {
+		t.Fatal("register with wrong create block should fail")
+	}
+
+	// the deployer registers the event, then the contract updates the param names
+	if err := register(addr1, createBlock.Hash, definition); err != nil {
+		t.Fatal(err)
+	}
+	event, _ := abi.ParseEventDefinition(definition)
+	if e := abi.GetEventDefinition(db, contract, event.Id()); e == nil || e.Name != "transfer" || e.Inputs[0].Name != "from" || !e.Inputs[0].Indexed {
+		t.Fatalf("unexpected event %v", e)
+	}
+	renamed := `{"type":"event","name":"transfer","inputs":[{"name":"sender","type":"address","indexed":true},{"name":"value","type":"uint256"}]}`
+	if err := register(contract, types.Hash{}, renamed); err != nil {
+		t.Fatal(err)
+	}
+	if e := abi.GetEventDefinition(db, contract, event.Id()); e == nil || e.Inputs[0].Name != "sender" || e.Inputs[1].Name != "value" {
+		t.Fatalf("unexpected event %v", e)
+	}
+	if e := abi.GetEventDefinition(db, other, event.Id()); e != nil {
+		t.Fatalf("event of other contract should not be registered, got %v", e)
+	}
+	if len(db.logList) != 2 || db.logList[1].Topics[2] != event.Id() {
+		t.Fatalf("unexpected logs %v", db.logList)
+	}
+}
@@ -0,0 +1,78 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestContractsLockPledge(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, PledgeLock: &config.ForkPoint{Height: 2}})
+	defer initFork()
+	if err := contracts.SetPledgeLockTiers([]*config.PledgeLockTier{{LockHeight: 2, Multiplier: 150}, {LockHeight: 1, Multiplier: 200}}); err == nil {
+		t.Fatal("lock heights should ascend")
+	}
+	if err := contracts.SetPledgeLockTiers([]*config.PledgeLockTier{{LockHeight: 2, Multiplier: 150}}); err != nil {
+		t.Fatal(err)
+	}
+	defer contracts.SetPledgeLockTiers(nil)
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+	beneficial, _, _ := types.CreateAddress()
+	pledgeAmount := new(big.Int).Mul(big.NewInt(1000), util.AttovPerVite)
+
+	run := func(method contracts.PrecompiledContractMethod, amount *big.Int, data []byte) ([]*contracts.SendBlock, error) {
+		sendBlock := &ledger.AccountBlock{Height: 3, AccountAddress: addr1, ToAddress: types.AddressPledge, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: amount, Data: data, Hash: types.DataHash(data)}
+		db.addr = addr1
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return nil, err
+		}
+		db.addr = types.AddressPledge
+		return method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressPledge}, sendBlock, util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit))
+	}
+	nextSnapshotBlock := func() {
+		now := time.Now()
+		db.snapshotBlockList = append(db.snapshotBlockList, &ledger.SnapshotBlock{Height: db.CurrentSnapshotBlock().Height + 1, Timestamp: &now})
+	}
+
+	data, _ := abi.ABIPledge.PackMethod(abi.MethodNameLockPledge, beneficial, uint8(2))
+	if _, err := run(&contracts.MethodLockPledge{}, pledgeAmount, data); err != util.ErrInvalidMethodParam {
+		t.Fatalf("tier 2 should not exist, got %v", err)
+	}
+	data, _ = abi.ABIPledge.PackMethod(abi.MethodNameLockPledge, beneficial, uint8(1))
+	if _, err := run(&contracts.MethodLockPledge{}, pledgeAmount, data); err != nil {
+		t.Fatal(err)
+	}
+	db.addr = types.AddressPledge
+	bonus := new(big.Int).Quo(pledgeAmount, big.NewInt(2))
+	if amount, b := abi.GetPledgeBeneficialAmount(db, beneficial), abi.GetPledgeBeneficialBonus(db, beneficial); amount.Cmp(pledgeAmount) != 0 || b.Cmp(bonus) != 0 {
+		t.Fatalf("unexpected beneficial amount %v and bonus %v", amount, b)
+	}
+	if list, _ := abi.GetPledgeInfoList(db, addr1); len(list) != 1 || list[0].LockTier != 1 || list[0].BeneficialAddr != beneficial {
+		t.Fatalf("unexpected pledge info list %v", list)
+	}
+
+	// locked for the height of the tier, not the minimum pledge height
+	cancelData, _ := abi.ABIPledge.PackMethod(abi.MethodNameCancelLockPledge, beneficial, uint8(1), pledgeAmount)
+	nextSnapshotBlock()
+	if _, err := run(&contracts.MethodCancelLockPledge{}, big.NewInt(0), cancelData); err == nil {
+		t.Fatal("pledge should be locked")
+	}
+	nextSnapshotBlock()
+	sendBlocks, err := run(&contracts.MethodCancelLockPledge{}, big.NewInt(0), cancelData)
+	if err != nil || len(sendBlocks) != 1 || sendBlocks[0].ToAddress != addr1 || sendBlocks[0].Amount.Cmp(pledgeAmount) != 0 {
+		t.Fatalf("unexpected cancel result %v, %v", sendBlocks, err)
+	}
+	db.addr = types.AddressPledge
+	if amount, b := abi.GetPledgeBeneficialAmount(db, beneficial), abi.GetPledgeBeneficialBonus(db, beneficial); amount.Sign() != 0 || b.Sign() != 0 {
+		t.Fatalf("unexpected beneficial amount %v and bonus %v after cancel", amount, b)
+	}
+}
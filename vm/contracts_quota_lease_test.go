@@ -0,0 +1,138 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestContractsQuotaLease(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, QuotaLease: &config.ForkPoint{Height: 2}})
+	defer initFork()
+	quota.InitQuotaConfig(false)
+
+	db := NewNoDatabase()
+	timestamp := time.Unix(1536214502, 0)
+	for i := uint64(1); i <= 10; i++ {
+		db.snapshotBlockList = append(db.snapshotBlockList, &ledger.SnapshotBlock{Height: i, Timestamp: &timestamp, Hash: types.DataHash([]byte{10, byte(i)})})
+	}
+	lessor, _, _ := types.CreateAddress()
+	beneficial, _, _ := types.CreateAddress()
+	vite := func(n int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+	}
+	pledgeAmount := vite(10000)
+	db.storageMap[types.AddressPledge] = make(map[string][]byte)
+	db.storageMap[types.AddressPledge][string(abi.GetPledgeBeneficialKey(lessor))], _ = abi.ABIPledge.PackVariable(abi.VariableNamePledgeBeneficial, pledgeAmount)
+	db.storageMap[types.AddressPledge][string(abi.GetPledgeKey(lessor, abi.GetPledgeBeneficialKey(lessor)))], _ = abi.ABIPledge.PackVariable(abi.VariableNamePledgeInfo, pledgeAmount, uint64(1))
+
+	method := &contracts.MethodLeaseQuota{}
+	lease := func(to types.Address, amount *big.Int, period uint64) error {
+		data, err := abi.ABIQuotaLease.PackMethod(abi.MethodNameLeaseQuota, to, amount, period)
+		if err != nil {
+			return err
+		}
+		sendBlock := &ledger.AccountBlock{AccountAddress: lessor, ToAddress: types.AddressQuotaLease, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: big.NewInt(0), Data: data}
+		db.addr = lessor
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return err
+		}
+		db.addr = types.AddressQuotaLease
+		_, err = method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressQuotaLease}, sendBlock, util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit))
+		return err
+	}
+	calcQuota := func(addr types.Address, pledgeAmount *big.Int) uint64 {
+		db.addr = addr
+		q, _, err := quota.CalcQuotaV2(db, addr, pledgeAmount, helper.Big0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q
+	}
+	unleasedQuota := func(pledgeAmount *big.Int) uint64 {
+		other, _, _ := types.CreateAddress()
+		return calcQuota(other, pledgeAmount)
+	}
+
+	for _, invalid := range []struct {
+		to     types.Address
+		amount *big.Int
+		period uint64
+	}{
+		{beneficial, big.NewInt(0), 75},
+		{beneficial, vite(1000), 74},
+		{beneficial, vite(1000), 3600*24*30 + 1},
+		{lessor, vite(1000), 75},
+	} {
+		if err := lease(invalid.to, invalid.amount, invalid.period); err != util.ErrInvalidMethodParam {
+			t.Fatalf("lease %v should be invalid, got %v", invalid, err)
+		}
+	}
+	if err := lease(beneficial, vite(10001), 75); err == nil {
+		t.Fatal("lease should not be more than the pledge amount")
+	}
+
+	if err := lease(beneficial, vite(6000), 75); err != nil {
+		t.Fatal(err)
+	}
+	if l := abi.GetQuotaLease(db, lessor, beneficial); l == nil || l.Amount.Cmp(vite(6000)) != 0 || l.ExpirationHeight != 85 {
+		t.Fatalf("unexpected lease %v", l)
+	}
+	if list := abi.GetQuotaLeaseInList(db, beneficial); len(list) != 1 || list[0].Lessor != lessor || list[0].Beneficial != beneficial {
+		t.Fatalf("unexpected leases to beneficial %v", list)
+	}
+	if q, expected := calcQuota(beneficial, big.NewInt(0)), unleasedQuota(vite(6000)); q != expected || q == 0 {
+		t.Fatalf("unexpected quota of beneficial %v, expected %v", q, expected)
+	}
+	if q, expected := calcQuota(lessor, pledgeAmount), unleasedQuota(vite(4000)); q != expected || q >= unleasedQuota(pledgeAmount) {
+		t.Fatalf("unexpected quota of lessor %v, expected %v", q, expected)
+	}
+
+	other, _, _ := types.CreateAddress()
+	if err := lease(other, vite(5000), 75); err == nil {
+		t.Fatal("the pledge amount leased out should not be leased again")
+	}
+	if err := lease(beneficial, vite(5000), 75); err == nil {
+		t.Fatal("an active lease should not be shortened")
+	}
+	if err := lease(beneficial, vite(7000), 100); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelPledge := func(amount *big.Int) error {
+		data, _ := abi.ABIPledge.PackMethod(abi.MethodNameCancelPledge, lessor, amount)
+		sendBlock := &ledger.AccountBlock{AccountAddress: lessor, ToAddress: types.AddressPledge, BlockType: ledger.BlockTypeSendCall, Amount: big.NewInt(0), Data: data}
+		db.addr = types.AddressPledge
+		_, err := (&contracts.MethodCancelPledge{}).DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressPledge}, sendBlock, util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit))
+		return err
+	}
+	if err := cancelPledge(vite(5000)); err == nil {
+		t.Fatal("the pledge amount leased out should not be canceled")
+	}
+
+	// the lease expires at height 110
+	for i := uint64(11); i <= 110; i++ {
+		db.snapshotBlockList = append(db.snapshotBlockList, &ledger.SnapshotBlock{Height: i, Timestamp: &timestamp, Hash: types.DataHash([]byte{10, byte(i)})})
+	}
+	if q := calcQuota(beneficial, big.NewInt(0)); q != 0 {
+		t.Fatalf("expired lease should give no quota, got %v", q)
+	}
+	if q, expected := calcQuota(lessor, pledgeAmount), unleasedQuota(pledgeAmount); q != expected {
+		t.Fatalf("unexpected quota of lessor after the lease expires %v, expected %v", q, expected)
+	}
+	if err := cancelPledge(vite(5000)); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.logList) != 2 {
+		t.Fatalf("unexpected logs %v", db.logList)
+	}
+}
@@ -0,0 +1,69 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+)
+
+func TestContractsRegistrationDetail(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, RegistrationDetail: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+	addr2, _, _ := types.CreateAddress()
+
+	run := func(method contracts.PrecompiledContractMethod, from types.Address, data []byte) error {
+		sendBlock := &ledger.AccountBlock{Height: 3, AccountAddress: from, ToAddress: types.AddressRegister, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: big.NewInt(0), Data: data, Hash: types.DataHash(data)}
+		db.addr = from
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return err
+		}
+		db.addr = types.AddressRegister
+		_, err := method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressRegister}, sendBlock, util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit))
+		return err
+	}
+
+	data, _ := abi.ABIRegister.PackMethod(abi.MethodNameUpdateDisplayName, types.SNAPSHOT_GID, "s1", "bad  name")
+	if err := run(&contracts.MethodUpdateDisplayName{}, addr1, data); err != util.ErrInvalidMethodParam {
+		t.Fatalf("display name should be invalid, got %v", err)
+	}
+	data, _ = abi.ABIRegister.PackMethod(abi.MethodNameUpdateDisplayName, types.SNAPSHOT_GID, "s1", "super node 1")
+	if err := run(&contracts.MethodUpdateDisplayName{}, addr2, data); err == nil {
+		t.Fatal("only the pledge address should update the display name")
+	}
+	if err := run(&contracts.MethodUpdateDisplayName{}, addr1, data); err != nil {
+		t.Fatal(err)
+	}
+	if name := abi.GetDisplayName(db, types.SNAPSHOT_GID, "s1"); name != "super node 1" {
+		t.Fatalf("unexpected display name %v", name)
+	}
+	if registration := abi.GetRegistration(db, types.SNAPSHOT_GID, "s1"); registration == nil || registration.Name != "s1" {
+		t.Fatalf("registration should be kept, got %v", registration)
+	}
+
+	data, _ = abi.ABIRegister.PackMethod(abi.MethodNameUpdateRewardWithdrawAddress, types.SNAPSHOT_GID, "s1", addr2)
+	if err := run(&contracts.MethodUpdateRewardWithdrawAddress{}, addr1, data); err != nil {
+		t.Fatal(err)
+	}
+	if withdrawAddr := abi.GetRewardWithdrawAddr(db, types.SNAPSHOT_GID, "s1"); withdrawAddr == nil || *withdrawAddr != addr2 {
+		t.Fatalf("unexpected reward withdraw address %v", withdrawAddr)
+	}
+	data, _ = abi.ABIRegister.PackMethod(abi.MethodNameUpdateRewardWithdrawAddress, types.SNAPSHOT_GID, "s1", addr1)
+	if err := run(&contracts.MethodUpdateRewardWithdrawAddress{}, addr1, data); err != nil {
+		t.Fatal(err)
+	}
+	if withdrawAddr := abi.GetRewardWithdrawAddr(db, types.SNAPSHOT_GID, "s1"); withdrawAddr != nil {
+		t.Fatalf("reward withdraw address should be removed, got %v", withdrawAddr)
+	}
+	if list := abi.GetCandidateList(db, types.SNAPSHOT_GID, nil); len(list) != 2 {
+		t.Fatalf("unexpected candidate list %v", list)
+	}
+}
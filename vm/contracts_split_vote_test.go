@@ -0,0 +1,82 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestContractsSplitVote(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, SplitVote: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+
+	run := func(method contracts.PrecompiledContractMethod, data []byte) error {
+		sendBlock := &ledger.AccountBlock{Height: 3, AccountAddress: addr1, ToAddress: types.AddressVote, BlockType: ledger.BlockTypeSendCall, TokenId: ledger.ViteTokenId, Amount: big.NewInt(0), Data: data, Hash: types.DataHash(data)}
+		db.addr = addr1
+		if _, err := method.DoSend(db, sendBlock, 1e6); err != nil {
+			return err
+		}
+		db.addr = types.AddressVote
+		_, err := method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressVote}, sendBlock, util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit))
+		return err
+	}
+
+	for _, invalid := range []struct {
+		nameList   []string
+		weightList []uint64
+	}{
+		{[]string{"s1", "s2"}, []uint64{5000}},
+		{[]string{"s1", "s2"}, []uint64{5000, 4000}},
+		{[]string{"s1", "s1"}, []uint64{5000, 5000}},
+		{[]string{"s1", "s2"}, []uint64{10000, 0}},
+		{[]string{"s1", ""}, []uint64{5000, 5000}},
+		{[]string{"s1", "s2", "s3", "s4", "s5", "s6"}, []uint64{5000, 1000, 1000, 1000, 1000, 1000}},
+	} {
+		data, _ := abi.ABIVote.PackMethod(abi.MethodNameSplitVote, types.SNAPSHOT_GID, strings.Join(invalid.nameList, ","), invalid.weightList)
+		if err := run(&contracts.MethodSplitVote{}, data); err != util.ErrInvalidMethodParam {
+			t.Fatalf("split vote %v %v should be invalid, got %v", invalid.nameList, invalid.weightList, err)
+		}
+	}
+	data, _ := abi.ABIVote.PackMethod(abi.MethodNameSplitVote, types.SNAPSHOT_GID, "s1,s3", []uint64{5000, 5000})
+	if err := run(&contracts.MethodSplitVote{}, data); err == nil {
+		t.Fatal("split vote for a node not registered should fail")
+	}
+
+	data, _ = abi.ABIVote.PackMethod(abi.MethodNameVote, types.SNAPSHOT_GID, "s1")
+	if err := run(&contracts.MethodVote{}, data); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = abi.ABIVote.PackMethod(abi.MethodNameSplitVote, types.SNAPSHOT_GID, "s1,s2", []uint64{7500, 2500})
+	if err := run(&contracts.MethodSplitVote{}, data); err != nil {
+		t.Fatal(err)
+	}
+	if vote := abi.GetVote(db, types.SNAPSHOT_GID, addr1); vote != nil {
+		t.Fatalf("the single vote should be replaced, got %v", vote)
+	}
+	voteList := abi.GetVoteList(db, types.SNAPSHOT_GID, nil)
+	if len(voteList) != 2 || voteList[0].VoterAddr != addr1 || voteList[0].NodeName != "s1" || voteList[0].Weight != 7500 ||
+		voteList[1].NodeName != "s2" || voteList[1].Weight != 2500 {
+		t.Fatalf("unexpected vote list %v", voteList)
+	}
+	if balance := voteList[1].WeightedBalance(big.NewInt(1000)); balance.Cmp(big.NewInt(250)) != 0 {
+		t.Fatalf("unexpected weighted balance %v", balance)
+	}
+
+	data, _ = abi.ABIVote.PackMethod(abi.MethodNameCancelVote, types.SNAPSHOT_GID)
+	if err := run(&contracts.MethodCancelVote{}, data); err != nil {
+		t.Fatal(err)
+	}
+	if voteList := abi.GetSplitVote(db, types.SNAPSHOT_GID, addr1); voteList != nil {
+		t.Fatalf("split vote should be cancelled, got %v", voteList)
+	}
+}
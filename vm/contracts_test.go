@@ -2049,3 +2049,75 @@ func TestGenesisBlockData(t *testing.T) {
 	}
 	fmt.Println("}")
 }
+
+func TestContractsReceiveQuota(t *testing.T) {
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, _, _, _ := prepareDb(viteTotalSupply)
+	db.addr = types.AddressConsensusGroup
+
+	receive := func(gid types.Gid, words int, meter *util.QuotaMeter) error {
+		data, _ := abi.ABIConsensusGroup.PackMethod(abi.MethodNameCreateConsensusGroup,
+			gid, uint8(25), int64(3), int64(1), uint8(2), uint8(50), ledger.ViteTokenId,
+			uint8(1), make([]byte, words*32), uint8(1), []byte{})
+		sendBlock := &ledger.AccountBlock{AccountAddress: addr1, ToAddress: types.AddressConsensusGroup, Amount: big.NewInt(0), Data: data}
+		_, err := (&contracts.MethodCreateConsensusGroup{}).DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressConsensusGroup}, sendBlock, meter)
+		return err
+	}
+
+	// quota used scales linearly with the condition params stored
+	for i, words := range []int{1, 2, 10, 100} {
+		meter := util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit)
+		if err := receive(types.Gid{byte(i + 1)}, words, meter); err != nil {
+			t.Fatal(err)
+		}
+		if expected := uint64(words) * contracts.ConditionParamPerWordGas; meter.Used() != expected {
+			t.Fatalf("%v words of condition params, expected quota used %v, got %v", words, expected, meter.Used())
+		}
+	}
+
+	gid := types.Gid{byte(0xff)}
+	if err := receive(gid, 10, util.NewQuotaMeter(10*contracts.ConditionParamPerWordGas-1)); err != util.ErrReceiveQuotaLimit {
+		t.Fatalf("expected %v, got %v", util.ErrReceiveQuotaLimit, err)
+	}
+	if abi.GetConsensusGroup(db, gid) != nil {
+		t.Fatal("consensus group should not be created over the quota limit")
+	}
+}
+
+func TestGetPrecompiledContractBeforeFork(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{
+		Smart:         &config.ForkPoint{Height: 2},
+		Mint:          &config.ForkPoint{Height: 2},
+		Amm:           &config.ForkPoint{Height: 10},
+		Allowance:     &config.ForkPoint{Height: 10},
+		EventRegistry: &config.ForkPoint{Height: 10},
+		Checkpoint:    &config.ForkPoint{Height: 10},
+		QuotaLease:    &config.ForkPoint{Height: 10},
+	})
+	defer initFork()
+
+	for _, addr := range types.ForkedPrecompiledContractAddressList {
+		if fork.IsPrecompiledContractAddress(addr, 9) {
+			t.Fatalf("%v should be a plain address before its fork", addr)
+		}
+		if !fork.IsPrecompiledContractAddress(addr, 10) {
+			t.Fatalf("%v should be a built-in contract after its fork", addr)
+		}
+	}
+	if len(fork.GetPrecompiledContractAddressList(9)) != len(types.PrecompiledContractAddressList) ||
+		len(fork.GetPrecompiledContractAddressList(10)) != len(types.PrecompiledContractAddressList)+len(types.ForkedPrecompiledContractAddressList) {
+		t.Fatal("unexpected built-in contract list")
+	}
+
+	data, _ := abi.ABIAmm.PackMethod(abi.MethodNameAmmDeposit)
+	if method, ok, err := GetPrecompiledContract(types.AddressAmm, data, 9); method != nil || ok || err != nil {
+		t.Fatalf("a send to the amm address before the fork should be a plain transfer, got %v %v %v", method, ok, err)
+	}
+	if method, ok, err := GetPrecompiledContract(types.AddressAmm, data, 10); method == nil || !ok || err != nil {
+		t.Fatalf("get amm deposit method failed, got %v %v %v", method, ok, err)
+	}
+	data, _ = abi.ABIMintage.PackMethod(abi.MethodNameBurn)
+	if method, ok, err := GetPrecompiledContract(types.AddressMintage, data, 1); method == nil || !ok || err != nil {
+		t.Fatalf("the mintage contract should be built-in at every height, got %v %v %v", method, ok, err)
+	}
+}
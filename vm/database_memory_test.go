@@ -85,6 +85,9 @@ func (db *memoryDatabase) GetAccountBlockByHash(hash *types.Hash) *ledger.Accoun
 func (db *memoryDatabase) GetSelfAccountBlockByHeight(height uint64) *ledger.AccountBlock {
 	return nil
 }
+func (db *memoryDatabase) GetPrevAccountBlocksBySnapshotHash(snapshotHash *types.Hash) ([]*ledger.AccountBlock, error) {
+	return nil, nil
+}
 func (db *memoryDatabase) Reset() {}
 func (db *memoryDatabase) IsAddressExisted(addr *types.Address) bool {
 	return false
@@ -12,6 +12,7 @@ import (
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/vm/contracts/abi"
 	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context"
 	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
 )
 
@@ -115,6 +116,13 @@ func (db *testDatabase) GetSelfAccountBlockByHeight(height uint64) *ledger.Accou
 	}
 	return nil
 }
+func (db *testDatabase) GetPrevAccountBlocksBySnapshotHash(snapshotHash *types.Hash) ([]*ledger.AccountBlock, error) {
+	var blocks []*ledger.AccountBlock
+	for block := db.PrevAccountBlock(); block != nil && block.SnapshotHash == *snapshotHash; block = db.GetAccountBlockByHash(&block.PrevHash) {
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
 func (db *testDatabase) Reset() {}
 func (db *testDatabase) IsAddressExisted(addr *types.Address) bool {
 	_, ok := db.accountBlockMap[*addr]
@@ -187,6 +195,13 @@ func (i *testIterator) Next() (key, value []byte, ok bool) {
 func (db *testDatabase) NewStorageIterator(addr *types.Address, prefix []byte) vmctxt_interface.StorageIterator {
 	storageMap := db.storageMap[*addr]
 	items := make([]testIteratorItem, 0)
+	// balances are kept out of the storage map
+	if bytes.Equal(prefix, vm_context.STORAGE_KEY_BALANCE) {
+		for tokenId, balance := range db.balanceMap[*addr] {
+			items = append(items, testIteratorItem{vm_context.BalanceKey(&tokenId), balance.Bytes()})
+		}
+		return &testIterator{0, items}
+	}
 	for key, value := range storageMap {
 		if len(prefix) > 0 {
 			if bytes.Equal([]byte(key)[:len(prefix)], prefix) {
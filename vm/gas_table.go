@@ -10,7 +10,7 @@ import (
 
 // memoryGasCosts calculates the quadratic gas for memory expansion. It does so
 // only for the memory region that is expanded, not the total memory.
-func memoryGasCost(mem *memory, newMemSize uint64) (uint64, error) {
+func memoryGasCost(gasTable *util.GasTable, mem *memory, newMemSize uint64) (uint64, error) {
 
 	if newMemSize == 0 {
 		return 0, nil
@@ -31,8 +31,8 @@ func memoryGasCost(mem *memory, newMemSize uint64) (uint64, error) {
 
 	if newMemSize > uint64(mem.len()) {
 		square := newMemSizeWords * newMemSizeWords
-		linCoef := newMemSizeWords * memoryGas
-		quadCoef := square / quadCoeffDiv
+		linCoef := newMemSizeWords * gasTable.MemoryGas
+		quadCoef := square / gasTable.QuadCoeffDiv
 		newTotalFee := linCoef + quadCoef
 
 		fee := newTotalFee - mem.lastGasCost
@@ -49,11 +49,18 @@ func constGasFunc(gas uint64) gasFunc {
 	}
 }
 
+// tableGasFunc returns the cost in the gas table of the snapshot height the vm runs at
+func tableGasFunc(cost func(t *util.GasTable) uint64) gasFunc {
+	return func(vm *VM, contrac *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
+		return cost(vm.gasTable), nil
+	}
+}
+
 func gasExp(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
 	expByteLen := uint64((stack.back(1).BitLen() + 7) / 8)
 
 	var (
-		gas      = expByteLen * expByteGas // no overflow check required. Max is 256 * expByteGas gas
+		gas      = expByteLen * vm.gasTable.ExpByteGas // no overflow check required. Max is 256 * ExpByteGas gas
 		overflow bool
 	)
 	if gas, overflow = helper.SafeAdd(gas, slowStepGas); overflow {
@@ -64,12 +71,12 @@ func gasExp(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (
 
 func gasBlake2b(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
 	var overflow bool
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, err
 	}
 
-	if gas, overflow = helper.SafeAdd(gas, blake2bGas); overflow {
+	if gas, overflow = helper.SafeAdd(gas, vm.gasTable.Blake2bGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 
@@ -77,7 +84,7 @@ func gasBlake2b(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint6
 	if overflow {
 		return 0, util.ErrGasUintOverflow
 	}
-	if wordGas, overflow = helper.SafeMul(helper.ToWordSize(wordGas), blake2bWordGas); overflow {
+	if wordGas, overflow = helper.SafeMul(helper.ToWordSize(wordGas), vm.gasTable.Blake2bWordGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 	if gas, overflow = helper.SafeAdd(gas, wordGas); overflow {
@@ -87,7 +94,7 @@ func gasBlake2b(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint6
 }
 
 func gasCallDataCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, err
 	}
@@ -102,7 +109,7 @@ func gasCallDataCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize
 		return 0, util.ErrGasUintOverflow
 	}
 
-	if words, overflow = helper.SafeMul(helper.ToWordSize(words), copyGas); overflow {
+	if words, overflow = helper.SafeMul(helper.ToWordSize(words), vm.gasTable.CopyGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 
@@ -113,7 +120,7 @@ func gasCallDataCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize
 }
 
 func gasCodeCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, err
 	}
@@ -127,7 +134,7 @@ func gasCodeCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint
 	if overflow {
 		return 0, util.ErrGasUintOverflow
 	}
-	if wordGas, overflow = helper.SafeMul(helper.ToWordSize(wordGas), copyGas); overflow {
+	if wordGas, overflow = helper.SafeMul(helper.ToWordSize(wordGas), vm.gasTable.CopyGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 	if gas, overflow = helper.SafeAdd(gas, wordGas); overflow {
@@ -137,13 +144,13 @@ func gasCodeCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint
 }
 
 func gasExtCodeCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, err
 	}
 
 	var overflow bool
-	if gas, overflow = helper.SafeAdd(gas, extCodeCopyGas); overflow {
+	if gas, overflow = helper.SafeAdd(gas, vm.gasTable.ExtCodeCopyGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 
@@ -152,7 +159,7 @@ func gasExtCodeCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize u
 		return 0, util.ErrGasUintOverflow
 	}
 
-	if wordGas, overflow = helper.SafeMul(helper.ToWordSize(wordGas), copyGas); overflow {
+	if wordGas, overflow = helper.SafeMul(helper.ToWordSize(wordGas), vm.gasTable.CopyGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 
@@ -163,7 +170,7 @@ func gasExtCodeCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize u
 }
 
 func gasReturnDataCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, err
 	}
@@ -178,7 +185,7 @@ func gasReturnDataCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySiz
 		return 0, util.ErrGasUintOverflow
 	}
 
-	if words, overflow = helper.SafeMul(helper.ToWordSize(words), copyGas); overflow {
+	if words, overflow = helper.SafeMul(helper.ToWordSize(words), vm.gasTable.CopyGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 
@@ -190,7 +197,7 @@ func gasReturnDataCopy(vm *VM, c *contract, stack *stack, mem *memory, memorySiz
 
 func gasMLoad(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
 	var overflow bool
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, util.ErrGasUintOverflow
 	}
@@ -202,7 +209,7 @@ func gasMLoad(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64)
 
 func gasMStore(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
 	var overflow bool
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, util.ErrGasUintOverflow
 	}
@@ -214,7 +221,7 @@ func gasMStore(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64
 
 func gasMStore8(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
 	var overflow bool
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, util.ErrGasUintOverflow
 	}
@@ -234,53 +241,53 @@ func gasSStore(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64
 	if !fork.IsMintFork(c.db.CurrentSnapshotBlock().Height) {
 		if len(currentValue) == 0 && newValue.Sign() != 0 {
 			// zero value to non-zero value, charge 20000
-			return sstoreSetGas, nil
+			return vm.gasTable.SstoreSetGas, nil
 		} else if len(currentValue) > 0 && newValue.Sign() == 0 {
 			// non-zero value to zero value, charge 5000 with 15000 refund
-			c.quotaRefund = c.quotaRefund + sstoreRefundGas
-			return sstoreClearGas, nil
+			c.quotaRefund = c.quotaRefund + vm.gasTable.SstoreRefundGas
+			return vm.gasTable.SstoreClearGas, nil
 		} else {
 			// non-zero value to non-zero value or zero value to zero value, charge 5000
-			return sstoreResetGas, nil
+			return vm.gasTable.SstoreResetGas, nil
 		}
 	}
 	if bytes.Equal(currentValue, newValue.Bytes()) {
 		// no change, charge 200
-		return sstoreNoopGas, nil
+		return vm.gasTable.SstoreNoopGas, nil
 	}
 	originalValue := c.db.GetOriginalStorage(locHash.Bytes())
 	if bytes.Equal(originalValue, currentValue) {
 		if len(originalValue) == 0 {
 			// zero value to non-zero value, charge 20000
-			return sstoreInitGas, nil
+			return vm.gasTable.SstoreInitGas, nil
 		}
 		if newValue.Sign() == 0 {
 			// non-zero value to zero value, charge 5000 with 15000 refund
-			c.quotaRefund = c.quotaRefund + sstoreClearRefundGas
+			c.quotaRefund = c.quotaRefund + vm.gasTable.SstoreClearRefundGas
 		}
 		// non-zero value to non-zero value, charge 5000
-		return sstoreCleanGas, nil
+		return vm.gasTable.SstoreCleanGas, nil
 	}
 	// value changed again, charge 200
 	if len(originalValue) > 0 {
 		if len(currentValue) == 0 {
 			// non-zero value to zero value to non-zero value, withdraw 15000 refund
-			c.quotaRefund = c.quotaRefund - sstoreClearRefundGas
+			c.quotaRefund = c.quotaRefund - vm.gasTable.SstoreClearRefundGas
 		} else if newValue.Sign() == 0 {
 			// non-zero value to non-zero value to zero value,
-			c.quotaRefund = c.quotaRefund + sstoreClearRefundGas
+			c.quotaRefund = c.quotaRefund + vm.gasTable.SstoreClearRefundGas
 		}
 	}
 	if bytes.Equal(originalValue, newValue.Bytes()) {
 		if len(originalValue) == 0 {
 			// zero value to non-zero value to zero value, 19800 refund
-			c.quotaRefund = c.quotaRefund + sstoreResetClearRefundGas
+			c.quotaRefund = c.quotaRefund + vm.gasTable.SstoreResetClearRefundGas
 		} else {
 			// non-zero value a to non-zero value b to non-zero value a,4800 refund for first sstore
-			c.quotaRefund = c.quotaRefund + sstoreResetRefundGas
+			c.quotaRefund = c.quotaRefund + vm.gasTable.SstoreResetRefundGas
 		}
 	}
-	return sstoreDirtyGas, nil
+	return vm.gasTable.SstoreDirtyGas, nil
 }
 
 func gasPush(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
@@ -302,20 +309,20 @@ func makeGasLog(n uint64) gasFunc {
 			return 0, util.ErrGasUintOverflow
 		}
 
-		gas, err := memoryGasCost(mem, memorySize)
+		gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 		if err != nil {
 			return 0, err
 		}
 
-		if gas, overflow = helper.SafeAdd(gas, logGas); overflow {
+		if gas, overflow = helper.SafeAdd(gas, vm.gasTable.LogGas); overflow {
 			return 0, util.ErrGasUintOverflow
 		}
-		if gas, overflow = helper.SafeAdd(gas, n*logTopicGas); overflow {
+		if gas, overflow = helper.SafeAdd(gas, n*vm.gasTable.LogTopicGas); overflow {
 			return 0, util.ErrGasUintOverflow
 		}
 
 		var memorySizeGas uint64
-		if memorySizeGas, overflow = helper.SafeMul(requestedSize, logDataGas); overflow {
+		if memorySizeGas, overflow = helper.SafeMul(requestedSize, vm.gasTable.LogDataGas); overflow {
 			return 0, util.ErrGasUintOverflow
 		}
 		if gas, overflow = helper.SafeAdd(gas, memorySizeGas); overflow {
@@ -326,33 +333,33 @@ func makeGasLog(n uint64) gasFunc {
 }
 
 func gasDelegateCall(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, err
 	}
 	var overflow bool
-	if gas, overflow = helper.SafeAdd(gas, callGas); overflow {
+	if gas, overflow = helper.SafeAdd(gas, vm.gasTable.CallGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 	return gas, nil
 }
 
 func gasCall(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	gas, err := memoryGasCost(mem, memorySize)
+	gas, err := memoryGasCost(vm.gasTable, mem, memorySize)
 	if err != nil {
 		return 0, err
 	}
 	var overflow bool
-	if gas, overflow = helper.SafeAdd(gas, callGas); overflow {
+	if gas, overflow = helper.SafeAdd(gas, vm.gasTable.CallGas); overflow {
 		return 0, util.ErrGasUintOverflow
 	}
 	return gas, nil
 }
 
 func gasReturn(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	return memoryGasCost(mem, memorySize)
+	return memoryGasCost(vm.gasTable, mem, memorySize)
 }
 
 func gasRevert(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64) (uint64, error) {
-	return memoryGasCost(mem, memorySize)
+	return memoryGasCost(vm.gasTable, mem, memorySize)
 }
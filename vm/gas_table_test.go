@@ -2,12 +2,14 @@ package vm
 
 import (
 	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
 	"testing"
 )
 
 func TestMemoryGasCost(t *testing.T) {
 	size := uint64(0xffffffffe0)
-	v, err := memoryGasCost(&memory{}, size)
+	v, err := memoryGasCost(&util.InitialGasTable, &memory{}, size)
 	if err != nil {
 		t.Error("didn't expect error:", err)
 	}
@@ -15,13 +17,37 @@ func TestMemoryGasCost(t *testing.T) {
 		t.Errorf("Expected: 36028899963961341, got %d", v)
 	}
 
-	_, err = memoryGasCost(&memory{}, size+1)
+	_, err = memoryGasCost(&util.InitialGasTable, &memory{}, size+1)
 	if err == nil {
 		t.Error("expected error")
 	}
 
-	_, err = memoryGasCost(&memory{}, helper.MaxUint64-64)
+	_, err = memoryGasCost(&util.InitialGasTable, &memory{}, helper.MaxUint64-64)
 	if err == nil {
 		t.Errorf("Expected error")
 	}
 }
+
+func TestGasTableOfVm(t *testing.T) {
+	next := util.InitialGasTable
+	next.JumpdestGas = 2
+	next.CopyGas = 6
+	next.MemoryGas = 4
+	next.QuadCoeffDiv = 256
+	for _, gasTable := range []*util.GasTable{&util.InitialGasTable, &next} {
+		vm := &VM{gasTable: gasTable}
+		if gas, _ := simpleInstructionSet[JUMPDEST].gasCost(vm, nil, nil, nil, 0); gas != gasTable.JumpdestGas {
+			t.Fatalf("unexpected jumpdest cost %v", gas)
+		}
+		st := newStack()
+		st.push(big.NewInt(64))
+		st.push(big.NewInt(0))
+		st.push(big.NewInt(0))
+		gas, err := gasCallDataCopy(vm, nil, st, &memory{}, 64)
+		// 2 words of memory and 2 words copied
+		expected := fastestStepGas + 2*gasTable.MemoryGas + 4/gasTable.QuadCoeffDiv + 2*gasTable.CopyGas
+		if err != nil || gas != expected {
+			t.Fatalf("unexpected calldatacopy cost %v, expected %v, err %v", gas, expected, err)
+		}
+	}
+}
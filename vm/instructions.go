@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"github.com/vitelabs/go-vite/common/helper"
@@ -9,6 +10,8 @@ import (
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_context"
+	"math/big"
+	"sort"
 )
 
 func opStop(pc *uint64, vm *VM, c *contract, memory *memory, stack *stack) ([]byte, error) {
@@ -789,6 +792,78 @@ func opCall(pc *uint64, vm *VM, c *contract, memory *memory, stack *stack) ([]by
 	return nil, nil
 }
 
+// opSelfdestruct sends all the balances of the contract to the beneficiary, deletes the storage and the code,
+// and marks the contract destroyed.
+func opSelfdestruct(pc *uint64, vm *VM, c *contract, memory *memory, stack *stack) ([]byte, error) {
+	beneficiaryBig := stack.pop()
+	beneficiary, _ := types.BigToAddress(beneficiaryBig)
+	c.intPool.put(beneficiaryBig)
+	if beneficiary == c.block.AccountAddress {
+		return nil, util.ErrInvalidBeneficiary
+	}
+
+	// every key walked costs quota, so the work is bounded by the quota of the block however large the storage is
+	useKeyQuota := func() (err error) {
+		c.quotaLeft, err = util.UseQuota(c.quotaLeft, vm.gasTable.SelfdestructKeyGas)
+		return err
+	}
+
+	var tokenIdList []types.TokenTypeId
+	iterator := c.db.NewStorageIterator(&c.block.AccountAddress, vm_context.STORAGE_KEY_BALANCE)
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		if err := useKeyQuota(); err != nil {
+			return nil, err
+		}
+		if len(value) == 0 || new(big.Int).SetBytes(value).Sign() == 0 {
+			continue
+		}
+		if tokenId, err := types.BytesToTokenTypeId(key[len(vm_context.STORAGE_KEY_BALANCE):]); err == nil {
+			tokenIdList = append(tokenIdList, tokenId)
+		}
+	}
+	sort.Slice(tokenIdList, func(i, j int) bool {
+		return bytes.Compare(tokenIdList[i].Bytes(), tokenIdList[j].Bytes()) < 0
+	})
+	for _, tokenId := range tokenIdList {
+		// the balance is subtracted when the send block is run
+		vm.AppendBlock(
+			&vm_context.VmAccountBlock{
+				AccountBlock: util.MakeSendBlock(
+					c.block,
+					beneficiary,
+					ledger.BlockTypeSendCall,
+					c.db.GetBalance(&c.block.AccountAddress, &tokenId),
+					tokenId,
+					vm.VmContext.GetNewBlockHeight(c.block),
+					nil),
+				VmContext: nil})
+	}
+
+	var keyList [][]byte
+	iterator = c.db.NewStorageIterator(&c.block.AccountAddress, nil)
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		if err := useKeyQuota(); err != nil {
+			return nil, err
+		}
+		if len(value) > 0 && !bytes.HasPrefix(key, vm_context.STORAGE_KEY_BALANCE) && !bytes.HasPrefix(key, vm_context.STORAGE_KEY_CODE) {
+			keyList = append(keyList, key)
+		}
+	}
+	for _, key := range keyList {
+		c.db.SetStorage(key, nil)
+	}
+	c.db.SetContractCode(util.PackContractCode(util.DestroyedContractType, nil))
+	return nil, nil
+}
+
 func opOffchainCall(pc *uint64, vm *VM, c *contract, memory *memory, stack *stack) ([]byte, error) {
 	c.intPool.put(stack.pop(), stack.pop(), stack.pop(), stack.pop(), stack.pop())
 	return nil, nil
@@ -18,9 +18,13 @@ var (
 	offchainSimpleInterpreter = &Interpreter{offchainSimpleInstructionSet}
 	mintInterpreter           = &Interpreter{mintInstructionSet}
 	offchainMintInterpreter   = &Interpreter{offchainMintInstructionSet}
+	selfdestructInterpreter   = &Interpreter{selfdestructInstructionSet}
 )
 
 func NewInterpreter(blockHeight uint64, offChain bool) *Interpreter {
+	if fork.IsSelfdestructFork(blockHeight) && !offChain {
+		return selfdestructInterpreter
+	}
 	if fork.IsMintFork(blockHeight) {
 		if offChain {
 			return offchainMintInterpreter
@@ -74,8 +78,15 @@ func (i *Interpreter) Run(vm *VM, c *contract) (ret []byte, err error) {
 		if err != nil {
 			return nil, err
 		}
+		var step *TraceStep
+		if vm.tracer != nil {
+			step = vm.tracer.captureStep(c, currentPc, op, cost, st)
+		}
 		c.quotaLeft, err = util.UseQuota(c.quotaLeft, cost)
 		if err != nil {
+			if step != nil {
+				step.Err = err.Error()
+			}
 			return nil, err
 		}
 
@@ -84,6 +95,9 @@ func (i *Interpreter) Run(vm *VM, c *contract) (ret []byte, err error) {
 		}
 
 		res, err := operation.execute(&pc, vm, c, mem, st)
+		if step != nil && err != nil {
+			step.Err = err.Error()
+		}
 
 		if nodeConfig.IsDebug {
 			currentCode := ""
@@ -1,6 +1,7 @@
 package vm
 
 import (
+	"github.com/vitelabs/go-vite/vm/util"
 	"math/big"
 )
 
@@ -34,8 +35,22 @@ var (
 	offchainSimpleInstructionSet = newOffchainSimpleInstructionSet()
 	mintInstructionSet           = newMintInstructionSet()
 	offchainMintInstructionSet   = newOffchainMintInstructionSet()
+	selfdestructInstructionSet   = newSelfdestructInstructionSet()
 )
 
+func newSelfdestructInstructionSet() [256]operation {
+	instructionSet := newMintInstructionSet()
+	instructionSet[SELFDESTRUCT] = operation{
+		execute:       opSelfdestruct,
+		gasCost:       tableGasFunc(func(t *util.GasTable) uint64 { return t.SelfdestructGas }),
+		validateStack: makeStackFunc(1, 0),
+		halts:         true,
+		valid:         true,
+		writes:        true,
+	}
+	return instructionSet
+}
+
 func newMintInstructionSet() [256]operation {
 	instructionSet := newSimpleInstructionSet()
 	instructionSet[ACCOUNTHEIGHT] = operation{
@@ -98,7 +113,7 @@ func newSimpleInstructionSet() [256]operation {
 	}
 	instructionSet[BALANCE] = operation{
 		execute:       opBalance,
-		gasCost:       constGasFunc(balanceGas),
+		gasCost:       tableGasFunc(func(t *util.GasTable) uint64 { return t.BalanceGas }),
 		validateStack: makeStackFunc(2, 1),
 		valid:         true,
 	}
@@ -208,7 +223,7 @@ func newOffchainSimpleInstructionSet() [256]operation {
 	}
 	instructionSet[BALANCE] = operation{
 		execute:       opOffchainBalance,
-		gasCost:       constGasFunc(balanceGas),
+		gasCost:       tableGasFunc(func(t *util.GasTable) uint64 { return t.BalanceGas }),
 		validateStack: makeStackFunc(2, 1),
 		valid:         true,
 	}
@@ -507,7 +522,7 @@ func newBaseInstructionSet() [256]operation {
 		},
 		EXTCODESIZE: {
 			execute:       opExtCodeSize,
-			gasCost:       constGasFunc(extCodeSizeGas),
+			gasCost:       tableGasFunc(func(t *util.GasTable) uint64 { return t.ExtCodeSizeGas }),
 			validateStack: makeStackFunc(1, 1),
 			valid:         true,
 		},
@@ -560,7 +575,7 @@ func newBaseInstructionSet() [256]operation {
 		},
 		SLOAD: {
 			execute:       opSLoad,
-			gasCost:       constGasFunc(sLoadGas),
+			gasCost:       tableGasFunc(func(t *util.GasTable) uint64 { return t.SLoadGas }),
 			validateStack: makeStackFunc(1, 1),
 			valid:         true,
 		},
@@ -592,7 +607,7 @@ func newBaseInstructionSet() [256]operation {
 		},
 		JUMPDEST: {
 			execute:       opJumpdest,
-			gasCost:       constGasFunc(jumpdestGas),
+			gasCost:       tableGasFunc(func(t *util.GasTable) uint64 { return t.JumpdestGas }),
 			validateStack: makeStackFunc(0, 0),
 			valid:         true,
 		},
@@ -0,0 +1,92 @@
+package quota
+
+import (
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+const (
+	// CongestionWindow is the count of the latest snapshot blocks the congestion of the net is measured over
+	CongestionWindow uint64 = 75
+	// CongestionCapacity is the fullness of the window the net takes without scaling the quota down, the fullness
+	// of a snapshot block is the count of the accounts it snapshots
+	CongestionCapacity uint64 = CongestionWindow * 200
+
+	// CongestionScaleBase is the scale of the quota when the net is not congested, the quota is scaled in per mille
+	CongestionScaleBase uint64 = 1000
+	minCongestionScale  uint64 = 500
+
+	congestionCacheSize = 1000
+)
+
+type congestionDb interface {
+	CurrentSnapshotBlock() *ledger.SnapshotBlock
+	GetSnapshotBlockByHash(hash *types.Hash) *ledger.SnapshotBlock
+}
+
+var (
+	// fullness of the windows ending at the snapshot hashes, a window never changes once its snapshot block is
+	// produced, so it is kept across forks of the chain
+	congestionCache     *simplelru.LRU
+	congestionCacheLock sync.Mutex
+)
+
+func init() {
+	congestionCache, _ = simplelru.NewLRU(congestionCacheSize, nil)
+}
+
+// CongestionScale returns the per mille the quota is scaled by when the window is as full as fullness, the quota is
+// scaled down in proportion beyond CongestionCapacity, but not below half of it.
+func CongestionScale(fullness uint64) uint64 {
+	if fullness <= CongestionCapacity {
+		return CongestionScaleBase
+	}
+	scale := CongestionCapacity * CongestionScaleBase / fullness
+	if scale < minCongestionScale {
+		return minCongestionScale
+	}
+	return scale
+}
+
+// GetCongestion returns the fullness of the window ending at the current snapshot block and the per mille the
+// quota is scaled by, which is always CongestionScaleBase before the CongestionQuota fork.
+func GetCongestion(db congestionDb) (fullness uint64, scale uint64) {
+	current := db.CurrentSnapshotBlock()
+	fullness = congestionFullness(db, current)
+	if !fork.IsCongestionQuotaFork(current.Height) {
+		return fullness, CongestionScaleBase
+	}
+	return fullness, CongestionScale(fullness)
+}
+
+func congestionFullness(db congestionDb, current *ledger.SnapshotBlock) uint64 {
+	congestionCacheLock.Lock()
+	cached, ok := congestionCache.Get(current.Hash)
+	congestionCacheLock.Unlock()
+	if ok {
+		return cached.(uint64)
+	}
+	fullness := uint64(0)
+	for i, block := uint64(0), current; block != nil; i++ {
+		fullness += uint64(len(block.SnapshotContent))
+		if i+1 >= CongestionWindow || block.Height <= 1 {
+			break
+		}
+		block = db.GetSnapshotBlockByHash(&block.PrevHash)
+	}
+	congestionCacheLock.Lock()
+	congestionCache.Add(current.Hash, fullness)
+	congestionCacheLock.Unlock()
+	return fullness
+}
+
+func scaleQuota(quota uint64, scale uint64) uint64 {
+	if scale == CongestionScaleBase {
+		return quota
+	}
+	return quota * scale / CongestionScaleBase
+}
@@ -0,0 +1,87 @@
+package quota
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type congestionTestDb struct {
+	blocks []*ledger.SnapshotBlock
+	reads  int
+}
+
+func (db *congestionTestDb) CurrentSnapshotBlock() *ledger.SnapshotBlock {
+	return db.blocks[len(db.blocks)-1]
+}
+func (db *congestionTestDb) GetSnapshotBlockByHash(hash *types.Hash) *ledger.SnapshotBlock {
+	db.reads++
+	for _, block := range db.blocks {
+		if block.Hash == *hash {
+			return block
+		}
+	}
+	return nil
+}
+
+func newCongestionTestDb(seed byte, height uint64, accounts int) *congestionTestDb {
+	db := &congestionTestDb{}
+	prevHash := types.Hash{}
+	for h := uint64(1); h <= height; h++ {
+		content := make(ledger.SnapshotContent, accounts)
+		for i := 0; i < accounts; i++ {
+			content[types.Address{byte(i), byte(i >> 8)}] = &ledger.HashHeight{Height: h}
+		}
+		block := &ledger.SnapshotBlock{Height: h, PrevHash: prevHash, Hash: types.DataHash([]byte{seed, byte(h), byte(h >> 8)}), SnapshotContent: content}
+		db.blocks = append(db.blocks, block)
+		prevHash = block.Hash
+	}
+	return db
+}
+
+func TestCongestionScale(t *testing.T) {
+	for _, c := range []struct {
+		fullness uint64
+		scale    uint64
+	}{
+		{0, CongestionScaleBase},
+		{CongestionCapacity, CongestionScaleBase},
+		{CongestionCapacity + CongestionCapacity/4, 800},
+		{CongestionCapacity * 2, 500},
+		{CongestionCapacity * 10, minCongestionScale},
+	} {
+		if scale := CongestionScale(c.fullness); scale != c.scale {
+			t.Fatalf("scale of fullness %v, expected %v, got %v", c.fullness, c.scale, scale)
+		}
+	}
+}
+
+func TestGetCongestion(t *testing.T) {
+	accounts := int(CongestionCapacity/CongestionWindow) * 2
+	db := newCongestionTestDb(1, CongestionWindow+10, accounts)
+	fullness, scale := GetCongestion(db)
+	if fullness != CongestionWindow*uint64(accounts) || scale != CongestionScaleBase {
+		t.Fatalf("unexpected congestion before the fork, fullness %v, scale %v", fullness, scale)
+	}
+	if db.reads != int(CongestionWindow)-1 {
+		t.Fatalf("the window should be read once, read %v snapshot blocks", db.reads)
+	}
+
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, CongestionQuota: &config.ForkPoint{Height: 2}})
+	defer fork.SetForkPoints(&config.ForkPoints{})
+	if fullness, scale := GetCongestion(db); fullness != CongestionWindow*uint64(accounts) || scale != 500 {
+		t.Fatalf("unexpected congestion after the fork, fullness %v, scale %v", fullness, scale)
+	}
+	if db.reads != int(CongestionWindow)-1 {
+		t.Fatalf("the fullness of the window should be cached, read %v snapshot blocks", db.reads)
+	}
+
+	// the window is not full yet at the beginning of the chain
+	db = newCongestionTestDb(2, 3, accounts)
+	if fullness, scale := GetCongestion(db); fullness != 3*uint64(accounts) || scale != CongestionScaleBase {
+		t.Fatalf("unexpected congestion of a short chain, fullness %v, scale %v", fullness, scale)
+	}
+}
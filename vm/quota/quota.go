@@ -1,12 +1,16 @@
 package quota
 
 import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
 	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
 	"math/big"
+	"sort"
 	"time"
 )
 
@@ -14,20 +18,108 @@ type NodeConfig struct {
 	QuotaParams
 	sectionList    []*big.Float
 	difficultyList []*big.Int
+
+	// the params and the sections above calculated in integers
+	floatA           quotaFloat
+	floatB           quotaFloat
+	floatSectionList []quotaFloat
 }
 
 var nodeConfig NodeConfig
 
 func InitQuotaConfig(isTestParam bool) {
+	if isTestParam {
+		nodeConfig, _ = NewNodeConfig(QuotaParamTest, sectionStrList, difficultyListTest)
+	} else {
+		nodeConfig, _ = NewNodeConfig(QuotaParamMainNet, sectionStrList, difficultyListMainNet)
+	}
+}
+
+// NewNodeConfig creates a quota config of params, sectionStrList are the ascending lower bounds of x of the
+// quota sections, difficultyList are the PoW difficulties of the sections with params.
+func NewNodeConfig(params QuotaParams, sectionStrList []string, difficultyList []*big.Int) (NodeConfig, error) {
+	if params.paramA == nil || params.paramB == nil {
+		return NodeConfig{}, errors.New("invalid quota params")
+	}
 	sectionList := make([]*big.Float, len(sectionStrList))
+	floatSectionList := make([]quotaFloat, len(sectionStrList))
 	for i, str := range sectionStrList {
-		sectionList[i], _ = new(big.Float).SetPrec(precForFloat).SetString(str)
+		section, ok := new(big.Float).SetPrec(precForFloat).SetString(str)
+		if !ok {
+			return NodeConfig{}, errors.New("invalid quota section " + str)
+		}
+		if i > 0 && section.Cmp(sectionList[i-1]) <= 0 {
+			return NodeConfig{}, errors.New("quota sections are not ascending")
+		}
+		sectionList[i] = section
+		floatSectionList[i] = quotaFloatFromBigFloat(section)
 	}
-	if isTestParam {
-		nodeConfig = NodeConfig{QuotaParamTest, sectionList, difficultyListTest}
-	} else {
-		nodeConfig = NodeConfig{QuotaParamMainNet, sectionList, difficultyListMainNet}
+	if len(sectionList) == 0 {
+		return NodeConfig{}, errors.New("empty quota sections")
+	}
+	return NodeConfig{
+		QuotaParams:      params,
+		sectionList:      sectionList,
+		difficultyList:   difficultyList,
+		floatA:           quotaFloatFromBigFloat(params.paramA),
+		floatB:           quotaFloatFromBigFloat(params.paramB),
+		floatSectionList: floatSectionList,
+	}, nil
+}
+
+// MainNetSectionList returns the quota sections of the main net.
+func MainNetSectionList() []string {
+	return append([]string(nil), sectionStrList...)
+}
+
+// PledgeQuota returns the quota pledgeAmount gets for a block referring to the snapshot block heightGap after
+// the one of its previous block.
+func (c *NodeConfig) PledgeQuota(heightGap uint64, pledgeAmount *big.Int) uint64 {
+	if pledgeAmount.Sign() == 0 {
+		return 0
+	}
+	return uint64(c.indexInSection(c.pledgeX(heightGap, pledgeAmount))) * quotaForSection
+}
+
+// PoWDifficulty returns the least PoW difficulty which gets quotaRequired together with the pledge of
+// PledgeQuota, nil if quotaRequired is beyond the sections.
+func (c *NodeConfig) PoWDifficulty(heightGap uint64, pledgeAmount *big.Int, quotaRequired uint64) *big.Int {
+	index := calcSectionIndexByQuotaRequired(quotaRequired)
+	if index >= uint64(len(c.sectionList)) {
+		return nil
 	}
+	x := new(big.Float).SetPrec(precForFloat).Sub(c.sectionList[index], c.pledgeX(heightGap, pledgeAmount).bigFloat())
+	if x.Sign() <= 0 {
+		return big.NewInt(0)
+	}
+	x.Quo(x, c.paramB)
+	difficulty, _ := x.Int(nil)
+	enough := func(difficulty *big.Int) bool {
+		x := quotaFloatFromInt(difficulty).mul(c.floatB).add(c.pledgeX(heightGap, pledgeAmount))
+		return uint64(c.indexInSection(x)) >= index
+	}
+	if enough(difficulty) {
+		return difficulty
+	}
+	// the quotient is rounded, raise it by doubling steps until the quota calculated the same way as CalcQuotaV2
+	// is enough, then search the least one between the last two
+	low, step := difficulty, big.NewInt(1)
+	high := new(big.Int).Add(low, step)
+	for !enough(high) {
+		low = high
+		step.Lsh(step, 1)
+		high = new(big.Int).Add(low, step)
+	}
+	for new(big.Int).Sub(high, low).Cmp(helper.Big1) > 0 {
+		mid := new(big.Int).Add(low, high)
+		mid.Rsh(mid, 1)
+		if enough(mid) {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return high
 }
 
 type quotaDb interface {
@@ -36,12 +128,13 @@ type quotaDb interface {
 	GetAccountBlockByHash(hash *types.Hash) *ledger.AccountBlock
 	CurrentSnapshotBlock() *ledger.SnapshotBlock
 	PrevAccountBlock() *ledger.AccountBlock
+	GetPrevAccountBlocksBySnapshotHash(snapshotHash *types.Hash) ([]*ledger.AccountBlock, error)
 	GetSnapshotBlockByHash(hash *types.Hash) *ledger.SnapshotBlock
 	GetGenesisSnapshotBlock() *ledger.SnapshotBlock
 }
 
+// GetPledgeQuota returns the quota beneficial gets by pledge, the chain caches it by the address and the snapshot hash
 func GetPledgeQuota(db quotaDb, beneficial types.Address, pledgeAmount *big.Int) (uint64, error) {
-	// TODO cache
 	quotaTotal, _, err := CalcQuota(db, beneficial, pledgeAmount, big.NewInt(0))
 	return quotaTotal, err
 }
@@ -52,7 +145,9 @@ func GetPledgeQuota(db quotaDb, beneficial types.Address, pledgeAmount *big.Int)
 //				- quotaLimitForAccount * (1 - 2/(1 + e**(fPledge * snapshotHeightGap * pledgeAmount)))
 // snapshotHeightGap is limit to 1 day
 // e**(fDifficulty * difficulty + fPledge * snapshotHeightGap * pledgeAmount) is discrete to reduce computation complexity
-// quotaLimitForAccount is within a range decided by net congestion and net capacity
+// quotaLimitForAccount is within a range decided by net congestion and net capacity, it is scaled down by
+// CongestionScale of the latest snapshot blocks after the CongestionQuota fork
+// pledgeAmount includes the active leases to and from the account after the QuotaLease fork
 // user account gets extra quota to send or receive a transaction if calc PoW, extra quota is decided by difficulty
 // contract account only gets quota via pledge
 // user account genesis block(a receive block) must calculate a PoW to get quota
@@ -69,9 +164,9 @@ func CalcQuota(db quotaDb, addr types.Address, pledgeAmount *big.Int, difficulty
 	}
 }
 
-func CalcCreateQuota(fee *big.Int) uint64 {
+func CalcCreateQuota(gasTable *util.GasTable, fee *big.Int) uint64 {
 	// TODO calc create quota
-	return quotaForCreateContract
+	return gasTable.QuotaForCreateContract
 }
 
 func IsPoW(nonce []byte) bool {
@@ -80,88 +175,118 @@ func IsPoW(nonce []byte) bool {
 
 func CalcQuotaV2(db quotaDb, addr types.Address, pledgeAmount *big.Int, difficulty *big.Int) (uint64, uint64, error) {
 	isPoW := difficulty.Sign() > 0
-	currentSnapshotHash := db.CurrentSnapshotBlock().Hash
 	prevBlock := db.PrevAccountBlock()
 	quotaUsed := uint64(0)
-	for {
-		if prevBlock != nil && currentSnapshotHash == prevBlock.SnapshotHash {
-			// quick fail on a receive error block referencing to the same snapshot block
-			if prevBlock.BlockType == ledger.BlockTypeReceiveError {
-				return 0, 0, util.ErrOutOfQuota
-			}
-			if isPoW && IsPoW(prevBlock.Nonce) {
-				// only one block gets extra quota when referencing to the same snapshot block
-				return 0, 0, util.ErrCalcPoWTwice
-			}
-			quotaUsed = quotaUsed + prevBlock.Quota
-			prevBlock = db.GetAccountBlockByHash(&prevBlock.PrevHash)
+	sameSnapshotBlocks, err := GetQuotaUsedBlocks(db)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, block := range sameSnapshotBlocks {
+		// quick fail on a receive error block referencing to the same snapshot block
+		if block.BlockType == ledger.BlockTypeReceiveError {
+			return 0, 0, util.ErrOutOfQuota
+		}
+		if isPoW && IsPoW(block.Nonce) {
+			// only one block gets extra quota when referencing to the same snapshot block
+			return 0, 0, util.ErrCalcPoWTwice
+		}
+		quotaUsed = quotaUsed + block.Quota
+	}
+	if len(sameSnapshotBlocks) > 0 {
+		prevBlock = db.GetAccountBlockByHash(&sameSnapshotBlocks[len(sameSnapshotBlocks)-1].PrevHash)
+	}
+
+	scale := CongestionScaleBase
+	if fork.IsCongestionQuotaFork(db.CurrentSnapshotBlock().Height) {
+		_, scale = GetCongestion(db)
+	}
+	if fork.IsQuotaLeaseFork(db.CurrentSnapshotBlock().Height) {
+		pledgeAmount = pledgeAmountWithLeases(db, addr, pledgeAmount)
+	}
+	var x quotaFloat
+	var quotaWithoutPoW uint64
+	if pledgeAmount.Sign() == 0 {
+		quotaWithoutPoW = 0
+	} else {
+		var heightGap uint64
+		if prevBlock == nil {
+			heightGap = db.CurrentSnapshotBlock().Height
 		} else {
-			x := new(big.Float).SetPrec(precForFloat).SetUint64(0)
-			tmpFLoat := new(big.Float).SetPrec(precForFloat)
-			var quotaWithoutPoW uint64
-			if pledgeAmount.Sign() == 0 {
-				quotaWithoutPoW = 0
-			} else {
-				if prevBlock == nil {
-					tmpFLoat.SetUint64(helper.Min(maxQuotaHeightGap, db.CurrentSnapshotBlock().Height))
-				} else {
-					prevSnapshotBlock := db.GetSnapshotBlockByHash(&prevBlock.SnapshotHash)
-					if prevSnapshotBlock == nil {
-						return 0, 0, util.ErrForked
-					}
-					tmpFLoat.SetUint64(helper.Min(maxQuotaHeightGap, db.CurrentSnapshotBlock().Height-prevSnapshotBlock.Height))
-				}
-				x.Mul(tmpFLoat, nodeConfig.paramA)
-				tmpFLoat.SetInt(pledgeAmount)
-				x.Mul(tmpFLoat, x)
-				quotaWithoutPoW = calcQuotaInSection(x)
-			}
-			if quotaWithoutPoW < quotaUsed {
-				return 0, 0, nil
+			prevSnapshotBlock := db.GetSnapshotBlockByHash(&prevBlock.SnapshotHash)
+			if prevSnapshotBlock == nil {
+				return 0, 0, util.ErrForked
 			}
-			quotaTotal := quotaWithoutPoW
-			if isPoW {
-				tmpFLoat.SetInt(difficulty)
-				tmpFLoat.Mul(tmpFLoat, nodeConfig.paramB)
-				x.Add(x, tmpFLoat)
-				quotaTotal = calcQuotaInSection(x)
-			}
-			return quotaTotal - quotaUsed, quotaTotal - quotaWithoutPoW, nil
+			heightGap = db.CurrentSnapshotBlock().Height - prevSnapshotBlock.Height
 		}
+		x = nodeConfig.pledgeX(heightGap, pledgeAmountWithBonus(db, addr, pledgeAmount))
+		quotaWithoutPoW = scaleQuota(calcQuotaInSection(x), scale)
+	}
+	if quotaWithoutPoW < quotaUsed {
+		return 0, 0, nil
+	}
+	quotaTotal := quotaWithoutPoW
+	if isPoW {
+		x = x.add(quotaFloatFromInt(difficulty).mul(nodeConfig.floatB))
+		quotaTotal = scaleQuota(calcQuotaInSection(x), scale)
+	}
+	return quotaTotal - quotaUsed, quotaTotal - quotaWithoutPoW, nil
+}
+
+// GetQuotaUsedBlocks returns the blocks from the prev account block downwards which refer to the current snapshot
+// block, CalcQuotaV2 takes the quota they used from the quota of the next block.
+func GetQuotaUsedBlocks(db quotaDb) ([]*ledger.AccountBlock, error) {
+	currentSnapshotHash := db.CurrentSnapshotBlock().Hash
+	prevBlock := db.PrevAccountBlock()
+	if prevBlock == nil || currentSnapshotHash != prevBlock.SnapshotHash {
+		return nil, nil
+	}
+	// read the blocks referring to the same snapshot block at once instead of walking the prev hashes
+	return db.GetPrevAccountBlocksBySnapshotHash(&currentSnapshotHash)
+}
+
+// pledgeAmountWithBonus adds the bonus of the locked pledges for addr to pledgeAmount, so that a pledge locked for
+// a tier gets the quota of the multiplier of the tier
+func pledgeAmountWithBonus(db quotaDb, addr types.Address, pledgeAmount *big.Int) *big.Int {
+	bonus := abi.UnpackPledgeBeneficialBonus(db.GetStorage(&types.AddressPledge, abi.GetPledgeBeneficialBonusKey(addr)))
+	if bonus.Sign() == 0 {
+		return pledgeAmount
+	}
+	return bonus.Add(bonus, pledgeAmount)
+}
+
+// pledgeAmountWithLeases adds the pledge amounts leased to addr to pledgeAmount and takes the ones addr leases out,
+// the leases expired at the current snapshot block are left out
+func pledgeAmountWithLeases(db quotaDb, addr types.Address, pledgeAmount *big.Int) *big.Int {
+	snapshotHeight := db.CurrentSnapshotBlock().Height
+	leasedIn := abi.GetActiveQuotaLeaseAmount(abi.UnpackQuotaLeaseList(db.NewStorageIterator(&types.AddressQuotaLease, abi.GetQuotaLeaseInPrefix(addr))), snapshotHeight)
+	leasedOut := abi.GetActiveQuotaLeaseAmount(abi.UnpackQuotaLeaseList(db.NewStorageIterator(&types.AddressQuotaLease, abi.GetQuotaLeaseOutPrefix(addr))), snapshotHeight)
+	if leasedIn.Sign() == 0 && leasedOut.Sign() == 0 {
+		return pledgeAmount
+	}
+	amount := leasedIn.Add(leasedIn, pledgeAmount)
+	if amount.Cmp(leasedOut) <= 0 {
+		return big.NewInt(0)
 	}
+	return amount.Sub(amount, leasedOut)
 }
 
-func calcQuotaInSection(x *big.Float) uint64 {
-	// TODO calc Qm according to net congestion in past 3600 snapshot blocks
+func calcQuotaInSection(x quotaFloat) uint64 {
 	return uint64(getIndexInSection(x)) * quotaForSection
 }
 
 // Get the largest index
 // which makes sectionList[index] <= x
-func getIndexInSection(x *big.Float) int {
-	return getIndexInSectionRange(x, 0, len(nodeConfig.sectionList)-1)
-}
-func getIndexInSectionRange(x *big.Float, left, right int) int {
-	if left == right {
-		return getExactIndex(x, left)
-	}
-	mid := (left + right + 1) / 2
-	cmp := nodeConfig.sectionList[mid].Cmp(x)
-	if cmp == 0 {
-		return mid
-	} else if cmp > 0 {
-		return getIndexInSectionRange(x, left, mid-1)
-	} else {
-		return getIndexInSectionRange(x, mid, right)
-	}
+func getIndexInSection(x quotaFloat) int {
+	return nodeConfig.indexInSection(x)
 }
-
-func getExactIndex(x *big.Float, index int) int {
-	if nodeConfig.sectionList[index].Cmp(x) <= 0 || index == 0 {
-		return index
-	} else {
-		return index - 1
+func (c *NodeConfig) indexInSection(x quotaFloat) int {
+	index := sort.Search(len(c.floatSectionList), func(i int) bool {
+		return c.floatSectionList[i].cmp(x) > 0
+	})
+	if index == 0 {
+		return 0
 	}
+	return index - 1
 }
 
 // A single account is limited to send 10 tx with PoW in one day
@@ -216,7 +341,63 @@ func CanPoW(db quotaDb, addr types.Address) bool {
 	}
 }
 
+// CalcPoWDifficulty returns the PoW difficulty which gets quotaRequired with no pledge, nil if quotaRequired is beyond
+// the sections.
 func CalcPoWDifficulty(quotaRequired uint64) *big.Int {
 	index := calcSectionIndexByQuotaRequired(quotaRequired)
+	if index >= uint64(len(nodeConfig.difficultyList)) {
+		return nil
+	}
 	return new(big.Int).Set(nodeConfig.difficultyList[index])
 }
+
+// CalcPledgeAmount returns the least pledge amount which gets quotaRequired for a block referring to the snapshot block
+// next to the one of its previous block, so that an account sending a transaction in every snapshot block needs no PoW.
+// It returns nil if pledge can't get quotaRequired.
+func CalcPledgeAmount(quotaRequired uint64) *big.Int {
+	index := calcSectionIndexByQuotaRequired(quotaRequired)
+	if index >= uint64(len(nodeConfig.sectionList)) {
+		return nil
+	}
+	if index == 0 {
+		return big.NewInt(0)
+	}
+	enough := func(pledgeAmount *big.Int) bool {
+		return uint64(getIndexInSection(calcPledgeX(1, pledgeAmount))) >= index
+	}
+	sectionAmount := func(i uint64) *big.Int {
+		x := new(big.Float).SetPrec(precForFloat).Quo(nodeConfig.sectionList[i], nodeConfig.paramA)
+		pledgeAmount, _ := x.Int(nil)
+		return pledgeAmount
+	}
+	// the quotients are rounded and the quota is calculated the same way as CalcQuotaV2, so the least amount is
+	// searched between the bound of the previous section, which is not enough, and the bound of the section, which
+	// is raised by doubling steps until it is enough
+	low, high := sectionAmount(index-1), sectionAmount(index)
+	if enough(low) {
+		low = big.NewInt(0)
+	}
+	for step := big.NewInt(1); !enough(high); step.Lsh(step, 1) {
+		low = high
+		high = new(big.Int).Add(low, step)
+	}
+	for new(big.Int).Sub(high, low).Cmp(helper.Big1) > 0 {
+		mid := new(big.Int).Add(low, high)
+		mid.Rsh(mid, 1)
+		if enough(mid) {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+	return high
+}
+
+func calcPledgeX(heightGap uint64, pledgeAmount *big.Int) quotaFloat {
+	return nodeConfig.pledgeX(heightGap, pledgeAmount)
+}
+
+func (c *NodeConfig) pledgeX(heightGap uint64, pledgeAmount *big.Int) quotaFloat {
+	x := quotaFloatFromUint64(helper.Min(maxQuotaHeightGap, heightGap)).mul(c.floatA)
+	return quotaFloatFromInt(pledgeAmount).mul(x)
+}
@@ -0,0 +1,115 @@
+package quota
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// quotaFloat is a non-negative binary floating point number mant * 2**exp held in integers, mant has exactly
+// precForFloat bits unless it is zero. Results are rounded to nearest even the same way as a big.Float of
+// precForFloat does, so that the quota is calculated bit exactly without allocating big.Float values.
+type quotaFloat struct {
+	mant uint64
+	exp  int
+}
+
+// newQuotaFloat rounds mant * 2**exp to precForFloat bits
+func newQuotaFloat(mant uint64, exp int) quotaFloat {
+	if mant == 0 {
+		return quotaFloat{}
+	}
+	n := bits.Len64(mant)
+	if n <= int(precForFloat) {
+		return quotaFloat{mant << uint(int(precForFloat)-n), exp - (int(precForFloat) - n)}
+	}
+	shift := uint(n - int(precForFloat))
+	return roundQuotaFloat(mant>>shift, mant>>(shift-1)&1 == 1, mant&(1<<(shift-1)-1) != 0, exp+int(shift))
+}
+
+// roundQuotaFloat rounds the truncated mant of precForFloat bits to nearest even, half is the highest bit truncated
+// and sticky tells whether any lower bit truncated is set
+func roundQuotaFloat(mant uint64, half, sticky bool, exp int) quotaFloat {
+	if half && (sticky || mant&1 == 1) {
+		mant++
+		if mant == 1<<precForFloat {
+			mant >>= 1
+			exp++
+		}
+	}
+	return quotaFloat{mant, exp}
+}
+
+func quotaFloatFromUint64(x uint64) quotaFloat {
+	return newQuotaFloat(x, 0)
+}
+
+// quotaFloatFromInt rounds a non-negative x to precForFloat bits
+func quotaFloatFromInt(x *big.Int) quotaFloat {
+	n := x.BitLen()
+	if n <= 64 {
+		return newQuotaFloat(x.Uint64(), 0)
+	}
+	shift := uint(n - int(precForFloat))
+	mant := new(big.Int).Rsh(x, shift).Uint64()
+	return roundQuotaFloat(mant, x.Bit(int(shift-1)) == 1, x.TrailingZeroBits() < shift-1, int(shift))
+}
+
+// quotaFloatFromBigFloat converts a non-negative f of at most precForFloat bits
+func quotaFloatFromBigFloat(f *big.Float) quotaFloat {
+	if f.Sign() == 0 {
+		return quotaFloat{}
+	}
+	mant := new(big.Float)
+	exp := f.MantExp(mant)
+	m, _ := mant.SetMantExp(mant, int(precForFloat)).Uint64()
+	return newQuotaFloat(m, exp-int(precForFloat))
+}
+
+func (x quotaFloat) bigFloat() *big.Float {
+	return new(big.Float).SetPrec(precForFloat).SetMantExp(new(big.Float).SetUint64(x.mant), x.exp)
+}
+
+func (x quotaFloat) mul(y quotaFloat) quotaFloat {
+	return newQuotaFloat(x.mant*y.mant, x.exp+y.exp)
+}
+
+func (x quotaFloat) add(y quotaFloat) quotaFloat {
+	if x.mant == 0 {
+		return y
+	}
+	if y.mant == 0 {
+		return x
+	}
+	if x.exp < y.exp {
+		x, y = y, x
+	}
+	d := x.exp - y.exp
+	if d > 2*int(precForFloat) {
+		// y is less than half an ulp of x and never rounds x up
+		return x
+	}
+	return newQuotaFloat(x.mant<<uint(d)+y.mant, y.exp)
+}
+
+func (x quotaFloat) cmp(y quotaFloat) int {
+	switch {
+	case x.mant == 0 || y.mant == 0:
+		return compareUint64(x.mant, y.mant)
+	case x.exp != y.exp:
+		if x.exp < y.exp {
+			return -1
+		}
+		return 1
+	default:
+		return compareUint64(x.mant, y.mant)
+	}
+}
+
+func compareUint64(a, b uint64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
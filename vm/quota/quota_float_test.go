@@ -0,0 +1,181 @@
+package quota
+
+import (
+	"github.com/vitelabs/go-vite/common/helper"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// getIndexInSectionByFloat is the section lookup of big.Float replaced by quotaFloat, kept as the reference
+func getIndexInSectionByFloat(x *big.Float) int {
+	return nodeConfig.indexInSectionByFloat(x, 0, len(nodeConfig.sectionList)-1)
+}
+func (c *NodeConfig) indexInSectionByFloat(x *big.Float, left, right int) int {
+	if left == right {
+		if c.sectionList[left].Cmp(x) <= 0 || left == 0 {
+			return left
+		}
+		return left - 1
+	}
+	mid := (left + right + 1) / 2
+	cmp := c.sectionList[mid].Cmp(x)
+	if cmp == 0 {
+		return mid
+	} else if cmp > 0 {
+		return c.indexInSectionByFloat(x, left, mid-1)
+	} else {
+		return c.indexInSectionByFloat(x, mid, right)
+	}
+}
+
+// quotaXByFloat calculates x of the quota with big.Float the way CalcQuotaV2 did before quotaFloat
+func quotaXByFloat(c *NodeConfig, heightGap uint64, pledgeAmount, difficulty *big.Int) *big.Float {
+	x := new(big.Float).SetPrec(precForFloat).SetUint64(0)
+	tmpFLoat := new(big.Float).SetPrec(precForFloat)
+	if pledgeAmount.Sign() > 0 {
+		tmpFLoat.SetUint64(helper.Min(maxQuotaHeightGap, heightGap))
+		x.Mul(tmpFLoat, c.paramA)
+		tmpFLoat.SetInt(pledgeAmount)
+		x.Mul(tmpFLoat, x)
+	}
+	if difficulty.Sign() > 0 {
+		tmpFLoat.SetInt(difficulty)
+		tmpFLoat.Mul(tmpFLoat, c.paramB)
+		x.Add(x, tmpFLoat)
+	}
+	return x
+}
+
+func quotaX(c *NodeConfig, heightGap uint64, pledgeAmount, difficulty *big.Int) quotaFloat {
+	var x quotaFloat
+	if pledgeAmount.Sign() > 0 {
+		x = c.pledgeX(heightGap, pledgeAmount)
+	}
+	if difficulty.Sign() > 0 {
+		x = x.add(quotaFloatFromInt(difficulty).mul(c.floatB))
+	}
+	return x
+}
+
+func randomInt(r *rand.Rand, maxBitLen int) *big.Int {
+	n := r.Intn(maxBitLen + 1)
+	x := new(big.Int)
+	for i := 0; i < n; i++ {
+		if i == n-1 || r.Intn(2) == 1 {
+			x.SetBit(x, i, 1)
+		}
+	}
+	return x
+}
+
+func TestQuotaFloat(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	ints := []*big.Int{big.NewInt(0), big.NewInt(1)}
+	for k := uint(1); k < 130; k++ {
+		p := new(big.Int).Lsh(helper.Big1, k)
+		ints = append(ints, p, new(big.Int).Sub(p, helper.Big1), new(big.Int).Add(p, helper.Big1))
+		if k > precForFloat {
+			// the ties between two floats of precForFloat bits
+			half := new(big.Int).Lsh(helper.Big1, k-precForFloat-1)
+			ints = append(ints, new(big.Int).Add(p, half), new(big.Int).Add(new(big.Int).Add(p, half), new(big.Int).Lsh(half, 1)))
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		ints = append(ints, randomInt(r, 128))
+	}
+	for i, a := range ints {
+		fa := new(big.Float).SetPrec(precForFloat).SetInt(a)
+		qa := quotaFloatFromInt(a)
+		if qa.bigFloat().Cmp(fa) != 0 {
+			t.Fatalf("rounding %v, expected %v, got %v", a, fa, qa.bigFloat())
+		}
+		b := ints[(i*7919+1)%len(ints)]
+		fb := new(big.Float).SetPrec(precForFloat).SetInt(b)
+		qb := quotaFloatFromInt(b)
+		if got, expected := qa.mul(qb).bigFloat(), new(big.Float).SetPrec(precForFloat).Mul(fa, fb); got.Cmp(expected) != 0 {
+			t.Fatalf("%v * %v, expected %v, got %v", a, b, expected, got)
+		}
+		if got, expected := qa.add(qb).bigFloat(), new(big.Float).SetPrec(precForFloat).Add(fa, fb); got.Cmp(expected) != 0 {
+			t.Fatalf("%v + %v, expected %v, got %v", a, b, expected, got)
+		}
+		if qa.cmp(qb) != fa.Cmp(fb) {
+			t.Fatalf("compare %v with %v, expected %v, got %v", a, b, fa.Cmp(fb), qa.cmp(qb))
+		}
+	}
+}
+
+func TestCalcQuota_Differential(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, isTestParam := range []bool{true, false} {
+		InitQuotaConfig(isTestParam)
+		c := &nodeConfig
+
+		heightGapList := []uint64{0, 1, 2, 3, 10, 59, 60, 61, 3599, 3600, maxQuotaHeightGap - 1, maxQuotaHeightGap, maxQuotaHeightGap + 1, helper.MaxUint64}
+		for i := 0; i < 20; i++ {
+			heightGapList = append(heightGapList, uint64(r.Intn(int(maxQuotaHeightGap)+1)))
+		}
+		// the pledge amounts around the lower bounds of the sections for each height gap
+		pledgeAmountList := []*big.Int{big.NewInt(0), big.NewInt(1)}
+		for _, section := range c.sectionList {
+			amount, _ := new(big.Float).Quo(section, c.paramA).Int(nil)
+			for _, heightGap := range []int64{1, 2, 10, 3600, int64(maxQuotaHeightGap)} {
+				base := new(big.Int).Quo(amount, big.NewInt(heightGap))
+				for d := int64(-3); d <= 3; d++ {
+					pledgeAmountList = append(pledgeAmountList, new(big.Int).Add(base, big.NewInt(d)))
+				}
+			}
+		}
+		difficultyList := []*big.Int{big.NewInt(0), big.NewInt(1)}
+		for _, difficulty := range c.difficultyList {
+			for d := int64(-3); d <= 3; d++ {
+				if v := new(big.Int).Add(difficulty, big.NewInt(d)); v.Sign() >= 0 {
+					difficultyList = append(difficultyList, v)
+				}
+			}
+		}
+
+		check := func(heightGap uint64, pledgeAmount, difficulty *big.Int) {
+			expected := quotaXByFloat(c, heightGap, pledgeAmount, difficulty)
+			got := quotaX(c, heightGap, pledgeAmount, difficulty)
+			if got.bigFloat().Cmp(expected) != 0 {
+				t.Fatalf("x of height gap %v, pledge amount %v, difficulty %v, expected %v, got %v", heightGap, pledgeAmount, difficulty, expected, got.bigFloat())
+			}
+			if index, expectedIndex := c.indexInSection(got), c.indexInSectionByFloat(expected, 0, len(c.sectionList)-1); index != expectedIndex {
+				t.Fatalf("section of height gap %v, pledge amount %v, difficulty %v, expected %v, got %v", heightGap, pledgeAmount, difficulty, expectedIndex, index)
+			}
+		}
+		for _, heightGap := range heightGapList {
+			for _, pledgeAmount := range pledgeAmountList {
+				if pledgeAmount.Sign() >= 0 {
+					check(heightGap, pledgeAmount, big.NewInt(0))
+				}
+			}
+		}
+		for _, difficulty := range difficultyList {
+			check(1, big.NewInt(0), difficulty)
+			for i := 0; i < 20; i++ {
+				check(heightGapList[r.Intn(len(heightGapList))], pledgeAmountList[r.Intn(len(pledgeAmountList))], difficulty)
+			}
+		}
+		for i := 0; i < 100000; i++ {
+			check(uint64(r.Intn(int(maxQuotaHeightGap)*2)), randomInt(r, 100), randomInt(r, 40))
+		}
+	}
+}
+
+func BenchmarkCalcQuotaX(b *testing.B) {
+	InitQuotaConfig(false)
+	pledgeAmount, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	difficulty := big.NewInt(67108863)
+	b.Run("big.Float", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			getIndexInSectionByFloat(quotaXByFloat(&nodeConfig, 75, pledgeAmount, difficulty))
+		}
+	})
+	b.Run("quotaFloat", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			getIndexInSection(quotaX(&nodeConfig, 75, pledgeAmount, difficulty))
+		}
+	})
+}
@@ -55,7 +55,7 @@ func TestCalcQuotaForPoWTest(t *testing.T) {
 	tmpFLoat.SetFloat64(difficulty)
 	tmpFLoat.Mul(tmpFLoat, QuotaParamTest.paramB)
 	x.Add(x, tmpFLoat)
-	quotaTotal := uint64(getIndexInSection(x)) * quotaForSection
+	quotaTotal := uint64(getIndexInSectionByFloat(x)) * quotaForSection
 	if quotaTotal != util.TxGas {
 		t.Fatalf("gain quota by calc PoW not enough to create a transaction, got %v", quotaTotal)
 	}
@@ -69,7 +69,7 @@ func TestCalcQuotaForMinPledgeTest(t *testing.T) {
 	x.Mul(tmpFLoat, QuotaParamTest.paramA)
 	tmpFLoat.SetInt(new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18)))
 	x.Mul(tmpFLoat, x)
-	quotaWithoutPoW := uint64(getIndexInSection(x)) * quotaForSection
+	quotaWithoutPoW := uint64(getIndexInSectionByFloat(x)) * quotaForSection
 	if quotaWithoutPoW != util.TxGas {
 		t.Fatalf("gain quota pledge minimum Vite Token not enough to create a transaction, got %v", quotaWithoutPoW)
 	}
@@ -84,7 +84,7 @@ func TestCalcQuotaForMaxPledgeTest(t *testing.T) {
 	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
 	tmpFLoat.SetInt(viteTotalSupply)
 	x.Mul(tmpFLoat, x)
-	quotaWithoutPoW := uint64(getIndexInSection(x)) * quotaForSection
+	quotaWithoutPoW := uint64(getIndexInSectionByFloat(x)) * quotaForSection
 	if quotaWithoutPoW != util.TxGas*uint64(len(nodeConfig.sectionList)-1) {
 		t.Fatalf("gain quota by calc PoW not enough to create a transaction, got %v", quotaWithoutPoW)
 	}
@@ -98,7 +98,7 @@ func TestCalcQuotaForPoWMainNet(t *testing.T) {
 	tmpFLoat.SetFloat64(difficulty)
 	tmpFLoat.Mul(tmpFLoat, QuotaParamMainNet.paramB)
 	x.Add(x, tmpFLoat)
-	quotaTotal := uint64(getIndexInSection(x)) * quotaForSection
+	quotaTotal := uint64(getIndexInSectionByFloat(x)) * quotaForSection
 	if quotaTotal != util.TxGas {
 		t.Fatalf("gain quota by calc PoW not enough to create a transaction, got %v", quotaTotal)
 	}
@@ -112,7 +112,7 @@ func TestCalcQuotaForMinPledgeMainNet(t *testing.T) {
 	x.Mul(tmpFLoat, QuotaParamMainNet.paramA)
 	tmpFLoat.SetInt(new(big.Int).Mul(big.NewInt(10000), big.NewInt(1e18)))
 	x.Mul(tmpFLoat, x)
-	quotaWithoutPoW := uint64(getIndexInSection(x)) * quotaForSection
+	quotaWithoutPoW := uint64(getIndexInSectionByFloat(x)) * quotaForSection
 	if quotaWithoutPoW != util.TxGas {
 		t.Fatalf("gain quota pledge minimum Vite Token not enough to create a transaction, got %v", quotaWithoutPoW)
 	}
@@ -127,7 +127,7 @@ func TestCalcQuotaForMaxPledgeMainNet(t *testing.T) {
 	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
 	tmpFLoat.SetInt(viteTotalSupply)
 	x.Mul(tmpFLoat, x)
-	quotaWithoutPoW := uint64(getIndexInSection(x)) * quotaForSection
+	quotaWithoutPoW := uint64(getIndexInSectionByFloat(x)) * quotaForSection
 	if quotaWithoutPoW != util.TxGas*uint64(len(nodeConfig.sectionList)-1) {
 		t.Fatalf("gain quota by calc PoW not enough to create a transaction, got %v", quotaWithoutPoW)
 	}
@@ -172,7 +172,7 @@ func TestPledgeQuota(t *testing.T) {
 		x.SetInt(pledgeAmount)
 		x.Mul(x, unit)
 		x.Mul(x, nodeConfig.paramA)
-		if got := getIndexInSection(x); got != i {
+		if got := getIndexInSectionByFloat(x); got != i {
 			fmt.Printf("get quota by pledge failed, pledgeAmount = %v, expected %v, got %v， section: %v\n", str, i, got, sectionStrList[i])
 		}
 	}
@@ -205,7 +205,7 @@ func TestPoWQuota(t *testing.T) {
 		difficulty, _ := new(big.Int).SetString(str, 10)
 		x.SetInt(difficulty)
 		x.Mul(x, nodeConfig.paramB)
-		if got := getIndexInSection(x); got != i {
+		if got := getIndexInSectionByFloat(x); got != i {
 			fmt.Printf("get quota by pow failed, difficulty = %v, expected %v, got %v， section: %v\n", str, i, got, sectionStrList[i])
 		}
 	}
@@ -217,11 +217,14 @@ func TestCalcPoWDifficultyMainNet(t *testing.T) {
 	for i, difficulty := range difficultyListMainNet {
 		tmpFLoat.SetInt(difficulty)
 		tmpFLoat.Mul(tmpFLoat, nodeConfig.paramB)
-		q := calcQuotaInSection(tmpFLoat)
+		q := calcQuotaInSection(quotaFloatFromBigFloat(tmpFLoat))
 		if q != uint64(i)*quotaForSection {
 			t.Fatalf("calc pow difficulty of main net failed, %v: %v", i, difficulty)
 		}
 	}
+	if difficulty := CalcPoWDifficulty(uint64(len(difficultyListMainNet)) * quotaForSection); difficulty != nil {
+		t.Fatalf("quota beyond the sections should not be got by pow, got %v", difficulty)
+	}
 }
 func TestCalcPoWDifficultyTest(t *testing.T) {
 	InitQuotaConfig(true)
@@ -229,9 +232,66 @@ func TestCalcPoWDifficultyTest(t *testing.T) {
 	for i, difficulty := range difficultyListTest {
 		tmpFLoat.SetInt(difficulty)
 		tmpFLoat.Mul(tmpFLoat, nodeConfig.paramB)
-		q := calcQuotaInSection(tmpFLoat)
+		q := calcQuotaInSection(quotaFloatFromBigFloat(tmpFLoat))
 		if q != uint64(i)*quotaForSection {
 			t.Fatalf("calc pow difficulty of main net failed, %v: %v", i, difficulty)
 		}
 	}
 }
+
+func TestCalcPledgeAmount(t *testing.T) {
+	for _, isTestParam := range []bool{true, false} {
+		InitQuotaConfig(isTestParam)
+		for _, quotaRequired := range []uint64{0, 1, 21000, 21001, 100000, 500000, uint64(len(nodeConfig.sectionList)-1) * quotaForSection} {
+			pledgeAmount := CalcPledgeAmount(quotaRequired)
+			if pledgeAmount == nil {
+				t.Fatalf("calc pledge amount failed, quota required: %v", quotaRequired)
+			}
+			if q := calcQuotaInSection(calcPledgeX(1, pledgeAmount)); q < quotaRequired {
+				t.Fatalf("pledge amount %v gets quota %v, required %v", pledgeAmount, q, quotaRequired)
+			}
+			less := new(big.Int).Sub(pledgeAmount, big.NewInt(1))
+			if pledgeAmount.Sign() > 0 && calcQuotaInSection(calcPledgeX(1, less)) >= quotaRequired {
+				t.Fatalf("pledge amount %v is not the least for quota %v", pledgeAmount, quotaRequired)
+			}
+		}
+		if pledgeAmount := CalcPledgeAmount(uint64(len(nodeConfig.sectionList)) * quotaForSection); pledgeAmount != nil {
+			t.Fatalf("quota beyond the sections should not be got by pledge, got %v", pledgeAmount)
+		}
+	}
+}
+
+func TestNodeConfig_PoWDifficulty(t *testing.T) {
+	InitQuotaConfig(false)
+	config, err := NewNodeConfig(QuotaParamMainNet, MainNetSectionList(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(difficultyListMainNet); i++ {
+		difficulty := config.PoWDifficulty(1, big.NewInt(0), uint64(i)*quotaForSection)
+		if difficulty == nil || difficulty.Cmp(difficultyListMainNet[i]) > 0 {
+			t.Fatalf("pow difficulty of section %v is %v, expected at most %v", i, difficulty, difficultyListMainNet[i])
+		}
+	}
+
+	pledgeAmount, _ := new(big.Int).SetString("20000000000000000000000", 10)
+	pledgeQuota := config.PledgeQuota(1, pledgeAmount)
+	if pledgeQuota != calcQuotaInSection(calcPledgeX(1, pledgeAmount)) {
+		t.Fatalf("unexpected pledge quota %v", pledgeQuota)
+	}
+	if difficulty := config.PoWDifficulty(1, pledgeAmount, pledgeQuota); difficulty.Sign() != 0 {
+		t.Fatalf("the pledge covers the quota, got difficulty %v", difficulty)
+	}
+	difficulty := config.PoWDifficulty(1, pledgeAmount, pledgeQuota+quotaForSection)
+	x := quotaFloatFromInt(difficulty).mul(config.floatB).add(calcPledgeX(1, pledgeAmount))
+	if q := calcQuotaInSection(x); q < pledgeQuota+quotaForSection {
+		t.Fatalf("difficulty %v gets quota %v with the pledge", difficulty, q)
+	}
+	if config.PoWDifficulty(1, pledgeAmount, uint64(len(config.sectionList))*quotaForSection) != nil {
+		t.Fatal("quota beyond the sections should not be got by pow")
+	}
+
+	if _, err := NewNodeConfig(QuotaParamMainNet, []string{"0.0", "0.2", "0.1"}, nil); err == nil {
+		t.Fatal("sections not ascending should be invalid")
+	}
+}
@@ -0,0 +1,251 @@
+package simulation
+
+// simulation replays traces of account blocks through alternative quota parameters and reports how often
+// the accounts would calculate PoW and how often they would get stuck, so that quota changes at forks are
+// evaluated against the real traffic before they are made.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"text/tabwriter"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/quota"
+)
+
+var errSnapshotBlockMissing = errors.New("the snapshot block referred to is missing")
+
+// CongestionModel scales the quota of the accounts by the congestion of the net.
+type CongestionModel interface {
+	// Window is the count of the latest snapshot blocks the congestion is measured over
+	Window() uint64
+	// Scale returns the factor the quota is scaled by when there are blocks account blocks in the window
+	Scale(blocks uint64) float64
+}
+
+// NoCongestion never scales the quota, as the quota works now.
+type NoCongestion struct{}
+
+func (NoCongestion) Window() uint64 { return 1 }
+
+func (NoCongestion) Scale(blocks uint64) float64 { return 1 }
+
+// LinearCongestion scales the quota down in proportion when there are more than Capacity account blocks
+// in the latest Blocks snapshot blocks, but not below MinScale.
+type LinearCongestion struct {
+	Blocks   uint64
+	Capacity uint64
+	MinScale float64
+}
+
+func (c LinearCongestion) Window() uint64 { return c.Blocks }
+
+func (c LinearCongestion) Scale(blocks uint64) float64 {
+	if blocks <= c.Capacity {
+		return 1
+	}
+	return math.Max(c.MinScale, float64(c.Capacity)/float64(blocks))
+}
+
+// NetCongestion scales the quota the way the net does after the CongestionQuota fork, taking the account blocks
+// in the window as its fullness.
+type NetCongestion struct{}
+
+func (NetCongestion) Window() uint64 { return quota.CongestionWindow }
+
+func (NetCongestion) Scale(blocks uint64) float64 {
+	return float64(quota.CongestionScale(blocks)) / float64(quota.CongestionScaleBase)
+}
+
+// Params is a set of quota parameters to simulate.
+type Params struct {
+	Name string
+	// QuotaParams are created by quota.NewQuotaParams
+	QuotaParams quota.QuotaParams
+	// SectionList are the lower bounds of the quota sections, the sections of the main net if nil
+	SectionList []string
+	// Congestion is NoCongestion if nil
+	Congestion CongestionModel
+}
+
+// MainNetParams returns the quota parameters of the main net.
+func MainNetParams() *Params {
+	return &Params{Name: "mainnet", QuotaParams: quota.QuotaParamMainNet}
+}
+
+// Report is the result of a trace replayed through a set of parameters. A block is stuck when neither its
+// pledge nor a PoW gets its quota, its account has to wait for later snapshot blocks to send it.
+type Report struct {
+	Name     string `json:"name"`
+	Blocks   uint64 `json:"blocks"`
+	Accounts int    `json:"accounts"`
+
+	PledgeBlocks        uint64   `json:"pledgeBlocks"`
+	PoWBlocks           uint64   `json:"powBlocks"`
+	PoWRate             float64  `json:"powRate"`
+	PoWAccounts         int      `json:"powAccounts"`
+	AvgDifficulty       *big.Int `json:"avgDifficulty"`
+	HistoricalPoWBlocks uint64   `json:"historicalPoWBlocks"`
+
+	StuckBlocks   uint64  `json:"stuckBlocks"`
+	StuckRate     float64 `json:"stuckRate"`
+	StuckAccounts int     `json:"stuckAccounts"`
+
+	// CongestedBlocks are the blocks whose quota is scaled down by the congestion model
+	CongestedBlocks uint64 `json:"congestedBlocks"`
+}
+
+// accountState is the quota state of an account in the snapshot block its latest block refers to.
+type accountState struct {
+	snapshotHeight uint64
+	// prevSnapshotHeight is the snapshot height of the blocks before the ones referring to snapshotHeight
+	prevSnapshotHeight uint64
+	hasPrev            bool
+	quotaUsed          uint64
+	powed              bool
+}
+
+func (s *accountState) heightGap() uint64 {
+	if !s.hasPrev {
+		return s.snapshotHeight
+	}
+	return s.snapshotHeight - s.prevSnapshotHeight
+}
+
+// congestionWindow counts the account blocks in the latest snapshot blocks.
+type congestionWindow struct {
+	size    uint64
+	heights []uint64
+	counts  []uint64
+	total   uint64
+}
+
+func (w *congestionWindow) add(height uint64) uint64 {
+	if n := len(w.heights); n > 0 && w.heights[n-1] == height {
+		w.counts[n-1]++
+	} else {
+		w.heights = append(w.heights, height)
+		w.counts = append(w.counts, 1)
+	}
+	w.total++
+	for len(w.heights) > 0 && w.heights[0]+w.size <= height {
+		w.total -= w.counts[0]
+		w.heights = w.heights[1:]
+		w.counts = w.counts[1:]
+	}
+	return w.total
+}
+
+// Simulate replays records through params, the records are sorted in place by the snapshot blocks they refer to.
+func Simulate(records []*Record, params *Params) (*Report, error) {
+	sectionList := params.SectionList
+	if sectionList == nil {
+		sectionList = quota.MainNetSectionList()
+	}
+	config, err := quota.NewNodeConfig(params.QuotaParams, sectionList, nil)
+	if err != nil {
+		return nil, err
+	}
+	congestion := params.Congestion
+	if congestion == nil {
+		congestion = NoCongestion{}
+	}
+	if congestion.Window() == 0 {
+		return nil, errors.New("the congestion window is empty")
+	}
+
+	sortRecords(records)
+	report := &Report{Name: params.Name}
+	accounts := make(map[types.Address]*accountState)
+	powAccounts := make(map[types.Address]struct{})
+	stuckAccounts := make(map[types.Address]struct{})
+	window := &congestionWindow{size: congestion.Window()}
+	difficultySum := new(big.Int)
+
+	for _, r := range records {
+		report.Blocks++
+		if r.PoW {
+			report.HistoricalPoWBlocks++
+		}
+
+		state, ok := accounts[r.Address]
+		if !ok {
+			state = &accountState{snapshotHeight: r.SnapshotHeight}
+			accounts[r.Address] = state
+		} else if state.snapshotHeight != r.SnapshotHeight {
+			state.prevSnapshotHeight, state.hasPrev = state.snapshotHeight, true
+			state.snapshotHeight = r.SnapshotHeight
+			state.quotaUsed, state.powed = 0, false
+		}
+
+		quotaRequired := r.Quota
+		if scale := congestion.Scale(window.add(r.SnapshotHeight)); scale < 1 {
+			report.CongestedBlocks++
+			quotaRequired = uint64(math.Ceil(float64(r.Quota) / scale))
+		}
+
+		pledgeAmount := r.PledgeAmount
+		if pledgeAmount == nil {
+			pledgeAmount = big.NewInt(0)
+		}
+		pledgeQuota := config.PledgeQuota(state.heightGap(), pledgeAmount)
+		if pledgeQuota >= state.quotaUsed+quotaRequired {
+			report.PledgeBlocks++
+			state.quotaUsed += quotaRequired
+			continue
+		}
+		if !r.Contract && !state.powed {
+			if difficulty := config.PoWDifficulty(state.heightGap(), pledgeAmount, state.quotaUsed+quotaRequired); difficulty != nil {
+				report.PoWBlocks++
+				powAccounts[r.Address] = struct{}{}
+				difficultySum.Add(difficultySum, difficulty)
+				state.quotaUsed += quotaRequired
+				state.powed = true
+				continue
+			}
+		}
+		report.StuckBlocks++
+		stuckAccounts[r.Address] = struct{}{}
+	}
+
+	report.Accounts = len(accounts)
+	report.PoWAccounts = len(powAccounts)
+	report.StuckAccounts = len(stuckAccounts)
+	if report.Blocks > 0 {
+		report.PoWRate = float64(report.PoWBlocks) / float64(report.Blocks)
+		report.StuckRate = float64(report.StuckBlocks) / float64(report.Blocks)
+	}
+	report.AvgDifficulty = new(big.Int)
+	if report.PoWBlocks > 0 {
+		report.AvgDifficulty.Quo(difficultySum, new(big.Int).SetUint64(report.PoWBlocks))
+	}
+	return report, nil
+}
+
+// Compare replays records through each of paramsList.
+func Compare(records []*Record, paramsList []*Params) ([]*Report, error) {
+	reports := make([]*Report, 0, len(paramsList))
+	for _, params := range paramsList {
+		report, err := Simulate(records, params)
+		if err != nil {
+			return nil, fmt.Errorf("simulate %v failed, error is %v", params.Name, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// WriteReports writes reports to w as a table.
+func WriteReports(w io.Writer, reports []*Report) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "name\tblocks\taccounts\tpow blocks\tpow rate\tpow accounts\tavg difficulty\tstuck blocks\tstuck rate\tstuck accounts\tcongested blocks")
+	for _, r := range reports {
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%.4f\t%v\t%v\t%v\t%.4f\t%v\t%v\n",
+			r.Name, r.Blocks, r.Accounts, r.PoWBlocks, r.PoWRate, r.PoWAccounts, r.AvgDifficulty,
+			r.StuckBlocks, r.StuckRate, r.StuckAccounts, r.CongestedBlocks)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,123 @@
+package simulation
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/quota"
+)
+
+func newTestTrace() []*Record {
+	pledged := types.Address{1}
+	user := types.Address{2}
+	contract := types.Address{3}
+	pledgeAmount, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	// unsorted on purpose
+	return []*Record{
+		{Address: contract, Height: 1, SnapshotHeight: 11, Quota: 21000, PledgeAmount: big.NewInt(0), Contract: true},
+		{Address: pledged, Height: 2, SnapshotHeight: 11, Quota: 21000, PledgeAmount: pledgeAmount},
+		{Address: pledged, Height: 1, SnapshotHeight: 10, Quota: 21000, PledgeAmount: pledgeAmount},
+		{Address: user, Height: 1, SnapshotHeight: 10, Quota: 21000, PledgeAmount: big.NewInt(0), PoW: true},
+		{Address: user, Height: 2, SnapshotHeight: 10, Quota: 21000, PledgeAmount: big.NewInt(0)},
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	report, err := Simulate(newTestTrace(), MainNetParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Blocks != 5 || report.Accounts != 3 || report.HistoricalPoWBlocks != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.PledgeBlocks != 2 || report.PoWBlocks != 1 || report.PoWAccounts != 1 || report.AvgDifficulty.Sign() <= 0 {
+		t.Fatalf("unexpected pledge and pow of report %+v", report)
+	}
+	// the second block of the user in a snapshot block can't calculate PoW again, the contract can't calculate PoW
+	if report.StuckBlocks != 2 || report.StuckAccounts != 2 || report.CongestedBlocks != 0 {
+		t.Fatalf("unexpected stuck blocks of report %+v", report)
+	}
+
+	congested := MainNetParams()
+	congested.Name = "congested"
+	congested.Congestion = LinearCongestion{Blocks: 1, Capacity: 1, MinScale: 0.5}
+	reports, err := Compare(newTestTrace(), []*Params{MainNetParams(), congested})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reports[1].CongestedBlocks != 3 || reports[1].AvgDifficulty.Cmp(reports[0].AvgDifficulty) <= 0 {
+		t.Fatalf("the congestion should raise the difficulty, reports %+v %+v", reports[0], reports[1])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReports(&buf, reports); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("congested")) {
+		t.Fatalf("unexpected reports %s", buf.String())
+	}
+
+	if _, err := Simulate(newTestTrace(), &Params{Name: "invalid", QuotaParams: quota.QuotaParamMainNet, SectionList: []string{"x"}}); err == nil {
+		t.Fatal("invalid sections should fail the simulation")
+	}
+}
+
+func TestSimulate_NetCongestion(t *testing.T) {
+	pledgeAmount, _ := new(big.Int).SetString("1000000000000000000000000", 10)
+	newTrace := func(blocksPerSnapshotBlock int) []*Record {
+		var records []*Record
+		for h := uint64(1); h <= quota.CongestionWindow; h++ {
+			for i := 0; i < blocksPerSnapshotBlock; i++ {
+				addr := types.Address{byte(i), byte(i >> 8)}
+				records = append(records, &Record{Address: addr, Height: h, SnapshotHeight: h, Quota: 21000, PledgeAmount: pledgeAmount})
+			}
+		}
+		return records
+	}
+	congested := MainNetParams()
+	congested.Congestion = NetCongestion{}
+
+	capacity := int(quota.CongestionCapacity / quota.CongestionWindow)
+	report, err := Simulate(newTrace(capacity), congested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CongestedBlocks != 0 || report.PledgeBlocks != report.Blocks {
+		t.Fatalf("the net within its capacity is not congested, report %+v", report)
+	}
+	report, err = Simulate(newTrace(capacity*3), congested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CongestedBlocks == 0 || report.CongestedBlocks == report.Blocks {
+		t.Fatalf("the net beyond its capacity is congested once the window fills, report %+v", report)
+	}
+
+	if NetCongestion.Scale(NetCongestion{}, quota.CongestionCapacity) != 1 ||
+		NetCongestion.Scale(NetCongestion{}, quota.CongestionCapacity*4) != 0.5 {
+		t.Fatal("unexpected scale of the net congestion")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	records := newTestTrace()
+	var buf bytes.Buffer
+	if err := WriteTrace(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(records) {
+		t.Fatalf("read %v records, expected %v", len(read), len(records))
+	}
+	for i, record := range read {
+		if record.Address != records[i].Address || record.SnapshotHeight != records[i].SnapshotHeight ||
+			record.PledgeAmount.Cmp(records[i].PledgeAmount) != 0 || record.PoW != records[i].PoW || record.Contract != records[i].Contract {
+			t.Fatalf("unexpected record %+v, expected %+v", record, records[i])
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package simulation
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+// exportBatchSize is the count of the snapshot blocks whose account blocks are read from the chain at a time
+const exportBatchSize = 1000
+
+// Record is an account block of a trace with what its quota depends on.
+type Record struct {
+	Address types.Address `json:"address"`
+	Height  uint64        `json:"height"`
+	// SnapshotHeight is the height of the snapshot block the account block refers to
+	SnapshotHeight uint64 `json:"snapshotHeight"`
+	Quota          uint64 `json:"quota"`
+	// PledgeAmount is the pledge of the address at the snapshot block
+	PledgeAmount *big.Int `json:"pledgeAmount"`
+	// PoW is whether the account block calculated a PoW on the chain
+	PoW      bool `json:"pow"`
+	Contract bool `json:"contract"`
+}
+
+// ReadTrace reads the records written by WriteTrace, one json object a line.
+func ReadTrace(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		record := &Record{}
+		if err := decoder.Decode(record); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if record.PledgeAmount == nil {
+			record.PledgeAmount = big.NewInt(0)
+		}
+		records = append(records, record)
+	}
+}
+
+func WriteTrace(w io.Writer, records []*Record) error {
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportTrace writes the records of the account blocks confirmed by the snapshot blocks from fromHeight to
+// toHeight of c to w, in the order of the snapshot blocks they refer to. The pledge amounts are read from the
+// state of the snapshot blocks, so the state of the range must not be garbage collected.
+func ExportTrace(c chain.Chain, fromHeight, toHeight uint64, w io.Writer) error {
+	exporter := &traceExporter{
+		chain:          c,
+		snapshotHeight: make(map[types.Hash]uint64),
+		contract:       make(map[types.Address]bool),
+	}
+	for from := fromHeight; from <= toHeight; from += exportBatchSize {
+		to := from + exportBatchSize - 1
+		if to > toHeight || to < from {
+			to = toHeight
+		}
+		records, err := exporter.export(from, to)
+		if err != nil {
+			return err
+		}
+		if err := WriteTrace(w, records); err != nil {
+			return err
+		}
+		if to == toHeight {
+			break
+		}
+	}
+	return nil
+}
+
+type traceExporter struct {
+	chain          chain.Chain
+	snapshotHeight map[types.Hash]uint64
+	pledgeAmount   map[types.Hash]map[types.Address]*big.Int
+	contract       map[types.Address]bool
+}
+
+func (e *traceExporter) export(fromHeight, toHeight uint64) ([]*Record, error) {
+	_, subLedger, err := e.chain.GetConfirmSubLedger(fromHeight, toHeight)
+	if err != nil {
+		return nil, err
+	}
+	// the account blocks of a batch mostly refer to the snapshot blocks of the batch
+	e.pledgeAmount = make(map[types.Hash]map[types.Address]*big.Int)
+
+	var records []*Record
+	for addr, blocks := range subLedger {
+		isContract, err := e.isContract(addr)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			snapshotHeight, err := e.getSnapshotHeight(block.SnapshotHash)
+			if err != nil {
+				return nil, err
+			}
+			pledgeAmount, err := e.getPledgeAmount(block.SnapshotHash, addr)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, &Record{
+				Address:        addr,
+				Height:         block.Height,
+				SnapshotHeight: snapshotHeight,
+				Quota:          block.Quota,
+				PledgeAmount:   pledgeAmount,
+				PoW:            quota.IsPoW(block.Nonce),
+				Contract:       isContract,
+			})
+		}
+	}
+	sortRecords(records)
+	return records, nil
+}
+
+func (e *traceExporter) isContract(addr types.Address) (bool, error) {
+	if isContract, ok := e.contract[addr]; ok {
+		return isContract, nil
+	}
+	accountType, err := e.chain.AccountType(&addr)
+	if err != nil {
+		return false, err
+	}
+	isContract := accountType == ledger.AccountTypeContract || types.IsPrecompiledContractAddress(addr)
+	e.contract[addr] = isContract
+	return isContract, nil
+}
+
+func (e *traceExporter) getSnapshotHeight(hash types.Hash) (uint64, error) {
+	if height, ok := e.snapshotHeight[hash]; ok {
+		return height, nil
+	}
+	block, err := e.chain.GetSnapshotBlockByHash(&hash)
+	if err != nil {
+		return 0, err
+	}
+	if block == nil {
+		return 0, errSnapshotBlockMissing
+	}
+	e.snapshotHeight[hash] = block.Height
+	return block.Height, nil
+}
+
+func (e *traceExporter) getPledgeAmount(snapshotHash types.Hash, addr types.Address) (*big.Int, error) {
+	amounts, ok := e.pledgeAmount[snapshotHash]
+	if !ok {
+		amounts = make(map[types.Address]*big.Int)
+		e.pledgeAmount[snapshotHash] = amounts
+	}
+	if amount, ok := amounts[addr]; ok {
+		return amount, nil
+	}
+	db, err := vm_context.NewVmContext(e.chain, &snapshotHash, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	amount := abi.GetPledgeBeneficialAmount(db, addr)
+	amounts[addr] = amount
+	return amount, nil
+}
+
+// sortRecords sorts records by the snapshot blocks they refer to, then by address and height.
+func sortRecords(records []*Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].SnapshotHeight != records[j].SnapshotHeight {
+			return records[i].SnapshotHeight < records[j].SnapshotHeight
+		}
+		if cmp := bytes.Compare(records[i].Address.Bytes(), records[j].Address.Bytes()); cmp != 0 {
+			return cmp < 0
+		}
+		return records[i].Height < records[j].Height
+	})
+}
@@ -0,0 +1,57 @@
+package vm
+
+import (
+	"encoding/hex"
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+// Tracer records the steps of the interpreter and the storage written by SSTORE while a vm runs with it,
+// for investigating an execution afterwards.
+type Tracer struct {
+	Steps   []*TraceStep    `json:"steps"`
+	Storage []*TraceStorage `json:"storage"`
+}
+
+// TraceStep is an opcode executed, QuotaLeft is the quota left before QuotaCost is used, and Err is the
+// error the step fails with.
+type TraceStep struct {
+	Addr      types.Address `json:"addr"`
+	Pc        uint64        `json:"pc"`
+	Op        string        `json:"op"`
+	QuotaCost uint64        `json:"quotaCost"`
+	QuotaLeft uint64        `json:"quotaLeft"`
+	Stack     []string      `json:"stack"`
+	Err       string        `json:"err,omitempty"`
+}
+
+// TraceStorage is a value written to the storage of Addr, a write undone by a failed execution is kept.
+type TraceStorage struct {
+	Addr  types.Address `json:"addr"`
+	Key   string        `json:"key"`
+	Value string        `json:"value"`
+}
+
+func NewTracer() *Tracer {
+	return &Tracer{}
+}
+
+func (t *Tracer) captureStep(c *contract, pc uint64, op opCode, cost uint64, st *stack) *TraceStep {
+	step := &TraceStep{
+		Addr:      c.block.AccountAddress,
+		Pc:        pc,
+		Op:        opCodeToString[op],
+		QuotaCost: cost,
+		QuotaLeft: c.quotaLeft,
+		Stack:     make([]string, len(st.data)),
+	}
+	for i, val := range st.data {
+		step.Stack[i] = val.Text(16)
+	}
+	t.Steps = append(t.Steps, step)
+	if op == SSTORE {
+		loc, _ := types.BigToHash(st.peek())
+		val, _ := types.BigToHash(st.back(1))
+		t.Storage = append(t.Storage, &TraceStorage{c.block.AccountAddress, hex.EncodeToString(loc.Bytes()), hex.EncodeToString(val.Bytes())})
+	}
+	return step
+}
@@ -0,0 +1,43 @@
+package vm
+
+import (
+	"encoding/hex"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/util"
+	"testing"
+	"time"
+)
+
+func TestTracer(t *testing.T) {
+	sbTime := time.Now()
+	sb := ledger.SnapshotBlock{Height: 1, Timestamp: &sbTime, Hash: types.DataHash([]byte{1, 1})}
+	addr, _, _ := types.CreateAddress()
+	db := NewMemoryDatabase(addr, &sb)
+	// sstore 1 at 2
+	code, _ := hex.DecodeString("6001600255")
+	db.SetContractCode(util.PackContractCode(util.SolidityPPContractType, code))
+
+	tracer := NewTracer()
+	vm := NewVM()
+	vm.SetTracer(tracer)
+	if _, err := vm.OffChainCall(db, nil); err != nil {
+		t.Fatal(err)
+	}
+	ops := []string{"PUSH1", "PUSH1", "SSTORE", "STOP"}
+	if len(tracer.Steps) != len(ops) {
+		t.Fatalf("unexpected steps %v", len(tracer.Steps))
+	}
+	for i, step := range tracer.Steps {
+		if step.Op != ops[i] || step.Addr != addr {
+			t.Fatalf("unexpected step %v, %v", i, step)
+		}
+	}
+	if sstore := tracer.Steps[2]; sstore.Pc != 4 || len(sstore.Stack) != 2 || sstore.Stack[0] != "1" || sstore.Stack[1] != "2" {
+		t.Fatalf("unexpected sstore step %v", sstore)
+	}
+	if len(tracer.Storage) != 1 || tracer.Storage[0].Key != hex.EncodeToString(types.Hash{31: 2}.Bytes()) ||
+		tracer.Storage[0].Value != hex.EncodeToString(types.Hash{31: 1}.Bytes()) {
+		t.Fatalf("unexpected storage %v", tracer.Storage)
+	}
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"bytes"
 	"encoding/hex"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
@@ -41,7 +42,9 @@ func MakeSendBlock(block *ledger.AccountBlock, toAddress types.Address, blockTyp
 
 var (
 	SolidityPPContractType = []byte{1}
-	contractTypeSize       = 1
+	// DestroyedContractType is the type of a contract destroyed by SELFDESTRUCT, whose code is deleted
+	DestroyedContractType = []byte{0}
+	contractTypeSize      = 1
 )
 
 func GetCreateContractData(bytecode []byte, contractType []byte, gid types.Gid) []byte {
@@ -66,12 +69,19 @@ func IsExistContractType(contractType []byte) bool {
 	return false
 }
 
+// IsContractDestroyed returns whether the contract addr is destroyed, the sends to it are refunded
+func IsContractDestroyed(db CommonDb, addr *types.Address) bool {
+	contractType, _ := GetContractCode(db, addr)
+	return bytes.Equal(contractType, DestroyedContractType)
+}
+
 func PackContractCode(contractType, code []byte) []byte {
 	return helper.JoinBytes(contractType, code)
 }
 
 type CommonDb interface {
 	GetContractCode(addr *types.Address) []byte
+	CurrentSnapshotBlock() *ledger.SnapshotBlock
 }
 
 func GetContractCode(db CommonDb, addr *types.Address) ([]byte, []byte) {
@@ -90,6 +100,19 @@ func NewContractAddress(accountAddress types.Address, accountBlockHeight uint64,
 		snapshotHash.Bytes())
 }
 
+// deterministicAddressPrefix keeps the addresses derived from a salt apart from the ones by NewContractAddress
+var deterministicAddressPrefix = []byte{0xff}
+
+// NewContractAddressBySalt returns the address of a contract created with a salt, it depends only on the creator,
+// the salt and the hash of the code so that it is known before the send create block.
+func NewContractAddressBySalt(accountAddress types.Address, salt types.Hash, codeHash types.Hash) types.Address {
+	return types.CreateContractAddress(
+		deterministicAddressPrefix,
+		accountAddress.Bytes(),
+		salt.Bytes(),
+		codeHash.Bytes())
+}
+
 func PrintMap(m map[string][]byte) string {
 	var result string
 	if len(m) > 0 {
@@ -107,7 +130,7 @@ func PrintMap(m map[string][]byte) string {
 }
 
 func IsUserAccount(db CommonDb, addr types.Address) bool {
-	if types.IsPrecompiledContractAddress(addr) {
+	if fork.IsPrecompiledContractAddress(addr, db.CurrentSnapshotBlock().Height) {
 		return false
 	}
 	_, code := GetContractCode(db, &addr)
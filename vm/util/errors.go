@@ -15,6 +15,9 @@ var (
 	ErrCalcPoWTwice                = errors.New("calc PoW twice referring to one snapshot block")
 	ErrAbiMethodNotFound           = errors.New("abi: method not found")
 	ErrDepth                       = errors.New("max call depth exceeded")
+	ErrContractNotExists           = errors.New("contract not exists")
+	ErrContractDestroyed           = errors.New("contract destroyed")
+	ErrInvalidBeneficiary          = errors.New("invalid beneficiary")
 
 	ErrForked                     = errors.New("chain forked")
 	ErrCalcPoWLimitReached        = errors.New("can not calc PoW in this block")
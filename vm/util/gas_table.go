@@ -0,0 +1,153 @@
+package util
+
+import "github.com/vitelabs/go-vite/common/helper"
+
+// GasTable is a version of the quota costs. The version a block uses is selected by the height of the snapshot
+// block it refers to, so a change of the costs is scheduled by a fork point, and the blocks before the fork are
+// still run with the costs they were produced with.
+type GasTable struct {
+	TxContractCreationGas  uint64 // Per transaction that creates a contract.
+	TxDataZeroGas          uint64 // Per byte of data attached to a transaction that equals zero.
+	TxDataNonZeroGas       uint64 // Per byte of data attached to a transaction that is not equal to zero.
+	QuotaForCreateContract uint64 // Quota limit for create contract.
+
+	SLoadGas        uint64
+	BalanceGas      uint64
+	ExtCodeSizeGas  uint64
+	ExtCodeCopyGas  uint64
+	ExpByteGas      uint64
+	Blake2bGas      uint64 // Once per Blake2b operation.
+	Blake2bWordGas  uint64 // Once per word of the Blake2b operation's data.
+	LogGas          uint64 // Per LOG* operation.
+	LogTopicGas     uint64 // Multiplied by the * of the LOG*, per LOG transaction.
+	LogDataGas      uint64 // Per byte in a LOG* operation's data.
+	CallGas         uint64 // Once per DELEGATECALL operation & message call transaction.
+	SelfdestructGas uint64 // Once per SELFDESTRUCT operation
+
+	SelfdestructKeyGas uint64 // Per storage key walked by a SELFDESTRUCT operation.
+
+	SstoreSetGas    uint64 // Once per SSTORE operation before the Mint fork.
+	SstoreResetGas  uint64 // Once per SSTORE operation if the zeroness changes from zero before the Mint fork.
+	SstoreClearGas  uint64 // Once per SSTORE operation if the zeroness doesn't change before the Mint fork.
+	SstoreRefundGas uint64 // Once per SSTORE operation if the zeroness changes to zero before the Mint fork.
+
+	SstoreNoopGas             uint64 // Once per SSTORE operation if the value doesn't change.
+	SstoreInitGas             uint64 // Once per SSTORE operation from clean zero.
+	SstoreCleanGas            uint64 // Once per SSTORE operation from clean non-zero.
+	SstoreDirtyGas            uint64 // Once per SSTORE operation from dirty.
+	SstoreClearRefundGas      uint64 // Once per SSTORE operation for clearing an originally existing storage slot.
+	SstoreResetClearRefundGas uint64 // Once per SSTORE operation for resetting to the original zero value.
+	SstoreResetRefundGas      uint64 // Once per SSTORE operation for resetting to the original non-zero value.
+
+	JumpdestGas     uint64 // Jumpdest gas cost.
+	ContractCodeGas uint64 // Per byte in contract code.
+	CopyGas         uint64 // Per word of the data copied.
+	MemoryGas       uint64 // Times the address of the (highest referenced byte in memory + 1).
+	QuadCoeffDiv    uint64 // Divisor for the quadratic particle of the memory cost equation.
+}
+
+// InitialGasTable is the quota costs before any fork of them
+var InitialGasTable = GasTable{
+	TxContractCreationGas:  53000,
+	TxDataZeroGas:          4,
+	TxDataNonZeroGas:       68,
+	QuotaForCreateContract: 1000000,
+
+	SLoadGas:        200,
+	BalanceGas:      400,
+	ExtCodeSizeGas:  700,
+	ExtCodeCopyGas:  700,
+	ExpByteGas:      50,
+	Blake2bGas:      30,
+	Blake2bWordGas:  6,
+	LogGas:          375,
+	LogTopicGas:     375,
+	LogDataGas:      8,
+	CallGas:         700,
+	SelfdestructGas: 5000,
+
+	SelfdestructKeyGas: 200,
+
+	SstoreSetGas:    20000,
+	SstoreResetGas:  5000,
+	SstoreClearGas:  5000,
+	SstoreRefundGas: 15000,
+
+	SstoreNoopGas:             200,
+	SstoreInitGas:             20000,
+	SstoreCleanGas:            5000,
+	SstoreDirtyGas:            200,
+	SstoreClearRefundGas:      15000,
+	SstoreResetClearRefundGas: 19800,
+	SstoreResetRefundGas:      4800,
+
+	JumpdestGas:     1,
+	ContractCodeGas: 200,
+	CopyGas:         3,
+	MemoryGas:       3,
+	QuadCoeffDiv:    512,
+}
+
+type gasTableFork struct {
+	isFork   func(blockHeight uint64) bool
+	gasTable *GasTable
+}
+
+// gasTableForkList is the versions of the quota costs after InitialGasTable, from the earliest fork to the latest.
+// A version is added with the fork.IsXxxFork function of the fork point it takes effect at.
+var gasTableForkList []gasTableFork
+
+// GasTableByHeight returns the quota costs at the snapshot block height
+func GasTableByHeight(blockHeight uint64) *GasTable {
+	for i := len(gasTableForkList) - 1; i >= 0; i-- {
+		if gasTableForkList[i].isFork(blockHeight) {
+			return gasTableForkList[i].gasTable
+		}
+	}
+	return &InitialGasTable
+}
+
+func (t *GasTable) UseQuotaForData(data []byte, quotaLeft uint64) (uint64, error) {
+	cost, err := t.DataGasCost(data)
+	if err != nil {
+		return 0, err
+	}
+	return UseQuota(quotaLeft, cost)
+}
+
+func (t *GasTable) IntrinsicGasCost(data []byte, isCreate bool) (uint64, error) {
+	var gas uint64
+	if isCreate {
+		gas = t.TxContractCreationGas
+	} else {
+		gas = TxGas
+	}
+	gasData, err := t.DataGasCost(data)
+	if err != nil || helper.MaxUint64-gas < gasData {
+		return 0, errGasUintOverflow
+	}
+	return gas + gasData, nil
+}
+
+func (t *GasTable) DataGasCost(data []byte) (uint64, error) {
+	var gas uint64
+	if len(data) > 0 {
+		var nonZeroByteCount uint64
+		for _, byteCode := range data {
+			if byteCode != 0 {
+				nonZeroByteCount++
+			}
+		}
+		if helper.MaxUint64/t.TxDataNonZeroGas < nonZeroByteCount {
+			return 0, errGasUintOverflow
+		}
+		gas = nonZeroByteCount * t.TxDataNonZeroGas
+
+		zeroByteCount := uint64(len(data)) - nonZeroByteCount
+		if (helper.MaxUint64-gas)/t.TxDataZeroGas < zeroByteCount {
+			return 0, errGasUintOverflow
+		}
+		gas += zeroByteCount * t.TxDataZeroGas
+	}
+	return gas, nil
+}
@@ -0,0 +1,27 @@
+package util
+
+import "testing"
+
+func TestGasTableByHeight(t *testing.T) {
+	if table := GasTableByHeight(100); table != &InitialGasTable {
+		t.Fatalf("unexpected gas table %v", table)
+	}
+	if cost, err := GasTableByHeight(100).IntrinsicGasCost([]byte{0, 1}, false); err != nil || cost != TxGas+4+68 {
+		t.Fatalf("unexpected intrinsic cost %v, %v", cost, err)
+	}
+
+	next := InitialGasTable
+	next.TxDataNonZeroGas = 16
+	next.SstoreInitGas = 22100
+	gasTableForkList = []gasTableFork{{func(blockHeight uint64) bool { return blockHeight >= 100 }, &next}}
+	defer func() { gasTableForkList = nil }()
+	if table := GasTableByHeight(99); table != &InitialGasTable {
+		t.Fatalf("the gas table before the fork should be the initial one, got %v", table)
+	}
+	if cost, err := GasTableByHeight(100).IntrinsicGasCost([]byte{0, 1}, false); err != nil || cost != TxGas+4+16 {
+		t.Fatalf("unexpected intrinsic cost after the fork %v, %v", cost, err)
+	}
+	if GasTableByHeight(99).SstoreInitGas != 20000 || GasTableByHeight(100).SstoreInitGas != 22100 {
+		t.Fatal("the sstore cost should switch at the fork height")
+	}
+}
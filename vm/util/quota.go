@@ -6,17 +6,17 @@ import (
 )
 
 var (
-	ErrOutOfQuota      = errors.New("out of quota")
-	errGasUintOverflow = errors.New("gas uint64 overflow")
+	ErrOutOfQuota        = errors.New("out of quota")
+	ErrReceiveQuotaLimit = errors.New("quota limit of built-in contract receive exceeded")
+	errGasUintOverflow   = errors.New("gas uint64 overflow")
 )
 
 const (
-	txDataZeroGas               uint64 = 4     // Per byte of data attached to a transaction that equals zero.
-	txDataNonZeroGas            uint64 = 68    // Per byte of data attached to a transaction that is not equal to zero.
 	TxGas                       uint64 = 21000 // Per transaction not creating a contract.
 	PrecompiledContractsSendGas uint64 = 21068
 	RefundGas                   uint64 = 21000
-	txContractCreationGas       uint64 = 53000 // Per transaction that creates a contract.
+
+	PrecompiledContractsReceiveQuotaLimit uint64 = 1000000 // Max quota a built-in contract method uses at receive.
 )
 
 func UseQuota(quotaLeft, cost uint64) (uint64, error) {
@@ -27,49 +27,35 @@ func UseQuota(quotaLeft, cost uint64) (uint64, error) {
 	return quotaLeft, nil
 }
 
-func UseQuotaForData(data []byte, quotaLeft uint64) (uint64, error) {
-	cost, err := DataGasCost(data)
-	if err != nil {
-		return 0, err
-	}
-	return UseQuota(quotaLeft, cost)
+// QuotaMeter meters the quota a built-in contract method uses at receive in proportion to the work it does,
+// e.g. the days of reward it settles, which is recorded as the quota of the receive block. A nil QuotaMeter
+// meters nothing.
+type QuotaMeter struct {
+	limit uint64
+	used  uint64
 }
 
-func IntrinsicGasCost(data []byte, isCreate bool) (uint64, error) {
-	var gas uint64
-	if isCreate {
-		gas = txContractCreationGas
-	} else {
-		gas = TxGas
+func NewQuotaMeter(limit uint64) *QuotaMeter {
+	return &QuotaMeter{limit: limit}
+}
+
+// Use uses cost quota for each of n units of work, nothing is used if the limit would be exceeded.
+func (m *QuotaMeter) Use(cost, n uint64) error {
+	if m == nil || cost == 0 || n == 0 {
+		return nil
 	}
-	gasData, err := DataGasCost(data)
-	if err != nil || helper.MaxUint64-gas < gasData {
-		return 0, errGasUintOverflow
+	if n > (m.limit-m.used)/cost {
+		return ErrReceiveQuotaLimit
 	}
-	return gas + gasData, nil
+	m.used += cost * n
+	return nil
 }
 
-func DataGasCost(data []byte) (uint64, error) {
-	var gas uint64
-	if len(data) > 0 {
-		var nonZeroByteCount uint64
-		for _, byteCode := range data {
-			if byteCode != 0 {
-				nonZeroByteCount++
-			}
-		}
-		if helper.MaxUint64/txDataNonZeroGas < nonZeroByteCount {
-			return 0, errGasUintOverflow
-		}
-		gas = nonZeroByteCount * txDataNonZeroGas
-
-		zeroByteCount := uint64(len(data)) - nonZeroByteCount
-		if (helper.MaxUint64-gas)/txDataZeroGas < zeroByteCount {
-			return 0, errGasUintOverflow
-		}
-		gas += zeroByteCount * txDataZeroGas
+func (m *QuotaMeter) Used() uint64 {
+	if m == nil {
+		return 0
 	}
-	return gas, nil
+	return m.used
 }
 
 func CalcQuotaUsed(quotaTotal, quotaAddition, quotaLeft, quotaRefund uint64, err error) uint64 {
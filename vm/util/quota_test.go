@@ -51,3 +51,24 @@ func TestUseQuota(t *testing.T) {
 		}
 	}
 }
+
+func TestQuotaMeter(t *testing.T) {
+	m := NewQuotaMeter(100)
+	if err := m.Use(10, 3); err != nil || m.Used() != 30 {
+		t.Fatalf("use quota fail, expected [30, nil], got [%v, %v]", m.Used(), err)
+	}
+	if err := m.Use(10, 8); err != ErrReceiveQuotaLimit || m.Used() != 30 {
+		t.Fatalf("use quota over limit, expected [30, %v], got [%v, %v]", ErrReceiveQuotaLimit, m.Used(), err)
+	}
+	if err := m.Use(10, 7); err != nil || m.Used() != 100 {
+		t.Fatalf("use quota to limit fail, expected [100, nil], got [%v, %v]", m.Used(), err)
+	}
+	if err := m.Use(1<<63, 1<<63); err != ErrReceiveQuotaLimit {
+		t.Fatalf("use overflowed quota, expected %v, got %v", ErrReceiveQuotaLimit, err)
+	}
+
+	var nilMeter *QuotaMeter
+	if err := nilMeter.Use(10, 1000); err != nil || nilMeter.Used() != 0 {
+		t.Fatalf("nil meter should meter nothing, got [%v, %v]", nilMeter.Used(), err)
+	}
+}
@@ -4,6 +4,7 @@ Package vm implements the vite virtual machine
 package vm
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"github.com/vitelabs/go-vite/common"
@@ -16,6 +17,7 @@ import (
 	"strconv"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
@@ -112,11 +114,18 @@ type VM struct {
 	VMConfig
 	abort int32
 	VmContext
-	i *Interpreter
+	i        *Interpreter
+	gasTable *util.GasTable
+	tracer   *Tracer
 }
 
 func NewVM() *VM {
-	return &VM{}
+	return &VM{gasTable: &util.InitialGasTable}
+}
+
+// SetTracer records the execution of the blocks run by vm in tracer, no execution is recorded if tracer is nil
+func (vm *VM) SetTracer(tracer *Tracer) {
+	vm.tracer = tracer
 }
 
 func printDebugBlockInfo(block *ledger.AccountBlock, blockList []*vm_context.VmAccountBlock, err error) {
@@ -171,6 +180,7 @@ func (vm *VM) Run(database vmctxt_interface.VmDatabase, block *ledger.AccountBlo
 	}
 	blockContext := &vm_context.VmAccountBlock{block.Copy(), database}
 	vm.i = NewInterpreter(database.CurrentSnapshotBlock().Height, false)
+	vm.gasTable = util.GasTableByHeight(database.CurrentSnapshotBlock().Height)
 	switch block.BlockType {
 	case ledger.BlockTypeReceive, ledger.BlockTypeReceiveError:
 		blockContext.AccountBlock.Data = nil
@@ -178,7 +188,7 @@ func (vm *VM) Run(database vmctxt_interface.VmDatabase, block *ledger.AccountBlo
 			if !fork.IsSmartFork(database.CurrentSnapshotBlock().Height) {
 				return nil, NoRetry, errors.New("snapshot height not supported")
 			}
-			return vm.receiveCreate(blockContext, sendBlock, quota.CalcCreateQuota(sendBlock.Fee))
+			return vm.receiveCreate(blockContext, sendBlock, quota.CalcCreateQuota(vm.gasTable, sendBlock.Fee))
 		} else if sendBlock.BlockType == ledger.BlockTypeSendCall || sendBlock.BlockType == ledger.BlockTypeSendReward {
 			return vm.receiveCall(blockContext, sendBlock)
 		} else if sendBlock.BlockType == ledger.BlockTypeSendRefund {
@@ -237,7 +247,7 @@ func (vm *VM) sendCreate(block *vm_context.VmAccountBlock, quotaTotal, quotaAddi
 	// check can make transaction
 	quotaLeft := quotaTotal
 	quotaRefund := uint64(0)
-	cost, err := util.IntrinsicGasCost(block.AccountBlock.Data, false)
+	cost, err := vm.gasTable.IntrinsicGasCost(block.AccountBlock.Data, false)
 	if err != nil {
 		return nil, err
 	}
@@ -268,11 +278,22 @@ func (vm *VM) sendCreate(block *vm_context.VmAccountBlock, quotaTotal, quotaAddi
 		return nil, util.ErrInsufficientBalance
 	}
 
-	contractAddr := util.NewContractAddress(
-		block.AccountBlock.AccountAddress,
-		block.AccountBlock.Height,
-		block.AccountBlock.PrevHash,
-		block.AccountBlock.SnapshotHash)
+	var contractAddr types.Address
+	if block.AccountBlock.Salt != nil {
+		if !fork.IsDeterministicAddressFork(block.VmContext.CurrentSnapshotBlock().Height) {
+			return nil, util.ErrVersionNotSupport
+		}
+		contractAddr = util.NewContractAddressBySalt(
+			block.AccountBlock.AccountAddress,
+			*block.AccountBlock.Salt,
+			types.DataHash(util.GetCodeFromCreateContractData(block.AccountBlock.Data)))
+	} else {
+		contractAddr = util.NewContractAddress(
+			block.AccountBlock.AccountAddress,
+			block.AccountBlock.Height,
+			block.AccountBlock.PrevHash,
+			block.AccountBlock.SnapshotHash)
+	}
 	if block.VmContext.IsAddressExisted(&contractAddr) {
 		return nil, util.ErrContractAddressCreationFail
 	}
@@ -298,7 +319,7 @@ func (vm *VM) receiveCreate(block *vm_context.VmAccountBlock, sendBlock *ledger.
 		return nil, NoRetry, util.ErrAddressCollision
 	}
 	// check can make transaction
-	cost, err := util.IntrinsicGasCost(nil, true)
+	cost, err := vm.gasTable.IntrinsicGasCost(nil, true)
 	if err != nil {
 		return nil, NoRetry, err
 	}
@@ -319,7 +340,7 @@ func (vm *VM) receiveCreate(block *vm_context.VmAccountBlock, sendBlock *ledger.
 	code, err := c.run(vm)
 	if err == nil && len(code) <= MaxCodeSize {
 		code := util.PackContractCode(util.GetContractTypeFromCreateContractData(sendBlock.Data), code)
-		codeCost := uint64(len(code)) * contractCodeGas
+		codeCost := uint64(len(code)) * vm.gasTable.ContractCodeGas
 		c.quotaLeft, err = util.UseQuota(c.quotaLeft, codeCost)
 		if err == nil {
 			block.VmContext.SetContractCode(code)
@@ -347,7 +368,7 @@ func (vm *VM) sendCall(block *vm_context.VmAccountBlock, quotaTotal, quotaAdditi
 
 	// check can make transaction
 	quotaLeft := quotaTotal
-	if p, ok, err := GetPrecompiledContract(block.AccountBlock.ToAddress, block.AccountBlock.Data); ok {
+	if p, ok, err := GetPrecompiledContract(block.AccountBlock.ToAddress, block.AccountBlock.Data, block.VmContext.CurrentSnapshotBlock().Height); ok {
 		if err != nil {
 			return nil, err
 		}
@@ -366,7 +387,7 @@ func (vm *VM) sendCall(block *vm_context.VmAccountBlock, quotaTotal, quotaAdditi
 		block.VmContext.SubBalance(&ledger.ViteTokenId, block.AccountBlock.Fee)
 	} else {
 		block.AccountBlock.Fee = helper.Big0
-		cost, err := util.IntrinsicGasCost(block.AccountBlock.Data, false)
+		cost, err := vm.gasTable.IntrinsicGasCost(block.AccountBlock.Data, false)
 		if err != nil {
 			return nil, err
 		}
@@ -390,14 +411,61 @@ var (
 	ResultDepthErr = byte(2)
 )
 
+// RevertReasonLengthMax is the max length of the revert reason kept in a failed receive block
+const RevertReasonLengthMax = 256
+
+// revertSelector is the selector of Error(string), which a contract reverts with by revert(reason)
+var revertSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
 func getReceiveCallData(db vmctxt_interface.VmDatabase, err error) []byte {
+	return getReceiveCallDataWithReason(db, err, nil)
+}
+
+// getReceiveCallDataWithReason appends the reason of a failed receive after the result since the revert reason
+// fork, the reason is the data the contract reverts with, or the error message if it does not revert
+func getReceiveCallDataWithReason(db vmctxt_interface.VmDatabase, err error, revertData []byte) []byte {
 	if err == nil {
 		return append(db.GetStorageHash().Bytes(), ResultSuccess)
 	} else if err == util.ErrDepth {
 		return append(db.GetStorageHash().Bytes(), ResultDepthErr)
 	} else {
-		return append(db.GetStorageHash().Bytes(), ResultFail)
+		data := append(db.GetStorageHash().Bytes(), ResultFail)
+		if !fork.IsRevertReasonFork(db.CurrentSnapshotBlock().Height) {
+			return data
+		}
+		reason := []byte(err.Error())
+		if err == util.ErrExecutionReverted && len(revertData) > 0 {
+			reason = revertData
+		}
+		if len(reason) > RevertReasonLengthMax {
+			reason = reason[:RevertReasonLengthMax]
+		}
+		return append(data, reason...)
+	}
+}
+
+// GetRevertReason returns the reason kept in the data of a failed receive block, the message of Error(string) is
+// decoded, and ok is false if the data keeps no reason.
+func GetRevertReason(data []byte) (reason string, ok bool) {
+	if len(data) <= types.HashSize+1 || data[types.HashSize] != ResultFail {
+		return "", false
+	}
+	raw := data[types.HashSize+1:]
+	if bytes.HasPrefix(raw, revertSelector) && len(raw) >= len(revertSelector)+2*helper.WordSize {
+		args := raw[len(revertSelector):]
+		offset := new(big.Int).SetBytes(args[:helper.WordSize])
+		if offset.IsUint64() && offset.Uint64()+helper.WordSize <= uint64(len(args)) {
+			start := offset.Uint64() + helper.WordSize
+			length := new(big.Int).SetBytes(args[offset.Uint64():start])
+			if length.IsUint64() && length.Uint64() <= uint64(len(args))-start {
+				return string(args[start : start+length.Uint64()]), true
+			}
+		}
+	}
+	if utf8.Valid(raw) {
+		return string(raw), true
 	}
+	return hex.EncodeToString(raw), true
 }
 
 func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.AccountBlock) (blockList []*vm_context.VmAccountBlock, isRetry bool, err error) {
@@ -414,13 +482,17 @@ func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.Ac
 		vm.updateBlock(block, util.ErrDepth, 0)
 		return vm.blockList, NoRetry, util.ErrDepth
 	}
-	if p, ok, _ := GetPrecompiledContract(block.AccountBlock.AccountAddress, sendBlock.Data); ok {
+	if p, ok, _ := GetPrecompiledContract(block.AccountBlock.AccountAddress, sendBlock.Data, block.VmContext.CurrentSnapshotBlock().Height); ok {
 		vm.blockList = []*vm_context.VmAccountBlock{block}
 		block.VmContext.AddBalance(&sendBlock.TokenId, sendBlock.Amount)
-		blockListToSend, err := p.DoReceive(block.VmContext, block.AccountBlock, sendBlock)
+		var meter *util.QuotaMeter
+		if fork.IsReceiveQuotaFork(block.VmContext.CurrentSnapshotBlock().Height) {
+			meter = util.NewQuotaMeter(util.PrecompiledContractsReceiveQuotaLimit)
+		}
+		blockListToSend, err := p.DoReceive(block.VmContext, block.AccountBlock, sendBlock, meter)
 		if err == nil {
 			block.AccountBlock.Data = getReceiveCallData(block.VmContext, err)
-			vm.updateBlock(block, err, 0)
+			vm.updateBlock(block, err, meter.Used())
 			for _, blockToSend := range blockListToSend {
 				vm.VmContext.AppendBlock(
 					&vm_context.VmAccountBlock{
@@ -441,8 +513,8 @@ func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.Ac
 		vm.revert(block)
 		refundFlag := false
 		refundFlag = doRefund(vm, block, sendBlock, p.GetRefundData(), ledger.BlockTypeSendCall)
-		block.AccountBlock.Data = getReceiveCallData(block.VmContext, err)
-		vm.updateBlock(block, err, 0)
+		block.AccountBlock.Data = getReceiveCallDataWithReason(block.VmContext, err, nil)
+		vm.updateBlock(block, err, meter.Used())
 		if refundFlag {
 			if refundErr := vm.doSendBlockList(0, util.PrecompiledContractsSendGas); refundErr == nil {
 				return vm.blockList, NoRetry, err
@@ -465,7 +537,7 @@ func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.Ac
 		}
 		quotaLeft := quotaTotal
 		quotaRefund := uint64(0)
-		cost, err := util.IntrinsicGasCost(nil, false)
+		cost, err := vm.gasTable.IntrinsicGasCost(nil, false)
 		if err != nil {
 			return nil, NoRetry, err
 		}
@@ -477,15 +549,21 @@ func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.Ac
 		// add balance, create account if not exist
 		block.VmContext.AddBalance(&sendBlock.TokenId, sendBlock.Amount)
 		// do transfer transaction if account code size is zero
-		_, code := util.GetContractCode(block.VmContext, &block.AccountBlock.AccountAddress)
-		if len(code) == 0 {
+		contractType, code := util.GetContractCode(block.VmContext, &block.AccountBlock.AccountAddress)
+		destroyed := bytes.Equal(contractType, util.DestroyedContractType)
+		if len(code) == 0 && !destroyed {
 			vm.updateBlock(block, nil, util.CalcQuotaUsed(quotaTotal, quotaAddition, quotaLeft, quotaRefund, nil))
 			return vm.blockList, NoRetry, nil
 		}
-		// run code
+		// run code, refund if the contract is destroyed
 		c := newContract(block.AccountBlock, block.VmContext, sendBlock, sendBlock.Data, quotaLeft, quotaRefund)
 		c.setCallCode(block.AccountBlock.AccountAddress, code)
-		_, err = c.run(vm)
+		var ret []byte
+		if destroyed {
+			err = util.ErrContractDestroyed
+		} else {
+			ret, err = c.run(vm)
+		}
 		if err == nil {
 			block.AccountBlock.Data = getReceiveCallData(block.VmContext, err)
 			vm.updateBlock(block, nil, util.CalcQuotaUsed(quotaTotal, quotaAddition, c.quotaLeft, c.quotaRefund, nil))
@@ -499,7 +577,7 @@ func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.Ac
 
 		if err == util.ErrOutOfQuota {
 			// if ErrOutOfQuota 3 times, refund with no quota
-			block.AccountBlock.Data = getReceiveCallData(block.VmContext, err)
+			block.AccountBlock.Data = getReceiveCallDataWithReason(block.VmContext, err, nil)
 			if receiveBlockHeights, _ := block.VmContext.GetReceiveBlockHeights(&sendBlock.Hash); len(receiveBlockHeights) >= outOfQuotaRetryTime {
 				refundFlag := doRefund(vm, block, sendBlock, []byte{}, ledger.BlockTypeSendRefund)
 				vm.updateBlock(block, nil, util.CalcQuotaUsed(quotaTotal, quotaAddition, c.quotaLeft, c.quotaRefund, err))
@@ -520,7 +598,7 @@ func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.Ac
 		}
 
 		refundFlag := doRefund(vm, block, sendBlock, []byte{}, ledger.BlockTypeSendRefund)
-		block.AccountBlock.Data = getReceiveCallData(block.VmContext, err)
+		block.AccountBlock.Data = getReceiveCallDataWithReason(block.VmContext, err, ret)
 		vm.updateBlock(block, err, util.CalcQuotaUsed(quotaTotal, quotaAddition, c.quotaLeft, c.quotaRefund, err))
 		if refundFlag {
 			if refundErr := vm.doSendBlockList(0, util.RefundGas); refundErr == nil {
@@ -595,7 +673,7 @@ func (vm *VM) sendReward(block *vm_context.VmAccountBlock, quotaTotal, quotaAddi
 
 	// check can make transaction
 	quotaLeft := quotaTotal
-	cost, err := util.IntrinsicGasCost(block.AccountBlock.Data, false)
+	cost, err := vm.gasTable.IntrinsicGasCost(block.AccountBlock.Data, false)
 	if err != nil {
 		return nil, err
 	}
@@ -618,7 +696,7 @@ func (vm *VM) sendRefund(block *vm_context.VmAccountBlock, quotaTotal, quotaAddi
 	defer monitor.LogTimerConsuming(monitorTags, time.Now())
 
 	block.AccountBlock.Fee = helper.Big0
-	cost, err := util.IntrinsicGasCost(block.AccountBlock.Data, false)
+	cost, err := vm.gasTable.IntrinsicGasCost(block.AccountBlock.Data, false)
 	if err != nil {
 		return nil, err
 	}
@@ -653,7 +731,7 @@ func (vm *VM) receiveRefund(block *vm_context.VmAccountBlock, sendBlock *ledger.
 	}
 	quotaLeft := quotaTotal
 	quotaRefund := uint64(0)
-	cost, err := util.IntrinsicGasCost(nil, false)
+	cost, err := vm.gasTable.IntrinsicGasCost(nil, false)
 	if err != nil {
 		return nil, NoRetry, err
 	}
@@ -750,7 +828,7 @@ func checkDepth(db vmctxt_interface.VmDatabase, sendBlock *ledger.AccountBlock)
 		depth = depth + 1
 		prevReceiveBlock := findPrevReceiveBlock(db, prevBlock)
 		prevBlock = db.GetAccountBlockByHash(&prevReceiveBlock.FromBlockHash)
-		if prevBlock == nil && prevReceiveBlock.Height == 1 && types.IsPrecompiledContractAddress(prevReceiveBlock.AccountAddress) {
+		if prevBlock == nil && prevReceiveBlock.Height == 1 && fork.IsPrecompiledContractAddress(prevReceiveBlock.AccountAddress, db.CurrentSnapshotBlock().Height) {
 			// some precompiled contracts' genesis block does not have prevblock
 			return false
 		}
@@ -790,7 +868,18 @@ func (vm *VM) OffChainReader(db vmctxt_interface.VmDatabase, code []byte, data [
 		}
 	}()
 	vm.i = NewInterpreter(db.CurrentSnapshotBlock().Height, true)
+	vm.gasTable = util.GasTableByHeight(db.CurrentSnapshotBlock().Height)
 	c := newContract(&ledger.AccountBlock{AccountAddress: *db.Address()}, db, &ledger.AccountBlock{ToAddress: *db.Address()}, data, offChainReaderGas, 0)
 	c.setCallCode(*db.Address(), code)
 	return c.run(vm)
 }
+
+// OffChainCall runs the deployed code of the contract db.Address() with data read only, which creates no block
+// and consumes no quota, and returns the result of the method called.
+func (vm *VM) OffChainCall(db vmctxt_interface.VmDatabase, data []byte) (result []byte, err error) {
+	_, code := util.GetContractCode(db, db.Address())
+	if len(code) == 0 {
+		return nil, util.ErrContractNotExists
+	}
+	return vm.OffChainReader(db, code, data)
+}
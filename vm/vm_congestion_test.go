@@ -0,0 +1,46 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/quota"
+)
+
+func TestCalcQuotaV2_Congestion(t *testing.T) {
+	quota.InitQuotaConfig(false)
+	addr1, _, _ := types.CreateAddress()
+	db := NewNoDatabase()
+	timestamp := time.Unix(1536214502, 0)
+	content := make(ledger.SnapshotContent)
+	for i := uint64(0); i < quota.CongestionCapacity*2; i++ {
+		content[types.Address{byte(i), byte(i >> 8), byte(i >> 16)}] = &ledger.HashHeight{Height: 1}
+	}
+	snapshot1 := &ledger.SnapshotBlock{Height: 1, Timestamp: &timestamp, Hash: types.DataHash([]byte("congested snapshot block")), SnapshotContent: content}
+	db.snapshotBlockList = append(db.snapshotBlockList, snapshot1)
+	db.storageMap[types.AddressPledge] = make(map[string][]byte)
+	db.addr = addr1
+	maxPledgeAmount := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+
+	quotaTotal, _, err := quota.CalcQuotaV2(db, addr1, maxPledgeAmount, helper.Big0)
+	if quotaTotal != 987000 || err != nil {
+		t.Fatalf("the quota is not scaled before the fork, got %v, %v", quotaTotal, err)
+	}
+
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, CongestionQuota: &config.ForkPoint{Height: 1}})
+	defer initFork()
+	quotaTotal, _, err = quota.CalcQuotaV2(db, addr1, maxPledgeAmount, helper.Big0)
+	if quotaTotal != 987000/2 || err != nil {
+		t.Fatalf("the quota should be scaled down by the congestion, got %v, %v", quotaTotal, err)
+	}
+	quotaTotal, quotaAddition, err := quota.CalcQuotaV2(db, addr1, big.NewInt(0), DefaultDifficulty)
+	if quotaTotal != 21000/2 || quotaAddition != 21000/2 || err != nil {
+		t.Fatalf("the quota of PoW should be scaled down by the congestion, got %v, %v, %v", quotaTotal, quotaAddition, err)
+	}
+}
@@ -0,0 +1,58 @@
+package vm
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+)
+
+func TestGetReceiveCallDataWithReason(t *testing.T) {
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite)
+	db, _, _, _, _, _ := prepareDb(viteTotalSupply)
+
+	// revert("not enough")
+	revertData := append([]byte{}, revertSelector...)
+	revertData = append(revertData, helper.LeftPadBytes(big.NewInt(32).Bytes(), helper.WordSize)...)
+	revertData = append(revertData, helper.LeftPadBytes(big.NewInt(10).Bytes(), helper.WordSize)...)
+	revertData = append(revertData, helper.RightPadBytes([]byte("not enough"), helper.WordSize)...)
+
+	if data := getReceiveCallDataWithReason(db, util.ErrExecutionReverted, revertData); len(data) != types.HashSize+1 {
+		t.Fatalf("reason should not be kept before the fork, got %v", data)
+	}
+
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, RevertReason: &config.ForkPoint{Height: 2}})
+	defer initFork()
+	if data := getReceiveCallDataWithReason(db, nil, nil); !bytes.Equal(data, append(db.GetStorageHash().Bytes(), ResultSuccess)) {
+		t.Fatalf("unexpected data of a successful receive, %v", data)
+	}
+	if _, ok := GetRevertReason(getReceiveCallDataWithReason(db, util.ErrDepth, nil)); ok {
+		t.Fatal("depth error should keep no reason")
+	}
+	tests := []struct {
+		err        error
+		revertData []byte
+		reason     string
+	}{
+		{util.ErrExecutionReverted, revertData, "not enough"},
+		{util.ErrExecutionReverted, []byte("plain"), "plain"},
+		{util.ErrExecutionReverted, nil, util.ErrExecutionReverted.Error()},
+		{util.ErrInsufficientBalance, nil, util.ErrInsufficientBalance.Error()},
+		{util.ErrExecutionReverted, []byte{0xff, 0x00}, "ff00"},
+		{util.ErrExecutionReverted, revertData[:len(revertSelector)+helper.WordSize+4], "08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" + "00000000"},
+	}
+	for _, test := range tests {
+		reason, ok := GetRevertReason(getReceiveCallDataWithReason(db, test.err, test.revertData))
+		if !ok || reason != test.reason {
+			t.Fatalf("unexpected reason %v %v, expected %v", ok, reason, test.reason)
+		}
+	}
+	if data := getReceiveCallDataWithReason(db, util.ErrExecutionReverted, bytes.Repeat([]byte("a"), 2*RevertReasonLengthMax)); len(data) != types.HashSize+1+RevertReasonLengthMax {
+		t.Fatalf("reason should be truncated, got length %v", len(data))
+	}
+}
@@ -0,0 +1,55 @@
+package vm
+
+import (
+	"encoding/hex"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestVmSendCreateBySalt(t *testing.T) {
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite)
+	db, addr1, _, hash12, snapshot, _ := prepareDb(viteTotalSupply)
+	blockTime := time.Now()
+
+	data, _ := hex.DecodeString("0000000000000000000201608060405260858060116000396000f300608060405260043610603e5763ffffffff7c0100000000000000000000000000000000000000000000000000000000600035041663f021ab8f81146043575b600080fd5b604c600435604e565b005b6000805490910190555600a165627a7a72305820b8d8d60a46c6ac6569047b17b012aa1ea458271f9bc8078ef0cff9208999d0900029")
+	salt := types.DataHash([]byte("salt"))
+	newBlock := func() *ledger.AccountBlock {
+		return &ledger.AccountBlock{
+			Height:         3,
+			AccountAddress: addr1,
+			BlockType:      ledger.BlockTypeSendCreate,
+			PrevHash:       hash12,
+			Amount:         big.NewInt(0),
+			Fee:            big.NewInt(0),
+			TokenId:        ledger.ViteTokenId,
+			SnapshotHash:   snapshot.Hash,
+			Data:           data,
+			Timestamp:      &blockTime,
+			Salt:           &salt,
+		}
+	}
+	db.addr = addr1
+	if _, _, err := NewVM().Run(db, newBlock(), nil); err != util.ErrVersionNotSupport {
+		t.Fatalf("salt should not be supported before the fork, got %v", err)
+	}
+
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, DeterministicAddress: &config.ForkPoint{Height: 2}})
+	defer initFork()
+	blockList, _, err := NewVM().Run(db, newBlock(), nil)
+	if err != nil || len(blockList) != 1 {
+		t.Fatalf("send create by salt failed, %v", err)
+	}
+	expected := util.NewContractAddressBySalt(addr1, salt, types.DataHash(util.GetCodeFromCreateContractData(data)))
+	if blockList[0].AccountBlock.ToAddress != expected {
+		t.Fatalf("unexpected contract address %v, expected %v", blockList[0].AccountBlock.ToAddress, expected)
+	}
+	if other := util.NewContractAddressBySalt(addr1, types.DataHash([]byte("other")), types.DataHash(util.GetCodeFromCreateContractData(data))); other == expected {
+		t.Fatal("another salt should derive another address")
+	}
+}
@@ -0,0 +1,191 @@
+package vm
+
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestSelfdestruct(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, Selfdestruct: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite)
+	db, addr1, _, hash12, snapshot, _ := prepareDb(viteTotalSupply)
+	blockTime := time.Now()
+
+	// code2 selfdestructs to addr3
+	addr2, _, _ := types.CreateAddress()
+	addr3, _, _ := types.CreateAddress()
+	db.codeMap[addr2] = append(append([]byte{1, byte(PUSH20)}, addr3.Bytes()...), byte(SELFDESTRUCT))
+	db.storageMap[addr2] = map[string][]byte{string(types.DataHash([]byte("key")).Bytes()): {1}}
+	db.accountBlockMap[addr2] = make(map[types.Hash]*ledger.AccountBlock)
+	db.storageMap[types.AddressPledge][string(abi.GetPledgeBeneficialKey(addr2))], _ = abi.ABIPledge.PackVariable(abi.VariableNamePledgeBeneficial, new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18)))
+
+	sendCall := func(hash, prevHash types.Hash, height uint64) *ledger.AccountBlock {
+		db.addr = addr1
+		block := &ledger.AccountBlock{
+			Height:         height,
+			AccountAddress: addr1,
+			ToAddress:      addr2,
+			BlockType:      ledger.BlockTypeSendCall,
+			PrevHash:       prevHash,
+			Amount:         big.NewInt(10),
+			Fee:            big.NewInt(0),
+			TokenId:        ledger.ViteTokenId,
+			SnapshotHash:   snapshot.Hash,
+			Timestamp:      &blockTime,
+			Hash:           hash,
+		}
+		blockList, _, err := NewVM().Run(db, block, nil)
+		if err != nil || len(blockList) != 1 {
+			t.Fatalf("send call failed, %v", err)
+		}
+		db.accountBlockMap[addr1][hash] = blockList[0].AccountBlock
+		return blockList[0].AccountBlock
+	}
+	receive := func(sendBlock *ledger.AccountBlock, hash types.Hash, height uint64) ([]*ledger.AccountBlock, error) {
+		db.addr = addr2
+		block := &ledger.AccountBlock{
+			Height:         height,
+			AccountAddress: addr2,
+			FromBlockHash:  sendBlock.Hash,
+			BlockType:      ledger.BlockTypeReceive,
+			SnapshotHash:   snapshot.Hash,
+			Timestamp:      &blockTime,
+			Hash:           hash,
+		}
+		blockList, _, err := NewVM().Run(db, block, sendBlock)
+		var result []*ledger.AccountBlock
+		for _, b := range blockList {
+			result = append(result, b.AccountBlock)
+		}
+		if len(result) > 0 {
+			db.accountBlockMap[addr2][hash] = result[0]
+		}
+		return result, err
+	}
+
+	hash13 := types.DataHash([]byte{1, 3})
+	blockList, err := receive(sendCall(hash13, hash12, 3), types.DataHash([]byte{2, 1}), 1)
+	if err != nil || len(blockList) != 2 ||
+		blockList[1].BlockType != ledger.BlockTypeSendCall ||
+		blockList[1].ToAddress != addr3 ||
+		blockList[1].TokenId != ledger.ViteTokenId ||
+		blockList[1].Amount.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("selfdestruct failed, %v, %v", err, blockList)
+	}
+	if balance := db.GetBalance(&addr2, &ledger.ViteTokenId); balance.Sign() != 0 {
+		t.Fatalf("the balance should be sent to the beneficiary, got %v", balance)
+	}
+	if value := db.storageMap[addr2][string(types.DataHash([]byte("key")).Bytes())]; len(value) > 0 {
+		t.Fatalf("the storage should be deleted, got %v", value)
+	}
+	if !util.IsContractDestroyed(db, &addr2) {
+		t.Fatal("the contract should be destroyed")
+	}
+
+	blockList, err = receive(sendCall(types.DataHash([]byte{1, 4}), hash13, 4), types.DataHash([]byte{2, 2}), 2)
+	if err != util.ErrContractDestroyed || len(blockList) != 2 ||
+		blockList[0].Data[types.HashSize] != ResultFail ||
+		blockList[1].BlockType != ledger.BlockTypeSendRefund ||
+		blockList[1].ToAddress != addr1 ||
+		blockList[1].Amount.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("the send to a destroyed contract should be refunded, %v, %v", err, blockList)
+	}
+}
+
+// unsavedCacheDatabase keeps the storage of db.addr in an unsaved cache, as the vm context of a block does
+type unsavedCacheDatabase struct {
+	*testDatabase
+	cache *vm_context.UnsavedCache
+}
+
+func (db *unsavedCacheDatabase) GetStorage(addr *types.Address, key []byte) []byte {
+	if *addr == db.addr {
+		return db.cache.GetStorage(key)
+	}
+	return db.testDatabase.GetStorage(addr, key)
+}
+func (db *unsavedCacheDatabase) SetStorage(key []byte, value []byte) {
+	db.cache.SetStorage(key, value)
+}
+func (db *unsavedCacheDatabase) SetContractCode(code []byte) {
+	db.cache.SetStorage(vm_context.STORAGE_KEY_CODE, code)
+}
+func (db *unsavedCacheDatabase) NewStorageIterator(addr *types.Address, prefix []byte) vmctxt_interface.StorageIterator {
+	if *addr == db.addr {
+		return db.cache.NewStorageIterator(prefix)
+	}
+	return db.testDatabase.NewStorageIterator(addr, prefix)
+}
+func (db *unsavedCacheDatabase) UnsavedCache() vmctxt_interface.UnsavedCache {
+	return db.cache
+}
+
+func TestSelfdestructLargeStorage(t *testing.T) {
+	addr1, _, _ := types.CreateAddress()
+	addr2, _, _ := types.CreateAddress()
+	// the storage deleted is larger than the max storage delta of a block
+	value := types.DataHash([]byte("value")).Bytes()
+	keyCount := ledger.MaxStorageSizeDelta/(types.HashSize+len(value)) + 1
+	key := func(i int) []byte {
+		return types.DataHash(new(big.Int).SetInt64(int64(i)).Bytes()).Bytes()
+	}
+	newDatabase := func() *unsavedCacheDatabase {
+		storageTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+		for i := 0; i < keyCount; i++ {
+			storageTrie.SetValue(key(i), value)
+		}
+		db := &unsavedCacheDatabase{NewNoDatabase(), vm_context.NewUnsavedCache(storageTrie)}
+		db.addr = addr1
+		return db
+	}
+	selfdestruct := func(db *unsavedCacheDatabase, quotaLeft uint64) (uint64, error) {
+		vm := NewVM()
+		c := newContract(&ledger.AccountBlock{AccountAddress: addr1}, db, &ledger.AccountBlock{}, nil, quotaLeft, 0)
+		c.intPool = poolOfIntPools.get()
+		defer poolOfIntPools.put(c.intPool)
+		stack := newStack()
+		stack.push(new(big.Int).SetBytes(addr2.Bytes()))
+		pc := uint64(0)
+		_, err := opSelfdestruct(&pc, vm, c, nil, stack)
+		return c.quotaLeft, err
+	}
+
+	db := newDatabase()
+	_, err := selfdestruct(db, util.InitialGasTable.SelfdestructKeyGas*uint64(keyCount)-1)
+	if err != util.ErrOutOfQuota {
+		t.Fatalf("selfdestruct should be out of quota when the keys walked cost more than the quota left, got %v", err)
+	}
+	if len(db.cache.GetStorage(vm_context.STORAGE_KEY_CODE)) > 0 {
+		t.Fatal("the contract should not be destroyed when selfdestruct is out of quota")
+	}
+
+	db = newDatabase()
+	quotaLeft, err := selfdestruct(db, util.InitialGasTable.SelfdestructKeyGas*uint64(keyCount))
+	if err != nil || quotaLeft != 0 {
+		t.Fatalf("selfdestruct failed, %v, quota left %v", err, quotaLeft)
+	}
+	for i := 0; i < keyCount; i++ {
+		if value := db.cache.GetStorage(key(i)); len(value) > 0 {
+			t.Fatalf("the storage should be deleted, got %v", value)
+		}
+	}
+	delta := db.cache.StorageDelta()
+	if delta.KeysDeleted != uint64(keyCount) || delta.SizeDelta >= -ledger.MaxStorageSizeDelta {
+		t.Fatalf("unexpected storage delta %+v", delta)
+	}
+	if err := delta.CheckSize(); err != nil {
+		t.Fatalf("the storage deleted by selfdestruct should never exceed the storage delta limit, got %v", err)
+	}
+}
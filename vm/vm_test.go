@@ -510,6 +510,9 @@ func TestCalcQuotaV2(t *testing.T) {
 	// prepare db
 	snapshot3 := &ledger.SnapshotBlock{Height: 3, Timestamp: &timestamp, Hash: types.DataHash([]byte{10, 3})}
 	db.snapshotBlockList = append(db.snapshotBlockList, snapshot3)
+	if blocks, err := quota.GetQuotaUsedBlocks(db); err != nil || len(blocks) != 0 {
+		t.Fatalf("blocks referring to the previous snapshot blocks used no quota of the next block, got %v, %v", blocks, err)
+	}
 
 	// first account block without PoW, pledge amount reaches quota limit, snapshot height gap=2
 	quotaTotal, quotaAddition, err = quota.CalcQuotaV2(db, addr1, maxPledgeAmount, helper.Big0)
@@ -550,6 +553,9 @@ func TestCalcQuotaV2(t *testing.T) {
 		Hash:           hash12,
 	}
 	db.accountBlockMap[addr1][hash12] = block12
+	if blocks, err := quota.GetQuotaUsedBlocks(db); err != nil || len(blocks) != 1 || blocks[0].Hash != hash12 {
+		t.Fatalf("unexpected quota used blocks %v, %v", blocks, err)
+	}
 
 	// second account block referring to same snapshotBlock without PoW, pledge amount reaches quota limit, snapshot height gap=2
 	quotaTotal, quotaAddition, err = quota.CalcQuotaV2(db, addr1, maxPledgeAmount, helper.Big0)
@@ -1094,3 +1100,27 @@ func TestOffChainReader(t *testing.T) {
 		}
 	}
 }
+
+func TestOffChainCall(t *testing.T) {
+	sbTime := time.Now()
+	sb := ledger.SnapshotBlock{Height: 1, Timestamp: &sbTime, Hash: types.DataHash([]byte{1, 1})}
+	addr, _, _ := types.CreateAddress()
+	db := NewMemoryDatabase(addr, &sb)
+	if _, err := NewVM().OffChainCall(db, nil); err != util.ErrContractNotExists {
+		t.Fatalf("call a contract not exists should fail, got %v", err)
+	}
+
+	// return 42
+	code, _ := hex.DecodeString("602a60005260206000f3")
+	db.SetContractCode(util.PackContractCode(util.SolidityPPContractType, code))
+	result, err := NewVM().OffChainCall(db, nil)
+	if err != nil || !bytes.Equal(result, helper.LeftPadBytes([]byte{42}, helper.WordSize)) {
+		t.Fatalf("unexpected result %v, %v", hex.EncodeToString(result), err)
+	}
+	// sstore 1 at 0
+	code, _ = hex.DecodeString("6001600055")
+	db.SetContractCode(util.PackContractCode(util.SolidityPPContractType, code))
+	if _, err := NewVM().OffChainCall(db, nil); err != nil || len(db.GetStorage(&addr, types.ZERO_HASH.Bytes())) > 0 {
+		t.Fatalf("off chain call should not write the storage, %v", err)
+	}
+}
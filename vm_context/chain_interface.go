@@ -26,7 +26,9 @@ type Chain interface {
 	GetSnapshotBlocksByHeight(height uint64, count uint64, forward, containSnapshotContent bool) ([]*ledger.SnapshotBlock, error)
 
 	GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error)
+	GetAccountBlocksBySnapshotHash(addr *types.Address, endHeight uint64, snapshotHash *types.Hash) ([]*ledger.AccountBlock, error)
 	GetStateTrie(hash *types.Hash) *trie.Trie
+	ShallowCheckStateTrie(stateHash *types.Hash) (bool, error)
 
 	NewStateTrie() *trie.Trie
 	GetConfirmAccountBlock(snapshotHeight uint64, address *types.Address) (*ledger.AccountBlock, error)
@@ -1,17 +1,91 @@
 package vm_context
 
-import "github.com/vitelabs/go-vite/trie"
+import (
+	"github.com/vitelabs/go-vite/trie"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"sort"
+	"strings"
+)
 
+// StorageIterator iterates the storage under a prefix in key order. The keys deleted keep empty values in the trie
+// and the unsaved cache, they are skipped.
 type StorageIterator struct {
-	trieIterator *trie.Iterator
+	// base iterates the storage in key order, it may be nil
+	base      vmctxt_interface.StorageIterator
+	baseKey   []byte
+	baseValue []byte
+	baseOk    bool
+	baseRead  bool
+
+	// the pending writes under the prefix, sorted by key
+	pendingKeys   []string
+	pendingValues [][]byte
+	index         int
 }
 
 func NewStorageIterator(trie *trie.Trie, prefix []byte) *StorageIterator {
+	return newMergedStorageIterator(trie, nil, prefix)
+}
+
+// newMergedStorageIterator iterates the storage of trie merged with the pending writes not flushed into trie yet,
+// a pending write replaces the value of the same key in trie
+func newMergedStorageIterator(trie *trie.Trie, pending map[string][]byte, prefix []byte) *StorageIterator {
+	if trie == nil {
+		return mergeStorageIterator(nil, pending, prefix)
+	}
+	return mergeStorageIterator(trie.NewIterator(prefix), pending, prefix)
+}
+
+// mergeStorageIterator iterates the storage of base merged with the pending writes. base must iterate in key order,
+// it may be nil. Only the pending keys are sorted up front, base is read one key at a time.
+func mergeStorageIterator(base vmctxt_interface.StorageIterator, pending map[string][]byte, prefix []byte) *StorageIterator {
+	pendingKeys := make([]string, 0, len(pending))
+	for key := range pending {
+		if strings.HasPrefix(key, string(prefix)) {
+			pendingKeys = append(pendingKeys, key)
+		}
+	}
+	sort.Strings(pendingKeys)
+
+	pendingValues := make([][]byte, len(pendingKeys))
+	for i, key := range pendingKeys {
+		pendingValues[i] = pending[key]
+	}
 	return &StorageIterator{
-		trieIterator: trie.NewIterator(prefix),
+		base:          base,
+		pendingKeys:   pendingKeys,
+		pendingValues: pendingValues,
 	}
 }
 
 func (si *StorageIterator) Next() (key, value []byte, ok bool) {
-	return si.trieIterator.Next()
+	for {
+		if !si.baseRead {
+			if si.base != nil {
+				si.baseKey, si.baseValue, si.baseOk = si.base.Next()
+			}
+			si.baseRead = true
+		}
+
+		hasPending := si.index < len(si.pendingKeys)
+		if !si.baseOk && !hasPending {
+			return nil, nil, false
+		}
+
+		if hasPending && (!si.baseOk || si.pendingKeys[si.index] <= string(si.baseKey)) {
+			key, value = []byte(si.pendingKeys[si.index]), si.pendingValues[si.index]
+			// the pending write replaces the value in base
+			if si.baseOk && si.pendingKeys[si.index] == string(si.baseKey) {
+				si.baseRead = false
+			}
+			si.index++
+		} else {
+			key, value = si.baseKey, si.baseValue
+			si.baseRead = false
+		}
+
+		if len(value) > 0 {
+			return key, value, true
+		}
+	}
 }
@@ -0,0 +1,49 @@
+package vm_context
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/trie"
+	"testing"
+)
+
+func TestUnsavedCache_NewStorageIterator(t *testing.T) {
+	stateTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	for _, key := range []string{"a3", "a1", "b1", "a2", "a5"} {
+		stateTrie.SetValue([]byte(key), []byte("saved"+key))
+	}
+	// deleted before, the empty value is left in the trie
+	stateTrie.SetValue([]byte("a4"), []byte{})
+
+	cache := NewUnsavedCache(stateTrie)
+	cache.SetStorage([]byte("a0"), []byte("new"))
+	cache.SetStorage([]byte("a2"), []byte("changed"))
+	cache.SetStorage([]byte("a3"), nil)
+	cache.SetStorage([]byte("c1"), []byte("other prefix"))
+
+	expected := []struct{ key, value string }{
+		{"a0", "new"},
+		{"a1", "saveda1"},
+		{"a2", "changed"},
+		{"a5", "saveda5"},
+	}
+	check := func(iterator interface {
+		Next() (key, value []byte, ok bool)
+	}) {
+		for i, e := range expected {
+			key, value, ok := iterator.Next()
+			if !ok || !bytes.Equal(key, []byte(e.key)) || !bytes.Equal(value, []byte(e.value)) {
+				t.Fatalf("item %v, expected %v %v, got %s %s %v", i, e.key, e.value, key, value, ok)
+			}
+		}
+		if key, _, ok := iterator.Next(); ok {
+			t.Fatalf("unexpected key %s", key)
+		}
+	}
+	check(cache.NewStorageIterator([]byte("a")))
+	if len(cache.Storage()) != 4 {
+		t.Fatal("iterating should not flush the pending writes")
+	}
+
+	// the same storage once the pending writes are flushed
+	check(NewStorageIterator(cache.Trie(), []byte("a")))
+}
@@ -0,0 +1,68 @@
+package vm_context
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+// StorageProof proves the value of a key in the storage of an account at a snapshot block. It is checked against
+// the state hash of the snapshot block, the root of the trie mapping the addresses to the state hashes of the
+// accounts, so that a light client needs only the snapshot block headers.
+type StorageProof struct {
+	// AccountProof proves the state hash of the account in the state of the snapshot block
+	AccountProof [][]byte
+	// StorageProof proves the value in the state of the account, nil if the account has no state at the snapshot block
+	StorageProof [][]byte
+}
+
+// NewStorageProof proves the value of key in the storage of addr at snapshotBlock
+func NewStorageProof(chain Chain, snapshotBlock *ledger.SnapshotBlock, addr types.Address, key []byte) (*StorageProof, error) {
+	snapshotTrie := chain.GetStateTrie(&snapshotBlock.StateHash)
+	if snapshotTrie == nil {
+		return nil, errors.New("the state of the snapshot block is not found")
+	}
+	accountProof, err := snapshotTrie.Prove(addr.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	proof := &StorageProof{AccountProof: accountProof}
+
+	stateHashBytes := snapshotTrie.GetValue(addr.Bytes())
+	if len(stateHashBytes) == 0 {
+		return proof, nil
+	}
+	stateHash, err := types.BytesToHash(stateHashBytes)
+	if err != nil {
+		return nil, err
+	}
+	accountTrie := chain.GetStateTrie(&stateHash)
+	if accountTrie == nil {
+		return nil, errors.New("the state of the account is not found")
+	}
+	if proof.StorageProof, err = accountTrie.Prove(key); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// Verify checks the proof against stateRoot, the state hash of the snapshot block, and returns the value of key in the
+// storage of addr, nil if it is absent.
+func (proof *StorageProof) Verify(stateRoot types.Hash, addr types.Address, key []byte) ([]byte, error) {
+	stateHashBytes, err := trie.VerifyProof(stateRoot, addr.Bytes(), proof.AccountProof)
+	if err != nil {
+		return nil, err
+	}
+	if len(stateHashBytes) == 0 {
+		if len(proof.StorageProof) > 0 {
+			return nil, trie.ErrInvalidProof
+		}
+		return nil, nil
+	}
+	stateHash, err := types.BytesToHash(stateHashBytes)
+	if err != nil {
+		return nil, trie.ErrInvalidProof
+	}
+	return trie.VerifyProof(stateHash, key, proof.StorageProof)
+}
@@ -0,0 +1,62 @@
+package vm_context
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	"testing"
+)
+
+type proofChain struct {
+	Chain
+	tries map[types.Hash]*trie.Trie
+}
+
+func (c *proofChain) GetStateTrie(hash *types.Hash) *trie.Trie {
+	return c.tries[*hash]
+}
+
+func TestStorageProof(t *testing.T) {
+	addr, _, _ := types.CreateAddress()
+	otherAddr, _, _ := types.CreateAddress()
+	absentAddr, _, _ := types.CreateAddress()
+
+	chain := &proofChain{tries: make(map[types.Hash]*trie.Trie)}
+	snapshotTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	for _, a := range []types.Address{addr, otherAddr} {
+		accountTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+		accountTrie.SetValue([]byte("key"), a.Bytes())
+		accountTrie.SetValue([]byte("key2"), []byte("value2"))
+		chain.tries[*accountTrie.Hash()] = accountTrie
+		snapshotTrie.SetValue(a.Bytes(), accountTrie.Hash().Bytes())
+	}
+	chain.tries[*snapshotTrie.Hash()] = snapshotTrie
+	snapshotBlock := &ledger.SnapshotBlock{StateHash: *snapshotTrie.Hash()}
+
+	for _, c := range []struct {
+		addr     types.Address
+		key      string
+		expected []byte
+	}{
+		{addr, "key", addr.Bytes()},
+		{otherAddr, "key", otherAddr.Bytes()},
+		{addr, "key2", []byte("value2")},
+		{addr, "key3", nil},
+		{absentAddr, "key", nil},
+	} {
+		proof, err := NewStorageProof(chain, snapshotBlock, c.addr, []byte(c.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := proof.Verify(snapshotBlock.StateHash, c.addr, []byte(c.key))
+		if err != nil || !bytes.Equal(value, c.expected) {
+			t.Fatalf("verify %v %v, expected %v, got %v, %v", c.addr, c.key, c.expected, value, err)
+		}
+		if c.expected != nil {
+			if _, err := proof.Verify(snapshotBlock.StateHash, otherAddr, []byte(c.key)); c.addr != otherAddr && err != trie.ErrInvalidProof {
+				t.Fatalf("the proof of %v should not prove %v, got %v", c.addr, otherAddr, err)
+			}
+		}
+	}
+}
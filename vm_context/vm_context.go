@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
@@ -35,10 +36,19 @@ type VmContext struct {
 
 	unsavedCache *UnsavedCache
 	frozen       bool
+	// parent is the context forked from, nil if the context is not forked
+	parent *VmContext
+
+	// the reads of the state before the block and the state tries of the other accounts at the current snapshot
+	// block, they never change within the block
+	originalStorage map[string][]byte
+	accountTries    map[types.Address]*trie.Trie
 
 	log log15.Logger
 }
 
+var ErrStatePruned = errors.New("the state of the snapshot block is pruned, the ledger gc must be disabled to keep it")
+
 func NewEmptyVmContextByTrie(t *trie.Trie) vmctxt_interface.VmDatabase {
 	if t == nil {
 		t = trie.NewTrie(nil, nil, nil)
@@ -119,7 +129,7 @@ func NewVmContext(chain Chain, snapshotBlockHash *types.Hash, prevAccountBlockHa
 		vmContext.trie = chain.NewStateTrie()
 	}
 
-	vmContext.unsavedCache = NewUnsavedCache(vmContext.trie)
+	vmContext.unsavedCache = vmContext.newUnsavedCache(vmContext.trie)
 
 	return vmContext, nil
 }
@@ -139,6 +149,56 @@ func (context *VmContext) getSnapshotTrie() *trie.Trie {
 	return context.snapshotTrie
 }
 
+// NewVmContextAt returns a read only context of addr at the snapshot block of snapshotHeight, which reads the state
+// confirmed by then, for the queries of the past states on an archive node.
+func NewVmContextAt(chain Chain, snapshotHeight uint64, addr *types.Address) (vmctxt_interface.VmDatabase, error) {
+	vmContext := &VmContext{
+		chain:   chain,
+		address: addr,
+
+		log: log15.New("module", "vmContext"),
+	}
+
+	snapshotBlock, err := chain.GetSnapshotBlockByHeight(snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if snapshotBlock == nil {
+		return nil, errors.New(fmt.Sprintf("snapshot block %d doesn't exist", snapshotHeight))
+	}
+	if ok, err := chain.ShallowCheckStateTrie(&snapshotBlock.StateHash); err != nil || !ok {
+		return nil, ErrStatePruned
+	}
+	vmContext.currentSnapshotBlock = snapshotBlock
+
+	prevAccountBlock, err := chain.GetConfirmAccountBlock(snapshotHeight, addr)
+	if err != nil {
+		return nil, err
+	}
+	if prevAccountBlock != nil {
+		if ok, err := chain.ShallowCheckStateTrie(&prevAccountBlock.StateHash); err != nil || !ok {
+			return nil, ErrStatePruned
+		}
+		vmContext.prevAccountBlock = prevAccountBlock
+		vmContext.trie = chain.GetStateTrie(&prevAccountBlock.StateHash)
+	}
+	if vmContext.trie == nil {
+		vmContext.trie = chain.NewStateTrie()
+	}
+
+	vmContext.unsavedCache = vmContext.newUnsavedCache(vmContext.trie)
+	// the past is not written
+	vmContext.frozen = true
+	return vmContext, nil
+}
+
+// newUnsavedCache returns an unsaved cache on trie, which removes the deleted keys since the StorageDeletion fork
+func (context *VmContext) newUnsavedCache(trie *trie.Trie) *UnsavedCache {
+	cache := NewUnsavedCache(trie)
+	cache.removeDeleted = context.currentSnapshotBlock != nil && fork.IsStorageDeletionFork(context.currentSnapshotBlock.Height)
+	return cache
+}
+
 func (context *VmContext) CopyAndFreeze() vmctxt_interface.VmDatabase {
 	copyTrie := context.unsavedCache.Trie().Copy()
 	context.frozen = true
@@ -148,11 +208,48 @@ func (context *VmContext) CopyAndFreeze() vmctxt_interface.VmDatabase {
 		currentSnapshotBlock: context.currentSnapshotBlock,
 
 		trie:         copyTrie,
-		unsavedCache: NewUnsavedCache(copyTrie),
+		unsavedCache: context.newUnsavedCache(copyTrie),
 		frozen:       false,
 	}
 }
 
+// Fork returns a child of the context for a speculative run. The child reads the state of the context and keeps its
+// writes apart, Merge writes them into the context and a child dropped without merging discards them. The context
+// should not be written while the child is in use.
+func (context *VmContext) Fork() *VmContext {
+	return &VmContext{
+		chain:                context.chain,
+		address:              context.address,
+		currentSnapshotBlock: context.currentSnapshotBlock,
+		prevAccountBlock:     context.prevAccountBlock,
+		snapshotTrie:         context.snapshotTrie,
+		trie:                 context.trie,
+
+		unsavedCache: context.unsavedCache.fork(),
+		frozen:       false,
+		parent:       context,
+
+		originalStorage: context.originalStorage,
+		accountTries:    context.accountTries,
+
+		log: context.log,
+	}
+}
+
+// Merge writes the storage, logs and contract gids of a forked context into the context it is forked from
+func (context *VmContext) Merge() error {
+	if context.parent == nil {
+		return errors.New("vm context is not forked")
+	}
+	if context.parent.frozen {
+		return errors.New("vm context forked from is frozen")
+	}
+	context.unsavedCache.merge()
+	// the writes belong to the parent now, the child is not merged twice
+	context.unsavedCache = context.parent.unsavedCache.fork()
+	return nil
+}
+
 func (context *VmContext) Address() *types.Address {
 	return context.address
 }
@@ -261,7 +358,7 @@ func (context *VmContext) GetSnapshotBlockByHash(hash *types.Hash) *ledger.Snaps
 }
 
 func (context *VmContext) Reset() {
-	context.unsavedCache = NewUnsavedCache(context.trie)
+	context.unsavedCache = context.newUnsavedCache(context.trie)
 	context.frozen = false
 }
 
@@ -310,30 +407,60 @@ func (context *VmContext) SetStorage(key []byte, value []byte) {
 	context.setStorage(key, value)
 }
 
+// SetValues sets the storage of a batch of keys at once, the balance and code keys are left out as SetStorage does
+func (context *VmContext) SetValues(values map[string][]byte) {
+	if context.frozen {
+		return
+	}
+	batch := make(map[string][]byte, len(values))
+	for key, value := range values {
+		if !context.isBalanceOrCode([]byte(key)) {
+			batch[key] = value
+		}
+	}
+	context.unsavedCache.SetValues(batch)
+}
+
 func (context *VmContext) GetStorage(addr *types.Address, key []byte) []byte {
 	if context.isSelf(addr) {
-		if value := context.unsavedCache.GetStorage(key); value != nil {
-			return value
-		}
-		if value := context.unsavedCache.trie.GetValue(key); value != nil {
-			return value
-		}
-		return context.trie.GetValue(key)
+		return context.unsavedCache.GetStorage(key)
 	} else if context.chain != nil {
-		snapshotTrie := context.getSnapshotTrie()
-		stateHashBytes := snapshotTrie.GetValue(addr.Bytes())
-
-		if len(stateHashBytes) > 0 {
-			stateHash, _ := types.BytesToHash(stateHashBytes)
-			trie := context.chain.GetStateTrie(&stateHash)
-			return trie.GetValue(key)
+		if accountTrie := context.getAccountTrie(addr); accountTrie != nil {
+			return getTrieValue(accountTrie, key)
 		}
 	}
 	return nil
 }
 
+// GetOriginalStorage returns the value of key before the block, the values read are cached for the block
 func (context *VmContext) GetOriginalStorage(key []byte) []byte {
-	return context.trie.GetValue(key)
+	if value, ok := context.originalStorage[string(key)]; ok {
+		return value
+	}
+	value := getTrieValue(context.trie, key)
+	if context.originalStorage == nil {
+		context.originalStorage = make(map[string][]byte)
+	}
+	context.originalStorage[string(key)] = value
+	return value
+}
+
+// getAccountTrie returns the state trie of addr at the current snapshot block, nil if addr has no state. Each trie is
+// resolved once for the block, instead of loading it from the chain on every read.
+func (context *VmContext) getAccountTrie(addr *types.Address) *trie.Trie {
+	if accountTrie, ok := context.accountTries[*addr]; ok {
+		return accountTrie
+	}
+	var accountTrie *trie.Trie
+	if stateHashBytes := context.getSnapshotTrie().GetValue(addr.Bytes()); len(stateHashBytes) > 0 {
+		stateHash, _ := types.BytesToHash(stateHashBytes)
+		accountTrie = context.chain.GetStateTrie(&stateHash)
+	}
+	if context.accountTries == nil {
+		context.accountTries = make(map[types.Address]*trie.Trie)
+	}
+	context.accountTries[*addr] = accountTrie
+	return accountTrie
 }
 
 func (context *VmContext) GetStorageHash() *types.Hash {
@@ -413,6 +540,54 @@ func (context *VmContext) GetAccountBlockByHash(hash *types.Hash) *ledger.Accoun
 	accountBlock, _ := context.chain.GetAccountBlockByHash(hash)
 	return accountBlock
 }
+
+// GetPrevAccountBlocksBySnapshotHash returns the blocks from the prev account block downwards which refer to
+// snapshotHash, in descending order of height. The prev account block and the ones above the latest saved block
+// may be unsaved, so they are walked by their prev hashes, and the saved ones are read in one scan
+func (context *VmContext) GetPrevAccountBlocksBySnapshotHash(snapshotHash *types.Hash) ([]*ledger.AccountBlock, error) {
+	if context.prevAccountBlock == nil {
+		return nil, nil
+	}
+	if context.chain == nil {
+		err := errors.New("context.chain is nil")
+		context.log.Error(err.Error(), "method", "GetPrevAccountBlocksBySnapshotHash")
+		return nil, err
+	}
+
+	addr := context.prevAccountBlock.AccountAddress
+	latestBlock, err := context.chain.GetLatestAccountBlock(&addr)
+	if err != nil {
+		context.log.Error("GetLatestAccountBlock failed, error is "+err.Error(), "method", "GetPrevAccountBlocksBySnapshotHash")
+		return nil, err
+	}
+	var savedHeight uint64
+	if latestBlock != nil {
+		savedHeight = latestBlock.Height
+	}
+
+	var blockList []*ledger.AccountBlock
+	block := context.prevAccountBlock
+	for block.Height > savedHeight {
+		if block.SnapshotHash != *snapshotHash {
+			return blockList, nil
+		}
+		blockList = append(blockList, block)
+		if block.Height == 1 {
+			return blockList, nil
+		}
+		block = context.GetAccountBlockByHash(&block.PrevHash)
+		if block == nil {
+			return blockList, nil
+		}
+	}
+
+	savedBlockList, err := context.chain.GetAccountBlocksBySnapshotHash(&addr, block.Height, snapshotHash)
+	if err != nil {
+		return nil, err
+	}
+	return append(blockList, savedBlockList...), nil
+}
+
 func (context *VmContext) GetSelfAccountBlockByHeight(height uint64) *ledger.AccountBlock {
 	if context.address == nil || context.prevAccountBlock == nil {
 		return nil
@@ -430,16 +605,9 @@ func (context *VmContext) GetSelfAccountBlockByHeight(height uint64) *ledger.Acc
 
 func (context *VmContext) NewStorageIterator(addr *types.Address, prefix []byte) vmctxt_interface.StorageIterator {
 	if context.isSelf(addr) {
-		return NewStorageIterator(context.unsavedCache.Trie(), prefix)
-	} else {
-		snapshotTrie := context.getSnapshotTrie()
-		stateHashBytes := snapshotTrie.GetValue(addr.Bytes())
-
-		if len(stateHashBytes) > 0 {
-			stateHash, _ := types.BytesToHash(stateHashBytes)
-			trie := context.chain.GetStateTrie(&stateHash)
-			return NewStorageIterator(trie, prefix)
-		}
+		return context.unsavedCache.NewStorageIterator(prefix)
+	} else if accountTrie := context.getAccountTrie(addr); accountTrie != nil {
+		return NewStorageIterator(accountTrie, prefix)
 	}
 	return nil
 }
@@ -24,11 +24,22 @@ type UnsavedCache struct {
 	contractGidList []vmctxt_interface.ContractGid
 
 	logList ledger.VmLogList
+	// a nil value is the tombstone of a deleted key
 	storage map[string][]byte
 
 	trie *trie.Trie
 
 	trieDirty bool
+	// removeDeleted removes the deleted keys from the trie when flushed, they are kept as empty values before the
+	// StorageDeletion fork
+	removeDeleted bool
+
+	// the sizes of the keys written before the block, 0 if a key is absent
+	originalSizes map[string]int
+	bytesWritten  uint64
+
+	// parent is the cache forked from, the keys not written to a forked cache are read from parent
+	parent *UnsavedCache
 }
 
 func NewUnsavedCache(trie *trie.Trie) *UnsavedCache {
@@ -37,14 +48,45 @@ func NewUnsavedCache(trie *trie.Trie) *UnsavedCache {
 
 		trie:      trie.Copy(),
 		trieDirty: false,
+
+		originalSizes: make(map[string]int),
+	}
+}
+
+// fork returns a child of the cache, which reads the storage of the cache and keeps its own writes until merge
+func (cache *UnsavedCache) fork() *UnsavedCache {
+	return &UnsavedCache{
+		storage: make(map[string][]byte),
+		// read only, the writes of a forked cache stay in storage
+		trie:          cache.trie,
+		removeDeleted: cache.removeDeleted,
+
+		originalSizes: make(map[string]int),
+		parent:        cache,
 	}
 }
 
+// merge writes the storage, logs and contract gids of a forked cache into its parent
+func (cache *UnsavedCache) merge() {
+	parent := cache.parent
+	bytesWritten := parent.bytesWritten
+	for key, value := range cache.storage {
+		parent.SetStorage([]byte(key), value)
+	}
+	parent.bytesWritten = bytesWritten + cache.bytesWritten
+	parent.logList = append(parent.logList, cache.logList...)
+	parent.contractGidList = append(parent.contractGidList, cache.contractGidList...)
+}
+
 func (cache *UnsavedCache) Trie() *trie.Trie {
+	if cache.parent != nil {
+		// a forked cache is not saved, the trie is built on demand without touching the parent
+		forkTrie := cache.parent.Trie().Copy()
+		cache.flush(forkTrie)
+		return forkTrie
+	}
 	if cache.trieDirty {
-		for key, value := range cache.storage {
-			cache.trie.SetValue([]byte(key), value)
-		}
+		cache.flush(cache.trie)
 
 		cache.storage = make(map[string][]byte)
 		cache.trieDirty = false
@@ -52,23 +94,92 @@ func (cache *UnsavedCache) Trie() *trie.Trie {
 	return cache.trie
 }
 
+func (cache *UnsavedCache) flush(t *trie.Trie) {
+	for key, value := range cache.storage {
+		if value != nil {
+			t.SetValue([]byte(key), value)
+		} else if cache.removeDeleted {
+			t.DeleteValue([]byte(key))
+		} else {
+			t.SetValue([]byte(key), []byte{})
+		}
+	}
+}
+
+// SetStorage sets the value of key, a nil or empty value deletes the key
 func (cache *UnsavedCache) SetStorage(key []byte, value []byte) {
+	if len(value) == 0 {
+		value = nil
+	}
 
-	// For unsaved judge.
-	if value == nil {
-		value = make([]byte, 0)
+	if _, ok := cache.originalSizes[string(key)]; !ok {
+		cache.originalSizes[string(key)] = storageSize(key, cache.GetStorage(key))
+	}
+	if len(value) > 0 {
+		cache.bytesWritten += uint64(storageSize(key, value))
 	}
 
 	cache.storage[string(key)] = value
 	cache.trieDirty = true
 }
 
+// SetValues sets the storage of a batch of keys, a nil or empty value deletes the key
+func (cache *UnsavedCache) SetValues(values map[string][]byte) {
+	for key, value := range values {
+		cache.SetStorage([]byte(key), value)
+	}
+}
+
+// StorageDelta returns the storage written since the cache is created, which is the storage of an account block
+func (cache *UnsavedCache) StorageDelta() *vmctxt_interface.StorageDelta {
+	delta := &vmctxt_interface.StorageDelta{BytesWritten: cache.bytesWritten}
+	for key, originalSize := range cache.originalSizes {
+		size := storageSize([]byte(key), cache.GetStorage([]byte(key)))
+		if originalSize == 0 && size > 0 {
+			delta.KeysCreated++
+		} else if originalSize > 0 && size == 0 {
+			delta.KeysDeleted++
+		}
+		delta.SizeDelta += int64(size - originalSize)
+	}
+	return delta
+}
+
+func storageSize(key, value []byte) int {
+	if len(value) == 0 {
+		return 0
+	}
+	return len(key) + len(value)
+}
+
+// GetStorage returns the value of key, nil if key is deleted. The empty values kept in the trie by the deletions
+// before the StorageDeletion fork are deleted keys as well, such a key is removed from the trie the first time it is
+// written since the fork. Reads never change the storage.
 func (cache *UnsavedCache) GetStorage(key []byte) []byte {
-	if value := cache.storage[string(key)]; value != nil {
+	if value, ok := cache.storage[string(key)]; ok {
 		return value
 	}
+	if cache.parent != nil {
+		return cache.parent.GetStorage(key)
+	}
 
-	return cache.trie.GetValue(key)
+	return getTrieValue(cache.trie, key)
+}
+
+// getTrieValue returns the value of key in t, an empty value kept by a deletion before the StorageDeletion fork is nil
+func getTrieValue(t *trie.Trie, key []byte) []byte {
+	if value := t.GetValue(key); len(value) > 0 {
+		return value
+	}
+	return nil
+}
+
+// NewStorageIterator iterates the storage under prefix with the pending writes, without flushing them into the trie
+func (cache *UnsavedCache) NewStorageIterator(prefix []byte) vmctxt_interface.StorageIterator {
+	if cache.parent != nil {
+		return mergeStorageIterator(cache.parent.NewStorageIterator(prefix), cache.storage, prefix)
+	}
+	return newMergedStorageIterator(cache.trie, cache.storage, prefix)
 }
 
 func (cache *UnsavedCache) ContractGidList() []vmctxt_interface.ContractGid {
@@ -0,0 +1,119 @@
+package vm_context
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/trie"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"testing"
+)
+
+func TestUnsavedCache_StorageDelta(t *testing.T) {
+	stateTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	stateTrie.SetValue([]byte("k1"), []byte("v1"))
+	stateTrie.SetValue([]byte("k2"), []byte("v2"))
+
+	cache := NewUnsavedCache(stateTrie)
+	cache.SetValues(map[string][]byte{
+		"k1": []byte("value1"),
+		"k2": nil,
+		"k3": []byte("v3"),
+		"k4": []byte("v4"),
+	})
+	// flushed into the trie in the middle of the block
+	cache.Trie()
+	cache.SetStorage([]byte("k4"), nil)
+	cache.SetStorage([]byte("k3"), []byte("value3"))
+
+	delta := cache.StorageDelta()
+	// k1 grows by 4, k2 is deleted, k3 is created, k4 is created and deleted within the block
+	if delta.KeysCreated != 1 || delta.KeysDeleted != 1 || delta.SizeDelta != 4-4+8 || delta.BytesWritten != 8+4+4+8 {
+		t.Fatalf("unexpected storage delta %+v", delta)
+	}
+	if err := delta.CheckSize(); err != nil {
+		t.Fatal(err)
+	}
+
+	cache = NewUnsavedCache(cache.Trie())
+	if delta := cache.StorageDelta(); *delta != (vmctxt_interface.StorageDelta{}) {
+		t.Fatalf("unexpected storage delta of a new block %+v", delta)
+	}
+}
+
+func TestUnsavedCache_DeleteStorage(t *testing.T) {
+	stateTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	stateTrie.SetValue([]byte("k1"), []byte("v1"))
+	stateTrie.SetValue([]byte("k2"), []byte("v2"))
+	// deleted before the StorageDeletion fork
+	stateTrie.SetValue([]byte("k3"), []byte{})
+
+	expectedTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	expectedTrie.SetValue([]byte("k2"), []byte("v2"))
+
+	for _, removeDeleted := range []bool{false, true} {
+		cache := NewUnsavedCache(stateTrie)
+		cache.removeDeleted = removeDeleted
+		cache.SetStorage([]byte("k1"), nil)
+		cache.SetStorage([]byte("k3"), []byte{})
+		for _, key := range []string{"k1", "k3", "k4"} {
+			if value := cache.GetStorage([]byte(key)); value != nil {
+				t.Fatalf("deleted key %v should be nil, got %v", key, value)
+			}
+		}
+		if value := cache.GetStorage([]byte("k2")); !bytes.Equal(value, []byte("v2")) {
+			t.Fatalf("unexpected value of k2 %v", value)
+		}
+
+		flushed := cache.Trie()
+		for _, key := range []string{"k1", "k3"} {
+			if value := flushed.GetValue([]byte(key)); removeDeleted && value != nil || !removeDeleted && value == nil {
+				t.Fatalf("removeDeleted %v, unexpected value of %v in the trie %v", removeDeleted, key, value)
+			}
+		}
+		if removeDeleted && *flushed.Hash() != *expectedTrie.Hash() {
+			t.Fatal("the deleted keys should be removed from the trie")
+		}
+		if value := cache.GetStorage([]byte("k1")); value != nil {
+			t.Fatalf("deleted key should be nil after flushed, got %v", value)
+		}
+	}
+}
+
+func TestUnsavedCache_RemoveLegacyEmptyValue(t *testing.T) {
+	stateTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	stateTrie.SetValue([]byte("k1"), []byte("v1"))
+	// deleted before the StorageDeletion fork
+	for _, key := range []string{"k2", "k3", "k4", "k5"} {
+		stateTrie.SetValue([]byte(key), []byte{})
+	}
+	stateHash := *stateTrie.Hash()
+
+	cache := NewUnsavedCache(stateTrie)
+	cache.removeDeleted = true
+	for _, key := range []string{"k1", "k2", "k3", "k6"} {
+		cache.GetStorage([]byte(key))
+	}
+	// a forked cache discarded without merge
+	cache.fork().GetStorage([]byte("k4"))
+	if len(cache.Storage()) != 0 || *cache.Trie().Hash() != stateHash {
+		t.Fatal("reading should not change the storage")
+	}
+
+	cache.SetStorage([]byte("k2"), nil)
+	cache.SetStorage([]byte("k3"), []byte("v3"))
+	forked := cache.fork()
+	forked.SetStorage([]byte("k4"), nil)
+	forked.merge()
+	if delta := cache.StorageDelta(); delta.KeysCreated != 1 || delta.KeysDeleted != 0 {
+		t.Fatalf("the empty values should count as deleted keys, got %+v", delta)
+	}
+
+	flushed := cache.Trie()
+	for key, expected := range map[string]bool{"k1": true, "k2": false, "k3": true, "k4": false, "k5": true} {
+		if flushed.HasKey([]byte(key)) != expected {
+			t.Fatalf("unexpected presence of %v in the trie, expected %v", key, expected)
+		}
+	}
+	if value := flushed.GetValue([]byte("k3")); !bytes.Equal(value, []byte("v3")) {
+		t.Fatalf("unexpected value of k3 %v", value)
+	}
+}
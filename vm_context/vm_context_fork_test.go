@@ -0,0 +1,81 @@
+package vm_context
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	"testing"
+)
+
+func TestVmContext_Fork(t *testing.T) {
+	addr, _, _ := types.CreateAddress()
+	stateTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	stateTrie.SetValue([]byte("k1"), []byte("v1"))
+	context := &VmContext{address: &addr, trie: stateTrie, unsavedCache: NewUnsavedCache(stateTrie)}
+	context.SetStorage([]byte("k2"), []byte("v2"))
+
+	checkStorage := func(db *VmContext, expected map[string]string) {
+		for key, value := range expected {
+			if got := db.GetStorage(&addr, []byte(key)); !bytes.Equal(got, []byte(value)) {
+				t.Fatalf("storage of %v, expected %q, got %q", key, value, got)
+			}
+		}
+		iterator := db.NewStorageIterator(&addr, []byte("k"))
+		count := 0
+		for {
+			key, value, ok := iterator.Next()
+			if !ok {
+				break
+			}
+			if expected[string(key)] != string(value) {
+				t.Fatalf("iterated %s %s, expected %q", key, value, expected[string(key)])
+			}
+			count++
+		}
+		for _, value := range expected {
+			if len(value) == 0 {
+				count++
+			}
+		}
+		if count != len(expected) {
+			t.Fatalf("iterated %v keys, expected %v", count, len(expected))
+		}
+	}
+
+	child := context.Fork()
+	checkStorage(child, map[string]string{"k1": "v1", "k2": "v2"})
+	child.SetStorage([]byte("k1"), nil)
+	child.SetStorage([]byte("k3"), []byte("v3"))
+	child.AddLog(&ledger.VmLog{Data: []byte("child")})
+	checkStorage(child, map[string]string{"k1": "", "k2": "v2", "k3": "v3"})
+	if *child.GetStorageHash() == *context.GetStorageHash() {
+		t.Fatal("the storage hash of the child should differ")
+	}
+
+	// discarded
+	checkStorage(context, map[string]string{"k1": "v1", "k2": "v2", "k3": ""})
+	if len(context.unsavedCache.LogList()) != 0 {
+		t.Fatal("the logs of the child should not reach the parent before merge")
+	}
+
+	grandchild := child.Fork()
+	grandchild.SetStorage([]byte("k2"), []byte("v2'"))
+	if err := grandchild.Merge(); err != nil {
+		t.Fatal(err)
+	}
+	expectedHash := child.GetStorageHash()
+	if err := child.Merge(); err != nil {
+		t.Fatal(err)
+	}
+	checkStorage(context, map[string]string{"k1": "", "k2": "v2'", "k3": "v3"})
+	if len(context.unsavedCache.LogList()) != 1 || *context.GetStorageHash() != *expectedHash {
+		t.Fatal("unexpected state of the parent after merge")
+	}
+	if delta := context.unsavedCache.StorageDelta(); delta.KeysCreated != 2 || delta.KeysDeleted != 1 {
+		t.Fatalf("unexpected storage delta after merge %+v", delta)
+	}
+	if err := context.Merge(); err == nil {
+		t.Fatal("a context not forked should not merge")
+	}
+}
@@ -0,0 +1,106 @@
+package vm_context
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	"testing"
+)
+
+// historyChain keeps the state of addr at every snapshot height, the heights pruned lose their tries
+type historyChain struct {
+	Chain
+	addr           types.Address
+	snapshotBlocks map[uint64]*ledger.SnapshotBlock
+	accountBlocks  map[uint64]*ledger.AccountBlock
+	tries          map[types.Hash]*trie.Trie
+}
+
+func newHistoryChain(addr types.Address, values ...string) *historyChain {
+	c := &historyChain{
+		addr:           addr,
+		snapshotBlocks: make(map[uint64]*ledger.SnapshotBlock),
+		accountBlocks:  make(map[uint64]*ledger.AccountBlock),
+		tries:          make(map[types.Hash]*trie.Trie),
+	}
+	for i, value := range values {
+		height := uint64(i + 1)
+		accountTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+		accountTrie.SetValue([]byte("key"), []byte(value))
+		c.tries[*accountTrie.Hash()] = accountTrie
+		c.accountBlocks[height] = &ledger.AccountBlock{AccountAddress: addr, Height: height, StateHash: *accountTrie.Hash()}
+
+		snapshotTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+		snapshotTrie.SetValue(addr.Bytes(), accountTrie.Hash().Bytes())
+		c.tries[*snapshotTrie.Hash()] = snapshotTrie
+		c.snapshotBlocks[height] = &ledger.SnapshotBlock{Height: height, StateHash: *snapshotTrie.Hash()}
+	}
+	return c
+}
+
+func (c *historyChain) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	return c.snapshotBlocks[height], nil
+}
+
+func (c *historyChain) GetConfirmAccountBlock(snapshotHeight uint64, address *types.Address) (*ledger.AccountBlock, error) {
+	if *address != c.addr {
+		return nil, nil
+	}
+	return c.accountBlocks[snapshotHeight], nil
+}
+
+func (c *historyChain) ShallowCheckStateTrie(stateHash *types.Hash) (bool, error) {
+	_, ok := c.tries[*stateHash]
+	return ok, nil
+}
+
+func (c *historyChain) GetStateTrie(hash *types.Hash) *trie.Trie {
+	if t, ok := c.tries[*hash]; ok {
+		return t.Copy()
+	}
+	return nil
+}
+
+func (c *historyChain) NewStateTrie() *trie.Trie {
+	return trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+}
+
+func TestNewVmContextAt(t *testing.T) {
+	addr, _, _ := types.CreateAddress()
+	otherAddr, _, _ := types.CreateAddress()
+	chain := newHistoryChain(addr, "v1", "v2", "v3")
+
+	for height, expected := range map[uint64]string{1: "v1", 2: "v2", 3: "v3"} {
+		db, err := NewVmContextAt(chain, height, &addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value := db.GetStorage(&addr, []byte("key")); !bytes.Equal(value, []byte(expected)) {
+			t.Fatalf("value at %v, expected %v, got %s", height, expected, value)
+		}
+		other, err := NewVmContextAt(chain, height, &otherAddr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value := other.GetStorage(&addr, []byte("key")); !bytes.Equal(value, []byte(expected)) {
+			t.Fatalf("value of the other account at %v, expected %v, got %s", height, expected, value)
+		}
+		if value := other.GetStorage(&otherAddr, []byte("key")); value != nil {
+			t.Fatalf("unexpected value of an account with no state %s", value)
+		}
+
+		db.SetStorage([]byte("key"), []byte("changed"))
+		if value := db.GetStorage(&addr, []byte("key")); !bytes.Equal(value, []byte(expected)) {
+			t.Fatal("the past state should not be written")
+		}
+	}
+
+	if _, err := NewVmContextAt(chain, 4, &addr); err == nil {
+		t.Fatal("the context should not be at a snapshot block not existing")
+	}
+	delete(chain.tries, chain.snapshotBlocks[1].StateHash)
+	if _, err := NewVmContextAt(chain, 1, &addr); err != ErrStatePruned {
+		t.Fatalf("the state pruned should not be read, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package vm_context
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"testing"
+)
+
+// prevBlocksChain saves the blocks of an account up to savedHeight, the ones above it can only be read by hash, like
+// the blocks in the pool
+type prevBlocksChain struct {
+	Chain
+	blocks      []*ledger.AccountBlock
+	savedHeight uint64
+}
+
+func (c *prevBlocksChain) GetLatestAccountBlock(addr *types.Address) (*ledger.AccountBlock, error) {
+	if c.savedHeight == 0 {
+		return nil, nil
+	}
+	return c.blocks[c.savedHeight-1], nil
+}
+
+func (c *prevBlocksChain) GetAccountBlockByHash(hash *types.Hash) (*ledger.AccountBlock, error) {
+	for _, block := range c.blocks {
+		if block.Hash == *hash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *prevBlocksChain) GetAccountBlocksBySnapshotHash(addr *types.Address, endHeight uint64, snapshotHash *types.Hash) ([]*ledger.AccountBlock, error) {
+	if endHeight > c.savedHeight {
+		endHeight = c.savedHeight
+	}
+	var blockList []*ledger.AccountBlock
+	for height := endHeight; height > 0 && c.blocks[height-1].SnapshotHash == *snapshotHash; height-- {
+		blockList = append(blockList, c.blocks[height-1])
+	}
+	return blockList, nil
+}
+
+func TestVmContext_GetPrevAccountBlocksBySnapshotHash(t *testing.T) {
+	addr, _, _ := types.CreateAddress()
+	oldSnapshotHash := types.DataHash([]byte("old"))
+	snapshotHash := types.DataHash([]byte("current"))
+
+	// blocks 1 and 2 refer to the old snapshot block, 3 to 6 to the current one
+	chain := &prevBlocksChain{}
+	for height := uint64(1); height <= 6; height++ {
+		block := &ledger.AccountBlock{AccountAddress: addr, Height: height, SnapshotHash: snapshotHash}
+		if height <= 2 {
+			block.SnapshotHash = oldSnapshotHash
+		}
+		block.Hash = types.DataHash([]byte{byte(height)})
+		if height > 1 {
+			block.PrevHash = chain.blocks[height-2].Hash
+		}
+		chain.blocks = append(chain.blocks, block)
+	}
+
+	for savedHeight := uint64(0); savedHeight <= 6; savedHeight++ {
+		chain.savedHeight = savedHeight
+		context := &VmContext{chain: chain, address: &addr, prevAccountBlock: chain.blocks[5]}
+		blockList, err := context.GetPrevAccountBlocksBySnapshotHash(&snapshotHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blockList) != 4 {
+			t.Fatalf("saved height %v, expected 4 blocks, got %v", savedHeight, len(blockList))
+		}
+		for i, block := range blockList {
+			if block.Height != uint64(6-i) {
+				t.Fatalf("saved height %v, unexpected block at %v: %v", savedHeight, i, block.Height)
+			}
+		}
+	}
+}
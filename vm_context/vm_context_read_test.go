@@ -0,0 +1,130 @@
+package vm_context
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	"strconv"
+	"testing"
+)
+
+// loadingChain copies the saved trie on every GetStateTrie, like the chain loads it from the db
+type loadingChain struct {
+	Chain
+	tries map[types.Hash]*trie.Trie
+	loads int
+}
+
+func (c *loadingChain) GetStateTrie(hash *types.Hash) *trie.Trie {
+	c.loads++
+	if t, ok := c.tries[*hash]; ok {
+		return t.Copy()
+	}
+	return nil
+}
+
+func newReadTestContext(keyCount int) (*VmContext, *loadingChain, types.Address) {
+	addr, _, _ := types.CreateAddress()
+	otherAddr, _, _ := types.CreateAddress()
+
+	chain := &loadingChain{tries: make(map[types.Hash]*trie.Trie)}
+	snapshotTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+	var stateTrie *trie.Trie
+	for _, a := range []types.Address{addr, otherAddr} {
+		accountTrie := trie.NewTrie(nil, nil, trie.NewTrieNodePool())
+		for i := 0; i < keyCount; i++ {
+			accountTrie.SetValue([]byte("key"+strconv.Itoa(i)), a.Bytes())
+		}
+		chain.tries[*accountTrie.Hash()] = accountTrie
+		snapshotTrie.SetValue(a.Bytes(), accountTrie.Hash().Bytes())
+		if a == addr {
+			stateTrie = accountTrie.Copy()
+		}
+	}
+	chain.tries[*snapshotTrie.Hash()] = snapshotTrie
+
+	context := &VmContext{
+		chain:                chain,
+		address:              &addr,
+		currentSnapshotBlock: &ledger.SnapshotBlock{StateHash: *snapshotTrie.Hash()},
+		trie:                 stateTrie,
+		unsavedCache:         NewUnsavedCache(stateTrie),
+	}
+	return context, chain, otherAddr
+}
+
+func TestVmContext_CachedReads(t *testing.T) {
+	context, chain, otherAddr := newReadTestContext(16)
+	addr := *context.address
+
+	context.SetStorage([]byte("key1"), []byte("changed"))
+	for i := 0; i < 3; i++ {
+		if value := context.GetOriginalStorage([]byte("key1")); !bytes.Equal(value, addr.Bytes()) {
+			t.Fatalf("unexpected original value %v", value)
+		}
+		if value := context.GetOriginalStorage([]byte("absent")); value != nil {
+			t.Fatalf("unexpected original value of absent key %v", value)
+		}
+		if value := context.GetStorage(&otherAddr, []byte("key2")); !bytes.Equal(value, otherAddr.Bytes()) {
+			t.Fatalf("unexpected value of other account %v", value)
+		}
+		absentAddr, _, _ := types.CreateAddress()
+		if value := context.GetStorage(&absentAddr, []byte("key2")); value != nil {
+			t.Fatalf("unexpected value of absent account %v", value)
+		}
+	}
+	if iterator := context.NewStorageIterator(&otherAddr, []byte("key1")); iterator == nil {
+		t.Fatal("other account should be iterated")
+	}
+	// the snapshot trie and the trie of the other account
+	if chain.loads != 2 {
+		t.Fatalf("state tries should be loaded once for the block, loaded %v times", chain.loads)
+	}
+
+	// a forked context shares the reads of the block
+	forked := context.Fork()
+	forked.GetStorage(&otherAddr, []byte("key3"))
+	if chain.loads != 2 {
+		t.Fatalf("forked context should reuse the loaded tries, loaded %v times", chain.loads)
+	}
+}
+
+func benchmarkRepeatedReads(b *testing.B, read func(context *VmContext, otherAddr *types.Address, key []byte)) {
+	context, _, otherAddr := newReadTestContext(1000)
+	keys := make([][]byte, 20)
+	for i := range keys {
+		keys[i] = []byte("key" + strconv.Itoa(i*50))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		read(context, &otherAddr, keys[i%len(keys)])
+	}
+}
+
+func BenchmarkVmContext_GetOriginalStorage(b *testing.B) {
+	b.Run("trie", func(b *testing.B) {
+		benchmarkRepeatedReads(b, func(context *VmContext, otherAddr *types.Address, key []byte) {
+			context.trie.GetValue(key)
+		})
+	})
+	b.Run("cached", func(b *testing.B) {
+		benchmarkRepeatedReads(b, func(context *VmContext, otherAddr *types.Address, key []byte) {
+			context.GetOriginalStorage(key)
+		})
+	})
+}
+
+func BenchmarkVmContext_GetStorageOfOtherAccount(b *testing.B) {
+	b.Run("load", func(b *testing.B) {
+		benchmarkRepeatedReads(b, func(context *VmContext, otherAddr *types.Address, key []byte) {
+			context.accountTries = nil
+			context.GetStorage(otherAddr, key)
+		})
+	})
+	b.Run("cached", func(b *testing.B) {
+		benchmarkRepeatedReads(b, func(context *VmContext, otherAddr *types.Address, key []byte) {
+			context.GetStorage(otherAddr, key)
+		})
+	})
+}
@@ -8,8 +8,29 @@ import (
 type UnsavedCache interface {
 	Trie() *trie.Trie
 	SetStorage(key []byte, value []byte)
+	SetValues(values map[string][]byte)
 	GetStorage(key []byte) []byte
+	NewStorageIterator(prefix []byte) StorageIterator
 	ContractGidList() []ContractGid
 	LogList() ledger.VmLogList
 	Storage() map[string][]byte
+	StorageDelta() *StorageDelta
+}
+
+// StorageDelta is the storage an account block writes, a key and its value count as len(key)+len(value) bytes and
+// a deleted key counts as none
+type StorageDelta struct {
+	// BytesWritten is the sum of the sizes of the keys set to non-empty values, the keys set twice count twice
+	BytesWritten uint64
+	// KeysCreated is the count of the keys absent before the block and present after
+	KeysCreated uint64
+	// KeysDeleted is the count of the keys present before the block and absent after
+	KeysDeleted uint64
+	// SizeDelta is the bytes the storage grows by, negative if it shrinks
+	SizeDelta int64
+}
+
+// CheckSize checks the growth of the storage against the protocol limit
+func (delta *StorageDelta) CheckSize() error {
+	return ledger.CheckStorageSizeDelta(delta.SizeDelta)
 }
@@ -20,6 +20,8 @@ type VmDatabase interface {
 
 	GetAccountBlockByHash(hash *types.Hash) *ledger.AccountBlock
 	GetSelfAccountBlockByHeight(height uint64) *ledger.AccountBlock
+	// GetPrevAccountBlocksBySnapshotHash returns the blocks from PrevAccountBlock downwards which refer to snapshotHash
+	GetPrevAccountBlocksBySnapshotHash(snapshotHash *types.Hash) ([]*ledger.AccountBlock, error)
 
 	UnsavedCache() UnsavedCache
 	Reset()